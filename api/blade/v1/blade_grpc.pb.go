@@ -0,0 +1,294 @@
+// gRPC API for internal services. Calls are answered by the same services as the REST API and
+// authorized with the shared GRPC_TOKEN, sent as "authorization: Bearer <token>" metadata.
+//
+// Run `go generate ./api/blade/v1` after changing this file.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: blade.proto
+
+package bladev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	BladeService_GetUser_FullMethodName         = "/blade.v1.BladeService/GetUser"
+	BladeService_IntrospectToken_FullMethodName = "/blade.v1.BladeService/IntrospectToken"
+	BladeService_GetProduct_FullMethodName      = "/blade.v1.BladeService/GetProduct"
+	BladeService_LookupBarcode_FullMethodName   = "/blade.v1.BladeService/LookupBarcode"
+	BladeService_ListStockLevels_FullMethodName = "/blade.v1.BladeService/ListStockLevels"
+)
+
+// BladeServiceClient is the client API for BladeService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BladeService looks up users, access tokens, products and stock
+type BladeServiceClient interface {
+	// GetUser returns a user by ID, username or email; NOT_FOUND when there is none
+	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error)
+	// IntrospectToken reports whether an access token is valid and whose it is. Invalid and expired
+	// tokens, and tokens of users who can't sign in, are inactive rather than an error.
+	IntrospectToken(ctx context.Context, in *IntrospectTokenRequest, opts ...grpc.CallOption) (*IntrospectTokenResponse, error)
+	// GetProduct returns a product by ID; NOT_FOUND when there is none
+	GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*Product, error)
+	// LookupBarcode resolves a scanned barcode to a product or variant and its stock
+	LookupBarcode(ctx context.Context, in *LookupBarcodeRequest, opts ...grpc.CallOption) (*LookupBarcodeResponse, error)
+	// ListStockLevels lists stock levels per product, variant and store
+	ListStockLevels(ctx context.Context, in *ListStockLevelsRequest, opts ...grpc.CallOption) (*ListStockLevelsResponse, error)
+}
+
+type bladeServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBladeServiceClient(cc grpc.ClientConnInterface) BladeServiceClient {
+	return &bladeServiceClient{cc}
+}
+
+func (c *bladeServiceClient) GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(User)
+	err := c.cc.Invoke(ctx, BladeService_GetUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bladeServiceClient) IntrospectToken(ctx context.Context, in *IntrospectTokenRequest, opts ...grpc.CallOption) (*IntrospectTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IntrospectTokenResponse)
+	err := c.cc.Invoke(ctx, BladeService_IntrospectToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bladeServiceClient) GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*Product, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Product)
+	err := c.cc.Invoke(ctx, BladeService_GetProduct_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bladeServiceClient) LookupBarcode(ctx context.Context, in *LookupBarcodeRequest, opts ...grpc.CallOption) (*LookupBarcodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LookupBarcodeResponse)
+	err := c.cc.Invoke(ctx, BladeService_LookupBarcode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bladeServiceClient) ListStockLevels(ctx context.Context, in *ListStockLevelsRequest, opts ...grpc.CallOption) (*ListStockLevelsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListStockLevelsResponse)
+	err := c.cc.Invoke(ctx, BladeService_ListStockLevels_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BladeServiceServer is the server API for BladeService service.
+// All implementations must embed UnimplementedBladeServiceServer
+// for forward compatibility.
+//
+// BladeService looks up users, access tokens, products and stock
+type BladeServiceServer interface {
+	// GetUser returns a user by ID, username or email; NOT_FOUND when there is none
+	GetUser(context.Context, *GetUserRequest) (*User, error)
+	// IntrospectToken reports whether an access token is valid and whose it is. Invalid and expired
+	// tokens, and tokens of users who can't sign in, are inactive rather than an error.
+	IntrospectToken(context.Context, *IntrospectTokenRequest) (*IntrospectTokenResponse, error)
+	// GetProduct returns a product by ID; NOT_FOUND when there is none
+	GetProduct(context.Context, *GetProductRequest) (*Product, error)
+	// LookupBarcode resolves a scanned barcode to a product or variant and its stock
+	LookupBarcode(context.Context, *LookupBarcodeRequest) (*LookupBarcodeResponse, error)
+	// ListStockLevels lists stock levels per product, variant and store
+	ListStockLevels(context.Context, *ListStockLevelsRequest) (*ListStockLevelsResponse, error)
+	mustEmbedUnimplementedBladeServiceServer()
+}
+
+// UnimplementedBladeServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBladeServiceServer struct{}
+
+func (UnimplementedBladeServiceServer) GetUser(context.Context, *GetUserRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
+}
+func (UnimplementedBladeServiceServer) IntrospectToken(context.Context, *IntrospectTokenRequest) (*IntrospectTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IntrospectToken not implemented")
+}
+func (UnimplementedBladeServiceServer) GetProduct(context.Context, *GetProductRequest) (*Product, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProduct not implemented")
+}
+func (UnimplementedBladeServiceServer) LookupBarcode(context.Context, *LookupBarcodeRequest) (*LookupBarcodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LookupBarcode not implemented")
+}
+func (UnimplementedBladeServiceServer) ListStockLevels(context.Context, *ListStockLevelsRequest) (*ListStockLevelsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListStockLevels not implemented")
+}
+func (UnimplementedBladeServiceServer) mustEmbedUnimplementedBladeServiceServer() {}
+func (UnimplementedBladeServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeBladeServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BladeServiceServer will
+// result in compilation errors.
+type UnsafeBladeServiceServer interface {
+	mustEmbedUnimplementedBladeServiceServer()
+}
+
+func RegisterBladeServiceServer(s grpc.ServiceRegistrar, srv BladeServiceServer) {
+	// If the following call pancis, it indicates UnimplementedBladeServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BladeService_ServiceDesc, srv)
+}
+
+func _BladeService_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BladeServiceServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BladeService_GetUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BladeServiceServer).GetUser(ctx, req.(*GetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BladeService_IntrospectToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IntrospectTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BladeServiceServer).IntrospectToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BladeService_IntrospectToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BladeServiceServer).IntrospectToken(ctx, req.(*IntrospectTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BladeService_GetProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BladeServiceServer).GetProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BladeService_GetProduct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BladeServiceServer).GetProduct(ctx, req.(*GetProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BladeService_LookupBarcode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupBarcodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BladeServiceServer).LookupBarcode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BladeService_LookupBarcode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BladeServiceServer).LookupBarcode(ctx, req.(*LookupBarcodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BladeService_ListStockLevels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStockLevelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BladeServiceServer).ListStockLevels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BladeService_ListStockLevels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BladeServiceServer).ListStockLevels(ctx, req.(*ListStockLevelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BladeService_ServiceDesc is the grpc.ServiceDesc for BladeService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BladeService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "blade.v1.BladeService",
+	HandlerType: (*BladeServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetUser",
+			Handler:    _BladeService_GetUser_Handler,
+		},
+		{
+			MethodName: "IntrospectToken",
+			Handler:    _BladeService_IntrospectToken_Handler,
+		},
+		{
+			MethodName: "GetProduct",
+			Handler:    _BladeService_GetProduct_Handler,
+		},
+		{
+			MethodName: "LookupBarcode",
+			Handler:    _BladeService_LookupBarcode_Handler,
+		},
+		{
+			MethodName: "ListStockLevels",
+			Handler:    _BladeService_ListStockLevels_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "blade.proto",
+}
@@ -0,0 +1,5 @@
+// Package bladev1 is the gRPC API internal services call: the BladeService client and server
+// generated from blade.proto. Services in other modules import it to get a typed client.
+package bladev1
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative blade.proto
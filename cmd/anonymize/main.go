@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"os"
+
+	"github.com/Aebroyx/the-blade-api/internal/anonymize"
+	"github.com/Aebroyx/the-blade-api/internal/config"
+	"github.com/Aebroyx/the-blade-api/internal/database"
+	"github.com/Aebroyx/the-blade-api/internal/logger"
+	"github.com/Aebroyx/the-blade-api/internal/password"
+)
+
+// anonymize scrambles personal data in the configured database so staging environments
+// can run on realistic volumes without real customer data.
+//
+// Usage:
+//
+//	go run ./cmd/anonymize -salt <secret> [-dry-run] [-batch-size 500] [-password <staging password>]
+func main() {
+	salt := flag.String("salt", os.Getenv("ANONYMIZE_SALT"), "secret used to derive deterministic fake values (or ANONYMIZE_SALT)")
+	dryRun := flag.Bool("dry-run", false, "only count the rows that would be changed")
+	batchSize := flag.Int("batch-size", 500, "number of rows processed per query")
+	newPassword := flag.String("password", "", "reset every user's password to this value")
+	flag.Parse()
+
+	if *salt == "" {
+		logger.Fatal("A salt is required: pass -salt or set ANONYMIZE_SALT")
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Failed to load configuration", "error", err)
+	}
+
+	// Log structured JSON at the configured level, as the API does
+	if err := logger.Setup(cfg.LogLevel); err != nil {
+		logger.Fatal("Invalid log level", "error", err)
+	}
+
+	// Never scramble a production database
+	if cfg.Environment == "production" {
+		logger.Fatal("Refusing to anonymize data while APP_ENV is production")
+	}
+
+	// Initialize database
+	db, err := database.NewConnection(cfg)
+	if err != nil {
+		logger.Fatal("Failed to connect to database", "error", err)
+	}
+
+	anonymizer := anonymize.NewAnonymizer(db.DB, anonymize.NewFaker(*salt), *batchSize, *dryRun)
+	results, err := anonymizer.Run(anonymize.DefaultTables)
+	if err != nil {
+		logger.Fatal("Anonymization failed", "error", err)
+	}
+
+	if *newPassword != "" && !*dryRun {
+		passwords, err := password.New(cfg.PasswordHasher)
+		if err != nil {
+			logger.Fatal("Failed to initialize password hashing", "error", err)
+		}
+		hashedPassword, err := passwords.Hash(*newPassword)
+		if err != nil {
+			logger.Fatal("Failed to hash password", "error", err)
+		}
+		if err := db.Exec("UPDATE users SET password = ?", hashedPassword).Error; err != nil {
+			logger.Fatal("Failed to reset passwords", "error", err)
+		}
+		slog.Info("Reset all user passwords")
+	}
+
+	for _, result := range results {
+		if *dryRun {
+			slog.Info("Rows would be anonymized", "table", result.Table, "rows", result.Rows, "dry_run", true)
+		} else {
+			slog.Info("Rows anonymized", "table", result.Table, "rows", result.Rows)
+		}
+	}
+
+	if !*dryRun {
+		slog.Info("Done. Flush the Redis user cache (user:*) if this environment uses Redis.")
+	}
+}
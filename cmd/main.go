@@ -3,15 +3,41 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
 
+	"github.com/Aebroyx/the-blade-api/internal/apiversion"
+	"github.com/Aebroyx/the-blade-api/internal/cache"
 	"github.com/Aebroyx/the-blade-api/internal/config"
+	"github.com/Aebroyx/the-blade-api/internal/contract"
 	"github.com/Aebroyx/the-blade-api/internal/database"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/graph"
 	"github.com/Aebroyx/the-blade-api/internal/handlers"
+	"github.com/Aebroyx/the-blade-api/internal/jobs"
+	"github.com/Aebroyx/the-blade-api/internal/license"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/Aebroyx/the-blade-api/internal/logger"
+	"github.com/Aebroyx/the-blade-api/internal/mailer"
+	"github.com/Aebroyx/the-blade-api/internal/metrics"
 	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/password"
+	"github.com/Aebroyx/the-blade-api/internal/payments"
+	"github.com/Aebroyx/the-blade-api/internal/ratelimit"
+	"github.com/Aebroyx/the-blade-api/internal/realtime"
+	"github.com/Aebroyx/the-blade-api/internal/rpc"
 	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/Aebroyx/the-blade-api/internal/slo"
+	"github.com/Aebroyx/the-blade-api/internal/storage"
+	"github.com/Aebroyx/the-blade-api/internal/stripe"
+	"github.com/Aebroyx/the-blade-api/internal/tracing"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
+	"github.com/uptrace/opentelemetry-go-extra/otelgorm"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func main() {
@@ -21,121 +47,968 @@ func main() {
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		logger.Fatal("Failed to load configuration", "error", err)
 	}
 
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
-		log.Fatalf("Invalid configuration: %v", err)
+		logger.Fatal("Invalid configuration", "error", err)
+	}
+
+	// Log structured JSON at the configured level
+	if err := logger.Setup(cfg.LogLevel); err != nil {
+		logger.Fatal("Invalid log level", "error", err)
+	}
+
+	// Apply deployment limits
+	if err := limits.Configure(cfg.Limits); err != nil {
+		logger.Fatal("Invalid limits configuration", "error", err)
+	}
+
+	// Initialize tracing; spans of requests, statements and Redis commands are exported to the
+	// OTLP collector when one is configured
+	tracingEnabled := cfg.TracingEndpoint != ""
+	if tracingEnabled {
+		shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+			Endpoint:    cfg.TracingEndpoint,
+			ServiceName: cfg.TracingServiceName,
+			SampleRate:  cfg.TracingSampleRate,
+		})
+		if err != nil {
+			logger.Fatal("Failed to set up tracing", "error", err)
+		}
+		defer shutdownTracing(context.Background())
 	}
 
 	// Initialize database
 	db, err := database.NewConnection(cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		logger.Fatal("Failed to connect to database", "error", err)
+	}
+	if tracingEnabled {
+		if err := db.Use(otelgorm.NewPlugin(otelgorm.WithoutQueryVariables(), otelgorm.WithoutMetrics())); err != nil {
+			logger.Fatal("Failed to set up database tracing", "error", err)
+		}
 	}
 
-	// Initialize Redis client
-	var redisClient *redis.Client
+	// Initialize Redis cache. If Redis is down the cache runs degraded and
+	// switches back on its own once the health check succeeds again.
+	appCache := cache.Disabled()
 	if cfg.UseRedis {
-		redisClient = redis.NewClient(&redis.Options{
+		redisClient := redis.NewClient(&redis.Options{
 			Addr:     fmt.Sprintf("%s:%s", cfg.RedisHost, cfg.RedisPort),
 			Password: cfg.RedisPassword,
 			DB:       cfg.RedisDB,
 		})
+		if tracingEnabled {
+			if err := redisotel.InstrumentTracing(redisClient); err != nil {
+				logger.Fatal("Failed to set up Redis tracing", "error", err)
+			}
+		}
 
-		// Test Redis connection
-		if err := redisClient.Ping(ctx).Err(); err != nil {
-			log.Printf("Warning: Failed to connect to Redis: %v. Running without Redis caching.", err)
-			redisClient = nil
+		appCache = cache.New(ctx, redisClient, cache.Policy{RateLimit: cfg.RedisDegradedRateLimit})
+		if appCache.Available() {
+			slog.Info("Connected to Redis", "host", cfg.RedisHost, "port", cfg.RedisPort)
 		} else {
-			log.Printf("Successfully connected to Redis at %s:%s", cfg.RedisHost, cfg.RedisPort)
+			slog.Warn("Failed to connect to Redis, starting in degraded mode", "host", cfg.RedisHost, "port", cfg.RedisPort)
 		}
+		appCache.EnableVerification(cfg.CacheVerifySampleRate)
+		go appCache.Monitor(ctx, cfg.RedisHealthInterval)
+	}
+
+	// Rate limit buckets live in Redis, or in memory without it
+	rateLimiter := ratelimit.NewLimiter(appCache)
+
+	// Initialize metrics: requests are recorded by the metrics middleware; the Go runtime,
+	// process, connection pool and cache metrics are read when Prometheus scrapes
+	metricsRegistry := metrics.NewRegistry()
+	if err := db.RegisterMetrics(metricsRegistry); err != nil {
+		logger.Fatal("Failed to initialize metrics", "error", err)
+	}
+	appCache.RegisterMetrics(metricsRegistry)
+	httpMetrics := metrics.NewHTTP(metricsRegistry)
+
+	// Initialize mailer
+	mail := mailer.New(cfg)
+
+	// Initialize password hashing; config validation rejects unknown algorithms
+	passwords, err := password.New(cfg.PasswordHasher)
+	if err != nil {
+		logger.Fatal("Failed to initialize password hashing", "error", err)
+	}
+
+	// Initialize file storage
+	files, err := storage.NewLocal(cfg.StorageDir, cfg.StoragePublicURL)
+	if err != nil {
+		logger.Fatal("Failed to initialize file storage", "error", err)
 	}
 
 	// Initialize services
-	userService := services.NewUserService(db.DB, cfg, redisClient)
+	activityService := services.NewActivityService(db.DB)
+	presenceService := services.NewPresenceService(db.DB, appCache, cfg.PresenceTTL)
+	changeFeedService := services.NewChangeFeedService(db.DB, cfg.ChangeFeedRetention)
+	notificationService := services.NewNotificationService(db.DB, mail)
+	userService := services.NewUserService(db.DB, cfg, appCache, passwords, activityService, notificationService, presenceService, changeFeedService)
+	tenantService := services.NewTenantService(db.DB)
+	tenantSignupService := services.NewTenantSignupService(db.DB, cfg, passwords, tenantService, activityService, notificationService)
+	subscriptionService := services.NewSubscriptionService(db.DB, appCache, cfg.StripeWebhookSecret)
+	teamService := services.NewTeamService(db.DB)
+	deviceService := services.NewDeviceService(db.DB)
+	userSettingsService := services.NewUserSettingsService(db.DB)
+	deviceConfigService := services.NewDeviceConfigService(db.DB)
+	printJobService := services.NewPrintJobService(db.DB)
+	reportService := services.NewReportService(db.DB)
+	reportScheduleService := services.NewReportScheduleService(db.DB, reportService, notificationService)
+	analyticsService := services.NewAnalyticsService(db.DB, appCache)
+	userPurgeService := services.NewUserPurgeService(db.DB, cfg, appCache, changeFeedService)
+	experimentService := services.NewExperimentService(db.DB)
+	settingService := services.NewSettingService(db.DB, appCache, changeFeedService)
+	tagService := services.NewTagService(db.DB, activityService, changeFeedService)
+	permissionService := services.NewPermissionService(db.DB, appCache, cfg.AuthClaimsMode)
+	categoryService := services.NewCategoryService(db.DB, changeFeedService)
+	productService := services.NewProductService(db.DB, appCache, changeFeedService)
+	storageQuotaService := services.NewStorageQuotaService(db.DB, models.StorageQuotas{
+		TenantBytes: cfg.StorageTenantQuota,
+		StoreBytes:  cfg.StorageStoreQuota,
+	})
+	productImageService := services.NewProductImageService(db.DB, files, productService, storageQuotaService)
+	inventoryService := services.NewInventoryService(db.DB, cfg, activityService, notificationService)
+	promotionService := services.NewPromotionService(db.DB)
+	orderService := services.NewOrderService(db.DB, promotionService, activityService)
+	orderService.OnTransition(inventoryService.OrderStockHook)
+	orderService.AfterTransition(notificationService.OrderStatusHook)
+	loyaltyService := services.NewLoyaltyService(db.DB, cfg.LoyaltyPointValue)
+	orderService.OnTransition(loyaltyService.OrderLoyaltyHook)
+	giftCardService := services.NewGiftCardService(db.DB)
+	orderService.OnTransition(giftCardService.OrderGiftCardHook)
+	kitchenService := services.NewKitchenService(db.DB, changeFeedService, notificationService)
+	orderService.AfterTransition(kitchenService.OrderKitchenHook)
+	orderService.AfterTransition(inventoryService.OrderStockChangedHook)
+	// Domain events are pushed to WebSocket clients on every instance
+	realtimeHub := realtime.NewHub(appCache)
+	orderService.AfterTransition(realtimeHub.OrderCreatedHook)
+	inventoryService.AfterStockChange(realtimeHub.StockChangedHook)
+	changeFeedService.AfterPublish(realtimeHub.UserUpdatedHook)
+	printJobService.AfterChange(realtimeHub.PrintJobChangedHook)
+	deviceConfigService.AfterUpdate(realtimeHub.TerminalConfigUpdatedHook)
+	couponService := services.NewCouponService(db.DB, appCache)
+	taxService := services.NewTaxService(db.DB)
+	unitService := services.NewUnitService(db.DB)
+	customerService := services.NewCustomerService(db.DB)
+	supplierService := services.NewSupplierService(db.DB)
+	storeService := services.NewStoreService(db.DB)
+	transferService := services.NewTransferService(db.DB, inventoryService)
+	stocktakeService := services.NewStocktakeService(db.DB, inventoryService)
+	cartService := services.NewCartService(db.DB, appCache, orderService, couponService)
+	tableService := services.NewTableService(db.DB)
+	importService := services.NewImportService(db.DB, productService, storageQuotaService)
+
+	// Payment providers, one per payment method
+	paymentProviders := payments.NewRegistry()
+	paymentProviders.Register(payments.MethodCash, payments.Cash{})
+	paymentProviders.Register(payments.MethodCard, payments.ExternalTerminal{})
+	paymentProviders.Register(payments.MethodLoyalty, payments.Loyalty{})
+	paymentProviders.Register(payments.MethodGiftCard, payments.GiftCard{})
+	if cfg.StripeSecretKey != "" {
+		paymentProviders.Register(payments.MethodStripe, payments.NewStripe(stripe.NewClient(cfg.StripeSecretKey), cfg.StripeCurrency))
+	}
+	cashDrawerService := services.NewCashDrawerService(db.DB, activityService)
+	shiftService := services.NewShiftService(db.DB, cashDrawerService, activityService)
+	paymentService := services.NewPaymentService(db.DB, orderService, loyaltyService, giftCardService, paymentProviders, activityService, cfg.StripePaymentsWebhookSecret)
+	returnService := services.NewReturnService(db.DB, orderService, inventoryService, paymentService, activityService)
+	returnService.OnReturn(loyaltyService.ReturnLoyaltyHook)
+	returnService.OnReturn(giftCardService.ReturnGiftCardHook)
+	receiptService := services.NewReceiptService(db.DB, settingService, notificationService)
+	invoiceService := services.NewInvoiceService(db.DB, orderService, receiptService)
+	quoteService := services.NewQuoteService(db.DB, orderService, receiptService, notificationService)
+	scimService := services.NewSCIMService(db.DB, userService, teamService, cfg.SCIMAdminGroups)
+	quotaService := services.NewQuotaService(db.DB, appCache, cfg.APIQuotas, cfg.APIQuotaWindow)
+	sloTracker := slo.NewTracker(cfg.SLOObjectives, cfg.SLOWindow, cfg.SLOAlertBurnRate)
+	sloService := services.NewSLOService(db.DB, cfg, sloTracker, notificationService)
+	healthService := services.NewHealthService(db.DB, appCache)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(userService)
 	userHandler := handlers.NewUserHandler(userService)
+	teamHandler := handlers.NewTeamHandler(teamService)
+	deviceHandler := handlers.NewDeviceHandler(deviceService)
+	tenantHandler := handlers.NewTenantHandler(tenantService)
+	tenantSignupHandler := handlers.NewTenantSignupHandler(tenantSignupService)
+	subscriptionHandler := handlers.NewSubscriptionHandler(subscriptionService)
+	userSettingsHandler := handlers.NewUserSettingsHandler(userSettingsService)
+	deviceConfigHandler := handlers.NewDeviceConfigHandler(deviceConfigService)
+	activityHandler := handlers.NewActivityHandler(activityService)
+	printJobHandler := handlers.NewPrintJobHandler(printJobService)
+	reportHandler := handlers.NewReportHandler(reportService)
+	reportScheduleHandler := handlers.NewReportScheduleHandler(reportScheduleService)
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService)
+	userPurgeHandler := handlers.NewUserPurgeHandler(userPurgeService)
+	experimentHandler := handlers.NewExperimentHandler(experimentService)
+	settingHandler := handlers.NewSettingHandler(settingService)
+	changeFeedHandler := handlers.NewChangeFeedHandler(changeFeedService)
+	tagHandler := handlers.NewTagHandler(tagService)
+	roleHandler := handlers.NewRoleHandler(userService, permissionService)
+	quotaHandler := handlers.NewQuotaHandler(quotaService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	categoryHandler := handlers.NewCategoryHandler(categoryService)
+	productHandler := handlers.NewProductHandler(productService)
+	productImageHandler := handlers.NewProductImageHandler(productImageService)
+	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+	transferHandler := handlers.NewTransferHandler(transferService)
+	stocktakeHandler := handlers.NewStocktakeHandler(stocktakeService)
+	orderHandler := handlers.NewOrderHandler(orderService)
+	cartHandler := handlers.NewCartHandler(cartService)
+	tableHandler := handlers.NewTableHandler(tableService, cartService)
+	kitchenHandler := handlers.NewKitchenHandler(kitchenService)
+	promotionHandler := handlers.NewPromotionHandler(promotionService)
+	couponHandler := handlers.NewCouponHandler(couponService)
+	taxHandler := handlers.NewTaxHandler(taxService)
+	unitHandler := handlers.NewUnitHandler(unitService)
+	customerHandler := handlers.NewCustomerHandler(customerService)
+	loyaltyHandler := handlers.NewLoyaltyHandler(loyaltyService)
+	giftCardHandler := handlers.NewGiftCardHandler(giftCardService)
+	supplierHandler := handlers.NewSupplierHandler(supplierService)
+	storeHandler := handlers.NewStoreHandler(storeService)
+	importHandler := handlers.NewImportHandler(importService)
+	paymentHandler := handlers.NewPaymentHandler(paymentService, paymentProviders)
+	returnHandler := handlers.NewReturnHandler(returnService)
+	receiptHandler := handlers.NewReceiptHandler(receiptService)
+	invoiceHandler := handlers.NewInvoiceHandler(invoiceService)
+	quoteHandler := handlers.NewQuoteHandler(quoteService)
+	cashDrawerHandler := handlers.NewCashDrawerHandler(cashDrawerService)
+	shiftHandler := handlers.NewShiftHandler(shiftService)
+	sloHandler := handlers.NewSLOHandler(sloService)
+	scimHandler := handlers.NewSCIMHandler(scimService)
+	readOnlyMode := middleware.NewReadOnlyMode(cfg.ReadOnlyMode, cfg.ReadOnlyReason)
+	licenseManager := license.NewManager(cfg.LicenseFile)
+	systemHandler := handlers.NewSystemHandler(appCache, readOnlyMode, licenseManager, storageQuotaService)
+	healthHandler := handlers.NewHealthHandler(healthService)
+	metricsHandler := handlers.NewMetricsHandler(metricsRegistry, cfg.MetricsToken)
+	graphqlHandler := handlers.NewGraphQLHandler(graph.NewSchema(userService, productService, orderService, reportService, permissionService))
+	realtimeHandler := handlers.NewRealtimeHandler(realtimeHub, permissionService, storeService, cfg.CORSAllowedOrigins)
+
+	// Start background jobs
+	if cfg.UserPurgeEnabled {
+		go jobs.Every(ctx, "user purge", cfg.UserPurgeInterval, userPurgeService.RunScheduledPurge)
+	}
+	go jobs.Every(ctx, "change feed prune", time.Hour, changeFeedService.Prune)
+	go realtimeHub.Run(ctx)
+	go jobs.Every(ctx, "product image prune", time.Hour, productImageService.PruneOrphans)
+	go jobs.Every(ctx, "low stock check", cfg.LowStockCheckInterval, inventoryService.CheckLowStock)
+	go jobs.Every(ctx, "imports", 30*time.Second, importService.ProcessImports)
+	go jobs.Every(ctx, "receipt emails", 10*time.Second, receiptService.SendReceiptEmails)
+	go jobs.Every(ctx, "quote expiry", time.Hour, quoteService.ExpireQuotes)
+	go jobs.Every(ctx, "report schedules", time.Minute, reportScheduleService.RunDueSchedules)
+	if cfg.StripePaymentsWebhookSecret != "" {
+		go jobs.Every(ctx, "payment events", 5*time.Second, paymentService.ProcessPaymentEvents)
+	}
+	if sloTracker.Enabled() {
+		go jobs.Every(ctx, "slo check", cfg.SLOCheckInterval, sloService.CheckBurnRates)
+	}
+	if cfg.LicenseFile != "" {
+		go jobs.Every(ctx, "license reload", cfg.LicenseReloadInterval, licenseManager.Refresh)
+	}
 
 	// Initialize router
 	router := gin.New() // Use gin.New() instead of gin.Default() to avoid default middleware
+	metaHandler := handlers.NewMetaHandler(router.Routes)
 
-	// Add logger middleware
-	router.Use(gin.Logger())
+	// Liveness and readiness probes and the metrics scrape come before all middleware: they
+	// aren't logged or measured, need no tenant and don't count against any plan
+	router.GET("/healthz", healthHandler.Liveness)
+	router.GET("/readyz", healthHandler.Readiness)
+	router.GET("/metrics", metricsHandler.GetMetrics)
 
-	// Add CORS middleware
-	router.Use(func(c *gin.Context) {
-		// Log incoming request
-		log.Printf("Incoming request: %s %s", c.Request.Method, c.Request.URL.Path)
+	// Give every other request an ID to find its log lines by
+	router.Use(middleware.RequestID())
 
-		// Get allowed origins from config
-		allowedOrigin := cfg.CORSAllowedOrigins
-		if allowedOrigin == "" {
-			allowedOrigin = "http://localhost:3001" // fallback
-		}
+	// Count and time it, and trace it when tracing is on
+	router.Use(middleware.Metrics(httpMetrics))
+	if tracingEnabled {
+		router.Use(otelgin.Middleware(cfg.TracingServiceName))
+	}
 
-		// Set CORS headers
-		c.Writer.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Max-Age", "86400") // 24 hours
-
-		// Handle preflight
-		if c.Request.Method == "OPTIONS" {
-			log.Printf("Handling OPTIONS request for: %s", c.Request.URL.Path)
-			c.AbortWithStatus(204)
-			return
-		}
+	// Log each request with its status, latency and caller
+	router.Use(middleware.RequestLogger())
 
-		c.Next()
-	})
+	// Time requests against the response time objectives of their route groups
+	if sloTracker.Enabled() {
+		router.Use(middleware.SLO(sloTracker))
+	}
+
+	// Report requests running more SQL statements than the budget
+	if cfg.SQLStatementBudget > 0 {
+		router.Use(middleware.StatementBudget(cfg.SQLStatementBudget))
+	}
+
+	// Resolve the tenant from the Host header, then apply its CORS profile
+	router.Use(middleware.Tenant(tenantService))
+	router.Use(middleware.CORS(cfg.CORSAllowedOrigins))
+	// Limit each client's requests, after CORS so preflights aren't counted and rejections
+	// carry CORS headers; route groups add tighter limits of their own
+	router.Use(middleware.RateLimit(rateLimiter, cfg.RateLimitGlobal))
 
-	// Public routes
-	public := router.Group("/api")
-	{
-		// Auth routes
-		auth := public.Group("/auth")
+	// Enforce the tenant's subscription and daily API call limit
+	router.Use(middleware.Plan(subscriptionService, appCache.Policy()))
+
+	// Reject oversized request bodies; import files and images may be larger. Routes are given
+	// without their API version.
+	router.Use(middleware.BodyLimit(map[string]string{
+		"/api/imports":             limits.MaxImportBytes,
+		"/api/products/import":     limits.MaxImportBytes,
+		"/api/products/:id/images": limits.MaxImageUploadBytes,
+	}))
+
+	// Reject mutating requests while the API is read-only
+	router.Use(middleware.ReadOnly(readOnlyMode))
+
+	// Resolve the terminal that sent the request, if any
+	router.Use(middleware.Device(db.DB))
+
+	// SCIM provisioning routes, authenticated with the SCIM token
+	if cfg.SCIMToken != "" {
+		scim := router.Group("/scim/v2", middleware.SCIMAuth(cfg.SCIMToken))
 		{
-			auth.POST("/register", authHandler.Register)
-			auth.POST("/login", authHandler.Login)
+			scim.GET("/ServiceProviderConfig", scimHandler.GetServiceProviderConfig)
+			scim.GET("/Users", scimHandler.GetUsers)
+			scim.POST("/Users", scimHandler.CreateUser)
+			scim.GET("/Users/:id", scimHandler.GetUser)
+			scim.PUT("/Users/:id", scimHandler.ReplaceUser)
+			scim.PATCH("/Users/:id", scimHandler.PatchUser)
+			scim.DELETE("/Users/:id", scimHandler.DeleteUser)
+			scim.GET("/Groups", scimHandler.GetGroups)
+			scim.POST("/Groups", scimHandler.CreateGroup)
+			scim.GET("/Groups/:id", scimHandler.GetGroup)
+			scim.PUT("/Groups/:id", scimHandler.ReplaceGroup)
+			scim.PATCH("/Groups/:id", scimHandler.PatchGroup)
+			scim.DELETE("/Groups/:id", scimHandler.DeleteGroup)
 		}
 	}
 
-	// Protected routes
-	protected := router.Group("/api")
-
-	// Use appropriate auth middleware based on Redis availability
-	if redisClient != nil {
-		protected.Use(middleware.Auth(cfg.JWTSecret, db.DB, redisClient))
-		log.Println("Using Redis-enabled auth middleware")
+	// Protected routes sign the user in, with the auth middleware matching the Redis
+	// configuration, then apply their quota and store
+	var authMiddleware gin.HandlerFunc
+	if cfg.UseRedis {
+		authMiddleware = middleware.Auth(cfg.JWTSecret, cfg.AuthClaimsMode, db.DB, appCache, presenceService)
+		slog.Info("Using Redis-enabled auth middleware")
 	} else {
-		protected.Use(middleware.AuthWithoutRedis(cfg.JWTSecret, db.DB))
-		log.Println("Using database-only auth middleware")
+		authMiddleware = middleware.AuthWithoutRedis(cfg.JWTSecret, db.DB)
+		slog.Info("Using database-only auth middleware")
+	}
+	protect := []gin.HandlerFunc{
+		authMiddleware,
+		middleware.UserQuota(quotaService, appCache.Policy()),
+		middleware.Store(storeService, licenseManager),
 	}
+	// Orders looked up by ID must be of a store the request may see
+	orderStore := middleware.StoreRecord("Order", orderService.OrderStore)
 
-	{
-		// AUTH ROUTES
-		protected.GET("/me", authHandler.GetMe)
-		protected.POST("/auth/logout", authHandler.Logout)
-		// USER ROUTES
-		protected.GET("/users", userHandler.GetAllUsers)
-		user := protected.Group("/user")
+	// Version 1 of the API
+	v1 := apiversion.NewRegistrar("v1", func(public *gin.RouterGroup, protected *gin.RouterGroup) {
+		// Public routes
 		{
-			user.GET("/:id", userHandler.GetUserById)
-			user.POST("/create", userHandler.CreateUser)
-			user.PUT("/:id", userHandler.UpdateUser)
-			user.DELETE("/:id", userHandler.DeleteUser)
-			user.PUT("/:id/soft-delete", userHandler.SoftDeleteUser)
+			// Auth routes
+			auth := public.Group("/auth", middleware.RateLimit(rateLimiter, cfg.RateLimitAuth))
+			{
+				auth.POST("/register", middleware.PlanCapacity(subscriptionService, models.PlanResourceUsers), authHandler.Register)
+				auth.POST("/login", authHandler.Login)
+				auth.POST("/confirm-email", authHandler.ConfirmEmail)
+				auth.POST("/reset-password", authHandler.ResetPassword)
+				auth.GET("/check-availability",
+					middleware.RateLimit(rateLimiter, ratelimit.Rule{Name: "check-availability", Limit: 20, Period: time.Minute}),
+					middleware.OptionalAuth(cfg.JWTSecret, db.DB),
+					authHandler.CheckAvailability)
+			}
+
+			// Route and DTO registry for client SDK generators
+			public.GET("/meta/contract", metaHandler.GetContract)
+
+			// Uploaded files, unless they are served from elsewhere
+			public.Static("/uploads", cfg.StorageDir)
+
+			// Device pairing is done by the terminal itself before any user signs in
+			public.POST("/devices/pair", deviceHandler.PairDevice)
+			// Terminals fetch their configuration with the device token only
+			public.GET("/terminal/config", deviceConfigHandler.GetTerminalConfig)
+			public.GET("/terminal/print-jobs/next", printJobHandler.ClaimNextPrintJob)
+			public.POST("/terminal/print-jobs/:id/ack", printJobHandler.AckPrintJob)
+			public.GET("/terminal/experiments", experimentHandler.GetTerminalExperiments)
+			public.GET("/terminal/ws", realtimeHandler.ConnectTerminal)
+			// Self-serve tenant signup, off unless explicitly enabled
+			if cfg.TenantSignupEnabled {
+				public.POST("/tenants/signup", tenantSignupHandler.Signup)
+				public.POST("/tenants/signup/verify", tenantSignupHandler.VerifySignup)
+				public.GET("/tenants/signup/status", tenantSignupHandler.GetProvisioningStatus)
+			}
+			// Stripe Billing webhook, authenticated by its signature
+			if cfg.StripeWebhookSecret != "" {
+				public.POST("/billing/stripe/webhook", subscriptionHandler.StripeWebhook)
+			}
+			// Stripe payments webhook, authenticated by its signature; events are applied in the background
+			if cfg.StripePaymentsWebhookSecret != "" {
+				public.POST("/webhooks/stripe", paymentHandler.StripeWebhook)
+			}
+		}
+
+		// Protected routes
+		{
+			// AUTH ROUTES
+			protected.GET("/me", authHandler.GetMe)
+			protected.PUT("/me/password", authHandler.ChangePassword)
+			protected.GET("/me/settings", userSettingsHandler.GetMySettings)
+			protected.PUT("/me/settings", userSettingsHandler.UpdateMySettings)
+			protected.GET("/me/notification-preferences", notificationHandler.GetMyNotificationPreferences)
+			protected.PUT("/me/notification-preferences", notificationHandler.UpdateMyNotificationPreferences)
+			protected.GET("/me/notifications", notificationHandler.GetMyNotifications)
+			protected.PUT("/me/notifications/read", notificationHandler.MarkAllMyNotificationsRead)
+			protected.PUT("/me/notifications/:id/read", notificationHandler.MarkMyNotificationRead)
+			protected.GET("/me/activity", activityHandler.GetMyActivity)
+			protected.GET("/me/experiments", experimentHandler.GetMyExperiments)
+			protected.POST("/auth/logout", authHandler.Logout)
+			// USER ROUTES
+			protected.GET("/users", middleware.RequirePermission(permissionService, models.PermissionUsersView), userHandler.GetAllUsers)
+			protected.GET("/users/export", middleware.RequirePermission(permissionService, models.PermissionUsersView), userHandler.ExportUsers)
+			protected.GET("/users/online", middleware.RequirePermission(permissionService, models.PermissionUsersOnline), userHandler.GetOnlineUsers)
+			protected.GET("/users/deleted", middleware.RequireRole(models.RoleAdmin), userHandler.GetDeletedUsers)
+			protected.POST("/users/bulk", middleware.RequireRole(models.RoleAdmin), userHandler.BulkUpdateUsers)
+			protected.POST("/users/merge", middleware.RequireRole(models.RoleAdmin), userHandler.MergeUsers)
+			protected.POST("/users/purge", middleware.RequireRole(models.RoleAdmin), userPurgeHandler.PurgeUsers)
+			user := protected.Group("/user")
+			{
+				user.GET("/:id", middleware.RequirePermission(permissionService, models.PermissionUsersView), userHandler.GetUserById)
+				user.POST("/create", middleware.PlanCapacity(subscriptionService, models.PlanResourceUsers), userHandler.CreateUser)
+				user.PUT("/:id", userHandler.UpdateUser)
+				user.DELETE("/:id", userHandler.DeleteUser)
+				user.PUT("/:id/soft-delete", userHandler.SoftDeleteUser)
+				user.GET("/:id/activity", middleware.RequirePermission(permissionService, models.PermissionActivityView), activityHandler.GetUserActivity)
+				user.GET("/:id/logins", middleware.RequirePermission(permissionService, models.PermissionActivityView), activityHandler.GetUserLogins)
+				user.PUT("/:id/restore", middleware.RequireRole(models.RoleAdmin), userHandler.RestoreUser)
+				user.PUT("/:id/suspend", middleware.RequireRole(models.RoleAdmin), userHandler.SuspendUser)
+				user.PUT("/:id/reactivate", middleware.RequireRole(models.RoleAdmin), userHandler.ReactivateUser)
+				user.POST("/:id/reset-password", middleware.RequireRole(models.RoleAdmin), userHandler.ResetUserPassword)
+				user.POST("/:id/tags", middleware.RequirePermission(permissionService, models.PermissionUsersTag), tagHandler.AssignUserTags)
+				user.DELETE("/:id/tags/:tagId", middleware.RequirePermission(permissionService, models.PermissionUsersTag), tagHandler.RemoveUserTag)
+				user.GET("/:id/quota", middleware.RequireRole(models.RoleAdmin), quotaHandler.GetUserQuota)
+				user.PUT("/:id/quota", middleware.RequireRole(models.RoleAdmin), quotaHandler.UpdateUserQuota)
+				user.POST("/:id/quota/reset", middleware.RequireRole(models.RoleAdmin), quotaHandler.ResetUserQuota)
+				user.GET("/:id/stores", middleware.RequireRole(models.RoleAdmin), middleware.RequireFeature(licenseManager, license.FeatureMultiStore), storeHandler.GetUserStores)
+				user.PUT("/:id/stores", middleware.RequireRole(models.RoleAdmin), middleware.RequireFeature(licenseManager, license.FeatureMultiStore), storeHandler.SetUserStores)
+			}
+			// TAG ROUTES
+			tags := protected.Group("/tags")
+			{
+				tags.GET("", tagHandler.GetAllTags)
+				tags.POST("", middleware.RequireRole(models.RoleAdmin), tagHandler.CreateTag)
+				tags.PUT("/:id", middleware.RequireRole(models.RoleAdmin), tagHandler.UpdateTag)
+				tags.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), tagHandler.DeleteTag)
+			}
+			// CATEGORY ROUTES
+			categories := protected.Group("/categories")
+			{
+				categories.GET("", categoryHandler.GetCategoryTree)
+				categories.GET("/:id", categoryHandler.GetCategoryById)
+				categories.POST("", middleware.RequireRole(models.RoleAdmin), categoryHandler.CreateCategory)
+				categories.PUT("/reorder", middleware.RequireRole(models.RoleAdmin), categoryHandler.ReorderCategories)
+				categories.PUT("/:id", middleware.RequireRole(models.RoleAdmin), categoryHandler.UpdateCategory)
+				categories.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), categoryHandler.DeleteCategory)
+			}
+
+			// PRODUCT ROUTES
+			products := protected.Group("/products")
+			{
+				products.GET("", productHandler.GetAllProducts)
+				products.GET("/barcode/:code", productHandler.LookupBarcode)
+				products.GET("/export", middleware.RequireRole(models.RoleAdmin), productHandler.ExportProducts)
+				products.POST("/import", middleware.RequireRole(models.RoleAdmin), importHandler.ImportProducts)
+				products.GET("/:id", productHandler.GetProductById)
+				products.POST("", middleware.RequireRole(models.RoleAdmin), productHandler.CreateProduct)
+				products.PUT("/:id", middleware.RequireRole(models.RoleAdmin), productHandler.UpdateProduct)
+				products.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), productHandler.DeleteProduct)
+				products.GET("/:id/variants", productHandler.GetProductVariants)
+				products.PUT("/:id/options", middleware.RequireRole(models.RoleAdmin), productHandler.SetProductOptions)
+				products.POST("/:id/variants/generate", middleware.RequireRole(models.RoleAdmin), productHandler.GenerateProductVariants)
+				products.PUT("/:id/variants/:variantId", middleware.RequireRole(models.RoleAdmin), productHandler.UpdateProductVariant)
+				products.DELETE("/:id/variants/:variantId", middleware.RequireRole(models.RoleAdmin), productHandler.DeleteProductVariant)
+				products.GET("/:id/components", productHandler.GetBundleComponents)
+				products.PUT("/:id/components", middleware.RequireRole(models.RoleAdmin), productHandler.SetBundleComponents)
+				products.GET("/:id/suppliers", middleware.RequirePermission(permissionService, models.PermissionSuppliersView), supplierHandler.GetProductSuppliers)
+				products.PUT("/:id/suppliers", middleware.RequirePermission(permissionService, models.PermissionSuppliersManage), supplierHandler.SetProductSuppliers)
+				products.GET("/:id/units", unitHandler.GetProductUnits)
+				products.PUT("/:id/units", middleware.RequireRole(models.RoleAdmin), unitHandler.SetProductUnits)
+				products.GET("/:id/images", productImageHandler.GetProductImages)
+				products.POST("/:id/images", middleware.RequireRole(models.RoleAdmin), productImageHandler.UploadProductImages)
+				products.PUT("/:id/images/order", middleware.RequireRole(models.RoleAdmin), productImageHandler.ReorderProductImages)
+				products.PUT("/:id/images/:imageId/primary", middleware.RequireRole(models.RoleAdmin), productImageHandler.SetPrimaryProductImage)
+				products.DELETE("/:id/images/:imageId", middleware.RequireRole(models.RoleAdmin), productImageHandler.DeleteProductImage)
+			}
+
+			// INVENTORY ROUTES
+			inventory := protected.Group("/inventory")
+			{
+				inventory.GET("/stock", middleware.RequirePermission(permissionService, models.PermissionInventoryView), inventoryHandler.GetStockLevels)
+				inventory.GET("/movements", middleware.RequirePermission(permissionService, models.PermissionInventoryView), inventoryHandler.GetStockMovements)
+				inventory.GET("/low-stock", middleware.RequirePermission(permissionService, models.PermissionInventoryView), inventoryHandler.GetLowStock)
+				inventory.POST("/adjustments", middleware.RequirePermission(permissionService, models.PermissionInventoryAdjust), inventoryHandler.AdjustStock)
+				inventory.POST("/receipts", middleware.RequirePermission(permissionService, models.PermissionInventoryAdjust), inventoryHandler.ReceiveStock)
+				inventory.GET("/serials", middleware.RequirePermission(permissionService, models.PermissionInventoryView), inventoryHandler.GetSerials)
+				inventory.GET("/batches", middleware.RequirePermission(permissionService, models.PermissionInventoryView), inventoryHandler.GetBatches)
+				inventory.GET("/batches/recall", middleware.RequirePermission(permissionService, models.PermissionInventoryView), inventoryHandler.GetBatchRecall)
+				inventory.GET("/expiring", middleware.RequirePermission(permissionService, models.PermissionInventoryView), inventoryHandler.GetExpiringStock)
+			}
+
+			// STOCKTAKE ROUTES
+			stocktakes := protected.Group("/stocktakes")
+			{
+				stocktakes.GET("", middleware.RequirePermission(permissionService, models.PermissionInventoryView), stocktakeHandler.GetAllStocktakes)
+				stocktakes.GET("/:id", middleware.RequirePermission(permissionService, models.PermissionInventoryView), stocktakeHandler.GetStocktakeById)
+				stocktakes.GET("/:id/report", middleware.RequirePermission(permissionService, models.PermissionInventoryView), stocktakeHandler.GetStocktakeReport)
+				stocktakes.POST("", middleware.RequirePermission(permissionService, models.PermissionInventoryCount), stocktakeHandler.CreateStocktake)
+				stocktakes.POST("/:id/counts", middleware.RequirePermission(permissionService, models.PermissionInventoryCount), stocktakeHandler.RecordCounts)
+				stocktakes.POST("/:id/scans", middleware.RequirePermission(permissionService, models.PermissionInventoryCount), stocktakeHandler.RecordScans)
+				stocktakes.PUT("/:id/approve", middleware.RequirePermission(permissionService, models.PermissionInventoryAdjust), stocktakeHandler.ApproveStocktake)
+				stocktakes.PUT("/:id/cancel", middleware.RequirePermission(permissionService, models.PermissionInventoryAdjust), stocktakeHandler.CancelStocktake)
+			}
+
+			// ORDER ROUTES
+			orders := protected.Group("/orders")
+			{
+				orders.GET("", middleware.RequirePermission(permissionService, models.PermissionOrdersView), orderHandler.GetAllOrders)
+				orders.POST("", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate), orderHandler.CreateOrder)
+				orders.GET("/:id", middleware.RequirePermission(permissionService, models.PermissionOrdersView), orderStore, orderHandler.GetOrderById)
+				orders.PUT("/:id/place", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate), orderStore, orderHandler.PlaceOrder)
+				orders.PUT("/:id/fulfill", middleware.RequirePermission(permissionService, models.PermissionOrdersManage), orderStore, orderHandler.FulfillOrder)
+				orders.PUT("/:id/complete", middleware.RequirePermission(permissionService, models.PermissionOrdersManage), orderStore, orderHandler.CompleteOrder)
+				orders.PUT("/:id/cancel", middleware.RequirePermission(permissionService, models.PermissionOrdersManage), orderStore, orderHandler.CancelOrder)
+				orders.GET("/:id/payments", middleware.RequirePermission(permissionService, models.PermissionOrdersView), orderStore, paymentHandler.GetOrderPayments)
+				orders.POST("/:id/payments", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate), orderStore, paymentHandler.CreatePayment)
+				orders.POST("/:id/payments/:paymentId/capture", middleware.RequirePermission(permissionService, models.PermissionOrdersManage), orderStore, paymentHandler.CapturePayment)
+				orders.POST("/:id/payments/:paymentId/refund", middleware.RequirePermission(permissionService, models.PermissionOrdersManage), orderStore, paymentHandler.RefundPayment)
+				orders.POST("/:id/refund", middleware.RequirePermission(permissionService, models.PermissionOrdersRefund), orderStore, returnHandler.RefundOrder)
+				orders.GET("/:id/returns", middleware.RequirePermission(permissionService, models.PermissionOrdersView), orderStore, returnHandler.GetOrderReturns)
+				orders.GET("/:id/receipt", middleware.RequirePermission(permissionService, models.PermissionOrdersView), orderStore, receiptHandler.GetReceipt)
+				orders.POST("/:id/receipt/email", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate), orderStore, receiptHandler.EmailReceipt)
+				orders.GET("/:id/receipt/emails", middleware.RequirePermission(permissionService, models.PermissionOrdersView), orderStore, receiptHandler.GetReceiptEmails)
+				orders.PUT("/:id/customer", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate), orderStore, orderHandler.SetOrderCustomer)
+			}
+
+			// RETURN ROUTES
+			protected.GET("/returns", middleware.RequirePermission(permissionService, models.PermissionOrdersRefund), returnHandler.GetAllReturns)
+
+			// PAYMENT ROUTES
+			paymentRoutes := protected.Group("/payments")
+			{
+				paymentRoutes.GET("/methods", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate), paymentHandler.GetPaymentMethods)
+				paymentRoutes.GET("/reconciliation", middleware.RequirePermission(permissionService, models.PermissionOrdersManage), paymentHandler.GetReconciliation)
+			}
+
+			// CASH DRAWER ROUTES
+			// Cashiers operate the drawer they opened; managers review every session
+			cashDrawers := protected.Group("/cash-drawers")
+			{
+				cashDrawers.GET("", middleware.RequirePermission(permissionService, models.PermissionOrdersManage), cashDrawerHandler.GetAllDrawers)
+				cashDrawers.POST("/open", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate), cashDrawerHandler.OpenDrawer)
+				cashDrawers.GET("/current", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate), cashDrawerHandler.GetCurrentDrawer)
+				cashDrawers.GET("/:id", middleware.RequirePermission(permissionService, models.PermissionOrdersManage), cashDrawerHandler.GetDrawerReport)
+				cashDrawers.POST("/:id/movements", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate), cashDrawerHandler.RecordMovement)
+				cashDrawers.POST("/:id/close", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate), cashDrawerHandler.CloseDrawer)
+			}
+
+			// SHIFT ROUTES
+			// Cashiers open and close the register on their terminal; managers review every shift
+			shifts := protected.Group("/shifts")
+			{
+				shifts.GET("", middleware.RequirePermission(permissionService, models.PermissionOrdersManage), shiftHandler.GetAllShifts)
+				shifts.POST("/open", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate), shiftHandler.OpenShift)
+				shifts.GET("/current", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate), shiftHandler.GetCurrentShift)
+				shifts.GET("/:id", middleware.RequirePermission(permissionService, models.PermissionOrdersManage), shiftHandler.GetShiftReport)
+				shifts.POST("/:id/close", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate), shiftHandler.CloseShift)
+			}
+
+			// CART ROUTES
+			// Terminals share one cart per device; other clients get one per user
+			cart := protected.Group("/cart", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate))
+			{
+				cart.GET("", cartHandler.GetCart)
+				cart.PUT("", cartHandler.UpdateCart)
+				cart.DELETE("", cartHandler.ClearCart)
+				cart.POST("/items", cartHandler.AddItem)
+				cart.PUT("/items/:lineId", cartHandler.UpdateItem)
+				cart.DELETE("/items/:lineId", cartHandler.RemoveItem)
+				cart.PUT("/customer", cartHandler.SetCustomer)
+				cart.POST("/apply-coupon", cartHandler.ApplyCoupon)
+				cart.DELETE("/coupon", cartHandler.RemoveCoupon)
+				cart.POST("/checkout", cartHandler.Checkout)
+				cart.POST("/park", cartHandler.ParkCart)
+			}
+
+			// PARKED SALE ROUTES
+			// Sales parked at one terminal can be resumed at any terminal of the same store
+			parkedSales := protected.Group("/parked-sales", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate))
+			{
+				parkedSales.GET("", cartHandler.GetParkedSales)
+				parkedSales.GET("/:id", cartHandler.GetParkedSale)
+				parkedSales.POST("/:id/resume", cartHandler.ResumeParkedSale)
+				parkedSales.DELETE("/:id", cartHandler.VoidParkedSale)
+			}
+
+			// DINING AREA ROUTES
+			diningAreas := protected.Group("/dining-areas")
+			{
+				diningAreas.GET("", tableHandler.GetAllDiningAreas)
+				diningAreas.GET("/:id", tableHandler.GetDiningAreaById)
+				diningAreas.POST("", middleware.RequireRole(models.RoleAdmin), tableHandler.CreateDiningArea)
+				diningAreas.PUT("/:id", middleware.RequireRole(models.RoleAdmin), tableHandler.UpdateDiningArea)
+				diningAreas.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), tableHandler.DeleteDiningArea)
+			}
+
+			// TABLE ROUTES
+			// A table's open ticket is a cart of the table, worked on like the terminal's cart
+			tables := protected.Group("/tables")
+			{
+				tables.GET("", tableHandler.GetAllTables)
+				tables.GET("/:id", tableHandler.GetTableById)
+				tables.POST("", middleware.RequireRole(models.RoleAdmin), tableHandler.CreateTable)
+				tables.PUT("/:id", middleware.RequireRole(models.RoleAdmin), tableHandler.UpdateTable)
+				tables.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), tableHandler.DeleteTable)
+
+				ticket := tables.Group("/:id/ticket", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate), tableHandler.TicketTable())
+				{
+					ticket.GET("", cartHandler.GetCart)
+					ticket.PUT("", cartHandler.UpdateCart)
+					ticket.DELETE("", cartHandler.ClearCart)
+					ticket.POST("/items", cartHandler.AddItem)
+					ticket.PUT("/items/:lineId", cartHandler.UpdateItem)
+					ticket.DELETE("/items/:lineId", cartHandler.RemoveItem)
+					ticket.PUT("/customer", cartHandler.SetCustomer)
+					ticket.POST("/apply-coupon", cartHandler.ApplyCoupon)
+					ticket.DELETE("/coupon", cartHandler.RemoveCoupon)
+					ticket.POST("/checkout", cartHandler.Checkout)
+					ticket.PUT("/transfer", tableHandler.TransferTicket)
+					ticket.POST("/merge", tableHandler.MergeTicket)
+				}
+			}
+
+			// KITCHEN ROUTES
+			// Kitchen displays follow the stream and acknowledge items as they are started and done
+			kitchen := protected.Group("/kitchen")
+			{
+				kitchen.GET("/tickets", middleware.RequirePermission(permissionService, models.PermissionKitchenView), kitchenHandler.GetTickets)
+				kitchen.GET("/tickets/:id", middleware.RequirePermission(permissionService, models.PermissionKitchenView), orderStore, kitchenHandler.GetTicket)
+				kitchen.GET("/stream", middleware.RequirePermission(permissionService, models.PermissionKitchenView), kitchenHandler.StreamTickets)
+				kitchen.PUT("/tickets/:id/items/:itemId/start", middleware.RequirePermission(permissionService, models.PermissionKitchenUpdate), orderStore, kitchenHandler.StartItem)
+				kitchen.PUT("/tickets/:id/items/:itemId/done", middleware.RequirePermission(permissionService, models.PermissionKitchenUpdate), orderStore, kitchenHandler.CompleteItem)
+			}
+
+			// PROMOTION ROUTES
+			promotions := protected.Group("/promotions")
+			{
+				promotions.GET("", promotionHandler.GetAllPromotions)
+				promotions.GET("/:id", promotionHandler.GetPromotionById)
+				promotions.POST("", middleware.RequireRole(models.RoleAdmin), promotionHandler.CreatePromotion)
+				promotions.PUT("/:id", middleware.RequireRole(models.RoleAdmin), promotionHandler.UpdatePromotion)
+				promotions.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), promotionHandler.DeletePromotion)
+			}
+
+			// COUPON ROUTES
+			coupons := protected.Group("/coupons", middleware.RequireRole(models.RoleAdmin))
+			{
+				coupons.GET("", couponHandler.GetAllCoupons)
+				coupons.GET("/:id", couponHandler.GetCouponById)
+				coupons.GET("/:id/redemptions", couponHandler.GetCouponRedemptions)
+				coupons.POST("", couponHandler.CreateCoupons)
+				coupons.PUT("/:id", couponHandler.UpdateCoupon)
+				coupons.DELETE("/:id", couponHandler.DeleteCoupon)
+			}
+
+			// TAX ROUTES
+			taxClasses := protected.Group("/tax-classes")
+			{
+				taxClasses.GET("", taxHandler.GetAllTaxClasses)
+				taxClasses.GET("/:id", taxHandler.GetTaxClassById)
+				taxClasses.POST("", middleware.RequireRole(models.RoleAdmin), taxHandler.CreateTaxClass)
+				taxClasses.PUT("/:id", middleware.RequireRole(models.RoleAdmin), taxHandler.UpdateTaxClass)
+				taxClasses.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), taxHandler.DeleteTaxClass)
+				taxClasses.POST("/:id/rates", middleware.RequireRole(models.RoleAdmin), taxHandler.CreateTaxRate)
+				taxClasses.PUT("/:id/rates/:rateId", middleware.RequireRole(models.RoleAdmin), taxHandler.UpdateTaxRate)
+				taxClasses.DELETE("/:id/rates/:rateId", middleware.RequireRole(models.RoleAdmin), taxHandler.DeleteTaxRate)
+			}
+
+			// UNIT ROUTES
+			units := protected.Group("/units")
+			{
+				units.GET("", unitHandler.GetAllUnits)
+				units.GET("/:id", unitHandler.GetUnitById)
+				units.POST("", middleware.RequireRole(models.RoleAdmin), unitHandler.CreateUnit)
+				units.PUT("/:id", middleware.RequireRole(models.RoleAdmin), unitHandler.UpdateUnit)
+				units.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), unitHandler.DeleteUnit)
+			}
+
+			// CUSTOMER ROUTES
+			customers := protected.Group("/customers")
+			{
+				customers.GET("", middleware.RequirePermission(permissionService, models.PermissionCustomersView), customerHandler.GetAllCustomers)
+				customers.GET("/:id", middleware.RequirePermission(permissionService, models.PermissionCustomersView), customerHandler.GetCustomerById)
+				customers.GET("/:id/orders", middleware.RequirePermission(permissionService, models.PermissionCustomersView), customerHandler.GetCustomerOrders)
+				customers.POST("", middleware.RequirePermission(permissionService, models.PermissionCustomersManage), customerHandler.CreateCustomer)
+				customers.PUT("/:id", middleware.RequirePermission(permissionService, models.PermissionCustomersManage), customerHandler.UpdateCustomer)
+				customers.DELETE("/:id", middleware.RequirePermission(permissionService, models.PermissionCustomersManage), customerHandler.DeleteCustomer)
+				customers.GET("/:id/loyalty", middleware.RequirePermission(permissionService, models.PermissionCustomersView), loyaltyHandler.GetBalance)
+				customers.GET("/:id/loyalty/transactions", middleware.RequirePermission(permissionService, models.PermissionCustomersView), loyaltyHandler.GetTransactions)
+				customers.POST("/:id/loyalty/adjustments", middleware.RequirePermission(permissionService, models.PermissionLoyaltyAdjust), loyaltyHandler.AdjustPoints)
+			}
+
+			// LOYALTY ROUTES
+			loyaltyRules := protected.Group("/loyalty-rules")
+			{
+				loyaltyRules.GET("", loyaltyHandler.GetAllLoyaltyRules)
+				loyaltyRules.GET("/:id", loyaltyHandler.GetLoyaltyRuleById)
+				loyaltyRules.POST("", middleware.RequireRole(models.RoleAdmin), loyaltyHandler.CreateLoyaltyRule)
+				loyaltyRules.PUT("/:id", middleware.RequireRole(models.RoleAdmin), loyaltyHandler.UpdateLoyaltyRule)
+				loyaltyRules.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), loyaltyHandler.DeleteLoyaltyRule)
+			}
+
+			// GIFT CARD ROUTES
+			giftCards := protected.Group("/gift-cards")
+			{
+				giftCards.GET("", middleware.RequirePermission(permissionService, models.PermissionGiftCardsView), giftCardHandler.GetAllGiftCards)
+				giftCards.POST("/balance", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate), giftCardHandler.CheckBalance)
+				giftCards.GET("/:id", middleware.RequirePermission(permissionService, models.PermissionGiftCardsView), giftCardHandler.GetGiftCardById)
+				giftCards.GET("/:id/transactions", middleware.RequirePermission(permissionService, models.PermissionGiftCardsView), giftCardHandler.GetTransactions)
+				giftCards.POST("", middleware.RequirePermission(permissionService, models.PermissionGiftCardsManage), giftCardHandler.IssueGiftCard)
+				giftCards.PUT("/:id/void", middleware.RequirePermission(permissionService, models.PermissionGiftCardsManage), giftCardHandler.VoidGiftCard)
+			}
+
+			// INVOICE ROUTES
+			invoices := protected.Group("/invoices")
+			{
+				invoices.GET("", middleware.RequirePermission(permissionService, models.PermissionInvoicesView), invoiceHandler.GetAllInvoices)
+				invoices.GET("/:id", middleware.RequirePermission(permissionService, models.PermissionInvoicesView), invoiceHandler.GetInvoiceById)
+				invoices.GET("/:id/pdf", middleware.RequirePermission(permissionService, models.PermissionInvoicesView), invoiceHandler.GetInvoicePDF)
+				invoices.POST("", middleware.RequirePermission(permissionService, models.PermissionInvoicesManage), invoiceHandler.CreateInvoice)
+				invoices.POST("/:id/payments", middleware.RequirePermission(permissionService, models.PermissionInvoicesManage), invoiceHandler.RecordPayment)
+				invoices.PUT("/:id/void", middleware.RequirePermission(permissionService, models.PermissionInvoicesManage), invoiceHandler.VoidInvoice)
+			}
+
+			// QUOTE ROUTES
+			quotes := protected.Group("/quotes")
+			{
+				quotes.GET("", middleware.RequirePermission(permissionService, models.PermissionQuotesView), quoteHandler.GetAllQuotes)
+				quotes.GET("/:id", middleware.RequirePermission(permissionService, models.PermissionQuotesView), quoteHandler.GetQuoteById)
+				quotes.GET("/:id/versions", middleware.RequirePermission(permissionService, models.PermissionQuotesView), quoteHandler.GetQuoteVersions)
+				quotes.GET("/:id/pdf", middleware.RequirePermission(permissionService, models.PermissionQuotesView), quoteHandler.GetQuotePDF)
+				quotes.POST("", middleware.RequirePermission(permissionService, models.PermissionQuotesManage), quoteHandler.CreateQuote)
+				quotes.PUT("/:id", middleware.RequirePermission(permissionService, models.PermissionQuotesManage), quoteHandler.UpdateQuote)
+				quotes.POST("/:id/send", middleware.RequirePermission(permissionService, models.PermissionQuotesManage), quoteHandler.SendQuote)
+				quotes.PUT("/:id/accept", middleware.RequirePermission(permissionService, models.PermissionQuotesManage), quoteHandler.AcceptQuote)
+				quotes.PUT("/:id/reject", middleware.RequirePermission(permissionService, models.PermissionQuotesManage), quoteHandler.RejectQuote)
+				quotes.POST("/:id/convert", middleware.RequirePermission(permissionService, models.PermissionQuotesManage), quoteHandler.ConvertQuote)
+			}
+
+			// STORE ROUTES
+			stores := protected.Group("/stores", middleware.RequireFeature(licenseManager, license.FeatureMultiStore))
+			{
+				stores.GET("", storeHandler.GetAllStores)
+				stores.GET("/:id", storeHandler.GetStoreById)
+				stores.POST("", middleware.RequireRole(models.RoleAdmin), storeHandler.CreateStore)
+				stores.PUT("/:id", middleware.RequireRole(models.RoleAdmin), storeHandler.UpdateStore)
+				stores.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), storeHandler.DeleteStore)
+			}
+
+			// TRANSFER ROUTES
+			transfers := protected.Group("/transfers", middleware.RequireFeature(licenseManager, license.FeatureMultiStore))
+			{
+				transfers.GET("", middleware.RequirePermission(permissionService, models.PermissionTransfersView), transferHandler.GetAllTransfers)
+				transfers.GET("/in-transit", middleware.RequirePermission(permissionService, models.PermissionTransfersView), transferHandler.GetInTransit)
+				transfers.GET("/:id", middleware.RequirePermission(permissionService, models.PermissionTransfersView), transferHandler.GetTransferById)
+				transfers.POST("", middleware.RequirePermission(permissionService, models.PermissionTransfersManage), transferHandler.CreateTransfer)
+				transfers.PUT("/:id/approve", middleware.RequirePermission(permissionService, models.PermissionTransfersApprove), transferHandler.ApproveTransfer)
+				transfers.PUT("/:id/send", middleware.RequirePermission(permissionService, models.PermissionTransfersManage), transferHandler.SendTransfer)
+				transfers.PUT("/:id/receive", middleware.RequirePermission(permissionService, models.PermissionTransfersManage), transferHandler.ReceiveTransfer)
+				transfers.PUT("/:id/cancel", middleware.RequirePermission(permissionService, models.PermissionTransfersManage), transferHandler.CancelTransfer)
+			}
+
+			// SUPPLIER ROUTES
+			suppliers := protected.Group("/suppliers")
+			{
+				suppliers.GET("", middleware.RequirePermission(permissionService, models.PermissionSuppliersView), supplierHandler.GetAllSuppliers)
+				suppliers.GET("/:id", middleware.RequirePermission(permissionService, models.PermissionSuppliersView), supplierHandler.GetSupplierById)
+				suppliers.GET("/:id/products", middleware.RequirePermission(permissionService, models.PermissionSuppliersView), supplierHandler.GetSupplierProducts)
+				suppliers.POST("", middleware.RequirePermission(permissionService, models.PermissionSuppliersManage), supplierHandler.CreateSupplier)
+				suppliers.PUT("/:id", middleware.RequirePermission(permissionService, models.PermissionSuppliersManage), supplierHandler.UpdateSupplier)
+				suppliers.DELETE("/:id", middleware.RequirePermission(permissionService, models.PermissionSuppliersManage), supplierHandler.DeleteSupplier)
+			}
+
+			// IMPORT ROUTES
+			// Products and order history from another POS, imported in the background
+			imports := protected.Group("/imports", middleware.RequireRole(models.RoleAdmin))
+			{
+				imports.GET("", importHandler.GetAllImports)
+				imports.POST("", importHandler.CreateImport)
+				imports.GET("/:id", importHandler.GetImportById)
+				imports.DELETE("/:id", importHandler.DeleteImport)
+			}
+
+			// TEAM ROUTES
+			teams := protected.Group("/teams")
+			{
+				teams.GET("", teamHandler.GetAllTeams)
+				teams.POST("", middleware.RequireRole(models.RoleAdmin), teamHandler.CreateTeam)
+				teams.GET("/:id", middleware.RequireTeamRole(db.DB, "id"), teamHandler.GetTeamById)
+				teams.PUT("/:id", middleware.RequireTeamRole(db.DB, "id", models.TeamRoleLead), teamHandler.UpdateTeam)
+				teams.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), teamHandler.DeleteTeam)
+				teams.GET("/:id/members", middleware.RequireTeamRole(db.DB, "id"), teamHandler.GetTeamMembers)
+				teams.POST("/:id/members", middleware.RequireTeamRole(db.DB, "id", models.TeamRoleLead), teamHandler.AddTeamMember)
+				teams.PUT("/:id/members/:userId", middleware.RequireTeamRole(db.DB, "id", models.TeamRoleLead), teamHandler.UpdateTeamMember)
+				teams.DELETE("/:id/members/:userId", middleware.RequireTeamRole(db.DB, "id", models.TeamRoleLead), teamHandler.RemoveTeamMember)
+			}
+			// DEVICE ROUTES
+			devices := protected.Group("/devices", middleware.RequireRole(models.RoleAdmin))
+			{
+				devices.GET("", deviceHandler.GetAllDevices)
+				devices.POST("", middleware.PlanCapacity(subscriptionService, models.PlanResourceLocations), deviceHandler.CreateDevice)
+				devices.GET("/:id", deviceHandler.GetDeviceById)
+				devices.PUT("/:id", middleware.PlanCapacity(subscriptionService, models.PlanResourceLocations), deviceHandler.UpdateDevice)
+				devices.DELETE("/:id", deviceHandler.DeleteDevice)
+				devices.POST("/:id/pairing-code", deviceHandler.RegeneratePairingCode)
+				devices.PUT("/:id/deactivate", deviceHandler.DeactivateDevice)
+				devices.GET("/:id/config", deviceConfigHandler.GetDeviceConfig)
+				devices.PUT("/:id/config", deviceConfigHandler.UpdateDeviceConfig)
+			}
+			// PRINT JOB ROUTES
+			printJobs := protected.Group("/print-jobs")
+			{
+				printJobs.GET("", middleware.RequirePermission(permissionService, models.PermissionPrintJobsView), printJobHandler.GetAllPrintJobs)
+				printJobs.POST("", middleware.RequirePermission(permissionService, models.PermissionPrintJobsManage), printJobHandler.CreatePrintJob)
+				printJobs.GET("/:id", middleware.RequirePermission(permissionService, models.PermissionPrintJobsView), printJobHandler.GetPrintJobById)
+				printJobs.PUT("/:id/cancel", middleware.RequirePermission(permissionService, models.PermissionPrintJobsManage), printJobHandler.CancelPrintJob)
+				printJobs.PUT("/:id/retry", middleware.RequirePermission(permissionService, models.PermissionPrintJobsManage), printJobHandler.RetryPrintJob)
+			}
+			// REPORT ROUTES
+			reports := protected.Group("/reports", middleware.RequireRole(models.RoleAdmin))
+			{
+				reports.GET("", reportHandler.GetAllReportDefinitions)
+				reports.POST("", reportHandler.CreateReportDefinition)
+				reports.GET("/entities", reportHandler.GetReportEntities)
+				reports.GET("/:id", reportHandler.GetReportDefinitionById)
+				reports.PUT("/:id", reportHandler.UpdateReportDefinition)
+				reports.DELETE("/:id", reportHandler.DeleteReportDefinition)
+				reports.GET("/:id/run", reportHandler.RunReport)
+			}
+			// REPORT SCHEDULE ROUTES
+			reportSchedules := protected.Group("/report-schedules", middleware.RequireRole(models.RoleAdmin))
+			{
+				reportSchedules.GET("", reportScheduleHandler.GetAllReportSchedules)
+				reportSchedules.POST("", reportScheduleHandler.CreateReportSchedule)
+				reportSchedules.GET("/:id", reportScheduleHandler.GetReportScheduleById)
+				reportSchedules.PUT("/:id", reportScheduleHandler.UpdateReportSchedule)
+				reportSchedules.DELETE("/:id", reportScheduleHandler.DeleteReportSchedule)
+				reportSchedules.GET("/:id/runs", reportScheduleHandler.GetReportScheduleRuns)
+				reportSchedules.POST("/:id/run", reportScheduleHandler.RunReportSchedule)
+			}
+			// ANALYTICS ROUTES
+			protected.GET("/analytics/sales", middleware.RequirePermission(permissionService, models.PermissionAnalyticsView), analyticsHandler.GetSalesAnalytics)
+			// SYSTEM ROUTES
+			protected.GET("/system/cache", middleware.RequireRole(models.RoleAdmin), systemHandler.GetCacheStatus)
+			protected.GET("/system/read-only", middleware.RequireRole(models.RoleAdmin), systemHandler.GetReadOnlyMode)
+			protected.PUT("/system/read-only", middleware.RequireRole(models.RoleAdmin), systemHandler.UpdateReadOnlyMode)
+			protected.GET("/system/license", middleware.RequireRole(models.RoleAdmin), systemHandler.GetLicense)
+			protected.GET("/system/storage", middleware.RequireRole(models.RoleAdmin), systemHandler.GetStorageUsage)
+			protected.GET("/system/schemas", systemHandler.GetSchemaVersions)
+			// EXPERIMENT ROUTES
+			experiments := protected.Group("/experiments", middleware.RequireRole(models.RoleAdmin))
+			{
+				experiments.GET("", experimentHandler.GetAllExperiments)
+				experiments.POST("", experimentHandler.CreateExperiment)
+				experiments.GET("/:id", experimentHandler.GetExperimentById)
+				experiments.PUT("/:id", experimentHandler.UpdateExperiment)
+				experiments.DELETE("/:id", experimentHandler.DeleteExperiment)
+				experiments.PUT("/:id/status", experimentHandler.UpdateExperimentStatus)
+				experiments.GET("/:id/assignments", experimentHandler.GetExperimentAssignments)
+			}
+			// SUBSCRIPTION ROUTES
+			admin := protected.Group("/admin", middleware.RequireRole(models.RoleAdmin))
+			{
+				admin.GET("/subscription", subscriptionHandler.GetSubscription)
+				admin.PUT("/subscription", subscriptionHandler.UpdateSubscription)
+				admin.GET("/plans", subscriptionHandler.GetAllPlans)
+				admin.POST("/plans", subscriptionHandler.CreatePlan)
+				admin.PUT("/plans/:id", subscriptionHandler.UpdatePlan)
+				admin.GET("/slo", sloHandler.GetSLOStatus)
+			}
+			// CHANGE FEED ROUTES
+			protected.GET("/changes", changeFeedHandler.GetChanges)
+			protected.GET("/changes/stream", changeFeedHandler.StreamChanges)
+			// SETTING ROUTES
+			settings := protected.Group("/settings")
+			{
+				settings.GET("", middleware.RequirePermission(permissionService, models.PermissionSettingsView), settingHandler.GetAllSettings)
+				settings.POST("", middleware.RequireRole(models.RoleAdmin), settingHandler.CreateSetting)
+				settings.GET("/effective/:key", middleware.RequirePermission(permissionService, models.PermissionSettingsView), settingHandler.GetEffectiveSetting)
+				settings.GET("/:id", middleware.RequirePermission(permissionService, models.PermissionSettingsView), settingHandler.GetSettingById)
+				settings.PUT("/:id", middleware.RequireRole(models.RoleAdmin), settingHandler.UpdateSetting)
+				settings.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), settingHandler.DeleteSetting)
+				settings.GET("/:id/history", middleware.RequirePermission(permissionService, models.PermissionSettingsView), settingHandler.GetSettingHistory)
+			}
+			// ROLE & PERMISSION ROUTES
+			roles := protected.Group("/roles", middleware.RequireRole(models.RoleAdmin))
+			{
+				roles.POST("/:role/users/assign", roleHandler.AssignRole)
+				roles.POST("/:role/users/remove", roleHandler.RemoveRole)
+			}
+			permissions := protected.Group("/permissions", middleware.RequireRole(models.RoleAdmin))
+			{
+				permissions.GET("", roleHandler.GetPermissions)
+				permissions.POST("/:permission/grant", roleHandler.GrantPermission)
+				permissions.POST("/:permission/revoke", roleHandler.RevokePermission)
+			}
+			// TENANT ROUTES
+			tenants := protected.Group("/tenants", middleware.RequireRole(models.RoleAdmin))
+			{
+				tenants.GET("", tenantHandler.GetAllTenants)
+				tenants.POST("", tenantHandler.CreateTenant)
+				tenants.GET("/:id", tenantHandler.GetTenantById)
+				tenants.PUT("/:id", tenantHandler.UpdateTenant)
+				tenants.DELETE("/:id", tenantHandler.DeleteTenant)
+			}
+			// GRAPHQL ROUTES
+			protected.POST("/graphql", graphqlHandler.Query)
+			// REALTIME ROUTES
+			protected.GET("/ws", realtimeHandler.Connect)
 		}
+	})
+
+	// Serve every API version under /api/<version>. A future version registers its own routes
+	// next to v1's and reuses v1's handlers wherever its responses don't change.
+	apiVersions := []apiversion.Registrar{v1}
+	versions := make([]string, 0, len(apiVersions))
+	api := router.Group(apiversion.Prefix)
+	for _, registrar := range apiVersions {
+		versioned := api.Group("/"+registrar.Version(), middleware.APIVersion(registrar.Version()), middleware.SchemaVersion())
+		registrar.Register(versioned, versioned.Group("", protect...))
+		versions = append(versions, registrar.Version())
+	}
+	router.NoRoute(middleware.NoRoute(versions))
+
+	// Routes must be described in internal/contract to reach generated clients
+	if missing := contract.Check(router.Routes()); len(missing) > 0 {
+		slog.Warn("Routes missing from the API contract", "routes", strings.Join(missing, ", "))
+	}
+
+	// Serve internal services over gRPC on a listener of its own
+	if cfg.GRPCPort != "" {
+		grpcServer := rpc.NewServer(cfg.GRPCToken, tracingEnabled, userService, productService, inventoryService)
+		go func() {
+			slog.Info("gRPC server starting", "addr", cfg.GetGRPCAddr())
+			if err := rpc.ListenAndServe(cfg.GetGRPCAddr(), grpcServer); err != nil {
+				logger.Fatal("Failed to start gRPC server", "error", err)
+			}
+		}()
 	}
 
-	// Start server
-	log.Printf("Server starting on %s", cfg.GetServerAddr())
-	if err := router.Run(cfg.GetServerAddr()); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	// Start server. Requests to unversioned /api paths are served by the version they negotiate,
+	// v1 by default, while clients move to versioned paths.
+	slog.Info("Server starting", "addr", cfg.GetServerAddr())
+	if err := http.ListenAndServe(cfg.GetServerAddr(), apiversion.Negotiate(router)); err != nil {
+		logger.Fatal("Failed to start server", "error", err)
 	}
 }
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+
+	"github.com/Aebroyx/the-blade-api/internal/config"
+	"github.com/Aebroyx/the-blade-api/internal/database"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/factories"
+	"github.com/Aebroyx/the-blade-api/internal/logger"
+	"gorm.io/gorm"
+)
+
+// seed fills an empty database with demo data: an administrator, users, categories, products
+// and orders placed by the administrator.
+// The same seed always produces the same data.
+//
+// Usage:
+//
+//	go run ./cmd/seed [-seed demo] [-users 20] [-categories 6] [-products 50] [-orders 30]
+func main() {
+	seed := flag.String("seed", "demo", "value the fake data is derived from")
+	userCount := flag.Int("users", 20, "number of users besides the administrator")
+	categoryCount := flag.Int("categories", 6, "number of top-level categories")
+	productCount := flag.Int("products", 50, "number of products")
+	orderCount := flag.Int("orders", 30, "number of orders")
+	flag.Parse()
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Failed to load configuration", "error", err)
+	}
+
+	// Log structured JSON at the configured level, as the API does
+	if err := logger.Setup(cfg.LogLevel); err != nil {
+		logger.Fatal("Invalid log level", "error", err)
+	}
+
+	// Never put demo accounts into production
+	if cfg.Environment == "production" {
+		logger.Fatal("Refusing to seed while APP_ENV is production")
+	}
+
+	// Initialize database
+	db, err := database.NewConnection(cfg)
+	if err != nil {
+		logger.Fatal("Failed to connect to database", "error", err)
+	}
+
+	var existing int64
+	if err := db.Model(&models.Users{}).Count(&existing).Error; err != nil {
+		logger.Fatal("Failed to count users", "error", err)
+	}
+	if existing > 0 {
+		logger.Fatal("Refusing to seed a database that already has users")
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		f := factories.New(tx, *seed)
+
+		admin, err := f.CreateUser(func(u *models.Users) {
+			u.Username = "admin"
+			u.Email = "admin@example.com"
+			u.Name = "Administrator"
+			u.Role = models.RoleAdmin
+		})
+		if err != nil {
+			return err
+		}
+		slog.Info("Created administrator", "username", admin.Username, "password", factories.Password)
+
+		for i := 0; i < *userCount; i++ {
+			if _, err := f.CreateUser(); err != nil {
+				return err
+			}
+		}
+
+		categories := make([]*models.Categories, 0, *categoryCount)
+		for i := 0; i < *categoryCount; i++ {
+			category, err := f.CreateCategory()
+			if err != nil {
+				return err
+			}
+			categories = append(categories, category)
+		}
+
+		products := make([]models.Products, 0, *productCount)
+		for i := 0; i < *productCount; i++ {
+			product, err := f.CreateProduct(func(p *models.Products) {
+				if len(categories) > 0 {
+					p.CategoryID = &categories[i%len(categories)].ID
+				}
+			})
+			if err != nil {
+				return err
+			}
+			products = append(products, *product)
+		}
+
+		// Orders need something to sell
+		if len(products) == 0 {
+			*orderCount = 0
+			return nil
+		}
+		for i := 0; i < *orderCount; i++ {
+			// One or two products per order
+			lines := []models.Products{products[i%len(products)]}
+			if other := (i*7 + 3) % len(products); other != i%len(products) {
+				lines = append(lines, products[other])
+			}
+			if _, err := f.CreateOrder(lines, func(o *models.Orders) { o.CreatedByID = &admin.ID }); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Fatal("Seeding failed", "error", err)
+	}
+
+	slog.Info("Seeded demo data", "users", *userCount+1, "categories", *categoryCount, "products", *productCount, "orders", *orderCount)
+}
@@ -0,0 +1,240 @@
+package anonymize
+
+import (
+	"fmt"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+// Column is a column holding personal data
+type Column struct {
+	Name string
+	Kind Kind
+}
+
+// Table lists the personal data columns of a table.
+// Where optionally restricts which rows are scrambled.
+type Table struct {
+	Name    string
+	Where   string
+	Columns []Column
+}
+
+// DefaultTables covers every table holding personal data.
+// Add new tables here when models with PII are introduced.
+var DefaultTables = []Table{
+	{
+		Name: "users",
+		Columns: []Column{
+			{Name: "name", Kind: KindName},
+			{Name: "username", Kind: KindUsername},
+			{Name: "email", Kind: KindEmail},
+			{Name: "pending_email", Kind: KindEmail},
+			{Name: "metadata", Kind: KindEmptyJSON},
+			{Name: "last_login_ip", Kind: KindIP},
+		},
+	},
+	{
+		Name: "login_events",
+		Columns: []Column{
+			{Name: "username", Kind: KindUsername},
+			{Name: "ip_address", Kind: KindIP},
+			{Name: "user_agent", Kind: KindText},
+		},
+	},
+	{
+		Name: "user_activities",
+		Columns: []Column{
+			{Name: "ip_address", Kind: KindIP},
+			{Name: "user_agent", Kind: KindText},
+		},
+	},
+	{
+		// Profile and email change events keep previous names and emails in their metadata
+		Name:  "user_activities",
+		Where: "event_type IN ('profile_updated', 'email_change_requested', 'email_changed')",
+		Columns: []Column{
+			{Name: "metadata", Kind: KindEmptyJSON},
+		},
+	},
+	{
+		// Customers' addresses receipts were emailed to
+		Name: "receipt_emails",
+		Columns: []Column{
+			{Name: "email", Kind: KindEmail},
+		},
+	},
+	{
+		Name: "customers",
+		Columns: []Column{
+			{Name: "name", Kind: KindName},
+			{Name: "email", Kind: KindEmail},
+			{Name: "phone", Kind: KindPhone},
+			{Name: "tax_id", Kind: KindText},
+			{Name: "billing_address", Kind: KindText},
+			{Name: "notes", Kind: KindText},
+		},
+	},
+	{
+		// Contacts at suppliers
+		Name: "suppliers",
+		Columns: []Column{
+			{Name: "contact_name", Kind: KindName},
+			{Name: "email", Kind: KindEmail},
+			{Name: "phone", Kind: KindPhone},
+		},
+	},
+	{
+		// Buyer details copied onto invoices
+		Name: "invoices",
+		Columns: []Column{
+			{Name: "buyer_name", Kind: KindName},
+			{Name: "buyer_address", Kind: KindText},
+			{Name: "buyer_tax_id", Kind: KindText},
+			{Name: "buyer_email", Kind: KindEmail},
+		},
+	},
+	{
+		// Addresses quotes were sent to
+		Name:  "quotes",
+		Where: "email <> ''",
+		Columns: []Column{
+			{Name: "email", Kind: KindEmail},
+		},
+	},
+	{
+		// Emails or phone numbers customers gave for per-customer coupon limits
+		Name:  "coupon_redemptions",
+		Where: "customer <> ''",
+		Columns: []Column{
+			{Name: "customer", Kind: KindText},
+		},
+	},
+	{
+		// Addresses scheduled reports are emailed to
+		Name:  "report_schedules",
+		Where: "recipients <> '[]'",
+		Columns: []Column{
+			{Name: "recipients", Kind: KindEmptyList},
+		},
+	},
+}
+
+// Result summarizes what was (or would be) changed in a table
+type Result struct {
+	Table string `json:"table"`
+	Rows  int    `json:"rows"`
+}
+
+// Anonymizer scrambles personal data in place
+type Anonymizer struct {
+	db        *gorm.DB
+	faker     *Faker
+	batchSize int
+	dryRun    bool
+}
+
+// NewAnonymizer creates an anonymizer. In dry-run mode rows are only counted.
+func NewAnonymizer(db *gorm.DB, faker *Faker, batchSize int, dryRun bool) *Anonymizer {
+	if batchSize < 1 {
+		batchSize = 500
+	}
+	return &Anonymizer{
+		db:        db,
+		faker:     faker,
+		batchSize: batchSize,
+		dryRun:    dryRun,
+	}
+}
+
+// Run anonymizes every given table, each in its own transaction
+func (a *Anonymizer) Run(tables []Table) ([]Result, error) {
+	results := make([]Result, 0, len(tables))
+	for _, table := range tables {
+		rows, err := a.anonymizeTable(table)
+		if err != nil {
+			return results, fmt.Errorf("failed to anonymize %s: %w", table.Name, err)
+		}
+		slog.Info("Anonymized rows", "table", table.Name, "rows", rows)
+		results = append(results, Result{Table: table.Name, Rows: rows})
+	}
+	return results, nil
+}
+
+// anonymizeTable walks the table in primary key order and rewrites the configured columns
+func (a *Anonymizer) anonymizeTable(table Table) (int, error) {
+	total := 0
+
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		selectColumns := []string{"id"}
+		for _, column := range table.Columns {
+			selectColumns = append(selectColumns, column.Name)
+		}
+
+		lastID := uint64(0)
+		for {
+			query := tx.Table(table.Name).Select(selectColumns).Where("id > ?", lastID).Order("id ASC").Limit(a.batchSize)
+			if table.Where != "" {
+				query = query.Where(table.Where)
+			}
+
+			var rows []map[string]interface{}
+			if err := query.Find(&rows).Error; err != nil {
+				return err
+			}
+			if len(rows) == 0 {
+				return nil
+			}
+
+			for _, row := range rows {
+				id, err := toUint64(row["id"])
+				if err != nil {
+					return err
+				}
+				lastID = id
+
+				if a.dryRun {
+					continue
+				}
+
+				updates := map[string]interface{}{}
+				for _, column := range table.Columns {
+					original := ""
+					if value := row[column.Name]; value != nil {
+						original = fmt.Sprint(value)
+					}
+					updates[column.Name] = a.faker.Value(column.Kind, original)
+				}
+
+				if err := tx.Table(table.Name).Where("id = ?", id).Updates(updates).Error; err != nil {
+					return err
+				}
+			}
+
+			total += len(rows)
+		}
+	})
+
+	return total, err
+}
+
+// toUint64 converts a scanned primary key into an integer
+func toUint64(value interface{}) (uint64, error) {
+	switch v := value.(type) {
+	case int64:
+		return uint64(v), nil
+	case int32:
+		return uint64(v), nil
+	case int:
+		return uint64(v), nil
+	case uint64:
+		return v, nil
+	case uint32:
+		return uint64(v), nil
+	case uint:
+		return uint64(v), nil
+	default:
+		return 0, fmt.Errorf("unexpected primary key type %T", value)
+	}
+}
@@ -0,0 +1,98 @@
+package anonymize
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Kind describes what a column holds and therefore how it is scrambled
+type Kind string
+
+const (
+	KindName      Kind = "name"
+	KindFirstName Kind = "first_name"
+	KindEmail     Kind = "email"
+	KindUsername  Kind = "username"
+	KindPhone     Kind = "phone"
+	KindIP        Kind = "ip"
+	KindText      Kind = "text"
+	KindEmptyJSON Kind = "empty_json"
+	KindEmptyList Kind = "empty_list"
+)
+
+var firstNames = []string{
+	"Ava", "Liam", "Maya", "Noah", "Sofia", "Ethan", "Aria", "Lucas", "Zara", "Mateo",
+	"Nina", "Omar", "Lena", "Kai", "Ivy", "Ravi", "Elena", "Theo", "Hana", "Jonas",
+	"Amara", "Felix", "Yuki", "Diego", "Leila", "Arjun", "Clara", "Tomas", "Mei", "Samir",
+}
+
+var lastNames = []string{
+	"Anderson", "Baker", "Castillo", "Dubois", "Evans", "Fischer", "Garcia", "Hughes", "Ito", "Jensen",
+	"Kowalski", "Larsen", "Moreau", "Nakamura", "Okafor", "Patel", "Quinn", "Rossi", "Silva", "Tanaka",
+	"Umar", "Varga", "Wagner", "Xu", "Yilmaz", "Zimmer", "Santoso", "Haddad", "Novak", "Lindqvist",
+}
+
+// Faker produces deterministic fake values: the same original value and salt always give the
+// same fake value, so relationships between tables and repeated runs stay consistent.
+type Faker struct {
+	salt []byte
+}
+
+// NewFaker creates a faker keyed by the given salt
+func NewFaker(salt string) *Faker {
+	return &Faker{salt: []byte(salt)}
+}
+
+// digest returns a keyed hash of the value for the given kind
+func (f *Faker) digest(kind Kind, value string) []byte {
+	mac := hmac.New(sha256.New, f.salt)
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// pick deterministically selects an item from a list using part of the digest
+func pick(digest []byte, offset int, items []string) string {
+	n := binary.BigEndian.Uint32(digest[offset : offset+4])
+	return items[int(n)%len(items)]
+}
+
+// Value returns the fake replacement for an original value of the given kind.
+// Empty values stay empty so optional columns keep their shape.
+func (f *Faker) Value(kind Kind, original string) string {
+	if original == "" && kind != KindEmptyJSON && kind != KindEmptyList {
+		return ""
+	}
+
+	d := f.digest(kind, original)
+	suffix := hex.EncodeToString(d[8:13])
+
+	switch kind {
+	case KindName:
+		return pick(d, 0, firstNames) + " " + pick(d, 4, lastNames)
+	case KindFirstName:
+		return pick(d, 0, firstNames)
+	case KindEmail:
+		return fmt.Sprintf("%s.%s.%s@example.com",
+			strings.ToLower(pick(d, 0, firstNames)), strings.ToLower(pick(d, 4, lastNames)), suffix)
+	case KindUsername:
+		return fmt.Sprintf("%s_%s", strings.ToLower(pick(d, 0, firstNames)), suffix)
+	case KindPhone:
+		n := binary.BigEndian.Uint32(d[0:4]) % 10000000
+		return fmt.Sprintf("+1555%07d", n)
+	case KindIP:
+		// Documentation range (RFC 5737) so scrambled addresses can never be real clients
+		return fmt.Sprintf("203.0.113.%d", d[0])
+	case KindEmptyJSON:
+		return "{}"
+	case KindEmptyList:
+		return "[]"
+	default:
+		return "redacted-" + suffix
+	}
+}
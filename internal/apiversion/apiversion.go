@@ -0,0 +1,143 @@
+// Package apiversion versions the API by path. Each version registers its routes under
+// /api/<version>, e.g. /api/v1/orders, so a new version can change response shapes while
+// clients of the old one keep working. Unversioned /api paths are a deprecated alias: they are
+// served by the version the client asks for in the X-API-Version header, or Default.
+package apiversion
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// Prefix is the path every API version is served under
+	Prefix = "/api"
+	// Header is sent by clients of unversioned paths to pick a version, and reports the version
+	// of every versioned response
+	Header = "X-API-Version"
+	// Default serves unversioned paths when the client asks for no version
+	Default = "v1"
+)
+
+// Registrar registers the routes of one API version. Public routes need no signed in user;
+// protected routes run after the auth middleware.
+type Registrar interface {
+	// Version is the path segment the routes are served under, e.g. "v1"
+	Version() string
+	Register(public *gin.RouterGroup, protected *gin.RouterGroup)
+}
+
+// registrarFunc is a Registrar calling a function
+type registrarFunc struct {
+	version  string
+	register func(public *gin.RouterGroup, protected *gin.RouterGroup)
+}
+
+// NewRegistrar returns a Registrar of version calling register
+func NewRegistrar(version string, register func(public *gin.RouterGroup, protected *gin.RouterGroup)) Registrar {
+	return registrarFunc{version: version, register: register}
+}
+
+func (r registrarFunc) Version() string {
+	return r.version
+}
+
+func (r registrarFunc) Register(public *gin.RouterGroup, protected *gin.RouterGroup) {
+	r.register(public, protected)
+}
+
+// IsVersion reports whether a path segment names a version: v followed by digits
+func IsVersion(segment string) bool {
+	if len(segment) < 2 || segment[0] != 'v' {
+		return false
+	}
+	for _, r := range segment[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsAPIPath reports whether a path is under Prefix
+func IsAPIPath(path string) bool {
+	return path == Prefix || strings.HasPrefix(path, Prefix+"/")
+}
+
+// split splits an API path into its version and the path after it; unversioned and non-API
+// paths have no version
+func split(path string) (string, string) {
+	rest, ok := strings.CutPrefix(path, Prefix+"/")
+	if !ok {
+		return "", ""
+	}
+	segment, after, _ := strings.Cut(rest, "/")
+	if !IsVersion(segment) {
+		return "", ""
+	}
+	return segment, after
+}
+
+// Of returns the version a path is served under, or "" when it is unversioned
+func Of(path string) string {
+	version, _ := split(path)
+	return version
+}
+
+// Strip removes the version from a path, e.g. /api/v1/orders/:id becomes /api/orders/:id.
+// Tables keyed by route path, such as route limits and routes exempt from read-only mode,
+// hold unversioned paths and are looked up with it.
+func Strip(path string) string {
+	version, after := split(path)
+	if version == "" {
+		return path
+	}
+	if after == "" {
+		return Prefix
+	}
+	return Prefix + "/" + after
+}
+
+// aliasKey is the context key of the unversioned path a request was sent to
+type aliasKey struct{}
+
+// AliasOf returns the unversioned path a request was sent to before Negotiate rewrote it
+func AliasOf(ctx context.Context) (string, bool) {
+	path, ok := ctx.Value(aliasKey{}).(string)
+	return path, ok
+}
+
+// Negotiate serves requests to unversioned /api paths with the version they ask for in the
+// Header, or Default, by rewriting the path before next routes it. Versions may be asked for
+// as "v2" or "2"; requests asking for versions that aren't served find no route.
+func Negotiate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if !IsAPIPath(path) || Of(path) != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		version := strings.TrimSpace(r.Header.Get(Header))
+		switch {
+		case version == "":
+			version = Default
+		case !strings.HasPrefix(version, "v"):
+			version = "v" + version
+		}
+		if !IsVersion(version) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rewritten := r.Clone(context.WithValue(r.Context(), aliasKey{}, path))
+		rewritten.URL.Path = Prefix + "/" + version + strings.TrimPrefix(path, Prefix)
+		if r.URL.RawPath != "" {
+			rewritten.URL.RawPath = Prefix + "/" + version + strings.TrimPrefix(r.URL.RawPath, Prefix)
+		}
+		next.ServeHTTP(w, rewritten)
+	})
+}
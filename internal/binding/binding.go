@@ -0,0 +1,124 @@
+// Package binding parses route and query parameters for handlers. Every helper answers an
+// invalid value with a 400 response of its own and reports false, so handlers only return:
+//
+//	id, ok := binding.ID(c, "id")
+//	if !ok {
+//		return
+//	}
+package binding
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/gin-gonic/gin"
+)
+
+// invalid responds that a parameter has an invalid value
+func invalid(c *gin.Context, name string, details interface{}) bool {
+	common.SendError(c, http.StatusBadRequest, fmt.Sprintf("Invalid %s parameter", name), common.CodeInvalidRequest, details)
+	return false
+}
+
+// parseID parses a positive database ID
+func parseID(value string) (uint, error) {
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a valid ID", value)
+	}
+	if id == 0 {
+		return 0, fmt.Errorf("IDs start at 1")
+	}
+	return uint(id), nil
+}
+
+// ID parses a route parameter holding a database ID
+func ID(c *gin.Context, name string) (uint, bool) {
+	id, err := parseID(c.Param(name))
+	if err != nil {
+		return 0, invalid(c, name, err.Error())
+	}
+	return id, true
+}
+
+// QueryID parses an optional query parameter holding a database ID; nil when it is absent
+func QueryID(c *gin.Context, name string) (*uint, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	id, err := parseID(value)
+	if err != nil {
+		return nil, invalid(c, name, err.Error())
+	}
+	return &id, true
+}
+
+// Enum parses a query parameter that must be one of the allowed values, falling back to the
+// default when it is absent
+func Enum(c *gin.Context, name string, fallback string, allowed ...string) (string, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return fallback, true
+	}
+	if !slices.Contains(allowed, value) {
+		return "", invalid(c, name, fmt.Sprintf("must be one of %s", strings.Join(allowed, ", ")))
+	}
+	return value, true
+}
+
+// Bool parses a boolean query parameter, falling back to the default when it is absent
+func Bool(c *gin.Context, name string, fallback bool) (bool, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return fallback, true
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, invalid(c, name, "must be true or false")
+	}
+	return parsed, true
+}
+
+// Int parses an integer query parameter of at least min, falling back to the default when it
+// is absent
+func Int(c *gin.Context, name string, fallback int, min int) (int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return fallback, true
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < min {
+		return 0, invalid(c, name, fmt.Sprintf("must be a whole number of at least %d", min))
+	}
+	return parsed, true
+}
+
+// List parses a comma-separated query parameter, dropping empty entries
+func List(c *gin.Context, name string) []string {
+	var items []string
+	for _, item := range strings.Split(c.Query(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// IDList parses a comma-separated query parameter of database IDs
+func IDList(c *gin.Context, name string) ([]uint, bool) {
+	items := List(c, name)
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		id, err := parseID(item)
+		if err != nil {
+			return nil, invalid(c, name, err.Error())
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
@@ -0,0 +1,385 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// State describes whether the cache is currently usable
+type State string
+
+const (
+	StateHealthy  State = "healthy"
+	StateDegraded State = "degraded"
+	StateDisabled State = "disabled"
+)
+
+// Rate limit policies while the cache is unavailable
+const (
+	RateLimitAllow = "allow" // Skip rate limiting and let requests through
+	RateLimitDeny  = "deny"  // Reject requests that would need a rate limit check
+	RateLimitLocal = "local" // Rate limit per API instance, in memory; quotas and plan limits are skipped as with allow
+)
+
+// maxPendingInvalidations bounds the keys remembered while degraded. Beyond it, stale
+// entries may be served after recovery until their TTL runs out.
+const maxPendingInvalidations = 10000
+
+var (
+	// ErrUnavailable is returned while the cache is degraded or disabled; callers fall back to the database
+	ErrUnavailable = errors.New("cache unavailable")
+	// ErrMiss is returned when the key is not cached
+	ErrMiss = errors.New("cache miss")
+)
+
+// Policy lists the explicit behaviors while the cache is unavailable
+type Policy struct {
+	RateLimit string
+}
+
+// Stats reports the cache state and how long it has spent degraded
+type Stats struct {
+	State                State             `json:"state"`
+	DegradedSince        *time.Time        `json:"degraded_since,omitempty"`
+	DegradedSeconds      float64           `json:"degraded_seconds"`
+	Transitions          int               `json:"transitions"`
+	SkippedOperations    int64             `json:"skipped_operations"`
+	Hits                 int64             `json:"hits"`
+	Misses               int64             `json:"misses"`
+	PendingInvalidations int               `json:"pending_invalidations"`
+	RateLimitPolicy      string            `json:"rate_limit_policy"`
+	Verification         VerificationStats `json:"verification"`
+}
+
+// Cache wraps Redis with health tracking. While Redis is unreachable the cache is degraded:
+// reads report ErrUnavailable without touching Redis, writes are skipped, and deletes are
+// remembered and replayed once Redis is back so no stale entries survive the outage.
+type Cache struct {
+	client *redis.Client
+	policy Policy
+
+	mu                   sync.Mutex
+	state                State
+	degradedSince        time.Time
+	degradedTotal        time.Duration
+	transitions          int
+	pendingInvalidations map[string]struct{}
+
+	skipped atomic.Int64
+	hits    atomic.Int64
+	misses  atomic.Int64
+
+	verifier verifier
+}
+
+// UserKey is the cache key of an authenticated user
+func UserKey(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// ClaimsVersionKey is the cache key of a user's current claims version
+func ClaimsVersionKey(userID uint) string {
+	return fmt.Sprintf("claims:user:%d", userID)
+}
+
+// PermissionsVersionKey changes whenever permissions are granted or revoked
+const PermissionsVersionKey = "permissions:version"
+
+// BarcodeKey is the cache key of what a scanned barcode resolves to
+func BarcodeKey(code string) string {
+	return fmt.Sprintf("barcode:%s", code)
+}
+
+// CartKey is the cache key of the cart of a terminal or user
+func CartKey(owner string) string {
+	return fmt.Sprintf("cart:%s", owner)
+}
+
+// PresenceKeyPrefix prefixes the short-lived keys marking a user as online
+const PresenceKeyPrefix = "presence:user:"
+
+// PresenceKey is the presence key of a user
+func PresenceKey(userID uint) string {
+	return fmt.Sprintf("%s%d", PresenceKeyPrefix, userID)
+}
+
+// EventsChannel is the pub/sub channel realtime events are fanned out over to every instance
+const EventsChannel = "events:realtime"
+
+// APIUsageKey is the key counting a tenant's API calls on a given day (YYYYMMDD)
+func APIUsageKey(tenantID uint, day string) string {
+	return fmt.Sprintf("usage:api:%d:%s", tenantID, day)
+}
+
+// RateLimitKey is the key of a client's token bucket for a rate limit
+func RateLimitKey(name string, subject string) string {
+	return fmt.Sprintf("ratelimit:%s:%s", name, subject)
+}
+
+// UserQuotaKey is the key counting a user's API requests in one quota window
+func UserQuotaKey(userID uint, windowStart int64) string {
+	return fmt.Sprintf("quota:user:%d:%d", userID, windowStart)
+}
+
+// SettingKey is the cache key of a setting stored for one scope
+func SettingKey(scope string, scopeID string, key string) string {
+	return fmt.Sprintf("setting:%s:%s:%s", scope, scopeID, key)
+}
+
+// AnalyticsKey is the cache key of an analytics result for one set of query parameters
+func AnalyticsKey(name string, params string) string {
+	return fmt.Sprintf("analytics:%s:%s", name, params)
+}
+
+// New creates a cache around a Redis client, starting degraded if Redis does not answer
+func New(ctx context.Context, client *redis.Client, policy Policy) *Cache {
+	c := &Cache{
+		client:               client,
+		policy:               policy,
+		state:                StateHealthy,
+		pendingInvalidations: map[string]struct{}{},
+	}
+	if err := client.Ping(ctx).Err(); err != nil {
+		c.markDegraded(err)
+	}
+	return c
+}
+
+// Disabled creates a cache that never stores anything, for deployments without Redis
+func Disabled() *Cache {
+	return &Cache{
+		state:                StateDisabled,
+		pendingInvalidations: map[string]struct{}{},
+	}
+}
+
+// Available reports whether Redis is currently used
+func (c *Cache) Available() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state == StateHealthy
+}
+
+// State returns the current state
+func (c *Cache) State() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// Policy returns the degraded behavior policy
+func (c *Cache) Policy() Policy {
+	return c.policy
+}
+
+// Client returns the underlying Redis client while the cache is healthy
+func (c *Cache) Client() (*redis.Client, bool) {
+	if !c.Available() {
+		return nil, false
+	}
+	return c.client, true
+}
+
+// Get returns the cached value for a key
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, error) {
+	if !c.Available() {
+		c.skipped.Add(1)
+		return nil, ErrUnavailable
+	}
+
+	data, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		c.misses.Add(1)
+		return nil, ErrMiss
+	}
+	if err != nil {
+		c.markDegraded(err)
+		return nil, ErrUnavailable
+	}
+	c.hits.Add(1)
+	return data, nil
+}
+
+// Set stores a value. While degraded the write is skipped.
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if !c.Available() {
+		c.skipped.Add(1)
+		return ErrUnavailable
+	}
+
+	if err := c.client.Set(ctx, key, value, ttl).Err(); err != nil {
+		c.markDegraded(err)
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// Delete removes keys. While degraded the keys are remembered and deleted on recovery.
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if c.Available() {
+		err := c.client.Del(ctx, keys...).Err()
+		if err == nil {
+			return nil
+		}
+		c.markDegraded(err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == StateDisabled {
+		return nil
+	}
+	for _, key := range keys {
+		if len(c.pendingInvalidations) >= maxPendingInvalidations {
+			slog.WarnContext(ctx, "Cache: too many pending invalidations, the key may be stale until it expires", "key", key)
+			continue
+		}
+		c.pendingInvalidations[key] = struct{}{}
+	}
+	return nil
+}
+
+// Ping checks that Redis answers, whatever the current state. The state itself is left to the
+// monitor. A disabled cache reports ErrUnavailable.
+func (c *Cache) Ping(ctx context.Context) error {
+	if c.State() == StateDisabled {
+		return ErrUnavailable
+	}
+	return c.client.Ping(ctx).Err()
+}
+
+// Monitor pings Redis on the given interval and switches between healthy and degraded
+func (c *Cache) Monitor(ctx context.Context, interval time.Duration) {
+	if c.State() == StateDisabled {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pingCtx, cancel := context.WithTimeout(ctx, interval)
+			err := c.client.Ping(pingCtx).Err()
+			cancel()
+
+			if err != nil {
+				c.markDegraded(err)
+				continue
+			}
+			if c.State() == StateDegraded {
+				c.recover(ctx)
+			}
+		}
+	}
+}
+
+// markDegraded switches to degraded mode after a Redis failure
+func (c *Cache) markDegraded(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state != StateHealthy {
+		return
+	}
+	c.state = StateDegraded
+	c.degradedSince = time.Now()
+	c.transitions++
+	slog.Warn("Cache: Redis unavailable, switching to degraded mode", "error", err)
+}
+
+// recover replays the invalidations missed while degraded, then switches back to healthy
+func (c *Cache) recover(ctx context.Context) {
+	c.mu.Lock()
+	keys := make([]string, 0, len(c.pendingInvalidations))
+	for key := range c.pendingInvalidations {
+		keys = append(keys, key)
+	}
+	c.mu.Unlock()
+
+	if len(keys) > 0 {
+		if err := c.client.Del(ctx, keys...).Err(); err != nil {
+			slog.WarnContext(ctx, "Cache: failed to replay invalidations, staying degraded", "keys", len(keys), "error", err)
+			return
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		delete(c.pendingInvalidations, key)
+	}
+	degradedFor := time.Since(c.degradedSince)
+	c.degradedTotal += degradedFor
+	c.state = StateHealthy
+	c.transitions++
+	slog.InfoContext(ctx, "Cache: Redis recovered", "degraded_for", degradedFor.Round(time.Second).String(), "replayed_invalidations", len(keys))
+}
+
+// RegisterMetrics exposes the cache hit and miss counters and whether Redis is in use. Reads
+// skipped while Redis is unavailable are neither hits nor misses.
+func (c *Cache) RegisterMetrics(r prometheus.Registerer) {
+	r.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "cache_hits_total",
+			Help: "Cache reads answered from Redis.",
+		}, func() float64 { return float64(c.hits.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "cache_misses_total",
+			Help: "Cache reads of keys Redis didn't hold.",
+		}, func() float64 { return float64(c.misses.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "cache_skipped_operations_total",
+			Help: "Cache operations skipped while Redis was unavailable.",
+		}, func() float64 { return float64(c.skipped.Load()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "cache_available",
+			Help: "Whether Redis is in use (1) or the cache is degraded or disabled (0).",
+		}, func() float64 {
+			if c.Available() {
+				return 1
+			}
+			return 0
+		}),
+	)
+}
+
+// Stats returns the current cache state and degradation metrics
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := Stats{
+		State:                c.state,
+		DegradedSeconds:      c.degradedTotal.Seconds(),
+		Transitions:          c.transitions,
+		SkippedOperations:    c.skipped.Load(),
+		Hits:                 c.hits.Load(),
+		Misses:               c.misses.Load(),
+		PendingInvalidations: len(c.pendingInvalidations),
+		RateLimitPolicy:      c.policy.RateLimit,
+		Verification:         c.verificationStats(),
+	}
+	if c.state == StateDegraded {
+		since := c.degradedSince
+		stats.DegradedSince = &since
+		stats.DegradedSeconds += time.Since(since).Seconds()
+	}
+	return stats
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrLocked is returned when a lock stays held by someone else for longer than the caller waits
+var ErrLocked = errors.New("lock held")
+
+// lockRetryInterval is how often a held lock is tried again
+const lockRetryInterval = 25 * time.Millisecond
+
+// releaseScript deletes a lock only if it still holds the caller's token, so a lock that
+// expired and was taken by someone else is left alone
+var releaseScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+
+// CouponLockKey is the key of the lock serializing reservations of a coupon
+func CouponLockKey(couponID uint) string {
+	return fmt.Sprintf("lock:coupon:%d", couponID)
+}
+
+// Lock takes a lock on a key across every API instance, waiting up to wait for it to be free.
+// The lock expires after ttl in case its holder dies; the returned function releases it.
+// While the cache is unavailable ErrUnavailable is returned and callers must serialize some
+// other way, e.g. with a row lock.
+func (c *Cache) Lock(ctx context.Context, key string, ttl time.Duration, wait time.Duration) (func(), error) {
+	if !c.Available() {
+		c.skipped.Add(1)
+		return nil, ErrUnavailable
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(buf)
+
+	deadline := time.Now().Add(wait)
+	for {
+		acquired, err := c.client.SetNX(ctx, key, token, ttl).Result()
+		if err != nil {
+			c.markDegraded(err)
+			return nil, ErrUnavailable
+		}
+		if acquired {
+			break
+		}
+		if time.Now().After(deadline) {
+			return nil, ErrLocked
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+
+	return func() {
+		if err := releaseScript.Run(context.Background(), c.client, []string{key}, token).Err(); err != nil {
+			slog.WarnContext(ctx, "Cache: failed to release lock, it expires on its own", "key", key, "ttl", ttl.String(), "error", err)
+		}
+	}, nil
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeTokenScript refills a token bucket for the time since it was last used and takes a token
+// if one is left, atomically across API instances. The bucket expires once it would be full
+// again, since a missing bucket is a full one. Tokens are returned as a string to keep their
+// fraction.
+var takeTokenScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local per_ms = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'at')
+local tokens = tonumber(bucket[1])
+local at = tonumber(bucket[2])
+if tokens == nil or at == nil then
+	tokens = capacity
+	at = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - at) * per_ms)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'at', tostring(now))
+redis.call('PEXPIRE', KEYS[1], ttl)
+return {allowed, tostring(tokens)}
+`)
+
+// TakeToken takes a token from the bucket at key, which holds up to capacity tokens and refills
+// completely over period. It reports whether a token was taken and the tokens left. While the
+// cache is unavailable ErrUnavailable is returned.
+func (c *Cache) TakeToken(ctx context.Context, key string, capacity int, period time.Duration) (bool, float64, error) {
+	if !c.Available() {
+		c.skipped.Add(1)
+		return false, 0, ErrUnavailable
+	}
+
+	perMs := float64(capacity) / float64(period.Milliseconds())
+	result, err := takeTokenScript.Run(ctx, c.client, []string{key},
+		capacity, strconv.FormatFloat(perMs, 'f', -1, 64), time.Now().UnixMilli(), period.Milliseconds()).Slice()
+	if err != nil {
+		c.markDegraded(err)
+		return false, 0, ErrUnavailable
+	}
+	if len(result) != 2 {
+		return false, 0, ErrUnavailable
+	}
+
+	allowed, _ := result[0].(int64)
+	tokensText, _ := result[1].(string)
+	tokens, err := strconv.ParseFloat(tokensText, 64)
+	if err != nil {
+		return false, 0, err
+	}
+	return allowed == 1, tokens, nil
+}
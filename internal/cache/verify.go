@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VerificationStats reports how often sampled cache hits disagreed with the database
+type VerificationStats struct {
+	SampleRate float64 `json:"sample_rate"`
+	Checked    int64   `json:"checked"`
+	Stale      int64   `json:"stale"`
+	// Share of checked hits that were stale
+	StaleRatio float64 `json:"stale_ratio"`
+	// Stale hits per key prefix, e.g. "user" or "setting"
+	StaleByPrefix map[string]int64 `json:"stale_by_prefix"`
+	LastStaleKey  string           `json:"last_stale_key,omitempty"`
+	LastStaleAt   *time.Time       `json:"last_stale_at,omitempty"`
+}
+
+// verifier samples cache hits and compares them with a fresh copy from the source of truth,
+// to catch invalidation bugs that would otherwise only show up as stale data
+type verifier struct {
+	mu            sync.Mutex
+	rate          float64
+	checked       int64
+	stale         int64
+	staleByPrefix map[string]int64
+	lastStaleKey  string
+	lastStaleAt   time.Time
+}
+
+// EnableVerification verifies the given share of cache hits (0 to 1) against the database.
+// Call it before the cache is used; 0 turns verification off.
+func (c *Cache) EnableVerification(rate float64) {
+	c.verifier.mu.Lock()
+	defer c.verifier.mu.Unlock()
+	c.verifier.rate = rate
+	if rate > 0 {
+		slog.Info("Cache: verifying cache hits against the database", "sample_rate", rate)
+	}
+}
+
+// Sample reports whether a cache hit should be verified. Callers that get true load the
+// value from the database and pass both copies to Verify.
+func (c *Cache) Sample() bool {
+	c.verifier.mu.Lock()
+	rate := c.verifier.rate
+	c.verifier.mu.Unlock()
+	return rate > 0 && rand.Float64() < rate
+}
+
+// Verify compares a cached value with a fresh copy from the database and records whether the
+// cached one was stale. Both are compared as JSON, so field order does not matter. Divergences
+// are logged with the names of the differing fields only, as values may hold personal data.
+func (c *Cache) Verify(key string, cached []byte, fresh []byte) bool {
+	fields, equal := diffJSON(cached, fresh)
+
+	v := &c.verifier
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.checked++
+	if equal {
+		return true
+	}
+
+	v.stale++
+	if v.staleByPrefix == nil {
+		v.staleByPrefix = map[string]int64{}
+	}
+	prefix, _, _ := strings.Cut(key, ":")
+	v.staleByPrefix[prefix]++
+	v.lastStaleKey = key
+	v.lastStaleAt = time.Now()
+
+	slog.Warn("Cache: stale entry", "key", key, "fields", strings.Join(fields, ", "))
+	return false
+}
+
+// verificationStats returns a snapshot of the verification counters
+func (c *Cache) verificationStats() VerificationStats {
+	v := &c.verifier
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	stats := VerificationStats{
+		SampleRate:    v.rate,
+		Checked:       v.checked,
+		Stale:         v.stale,
+		StaleByPrefix: make(map[string]int64, len(v.staleByPrefix)),
+		LastStaleKey:  v.lastStaleKey,
+	}
+	for prefix, count := range v.staleByPrefix {
+		stats.StaleByPrefix[prefix] = count
+	}
+	if v.checked > 0 {
+		stats.StaleRatio = float64(v.stale) / float64(v.checked)
+	}
+	if !v.lastStaleAt.IsZero() {
+		at := v.lastStaleAt
+		stats.LastStaleAt = &at
+	}
+	return stats
+}
+
+// diffJSON compares two JSON documents. For objects it lists the top-level fields that differ;
+// other documents are reported as a whole.
+func diffJSON(a []byte, b []byte) ([]string, bool) {
+	var left, right interface{}
+	if json.Unmarshal(a, &left) != nil || json.Unmarshal(b, &right) != nil {
+		return []string{"(invalid json)"}, false
+	}
+	if reflect.DeepEqual(left, right) {
+		return nil, true
+	}
+
+	leftObject, leftOK := left.(map[string]interface{})
+	rightObject, rightOK := right.(map[string]interface{})
+	if !leftOK || !rightOK {
+		return []string{"(value)"}, false
+	}
+
+	var fields []string
+	for name, value := range leftObject {
+		if other, ok := rightObject[name]; !ok || !reflect.DeepEqual(value, other) {
+			fields = append(fields, name)
+		}
+	}
+	for name := range rightObject {
+		if _, ok := leftObject[name]; !ok {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields, false
+}
@@ -9,14 +9,23 @@ type Response struct {
 	Data    any    `json:"data,omitempty"`
 }
 
-// ErrorResponse represents a standardized error response
+// ErrorResponse represents a standardized error response. RequestID identifies the request in
+// the server logs, for users to quote when reporting the error. It sits beside Details rather
+// than in it, since Details takes a different shape per error.
 type ErrorResponse struct {
-	Status  string `json:"status"`
-	Message string `json:"message"`
-	Code    string `json:"code,omitempty"`
-	Details any    `json:"details,omitempty"`
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	Code      string `json:"code,omitempty"`
+	Details   any    `json:"details,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
+// RequestIDKey is the gin context key of the request's ID
+const RequestIDKey = "request_id"
+
+// RequestIDHeader carries the ID of a request, in both directions
+const RequestIDHeader = "X-Request-ID"
+
 // NewErrorResponse creates a new error response
 func NewErrorResponse(message string, code string, details any) ErrorResponse {
 	return ErrorResponse{
@@ -29,7 +38,9 @@ func NewErrorResponse(message string, code string, details any) ErrorResponse {
 
 // SendError sends an error response
 func SendError(c *gin.Context, status int, message string, code string, details any) {
-	c.JSON(status, NewErrorResponse(message, code, details))
+	response := NewErrorResponse(message, code, details)
+	response.RequestID = c.GetString(RequestIDKey)
+	c.JSON(status, response)
 }
 
 // SendSuccess sends a success response
@@ -53,8 +64,82 @@ const (
 	CodeNotFound        = "NOT_FOUND"
 	CodeBadRequest      = "BAD_REQUEST"
 	CodeConflict        = "CONFLICT"
+
+	CodeInvalidDeviceToken = "INVALID_DEVICE_TOKEN"
+	CodeDeviceDeactivated  = "DEVICE_DEACTIVATED"
+	CodeInvalidPairingCode = "INVALID_PAIRING_CODE"
+	CodePairingCodeExpired = "PAIRING_CODE_EXPIRED"
+
+	CodeAccountSuspended = "ACCOUNT_SUSPENDED"
+	CodeAccountPending   = "ACCOUNT_PENDING"
+
+	CodeLimitExceeded = "LIMIT_EXCEEDED"
+
+	CodePasswordChangeRequired = "PASSWORD_CHANGE_REQUIRED"
+	CodeInvalidResetToken      = "INVALID_RESET_TOKEN"
+
+	CodeReadOnly = "READ_ONLY"
+
+	CodePaymentRequired         = "PAYMENT_REQUIRED"
+	CodePlanLimitExceeded       = "PLAN_LIMIT_EXCEEDED"
+	CodeUsageUnavailable        = "USAGE_UNAVAILABLE"
+	CodeRateLimited             = "RATE_LIMITED"
+	CodeInvalidWebhookSignature = "INVALID_WEBHOOK_SIGNATURE"
+
+	CodeFeatureNotLicensed = "FEATURE_NOT_LICENSED"
+	CodeCursorExpired      = "CURSOR_EXPIRED"
+
+	CodeQuotaExceeded        = "QUOTA_EXCEEDED"
+	CodeStorageQuotaExceeded = "STORAGE_QUOTA_EXCEEDED"
+
+	CodeUnsupportedSchemaVersion = "UNSUPPORTED_SCHEMA_VERSION"
+	CodeUnsupportedAPIVersion    = "UNSUPPORTED_API_VERSION"
 )
 
+// ErrorCodes describes every error code, for the API contract. Add new codes here too.
+var ErrorCodes = map[string]string{
+	CodeInvalidRequest:  "The request body, path or query parameters could not be parsed",
+	CodeValidationError: "The request was parsed but failed validation; details say which fields",
+	CodeUsernameExists:  "The username is taken",
+	CodeEmailExists:     "The email address is taken",
+	CodeInternalError:   "The server failed to handle the request",
+	CodeUnauthorized:    "Missing or invalid credentials",
+	CodeForbidden:       "The caller lacks the role or permission the route requires",
+	CodeNotFound:        "The resource does not exist",
+	CodeBadRequest:      "The request can't be handled as sent",
+	CodeConflict:        "The request conflicts with the resource's current state",
+
+	CodeInvalidDeviceToken: "Missing or unknown device token",
+	CodeDeviceDeactivated:  "The device was deactivated",
+	CodeInvalidPairingCode: "Unknown pairing code",
+	CodePairingCodeExpired: "The pairing code expired",
+
+	CodeAccountSuspended: "The account is suspended",
+	CodeAccountPending:   "The account is waiting for approval",
+
+	CodeLimitExceeded: "The request exceeds a configured limit; details name the limit",
+
+	CodePasswordChangeRequired: "The user must change their password first",
+	CodeInvalidResetToken:      "Invalid or expired password reset token",
+
+	CodeReadOnly: "The API is in read-only mode",
+
+	CodePaymentRequired:         "The subscription is not active, or a payment was declined",
+	CodePlanLimitExceeded:       "The plan's capacity for the resource is reached",
+	CodeUsageUnavailable:        "Plan usage could not be checked",
+	CodeRateLimited:             "Too many requests; retry later",
+	CodeInvalidWebhookSignature: "The webhook signature does not match",
+
+	CodeFeatureNotLicensed: "The license does not include the feature",
+	CodeCursorExpired:      "The change feed cursor is too old; resync from scratch",
+
+	CodeQuotaExceeded:        "The user's request quota is used up",
+	CodeStorageQuotaExceeded: "The upload would take the tenant or store over its storage quota; details give the scope, quota and usage",
+
+	CodeUnsupportedSchemaVersion: "The requested response schema version is not served",
+	CodeUnsupportedAPIVersion:    "The requested API version is not served",
+}
+
 // Common error responses
 var (
 	ErrInvalidRequest = func(details any) ErrorResponse {
@@ -3,9 +3,17 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/Aebroyx/the-blade-api/internal/logger"
+	"github.com/Aebroyx/the-blade-api/internal/password"
+	"github.com/Aebroyx/the-blade-api/internal/ratelimit"
+	"github.com/Aebroyx/the-blade-api/internal/slo"
 	"github.com/joho/godotenv"
 )
 
@@ -22,6 +30,9 @@ type Config struct {
 	DBPassword string
 	DBName     string
 	DBSSLMode  string
+	// Requests running more SQL statements than this are logged as likely N+1 queries;
+	// 0 turns the check off
+	SQLStatementBudget int
 
 	// Redis config
 	UseRedis      bool
@@ -29,16 +40,123 @@ type Config struct {
 	RedisPort     string
 	RedisPassword string
 	RedisDB       int
+	// How often Redis health is checked, and what rate limiting does while it is down
+	RedisHealthInterval    time.Duration
+	RedisDegradedRateLimit string
+	// Share of cache hits (0 to 1) that are also read from the database to detect stale entries
+	CacheVerifySampleRate float64
+	// How long a user stays online after their last authenticated request
+	PresenceTTL time.Duration
 
 	// JWT config
 	JWTSecret string
 	JWTExpiry time.Duration
+	// strict checks on every request that the user's role and status are current;
+	// performance trusts the cached user until it expires
+	AuthClaimsMode string
+
+	// Algorithm new passwords are hashed with; existing hashes of every algorithm keep working
+	// and are rehashed on the next login
+	PasswordHasher string
 
 	// CORS config
 	CORSAllowedOrigins string
 
 	// Logging
 	LogLevel string
+
+	// Start with the API in read-only mode, e.g. during migrations
+	ReadOnlyMode   bool
+	ReadOnlyReason string
+
+	// Public tenant signup. Users are not isolated per tenant, so the first administrator
+	// of a signed-up tenant can manage every user; only enable this where that is intended.
+	TenantSignupEnabled bool
+
+	// Signing secret of the Stripe Billing webhook endpoint
+	StripeWebhookSecret string
+
+	// Stripe payments: the secret key enables the stripe payment method, the webhook secret
+	// the endpoint that settles its payments, and the currency is charged in
+	StripeSecretKey             string
+	StripePaymentsWebhookSecret string
+	StripeCurrency              string
+
+	// What a loyalty point is worth when redeemed, in minor currency units
+	LoyaltyPointValue int64
+
+	// Bearer token identity providers use for SCIM provisioning, and the groups whose
+	// members get the admin role
+	SCIMToken       string
+	SCIMAdminGroups []string
+
+	// How long change feed events are kept for clients catching up
+	ChangeFeedRetention time.Duration
+
+	// Signed license file for self-hosted installs, re-read on the given interval
+	LicenseFile           string
+	LicenseReloadInterval time.Duration
+
+	// Directory uploaded files are kept in, and the base URL clients fetch them from
+	StorageDir       string
+	StoragePublicURL string
+	// Bytes of uploaded files each tenant, and each store of a tenant, may keep; 0 is unlimited
+	StorageTenantQuota int64
+	StorageStoreQuota  int64
+
+	// Mail config
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
+
+	// Frontend URL used in links sent by email
+	FrontendURL string
+
+	// User purge config
+	UserPurgeEnabled       bool
+	UserPurgeInterval      time.Duration
+	UserPurgeRetentionDays int
+	UserPurgeMode          string
+
+	// How often products are checked for low stock; 0 disables low-stock alerts
+	LowStockCheckInterval time.Duration
+
+	// Whether selling batches past their expiry date is blocked or only flagged on the sale
+	ExpiredStockPolicy string
+
+	// Limits overridden for this deployment, keyed by limit name
+	Limits map[string]int
+
+	// API requests each role may make per quota window; 0 means unlimited
+	APIQuotaWindow time.Duration
+	APIQuotas      map[string]int
+
+	// Requests each client may make to the whole API, and to the auth routes on top
+	RateLimitGlobal ratelimit.Rule
+	RateLimitAuth   ratelimit.Rule
+
+	// Response time objectives per route group, the window their error budgets are tracked
+	// over, and the burn rate administrators are alerted at, checked on the given interval
+	SLOObjectives    []slo.Objective
+	SLOWindow        time.Duration
+	SLOAlertBurnRate float64
+	SLOCheckInterval time.Duration
+
+	// Bearer token Prometheus scrapes /metrics with; empty leaves /metrics open
+	MetricsToken string
+
+	// Port of the gRPC listener for internal services, and the bearer token they call it with;
+	// gRPC is off without a port
+	GRPCPort  string
+	GRPCToken string
+
+	// OTLP/HTTP collector traces are exported to, the service name they're recorded under and
+	// the share of new traces recorded; tracing is off without an endpoint
+	TracingEndpoint    string
+	TracingServiceName string
+	TracingSampleRate  float64
 }
 
 // Load loads the configuration from environment variables
@@ -54,6 +172,148 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("invalid JWT_EXPIRY format: %v", err)
 	}
 
+	// Parse Redis health check interval
+	redisHealthInterval, err := time.ParseDuration(getEnv("REDIS_HEALTH_INTERVAL", "5s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_HEALTH_INTERVAL format: %v", err)
+	}
+
+	// Parse cache verification sample rate
+	cacheVerifySampleRate, err := strconv.ParseFloat(getEnv("CACHE_VERIFY_SAMPLE_RATE", "0"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CACHE_VERIFY_SAMPLE_RATE format: %v", err)
+	}
+
+	// Parse presence TTL
+	presenceTTL, err := time.ParseDuration(getEnv("PRESENCE_TTL", "2m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PRESENCE_TTL format: %v", err)
+	}
+
+	// Parse change feed retention
+	changeFeedRetention, err := time.ParseDuration(getEnv("CHANGE_FEED_RETENTION", "24h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CHANGE_FEED_RETENTION format: %v", err)
+	}
+
+	// Parse license reload interval
+	licenseReloadInterval, err := time.ParseDuration(getEnv("LICENSE_RELOAD_INTERVAL", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LICENSE_RELOAD_INTERVAL format: %v", err)
+	}
+
+	// Parse user purge settings
+	userPurgeInterval, err := time.ParseDuration(getEnv("USER_PURGE_INTERVAL", "24h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid USER_PURGE_INTERVAL format: %v", err)
+	}
+
+	userPurgeRetentionDays, err := strconv.Atoi(getEnv("USER_PURGE_RETENTION_DAYS", "30"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid USER_PURGE_RETENTION_DAYS: %v", err)
+	}
+
+	// Parse low-stock check interval
+	lowStockCheckInterval, err := time.ParseDuration(getEnv("LOW_STOCK_CHECK_INTERVAL", "15m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOW_STOCK_CHECK_INTERVAL format: %v", err)
+	}
+
+	// Parse the per-request SQL statement budget
+	sqlStatementBudget, err := strconv.Atoi(getEnv("SQL_STATEMENT_BUDGET", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SQL_STATEMENT_BUDGET: %v", err)
+	}
+
+	// Parse per-user API quotas
+	apiQuotaWindow, err := time.ParseDuration(getEnv("API_QUOTA_WINDOW", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid API_QUOTA_WINDOW format: %v", err)
+	}
+	apiQuotaUser, err := strconv.Atoi(getEnv("API_QUOTA_USER", "1000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid API_QUOTA_USER: %v", err)
+	}
+	apiQuotaAdmin, err := strconv.Atoi(getEnv("API_QUOTA_ADMIN", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid API_QUOTA_ADMIN: %v", err)
+	}
+
+	// Parse rate limits
+	rateLimitGlobal, err := ratelimit.ParseRule("global", getEnv("RATE_LIMIT_GLOBAL", "600/1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_GLOBAL: %v", err)
+	}
+	rateLimitAuth, err := ratelimit.ParseRule("auth", getEnv("RATE_LIMIT_AUTH", "20/1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_AUTH: %v", err)
+	}
+
+	// Parse response time objectives
+	sloObjectives, err := slo.Parse(getEnv("SLO_OBJECTIVES", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SLO_OBJECTIVES: %v", err)
+	}
+	sloWindow, err := time.ParseDuration(getEnv("SLO_WINDOW", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SLO_WINDOW format: %v", err)
+	}
+	sloAlertBurnRate, err := strconv.ParseFloat(getEnv("SLO_ALERT_BURN_RATE", "2"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SLO_ALERT_BURN_RATE format: %v", err)
+	}
+	sloCheckInterval, err := time.ParseDuration(getEnv("SLO_CHECK_INTERVAL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SLO_CHECK_INTERVAL format: %v", err)
+	}
+
+	// Parse tracing sample rate
+	tracingSampleRate, err := strconv.ParseFloat(getEnv("OTEL_TRACES_SAMPLE_RATE", "1"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTEL_TRACES_SAMPLE_RATE format: %v", err)
+	}
+
+	// gRPC exposes users and tokens, so it never runs without a token
+	grpcPort := getEnv("GRPC_PORT", "")
+	grpcToken := getEnv("GRPC_TOKEN", "")
+	if grpcPort != "" && grpcToken == "" {
+		return nil, fmt.Errorf("GRPC_TOKEN is required when GRPC_PORT is set")
+	}
+
+	// Parse the loyalty point value
+	loyaltyPointValue, err := strconv.ParseInt(getEnv("LOYALTY_POINT_VALUE", "1"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOYALTY_POINT_VALUE: %v", err)
+	}
+	if loyaltyPointValue < 1 {
+		return nil, fmt.Errorf("invalid LOYALTY_POINT_VALUE: must be at least 1")
+	}
+
+	// Parse the storage quotas
+	storageTenantQuota, err := strconv.ParseInt(getEnv("STORAGE_TENANT_QUOTA_BYTES", "0"), 10, 64)
+	if err != nil || storageTenantQuota < 0 {
+		return nil, fmt.Errorf("invalid STORAGE_TENANT_QUOTA_BYTES: must be a number of bytes, 0 for unlimited")
+	}
+	storageStoreQuota, err := strconv.ParseInt(getEnv("STORAGE_STORE_QUOTA_BYTES", "0"), 10, 64)
+	if err != nil || storageStoreQuota < 0 {
+		return nil, fmt.Errorf("invalid STORAGE_STORE_QUOTA_BYTES: must be a number of bytes, 0 for unlimited")
+	}
+
+	// Parse limit overrides, e.g. LIMIT_MAX_BULK_IDS=1000
+	limitOverrides := map[string]int{}
+	for _, name := range limits.Names() {
+		key := "LIMIT_" + strings.ToUpper(name)
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", key, err)
+		}
+		limitOverrides[name] = parsed
+	}
+
 	// Parse Redis DB number
 	redisDB := 0
 	if dbStr := getEnv("REDIS_DB", "0"); dbStr != "" {
@@ -76,22 +336,118 @@ func Load() (*Config, error) {
 		DBName:     getEnv("DB_NAME", "blade_pos"),
 		DBSSLMode:  getEnv("DB_SSL_MODE", "disable"),
 
+		SQLStatementBudget: sqlStatementBudget,
+
 		// Redis config
-		UseRedis:      getEnv("USE_REDIS", "false") == "true",
-		RedisHost:     getEnv("REDIS_HOST", "localhost"),
-		RedisPort:     getEnv("REDIS_PORT", "6379"),
-		RedisPassword: getEnv("REDIS_PASSWORD", ""),
-		RedisDB:       redisDB,
+		UseRedis:               getEnv("USE_REDIS", "false") == "true",
+		RedisHost:              getEnv("REDIS_HOST", "localhost"),
+		RedisPort:              getEnv("REDIS_PORT", "6379"),
+		RedisPassword:          getEnv("REDIS_PASSWORD", ""),
+		RedisDB:                redisDB,
+		RedisHealthInterval:    redisHealthInterval,
+		RedisDegradedRateLimit: getEnv("REDIS_DEGRADED_RATE_LIMIT", "local"),
+		CacheVerifySampleRate:  cacheVerifySampleRate,
+		PresenceTTL:            presenceTTL,
 
 		// JWT config
-		JWTSecret: getEnv("JWT_SECRET", ""),
-		JWTExpiry: jwtExpiry,
+		JWTSecret:      getEnv("JWT_SECRET", ""),
+		JWTExpiry:      jwtExpiry,
+		AuthClaimsMode: getEnv("AUTH_CLAIMS_MODE", models.ClaimsModeStrict),
+		PasswordHasher: getEnv("PASSWORD_HASHER", password.AlgorithmBcrypt),
 
 		// CORS config
 		CORSAllowedOrigins: getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:3000"),
 
 		// Logging
 		LogLevel: getEnv("LOG_LEVEL", "debug"),
+
+		// Read-only mode
+		ReadOnlyMode:   getEnv("READ_ONLY_MODE", "false") == "true",
+		ReadOnlyReason: getEnv("READ_ONLY_REASON", ""),
+
+		// Tenant signup
+		TenantSignupEnabled: getEnv("TENANT_SIGNUP_ENABLED", "false") == "true",
+
+		// Billing
+		StripeWebhookSecret: getEnv("STRIPE_WEBHOOK_SECRET", ""),
+
+		// Payments
+		StripeSecretKey:             getEnv("STRIPE_SECRET_KEY", ""),
+		StripePaymentsWebhookSecret: getEnv("STRIPE_PAYMENTS_WEBHOOK_SECRET", ""),
+		StripeCurrency:              getEnv("STRIPE_CURRENCY", "usd"),
+
+		// Loyalty
+		LoyaltyPointValue: loyaltyPointValue,
+
+		// SCIM provisioning
+		SCIMToken:       getEnv("SCIM_TOKEN", ""),
+		SCIMAdminGroups: strings.Split(getEnv("SCIM_ADMIN_GROUPS", ""), ","),
+
+		// Change feed
+		ChangeFeedRetention: changeFeedRetention,
+
+		// License
+		LicenseFile:           getEnv("LICENSE_FILE", ""),
+		LicenseReloadInterval: licenseReloadInterval,
+
+		// File storage
+		StorageDir:         getEnv("STORAGE_DIR", "uploads"),
+		StoragePublicURL:   strings.TrimRight(getEnv("STORAGE_PUBLIC_URL", "/api/v1/uploads"), "/"),
+		StorageTenantQuota: storageTenantQuota,
+		StorageStoreQuota:  storageStoreQuota,
+
+		// Mail config
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		MailFrom:     getEnv("MAIL_FROM", "no-reply@localhost"),
+
+		FrontendURL: strings.TrimRight(getEnv("FRONTEND_URL", "http://localhost:3000"), "/"),
+
+		// User purge config
+		UserPurgeEnabled:       getEnv("USER_PURGE_ENABLED", "false") == "true",
+		UserPurgeInterval:      userPurgeInterval,
+		UserPurgeRetentionDays: userPurgeRetentionDays,
+		UserPurgeMode:          getEnv("USER_PURGE_MODE", "delete"),
+
+		// Low-stock alerts
+		LowStockCheckInterval: lowStockCheckInterval,
+
+		// Expired stock
+		ExpiredStockPolicy: getEnv("EXPIRED_STOCK_POLICY", models.ExpiredStockBlock),
+
+		// Limits
+		Limits: limitOverrides,
+
+		// API quotas
+		APIQuotaWindow: apiQuotaWindow,
+		APIQuotas: map[string]int{
+			models.RoleUser:  apiQuotaUser,
+			models.RoleAdmin: apiQuotaAdmin,
+		},
+
+		// Rate limits
+		RateLimitGlobal: rateLimitGlobal,
+		RateLimitAuth:   rateLimitAuth,
+
+		// Response time objectives
+		SLOObjectives:    sloObjectives,
+		SLOWindow:        sloWindow,
+		SLOAlertBurnRate: sloAlertBurnRate,
+		SLOCheckInterval: sloCheckInterval,
+
+		// Metrics
+		MetricsToken: getEnv("METRICS_TOKEN", ""),
+
+		// gRPC
+		GRPCPort:  grpcPort,
+		GRPCToken: grpcToken,
+
+		// Tracing
+		TracingEndpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		TracingServiceName: getEnv("OTEL_SERVICE_NAME", "the-blade-api"),
+		TracingSampleRate:  tracingSampleRate,
 	}, nil
 }
 
@@ -109,10 +465,71 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("JWT_SECRET is required")
 	}
 
+	if c.AuthClaimsMode != models.ClaimsModeStrict && c.AuthClaimsMode != models.ClaimsModePerformance {
+		return fmt.Errorf("AUTH_CLAIMS_MODE must be strict or performance")
+	}
+
+	if !slices.Contains(password.Algorithms, c.PasswordHasher) {
+		return fmt.Errorf("PASSWORD_HASHER must be one of %s", strings.Join(password.Algorithms, ", "))
+	}
+
 	if c.DBPassword == "" {
 		return fmt.Errorf("DB_PASSWORD is required")
 	}
 
+	if c.RedisDegradedRateLimit != "allow" && c.RedisDegradedRateLimit != "deny" && c.RedisDegradedRateLimit != "local" {
+		return fmt.Errorf("REDIS_DEGRADED_RATE_LIMIT must be allow, deny or local")
+	}
+
+	if c.CacheVerifySampleRate < 0 || c.CacheVerifySampleRate > 1 {
+		return fmt.Errorf("CACHE_VERIFY_SAMPLE_RATE must be between 0 and 1")
+	}
+
+	if c.RedisHealthInterval <= 0 {
+		return fmt.Errorf("REDIS_HEALTH_INTERVAL must be positive")
+	}
+
+	if c.PresenceTTL <= 0 {
+		return fmt.Errorf("PRESENCE_TTL must be positive")
+	}
+
+	if c.UserPurgeMode != "delete" && c.UserPurgeMode != "anonymize" {
+		return fmt.Errorf("USER_PURGE_MODE must be delete or anonymize")
+	}
+
+	if c.ExpiredStockPolicy != models.ExpiredStockBlock && c.ExpiredStockPolicy != models.ExpiredStockWarn {
+		return fmt.Errorf("EXPIRED_STOCK_POLICY must be block or warn")
+	}
+
+	if c.SQLStatementBudget < 0 {
+		return fmt.Errorf("SQL_STATEMENT_BUDGET must not be negative")
+	}
+
+	if c.SLOWindow < time.Minute {
+		return fmt.Errorf("SLO_WINDOW must be at least 1m")
+	}
+
+	if c.SLOAlertBurnRate <= 0 {
+		return fmt.Errorf("SLO_ALERT_BURN_RATE must be positive")
+	}
+
+	if _, err := logger.ParseLevel(c.LogLevel); err != nil {
+		return fmt.Errorf("LOG_LEVEL must be debug, info, warn or error")
+	}
+
+	if c.TracingSampleRate < 0 || c.TracingSampleRate > 1 {
+		return fmt.Errorf("OTEL_TRACES_SAMPLE_RATE must be between 0 and 1")
+	}
+
+	if c.UserPurgeRetentionDays < 1 {
+		return fmt.Errorf("USER_PURGE_RETENTION_DAYS must be at least 1")
+	}
+
+	// Without the webhook, Stripe payments awaiting the customer's bank would never settle
+	if c.StripeSecretKey != "" && c.StripePaymentsWebhookSecret == "" {
+		return fmt.Errorf("STRIPE_PAYMENTS_WEBHOOK_SECRET is required with STRIPE_SECRET_KEY")
+	}
+
 	return nil
 }
 
@@ -128,3 +545,8 @@ func (c *Config) GetDSN() string {
 func (c *Config) GetServerAddr() string {
 	return fmt.Sprintf("%s:%s", c.ServerHost, c.ServerPort)
 }
+
+// GetGRPCAddr returns the address of the gRPC listener, on the host of the HTTP server
+func (c *Config) GetGRPCAddr() string {
+	return fmt.Sprintf("%s:%s", c.ServerHost, c.GRPCPort)
+}
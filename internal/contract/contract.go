@@ -0,0 +1,397 @@
+// Package contract describes the API for client generators: every route with who may call it,
+// the DTOs it reads and returns, whether it paginates and the error codes it can answer with.
+// It goes further than an OpenAPI document by carrying the permission model and the error
+// codes, so companion repositories can generate typed TypeScript and Go SDKs from it alone.
+//
+// Routes are described in routes.go next to their registration in cmd/main.go; Check reports
+// routes the router serves without a description, so the two can't drift apart unnoticed.
+// Descriptions use unversioned paths and describe the route in every API version serving it.
+package contract
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Aebroyx/the-blade-api/internal/apiversion"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/schema"
+	"github.com/gin-gonic/gin"
+)
+
+// Version of the contract document format; bumped when its shape changes
+const Version = 3
+
+// How a route authenticates its caller
+const (
+	AuthNone = "none"
+	// Signed in user, with a bearer token
+	AuthUser = "user"
+	// Paired terminal, with its device token
+	AuthDevice = "device"
+	// Identity provider, with the SCIM token
+	AuthSCIM = "scim"
+	// Webhook sender, with a signature of the body
+	AuthSignature = "signature"
+	// Metrics scraper, with the metrics token when one is configured
+	AuthMetrics = "metrics"
+)
+
+// How a route encodes its response
+const (
+	// JSON in the common.Response envelope, errors in common.ErrorResponse
+	EncodingEnvelope = "envelope"
+	// JSON without the envelope
+	EncodingRaw = "raw"
+	// Server-sent events, each carrying the Response type
+	EncodingStream = "stream"
+	// A file download
+	EncodingFile = "file"
+	// Plain text, e.g. the Prometheus exposition format
+	EncodingText = "text"
+	// A WebSocket; clients send messages of the Request type and are sent the Response type
+	EncodingWebSocket = "websocket"
+)
+
+// Route describes one route. Request, Query and Response are zero values of the DTO types;
+// a paginated route's Response is the type of one item.
+type Route struct {
+	Method string
+	Path   string
+	Auth   string
+	// Any of these roles may call the route
+	Roles []string
+	// Permission required, checked against the caller's role
+	Permission string
+	// Team roles required on the team in the :id parameter; empty with TeamMember set means
+	// any member
+	TeamRoles  []string
+	TeamMember bool
+	// Plan resource the route adds to, refused once the plan's capacity is reached
+	PlanResource string
+	// The route keeps uploaded files, refused once a storage quota is used up
+	StoresFiles bool
+	Request     any
+	// Request is sent as multipart form fields instead of JSON
+	Multipart bool
+	Query     any
+	Response  any
+	Paginated bool
+	// EncodingEnvelope when empty
+	Encoding string
+	// Status of a successful response; 200 when zero
+	Status int
+}
+
+// Document is the contract served at /api/v1/meta/contract. Revision changes whenever anything
+// in the document does, so generators can skip unchanged contracts.
+type Document struct {
+	Version    int                   `json:"version"`
+	Revision   string                `json:"revision"`
+	Envelope   Envelope              `json:"envelope"`
+	Pagination Pagination            `json:"pagination"`
+	ErrorCodes map[string]string     `json:"error_codes"`
+	Routes     []RouteDocument       `json:"routes"`
+	Types      map[string]TypeSchema `json:"types"`
+}
+
+// Envelope describes the JSON wrapped around every response. Every response carries its
+// request's ID in RequestIDHeader, and errors also return it as their top-level request_id field.
+type Envelope struct {
+	Success         TypeSchema `json:"success"`
+	Error           TypeSchema `json:"error"`
+	RequestIDHeader string     `json:"request_id_header"`
+	SchemaHeader    string     `json:"schema_header"`
+	SchemaVersion   string     `json:"schema_version_endpoint"`
+}
+
+// Pagination describes the query parameters and response of paginated routes
+type Pagination struct {
+	Query    []Parameter `json:"query"`
+	Response TypeSchema  `json:"response"`
+}
+
+// Parameter is a path or query parameter
+type Parameter struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+}
+
+// RouteDocument is a route as published in the contract
+type RouteDocument struct {
+	Method       string      `json:"method"`
+	Path         string      `json:"path"`
+	Version      string      `json:"version,omitempty"`
+	Operation    string      `json:"operation"`
+	Auth         string      `json:"auth"`
+	Roles        []string    `json:"roles,omitempty"`
+	Permission   string      `json:"permission,omitempty"`
+	TeamRoles    []string    `json:"team_roles,omitempty"`
+	TeamMember   bool        `json:"team_member,omitempty"`
+	PlanResource string      `json:"plan_resource,omitempty"`
+	PathParams   []Parameter `json:"path_params,omitempty"`
+	Request      *TypeRef    `json:"request,omitempty"`
+	RequestBody  string      `json:"request_body,omitempty"`
+	Query        *TypeRef    `json:"query,omitempty"`
+	Response     *TypeRef    `json:"response,omitempty"`
+	Paginated    bool        `json:"paginated,omitempty"`
+	Encoding     string      `json:"encoding"`
+	Status       int         `json:"status"`
+	Errors       []string    `json:"errors"`
+	Schema       SchemaInfo  `json:"schema"`
+	// Undocumented routes are served but not described yet; generators should skip them
+	Undocumented bool `json:"undocumented,omitempty"`
+}
+
+// SchemaInfo lists the response schema versions a route serves under its API version
+type SchemaInfo struct {
+	Current int `json:"current"`
+	Default int `json:"default"`
+	Oldest  int `json:"oldest"`
+}
+
+func key(method string, path string) string {
+	return method + " " + path
+}
+
+// described indexes the route table by method and path
+func described() map[string]Route {
+	result := make(map[string]Route, len(routes))
+	for _, route := range routes {
+		result[key(route.Method, route.Path)] = route
+	}
+	return result
+}
+
+// Check returns the routes the router serves without a description, as "METHOD path"
+func Check(served gin.RoutesInfo) []string {
+	table := described()
+	var missing []string
+	for _, route := range served {
+		if _, ok := table[key(route.Method, apiversion.Strip(route.Path))]; !ok {
+			missing = append(missing, key(route.Method, route.Path))
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Build describes the routes the router serves. Described routes that aren't served, such as
+// routes switched off by configuration, are left out.
+func Build(served gin.RoutesInfo) (*Document, error) {
+	types := newTypeRegistry()
+	table := described()
+
+	doc := &Document{
+		Version: Version,
+		Envelope: Envelope{
+			Success:         types.schemaOf(common.Response{}),
+			Error:           types.schemaOf(common.ErrorResponse{}),
+			RequestIDHeader: common.RequestIDHeader,
+			SchemaHeader:    schema.Header,
+			SchemaVersion:   "/api/" + apiversion.Default + "/system/schemas",
+		},
+		Pagination: Pagination{
+			Query:    paginationQuery,
+			Response: paginationResponse,
+		},
+		ErrorCodes: common.ErrorCodes,
+		Routes:     make([]RouteDocument, 0, len(served)),
+	}
+
+	for _, info := range served {
+		route, ok := table[key(info.Method, apiversion.Strip(info.Path))]
+		if !ok {
+			route = Route{Method: info.Method, Path: apiversion.Strip(info.Path)}
+		}
+		document := types.route(route)
+		document.Path = info.Path
+		document.Version = apiversion.Of(info.Path)
+		versions := schema.Lookup(info.Method, info.Path)
+		document.Schema = SchemaInfo{Current: versions.Current, Default: versions.Default, Oldest: versions.Oldest}
+		document.Undocumented = !ok
+		doc.Routes = append(doc.Routes, document)
+	}
+	sort.Slice(doc.Routes, func(i, j int) bool {
+		if doc.Routes[i].Path != doc.Routes[j].Path {
+			return doc.Routes[i].Path < doc.Routes[j].Path
+		}
+		return doc.Routes[i].Method < doc.Routes[j].Method
+	})
+	doc.Types = types.types
+
+	// encoding/json sorts map keys, so the same contract always hashes the same
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	doc.Revision = hex.EncodeToString(sum[:8])
+	return doc, nil
+}
+
+// route publishes a described route
+func (r *typeRegistry) route(route Route) RouteDocument {
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	auth := route.Auth
+	if auth == "" {
+		auth = AuthNone
+	}
+	encoding := route.Encoding
+	if encoding == "" {
+		encoding = EncodingEnvelope
+	}
+
+	document := RouteDocument{
+		Method:       route.Method,
+		Path:         route.Path,
+		Operation:    operation(route.Method, route.Path),
+		Auth:         auth,
+		Roles:        route.Roles,
+		Permission:   route.Permission,
+		TeamRoles:    route.TeamRoles,
+		TeamMember:   route.TeamMember,
+		PlanResource: route.PlanResource,
+		PathParams:   pathParams(route.Path),
+		Request:      r.ref(route.Request),
+		Query:        r.ref(route.Query),
+		Response:     r.ref(route.Response),
+		Paginated:    route.Paginated,
+		Encoding:     encoding,
+		Status:       status,
+		Errors:       routeErrors(route),
+	}
+	if route.Request != nil {
+		document.RequestBody = "json"
+		if route.Multipart {
+			document.RequestBody = "multipart"
+		}
+	}
+	return document
+}
+
+// operation derives a stable operation name from the method and unversioned path, e.g. "GET
+// /api/orders/:id/payments" becomes "get_orders_id_payments"
+func operation(method string, path string) string {
+	parts := []string{strings.ToLower(method)}
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment == "api" && len(parts) == 1 {
+			continue
+		}
+		segment = strings.TrimLeft(segment, ":*")
+		segment = strings.NewReplacer("-", "_", ".", "_").Replace(segment)
+		if segment != "" {
+			parts = append(parts, strings.ToLower(segment))
+		}
+	}
+	return strings.Join(parts, "_")
+}
+
+// pathParams lists the parameters in a route path
+func pathParams(path string) []Parameter {
+	var params []Parameter
+	for _, segment := range strings.Split(path, "/") {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		param := Parameter{Name: segment[1:], Type: "string"}
+		if param.Name == "id" || strings.HasSuffix(param.Name, "Id") {
+			param.Type = "integer"
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// routeErrors lists the error codes a route can answer with, beyond INTERNAL_ERROR and the
+// codes of the global middleware listed in globalErrors
+func routeErrors(route Route) []string {
+	codes := append([]string{}, globalErrors...)
+	switch route.Auth {
+	case AuthUser:
+		codes = append(codes, common.CodeUnauthorized, common.CodeAccountSuspended, common.CodeAccountPending,
+			common.CodePasswordChangeRequired, common.CodeQuotaExceeded)
+	case AuthDevice:
+		codes = append(codes, common.CodeInvalidDeviceToken, common.CodeDeviceDeactivated)
+	case AuthSCIM, AuthMetrics:
+		codes = append(codes, common.CodeUnauthorized)
+	case AuthSignature:
+		codes = append(codes, common.CodeInvalidWebhookSignature)
+	}
+	if len(route.Roles) > 0 || route.Permission != "" || route.TeamMember || len(route.TeamRoles) > 0 {
+		codes = append(codes, common.CodeForbidden)
+	}
+	if route.PlanResource != "" {
+		codes = append(codes, common.CodePlanLimitExceeded)
+	}
+	if route.StoresFiles {
+		codes = append(codes, common.CodeStorageQuotaExceeded)
+	}
+	if route.Request != nil {
+		codes = append(codes, common.CodeInvalidRequest, common.CodeValidationError)
+	}
+	if route.Query != nil || route.Paginated {
+		codes = append(codes, common.CodeInvalidRequest)
+	}
+	if strings.Contains(route.Path, ":") {
+		codes = append(codes, common.CodeInvalidRequest, common.CodeNotFound)
+	}
+	if route.Method != http.MethodGet {
+		codes = append(codes, common.CodeReadOnly)
+	}
+	if route.Encoding == EncodingWebSocket {
+		// Requests that aren't upgrades, or come from an origin CORS doesn't allow
+		codes = append(codes, common.CodeInvalidRequest, common.CodeForbidden)
+	}
+
+	seen := map[string]bool{}
+	result := codes[:0]
+	for _, code := range codes {
+		if !seen[code] {
+			seen[code] = true
+			result = append(result, code)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// globalErrors can be answered by any route, from the middleware every request passes through
+var globalErrors = []string{
+	common.CodeInternalError,
+	common.CodeLimitExceeded,
+	common.CodePaymentRequired,
+	common.CodeRateLimited,
+	common.CodeUnsupportedSchemaVersion,
+}
+
+// paginationQuery lists the query parameters every paginated route reads
+var paginationQuery = []Parameter{
+	{Name: "page", Type: "integer", Description: "Page number, from 1"},
+	{Name: "pageSize", Type: "integer", Description: "Items per page"},
+	{Name: "search", Type: "string", Description: "Free-text search over the route's search fields"},
+	{Name: "sortBy", Type: "string", Description: "Field to sort by; unknown fields fall back to the default sort"},
+	{Name: "sortDesc", Type: "boolean", Description: "Sort descending"},
+	{Name: "filters[<field>]", Type: "string", Description: "Exact match filter; unknown fields are ignored"},
+	{Name: "dates[<field>][start]", Type: "string", Description: "Start of a date range, an RFC 3339 timestamp or YYYY-MM-DD"},
+	{Name: "dates[<field>][end]", Type: "string", Description: "End of a date range, an RFC 3339 timestamp or YYYY-MM-DD; plain dates cover the whole day"},
+}
+
+// paginationResponse is the data of a paginated response, with items of the route's Response
+var paginationResponse = TypeSchema{
+	Kind: "object",
+	Fields: []Field{
+		{Name: "data", Type: TypeRef{Kind: "array", Items: &TypeRef{Kind: "item"}}, Required: true},
+		{Name: "total", Type: TypeRef{Kind: "integer"}, Required: true},
+		{Name: "page", Type: TypeRef{Kind: "integer"}, Required: true},
+		{Name: "pageSize", Type: TypeRef{Kind: "integer"}, Required: true},
+		{Name: "totalPages", Type: TypeRef{Kind: "integer"}, Required: true},
+	},
+}
@@ -0,0 +1,241 @@
+package contract
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TypeRef refers to a type: a primitive kind, an array or map of another type, or a named DTO
+// listed in the document's types
+type TypeRef struct {
+	// string, integer, number, boolean, datetime, any, array, map, ref, or item for the items
+	// of a paginated response
+	Kind     string   `json:"kind"`
+	Name     string   `json:"name,omitempty"`
+	Items    *TypeRef `json:"items,omitempty"`
+	Nullable bool     `json:"nullable,omitempty"`
+}
+
+// TypeSchema describes a DTO
+type TypeSchema struct {
+	Kind   string  `json:"kind"`
+	Fields []Field `json:"fields,omitempty"`
+	// Element type of DTOs that are named slices or maps
+	Items *TypeRef `json:"items,omitempty"`
+}
+
+// Field is a field of a DTO, with the validation rules enforced on requests
+type Field struct {
+	Name      string   `json:"name"`
+	Type      TypeRef  `json:"type"`
+	Required  bool     `json:"required,omitempty"`
+	OmitEmpty bool     `json:"omit_empty,omitempty"`
+	Enum      []string `json:"enum,omitempty"`
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+	Validate  string   `json:"validate,omitempty"`
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// typeRegistry collects the DTOs reachable from the described routes
+type typeRegistry struct {
+	types map[string]TypeSchema
+}
+
+func newTypeRegistry() *typeRegistry {
+	return &typeRegistry{types: map[string]TypeSchema{}}
+}
+
+// ref describes the type of a DTO value, registering named types; nil for no DTO
+func (r *typeRegistry) ref(value any) *TypeRef {
+	if value == nil {
+		return nil
+	}
+	ref := r.typeRef(reflect.TypeOf(value))
+	return &ref
+}
+
+// schemaOf describes a struct inline, without registering it
+func (r *typeRegistry) schemaOf(value any) TypeSchema {
+	return r.describe(reflect.TypeOf(value))
+}
+
+// typeName names a DTO after its package and type, e.g. "models.Orders"
+func typeName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	return pkg + "." + t.Name()
+}
+
+func (r *typeRegistry) typeRef(t reflect.Type) TypeRef {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		nullable = true
+		t = t.Elem()
+	}
+
+	ref := r.kindRef(t)
+	ref.Nullable = ref.Nullable || nullable
+	return ref
+}
+
+func (r *typeRegistry) kindRef(t reflect.Type) TypeRef {
+	switch {
+	case t == timeType:
+		return TypeRef{Kind: "datetime"}
+	case t == deletedAtType:
+		return TypeRef{Kind: "datetime", Nullable: true}
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		// Custom JSON encodings can't be described field by field
+		return TypeRef{Kind: "any"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return TypeRef{Kind: "string"}
+	case reflect.Bool:
+		return TypeRef{Kind: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return TypeRef{Kind: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return TypeRef{Kind: "number"}
+	case reflect.Interface:
+		return TypeRef{Kind: "any"}
+	}
+
+	if t.Name() != "" && (t.Kind() == reflect.Struct || t.Kind() == reflect.Slice || t.Kind() == reflect.Map) {
+		name := typeName(t)
+		if _, ok := r.types[name]; !ok {
+			// Registered before describing, so recursive types terminate
+			r.types[name] = TypeSchema{Kind: "object"}
+			r.types[name] = r.describe(t)
+		}
+		return TypeRef{Kind: "ref", Name: name}
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := r.typeRef(t.Elem())
+		return TypeRef{Kind: "array", Items: &items, Nullable: t.Kind() == reflect.Slice}
+	case reflect.Map:
+		items := r.typeRef(t.Elem())
+		return TypeRef{Kind: "map", Items: &items}
+	case reflect.Struct:
+		return TypeRef{Kind: "any"}
+	}
+	return TypeRef{Kind: "any"}
+}
+
+// describe lists the JSON fields of a struct, or the element type of a named slice or map
+func (r *typeRegistry) describe(t reflect.Type) TypeSchema {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := r.typeRef(t.Elem())
+		return TypeSchema{Kind: "array", Items: &items}
+	case reflect.Map:
+		items := r.typeRef(t.Elem())
+		return TypeSchema{Kind: "map", Items: &items}
+	}
+
+	return TypeSchema{Kind: "object", Fields: r.fields(t)}
+}
+
+// fields lists the JSON fields of a struct, flattening embedded structs as encoding/json does
+func (r *typeRegistry) fields(t reflect.Type) []Field {
+	var fields []Field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, omitEmpty, skip := jsonName(sf)
+		if skip {
+			continue
+		}
+		if sf.Anonymous && name == "" {
+			embedded := sf.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, r.fields(embedded)...)
+				continue
+			}
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		field := Field{
+			Name:      name,
+			Type:      r.typeRef(sf.Type),
+			OmitEmpty: omitEmpty,
+		}
+		applyValidation(&field, sf.Tag.Get("validate"))
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// jsonName reads the name a field is encoded under; request structs bound from forms use their
+// form tag
+func jsonName(sf reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	tag, ok := sf.Tag.Lookup("json")
+	if !ok {
+		tag = sf.Tag.Get("form")
+	}
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return parts[0], omitEmpty, false
+}
+
+// applyValidation copies the validator rules clients can check before sending a request
+func applyValidation(field *Field, rules string) {
+	if rules == "" {
+		return
+	}
+	field.Validate = rules
+
+	for _, rule := range strings.Split(rules, ",") {
+		// Rules after dive apply to the elements
+		if rule == "dive" {
+			return
+		}
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			field.Required = true
+		case "oneof":
+			field.Enum = strings.Fields(param)
+		case "min", "gte":
+			if value, err := strconv.ParseFloat(param, 64); err == nil {
+				field.Min = &value
+			}
+		case "max", "lte":
+			if value, err := strconv.ParseFloat(param, 64); err == nil {
+				field.Max = &value
+			}
+		}
+	}
+}
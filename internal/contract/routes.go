@@ -0,0 +1,390 @@
+package contract
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/license"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/schema"
+	"github.com/Aebroyx/the-blade-api/internal/slo"
+)
+
+// routes describes every route registered in cmd/main.go, in the same order, with API routes
+// given without their version. Describe a route here when adding it there; routes served
+// without a description are logged at startup and published as undocumented.
+var routes = []Route{
+	// Probes and the metrics scrape, served ahead of all middleware
+	{Method: http.MethodGet, Path: "/healthz", Auth: AuthNone, Response: models.HealthResponse{}, Encoding: EncodingRaw},
+	{Method: http.MethodGet, Path: "/readyz", Auth: AuthNone, Response: models.HealthResponse{}, Encoding: EncodingRaw},
+	{Method: http.MethodGet, Path: "/metrics", Auth: AuthMetrics, Encoding: EncodingText},
+
+	// Public routes
+	{Method: http.MethodPost, Path: "/api/auth/register", Auth: AuthNone, PlanResource: models.PlanResourceUsers, Request: models.RegisterRequest{}, Response: models.RegisterResponse{}, Encoding: EncodingRaw, Status: http.StatusCreated},
+	{Method: http.MethodPost, Path: "/api/auth/login", Auth: AuthNone, Request: models.LoginRequest{}, Encoding: EncodingRaw},
+	{Method: http.MethodPost, Path: "/api/auth/confirm-email", Auth: AuthNone, Request: models.ConfirmEmailRequest{}, Response: models.Users{}},
+	{Method: http.MethodPost, Path: "/api/auth/reset-password", Auth: AuthNone, Request: models.ResetPasswordRequest{}},
+	{Method: http.MethodGet, Path: "/api/auth/check-availability", Auth: AuthNone, Query: models.AvailabilityQuery{}, Response: models.AvailabilityResponse{}},
+	{Method: http.MethodGet, Path: "/api/meta/contract", Auth: AuthNone, Response: Document{}},
+	{Method: http.MethodGet, Path: "/api/uploads/*filepath", Auth: AuthNone, Encoding: EncodingFile},
+	{Method: http.MethodHead, Path: "/api/uploads/*filepath", Auth: AuthNone, Encoding: EncodingFile},
+
+	// Terminals, authenticated with their device token; pairing needs no token
+	{Method: http.MethodPost, Path: "/api/devices/pair", Auth: AuthNone, Request: models.PairDeviceRequest{}, Response: models.PairDeviceResponse{}},
+	{Method: http.MethodGet, Path: "/api/terminal/config", Auth: AuthDevice, Response: models.TerminalConfigResponse{}},
+	{Method: http.MethodGet, Path: "/api/terminal/print-jobs/next", Auth: AuthDevice, Response: models.PrintJobs{}},
+	{Method: http.MethodPost, Path: "/api/terminal/print-jobs/:id/ack", Auth: AuthDevice, Request: models.AckPrintJobRequest{}, Response: models.PrintJobs{}},
+	{Method: http.MethodGet, Path: "/api/terminal/experiments", Auth: AuthDevice, Response: map[string]string{}},
+	{Method: http.MethodGet, Path: "/api/terminal/ws", Auth: AuthDevice, Request: models.RealtimeClientMessage{}, Response: models.RealtimeEvent{}, Encoding: EncodingWebSocket},
+
+	// Self-serve tenant signup, when enabled
+	{Method: http.MethodPost, Path: "/api/tenants/signup", Auth: AuthNone, Request: models.TenantSignupRequest{}, Response: models.TenantProvisioningStatus{}, Status: http.StatusCreated},
+	{Method: http.MethodPost, Path: "/api/tenants/signup/verify", Auth: AuthNone, Request: models.VerifyTenantSignupRequest{}, Response: models.TenantProvisioningStatus{}},
+	{Method: http.MethodGet, Path: "/api/tenants/signup/status", Auth: AuthNone, Response: models.TenantProvisioningStatus{}},
+
+	// Stripe Billing webhook, when configured
+	{Method: http.MethodPost, Path: "/api/billing/stripe/webhook", Auth: AuthSignature},
+
+	// Stripe payments webhook, when configured
+	{Method: http.MethodPost, Path: "/api/webhooks/stripe", Auth: AuthSignature},
+
+	// SCIM provisioning, when configured
+	{Method: http.MethodGet, Path: "/scim/v2/ServiceProviderConfig", Auth: AuthSCIM, Encoding: EncodingRaw},
+	{Method: http.MethodGet, Path: "/scim/v2/Users", Auth: AuthSCIM, Response: models.SCIMListResponse{}, Encoding: EncodingRaw},
+	{Method: http.MethodPost, Path: "/scim/v2/Users", Auth: AuthSCIM, Request: models.SCIMUser{}, Response: models.SCIMUser{}, Encoding: EncodingRaw, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/scim/v2/Users/:id", Auth: AuthSCIM, Response: models.SCIMUser{}, Encoding: EncodingRaw},
+	{Method: http.MethodPut, Path: "/scim/v2/Users/:id", Auth: AuthSCIM, Request: models.SCIMUser{}, Response: models.SCIMUser{}, Encoding: EncodingRaw},
+	{Method: http.MethodPatch, Path: "/scim/v2/Users/:id", Auth: AuthSCIM, Request: models.SCIMPatchRequest{}, Response: models.SCIMUser{}, Encoding: EncodingRaw},
+	{Method: http.MethodDelete, Path: "/scim/v2/Users/:id", Auth: AuthSCIM, Encoding: EncodingRaw, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/scim/v2/Groups", Auth: AuthSCIM, Response: models.SCIMListResponse{}, Encoding: EncodingRaw},
+	{Method: http.MethodPost, Path: "/scim/v2/Groups", Auth: AuthSCIM, Request: models.SCIMGroup{}, Response: models.SCIMGroup{}, Encoding: EncodingRaw, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/scim/v2/Groups/:id", Auth: AuthSCIM, Response: models.SCIMGroup{}, Encoding: EncodingRaw},
+	{Method: http.MethodPut, Path: "/scim/v2/Groups/:id", Auth: AuthSCIM, Request: models.SCIMGroup{}, Response: models.SCIMGroup{}, Encoding: EncodingRaw},
+	{Method: http.MethodPatch, Path: "/scim/v2/Groups/:id", Auth: AuthSCIM, Request: models.SCIMPatchRequest{}, Response: models.SCIMGroup{}, Encoding: EncodingRaw},
+	{Method: http.MethodDelete, Path: "/scim/v2/Groups/:id", Auth: AuthSCIM, Encoding: EncodingRaw, Status: http.StatusNoContent},
+
+	// Signed in users
+	{Method: http.MethodGet, Path: "/api/me", Auth: AuthUser, Response: models.RegisterResponse{}, Encoding: EncodingRaw},
+	{Method: http.MethodPut, Path: "/api/me/password", Auth: AuthUser, Request: models.ChangePasswordRequest{}},
+	{Method: http.MethodGet, Path: "/api/me/settings", Auth: AuthUser, Response: models.UserSettings{}},
+	{Method: http.MethodPut, Path: "/api/me/settings", Auth: AuthUser, Request: models.JSONMap{}, Response: models.UserSettings{}},
+	{Method: http.MethodGet, Path: "/api/me/notification-preferences", Auth: AuthUser, Response: models.NotificationPreferencesResponse{}},
+	{Method: http.MethodPut, Path: "/api/me/notification-preferences", Auth: AuthUser, Request: models.UpdateNotificationPreferencesRequest{}, Response: models.NotificationPreferencesResponse{}},
+	{Method: http.MethodGet, Path: "/api/me/notifications", Auth: AuthUser, Paginated: true, Response: models.Notifications{}},
+	{Method: http.MethodPut, Path: "/api/me/notifications/read", Auth: AuthUser, Response: models.MarkNotificationsReadResponse{}},
+	{Method: http.MethodPut, Path: "/api/me/notifications/:id/read", Auth: AuthUser, Response: models.Notifications{}},
+	{Method: http.MethodGet, Path: "/api/me/activity", Auth: AuthUser, Paginated: true, Response: models.UserActivities{}},
+	{Method: http.MethodGet, Path: "/api/me/experiments", Auth: AuthUser, Response: map[string]string{}},
+	{Method: http.MethodPost, Path: "/api/auth/logout", Auth: AuthUser, Encoding: EncodingRaw},
+	{Method: http.MethodGet, Path: "/api/users", Auth: AuthUser, Permission: models.PermissionUsersView, Paginated: true, Response: models.Users{}},
+	{Method: http.MethodGet, Path: "/api/users/export", Auth: AuthUser, Permission: models.PermissionUsersView, Encoding: EncodingFile},
+	{Method: http.MethodGet, Path: "/api/users/online", Auth: AuthUser, Permission: models.PermissionUsersOnline, Response: []models.OnlineUser{}},
+	{Method: http.MethodGet, Path: "/api/users/deleted", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Paginated: true, Response: models.Users{}},
+	{Method: http.MethodPost, Path: "/api/users/bulk", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.BulkUserRequest{}, Response: models.BulkUserResponse{}},
+	{Method: http.MethodPost, Path: "/api/users/merge", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.MergeUsersRequest{}, Response: models.MergeUsersResponse{}},
+	{Method: http.MethodPost, Path: "/api/users/purge", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.UserPurgeReport{}},
+	{Method: http.MethodGet, Path: "/api/user/:id", Auth: AuthUser, Permission: models.PermissionUsersView, Response: models.Users{}},
+	{Method: http.MethodPost, Path: "/api/user/create", Auth: AuthUser, PlanResource: models.PlanResourceUsers, Request: models.CreateUserRequest{}, Response: models.SCIMUser{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/user/:id", Auth: AuthUser, Request: models.UpdateUserRequest{}, Response: models.Users{}},
+	{Method: http.MethodDelete, Path: "/api/user/:id", Auth: AuthUser, Response: models.Users{}},
+	{Method: http.MethodPut, Path: "/api/user/:id/soft-delete", Auth: AuthUser, Response: models.Users{}},
+	{Method: http.MethodGet, Path: "/api/user/:id/activity", Auth: AuthUser, Permission: models.PermissionActivityView, Paginated: true, Response: models.UserActivities{}},
+	{Method: http.MethodGet, Path: "/api/user/:id/logins", Auth: AuthUser, Permission: models.PermissionActivityView, Paginated: true, Response: models.LoginEvents{}},
+	{Method: http.MethodPut, Path: "/api/user/:id/restore", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Users{}},
+	{Method: http.MethodPut, Path: "/api/user/:id/suspend", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Users{}},
+	{Method: http.MethodPut, Path: "/api/user/:id/reactivate", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Users{}},
+	{Method: http.MethodPost, Path: "/api/user/:id/reset-password", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.AdminResetPasswordRequest{}, Response: models.AdminResetPasswordResponse{}},
+	{Method: http.MethodPost, Path: "/api/user/:id/tags", Auth: AuthUser, Permission: models.PermissionUsersTag, Request: models.AssignUserTagsRequest{}, Response: []models.Tags{}},
+	{Method: http.MethodDelete, Path: "/api/user/:id/tags/:tagId", Auth: AuthUser, Permission: models.PermissionUsersTag, Response: []models.Tags{}},
+	{Method: http.MethodGet, Path: "/api/user/:id/quota", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.UserQuotaStatus{}},
+	{Method: http.MethodPut, Path: "/api/user/:id/quota", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.UpdateUserQuotaRequest{}, Response: models.UserQuotaStatus{}},
+	{Method: http.MethodPost, Path: "/api/user/:id/quota/reset", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.UserQuotaStatus{}},
+	{Method: http.MethodGet, Path: "/api/user/:id/stores", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: []models.UserStores{}},
+	{Method: http.MethodPut, Path: "/api/user/:id/stores", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.SetUserStoresRequest{}, Response: []models.UserStores{}},
+	{Method: http.MethodGet, Path: "/api/tags", Auth: AuthUser, Paginated: true, Response: models.Tags{}},
+	{Method: http.MethodPost, Path: "/api/tags", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.TagRequest{}, Response: models.Tags{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/tags/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.TagRequest{}, Response: models.Tags{}},
+	{Method: http.MethodDelete, Path: "/api/tags/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Tags{}},
+	{Method: http.MethodGet, Path: "/api/categories", Auth: AuthUser, Response: []models.Categories{}},
+	{Method: http.MethodGet, Path: "/api/categories/:id", Auth: AuthUser, Response: models.Categories{}},
+	{Method: http.MethodPost, Path: "/api/categories", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.CreateCategoryRequest{}, Response: models.Categories{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/categories/reorder", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.ReorderCategoriesRequest{}, Response: []models.Categories{}},
+	{Method: http.MethodPut, Path: "/api/categories/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.UpdateCategoryRequest{}, Response: models.Categories{}},
+	{Method: http.MethodDelete, Path: "/api/categories/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Categories{}},
+	{Method: http.MethodGet, Path: "/api/products", Auth: AuthUser, Paginated: true, Response: models.Products{}},
+	{Method: http.MethodGet, Path: "/api/products/barcode/:code", Auth: AuthUser, Response: models.BarcodeLookupResponse{}},
+	{Method: http.MethodGet, Path: "/api/products/export", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Encoding: EncodingFile},
+	{Method: http.MethodPost, Path: "/api/products/import", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.ProductImportRequest{}, Multipart: true, StoresFiles: true, Response: models.Imports{}},
+	{Method: http.MethodGet, Path: "/api/products/:id", Auth: AuthUser, Response: models.Products{}},
+	{Method: http.MethodPost, Path: "/api/products", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.CreateProductRequest{}, Response: models.Products{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/products/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.UpdateProductRequest{}, Response: models.Products{}},
+	{Method: http.MethodDelete, Path: "/api/products/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Products{}},
+	{Method: http.MethodGet, Path: "/api/products/:id/variants", Auth: AuthUser, Response: models.ProductVariantsResponse{}},
+	{Method: http.MethodPut, Path: "/api/products/:id/options", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.SetProductOptionsRequest{}, Response: models.ProductVariantsResponse{}},
+	{Method: http.MethodPost, Path: "/api/products/:id/variants/generate", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.GenerateProductVariantsResponse{}},
+	{Method: http.MethodPut, Path: "/api/products/:id/variants/:variantId", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.UpdateProductVariantRequest{}, Response: models.ProductVariants{}},
+	{Method: http.MethodDelete, Path: "/api/products/:id/variants/:variantId", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.ProductVariants{}},
+	{Method: http.MethodGet, Path: "/api/products/:id/components", Auth: AuthUser, Response: []models.BundleComponents{}},
+	{Method: http.MethodPut, Path: "/api/products/:id/components", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.SetBundleComponentsRequest{}, Response: []models.BundleComponents{}},
+	{Method: http.MethodGet, Path: "/api/products/:id/suppliers", Auth: AuthUser, Permission: models.PermissionSuppliersView, Response: []models.ProductSuppliers{}},
+	{Method: http.MethodPut, Path: "/api/products/:id/suppliers", Auth: AuthUser, Permission: models.PermissionSuppliersManage, Request: models.SetProductSuppliersRequest{}, Response: []models.ProductSuppliers{}},
+	{Method: http.MethodGet, Path: "/api/products/:id/units", Auth: AuthUser, Response: []models.ProductUnits{}},
+	{Method: http.MethodPut, Path: "/api/products/:id/units", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.SetProductUnitsRequest{}, Response: []models.ProductUnits{}},
+	{Method: http.MethodGet, Path: "/api/products/:id/images", Auth: AuthUser, Response: []models.ProductImages{}},
+	{Method: http.MethodPost, Path: "/api/products/:id/images", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Multipart: true, StoresFiles: true, Response: []models.ProductImages{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/products/:id/images/order", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.ReorderProductImagesRequest{}, Response: []models.ProductImages{}},
+	{Method: http.MethodPut, Path: "/api/products/:id/images/:imageId/primary", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: []models.ProductImages{}},
+	{Method: http.MethodDelete, Path: "/api/products/:id/images/:imageId", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: []models.ProductImages{}},
+	{Method: http.MethodGet, Path: "/api/inventory/stock", Auth: AuthUser, Permission: models.PermissionInventoryView, Paginated: true},
+	{Method: http.MethodGet, Path: "/api/inventory/movements", Auth: AuthUser, Permission: models.PermissionInventoryView, Paginated: true, Response: models.StockMovements{}},
+	{Method: http.MethodGet, Path: "/api/inventory/low-stock", Auth: AuthUser, Permission: models.PermissionInventoryView, Paginated: true},
+	{Method: http.MethodPost, Path: "/api/inventory/adjustments", Auth: AuthUser, Permission: models.PermissionInventoryAdjust, Request: models.StockAdjustmentRequest{}, Response: []models.StockMovements{}, Status: http.StatusCreated},
+	{Method: http.MethodPost, Path: "/api/inventory/receipts", Auth: AuthUser, Permission: models.PermissionInventoryAdjust, Request: models.StockReceiptRequest{}, Response: []models.StockMovements{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/inventory/serials", Auth: AuthUser, Permission: models.PermissionInventoryView, Paginated: true, Response: models.StockSerials{}},
+	{Method: http.MethodGet, Path: "/api/inventory/batches", Auth: AuthUser, Permission: models.PermissionInventoryView, Paginated: true, Response: models.StockBatches{}},
+	{Method: http.MethodGet, Path: "/api/inventory/batches/recall", Auth: AuthUser, Permission: models.PermissionInventoryView, Query: models.BatchRecallQuery{}, Response: models.BatchRecall{}},
+	{Method: http.MethodGet, Path: "/api/inventory/expiring", Auth: AuthUser, Permission: models.PermissionInventoryView, Paginated: true, Query: models.ExpiringStockQuery{}},
+	{Method: http.MethodGet, Path: "/api/stocktakes", Auth: AuthUser, Permission: models.PermissionInventoryView, Paginated: true, Response: models.Stocktakes{}},
+	{Method: http.MethodGet, Path: "/api/stocktakes/:id", Auth: AuthUser, Permission: models.PermissionInventoryView, Response: models.Stocktakes{}},
+	{Method: http.MethodGet, Path: "/api/stocktakes/:id/report", Auth: AuthUser, Permission: models.PermissionInventoryView, Response: models.StocktakeReport{}},
+	{Method: http.MethodPost, Path: "/api/stocktakes", Auth: AuthUser, Permission: models.PermissionInventoryCount, Request: models.CreateStocktakeRequest{}, Response: models.Stocktakes{}, Status: http.StatusCreated},
+	{Method: http.MethodPost, Path: "/api/stocktakes/:id/counts", Auth: AuthUser, Permission: models.PermissionInventoryCount, Request: models.StocktakeCountRequest{}, Response: models.Stocktakes{}},
+	{Method: http.MethodPost, Path: "/api/stocktakes/:id/scans", Auth: AuthUser, Permission: models.PermissionInventoryCount, Request: models.StocktakeScanRequest{}, Response: models.StocktakeScanResult{}},
+	{Method: http.MethodPut, Path: "/api/stocktakes/:id/approve", Auth: AuthUser, Permission: models.PermissionInventoryAdjust, Response: models.Stocktakes{}},
+	{Method: http.MethodPut, Path: "/api/stocktakes/:id/cancel", Auth: AuthUser, Permission: models.PermissionInventoryAdjust, Response: models.Stocktakes{}},
+	{Method: http.MethodGet, Path: "/api/orders", Auth: AuthUser, Permission: models.PermissionOrdersView, Paginated: true, Response: models.Orders{}},
+	{Method: http.MethodPost, Path: "/api/orders", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.CreateOrderRequest{}, Response: models.Orders{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/orders/:id", Auth: AuthUser, Permission: models.PermissionOrdersView, Response: models.Orders{}},
+	{Method: http.MethodPut, Path: "/api/orders/:id/place", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.OrderStatusRequest{}, Response: models.Orders{}},
+	{Method: http.MethodPut, Path: "/api/orders/:id/fulfill", Auth: AuthUser, Permission: models.PermissionOrdersManage, Request: models.OrderStatusRequest{}, Response: models.Orders{}},
+	{Method: http.MethodPut, Path: "/api/orders/:id/complete", Auth: AuthUser, Permission: models.PermissionOrdersManage, Request: models.OrderStatusRequest{}, Response: models.Orders{}},
+	{Method: http.MethodPut, Path: "/api/orders/:id/cancel", Auth: AuthUser, Permission: models.PermissionOrdersManage, Request: models.OrderStatusRequest{}, Response: models.Orders{}},
+	{Method: http.MethodGet, Path: "/api/orders/:id/payments", Auth: AuthUser, Permission: models.PermissionOrdersView, Response: models.OrderPaymentsResponse{}},
+	{Method: http.MethodPost, Path: "/api/orders/:id/payments", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.CreatePaymentRequest{}, Response: models.Payments{}, Status: http.StatusCreated},
+	{Method: http.MethodPost, Path: "/api/orders/:id/payments/:paymentId/capture", Auth: AuthUser, Permission: models.PermissionOrdersManage, Response: models.Payments{}},
+	{Method: http.MethodPost, Path: "/api/orders/:id/payments/:paymentId/refund", Auth: AuthUser, Permission: models.PermissionOrdersManage, Request: models.RefundPaymentRequest{}, Response: models.Payments{}},
+	{Method: http.MethodPost, Path: "/api/orders/:id/refund", Auth: AuthUser, Permission: models.PermissionOrdersRefund, Request: models.RefundOrderRequest{}, Response: models.OrderReturns{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/orders/:id/returns", Auth: AuthUser, Permission: models.PermissionOrdersView, Response: []models.OrderReturns{}},
+	{Method: http.MethodGet, Path: "/api/orders/:id/receipt", Auth: AuthUser, Permission: models.PermissionOrdersView, Query: models.ReceiptQuery{}, Encoding: EncodingFile},
+	{Method: http.MethodPost, Path: "/api/orders/:id/receipt/email", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.EmailReceiptRequest{}, Response: models.ReceiptEmails{}, Status: http.StatusAccepted},
+	{Method: http.MethodGet, Path: "/api/orders/:id/receipt/emails", Auth: AuthUser, Permission: models.PermissionOrdersView, Response: []models.ReceiptEmails{}},
+	{Method: http.MethodPut, Path: "/api/orders/:id/customer", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.SetCustomerRequest{}, Response: models.Orders{}},
+	{Method: http.MethodGet, Path: "/api/returns", Auth: AuthUser, Permission: models.PermissionOrdersRefund, Paginated: true, Response: models.OrderReturns{}},
+	{Method: http.MethodGet, Path: "/api/payments/methods", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Response: []string{}},
+	{Method: http.MethodGet, Path: "/api/payments/reconciliation", Auth: AuthUser, Permission: models.PermissionOrdersManage, Paginated: true},
+	{Method: http.MethodGet, Path: "/api/cash-drawers", Auth: AuthUser, Permission: models.PermissionOrdersManage, Paginated: true, Response: models.CashDrawerSessions{}},
+	{Method: http.MethodPost, Path: "/api/cash-drawers/open", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.OpenCashDrawerRequest{}, Response: models.CashDrawerSessions{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/cash-drawers/current", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Response: models.CashDrawerReport{}},
+	{Method: http.MethodGet, Path: "/api/cash-drawers/:id", Auth: AuthUser, Permission: models.PermissionOrdersManage, Response: models.CashDrawerReport{}},
+	{Method: http.MethodPost, Path: "/api/cash-drawers/:id/movements", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.CashMovementRequest{}, Response: models.CashDrawerMovements{}, Status: http.StatusCreated},
+	{Method: http.MethodPost, Path: "/api/cash-drawers/:id/close", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.CloseCashDrawerRequest{}, Response: models.CashDrawerReport{}},
+	{Method: http.MethodGet, Path: "/api/shifts", Auth: AuthUser, Permission: models.PermissionOrdersManage, Paginated: true, Response: models.Shifts{}},
+	{Method: http.MethodPost, Path: "/api/shifts/open", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.OpenShiftRequest{}, Response: models.Shifts{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/shifts/current", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Response: models.ShiftReport{}},
+	{Method: http.MethodGet, Path: "/api/shifts/:id", Auth: AuthUser, Permission: models.PermissionOrdersManage, Response: models.ShiftReport{}},
+	{Method: http.MethodPost, Path: "/api/shifts/:id/close", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.CloseShiftRequest{}, Response: models.ShiftReport{}},
+	{Method: http.MethodGet, Path: "/api/cart", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Response: models.CartResponse{}},
+	{Method: http.MethodPut, Path: "/api/cart", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.UpdateCartRequest{}, Response: models.CartResponse{}},
+	{Method: http.MethodDelete, Path: "/api/cart", Auth: AuthUser, Permission: models.PermissionOrdersCreate},
+	{Method: http.MethodPost, Path: "/api/cart/items", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.AddCartItemRequest{}, Response: models.CartResponse{}},
+	{Method: http.MethodPut, Path: "/api/cart/items/:lineId", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.UpdateCartItemRequest{}, Response: models.CartResponse{}},
+	{Method: http.MethodDelete, Path: "/api/cart/items/:lineId", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Response: models.CartResponse{}},
+	{Method: http.MethodPut, Path: "/api/cart/customer", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.SetCustomerRequest{}, Response: models.CartResponse{}},
+	{Method: http.MethodPost, Path: "/api/cart/apply-coupon", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.ApplyCouponRequest{}, Response: models.CartResponse{}},
+	{Method: http.MethodDelete, Path: "/api/cart/coupon", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Response: models.CartResponse{}},
+	{Method: http.MethodPost, Path: "/api/cart/checkout", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Response: models.Orders{}, Status: http.StatusCreated},
+	{Method: http.MethodPost, Path: "/api/cart/park", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.ParkCartRequest{}, Response: models.CartResponse{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/parked-sales", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Paginated: true, Response: models.Carts{}},
+	{Method: http.MethodGet, Path: "/api/parked-sales/:id", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Response: models.CartResponse{}},
+	{Method: http.MethodPost, Path: "/api/parked-sales/:id/resume", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Response: models.CartResponse{}},
+	{Method: http.MethodDelete, Path: "/api/parked-sales/:id", Auth: AuthUser, Permission: models.PermissionOrdersCreate},
+	{Method: http.MethodGet, Path: "/api/dining-areas", Auth: AuthUser, Paginated: true, Response: models.DiningAreas{}},
+	{Method: http.MethodGet, Path: "/api/dining-areas/:id", Auth: AuthUser, Response: models.DiningAreas{}},
+	{Method: http.MethodPost, Path: "/api/dining-areas", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.DiningAreaRequest{}, Response: models.DiningAreas{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/dining-areas/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.DiningAreaRequest{}, Response: models.DiningAreas{}},
+	{Method: http.MethodDelete, Path: "/api/dining-areas/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.DiningAreas{}},
+	{Method: http.MethodGet, Path: "/api/tables", Auth: AuthUser, Paginated: true, Response: models.DiningTableStatus{}},
+	{Method: http.MethodGet, Path: "/api/tables/:id", Auth: AuthUser, Response: models.DiningTables{}},
+	{Method: http.MethodPost, Path: "/api/tables", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.DiningTableRequest{}, Response: models.DiningTables{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/tables/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.DiningTableRequest{}, Response: models.DiningTables{}},
+	{Method: http.MethodDelete, Path: "/api/tables/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.DiningTables{}},
+	{Method: http.MethodGet, Path: "/api/tables/:id/ticket", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Response: models.CartResponse{}},
+	{Method: http.MethodPut, Path: "/api/tables/:id/ticket", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.UpdateCartRequest{}, Response: models.CartResponse{}},
+	{Method: http.MethodDelete, Path: "/api/tables/:id/ticket", Auth: AuthUser, Permission: models.PermissionOrdersCreate},
+	{Method: http.MethodPost, Path: "/api/tables/:id/ticket/items", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.AddCartItemRequest{}, Response: models.CartResponse{}},
+	{Method: http.MethodPut, Path: "/api/tables/:id/ticket/items/:lineId", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.UpdateCartItemRequest{}, Response: models.CartResponse{}},
+	{Method: http.MethodDelete, Path: "/api/tables/:id/ticket/items/:lineId", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Response: models.CartResponse{}},
+	{Method: http.MethodPut, Path: "/api/tables/:id/ticket/customer", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.SetCustomerRequest{}, Response: models.CartResponse{}},
+	{Method: http.MethodPost, Path: "/api/tables/:id/ticket/apply-coupon", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.ApplyCouponRequest{}, Response: models.CartResponse{}},
+	{Method: http.MethodDelete, Path: "/api/tables/:id/ticket/coupon", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Response: models.CartResponse{}},
+	{Method: http.MethodPost, Path: "/api/tables/:id/ticket/checkout", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Response: models.Orders{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/tables/:id/ticket/transfer", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.MoveTicketRequest{}, Response: models.CartResponse{}},
+	{Method: http.MethodPost, Path: "/api/tables/:id/ticket/merge", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.MoveTicketRequest{}, Response: models.CartResponse{}},
+	{Method: http.MethodGet, Path: "/api/kitchen/tickets", Auth: AuthUser, Permission: models.PermissionKitchenView, Response: []models.KitchenTicket{}},
+	{Method: http.MethodGet, Path: "/api/kitchen/tickets/:id", Auth: AuthUser, Permission: models.PermissionKitchenView, Response: models.KitchenTicket{}},
+	{Method: http.MethodGet, Path: "/api/kitchen/stream", Auth: AuthUser, Permission: models.PermissionKitchenView, Response: models.KitchenTicket{}, Encoding: EncodingStream},
+	{Method: http.MethodPut, Path: "/api/kitchen/tickets/:id/items/:itemId/start", Auth: AuthUser, Permission: models.PermissionKitchenUpdate, Response: models.KitchenTicket{}},
+	{Method: http.MethodPut, Path: "/api/kitchen/tickets/:id/items/:itemId/done", Auth: AuthUser, Permission: models.PermissionKitchenUpdate, Response: models.KitchenTicket{}},
+	{Method: http.MethodGet, Path: "/api/promotions", Auth: AuthUser, Paginated: true, Response: models.Promotions{}},
+	{Method: http.MethodGet, Path: "/api/promotions/:id", Auth: AuthUser, Response: models.Promotions{}},
+	{Method: http.MethodPost, Path: "/api/promotions", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.CreatePromotionRequest{}, Response: models.Promotions{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/promotions/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.UpdatePromotionRequest{}, Response: models.Promotions{}},
+	{Method: http.MethodDelete, Path: "/api/promotions/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Promotions{}},
+	{Method: http.MethodGet, Path: "/api/coupons", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Paginated: true, Response: models.Coupons{}},
+	{Method: http.MethodGet, Path: "/api/coupons/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Coupons{}},
+	{Method: http.MethodGet, Path: "/api/coupons/:id/redemptions", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: []models.CouponRedemptions{}},
+	{Method: http.MethodPost, Path: "/api/coupons", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.CreateCouponsRequest{}, Response: []models.Coupons{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/coupons/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.UpdateCouponRequest{}, Response: models.Coupons{}},
+	{Method: http.MethodDelete, Path: "/api/coupons/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Coupons{}},
+	{Method: http.MethodGet, Path: "/api/tax-classes", Auth: AuthUser, Paginated: true, Response: models.TaxClasses{}},
+	{Method: http.MethodGet, Path: "/api/tax-classes/:id", Auth: AuthUser, Response: models.TaxClasses{}},
+	{Method: http.MethodPost, Path: "/api/tax-classes", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.CreateTaxClassRequest{}, Response: models.TaxClasses{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/tax-classes/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.UpdateTaxClassRequest{}, Response: models.TaxClasses{}},
+	{Method: http.MethodDelete, Path: "/api/tax-classes/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.TaxClasses{}},
+	{Method: http.MethodPost, Path: "/api/tax-classes/:id/rates", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.TaxRateRequest{}, Response: models.TaxRates{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/tax-classes/:id/rates/:rateId", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.TaxRateRequest{}, Response: models.TaxRates{}},
+	{Method: http.MethodDelete, Path: "/api/tax-classes/:id/rates/:rateId", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.TaxRates{}},
+	{Method: http.MethodGet, Path: "/api/units", Auth: AuthUser, Paginated: true, Response: models.UnitsOfMeasure{}},
+	{Method: http.MethodGet, Path: "/api/units/:id", Auth: AuthUser, Response: models.UnitsOfMeasure{}},
+	{Method: http.MethodPost, Path: "/api/units", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.CreateUnitRequest{}, Response: models.UnitsOfMeasure{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/units/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.UpdateUnitRequest{}, Response: models.UnitsOfMeasure{}},
+	{Method: http.MethodDelete, Path: "/api/units/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.UnitsOfMeasure{}},
+	{Method: http.MethodGet, Path: "/api/customers", Auth: AuthUser, Permission: models.PermissionCustomersView, Paginated: true, Response: models.Customers{}},
+	{Method: http.MethodGet, Path: "/api/customers/:id", Auth: AuthUser, Permission: models.PermissionCustomersView, Response: models.Customers{}},
+	{Method: http.MethodGet, Path: "/api/customers/:id/orders", Auth: AuthUser, Permission: models.PermissionCustomersView, Paginated: true, Response: models.Orders{}},
+	{Method: http.MethodPost, Path: "/api/customers", Auth: AuthUser, Permission: models.PermissionCustomersManage, Request: models.CreateCustomerRequest{}, Response: models.Customers{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/customers/:id", Auth: AuthUser, Permission: models.PermissionCustomersManage, Request: models.UpdateCustomerRequest{}, Response: models.Customers{}},
+	{Method: http.MethodDelete, Path: "/api/customers/:id", Auth: AuthUser, Permission: models.PermissionCustomersManage, Response: models.Customers{}},
+	{Method: http.MethodGet, Path: "/api/customers/:id/loyalty", Auth: AuthUser, Permission: models.PermissionCustomersView, Response: models.LoyaltyBalanceResponse{}},
+	{Method: http.MethodGet, Path: "/api/customers/:id/loyalty/transactions", Auth: AuthUser, Permission: models.PermissionCustomersView, Paginated: true, Response: models.LoyaltyTransactions{}},
+	{Method: http.MethodPost, Path: "/api/customers/:id/loyalty/adjustments", Auth: AuthUser, Permission: models.PermissionLoyaltyAdjust, Request: models.LoyaltyAdjustmentRequest{}, Response: models.LoyaltyTransactions{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/loyalty-rules", Auth: AuthUser, Paginated: true, Response: models.LoyaltyRules{}},
+	{Method: http.MethodGet, Path: "/api/loyalty-rules/:id", Auth: AuthUser, Response: models.LoyaltyRules{}},
+	{Method: http.MethodPost, Path: "/api/loyalty-rules", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.LoyaltyRuleRequest{}, Response: models.LoyaltyRules{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/loyalty-rules/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.LoyaltyRuleRequest{}, Response: models.LoyaltyRules{}},
+	{Method: http.MethodDelete, Path: "/api/loyalty-rules/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.LoyaltyRules{}},
+	{Method: http.MethodGet, Path: "/api/gift-cards", Auth: AuthUser, Permission: models.PermissionGiftCardsView, Paginated: true, Response: models.GiftCards{}},
+	{Method: http.MethodPost, Path: "/api/gift-cards/balance", Auth: AuthUser, Permission: models.PermissionOrdersCreate, Request: models.GiftCardBalanceRequest{}, Response: models.GiftCardBalanceResponse{}},
+	{Method: http.MethodGet, Path: "/api/gift-cards/:id", Auth: AuthUser, Permission: models.PermissionGiftCardsView, Response: models.GiftCards{}},
+	{Method: http.MethodGet, Path: "/api/gift-cards/:id/transactions", Auth: AuthUser, Permission: models.PermissionGiftCardsView, Paginated: true, Response: models.GiftCardTransactions{}},
+	{Method: http.MethodPost, Path: "/api/gift-cards", Auth: AuthUser, Permission: models.PermissionGiftCardsManage, Request: models.IssueGiftCardRequest{}, Response: models.GiftCards{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/gift-cards/:id/void", Auth: AuthUser, Permission: models.PermissionGiftCardsManage, Request: models.VoidGiftCardRequest{}, Response: models.GiftCards{}},
+	{Method: http.MethodGet, Path: "/api/invoices", Auth: AuthUser, Permission: models.PermissionInvoicesView, Paginated: true, Response: models.Invoices{}},
+	{Method: http.MethodGet, Path: "/api/invoices/:id", Auth: AuthUser, Permission: models.PermissionInvoicesView, Response: models.Invoices{}},
+	{Method: http.MethodGet, Path: "/api/invoices/:id/pdf", Auth: AuthUser, Permission: models.PermissionInvoicesView, Query: models.InvoicePDFQuery{}, Encoding: EncodingFile},
+	{Method: http.MethodPost, Path: "/api/invoices", Auth: AuthUser, Permission: models.PermissionInvoicesManage, Request: models.CreateInvoiceRequest{}, Response: models.Invoices{}, Status: http.StatusCreated},
+	{Method: http.MethodPost, Path: "/api/invoices/:id/payments", Auth: AuthUser, Permission: models.PermissionInvoicesManage, Request: models.InvoicePaymentRequest{}, Response: models.Invoices{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/invoices/:id/void", Auth: AuthUser, Permission: models.PermissionInvoicesManage, Request: models.VoidInvoiceRequest{}, Response: models.Invoices{}},
+	{Method: http.MethodGet, Path: "/api/quotes", Auth: AuthUser, Permission: models.PermissionQuotesView, Paginated: true, Response: models.Quotes{}},
+	{Method: http.MethodGet, Path: "/api/quotes/:id", Auth: AuthUser, Permission: models.PermissionQuotesView, Response: models.Quotes{}},
+	{Method: http.MethodGet, Path: "/api/quotes/:id/versions", Auth: AuthUser, Permission: models.PermissionQuotesView, Response: []models.QuoteVersions{}},
+	{Method: http.MethodGet, Path: "/api/quotes/:id/pdf", Auth: AuthUser, Permission: models.PermissionQuotesView, Query: models.QuotePDFQuery{}, Encoding: EncodingFile},
+	{Method: http.MethodPost, Path: "/api/quotes", Auth: AuthUser, Permission: models.PermissionQuotesManage, Request: models.QuoteRequest{}, Response: models.Quotes{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/quotes/:id", Auth: AuthUser, Permission: models.PermissionQuotesManage, Request: models.QuoteRequest{}, Response: models.Quotes{}},
+	{Method: http.MethodPost, Path: "/api/quotes/:id/send", Auth: AuthUser, Permission: models.PermissionQuotesManage, Request: models.SendQuoteRequest{}, Response: models.Quotes{}},
+	{Method: http.MethodPut, Path: "/api/quotes/:id/accept", Auth: AuthUser, Permission: models.PermissionQuotesManage, Request: models.QuoteStatusRequest{}, Response: models.Quotes{}},
+	{Method: http.MethodPut, Path: "/api/quotes/:id/reject", Auth: AuthUser, Permission: models.PermissionQuotesManage, Request: models.QuoteStatusRequest{}, Response: models.Quotes{}},
+	{Method: http.MethodPost, Path: "/api/quotes/:id/convert", Auth: AuthUser, Permission: models.PermissionQuotesManage, Request: models.ConvertQuoteRequest{}, Response: models.Orders{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/stores", Auth: AuthUser, Paginated: true, Response: models.Stores{}},
+	{Method: http.MethodGet, Path: "/api/stores/:id", Auth: AuthUser, Response: models.Stores{}},
+	{Method: http.MethodPost, Path: "/api/stores", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.StoreRequest{}, Response: models.Stores{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/stores/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.StoreRequest{}, Response: models.Stores{}},
+	{Method: http.MethodDelete, Path: "/api/stores/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Stores{}},
+	{Method: http.MethodGet, Path: "/api/transfers", Auth: AuthUser, Permission: models.PermissionTransfersView, Paginated: true, Response: models.StockTransfers{}},
+	{Method: http.MethodGet, Path: "/api/transfers/in-transit", Auth: AuthUser, Permission: models.PermissionTransfersView, Paginated: true, Response: models.InTransitStock{}},
+	{Method: http.MethodGet, Path: "/api/transfers/:id", Auth: AuthUser, Permission: models.PermissionTransfersView, Response: models.StockTransfers{}},
+	{Method: http.MethodPost, Path: "/api/transfers", Auth: AuthUser, Permission: models.PermissionTransfersManage, Request: models.CreateStockTransferRequest{}, Response: models.StockTransfers{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/transfers/:id/approve", Auth: AuthUser, Permission: models.PermissionTransfersApprove, Response: models.StockTransfers{}},
+	{Method: http.MethodPut, Path: "/api/transfers/:id/send", Auth: AuthUser, Permission: models.PermissionTransfersManage, Response: models.StockTransfers{}},
+	{Method: http.MethodPut, Path: "/api/transfers/:id/receive", Auth: AuthUser, Permission: models.PermissionTransfersManage, Response: models.StockTransfers{}},
+	{Method: http.MethodPut, Path: "/api/transfers/:id/cancel", Auth: AuthUser, Permission: models.PermissionTransfersManage, Response: models.StockTransfers{}},
+	{Method: http.MethodGet, Path: "/api/suppliers", Auth: AuthUser, Permission: models.PermissionSuppliersView, Paginated: true, Response: models.Suppliers{}},
+	{Method: http.MethodGet, Path: "/api/suppliers/:id", Auth: AuthUser, Permission: models.PermissionSuppliersView, Response: models.Suppliers{}},
+	{Method: http.MethodGet, Path: "/api/suppliers/:id/products", Auth: AuthUser, Permission: models.PermissionSuppliersView, Paginated: true, Response: models.ProductSuppliers{}},
+	{Method: http.MethodPost, Path: "/api/suppliers", Auth: AuthUser, Permission: models.PermissionSuppliersManage, Request: models.SupplierRequest{}, Response: models.Suppliers{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/suppliers/:id", Auth: AuthUser, Permission: models.PermissionSuppliersManage, Request: models.SupplierRequest{}, Response: models.Suppliers{}},
+	{Method: http.MethodDelete, Path: "/api/suppliers/:id", Auth: AuthUser, Permission: models.PermissionSuppliersManage, Response: models.Suppliers{}},
+	{Method: http.MethodGet, Path: "/api/imports", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Paginated: true, Response: models.Imports{}},
+	{Method: http.MethodPost, Path: "/api/imports", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.CreateImportRequest{}, Multipart: true, StoresFiles: true, Response: models.Imports{}, Status: http.StatusAccepted},
+	{Method: http.MethodGet, Path: "/api/imports/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Imports{}},
+	{Method: http.MethodDelete, Path: "/api/imports/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Imports{}},
+	{Method: http.MethodGet, Path: "/api/teams", Auth: AuthUser, Paginated: true, Response: models.Teams{}},
+	{Method: http.MethodPost, Path: "/api/teams", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.CreateTeamRequest{}, Response: models.Teams{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/teams/:id", Auth: AuthUser, TeamMember: true, Response: models.Teams{}},
+	{Method: http.MethodPut, Path: "/api/teams/:id", Auth: AuthUser, TeamRoles: []string{models.TeamRoleLead}, Request: models.UpdateTeamRequest{}, Response: models.Teams{}},
+	{Method: http.MethodDelete, Path: "/api/teams/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Teams{}},
+	{Method: http.MethodGet, Path: "/api/teams/:id/members", Auth: AuthUser, TeamMember: true, Response: []models.TeamMembers{}},
+	{Method: http.MethodPost, Path: "/api/teams/:id/members", Auth: AuthUser, TeamRoles: []string{models.TeamRoleLead}, Request: models.AddTeamMemberRequest{}, Response: models.TeamMembers{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/teams/:id/members/:userId", Auth: AuthUser, TeamRoles: []string{models.TeamRoleLead}, Request: models.UpdateTeamMemberRequest{}, Response: models.TeamMembers{}},
+	{Method: http.MethodDelete, Path: "/api/teams/:id/members/:userId", Auth: AuthUser, TeamRoles: []string{models.TeamRoleLead}, Response: models.TeamMembers{}},
+	{Method: http.MethodGet, Path: "/api/devices", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Paginated: true, Response: models.Devices{}},
+	{Method: http.MethodPost, Path: "/api/devices", Auth: AuthUser, Roles: []string{models.RoleAdmin}, PlanResource: models.PlanResourceLocations, Request: models.CreateDeviceRequest{}, Response: models.PairingCodeResponse{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/devices/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Devices{}},
+	{Method: http.MethodPut, Path: "/api/devices/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, PlanResource: models.PlanResourceLocations, Request: models.UpdateDeviceRequest{}, Response: models.Devices{}},
+	{Method: http.MethodDelete, Path: "/api/devices/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Devices{}},
+	{Method: http.MethodPost, Path: "/api/devices/:id/pairing-code", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.PairingCodeResponse{}},
+	{Method: http.MethodPut, Path: "/api/devices/:id/deactivate", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Devices{}},
+	{Method: http.MethodGet, Path: "/api/devices/:id/config", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.TerminalConfigResponse{}},
+	{Method: http.MethodPut, Path: "/api/devices/:id/config", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.UpdateDeviceConfigRequest{}, Response: models.TerminalConfigResponse{}},
+	{Method: http.MethodGet, Path: "/api/print-jobs", Auth: AuthUser, Permission: models.PermissionPrintJobsView, Paginated: true, Response: models.PrintJobs{}},
+	{Method: http.MethodPost, Path: "/api/print-jobs", Auth: AuthUser, Permission: models.PermissionPrintJobsManage, Request: models.CreatePrintJobRequest{}, Response: models.PrintJobs{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/print-jobs/:id", Auth: AuthUser, Permission: models.PermissionPrintJobsView, Response: models.PrintJobs{}},
+	{Method: http.MethodPut, Path: "/api/print-jobs/:id/cancel", Auth: AuthUser, Permission: models.PermissionPrintJobsManage, Response: models.PrintJobs{}},
+	{Method: http.MethodPut, Path: "/api/print-jobs/:id/retry", Auth: AuthUser, Permission: models.PermissionPrintJobsManage, Response: models.PrintJobs{}},
+	{Method: http.MethodGet, Path: "/api/reports", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Paginated: true, Response: models.ReportDefinitions{}},
+	{Method: http.MethodPost, Path: "/api/reports", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.ReportDefinitionRequest{}, Response: models.ReportDefinitions{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/reports/entities", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: []models.ReportEntityResponse{}},
+	{Method: http.MethodGet, Path: "/api/reports/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.ReportDefinitions{}},
+	{Method: http.MethodPut, Path: "/api/reports/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.ReportDefinitionRequest{}, Response: models.ReportDefinitions{}},
+	{Method: http.MethodDelete, Path: "/api/reports/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.ReportDefinitions{}},
+	{Method: http.MethodGet, Path: "/api/reports/:id/run", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Paginated: true},
+	{Method: http.MethodGet, Path: "/api/report-schedules", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Paginated: true, Response: models.ReportSchedules{}},
+	{Method: http.MethodPost, Path: "/api/report-schedules", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.ReportScheduleRequest{}, Response: models.ReportSchedules{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/report-schedules/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.ReportSchedules{}},
+	{Method: http.MethodPut, Path: "/api/report-schedules/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.ReportScheduleRequest{}, Response: models.ReportSchedules{}},
+	{Method: http.MethodDelete, Path: "/api/report-schedules/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.ReportSchedules{}},
+	{Method: http.MethodGet, Path: "/api/report-schedules/:id/runs", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Paginated: true, Response: models.ReportScheduleRuns{}},
+	{Method: http.MethodPost, Path: "/api/report-schedules/:id/run", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.ReportScheduleRuns{}},
+	{Method: http.MethodGet, Path: "/api/analytics/sales", Auth: AuthUser, Permission: models.PermissionAnalyticsView, Query: models.SalesAnalyticsQuery{}, Response: models.SalesAnalyticsResponse{}},
+	{Method: http.MethodGet, Path: "/api/system/cache", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: cache.Stats{}},
+	{Method: http.MethodGet, Path: "/api/system/read-only", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: middleware.ReadOnlyStatus{}},
+	{Method: http.MethodPut, Path: "/api/system/read-only", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.UpdateReadOnlyModeRequest{}, Response: middleware.ReadOnlyStatus{}},
+	{Method: http.MethodGet, Path: "/api/system/license", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: license.Entitlements{}},
+	{Method: http.MethodGet, Path: "/api/system/storage", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.StorageUsageReport{}},
+	{Method: http.MethodGet, Path: "/api/system/schemas", Auth: AuthUser, Response: []schema.Endpoint{}},
+	{Method: http.MethodGet, Path: "/api/experiments", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Paginated: true, Response: models.Experiments{}},
+	{Method: http.MethodPost, Path: "/api/experiments", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.ExperimentRequest{}, Response: models.Experiments{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/experiments/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Experiments{}},
+	{Method: http.MethodPut, Path: "/api/experiments/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.ExperimentRequest{}, Response: models.Experiments{}},
+	{Method: http.MethodDelete, Path: "/api/experiments/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Experiments{}},
+	{Method: http.MethodPut, Path: "/api/experiments/:id/status", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.UpdateExperimentStatusRequest{}, Response: models.Experiments{}},
+	{Method: http.MethodGet, Path: "/api/experiments/:id/assignments", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Paginated: true, Response: models.ExperimentAssignments{}},
+	{Method: http.MethodGet, Path: "/api/admin/subscription", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.SubscriptionResponse{}},
+	{Method: http.MethodPut, Path: "/api/admin/subscription", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.UpdateSubscriptionRequest{}, Response: models.SubscriptionResponse{}},
+	{Method: http.MethodGet, Path: "/api/admin/plans", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Paginated: true, Response: models.Plans{}},
+	{Method: http.MethodPost, Path: "/api/admin/plans", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.CreatePlanRequest{}, Response: models.Plans{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/admin/plans/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.UpdatePlanRequest{}, Response: models.Plans{}},
+	{Method: http.MethodGet, Path: "/api/admin/slo", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: slo.Report{}},
+	{Method: http.MethodGet, Path: "/api/changes", Auth: AuthUser, Query: models.ChangeFeedQuery{}, Response: models.ChangeFeedResponse{}},
+	{Method: http.MethodGet, Path: "/api/changes/stream", Auth: AuthUser, Response: models.ChangeFeedResponse{}, Encoding: EncodingStream},
+	{Method: http.MethodGet, Path: "/api/settings", Auth: AuthUser, Permission: models.PermissionSettingsView, Paginated: true, Response: models.Settings{}},
+	{Method: http.MethodPost, Path: "/api/settings", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.CreateSettingRequest{}, Response: models.Settings{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/settings/effective/:key", Auth: AuthUser, Permission: models.PermissionSettingsView, Query: models.EffectiveSettingQuery{}, Response: models.EffectiveSetting{}},
+	{Method: http.MethodGet, Path: "/api/settings/:id", Auth: AuthUser, Permission: models.PermissionSettingsView, Response: models.Settings{}},
+	{Method: http.MethodPut, Path: "/api/settings/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.UpdateSettingRequest{}, Response: models.Settings{}},
+	{Method: http.MethodDelete, Path: "/api/settings/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Settings{}},
+	{Method: http.MethodGet, Path: "/api/settings/:id/history", Auth: AuthUser, Permission: models.PermissionSettingsView, Paginated: true, Response: models.SettingChanges{}},
+	{Method: http.MethodPost, Path: "/api/roles/:role/users/assign", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.RoleUsersRequest{}, Response: models.BulkUserResponse{}},
+	{Method: http.MethodPost, Path: "/api/roles/:role/users/remove", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.RoleUsersRequest{}, Response: models.BulkUserResponse{}},
+	{Method: http.MethodGet, Path: "/api/permissions", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: []models.PermissionInfo{}},
+	{Method: http.MethodPost, Path: "/api/permissions/:permission/grant", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.PermissionRolesRequest{}, Response: models.PermissionRolesResponse{}},
+	{Method: http.MethodPost, Path: "/api/permissions/:permission/revoke", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.PermissionRolesRequest{}, Response: models.PermissionRolesResponse{}},
+	{Method: http.MethodGet, Path: "/api/tenants", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Paginated: true, Response: models.Tenants{}},
+	{Method: http.MethodPost, Path: "/api/tenants", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.CreateTenantRequest{}, Response: models.Tenants{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/tenants/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Tenants{}},
+	{Method: http.MethodPut, Path: "/api/tenants/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Request: models.UpdateTenantRequest{}, Response: models.Tenants{}},
+	{Method: http.MethodDelete, Path: "/api/tenants/:id", Auth: AuthUser, Roles: []string{models.RoleAdmin}, Response: models.Tenants{}},
+	{Method: http.MethodPost, Path: "/api/graphql", Auth: AuthUser, Request: models.GraphQLRequest{}, Response: models.GraphQLResponse{}, Encoding: EncodingRaw},
+	{Method: http.MethodGet, Path: "/api/ws", Auth: AuthUser, Request: models.RealtimeClientMessage{}, Response: models.RealtimeEvent{}, Encoding: EncodingWebSocket},
+}
@@ -1,13 +1,16 @@
 package database
 
 import (
+	"context"
 	"fmt"
-	"log"
 
 	"github.com/Aebroyx/the-blade-api/internal/config"
 	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
@@ -15,27 +18,266 @@ type DB struct {
 	*gorm.DB
 }
 
+// migratedModels are the models whose tables are auto-migrated on startup
+var migratedModels = []interface{}{
+	&models.Users{},
+	&models.Teams{},
+	&models.TeamMembers{},
+	&models.Devices{},
+	&models.UserSettings{},
+	&models.DeviceConfigs{},
+	&models.UserActivities{},
+	&models.PrintJobs{},
+	&models.ReportDefinitions{},
+	&models.ReportSchedules{},
+	&models.ReportScheduleRuns{},
+	&models.Experiments{},
+	&models.ExperimentAssignments{},
+	&models.LoginEvents{},
+	&models.Tenants{},
+	&models.Plans{},
+	&models.Subscriptions{},
+	&models.BillingEvents{},
+	&models.Settings{},
+	&models.SettingChanges{},
+	&models.ChangeEvents{},
+	&models.Tags{},
+	&models.UserTags{},
+	&models.RolePermissions{},
+	&models.UserQuotas{},
+	&models.NotificationPreferences{},
+	&models.Notifications{},
+	&models.Categories{},
+	&models.Products{},
+	&models.ProductOptions{},
+	&models.ProductVariants{},
+	&models.ProductImages{},
+	&models.StorageUsages{},
+	&models.BundleComponents{},
+	&models.StockLevels{},
+	&models.StockMovements{},
+	&models.StockSerials{},
+	&models.StockBatches{},
+	&models.StockTransfers{},
+	&models.StockTransferItems{},
+	&models.Stocktakes{},
+	&models.StocktakeItems{},
+	&models.GiftCards{},
+	&models.GiftCardTransactions{},
+	&models.Orders{},
+	&models.OrderItems{},
+	&models.OrderItemComponents{},
+	&models.Carts{},
+	&models.DiningAreas{},
+	&models.DiningTables{},
+	&models.Imports{},
+	&models.Payments{},
+	&models.PaymentEvents{},
+	&models.CashDrawerSessions{},
+	&models.CashDrawerMovements{},
+	&models.OrderReturns{},
+	&models.OrderReturnItems{},
+	&models.OrderReturnRefunds{},
+	&models.ReceiptEmails{},
+	&models.InvoiceSequences{},
+	&models.Invoices{},
+	&models.InvoiceLines{},
+	&models.InvoiceTaxes{},
+	&models.InvoicePayments{},
+	&models.Quotes{},
+	&models.QuoteVersions{},
+	&models.QuoteItems{},
+	&models.Promotions{},
+	&models.OrderPromotions{},
+	&models.Coupons{},
+	&models.CouponRedemptions{},
+	&models.TaxClasses{},
+	&models.TaxRates{},
+	&models.OrderTaxes{},
+	&models.UnitsOfMeasure{},
+	&models.ProductUnits{},
+	&models.Customers{},
+	&models.LoyaltyRules{},
+	&models.LoyaltyTransactions{},
+	&models.Suppliers{},
+	&models.ProductSuppliers{},
+	&models.Shifts{},
+	&models.Stores{},
+	&models.UserStores{},
+}
+
 func NewConnection(cfg *config.Config) (*DB, error) {
-	// Configure GORM logger
-	gormLogger := logger.New(
-		log.New(log.Writer(), "\r\n", log.LstdFlags),
-		logger.Config{
-			LogLevel: logger.Info,
-		},
-	)
-
-	// Open database connection
+	// Open database connection; statements are logged at debug level, slow and failed ones
+	// above it
 	db, err := gorm.Open(postgres.Open(cfg.GetDSN()), &gorm.Config{
-		Logger: gormLogger,
+		Logger: statementLogger{level: logger.Info},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %v", err)
 	}
 
+	// Count statements per request so requests over their budget can be reported
+	if cfg.SQLStatementBudget > 0 {
+		if err := db.Use(StatementCounting{}); err != nil {
+			return nil, fmt.Errorf("failed to set up statement counting: %v", err)
+		}
+	}
+
+	// Users and tags are joined through an explicit model
+	if err := db.SetupJoinTable(&models.Users{}, "Tags", &models.UserTags{}); err != nil {
+		return nil, fmt.Errorf("failed to set up user tags: %v", err)
+	}
+
+	// Let deleted teams' names be used again; the old constraint goes before AutoMigrate, which
+	// would only look for it under its current name
+	if err := migrateTeamNames(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate team names: %v", err)
+	}
+
 	// Auto-migrate models
-	if err := db.AutoMigrate(&models.Users{}); err != nil {
+	if err := db.AutoMigrate(migratedModels...); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %v", err)
 	}
 
+	// Add full-text search columns and indexes
+	if err := migrateSearchVectors(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate search vectors: %v", err)
+	}
+
+	// Add the stock level index that keeps one row per product, variant and store
+	if err := migrateStockLevels(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate stock levels: %v", err)
+	}
+
+	// Add the batch index that keeps one row per batch of a product, variant and store
+	if err := migrateStockBatches(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate stock batches: %v", err)
+	}
+
+	// Record the batches of lines sold from a batch before batches were allocated per line
+	if err := db.Exec(`UPDATE order_items SET batches = jsonb_build_array(jsonb_build_object('batch', batch, 'quantity', quantity, 'returned', returned_quantity))
+		WHERE batch <> '' AND batches = '[]'::jsonb
+		AND order_id IN (SELECT id FROM orders WHERE status NOT IN ('draft', 'cancelled'))`).Error; err != nil {
+		return nil, fmt.Errorf("failed to migrate order item batches: %v", err)
+	}
+
+	// Add the default units of measure; existing ones are left as they are
+	units := append([]models.UnitsOfMeasure{}, models.DefaultUnits...)
+	if err := db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "code"}}, DoNothing: true}).Create(&units).Error; err != nil {
+		return nil, fmt.Errorf("failed to seed units of measure: %v", err)
+	}
+
+	// Movements recorded before units of measure were given in the stock unit
+	if err := db.Exec("UPDATE stock_movements SET unit_quantity = quantity WHERE unit_quantity = 0").Error; err != nil {
+		return nil, fmt.Errorf("failed to migrate stock movement units: %v", err)
+	}
+
+	// Charge the images uploaded before storage was metered to requests without a tenant or store
+	if err := db.Exec(`INSERT INTO storage_usages (tenant_id, store_id, bytes, files, updated_at)
+		SELECT 0, 0, SUM(size + thumbnail_size), COUNT(*) * 2, NOW() FROM product_images
+		HAVING COUNT(*) > 0 AND NOT EXISTS (SELECT 1 FROM storage_usages)`).Error; err != nil {
+		return nil, fmt.Errorf("failed to migrate storage usage: %v", err)
+	}
+
+	// Record when orders placed before the status workflow were placed
+	if err := db.Exec("UPDATE orders SET placed_at = created_at WHERE placed_at IS NULL AND status <> 'draft'").Error; err != nil {
+		return nil, fmt.Errorf("failed to migrate orders: %v", err)
+	}
+
 	return &DB{db}, nil
 }
+
+// migrateTeamNames drops the unique constraint team names had before it was replaced by
+// idx_teams_name, which leaves deleted teams out. Earlier releases named it either way, and new
+// databases have no teams table yet.
+func migrateTeamNames(db *gorm.DB) error {
+	if db.Dialector.Name() != "postgres" {
+		return nil
+	}
+	for _, constraint := range []string{"uni_teams_name", "teams_name_key"} {
+		if err := db.Exec("ALTER TABLE IF EXISTS teams DROP CONSTRAINT IF EXISTS " + constraint).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateSearchVectors adds generated tsvector columns with GIN indexes for ranked full-text search.
+// Punctuation in emails is turned into spaces so "john@example.com" matches a search for "john example".
+// Other databases keep using the ILIKE search fallback.
+func migrateSearchVectors(db *gorm.DB) error {
+	if db.Dialector.Name() != "postgres" {
+		return nil
+	}
+
+	statements := []string{
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+			setweight(to_tsvector('simple', translate(coalesce(username, ''), '._-', '   ')), 'A') ||
+			setweight(to_tsvector('simple', translate(coalesce(email, ''), '@._-+', '     ')), 'B')
+		) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_users_search_vector ON users USING GIN (search_vector)`,
+	}
+
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateStockLevels adds the unique index stock level upserts conflict on. Variant and store are
+// optional, and NULLs never conflict in a plain unique index, so both are coalesced to 0.
+func migrateStockLevels(db *gorm.DB) error {
+	return db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_levels_product_variant_store
+		ON stock_levels (product_id, (COALESCE(variant_id, 0)), (COALESCE(store_id, 0)))`).Error
+}
+
+// migrateStockBatches adds the unique index batch upserts conflict on, coalescing the optional
+// variant and store like migrateStockLevels
+func migrateStockBatches(db *gorm.DB) error {
+	return db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_batches_product_variant_store_batch
+		ON stock_batches (product_id, (COALESCE(variant_id, 0)), (COALESCE(store_id, 0)), batch)`).Error
+}
+
+// PendingMigrations returns the tables of migrated models missing from the database, e.g. while a
+// newer release's migration hasn't run yet. Tables are looked up in a single query.
+func PendingMigrations(ctx context.Context, db *gorm.DB) ([]string, error) {
+	tables := make([]string, 0, len(migratedModels))
+	for _, model := range migratedModels {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		tables = append(tables, stmt.Schema.Table)
+	}
+
+	var existing []string
+	if err := db.WithContext(ctx).Raw(`SELECT table_name FROM information_schema.tables
+		WHERE table_schema = CURRENT_SCHEMA() AND table_name IN ?`, tables).Scan(&existing).Error; err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool, len(existing))
+	for _, table := range existing {
+		found[table] = true
+	}
+
+	pending := []string{}
+	for _, table := range tables {
+		if !found[table] {
+			pending = append(pending, table)
+		}
+	}
+	return pending, nil
+}
+
+// RegisterMetrics exposes the connection pool stats, read at scrape time
+func (db *DB) RegisterMetrics(r prometheus.Registerer) error {
+	sqlDB, err := db.DB.DB()
+	if err != nil {
+		return err
+	}
+	return r.Register(collectors.NewDBStatsCollector(sqlDB, "postgres"))
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// statementCounterKey is the context key of a request's statement counter
+type statementCounterKey struct{}
+
+// StatementCounter counts the SQL statements run with a context
+type StatementCounter struct {
+	count atomic.Int64
+}
+
+// Count returns the number of statements run so far
+func (c *StatementCounter) Count() int64 {
+	return c.count.Load()
+}
+
+// WithStatementCounter returns a context whose statements are counted by the counter returned
+// with it. Only statements run with the context, e.g. through db.WithContext, are counted.
+func WithStatementCounter(ctx context.Context) (context.Context, *StatementCounter) {
+	counter := &StatementCounter{}
+	return context.WithValue(ctx, statementCounterKey{}, counter), counter
+}
+
+// StatementCounting is a GORM plugin counting statements against the counter of their context.
+// Statements without a counter are not affected.
+type StatementCounting struct{}
+
+func (StatementCounting) Name() string {
+	return "statement_counting"
+}
+
+func (StatementCounting) Initialize(db *gorm.DB) error {
+	count := func(db *gorm.DB) {
+		if db.Statement == nil || db.Statement.Context == nil {
+			return
+		}
+		if counter, ok := db.Statement.Context.Value(statementCounterKey{}).(*StatementCounter); ok {
+			counter.count.Add(1)
+		}
+	}
+
+	callbacks := db.Callback()
+	if err := callbacks.Create().After("gorm:create").Register("statement_counting:create", count); err != nil {
+		return err
+	}
+	if err := callbacks.Query().After("gorm:query").Register("statement_counting:query", count); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("statement_counting:update", count); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("gorm:delete").Register("statement_counting:delete", count); err != nil {
+		return err
+	}
+	if err := callbacks.Row().After("gorm:row").Register("statement_counting:row", count); err != nil {
+		return err
+	}
+	return callbacks.Raw().After("gorm:raw").Register("statement_counting:raw", count)
+}
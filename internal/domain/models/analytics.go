@@ -0,0 +1,90 @@
+package models
+
+import "time"
+
+// Sales analytics groupings of the sales over time
+const (
+	AnalyticsGroupByDay   = "day"
+	AnalyticsGroupByWeek  = "week"
+	AnalyticsGroupByMonth = "month"
+)
+
+// SalesAnalyticsQuery represents the query parameters of the sales analytics. Sales count from
+// when orders were paid, From inclusive and To exclusive; the range defaults to the last 30
+// days. Limit bounds the top products, categories and staff members.
+type SalesAnalyticsQuery struct {
+	From    *time.Time `form:"from"`
+	To      *time.Time `form:"to"`
+	GroupBy string     `form:"group_by" validate:"omitempty,oneof=day week month"`
+	Limit   int        `form:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// SalesSummary sums up the sales of a range. Sales are order totals and NetSales what is left
+// of them after refunds. Amounts are in minor currency units.
+type SalesSummary struct {
+	Orders            int64 `json:"orders"`
+	Sales             int64 `json:"sales"`
+	Discounts         int64 `json:"discounts"`
+	Tax               int64 `json:"tax"`
+	Refunds           int64 `json:"refunds"`
+	NetSales          int64 `json:"net_sales"`
+	AverageOrderValue int64 `json:"average_order_value"`
+}
+
+// SalesPeriod is what was sold in one day, week or month, starting at Period
+type SalesPeriod struct {
+	Period   time.Time `json:"period"`
+	Orders   int64     `json:"orders"`
+	Sales    int64     `json:"sales"`
+	NetSales int64     `json:"net_sales"`
+}
+
+// ProductSales is what was sold of one product, returns taken off and refunded orders left out
+type ProductSales struct {
+	ProductID uint   `json:"product_id"`
+	Name      string `json:"name"`
+	SKU       string `json:"sku"`
+	Quantity  int64  `json:"quantity"`
+	Sales     int64  `json:"sales"`
+}
+
+// CategorySales is what was sold in one category, counted like ProductSales. Products without a
+// category have no CategoryID.
+type CategorySales struct {
+	CategoryID *uint  `json:"category_id"`
+	Name       string `json:"name"`
+	Quantity   int64  `json:"quantity"`
+	Sales      int64  `json:"sales"`
+}
+
+// StaffSales is what one staff member sold, refunds taken off. Orders placed by users since
+// deleted have no UserID.
+type StaffSales struct {
+	UserID *uint  `json:"user_id"`
+	Name   string `json:"name"`
+	Orders int64  `json:"orders"`
+	Sales  int64  `json:"sales"`
+}
+
+// PaymentMethodSales is what was taken with one payment method, refunds taken off
+type PaymentMethodSales struct {
+	Method   string `json:"method"`
+	Payments int64  `json:"payments"`
+	Amount   int64  `json:"amount"`
+}
+
+// SalesAnalyticsResponse breaks the sales of a range down over time, by product, category,
+// staff member and payment method. GeneratedAt is when the numbers were worked out; they are
+// cached for a few minutes.
+type SalesAnalyticsResponse struct {
+	From            time.Time            `json:"from"`
+	To              time.Time            `json:"to"`
+	GroupBy         string               `json:"group_by"`
+	Summary         SalesSummary         `json:"summary"`
+	Series          []SalesPeriod        `json:"series"`
+	TopProducts     []ProductSales       `json:"top_products"`
+	ByCategory      []CategorySales      `json:"by_category"`
+	ByStaff         []StaffSales         `json:"by_staff"`
+	ByPaymentMethod []PaymentMethodSales `json:"by_payment_method"`
+	GeneratedAt     time.Time            `json:"generated_at"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Bundle pricing. Fixed bundles sell at the bundle product's price; derived bundles at the sum
+// of the current prices of their components.
+const (
+	BundlePricingFixed   = "fixed"
+	BundlePricingDerived = "derived"
+)
+
+// BundleComponents are the products, or variants of them, one unit of a bundle product is made
+// of. Bundles hold no stock of their own: selling one takes Quantity of each component from
+// stock, counted in the component's stock unit.
+type BundleComponents struct {
+	ID        uint             `json:"id" gorm:"primaryKey"`
+	BundleID  uint             `json:"bundle_id" gorm:"not null;index"`
+	ProductID uint             `json:"product_id" gorm:"not null;index"`
+	VariantID *uint            `json:"variant_id" gorm:"index"`
+	Product   *Products        `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	Variant   *ProductVariants `json:"variant,omitempty" gorm:"foreignKey:VariantID"`
+	Quantity  int64            `json:"quantity" gorm:"not null"`
+	Position  int              `json:"position" gorm:"not null;default:0"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// OrderItemComponents are the components of a bundle sold on an order line, copied from the
+// bundle when the order is placed so sales of components can be reported whether they were sold
+// on their own or in a bundle. PerBundle is the quantity in one bundle and Quantity the quantity
+// sold on the line.
+type OrderItemComponents struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	OrderItemID  uint      `json:"order_item_id" gorm:"not null;index"`
+	ProductID    uint      `json:"product_id" gorm:"not null;index"`
+	VariantID    *uint     `json:"variant_id" gorm:"index"`
+	Name         string    `json:"name" gorm:"not null;size:255"`
+	VariantTitle string    `json:"variant_title,omitempty" gorm:"size:255"`
+	SKU          string    `json:"sku" gorm:"not null;size:100"`
+	PerBundle    int64     `json:"per_bundle" gorm:"not null"`
+	Quantity     int64     `json:"quantity" gorm:"not null"`
+	UnitPrice    int64     `json:"unit_price" gorm:"not null;default:0"`
+	UnitCost     int64     `json:"unit_cost" gorm:"not null;default:0"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index"`
+}
+
+// BundleComponentInput is one component of a bundle
+type BundleComponentInput struct {
+	ProductID uint  `json:"product_id" validate:"required"`
+	VariantID *uint `json:"variant_id"`
+	Quantity  int64 `json:"quantity" validate:"required,min=1"`
+}
+
+// SetBundleComponentsRequest replaces the components of a bundle product, in display order
+type SetBundleComponentsRequest struct {
+	Components []BundleComponentInput `json:"components" validate:"max=50,dive"`
+}
@@ -0,0 +1,191 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// CartLine is one line of a cart. Prices are not stored: lines are priced from the catalog
+// whenever the cart is shown, the way the order placed at checkout will be. Course and Seat
+// annotate lines of table tickets for the kitchen.
+type CartLine struct {
+	ID        uint   `json:"id"`
+	ProductID uint   `json:"product_id"`
+	VariantID *uint  `json:"variant_id"`
+	Quantity  int64  `json:"quantity"`
+	UnitPrice *int64 `json:"unit_price,omitempty"`
+	Discount  int64  `json:"discount"`
+	TaxRate   int64  `json:"tax_rate"`
+	Course    int64  `json:"course,omitempty"`
+	Seat      int64  `json:"seat,omitempty"`
+}
+
+// CartLines are the lines of a cart stored in a JSONB column
+type CartLines []CartLine
+
+// Value implements driver.Valuer
+func (l CartLines) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (l *CartLines) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = CartLines{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for CartLines: %T", value)
+	}
+
+	result := CartLines{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*l = result
+	return nil
+}
+
+// Carts are the draft orders being rung up. A terminal has one cart shared by whoever works
+// it; requests without a terminal use a cart of the signed-in user. In restaurants a table's
+// open ticket is a cart of the table, shared by every terminal serving it. Owner is
+// "device:<id>", "user:<id>" or "table:<id>" accordingly. Sales put on hold are parked carts,
+// owned by nobody ("parked:<id>") until a terminal of StoreID resumes them; Label tells them
+// apart and ParkedAt is when the sale was parked.
+type Carts struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Owner      string     `json:"-" gorm:"not null;size:50;uniqueIndex"`
+	DeviceID   *uint      `json:"device_id,omitempty" gorm:"index"`
+	TableID    *uint      `json:"table_id,omitempty" gorm:"index"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	Note       string     `json:"note" gorm:"size:255"`
+	CouponID   *uint      `json:"coupon_id,omitempty" gorm:"index"`
+	CustomerID *uint      `json:"customer_id,omitempty" gorm:"index"`
+	Lines      CartLines  `json:"lines" gorm:"type:jsonb;not null"`
+	NextLineID uint       `json:"-" gorm:"not null;default:1"`
+	StoreID    *uint      `json:"store_id,omitempty" gorm:"index"`
+	Label      string     `json:"label,omitempty" gorm:"size:100"`
+	ParkedAt   *time.Time `json:"parked_at,omitempty" gorm:"index"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// CartOwner identifies whose cart a request works on: the table's for ticket requests, else
+// the terminal's if it came from one, otherwise the user's. Location is the terminal's, whose
+// tax rates the cart is taxed at, and StoreID the store the request works in, whose stock the
+// cart's order takes.
+type CartOwner struct {
+	UserID   uint
+	DeviceID *uint
+	TableID  *uint
+	Location string
+	StoreID  *uint
+}
+
+// Key returns the Owner value of the cart
+func (o CartOwner) Key() string {
+	if o.TableID != nil {
+		return fmt.Sprintf("table:%d", *o.TableID)
+	}
+	if o.DeviceID != nil {
+		return fmt.Sprintf("device:%d", *o.DeviceID)
+	}
+	return fmt.Sprintf("user:%d", o.UserID)
+}
+
+// CartItemResponse is a cart line priced from the catalog. Lines that can no longer be sold,
+// e.g. because the product was archived, carry an error and are left out of the totals.
+type CartItemResponse struct {
+	LineID       uint      `json:"line_id"`
+	ProductID    uint      `json:"product_id"`
+	VariantID    *uint     `json:"variant_id"`
+	Name         string    `json:"name,omitempty"`
+	VariantTitle string    `json:"variant_title,omitempty"`
+	SKU          string    `json:"sku,omitempty"`
+	Quantity     int64     `json:"quantity"`
+	UnitPrice    int64     `json:"unit_price"`
+	Subtotal     int64     `json:"subtotal"`
+	Discount     int64     `json:"discount"`
+	TaxRate      int64     `json:"tax_rate"`
+	Tax          int64     `json:"tax"`
+	Taxes        LineTaxes `json:"taxes"`
+	Total        int64     `json:"total"`
+	Course       int64     `json:"course,omitempty"`
+	Seat         int64     `json:"seat,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// CartResponse is a cart with its running totals, computed the same way as an order's,
+// including the discounts of the promotions that apply to it and the tax breakdown. A coupon that can no longer be
+// used carries an error and gives no discount.
+type CartResponse struct {
+	ID            uint               `json:"id"`
+	DeviceID      *uint              `json:"device_id,omitempty"`
+	TableID       *uint              `json:"table_id,omitempty"`
+	CustomerID    *uint              `json:"customer_id,omitempty"`
+	Note          string             `json:"note"`
+	Items         []CartItemResponse `json:"items"`
+	ItemCount     int64              `json:"item_count"`
+	Subtotal      int64              `json:"subtotal"`
+	DiscountTotal int64              `json:"discount_total"`
+	TaxTotal      int64              `json:"tax_total"`
+	IncludedTax   int64              `json:"included_tax"`
+	Total         int64              `json:"total"`
+	Taxes         []TaxBreakdown     `json:"taxes"`
+	Promotions    []AppliedPromotion `json:"promotions"`
+	CouponCode    string             `json:"coupon_code,omitempty"`
+	CouponError   string             `json:"coupon_error,omitempty"`
+	Label         string             `json:"label,omitempty"`
+	ParkedAt      *time.Time         `json:"parked_at,omitempty"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+// AddCartItemRequest represents the request payload for adding a product to the cart. Adding a
+// product already in the cart at the same price, tax rate, course and seat increases that
+// line's quantity.
+type AddCartItemRequest struct {
+	ProductID uint   `json:"product_id" validate:"required"`
+	VariantID *uint  `json:"variant_id"`
+	Quantity  int64  `json:"quantity" validate:"required,min=1"`
+	UnitPrice *int64 `json:"unit_price" validate:"omitempty,min=0"`
+	Discount  int64  `json:"discount" validate:"min=0"`
+	TaxRate   int64  `json:"tax_rate" validate:"min=0,max=10000"`
+	Course    int64  `json:"course" validate:"min=0,max=100"`
+	Seat      int64  `json:"seat" validate:"min=0,max=1000"`
+}
+
+// UpdateCartItemRequest represents the request payload for changing a cart line, including
+// the discount applied to it and its course and seat
+type UpdateCartItemRequest struct {
+	Quantity  int64  `json:"quantity" validate:"required,min=1"`
+	UnitPrice *int64 `json:"unit_price" validate:"omitempty,min=0"`
+	Discount  int64  `json:"discount" validate:"min=0"`
+	TaxRate   int64  `json:"tax_rate" validate:"min=0,max=10000"`
+	Course    int64  `json:"course" validate:"min=0,max=100"`
+	Seat      int64  `json:"seat" validate:"min=0,max=1000"`
+}
+
+// ParkCartRequest represents the request payload for parking the cart, with a label to find
+// the sale by when it is resumed, e.g. the customer's name
+type ParkCartRequest struct {
+	Label string `json:"label" validate:"required,max=100"`
+}
+
+// UpdateCartRequest represents the request payload for changing the cart itself
+type UpdateCartRequest struct {
+	Note string `json:"note" validate:"max=255"`
+}
@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// Cash drawer session statuses
+const (
+	CashDrawerStatusOpen   = "open"
+	CashDrawerStatusClosed = "closed"
+)
+
+// Cash drawer movement types. Sales and refunds are recorded for cash payments; paid-ins and
+// paid-outs are entered by the cashier, e.g. change brought from the safe or a supplier paid
+// from the till.
+const (
+	CashMovementSale    = "sale"
+	CashMovementRefund  = "refund"
+	CashMovementPaidIn  = "paid_in"
+	CashMovementPaidOut = "paid_out"
+)
+
+// CashDrawerSessions track the cash in a till from opening to close. A cashier has at most one
+// open session, and the cash payments they take and refund while it is open are recorded on it.
+// Expected is what the drawer should hold: the opening float plus sales and paid-ins, minus
+// refunds and paid-outs. At close the counted cash is compared with it. Amounts are in minor
+// currency units.
+type CashDrawerSessions struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	DeviceID     *uint      `json:"device_id,omitempty" gorm:"index"`
+	OpenedByID   *uint      `json:"opened_by_id,omitempty" gorm:"index;uniqueIndex:idx_cash_drawer_sessions_open,where:status = 'open'"`
+	ClosedByID   *uint      `json:"closed_by_id,omitempty" gorm:"index"`
+	Status       string     `json:"status" gorm:"not null;size:20;index"`
+	OpeningFloat int64      `json:"opening_float" gorm:"not null;default:0"`
+	Expected     int64      `json:"expected" gorm:"not null;default:0"`
+	Counted      *int64     `json:"counted"`
+	Variance     *int64     `json:"variance"`
+	OpeningNote  string     `json:"opening_note,omitempty" gorm:"size:255"`
+	ClosingNote  string     `json:"closing_note,omitempty" gorm:"size:255"`
+	OpenedAt     time.Time  `json:"opened_at" gorm:"index"`
+	ClosedAt     *time.Time `json:"closed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// CashDrawerMovements are the cash put into and taken out of a drawer during a session.
+// Amount is always positive; Type says which way the cash went.
+type CashDrawerMovements struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	SessionID   uint      `json:"session_id" gorm:"not null;index"`
+	Type        string    `json:"type" gorm:"not null;size:20;index"`
+	Amount      int64     `json:"amount" gorm:"not null"`
+	Reason      string    `json:"reason,omitempty" gorm:"size:255"`
+	PaymentID   *uint     `json:"payment_id,omitempty" gorm:"index"`
+	CreatedByID *uint     `json:"created_by_id,omitempty" gorm:"index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// OpenCashDrawerRequest represents the request payload for opening a cash drawer. DeviceID is
+// the till the drawer belongs to, if it is a registered device.
+type OpenCashDrawerRequest struct {
+	DeviceID     *uint  `json:"device_id" validate:"omitempty,min=1"`
+	OpeningFloat int64  `json:"opening_float" validate:"min=0"`
+	Note         string `json:"note" validate:"max=255"`
+}
+
+// CashMovementRequest represents the request payload for a paid-in or paid-out
+type CashMovementRequest struct {
+	Type   string `json:"type" validate:"required,oneof=paid_in paid_out"`
+	Amount int64  `json:"amount" validate:"required,min=1"`
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+// CloseCashDrawerRequest represents the request payload for closing a cash drawer with the
+// cash counted in it
+type CloseCashDrawerRequest struct {
+	Counted *int64 `json:"counted" validate:"required,min=0"`
+	Note    string `json:"note" validate:"max=255"`
+}
+
+// CashDrawerReport breaks a session down by movement type. While the drawer is open Expected
+// is what it should hold now; once closed Counted and Variance (counted minus expected) say
+// whether cash is over or short.
+type CashDrawerReport struct {
+	Session      CashDrawerSessions    `json:"session"`
+	OpeningFloat int64                 `json:"opening_float"`
+	Sales        int64                 `json:"sales"`
+	Refunds      int64                 `json:"refunds"`
+	PaidIn       int64                 `json:"paid_in"`
+	PaidOut      int64                 `json:"paid_out"`
+	Expected     int64                 `json:"expected"`
+	Counted      *int64                `json:"counted"`
+	Variance     *int64                `json:"variance"`
+	Movements    []CashDrawerMovements `json:"movements"`
+}
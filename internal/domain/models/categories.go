@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Categories group products. Categories nest under a parent category; top-level categories
+// have no parent. Position orders a category among its siblings.
+type Categories struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	ParentID    *uint          `json:"parent_id" gorm:"index"`
+	Name        string         `json:"name" gorm:"not null;size:100"`
+	Description string         `json:"description" gorm:"size:255"`
+	Position    int            `json:"position" gorm:"not null;default:0"`
+	Children    []*Categories  `json:"children,omitempty" gorm:"-"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// CreateCategoryRequest represents the request payload for creating a category
+type CreateCategoryRequest struct {
+	ParentID    *uint  `json:"parent_id"`
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+// UpdateCategoryRequest represents the request payload for updating or moving a category
+type UpdateCategoryRequest struct {
+	ParentID    *uint  `json:"parent_id"`
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+// ReorderCategoriesRequest lists every child of a parent category in their new order.
+// A nil parent reorders the top-level categories.
+type ReorderCategoriesRequest struct {
+	ParentID    *uint  `json:"parent_id"`
+	CategoryIDs []uint `json:"category_ids" validate:"required,min=1"`
+}
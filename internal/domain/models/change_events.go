@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Entities reported by the change feed
+const (
+	ChangeEntityUser     = "user"
+	ChangeEntitySetting  = "setting"
+	ChangeEntityProduct  = "product"
+	ChangeEntityCategory = "category"
+	// Orders on the kitchen display, published when they reach or leave it and when the kitchen
+	// acknowledges their lines
+	ChangeEntityKitchenTicket = "kitchen_ticket"
+)
+
+// Change feed actions
+const (
+	ChangeActionCreated = "created"
+	ChangeActionUpdated = "updated"
+	ChangeActionDeleted = "deleted"
+)
+
+// ChangeEvents is a notice that an entity changed. The ID doubles as the feed's sequence number.
+// Notices carry no data; clients refetch the entity or drop it from their cache.
+type ChangeEvents struct {
+	ID        uint64    `json:"seq" gorm:"primaryKey"`
+	Entity    string    `json:"entity" gorm:"not null;size:50"`
+	EntityID  uint      `json:"entity_id" gorm:"not null"`
+	Action    string    `json:"action" gorm:"not null;size:20"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// ChangeFeedQuery represents the query parameters of the change feed.
+// Without since, the feed starts at the latest sequence number.
+type ChangeFeedQuery struct {
+	Since *uint64 `form:"since"`
+	Limit int     `form:"limit" validate:"omitempty,min=1,max=500"`
+	// Wait is how many seconds to hold the request open when there are no new changes
+	Wait int `form:"wait" validate:"min=0,max=30"`
+}
+
+// ChangeFeedResponse lists changes after the requested sequence number.
+// LastSeq is the cursor to pass as since on the next request.
+type ChangeFeedResponse struct {
+	Events  []ChangeEvents `json:"events"`
+	LastSeq uint64         `json:"last_seq"`
+}
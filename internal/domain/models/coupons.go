@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Coupons are codes customers hand over at checkout to get a coupon-only promotion. A coupon
+// can be limited in how often it is used overall and per customer, and expires at ExpiresAt.
+// UsedCount counts the orders it was redeemed on.
+type Coupons struct {
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	Code             string         `json:"code" gorm:"not null;size:50;uniqueIndex:idx_coupons_code,where:deleted_at IS NULL"`
+	PromotionID      uint           `json:"promotion_id" gorm:"not null;index"`
+	UsageLimit       *int           `json:"usage_limit"`
+	PerCustomerLimit *int           `json:"per_customer_limit"`
+	UsedCount        int            `json:"used_count" gorm:"not null;default:0"`
+	Active           bool           `json:"active" gorm:"not null;default:true"`
+	ExpiresAt        *time.Time     `json:"expires_at"`
+	CreatedByID      *uint          `json:"created_by_id,omitempty" gorm:"index"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Coupon redemption statuses
+const (
+	// Held for a cart until ExpiresAt, counting against the coupon's limits
+	CouponRedemptionReserved = "reserved"
+	// Used on an order
+	CouponRedemptionRedeemed = "redeemed"
+	// Given back when the coupon was removed from the cart or the cart was cleared
+	CouponRedemptionReleased = "released"
+)
+
+// CouponReservationTTL is how long applying a coupon to a cart holds a use of it
+const CouponReservationTTL = 30 * time.Minute
+
+// CouponRedemptions are the uses of coupons. Applying a coupon to a cart reserves a use, so
+// limited coupons can't be handed out more often than allowed while carts are being rung up;
+// checkout turns the reservation into a redemption of the order. Customer is the email or
+// phone number the customer gave, normalized, for per-customer limits.
+type CouponRedemptions struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	CouponID  uint       `json:"coupon_id" gorm:"not null;index"`
+	CartID    *uint      `json:"cart_id,omitempty" gorm:"index"`
+	OrderID   *uint      `json:"order_id,omitempty" gorm:"index"`
+	Customer  string     `json:"customer,omitempty" gorm:"size:255;index"`
+	Status    string     `json:"status" gorm:"not null;size:20;index"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// CreateCouponsRequest represents the request payload for creating coupons. With a code one
+// coupon is created with it; otherwise Count coupons are generated with random codes starting
+// with Prefix.
+type CreateCouponsRequest struct {
+	PromotionID      uint       `json:"promotion_id" validate:"required"`
+	Code             string     `json:"code" validate:"omitempty,min=4,max=50,alphanum"`
+	Count            int        `json:"count" validate:"required_without=Code,omitempty,min=1,max=1000"`
+	Prefix           string     `json:"prefix" validate:"omitempty,max=20,alphanum"`
+	UsageLimit       *int       `json:"usage_limit" validate:"omitempty,min=1"`
+	PerCustomerLimit *int       `json:"per_customer_limit" validate:"omitempty,min=1"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+}
+
+// UpdateCouponRequest represents the request payload for changing a coupon's limits
+type UpdateCouponRequest struct {
+	UsageLimit       *int       `json:"usage_limit" validate:"omitempty,min=1"`
+	PerCustomerLimit *int       `json:"per_customer_limit" validate:"omitempty,min=1"`
+	Active           *bool      `json:"active" validate:"required"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+}
+
+// ApplyCouponRequest represents the request payload for applying a coupon to the cart.
+// Customer identifies who uses it, by email or phone number, and is required for coupons
+// limited per customer.
+type ApplyCouponRequest struct {
+	Code     string `json:"code" validate:"required,max=50"`
+	Customer string `json:"customer" validate:"max=255"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Customers are the people orders are sold to. Email and phone are optional but identify the
+// customer, e.g. for receipts and per-customer coupon limits; MarketingOptInAt records when
+// the customer agreed to marketing. LoyaltyPoints is the customer's balance of loyalty points,
+// only changed through the loyalty ledger. Business customers have a company, tax ID and billing
+// address, which their invoices are made out to.
+type Customers struct {
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	Name             string         `json:"name" gorm:"not null;size:255;index"`
+	Email            string         `json:"email" gorm:"size:255;index"`
+	Phone            string         `json:"phone" gorm:"size:50;index"`
+	Company          string         `json:"company" gorm:"size:255"`
+	TaxID            string         `json:"tax_id" gorm:"size:50"`
+	BillingAddress   string         `json:"billing_address" gorm:"type:text"`
+	Notes            string         `json:"notes" gorm:"type:text"`
+	MarketingOptIn   bool           `json:"marketing_opt_in" gorm:"not null;default:false"`
+	MarketingOptInAt *time.Time     `json:"marketing_opt_in_at,omitempty"`
+	LoyaltyPoints    int64          `json:"loyalty_points" gorm:"not null;default:0"`
+	Stats            *CustomerStats `json:"stats,omitempty" gorm:"-"`
+	ImportID         *uint          `json:"import_id,omitempty" gorm:"index"`
+	CreatedByID      *uint          `json:"created_by_id,omitempty" gorm:"index"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// CustomerStats sum up a customer's purchases. Draft and cancelled orders don't count.
+type CustomerStats struct {
+	OrderCount  int64      `json:"order_count"`
+	TotalSpent  int64      `json:"total_spent"`
+	LastOrderAt *time.Time `json:"last_order_at"`
+}
+
+// CreateCustomerRequest represents the request payload for creating a customer
+type CreateCustomerRequest struct {
+	Name           string `json:"name" validate:"required,max=255"`
+	Email          string `json:"email" validate:"omitempty,email,max=255"`
+	Phone          string `json:"phone" validate:"omitempty,max=50"`
+	Company        string `json:"company" validate:"max=255"`
+	TaxID          string `json:"tax_id" validate:"max=50"`
+	BillingAddress string `json:"billing_address" validate:"max=2000"`
+	Notes          string `json:"notes" validate:"max=5000"`
+	MarketingOptIn bool   `json:"marketing_opt_in"`
+}
+
+// UpdateCustomerRequest represents the request payload for updating a customer
+type UpdateCustomerRequest struct {
+	Name           string `json:"name" validate:"required,max=255"`
+	Email          string `json:"email" validate:"omitempty,email,max=255"`
+	Phone          string `json:"phone" validate:"omitempty,max=50"`
+	Company        string `json:"company" validate:"max=255"`
+	TaxID          string `json:"tax_id" validate:"max=50"`
+	BillingAddress string `json:"billing_address" validate:"max=2000"`
+	Notes          string `json:"notes" validate:"max=5000"`
+	MarketingOptIn bool   `json:"marketing_opt_in"`
+}
+
+// SetCustomerRequest represents the request payload for attaching a customer to an order or
+// the cart; no customer detaches it
+type SetCustomerRequest struct {
+	CustomerID *uint `json:"customer_id"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Tax modes
+const (
+	TaxModeInclusive = "inclusive"
+	TaxModeExclusive = "exclusive"
+)
+
+// DefaultEnabledTenders is used for terminals without an explicit configuration
+var DefaultEnabledTenders = JSONStringList{"cash", "card"}
+
+type DeviceConfigs struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	DeviceID       uint           `json:"device_id" gorm:"uniqueIndex;not null"`
+	ReceiptHeader  string         `json:"receipt_header" gorm:"size:500"`
+	EnabledTenders JSONStringList `json:"enabled_tenders" gorm:"type:jsonb;not null;default:'[]'"`
+	TaxMode        string         `json:"tax_mode" gorm:"not null;default:'exclusive';size:20"`
+	FeatureFlags   JSONMap        `json:"feature_flags" gorm:"type:jsonb;not null;default:'{}'"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// UpdateDeviceConfigRequest represents the request payload for changing a terminal's configuration
+type UpdateDeviceConfigRequest struct {
+	ReceiptHeader  string          `json:"receipt_header" validate:"max=500"`
+	EnabledTenders []string        `json:"enabled_tenders" validate:"required,min=1,dive,oneof=cash card gift_card store_credit voucher"`
+	TaxMode        string          `json:"tax_mode" validate:"required,oneof=inclusive exclusive"`
+	FeatureFlags   map[string]bool `json:"feature_flags"`
+}
+
+// TerminalConfigResponse represents the configuration payload served to a terminal
+type TerminalConfigResponse struct {
+	DeviceID       uint            `json:"device_id"`
+	ReceiptHeader  string          `json:"receipt_header"`
+	EnabledTenders []string        `json:"enabled_tenders"`
+	TaxMode        string          `json:"tax_mode"`
+	FeatureFlags   map[string]bool `json:"feature_flags"`
+	UpdatedAt      *time.Time      `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Device statuses
+const (
+	DeviceStatusPending     = "pending"
+	DeviceStatusActive      = "active"
+	DeviceStatusDeactivated = "deactivated"
+)
+
+// Devices are the terminals of a business. A terminal set up at a store only works in that
+// store; Location picks its tax rates and receipt template.
+type Devices struct {
+	ID                   uint           `json:"id" gorm:"primaryKey"`
+	Name                 string         `json:"name" gorm:"not null;size:100"`
+	Location             string         `json:"location" gorm:"size:100;index"`
+	StoreID              *uint          `json:"store_id,omitempty" gorm:"index"`
+	Status               string         `json:"status" gorm:"not null;default:'pending';size:20;index"`
+	PairingCode          *string        `json:"-" gorm:"uniqueIndex;size:16"`
+	PairingCodeExpiresAt *time.Time     `json:"pairing_code_expires_at,omitempty"`
+	TokenHash            *string        `json:"-" gorm:"uniqueIndex;size:64"`
+	PairedAt             *time.Time     `json:"paired_at,omitempty"`
+	LastSeenAt           *time.Time     `json:"last_seen_at,omitempty"`
+	DeactivatedAt        *time.Time     `json:"deactivated_at,omitempty"`
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+	DeletedAt            gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// CreateDeviceRequest represents the request payload for registering a device
+type CreateDeviceRequest struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	Location string `json:"location" validate:"max=100"`
+	StoreID  *uint  `json:"store_id" validate:"omitempty,min=1"`
+}
+
+// UpdateDeviceRequest represents the request payload for updating a device
+type UpdateDeviceRequest struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	Location string `json:"location" validate:"max=100"`
+	StoreID  *uint  `json:"store_id" validate:"omitempty,min=1"`
+}
+
+// PairingCodeResponse represents a freshly issued pairing code for a device
+type PairingCodeResponse struct {
+	Device      Devices   `json:"device"`
+	PairingCode string    `json:"pairing_code"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// PairDeviceRequest represents the request payload sent by a terminal to pair itself
+type PairDeviceRequest struct {
+	PairingCode string `json:"pairing_code" validate:"required"`
+}
+
+// PairDeviceResponse represents the device token returned once on pairing
+type PairDeviceResponse struct {
+	Device      Devices `json:"device"`
+	DeviceToken string  `json:"device_token"`
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Experiment statuses
+const (
+	ExperimentStatusDraft   = "draft"
+	ExperimentStatusRunning = "running"
+	ExperimentStatusStopped = "stopped"
+)
+
+// Experiment subject types
+const (
+	ExperimentSubjectUser   = "user"
+	ExperimentSubjectDevice = "device"
+)
+
+// ExperimentVariant is one arm of an experiment. Weights are relative to the other variants.
+type ExperimentVariant struct {
+	Name   string `json:"name" validate:"required,max=50"`
+	Weight int    `json:"weight" validate:"required,min=1,max=1000"`
+}
+
+// ExperimentVariants is a list of variants stored in a JSONB column
+type ExperimentVariants []ExperimentVariant
+
+// Value implements driver.Valuer
+func (v ExperimentVariants) Value() (driver.Value, error) {
+	if v == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (v *ExperimentVariants) Scan(value interface{}) error {
+	var data []byte
+	switch val := value.(type) {
+	case nil:
+		*v = ExperimentVariants{}
+		return nil
+	case []byte:
+		data = val
+	case string:
+		data = []byte(val)
+	default:
+		return fmt.Errorf("unsupported type for ExperimentVariants: %T", value)
+	}
+
+	result := ExperimentVariants{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*v = result
+	return nil
+}
+
+type Experiments struct {
+	ID          uint               `json:"id" gorm:"primaryKey"`
+	Key         string             `json:"key" gorm:"unique;not null;size:100"`
+	Name        string             `json:"name" gorm:"not null;size:100"`
+	Description string             `json:"description" gorm:"size:255"`
+	SubjectType string             `json:"subject_type" gorm:"not null;default:'user';size:20"`
+	Status      string             `json:"status" gorm:"not null;default:'draft';size:20;index"`
+	Rollout     int                `json:"rollout" gorm:"not null;default:100"`
+	Variants    ExperimentVariants `json:"variants" gorm:"type:jsonb;not null;default:'[]'"`
+	StartedAt   *time.Time         `json:"started_at,omitempty"`
+	StoppedAt   *time.Time         `json:"stopped_at,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt     `json:"-" gorm:"index"`
+}
+
+// ExperimentAssignments records the variant a subject was placed in, so it never changes
+// when weights or rollout are adjusted later
+type ExperimentAssignments struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	ExperimentID uint      `json:"experiment_id" gorm:"not null;uniqueIndex:idx_experiment_assignments_subject"`
+	SubjectType  string    `json:"subject_type" gorm:"not null;size:20;uniqueIndex:idx_experiment_assignments_subject"`
+	SubjectID    uint      `json:"subject_id" gorm:"not null;uniqueIndex:idx_experiment_assignments_subject"`
+	Variant      string    `json:"variant" gorm:"not null;size:50"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ExperimentRequest represents the request payload for creating or updating an experiment
+type ExperimentRequest struct {
+	Key         string              `json:"key" validate:"required,max=100"`
+	Name        string              `json:"name" validate:"required,max=100"`
+	Description string              `json:"description" validate:"max=255"`
+	SubjectType string              `json:"subject_type" validate:"required,oneof=user device"`
+	Rollout     *int                `json:"rollout" validate:"omitempty,min=0,max=100"`
+	Variants    []ExperimentVariant `json:"variants" validate:"required,min=1,max=10,dive"`
+}
+
+// UpdateExperimentStatusRequest represents the request payload for starting or stopping an experiment
+type UpdateExperimentStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=draft running stopped"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// Gift card statuses. Void cards can't be redeemed, e.g. when they were lost or the order that
+// sold them was cancelled.
+const (
+	GiftCardStatusActive = "active"
+	GiftCardStatusVoid   = "void"
+)
+
+// Gift card transaction types. Cards are issued when an order selling them is paid or by hand,
+// redeemed by gift card payments and refunded when such a payment is refunded. Voiding a card
+// takes its remaining balance.
+const (
+	GiftCardIssued   = "issued"
+	GiftCardRedeemed = "redeemed"
+	GiftCardRefunded = "refunded"
+	GiftCardVoided   = "voided"
+)
+
+// GiftCards are stored-value cards redeemed as payments. Amounts are in minor currency units.
+// Cards sold at the till keep the order and line they were sold on.
+type GiftCards struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	Code           string     `json:"code" gorm:"not null;size:32;uniqueIndex"`
+	InitialBalance int64      `json:"initial_balance" gorm:"not null"`
+	Balance        int64      `json:"balance" gorm:"not null"`
+	Status         string     `json:"status" gorm:"not null;default:'active';size:20;index"`
+	CustomerID     *uint      `json:"customer_id,omitempty" gorm:"index"`
+	OrderID        *uint      `json:"order_id,omitempty" gorm:"index"`
+	OrderItemID    *uint      `json:"order_item_id,omitempty" gorm:"index"`
+	Note           string     `json:"note" gorm:"size:255"`
+	IssuedByID     *uint      `json:"issued_by_id,omitempty" gorm:"index"`
+	VoidedByID     *uint      `json:"voided_by_id,omitempty" gorm:"index"`
+	VoidedAt       *time.Time `json:"voided_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"index"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// GiftCardTransactions are the ledger of a card's balance. Amount is positive for value added
+// and negative for value taken; Balance is the card's balance after the transaction.
+type GiftCardTransactions struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	GiftCardID  uint      `json:"gift_card_id" gorm:"not null;index"`
+	Type        string    `json:"type" gorm:"not null;size:20;index"`
+	Amount      int64     `json:"amount" gorm:"not null"`
+	Balance     int64     `json:"balance" gorm:"not null"`
+	OrderID     *uint     `json:"order_id,omitempty" gorm:"index"`
+	PaymentID   *uint     `json:"payment_id,omitempty" gorm:"index"`
+	Reason      string    `json:"reason,omitempty" gorm:"size:255"`
+	CreatedByID *uint     `json:"created_by_id,omitempty" gorm:"index"`
+	CreatedAt   time.Time `json:"created_at" gorm:"index"`
+}
+
+// IssueGiftCardRequest represents the request payload for issuing a gift card by hand, e.g. as a
+// goodwill gesture
+type IssueGiftCardRequest struct {
+	Amount     int64  `json:"amount" validate:"required,min=1"`
+	CustomerID *uint  `json:"customer_id" validate:"omitempty,min=1"`
+	Note       string `json:"note" validate:"max=255"`
+}
+
+// VoidGiftCardRequest represents the request payload for voiding a gift card
+type VoidGiftCardRequest struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+// GiftCardBalanceRequest represents the request payload for checking a card's balance
+type GiftCardBalanceRequest struct {
+	Code string `json:"code" validate:"required,max=64"`
+}
+
+// GiftCardBalanceResponse is what a balance check tells about a card. The code is masked so
+// only its holder knows it in full.
+type GiftCardBalanceResponse struct {
+	Code    string `json:"code"`
+	Balance int64  `json:"balance"`
+	Status  string `json:"status"`
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// What an import creates
+const (
+	ImportEntityProducts  = "products"
+	ImportEntityOrders    = "orders"
+	ImportEntityCustomers = "customers"
+)
+
+// Import statuses
+const (
+	ImportStatusPending    = "pending"
+	ImportStatusProcessing = "processing"
+	ImportStatusCompleted  = "completed"
+	ImportStatusFailed     = "failed"
+)
+
+// ImportFields lists the fields each entity reads from an import file; fields marked required
+// must be present in every row
+var ImportFields = map[string]map[string]bool{
+	ImportEntityProducts: {
+		"name":          true,
+		"sku":           true,
+		"barcode":       false,
+		"description":   false,
+		"price":         false,
+		"cost":          false,
+		"category":      false,
+		"status":        false,
+		"reorder_point": false,
+	},
+	ImportEntityOrders: {
+		"order_number": true,
+		"placed_at":    true,
+		"status":       false,
+		"note":         false,
+		"sku":          true,
+		"name":         false,
+		"quantity":     true,
+		"unit_price":   false,
+		"unit_cost":    false,
+		"discount":     false,
+		"tax_rate":     false,
+	},
+	ImportEntityCustomers: {
+		"name":             true,
+		"email":            false,
+		"phone":            false,
+		"notes":            false,
+		"marketing_opt_in": false,
+	},
+}
+
+// ImportRowError is a problem with one row of an import. Row 1 is the first record after the
+// header.
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Field string `json:"field,omitempty"`
+	Error string `json:"error"`
+}
+
+// ImportRowErrors are the row errors of an import stored in a JSONB column
+type ImportRowErrors []ImportRowError
+
+// Value implements driver.Valuer
+func (e ImportRowErrors) Value() (driver.Value, error) {
+	if e == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (e *ImportRowErrors) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*e = ImportRowErrors{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for ImportRowErrors: %T", value)
+	}
+
+	result := ImportRowErrors{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*e = result
+	return nil
+}
+
+// Imports load historical products, orders and customers from another POS, and bulk update
+// the catalog. The file is kept until the import is processed in the background, base64
+// encoded when it is an XLSX workbook; an import is all or nothing, so a failed import created
+// nothing and lists what to fix in Errors. Upserts update the products whose SKU they list and
+// create the rest, and dry runs check the whole file and report what they would have done
+// without writing anything. ProcessedRows tracks the progress of a running import. The kept file
+// is charged to the storage usage of TenantID and StoreID, DataBytes at a time, until it is
+// dropped or the import deleted.
+type Imports struct {
+	ID            uint            `json:"id" gorm:"primaryKey"`
+	Entity        string          `json:"entity" gorm:"not null;size:20"`
+	Format        string          `json:"format" gorm:"not null;size:10"`
+	Filename      string          `json:"filename" gorm:"size:255"`
+	Mapping       JSONMap         `json:"mapping" gorm:"type:jsonb;not null;default:'{}'"`
+	Data          string          `json:"-" gorm:"type:text;not null"`
+	Upsert        bool            `json:"upsert" gorm:"not null;default:false"`
+	DryRun        bool            `json:"dry_run" gorm:"not null;default:false"`
+	Status        string          `json:"status" gorm:"not null;default:'pending';size:20;index"`
+	TotalRows     int             `json:"total_rows" gorm:"not null;default:0"`
+	ProcessedRows int             `json:"processed_rows" gorm:"not null;default:0"`
+	ImportedRows  int             `json:"imported_rows" gorm:"not null;default:0"`
+	CreatedRows   int             `json:"created_rows" gorm:"not null;default:0"`
+	UpdatedRows   int             `json:"updated_rows" gorm:"not null;default:0"`
+	Errors        ImportRowErrors `json:"errors" gorm:"type:jsonb;not null;default:'[]'"`
+	CreatedByID   *uint           `json:"created_by_id,omitempty" gorm:"index"`
+	TenantID      uint            `json:"-" gorm:"not null;default:0"`
+	StoreID       uint            `json:"-" gorm:"not null;default:0"`
+	DataBytes     int64           `json:"-" gorm:"not null;default:0"`
+	StartedAt     *time.Time      `json:"started_at,omitempty"`
+	FinishedAt    *time.Time      `json:"finished_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at" gorm:"index"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// CreateImportRequest represents the form fields of an import upload, sent along with the file.
+// Mapping is a JSON object of field names to the file's column names.
+type CreateImportRequest struct {
+	Entity  string `form:"entity" validate:"required,oneof=products orders customers"`
+	Format  string `form:"format" validate:"omitempty,oneof=csv json xlsx"`
+	Mapping string `form:"mapping" validate:"omitempty,max=5000"`
+}
+
+// ProductImportRequest represents the form fields of a catalog upload, sent along with the
+// file. Rows update the product with their SKU or create one; DryRun only checks the file.
+type ProductImportRequest struct {
+	Format  string `form:"format" validate:"omitempty,oneof=csv json xlsx"`
+	Mapping string `form:"mapping" validate:"omitempty,max=5000"`
+	DryRun  bool   `form:"dry_run"`
+}
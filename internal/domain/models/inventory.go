@@ -0,0 +1,108 @@
+package models
+
+import "time"
+
+// Stock movement types
+const (
+	StockMovementSale       = "sale"
+	StockMovementPurchase   = "purchase"
+	StockMovementAdjustment = "adjustment"
+	StockMovementTransfer   = "transfer"
+	// Stock of a sale put back, e.g. when the order is cancelled
+	StockMovementReturn = "return"
+)
+
+// Stock adjustment reasons
+const (
+	StockAdjustmentDamage    = "damage"
+	StockAdjustmentShrinkage = "shrinkage"
+	StockAdjustmentRecount   = "recount"
+)
+
+// StockLevels hold the quantity on hand of a product, or one of its variants, at a store.
+// Levels are only changed by recording stock movements. A nil store is the default store
+// used until stores are set up.
+type StockLevels struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ProductID uint      `json:"product_id" gorm:"not null;index"`
+	VariantID *uint     `json:"variant_id" gorm:"index"`
+	StoreID   *uint     `json:"store_id" gorm:"index"`
+	Quantity  int64     `json:"quantity" gorm:"not null;default:0"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StockMovements is the ledger of every stock change. Quantity is the signed change and
+// Balance the stock level right after it, both in the product's stock unit; UnitQuantity is the
+// change as it was given, counted in Unit. Movements of tracked products list the serials or
+// name the batch that moved.
+type StockMovements struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	ProductID    uint           `json:"product_id" gorm:"not null;index"`
+	VariantID    *uint          `json:"variant_id" gorm:"index"`
+	StoreID      *uint          `json:"store_id" gorm:"index"`
+	Type         string         `json:"type" gorm:"not null;size:20;index"`
+	Quantity     int64          `json:"quantity" gorm:"not null"`
+	Balance      int64          `json:"balance" gorm:"not null"`
+	Unit         string         `json:"unit" gorm:"not null;default:'ea';size:20"`
+	UnitQuantity int64          `json:"unit_quantity" gorm:"not null;default:0"`
+	Reason       string         `json:"reason,omitempty" gorm:"size:20;index"`
+	Reference    string         `json:"reference" gorm:"size:100;index"`
+	Note         string         `json:"note" gorm:"size:255"`
+	Serials      JSONStringList `json:"serials,omitempty" gorm:"type:jsonb;not null;default:'[]'"`
+	Batch        string         `json:"batch,omitempty" gorm:"size:100;index"`
+	CreatedByID  *uint          `json:"created_by_id,omitempty" gorm:"index"`
+	CreatedAt    time.Time      `json:"created_at" gorm:"index"`
+}
+
+// StockMovementInput describes a stock change for InventoryService.RecordMovements. Serials
+// and Batch name the units of tracked products that move, and OrderID the order serials are
+// sold with. ExpiresAt sets the expiry date of a batch coming in. Quantity is counted in Unit,
+// or in the product's stock unit when Unit is empty.
+type StockMovementInput struct {
+	ProductID   uint
+	VariantID   *uint
+	StoreID     *uint
+	Type        string
+	Quantity    int64
+	Unit        string
+	Reason      string
+	Reference   string
+	Note        string
+	Serials     []string
+	Batch       string
+	ExpiresAt   *time.Time
+	OrderID     *uint
+	CreatedByID *uint
+}
+
+// StockAdjustmentItem is one stock change of an adjustment; Quantity is the signed change.
+// Serials and Batch optionally name the units of tracked products adjusted. Unit is the unit of
+// measure Quantity is counted in and defaults to the product's stock unit.
+type StockAdjustmentItem struct {
+	ProductID uint     `json:"product_id" validate:"required"`
+	VariantID *uint    `json:"variant_id"`
+	Quantity  int64    `json:"quantity" validate:"required"`
+	Unit      string   `json:"unit" validate:"max=20"`
+	Serials   []string `json:"serials" validate:"omitempty,max=1000,dive,required,max=100"`
+	Batch     string   `json:"batch" validate:"max=100"`
+}
+
+// StockAdjustmentRequest represents the request payload for adjusting stock. StoreID is set by
+// the server to the store the request works in, whose stock is adjusted.
+type StockAdjustmentRequest struct {
+	Reason    string                `json:"reason" validate:"required,oneof=damage shrinkage recount"`
+	Reference string                `json:"reference" validate:"max=100"`
+	Note      string                `json:"note" validate:"max=255"`
+	Items     []StockAdjustmentItem `json:"items" validate:"required,min=1,dive"`
+	StoreID   *uint                 `json:"-"`
+}
+
+// LowStockProduct is a product whose stock, summed over its variants and stores, is at or
+// below its reorder point
+type LowStockProduct struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	SKU          string `json:"sku"`
+	ReorderPoint int64  `json:"reorder_point"`
+	Quantity     int64  `json:"quantity"`
+}
@@ -0,0 +1,136 @@
+package models
+
+import "time"
+
+// Invoice statuses. Invoices are issued unpaid, or paid when the order was paid at the till, and
+// become paid once payments cover the total. Void invoices keep their number, so numbering has
+// no gaps, and the order can be invoiced again.
+const (
+	InvoiceStatusIssued        = "issued"
+	InvoiceStatusPartiallyPaid = "partially_paid"
+	InvoiceStatusPaid          = "paid"
+	InvoiceStatusVoid          = "void"
+)
+
+// InvoiceSequences hold the last invoice number handed out per store. A sequence row stays
+// locked while an invoice is issued, so numbers are handed out in order and a rolled back
+// invoice doesn't leave a gap. Scope is "store:<id>", or "store:none" for invoices of orders
+// made outside any store.
+type InvoiceSequences struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Scope      string    `json:"scope" gorm:"not null;size:50;uniqueIndex"`
+	LastNumber int64     `json:"last_number" gorm:"not null;default:0"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Invoices are made out from orders for business customers. Seller and buyer details, lines and
+// totals are copied when the invoice is issued, so later changes to the store, customer or
+// catalog don't rewrite it. Amounts are in minor currency units; Balance is what is still due.
+type Invoices struct {
+	ID            uint              `json:"id" gorm:"primaryKey"`
+	Number        string            `json:"number" gorm:"not null;size:40;uniqueIndex"`
+	Sequence      int64             `json:"sequence" gorm:"not null"`
+	Status        string            `json:"status" gorm:"not null;default:'issued';size:20;index"`
+	OrderID       uint              `json:"order_id" gorm:"not null;index"`
+	CustomerID    uint              `json:"customer_id" gorm:"not null;index"`
+	StoreID       *uint             `json:"store_id,omitempty" gorm:"index"`
+	SellerName    string            `json:"seller_name" gorm:"not null;size:255"`
+	SellerAddress string            `json:"seller_address" gorm:"type:text"`
+	SellerTaxID   string            `json:"seller_tax_id" gorm:"size:50"`
+	SellerEmail   string            `json:"seller_email" gorm:"size:255"`
+	SellerPhone   string            `json:"seller_phone" gorm:"size:50"`
+	BuyerName     string            `json:"buyer_name" gorm:"not null;size:255;index"`
+	BuyerCompany  string            `json:"buyer_company" gorm:"size:255"`
+	BuyerAddress  string            `json:"buyer_address" gorm:"type:text"`
+	BuyerTaxID    string            `json:"buyer_tax_id" gorm:"size:50"`
+	BuyerEmail    string            `json:"buyer_email" gorm:"size:255"`
+	Subtotal      int64             `json:"subtotal" gorm:"not null"`
+	DiscountTotal int64             `json:"discount_total" gorm:"not null"`
+	TaxTotal      int64             `json:"tax_total" gorm:"not null"`
+	IncludedTax   int64             `json:"included_tax" gorm:"not null"`
+	Total         int64             `json:"total" gorm:"not null"`
+	AmountPaid    int64             `json:"amount_paid" gorm:"not null;default:0"`
+	Balance       int64             `json:"balance" gorm:"not null"`
+	Note          string            `json:"note" gorm:"type:text"`
+	Terms         string            `json:"terms" gorm:"type:text"`
+	IssuedAt      time.Time         `json:"issued_at" gorm:"not null;index"`
+	DueAt         time.Time         `json:"due_at" gorm:"not null;index"`
+	PaidAt        *time.Time        `json:"paid_at,omitempty"`
+	VoidReason    string            `json:"void_reason,omitempty" gorm:"size:255"`
+	VoidedAt      *time.Time        `json:"voided_at,omitempty"`
+	CreatedByID   *uint             `json:"created_by_id,omitempty" gorm:"index"`
+	VoidedByID    *uint             `json:"voided_by_id,omitempty" gorm:"index"`
+	Lines         []InvoiceLines    `json:"lines,omitempty" gorm:"foreignKey:InvoiceID"`
+	Taxes         []InvoiceTaxes    `json:"taxes,omitempty" gorm:"foreignKey:InvoiceID"`
+	Payments      []InvoicePayments `json:"payments,omitempty" gorm:"foreignKey:InvoiceID"`
+	CreatedAt     time.Time         `json:"created_at" gorm:"index"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// InvoiceLines are the lines of an invoice, copied from the order's items
+type InvoiceLines struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	InvoiceID   uint   `json:"invoice_id" gorm:"not null;index"`
+	OrderItemID uint   `json:"order_item_id" gorm:"not null"`
+	Description string `json:"description" gorm:"not null;size:255"`
+	SKU         string `json:"sku" gorm:"size:100"`
+	Quantity    int64  `json:"quantity" gorm:"not null"`
+	UnitPrice   int64  `json:"unit_price" gorm:"not null"`
+	Subtotal    int64  `json:"subtotal" gorm:"not null"`
+	Discount    int64  `json:"discount" gorm:"not null"`
+	TaxRate     int64  `json:"tax_rate" gorm:"not null"`
+	Tax         int64  `json:"tax" gorm:"not null"`
+	Total       int64  `json:"total" gorm:"not null"`
+}
+
+// InvoiceTaxes are the tax breakdown of an invoice, copied from the order's
+type InvoiceTaxes struct {
+	ID        uint `json:"id" gorm:"primaryKey"`
+	InvoiceID uint `json:"invoice_id" gorm:"not null;index"`
+	TaxBreakdown
+}
+
+// InvoicePayments are payments towards an invoice: the order's payments at the till, recorded
+// when the invoice is issued, and payments received later, e.g. by bank transfer. PaymentID is
+// the till payment, if it was one.
+type InvoicePayments struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	InvoiceID   uint      `json:"invoice_id" gorm:"not null;index"`
+	PaymentID   *uint     `json:"payment_id,omitempty" gorm:"index"`
+	Method      string    `json:"method" gorm:"not null;size:30"`
+	Amount      int64     `json:"amount" gorm:"not null"`
+	Reference   string    `json:"reference" gorm:"size:100"`
+	PaidAt      time.Time `json:"paid_at" gorm:"not null"`
+	CreatedByID *uint     `json:"created_by_id,omitempty" gorm:"index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CreateInvoiceRequest represents the request payload for invoicing an order. The invoice is due
+// at DueAt, or DueDays after it is issued; Terms are the payment terms printed on it.
+type CreateInvoiceRequest struct {
+	OrderID uint       `json:"order_id" validate:"required"`
+	DueAt   *time.Time `json:"due_at"`
+	DueDays *int       `json:"due_days" validate:"omitempty,min=0,max=365"`
+	Note    string     `json:"note" validate:"max=2000"`
+	Terms   string     `json:"terms" validate:"max=2000"`
+}
+
+// InvoicePaymentRequest represents the request payload for recording a payment received
+// towards an invoice
+type InvoicePaymentRequest struct {
+	Amount    int64      `json:"amount" validate:"required,min=1"`
+	Method    string     `json:"method" validate:"required,max=30"`
+	Reference string     `json:"reference" validate:"max=100"`
+	PaidAt    *time.Time `json:"paid_at"`
+}
+
+// VoidInvoiceRequest represents the request payload for voiding an invoice
+type VoidInvoiceRequest struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+// InvoicePDFQuery selects the location whose receipt template sets the currency of an invoice
+// PDF, when the request doesn't come from a terminal
+type InvoicePDFQuery struct {
+	Location string `form:"location" validate:"max=100"`
+}
@@ -0,0 +1,163 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// JSONMap is a JSON object stored in a JSONB column
+type JSONMap map[string]interface{}
+
+// Value implements driver.Valuer
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (m *JSONMap) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*m = JSONMap{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for JSONMap: %T", value)
+	}
+
+	result := JSONMap{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*m = result
+	return nil
+}
+
+// JSONStringList is a list of strings stored in a JSONB column
+type JSONStringList []string
+
+// Value implements driver.Valuer
+func (l JSONStringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (l *JSONStringList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = JSONStringList{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for JSONStringList: %T", value)
+	}
+
+	result := JSONStringList{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*l = result
+	return nil
+}
+
+// JSONIDList is a list of record IDs stored in a JSONB column
+type JSONIDList []uint
+
+// Value implements driver.Valuer
+func (l JSONIDList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (l *JSONIDList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = JSONIDList{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for JSONIDList: %T", value)
+	}
+
+	result := JSONIDList{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*l = result
+	return nil
+}
+
+// JSONValue is any JSON value (string, number, boolean, object, or list) stored in a JSONB column
+type JSONValue struct {
+	Data interface{}
+}
+
+// MarshalJSON encodes the wrapped value
+func (v JSONValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Data)
+}
+
+// UnmarshalJSON decodes any JSON value
+func (v *JSONValue) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.Data)
+}
+
+// Value implements driver.Valuer
+func (v JSONValue) Value() (driver.Value, error) {
+	data, err := json.Marshal(v.Data)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (v *JSONValue) Scan(value interface{}) error {
+	var data []byte
+	switch raw := value.(type) {
+	case nil:
+		v.Data = nil
+		return nil
+	case []byte:
+		data = raw
+	case string:
+		data = []byte(raw)
+	default:
+		return fmt.Errorf("unsupported type for JSONValue: %T", value)
+	}
+
+	return json.Unmarshal(data, &v.Data)
+}
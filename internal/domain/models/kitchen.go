@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Kitchen statuses of order lines. Lines are queued when the order is created, reach the display
+// once it is placed, are started when a cook picks them up and done when ready to be served.
+const (
+	KitchenStatusQueued  = "queued"
+	KitchenStatusStarted = "started"
+	KitchenStatusDone    = "done"
+)
+
+// KitchenTicketItem is a line of a kitchen ticket
+type KitchenTicketItem struct {
+	ID            uint       `json:"id"`
+	Name          string     `json:"name"`
+	VariantTitle  string     `json:"variant_title,omitempty"`
+	Quantity      int64      `json:"quantity"`
+	Course        int64      `json:"course,omitempty"`
+	Seat          int64      `json:"seat,omitempty"`
+	KitchenStatus string     `json:"kitchen_status"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	DoneAt        *time.Time `json:"done_at,omitempty"`
+}
+
+// KitchenTicket is an order as the kitchen display shows it. Open tickets are placed or paid
+// orders with lines not done yet; the rest have left the display.
+type KitchenTicket struct {
+	OrderID   uint                `json:"order_id"`
+	Number    string              `json:"number"`
+	Status    string              `json:"status"`
+	Note      string              `json:"note"`
+	StoreID   *uint               `json:"store_id,omitempty"`
+	TableID   *uint               `json:"table_id,omitempty"`
+	TableName string              `json:"table_name,omitempty"`
+	PlacedAt  *time.Time          `json:"placed_at,omitempty"`
+	Open      bool                `json:"open"`
+	Items     []KitchenTicketItem `json:"items"`
+}
+
+// KitchenTicketEvent is a ticket to send down the kitchen stream since it changed. Seq is the change
+// feed sequence number the stream resumes from.
+type KitchenTicketEvent struct {
+	Seq    uint64         `json:"seq"`
+	Ticket *KitchenTicket `json:"ticket"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Login failure reasons
+const (
+	LoginFailureUnknownUser      = "unknown_user"
+	LoginFailureInvalidPassword  = "invalid_password"
+	LoginFailureAccountSuspended = "account_suspended"
+	LoginFailureAccountPending   = "account_pending"
+)
+
+// LoginEvents records every login attempt. UserID is empty when the username did not match any user.
+type LoginEvents struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	UserID        *uint     `json:"user_id" gorm:"index:idx_login_events_user_created"`
+	Username      string    `json:"username" gorm:"size:50"`
+	Success       bool      `json:"success" gorm:"not null;index"`
+	FailureReason string    `json:"failure_reason,omitempty" gorm:"size:50"`
+	IPAddress     string    `json:"ip_address" gorm:"size:45"`
+	UserAgent     string    `json:"user_agent" gorm:"size:255"`
+	CreatedAt     time.Time `json:"created_at" gorm:"index:idx_login_events_user_created"`
+}
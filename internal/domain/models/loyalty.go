@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Loyalty transaction types. Points are earned when an order is paid and taken back when it is
+// cancelled or refunded; redeemed points pay for orders and come back when the payment is
+// refunded. Adjustments are made by staff.
+const (
+	LoyaltyEarned   = "earned"
+	LoyaltyReversed = "reversed"
+	LoyaltyRedeemed = "redeemed"
+	LoyaltyRefunded = "refunded"
+	LoyaltyAdjusted = "adjusted"
+)
+
+// LoyaltyRules say how many points customers earn: Points for every Spend minor currency units
+// of a line's total. A rule with a category applies to the category's products and wins over
+// rules without one; where several rules apply the most generous counts.
+type LoyaltyRules struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	Name       string         `json:"name" gorm:"not null;size:255"`
+	CategoryID *uint          `json:"category_id,omitempty" gorm:"index"`
+	Spend      int64          `json:"spend" gorm:"not null"`
+	Points     int64          `json:"points" gorm:"not null"`
+	Active     bool           `json:"active" gorm:"not null;default:true;index"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// LoyaltyTransactions are the ledger of a customer's points. Points is positive for points
+// added and negative for points taken; Balance is the customer's balance after the transaction.
+type LoyaltyTransactions struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	CustomerID  uint      `json:"customer_id" gorm:"not null;index"`
+	Type        string    `json:"type" gorm:"not null;size:20;index"`
+	Points      int64     `json:"points" gorm:"not null"`
+	Balance     int64     `json:"balance" gorm:"not null"`
+	OrderID     *uint     `json:"order_id,omitempty" gorm:"index"`
+	PaymentID   *uint     `json:"payment_id,omitempty" gorm:"index"`
+	Reason      string    `json:"reason,omitempty" gorm:"size:255"`
+	CreatedByID *uint     `json:"created_by_id,omitempty" gorm:"index"`
+	CreatedAt   time.Time `json:"created_at" gorm:"index"`
+}
+
+// LoyaltyRuleRequest represents the request payload for creating or updating a loyalty rule
+type LoyaltyRuleRequest struct {
+	Name       string `json:"name" validate:"required,max=255"`
+	CategoryID *uint  `json:"category_id" validate:"omitempty,min=1"`
+	Spend      int64  `json:"spend" validate:"required,min=1"`
+	Points     int64  `json:"points" validate:"required,min=1"`
+	Active     *bool  `json:"active"`
+}
+
+// LoyaltyAdjustmentRequest represents the request payload for adding points to or taking
+// them from a customer's balance
+type LoyaltyAdjustmentRequest struct {
+	Points int64  `json:"points" validate:"required,ne=0"`
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+// LoyaltyBalanceResponse is a customer's point balance and what it is worth when redeemed,
+// in minor currency units
+type LoyaltyBalanceResponse struct {
+	CustomerID uint  `json:"customer_id"`
+	Points     int64 `json:"points"`
+	PointValue int64 `json:"point_value"`
+	Value      int64 `json:"value"`
+}
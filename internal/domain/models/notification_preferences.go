@@ -0,0 +1,91 @@
+package models
+
+import "time"
+
+// Notification channels
+const (
+	NotificationChannelEmail = "email"
+	NotificationChannelSMS   = "sms"
+	NotificationChannelPush  = "push"
+	// Shown in the user's notification inbox
+	NotificationChannelInApp = "in_app"
+)
+
+// NotificationChannels lists every channel a user can set preferences for
+var NotificationChannels = []string{NotificationChannelEmail, NotificationChannelSMS, NotificationChannelPush, NotificationChannelInApp}
+
+// Notification event types
+const (
+	// Sign-up verification, email changes and password resets
+	NotificationEventAccountSecurity = "account_security"
+	// Product news and announcements
+	NotificationEventAnnouncements = "announcements"
+	// Products falling to or below their reorder point
+	NotificationEventLowStock = "low_stock"
+	// Orders a user placed being paid, fulfilled, cancelled or refunded by someone else
+	NotificationEventOrderStatus = "order_status"
+	// Route groups missing their response time objective, sent to administrators
+	NotificationEventSLOAlerts = "slo_alerts"
+	// Lines of orders a user placed being marked done by the kitchen
+	NotificationEventKitchenReady = "kitchen_ready"
+	// Order receipts emailed to customers. Customers aren't users, so there are no
+	// preferences for it.
+	NotificationEventReceipt = "receipt"
+	// Quotes emailed to customers; like receipts, there are no preferences for it
+	NotificationEventQuote = "quote"
+	// Scheduled reports emailed to their recipients, who needn't be users; like receipts, there
+	// are no preferences for it
+	NotificationEventScheduledReport = "scheduled_report"
+	// Scheduled reports that failed to run or be delivered, sent to administrators
+	NotificationEventReportFailures = "report_failures"
+)
+
+// NotificationEvent describes an event type users receive notifications for
+type NotificationEvent struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	// Critical notifications are always sent and cannot be opted out of
+	Critical bool `json:"critical"`
+}
+
+// NotificationEvents lists every notification event type
+var NotificationEvents = []NotificationEvent{
+	{Type: NotificationEventAccountSecurity, Description: "Account verification, email changes and password resets", Critical: true},
+	{Type: NotificationEventAnnouncements, Description: "Product news and announcements"},
+	{Type: NotificationEventLowStock, Description: "Products running low on stock"},
+	{Type: NotificationEventOrderStatus, Description: "Changes to the status of orders you placed"},
+	{Type: NotificationEventSLOAlerts, Description: "API route groups responding too slowly"},
+	{Type: NotificationEventKitchenReady, Description: "Items of orders you placed ready to be served"},
+	{Type: NotificationEventReportFailures, Description: "Scheduled reports that failed to run or be delivered"},
+}
+
+// NotificationPreferences records a user's choice for one channel and event type.
+// Without a row the notification is sent.
+type NotificationPreferences struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_notification_preference"`
+	Channel   string    `json:"channel" gorm:"not null;size:20;uniqueIndex:idx_notification_preference"`
+	EventType string    `json:"event_type" gorm:"not null;size:50;uniqueIndex:idx_notification_preference"`
+	Enabled   bool      `json:"enabled" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotificationPreference is a user's effective choice for one channel and event type
+type NotificationPreference struct {
+	Channel   string `json:"channel" validate:"required,oneof=email sms push in_app"`
+	EventType string `json:"event_type" validate:"required,max=50"`
+	Enabled   *bool  `json:"enabled" validate:"required"`
+	Critical  bool   `json:"critical"`
+}
+
+// UpdateNotificationPreferencesRequest changes some of the user's preferences; the rest are kept
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreference `json:"preferences" validate:"required,min=1,dive"`
+}
+
+// NotificationPreferencesResponse lists the user's preferences for every channel and event type
+type NotificationPreferencesResponse struct {
+	Preferences []NotificationPreference `json:"preferences"`
+	Events      []NotificationEvent      `json:"events"`
+}
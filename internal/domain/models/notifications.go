@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Notifications are the in-app notifications in a user's inbox
+type Notifications struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	EventType string     `json:"event_type" gorm:"not null;size:50;index"`
+	Title     string     `json:"title" gorm:"not null;size:255"`
+	Body      string     `json:"body" gorm:"type:text"`
+	Data      JSONMap    `json:"data" gorm:"type:jsonb;not null;default:'{}'"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"index"`
+}
+
+// MarkNotificationsReadResponse reports how many notifications were marked as read
+type MarkNotificationsReadResponse struct {
+	Updated int64 `json:"updated"`
+}
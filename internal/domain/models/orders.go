@@ -0,0 +1,169 @@
+package models
+
+import "time"
+
+// Order statuses
+const (
+	// Being put together; takes no stock until it is placed
+	OrderStatusDraft     = "draft"
+	OrderStatusPlaced    = "placed"
+	OrderStatusPaid      = "paid"
+	OrderStatusFulfilled = "fulfilled"
+	OrderStatusCompleted = "completed"
+	OrderStatusCancelled = "cancelled"
+	OrderStatusRefunded  = "refunded"
+)
+
+// OrderStatusTransitions lists the statuses each status may move to. Orders are cancelled
+// until they are paid and returned after. Placed orders become paid only once their payments
+// or invoice settle them, so no status change moves an order to paid by itself.
+var OrderStatusTransitions = map[string][]string{
+	OrderStatusDraft:     {OrderStatusPlaced, OrderStatusCancelled},
+	OrderStatusPlaced:    {OrderStatusCancelled},
+	OrderStatusPaid:      {OrderStatusFulfilled},
+	OrderStatusFulfilled: {OrderStatusCompleted},
+}
+
+// OrderRefundableStatuses are the statuses an order becomes refunded from once returns have
+// taken back all of its items. Returns refund the payments, so no status change moves an order
+// to refunded by itself.
+var OrderRefundableStatuses = []string{OrderStatusPaid, OrderStatusFulfilled, OrderStatusCompleted}
+
+// Orders are completed sales. Amounts are in minor currency units and computed by the server
+// from the line items: Total = Subtotal - DiscountTotal + TaxTotal - IncludedTax, where
+// IncludedTax is the part of TaxTotal already included in prices. TaxRegion picks the tax
+// rates the order was taxed at and Taxes break its tax down per rate. Number is derived from
+// the ID once the order is saved and is what receipts and stock movements refer to; imported
+// orders keep the number they had in the POS they came from. CustomerID is the customer the
+// sale was made to, if any, DeviceID the terminal it was rung up at, ShiftID that terminal's
+// shift and StoreID the store it was sold in, whose stock it takes. TableID is the table whose ticket it was
+// checked out from. Each status records when the order reached it.
+type Orders struct {
+	ID            uint              `json:"id" gorm:"primaryKey"`
+	Number        string            `json:"number" gorm:"not null;size:30;index"`
+	Status        string            `json:"status" gorm:"not null;default:'placed';size:20;index"`
+	StatusReason  string            `json:"status_reason,omitempty" gorm:"size:255"`
+	Note          string            `json:"note" gorm:"size:255"`
+	ItemCount     int64             `json:"item_count" gorm:"not null;default:0"`
+	Subtotal      int64             `json:"subtotal" gorm:"not null;default:0"`
+	DiscountTotal int64             `json:"discount_total" gorm:"not null;default:0"`
+	TaxTotal      int64             `json:"tax_total" gorm:"not null;default:0"`
+	IncludedTax   int64             `json:"included_tax" gorm:"not null;default:0"`
+	Total         int64             `json:"total" gorm:"not null;default:0"`
+	TaxRegion     string            `json:"tax_region,omitempty" gorm:"size:100"`
+	CustomerID    *uint             `json:"customer_id,omitempty" gorm:"index"`
+	CouponID      *uint             `json:"coupon_id,omitempty" gorm:"index"`
+	DeviceID      *uint             `json:"device_id,omitempty" gorm:"index"`
+	ShiftID       *uint             `json:"shift_id,omitempty" gorm:"index"`
+	StoreID       *uint             `json:"store_id,omitempty" gorm:"index"`
+	TableID       *uint             `json:"table_id,omitempty" gorm:"index"`
+	CreatedByID   *uint             `json:"created_by_id,omitempty" gorm:"index"`
+	ImportID      *uint             `json:"import_id,omitempty" gorm:"index"`
+	Items         []OrderItems      `json:"items,omitempty" gorm:"foreignKey:OrderID"`
+	Promotions    []OrderPromotions `json:"promotions,omitempty" gorm:"foreignKey:OrderID"`
+	Taxes         []OrderTaxes      `json:"taxes,omitempty" gorm:"foreignKey:OrderID"`
+	PlacedAt      *time.Time        `json:"placed_at,omitempty" gorm:"index"`
+	PaidAt        *time.Time        `json:"paid_at,omitempty" gorm:"index"`
+	FulfilledAt   *time.Time        `json:"fulfilled_at,omitempty"`
+	CompletedAt   *time.Time        `json:"completed_at,omitempty"`
+	CancelledAt   *time.Time        `json:"cancelled_at,omitempty"`
+	RefundedAt    *time.Time        `json:"refunded_at,omitempty"`
+	CreatedAt     time.Time         `json:"created_at" gorm:"index"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// OrderItems are the lines of an order. Name, SKU, unit price and cost are copied from the
+// product when the order is placed, so later catalog changes don't rewrite past sales, as is
+// the unit of measure the quantity is counted in. Taxes are the rates the line was taxed at,
+// applied to the line amount after its discount, and TaxRate is their sum in basis points
+// (1000 = 10%). Tax included in the price is part of Tax but not added to Total. Course and
+// Seat are restaurant annotations telling the kitchen when to fire the line and who it is for;
+// 0 means none. KitchenStatus follows the line through the kitchen once the order is placed;
+// lines sold before there was a kitchen display have none. ReturnedQuantity and RefundedAmount
+// count what has been returned and paid back so far. Serials name the units sold of serialized
+// products, and ReturnedSerials the serials returned since. Batch is the batch asked for of
+// batch-tracked products; Batches are the batches the line was taken from when the order was
+// placed, the first to expire first unless one was asked for. Components are what lines of
+// bundles were made of.
+type OrderItems struct {
+	ID               uint                  `json:"id" gorm:"primaryKey"`
+	OrderID          uint                  `json:"order_id" gorm:"not null;index"`
+	ProductID        uint                  `json:"product_id" gorm:"not null;index"`
+	VariantID        *uint                 `json:"variant_id" gorm:"index"`
+	Name             string                `json:"name" gorm:"not null;size:255"`
+	VariantTitle     string                `json:"variant_title,omitempty" gorm:"size:255"`
+	SKU              string                `json:"sku" gorm:"not null;size:100"`
+	Quantity         int64                 `json:"quantity" gorm:"not null"`
+	Unit             string                `json:"unit" gorm:"not null;default:'ea';size:20"`
+	UnitPrice        int64                 `json:"unit_price" gorm:"not null"`
+	UnitCost         int64                 `json:"unit_cost" gorm:"not null;default:0"`
+	Subtotal         int64                 `json:"subtotal" gorm:"not null"`
+	Discount         int64                 `json:"discount" gorm:"not null;default:0"`
+	TaxRate          int64                 `json:"tax_rate" gorm:"not null;default:0"`
+	Tax              int64                 `json:"tax" gorm:"not null;default:0"`
+	Taxes            LineTaxes             `json:"taxes" gorm:"type:jsonb;not null;default:'[]'"`
+	Total            int64                 `json:"total" gorm:"not null"`
+	Course           int64                 `json:"course,omitempty" gorm:"not null;default:0"`
+	Seat             int64                 `json:"seat,omitempty" gorm:"not null;default:0"`
+	KitchenStatus    string                `json:"kitchen_status,omitempty" gorm:"not null;default:'';size:20"`
+	KitchenStartedAt *time.Time            `json:"kitchen_started_at,omitempty"`
+	KitchenDoneAt    *time.Time            `json:"kitchen_done_at,omitempty"`
+	Serials          JSONStringList        `json:"serials,omitempty" gorm:"type:jsonb;not null;default:'[]'"`
+	Batch            string                `json:"batch,omitempty" gorm:"size:100;index"`
+	Batches          BatchAllocations      `json:"batches,omitempty" gorm:"type:jsonb;not null;default:'[]'"`
+	ReturnedQuantity int64                 `json:"returned_quantity" gorm:"not null;default:0"`
+	ReturnedSerials  JSONStringList        `json:"returned_serials,omitempty" gorm:"type:jsonb;not null;default:'[]'"`
+	RefundedAmount   int64                 `json:"refunded_amount" gorm:"not null;default:0"`
+	Components       []OrderItemComponents `json:"components,omitempty" gorm:"foreignKey:OrderItemID"`
+	CreatedAt        time.Time             `json:"created_at"`
+}
+
+// OrderItemRequest is one line of a new order. The unit price defaults to the current price of
+// the product or variant; Discount is an amount off the line. TaxRate is added on top for
+// products without a tax class; products with one are taxed at its rates. Course and Seat
+// annotate the line for the kitchen. Lines of serialized products list a serial per unit sold;
+// lines of batch-tracked products may name the batch they are sold from.
+type OrderItemRequest struct {
+	ProductID uint     `json:"product_id" validate:"required"`
+	VariantID *uint    `json:"variant_id"`
+	Quantity  int64    `json:"quantity" validate:"required,min=1"`
+	UnitPrice *int64   `json:"unit_price" validate:"omitempty,min=0"`
+	Discount  int64    `json:"discount" validate:"min=0"`
+	TaxRate   int64    `json:"tax_rate" validate:"min=0,max=10000"`
+	Course    int64    `json:"course" validate:"min=0,max=100"`
+	Seat      int64    `json:"seat" validate:"min=0,max=1000"`
+	Serials   []string `json:"serials" validate:"omitempty,max=1000,dive,required,max=100"`
+	Batch     string   `json:"batch" validate:"max=100"`
+}
+
+// CreateOrderRequest represents the request payload for placing an order. Draft orders are
+// saved without taking stock and placed later. Region picks the tax rates of products with a
+// tax class and defaults to the location of the terminal placing the order. DeviceID and StoreID
+// are set by the server to the terminal placing the order, which needs an open shift, and the
+// store the request works in; TableID to the table whose ticket is checked out. QuotedPrices is
+// set when converting a quote: its lines' prices and discounts are charged as they are, without
+// applying the promotions running now on top.
+type CreateOrderRequest struct {
+	Note         string             `json:"note" validate:"max=255"`
+	Region       string             `json:"region" validate:"max=100"`
+	CustomerID   *uint              `json:"customer_id"`
+	Draft        bool               `json:"draft"`
+	Items        []OrderItemRequest `json:"items" validate:"required,min=1,dive"`
+	DeviceID     *uint              `json:"-"`
+	StoreID      *uint              `json:"-"`
+	TableID      *uint              `json:"-"`
+	QuotedPrices bool               `json:"-"`
+}
+
+// OrderStatusRequest represents the request payload for moving an order to another status
+type OrderStatusRequest struct {
+	Reason string `json:"reason" validate:"max=255"`
+}
+
+// ReceiptQuery selects how an order's receipt is rendered: as a PDF (the default), ESC/POS
+// commands for thermal printers or plain text. Location picks the location's receipt template
+// when the request doesn't come from a terminal.
+type ReceiptQuery struct {
+	Format   string `form:"format" validate:"omitempty,oneof=pdf escpos text"`
+	Location string `form:"location" validate:"max=100"`
+}
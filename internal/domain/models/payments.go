@@ -0,0 +1,100 @@
+package models
+
+import "time"
+
+// Payment statuses
+const (
+	// Waiting for the provider to confirm, e.g. while the customer authenticates with their bank
+	PaymentStatusPending = "pending"
+	// Reserved at the provider, not taken yet
+	PaymentStatusAuthorized = "authorized"
+	PaymentStatusCaptured   = "captured"
+	// Declined by the provider; FailureReason says why
+	PaymentStatusFailed = "failed"
+	// Captured and returned in full
+	PaymentStatusRefunded = "refunded"
+)
+
+// Payments are the money taken for an order. An order may be paid with several payments, e.g.
+// part cash and part card, and becomes paid once its captured payments cover the total.
+// Amounts are in minor currency units; Tendered and Change record the cash handed over and
+// given back, while Amount is what goes towards the order. ShiftID is the shift the cashier
+// had open when taking the payment.
+type Payments struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	OrderID        uint       `json:"order_id" gorm:"not null;index"`
+	Method         string     `json:"method" gorm:"not null;size:30;index"`
+	Status         string     `json:"status" gorm:"not null;size:20;index"`
+	Amount         int64      `json:"amount" gorm:"not null"`
+	RefundedAmount int64      `json:"refunded_amount" gorm:"not null;default:0"`
+	Tendered       int64      `json:"tendered" gorm:"not null;default:0"`
+	Change         int64      `json:"change" gorm:"not null;default:0"`
+	Reference      string     `json:"reference" gorm:"size:255;index"`
+	ShiftID        *uint      `json:"shift_id,omitempty" gorm:"index"`
+	FailureReason  string     `json:"failure_reason,omitempty" gorm:"size:255"`
+	CreatedByID    *uint      `json:"created_by_id,omitempty" gorm:"index"`
+	CapturedAt     *time.Time `json:"captured_at,omitempty"`
+	RefundedAt     *time.Time `json:"refunded_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"index"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// CreatePaymentRequest represents the request payload for paying an order. Tendered is the cash
+// handed over, when more than the amount; Capture false only authorizes the payment at
+// providers that support it.
+type CreatePaymentRequest struct {
+	Method    string `json:"method" validate:"required,max=30"`
+	Amount    int64  `json:"amount" validate:"required,min=1"`
+	Tendered  *int64 `json:"tendered" validate:"omitempty,min=1"`
+	Reference string `json:"reference" validate:"max=255"`
+	Capture   *bool  `json:"capture"`
+}
+
+// RefundPaymentRequest represents the request payload for refunding part or all of a payment
+type RefundPaymentRequest struct {
+	Amount int64  `json:"amount" validate:"required,min=1"`
+	Reason string `json:"reason" validate:"max=255"`
+}
+
+// OrderPaymentsResponse lists an order's payments and how they add up against its total.
+// Paid is captured minus refunded; Balance is what is left to authorize once pending payments
+// are confirmed.
+type OrderPaymentsResponse struct {
+	OrderID    uint       `json:"order_id"`
+	Total      int64      `json:"total"`
+	Pending    int64      `json:"pending"`
+	Authorized int64      `json:"authorized"`
+	Captured   int64      `json:"captured"`
+	Refunded   int64      `json:"refunded"`
+	Paid       int64      `json:"paid"`
+	Balance    int64      `json:"balance"`
+	Payments   []Payments `json:"payments"`
+}
+
+// Payment event statuses
+const (
+	PaymentEventStatusPending   = "pending"
+	PaymentEventStatusProcessed = "processed"
+	// Given up after PaymentEventMaxAttempts; LastError says why
+	PaymentEventStatusFailed = "failed"
+)
+
+// PaymentEventMaxAttempts is how often applying a payment event is tried before it fails
+const PaymentEventMaxAttempts = 10
+
+// PaymentEvents are webhook events received from payment providers. They are stored when they
+// arrive and applied in the background, once per provider and event ID, so redeliveries are
+// ignored and events that arrive before their payment is recorded are retried.
+type PaymentEvents struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Provider    string     `json:"provider" gorm:"not null;size:30;uniqueIndex:idx_payment_events_provider_event"`
+	EventID     string     `json:"event_id" gorm:"not null;size:100;uniqueIndex:idx_payment_events_provider_event"`
+	Type        string     `json:"type" gorm:"not null;size:100"`
+	Payload     string     `json:"-" gorm:"type:text;not null"`
+	Status      string     `json:"status" gorm:"not null;size:20;index"`
+	Attempts    int        `json:"attempts" gorm:"not null;default:0"`
+	LastError   string     `json:"last_error,omitempty" gorm:"size:255"`
+	RetryAt     *time.Time `json:"retry_at,omitempty"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
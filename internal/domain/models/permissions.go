@@ -0,0 +1,116 @@
+package models
+
+import "time"
+
+// Permissions that can be granted to roles. Administrators hold every permission.
+const (
+	PermissionUsersView        = "users.view"
+	PermissionUsersTag         = "users.tag"
+	PermissionUsersOnline      = "users.online"
+	PermissionActivityView     = "activity.view"
+	PermissionSettingsView     = "settings.view"
+	PermissionInventoryView    = "inventory.view"
+	PermissionInventoryAdjust  = "inventory.adjust"
+	PermissionInventoryAlerts  = "inventory.alerts"
+	PermissionInventoryCount   = "inventory.count"
+	PermissionOrdersView       = "orders.view"
+	PermissionOrdersCreate     = "orders.create"
+	PermissionOrdersManage     = "orders.manage"
+	PermissionOrdersRefund     = "orders.refund"
+	PermissionCustomersView    = "customers.view"
+	PermissionCustomersManage  = "customers.manage"
+	PermissionLoyaltyAdjust    = "loyalty.adjust"
+	PermissionKitchenView      = "kitchen.view"
+	PermissionKitchenUpdate    = "kitchen.update"
+	PermissionInvoicesView     = "invoices.view"
+	PermissionInvoicesManage   = "invoices.manage"
+	PermissionQuotesView       = "quotes.view"
+	PermissionQuotesManage     = "quotes.manage"
+	PermissionGiftCardsView    = "gift_cards.view"
+	PermissionGiftCardsManage  = "gift_cards.manage"
+	PermissionSuppliersView    = "suppliers.view"
+	PermissionSuppliersManage  = "suppliers.manage"
+	PermissionAnalyticsView    = "analytics.view"
+	PermissionTransfersView    = "transfers.view"
+	PermissionTransfersManage  = "transfers.manage"
+	PermissionTransfersApprove = "transfers.approve"
+	PermissionPrintJobsView    = "print_jobs.view"
+	PermissionPrintJobsManage  = "print_jobs.manage"
+)
+
+// PermissionCatalog lists every permission with a short description
+var PermissionCatalog = map[string]string{
+	PermissionUsersView:        "View and export the user directory and follow user updates",
+	PermissionUsersTag:         "Add and remove user tags",
+	PermissionUsersOnline:      "See which users are online",
+	PermissionActivityView:     "View user activity and login history",
+	PermissionSettingsView:     "View business settings",
+	PermissionInventoryView:    "View stock levels and stock movements",
+	PermissionInventoryAdjust:  "Adjust stock for damage, shrinkage and recounts, and approve stocktakes",
+	PermissionInventoryAlerts:  "Receive low-stock alerts",
+	PermissionInventoryCount:   "Open stocktakes and record counted stock",
+	PermissionOrdersView:       "View orders and their items",
+	PermissionOrdersCreate:     "Place orders",
+	PermissionOrdersManage:     "Move orders through payment, fulfilment, cancellation and refund",
+	PermissionOrdersRefund:     "Refund orders and lines through their payments and process returns",
+	PermissionCustomersView:    "View customers and their purchase history",
+	PermissionCustomersManage:  "Add, change and delete customers",
+	PermissionLoyaltyAdjust:    "Add and take loyalty points by hand",
+	PermissionKitchenView:      "Follow the tickets on the kitchen display",
+	PermissionKitchenUpdate:    "Mark the items of kitchen tickets started and done",
+	PermissionInvoicesView:     "View invoices and download them as PDF",
+	PermissionInvoicesManage:   "Issue and void invoices and record payments against them",
+	PermissionQuotesView:       "View quotes, their versions and PDFs",
+	PermissionQuotesManage:     "Draft, revise and send quotes, record answers and convert them into orders",
+	PermissionGiftCardsView:    "View gift cards, their codes and their transactions",
+	PermissionGiftCardsManage:  "Issue gift cards by hand and void them",
+	PermissionSuppliersView:    "View suppliers and what products are bought from them",
+	PermissionSuppliersManage:  "Add, change and delete suppliers and link them to products",
+	PermissionAnalyticsView:    "View sales analytics",
+	PermissionTransfersView:    "View stock transfers between stores and stock in transit",
+	PermissionTransfersManage:  "Request, send, receive and cancel stock transfers",
+	PermissionTransfersApprove: "Approve requested stock transfers",
+	PermissionPrintJobsView:    "View the print jobs queued for terminals",
+	PermissionPrintJobsManage:  "Queue print jobs on terminals and cancel and retry them",
+}
+
+// RolePermissions grants a permission to everyone with a role
+type RolePermissions struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Role        string    `json:"role" gorm:"not null;size:20;uniqueIndex:idx_role_permissions_role_permission"`
+	Permission  string    `json:"permission" gorm:"not null;size:100;uniqueIndex:idx_role_permissions_role_permission"`
+	GrantedByID *uint     `json:"granted_by_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RoleUsersRequest represents the request payload for assigning a role to, or removing it from, many users
+type RoleUsersRequest struct {
+	UserIDs []uint `json:"user_ids" validate:"required,min=1,dive,min=1"`
+}
+
+// PermissionRolesRequest represents the request payload for granting or revoking a permission for many roles
+type PermissionRolesRequest struct {
+	Roles []string `json:"roles" validate:"required,min=1,dive,oneof=admin user"`
+}
+
+// PermissionRoleResult is the outcome of a grant or revoke for a single role
+type PermissionRoleResult struct {
+	Role    string `json:"role"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// PermissionRolesResponse summarizes a grant or revoke across roles
+type PermissionRolesResponse struct {
+	Permission string                 `json:"permission"`
+	Succeeded  int                    `json:"succeeded"`
+	Failed     int                    `json:"failed"`
+	Results    []PermissionRoleResult `json:"results"`
+}
+
+// PermissionInfo describes a permission and the roles it is granted to
+type PermissionInfo struct {
+	Permission  string   `json:"permission"`
+	Description string   `json:"description"`
+	Roles       []string `json:"roles"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Print job types
+const (
+	PrintJobTypeReceipt = "receipt"
+	PrintJobTypeLabel   = "label"
+)
+
+// Print job statuses
+const (
+	PrintJobStatusQueued    = "queued"
+	PrintJobStatusPrinting  = "printing"
+	PrintJobStatusPrinted   = "printed"
+	PrintJobStatusFailed    = "failed"
+	PrintJobStatusCancelled = "cancelled"
+)
+
+type PrintJobs struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	DeviceID    uint       `json:"device_id" gorm:"not null;index:idx_print_jobs_device_status"`
+	Type        string     `json:"type" gorm:"not null;size:20"`
+	Payload     string     `json:"payload" gorm:"type:text;not null"`
+	Copies      int        `json:"copies" gorm:"not null;default:1"`
+	Status      string     `json:"status" gorm:"not null;default:'queued';size:20;index:idx_print_jobs_device_status"`
+	Attempts    int        `json:"attempts" gorm:"not null;default:0"`
+	MaxAttempts int        `json:"max_attempts" gorm:"not null;default:3"`
+	LastError   string     `json:"last_error,omitempty" gorm:"size:500"`
+	AvailableAt time.Time  `json:"available_at" gorm:"not null;index"`
+	ClaimedAt   *time.Time `json:"claimed_at,omitempty"`
+	PrintedAt   *time.Time `json:"printed_at,omitempty"`
+	CreatedByID *uint      `json:"created_by_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// CreatePrintJobRequest represents the request payload for enqueuing a print job
+type CreatePrintJobRequest struct {
+	DeviceID    uint   `json:"device_id" validate:"required"`
+	Type        string `json:"type" validate:"required,oneof=receipt label"`
+	Payload     string `json:"payload" validate:"required"`
+	Copies      int    `json:"copies" validate:"omitempty,min=1,max=20"`
+	MaxAttempts int    `json:"max_attempts" validate:"omitempty,min=1,max=10"`
+	// StoreID is the store the job was queued from, whose devices it may print on; set by the server
+	StoreID *uint `json:"-"`
+}
+
+// AckPrintJobRequest represents a terminal's report on a print job it claimed
+type AckPrintJobRequest struct {
+	Status string `json:"status" validate:"required,oneof=printed failed"`
+	Error  string `json:"error" validate:"max=500"`
+}
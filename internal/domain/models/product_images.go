@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// ProductImages are the gallery of a product. Files are kept in storage under Key, with a
+// scaled-down copy under ThumbnailKey; Position orders the gallery and the primary image is
+// the one shown for the product in listings. TenantID and StoreID name who the files are
+// charged to in storage usage.
+type ProductImages struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	ProductID     uint      `json:"product_id" gorm:"not null;index"`
+	Key           string    `json:"-" gorm:"not null;size:255"`
+	ThumbnailKey  string    `json:"-" gorm:"not null;size:255"`
+	URL           string    `json:"url" gorm:"not null;size:500"`
+	ThumbnailURL  string    `json:"thumbnail_url" gorm:"not null;size:500"`
+	Filename      string    `json:"filename" gorm:"size:255"`
+	ContentType   string    `json:"content_type" gorm:"not null;size:50"`
+	Size          int64     `json:"size" gorm:"not null"`
+	ThumbnailSize int64     `json:"thumbnail_size" gorm:"not null;default:0"`
+	Width         int       `json:"width" gorm:"not null"`
+	Height        int       `json:"height" gorm:"not null"`
+	Position      int       `json:"position" gorm:"not null;default:0"`
+	IsPrimary     bool      `json:"is_primary" gorm:"not null;default:false"`
+	TenantID      uint      `json:"-" gorm:"not null;default:0"`
+	StoreID       uint      `json:"-" gorm:"not null;default:0"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ProductImageUpload is one file of an image upload
+type ProductImageUpload struct {
+	Filename string
+	Data     []byte
+}
+
+// ReorderProductImagesRequest represents the request payload for reordering a product's
+// gallery; every image of the product must be listed, in the new order
+type ReorderProductImagesRequest struct {
+	ImageIDs []uint `json:"image_ids" validate:"required,min=1"`
+}
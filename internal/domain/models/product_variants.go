@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// How product listings include variants
+const (
+	ProductVariantsNest    = "nest"    // Each product carries its variants
+	ProductVariantsFlatten = "flatten" // Each variant is a row of its own; products without variants keep one row
+)
+
+// ProductOptions are the dimensions a product varies in, e.g. size with the values S, M and L.
+// Position orders the options, and with them the parts of variant titles and SKUs.
+type ProductOptions struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	ProductID uint           `json:"product_id" gorm:"not null;uniqueIndex:idx_product_options_product_name"`
+	Name      string         `json:"name" gorm:"not null;size:50;uniqueIndex:idx_product_options_product_name"`
+	Values    JSONStringList `json:"values" gorm:"type:jsonb;not null"`
+	Position  int            `json:"position" gorm:"not null;default:0"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// ProductVariants are the sellable combinations of a product's option values. Every variant has
+// its own SKU, barcode and price; Options maps each option name to the variant's value.
+type ProductVariants struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	ProductID uint           `json:"product_id" gorm:"not null;index"`
+	Title     string         `json:"title" gorm:"not null;size:255"`
+	Options   JSONMap        `json:"options" gorm:"type:jsonb;not null"`
+	SKU       string         `json:"sku" gorm:"not null;size:100;uniqueIndex:idx_product_variants_sku,where:deleted_at IS NULL"`
+	Barcode   string         `json:"barcode" gorm:"size:100;index"`
+	Price     int64          `json:"price" gorm:"not null;default:0"`
+	Cost      int64          `json:"cost" gorm:"not null;default:0"`
+	Status    string         `json:"status" gorm:"not null;default:'active';size:20;index"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// ProductOptionRequest is one option of a product's option set
+type ProductOptionRequest struct {
+	Name   string   `json:"name" validate:"required,max=50"`
+	Values []string `json:"values" validate:"required,min=1,dive,required,max=50"`
+}
+
+// SetProductOptionsRequest replaces a product's option set. An empty list removes every option.
+type SetProductOptionsRequest struct {
+	Options []ProductOptionRequest `json:"options" validate:"max=5,dive"`
+}
+
+// UpdateProductVariantRequest represents the request payload for updating a variant
+type UpdateProductVariantRequest struct {
+	SKU     string `json:"sku" validate:"required,max=100"`
+	Barcode string `json:"barcode" validate:"omitempty,max=100"`
+	Price   int64  `json:"price" validate:"min=0"`
+	Cost    int64  `json:"cost" validate:"min=0"`
+	Status  string `json:"status" validate:"required,oneof=active inactive archived"`
+}
+
+// ProductVariantsResponse lists a product's option set and variants
+type ProductVariantsResponse struct {
+	Options  []ProductOptions  `json:"options"`
+	Variants []ProductVariants `json:"variants"`
+}
+
+// GenerateProductVariantsResponse reports the outcome of generating a product's variants
+type GenerateProductVariantsResponse struct {
+	// Variants created for combinations that had none
+	Created []ProductVariants `json:"created"`
+	// Variants whose combination is no longer part of the option set; they were made inactive
+	Deactivated []ProductVariants `json:"deactivated"`
+	Variants    []ProductVariants `json:"variants"`
+}
+
+// BarcodeLookupResponse is what a scanned barcode resolves to: a product, or one of its
+// variants, with its current price and stock summed over stores
+type BarcodeLookupResponse struct {
+	ProductID    uint    `json:"product_id"`
+	VariantID    *uint   `json:"variant_id"`
+	Name         string  `json:"name"`
+	VariantTitle *string `json:"variant_title"`
+	SKU          string  `json:"sku"`
+	Barcode      string  `json:"barcode"`
+	Price        int64   `json:"price"`
+	Status       string  `json:"status"`
+	Stock        int64   `json:"stock"`
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Product statuses
+const (
+	ProductStatusActive   = "active"
+	ProductStatusInactive = "inactive"
+	ProductStatusArchived = "archived"
+)
+
+// Products are the items sold at the point of sale. Prices and costs are in minor
+// currency units (e.g. cents) so totals add up without rounding errors. Products whose stock
+// falls to or below their reorder point are reported as low on stock; LowStockAlertedAt
+// records when managers were alerted so they are alerted once until stock recovers. Products
+// in a tax class are taxed at its rates, variants included. ImageURL and ThumbnailURL are those
+// of the primary image of the product's gallery. Selling a gift card product issues a gift card
+// per unit worth the line's unit price. Tracking is "serial" or "batch" for products whose
+// stock is tracked by serial number or batch; their sales and receipts name the units. Unit is
+// the code of the unit of measure the product is stocked and sold in; prices and costs are per
+// unit. Bundles are sold as a set of component products that hold the stock, priced as set by
+// BundlePricing.
+type Products struct {
+	ID                uint              `json:"id" gorm:"primaryKey"`
+	Name              string            `json:"name" gorm:"not null;size:255;index"`
+	SKU               string            `json:"sku" gorm:"not null;size:100;uniqueIndex:idx_products_sku,where:deleted_at IS NULL"`
+	Barcode           string            `json:"barcode" gorm:"size:100;index"`
+	Description       string            `json:"description" gorm:"type:text"`
+	Price             int64             `json:"price" gorm:"not null;default:0"`
+	Cost              int64             `json:"cost" gorm:"not null;default:0"`
+	CategoryID        *uint             `json:"category_id" gorm:"index"`
+	TaxClassID        *uint             `json:"tax_class_id" gorm:"index"`
+	Status            string            `json:"status" gorm:"not null;default:'active';size:20;index"`
+	ReorderPoint      *int64            `json:"reorder_point"`
+	GiftCard          bool              `json:"gift_card" gorm:"not null;default:false"`
+	Tracking          string            `json:"tracking" gorm:"not null;default:'';size:20"`
+	Unit              string            `json:"unit" gorm:"not null;default:'ea';size:20"`
+	Bundle            bool              `json:"bundle" gorm:"not null;default:false"`
+	BundlePricing     string            `json:"bundle_pricing,omitempty" gorm:"not null;default:'';size:20"`
+	LowStockAlertedAt *time.Time        `json:"-"`
+	ImportID          *uint             `json:"import_id,omitempty" gorm:"index"`
+	ImageURL          string            `json:"image_url" gorm:"size:500"`
+	ThumbnailURL      string            `json:"thumbnail_url" gorm:"size:500"`
+	Variants          []ProductVariants `json:"variants,omitempty" gorm:"foreignKey:ProductID"`
+	Images            []ProductImages   `json:"images,omitempty" gorm:"foreignKey:ProductID"`
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt    `json:"-" gorm:"index"`
+}
+
+// CreateProductRequest represents the request payload for creating a product
+type CreateProductRequest struct {
+	Name          string `json:"name" validate:"required,max=255"`
+	SKU           string `json:"sku" validate:"required,max=100"`
+	Barcode       string `json:"barcode" validate:"omitempty,max=100"`
+	Description   string `json:"description" validate:"max=5000"`
+	Price         int64  `json:"price" validate:"min=0"`
+	Cost          int64  `json:"cost" validate:"min=0"`
+	CategoryID    *uint  `json:"category_id"`
+	TaxClassID    *uint  `json:"tax_class_id"`
+	Status        string `json:"status" validate:"omitempty,oneof=active inactive archived"`
+	ReorderPoint  *int64 `json:"reorder_point" validate:"omitempty,min=0"`
+	GiftCard      bool   `json:"gift_card"`
+	Tracking      string `json:"tracking" validate:"omitempty,oneof=serial batch"`
+	Unit          string `json:"unit" validate:"omitempty,max=20"`
+	Bundle        bool   `json:"bundle"`
+	BundlePricing string `json:"bundle_pricing" validate:"omitempty,oneof=fixed derived"`
+}
+
+// UpdateProductRequest represents the request payload for updating a product
+type UpdateProductRequest struct {
+	Name          string `json:"name" validate:"required,max=255"`
+	SKU           string `json:"sku" validate:"required,max=100"`
+	Barcode       string `json:"barcode" validate:"omitempty,max=100"`
+	Description   string `json:"description" validate:"max=5000"`
+	Price         int64  `json:"price" validate:"min=0"`
+	Cost          int64  `json:"cost" validate:"min=0"`
+	CategoryID    *uint  `json:"category_id"`
+	TaxClassID    *uint  `json:"tax_class_id"`
+	Status        string `json:"status" validate:"required,oneof=active inactive archived"`
+	ReorderPoint  *int64 `json:"reorder_point" validate:"omitempty,min=0"`
+	GiftCard      bool   `json:"gift_card"`
+	Tracking      string `json:"tracking" validate:"omitempty,oneof=serial batch"`
+	Unit          string `json:"unit" validate:"omitempty,max=20"`
+	Bundle        bool   `json:"bundle"`
+	BundlePricing string `json:"bundle_pricing" validate:"omitempty,oneof=fixed derived"`
+}
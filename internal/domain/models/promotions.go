@@ -0,0 +1,114 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Promotion types
+const (
+	// Value is a rate in basis points (1000 = 10%) off every matching line
+	PromotionTypePercentage = "percentage"
+	// Value is an amount in minor units off the matching lines together
+	PromotionTypeFixed = "fixed"
+)
+
+// Promotion scopes
+const (
+	// Every line of the order
+	PromotionScopeOrder = "order"
+	// Lines of the products in ProductIDs, whichever variant
+	PromotionScopeProducts = "products"
+	// Lines of products in the categories in CategoryIDs or their subcategories
+	PromotionScopeCategories = "categories"
+)
+
+// Promotion stacking rules
+const (
+	// Combines with the other stackable promotions
+	PromotionStackingStackable = "stackable"
+	// Applies on its own, instead of the stackable promotions when it saves more
+	PromotionStackingExclusive = "exclusive"
+)
+
+// Promotions are discounts applied automatically to carts and orders. A promotion applies
+// while it is active and within its time window, when the lines it covers add up to at least
+// MinPurchase; coupon-only promotions apply only to carts a coupon for them is applied to.
+// Stackable promotions apply one after another in order of Priority, highest first, each on
+// what the previous ones left; the customer gets whichever saves the most of those combined
+// or any one exclusive promotion.
+type Promotions struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"not null;size:100"`
+	Description string         `json:"description" gorm:"size:255"`
+	Type        string         `json:"type" gorm:"not null;size:20"`
+	Value       int64          `json:"value" gorm:"not null"`
+	Scope       string         `json:"scope" gorm:"not null;size:20"`
+	ProductIDs  JSONIDList     `json:"product_ids" gorm:"type:jsonb;not null;default:'[]'"`
+	CategoryIDs JSONIDList     `json:"category_ids" gorm:"type:jsonb;not null;default:'[]'"`
+	MinPurchase int64          `json:"min_purchase" gorm:"not null;default:0"`
+	Stacking    string         `json:"stacking" gorm:"not null;default:'stackable';size:20"`
+	Priority    int            `json:"priority" gorm:"not null;default:0"`
+	CouponOnly  bool           `json:"coupon_only" gorm:"not null;default:false"`
+	Active      bool           `json:"active" gorm:"not null;default:true;index"`
+	StartsAt    *time.Time     `json:"starts_at"`
+	EndsAt      *time.Time     `json:"ends_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// CreatePromotionRequest represents the request payload for creating a promotion
+type CreatePromotionRequest struct {
+	Name        string     `json:"name" validate:"required,max=100"`
+	Description string     `json:"description" validate:"max=255"`
+	Type        string     `json:"type" validate:"required,oneof=percentage fixed"`
+	Value       int64      `json:"value" validate:"required,min=1"`
+	Scope       string     `json:"scope" validate:"required,oneof=order products categories"`
+	ProductIDs  []uint     `json:"product_ids" validate:"required_if=Scope products,max=500,dive,min=1"`
+	CategoryIDs []uint     `json:"category_ids" validate:"required_if=Scope categories,max=100,dive,min=1"`
+	MinPurchase int64      `json:"min_purchase" validate:"min=0"`
+	Stacking    string     `json:"stacking" validate:"omitempty,oneof=stackable exclusive"`
+	Priority    int        `json:"priority"`
+	CouponOnly  bool       `json:"coupon_only"`
+	Active      *bool      `json:"active"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+}
+
+// UpdatePromotionRequest represents the request payload for updating a promotion
+type UpdatePromotionRequest struct {
+	Name        string     `json:"name" validate:"required,max=100"`
+	Description string     `json:"description" validate:"max=255"`
+	Type        string     `json:"type" validate:"required,oneof=percentage fixed"`
+	Value       int64      `json:"value" validate:"required,min=1"`
+	Scope       string     `json:"scope" validate:"required,oneof=order products categories"`
+	ProductIDs  []uint     `json:"product_ids" validate:"required_if=Scope products,max=500,dive,min=1"`
+	CategoryIDs []uint     `json:"category_ids" validate:"required_if=Scope categories,max=100,dive,min=1"`
+	MinPurchase int64      `json:"min_purchase" validate:"min=0"`
+	Stacking    string     `json:"stacking" validate:"omitempty,oneof=stackable exclusive"`
+	Priority    int        `json:"priority"`
+	CouponOnly  bool       `json:"coupon_only"`
+	Active      *bool      `json:"active"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+}
+
+// AppliedPromotion is a promotion applied to a cart or order and the discount it gave
+type AppliedPromotion struct {
+	PromotionID uint   `json:"promotion_id"`
+	Name        string `json:"name"`
+	Amount      int64  `json:"amount"`
+}
+
+// OrderPromotions record the promotions an order was placed with, so reports can tell what
+// each promotion gave away even after it changes
+type OrderPromotions struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	OrderID     uint      `json:"order_id" gorm:"not null;index"`
+	PromotionID uint      `json:"promotion_id" gorm:"not null;index"`
+	Name        string    `json:"name" gorm:"not null;size:100"`
+	Amount      int64     `json:"amount" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+}
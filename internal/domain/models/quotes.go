@@ -0,0 +1,136 @@
+package models
+
+import "time"
+
+// Quote statuses. Quotes are drafted, sent to the customer, then accepted or rejected by them,
+// or expire once their expiry date passes while sent. Revising a sent quote makes a new version
+// and takes it back to draft until it is sent again.
+const (
+	QuoteStatusDraft    = "draft"
+	QuoteStatusSent     = "sent"
+	QuoteStatusAccepted = "accepted"
+	QuoteStatusRejected = "rejected"
+	QuoteStatusExpired  = "expired"
+)
+
+// QuoteStatusTransitions lists the statuses each status may move to
+var QuoteStatusTransitions = map[string][]string{
+	QuoteStatusDraft: {QuoteStatusSent},
+	QuoteStatusSent:  {QuoteStatusDraft, QuoteStatusAccepted, QuoteStatusRejected, QuoteStatusExpired},
+}
+
+// Quotes are priced offers to a customer that can later be converted into an order at the
+// quoted prices. Amounts are those of the current version, in minor currency units, and add up
+// like an order's. Email is where the quote is sent, by default the customer's. OrderID is the
+// order the quote was converted into.
+type Quotes struct {
+	ID            uint         `json:"id" gorm:"primaryKey"`
+	Number        string       `json:"number" gorm:"not null;size:30;index"`
+	Version       int          `json:"version" gorm:"not null;default:1"`
+	Status        string       `json:"status" gorm:"not null;default:'draft';size:20;index"`
+	StatusReason  string       `json:"status_reason,omitempty" gorm:"size:255"`
+	CustomerID    *uint        `json:"customer_id,omitempty" gorm:"index"`
+	Email         string       `json:"email" gorm:"size:255"`
+	StoreID       *uint        `json:"store_id,omitempty" gorm:"index"`
+	TaxRegion     string       `json:"tax_region,omitempty" gorm:"size:100"`
+	Note          string       `json:"note" gorm:"type:text"`
+	Terms         string       `json:"terms" gorm:"type:text"`
+	ItemCount     int64        `json:"item_count" gorm:"not null;default:0"`
+	Subtotal      int64        `json:"subtotal" gorm:"not null;default:0"`
+	DiscountTotal int64        `json:"discount_total" gorm:"not null;default:0"`
+	TaxTotal      int64        `json:"tax_total" gorm:"not null;default:0"`
+	IncludedTax   int64        `json:"included_tax" gorm:"not null;default:0"`
+	Total         int64        `json:"total" gorm:"not null;default:0"`
+	ExpiresAt     time.Time    `json:"expires_at" gorm:"not null;index"`
+	OrderID       *uint        `json:"order_id,omitempty" gorm:"index"`
+	Items         []QuoteItems `json:"items,omitempty" gorm:"-"`
+	SentAt        *time.Time   `json:"sent_at,omitempty"`
+	AcceptedAt    *time.Time   `json:"accepted_at,omitempty"`
+	RejectedAt    *time.Time   `json:"rejected_at,omitempty"`
+	ExpiredAt     *time.Time   `json:"expired_at,omitempty"`
+	ConvertedAt   *time.Time   `json:"converted_at,omitempty"`
+	CreatedByID   *uint        `json:"created_by_id,omitempty" gorm:"index"`
+	CreatedAt     time.Time    `json:"created_at" gorm:"index"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// QuoteVersions keep every version of a quote with its lines and totals, so what was sent to
+// the customer can be looked up after the quote is revised. SentAt is when the version was last
+// sent; a version that was never sent is revised in place.
+type QuoteVersions struct {
+	ID            uint         `json:"id" gorm:"primaryKey"`
+	QuoteID       uint         `json:"quote_id" gorm:"not null;uniqueIndex:idx_quote_versions_quote_version"`
+	Version       int          `json:"version" gorm:"not null;uniqueIndex:idx_quote_versions_quote_version"`
+	Note          string       `json:"note" gorm:"type:text"`
+	Terms         string       `json:"terms" gorm:"type:text"`
+	ItemCount     int64        `json:"item_count" gorm:"not null"`
+	Subtotal      int64        `json:"subtotal" gorm:"not null"`
+	DiscountTotal int64        `json:"discount_total" gorm:"not null"`
+	TaxTotal      int64        `json:"tax_total" gorm:"not null"`
+	IncludedTax   int64        `json:"included_tax" gorm:"not null"`
+	Total         int64        `json:"total" gorm:"not null"`
+	ExpiresAt     time.Time    `json:"expires_at" gorm:"not null"`
+	Items         []QuoteItems `json:"items,omitempty" gorm:"-"`
+	SentAt        *time.Time   `json:"sent_at,omitempty"`
+	CreatedByID   *uint        `json:"created_by_id,omitempty" gorm:"index"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+// QuoteItems are the lines of a version of a quote, priced like order lines. The quoted unit
+// price and discount are what the order the quote is converted into charges.
+type QuoteItems struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	QuoteID      uint      `json:"quote_id" gorm:"not null;index:idx_quote_items_quote_version"`
+	Version      int       `json:"version" gorm:"not null;index:idx_quote_items_quote_version"`
+	ProductID    uint      `json:"product_id" gorm:"not null;index"`
+	VariantID    *uint     `json:"variant_id" gorm:"index"`
+	Name         string    `json:"name" gorm:"not null;size:255"`
+	VariantTitle string    `json:"variant_title,omitempty" gorm:"size:255"`
+	SKU          string    `json:"sku" gorm:"not null;size:100"`
+	Quantity     int64     `json:"quantity" gorm:"not null"`
+	UnitPrice    int64     `json:"unit_price" gorm:"not null"`
+	Subtotal     int64     `json:"subtotal" gorm:"not null"`
+	Discount     int64     `json:"discount" gorm:"not null;default:0"`
+	TaxRate      int64     `json:"tax_rate" gorm:"not null;default:0"`
+	Tax          int64     `json:"tax" gorm:"not null;default:0"`
+	Taxes        LineTaxes `json:"taxes" gorm:"type:jsonb;not null;default:'[]'"`
+	Total        int64     `json:"total" gorm:"not null"`
+}
+
+// QuoteRequest represents the request payload for drafting or revising a quote. Lines are
+// priced like order lines. The quote expires at ExpiresAt, or ValidDays after it is saved.
+type QuoteRequest struct {
+	CustomerID *uint              `json:"customer_id"`
+	Email      string             `json:"email" validate:"omitempty,email,max=255"`
+	Region     string             `json:"region" validate:"max=100"`
+	Note       string             `json:"note" validate:"max=2000"`
+	Terms      string             `json:"terms" validate:"max=2000"`
+	ExpiresAt  *time.Time         `json:"expires_at"`
+	ValidDays  *int               `json:"valid_days" validate:"omitempty,min=1,max=365"`
+	Items      []OrderItemRequest `json:"items" validate:"required,min=1,dive"`
+	StoreID    *uint              `json:"-"`
+}
+
+// SendQuoteRequest represents the request payload for emailing a quote. Email defaults to the
+// quote's; Location picks the receipt template whose currency the quote is shown in.
+type SendQuoteRequest struct {
+	Email    string `json:"email" validate:"omitempty,email,max=255"`
+	Location string `json:"location" validate:"max=100"`
+}
+
+// QuoteStatusRequest represents the request payload for recording the customer's answer
+type QuoteStatusRequest struct {
+	Reason string `json:"reason" validate:"max=255"`
+}
+
+// ConvertQuoteRequest represents the request payload for converting an accepted quote into an
+// order. Draft saves the order without taking stock, to be placed later.
+type ConvertQuoteRequest struct {
+	Draft bool `json:"draft"`
+}
+
+// QuotePDFQuery selects the location whose receipt template sets the currency of a quote PDF,
+// when the request doesn't come from a terminal
+type QuotePDFQuery struct {
+	Location string `form:"location" validate:"max=100"`
+}
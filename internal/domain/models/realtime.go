@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// Topics WebSocket clients subscribe to
+const (
+	RealtimeTopicOrders = "orders"
+	RealtimeTopicStock  = "stock"
+	RealtimeTopicUsers  = "users"
+	// Print jobs being queued, printed or cancelled; terminals only get their own
+	RealtimeTopicPrintJobs = "print_jobs"
+	// A terminal's configuration changing; only terminals may follow it, and only their own
+	RealtimeTopicTerminalConfig = "terminal_config"
+)
+
+// Domain events pushed to WebSocket clients
+const (
+	RealtimeOrderCreated = "order.created"
+	RealtimeStockChanged = "stock.changed"
+	RealtimeUserUpdated  = "user.updated"
+	// The data of print job events is the job; terminals claim queued jobs as usual
+	RealtimePrintJobUpdated = "print_job.updated"
+	// The data of terminal config events is the device ID and the ETag of the new configuration
+	RealtimeTerminalConfigUpdated = "terminal_config.updated"
+)
+
+// Messages WebSocket clients send
+const (
+	RealtimeSubscribe   = "subscribe"
+	RealtimeUnsubscribe = "unsubscribe"
+	RealtimePing        = "ping"
+)
+
+// Messages the server sends besides events
+const (
+	RealtimeSubscribed   = "subscribed"
+	RealtimeUnsubscribed = "unsubscribed"
+	RealtimePong         = "pong"
+	RealtimeError        = "error"
+)
+
+// RealtimeEvent is a domain event pushed to the WebSocket clients subscribed to its topic.
+// Events only carry what changed; clients refetch whatever else they need.
+type RealtimeEvent struct {
+	Type       string      `json:"type"`
+	Topic      string      `json:"topic"`
+	Data       interface{} `json:"data"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	// Store is the store the event happened in, 0 for store data outside any store. Clients
+	// limited to some stores only get the events of those stores. Events about no store, such
+	// as user updates, have none.
+	Store *uint `json:"-"`
+	// Device addresses the event to one terminal. Terminals only get the events addressed to
+	// them.
+	Device *uint `json:"-"`
+}
+
+// RealtimeClientMessage is a message sent by a WebSocket client, e.g.
+// {"type": "subscribe", "topics": ["orders", "stock"]}
+type RealtimeClientMessage struct {
+	Type   string   `json:"type"`
+	Topics []string `json:"topics,omitempty"`
+}
+
+// RealtimeServerMessage answers a client message. Errors carry a code and message and leave
+// the connection open.
+type RealtimeServerMessage struct {
+	Type    string   `json:"type"`
+	Topics  []string `json:"topics,omitempty"`
+	Code    string   `json:"code,omitempty"`
+	Message string   `json:"message,omitempty"`
+}
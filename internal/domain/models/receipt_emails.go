@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Receipt email statuses
+const (
+	ReceiptEmailStatusPending = "pending"
+	ReceiptEmailStatusSent    = "sent"
+	// Given up after ReceiptEmailMaxAttempts; LastError says why
+	ReceiptEmailStatusFailed = "failed"
+)
+
+// ReceiptEmailMaxAttempts is how often sending a receipt email is tried before it fails
+const ReceiptEmailMaxAttempts = 5
+
+// ReceiptEmails are receipts emailed to customers. They are queued when requested and sent in
+// the background, so a mail server outage delays receipts instead of losing them. TenantID and
+// Location pick the receipt template, as they would for a printed receipt.
+type ReceiptEmails struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	OrderID       uint       `json:"order_id" gorm:"not null;index"`
+	Email         string     `json:"email" gorm:"not null;size:255"`
+	TenantID      uint       `json:"-" gorm:"not null;default:0"`
+	Location      string     `json:"-" gorm:"size:100"`
+	Status        string     `json:"status" gorm:"not null;size:20;index"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	LastError     string     `json:"last_error,omitempty" gorm:"size:255"`
+	RetryAt       *time.Time `json:"retry_at,omitempty"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	RequestedByID *uint      `json:"requested_by_id,omitempty" gorm:"index"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// EmailReceiptRequest represents the request payload for emailing an order's receipt. Email
+// defaults to the email of the order's customer.
+type EmailReceiptRequest struct {
+	Email    string `json:"email" validate:"omitempty,email,max=255"`
+	Location string `json:"location" validate:"max=100"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReportDefinitions struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"unique;not null;size:100"`
+	Description string         `json:"description" gorm:"size:255"`
+	Entity      string         `json:"entity" gorm:"not null;size:50"`
+	Dimensions  JSONStringList `json:"dimensions" gorm:"type:jsonb;not null;default:'[]'"`
+	Measures    JSONStringList `json:"measures" gorm:"type:jsonb;not null;default:'[]'"`
+	Filters     JSONMap        `json:"filters" gorm:"type:jsonb;not null;default:'{}'"`
+	CreatedByID *uint          `json:"created_by_id,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// ReportDefinitionRequest represents the request payload for creating or updating a report definition
+type ReportDefinitionRequest struct {
+	Name        string                 `json:"name" validate:"required,max=100"`
+	Description string                 `json:"description" validate:"max=255"`
+	Entity      string                 `json:"entity" validate:"required"`
+	Dimensions  []string               `json:"dimensions" validate:"required,min=1,max=5"`
+	Measures    []string               `json:"measures" validate:"required,min=1,max=10"`
+	Filters     map[string]interface{} `json:"filters"`
+}
+
+// ReportEntityResponse describes what a report can be built from
+type ReportEntityResponse struct {
+	Entity     string   `json:"entity"`
+	Dimensions []string `json:"dimensions"`
+	Measures   []string `json:"measures"`
+	Filters    []string `json:"filters"`
+	DateField  string   `json:"date_field"`
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Report file formats
+const (
+	ReportFormatCSV = "csv"
+	ReportFormatPDF = "pdf"
+)
+
+// How scheduled reports are delivered
+const (
+	// Attached to an email to each recipient
+	ReportDeliveryEmail = "email"
+	// POSTed as the request body to the webhook URL
+	ReportDeliveryWebhook = "webhook"
+)
+
+// Periods a scheduled report covers, ending when the run was due. An empty period covers every row.
+const (
+	ReportPeriodDay   = "day"
+	ReportPeriodWeek  = "week"
+	ReportPeriodMonth = "month"
+)
+
+// Report run statuses
+const (
+	ReportRunStatusSucceeded = "succeeded"
+	ReportRunStatusFailed    = "failed"
+)
+
+// What started a report run
+const (
+	ReportRunTriggerSchedule = "schedule"
+	ReportRunTriggerManual   = "manual"
+)
+
+// ReportSchedules run a report definition on a cron expression and deliver the rows as a file.
+// NextRunAt is when the schedule is next due; inactive schedules have none.
+type ReportSchedules struct {
+	ID         uint               `json:"id" gorm:"primaryKey"`
+	ReportID   uint               `json:"report_id" gorm:"not null;index"`
+	Report     *ReportDefinitions `json:"report,omitempty" gorm:"foreignKey:ReportID"`
+	Name       string             `json:"name" gorm:"not null;size:100"`
+	Cron       string             `json:"cron" gorm:"not null;size:100"`
+	Timezone   string             `json:"timezone" gorm:"not null;size:64;default:'UTC'"`
+	Period     string             `json:"period" gorm:"size:10"`
+	Format     string             `json:"format" gorm:"not null;size:10"`
+	Delivery   string             `json:"delivery" gorm:"not null;size:10"`
+	Recipients JSONStringList     `json:"recipients" gorm:"type:jsonb;not null;default:'[]'"`
+	WebhookURL string             `json:"webhook_url,omitempty" gorm:"size:500"`
+	// Signs webhook deliveries; never returned
+	WebhookSecret       string         `json:"-" gorm:"size:255"`
+	IsActive            bool           `json:"is_active" gorm:"not null;default:true"`
+	NextRunAt           *time.Time     `json:"next_run_at,omitempty" gorm:"index"`
+	LastRunAt           *time.Time     `json:"last_run_at,omitempty"`
+	LastStatus          string         `json:"last_status,omitempty" gorm:"size:20"`
+	ConsecutiveFailures int            `json:"consecutive_failures" gorm:"not null;default:0"`
+	CreatedByID         *uint          `json:"created_by_id,omitempty" gorm:"index"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// ReportScheduleRuns is the history of a schedule: one row per run, whether it was delivered
+type ReportScheduleRuns struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	ScheduleID    uint       `json:"schedule_id" gorm:"not null;index"`
+	ReportID      uint       `json:"report_id" gorm:"not null"`
+	Trigger       string     `json:"trigger" gorm:"not null;size:20"`
+	Status        string     `json:"status" gorm:"not null;size:20;index"`
+	Format        string     `json:"format" gorm:"not null;size:10"`
+	Delivery      string     `json:"delivery" gorm:"not null;size:10"`
+	Rows          int        `json:"rows" gorm:"not null;default:0"`
+	Bytes         int        `json:"bytes" gorm:"not null;default:0"`
+	Error         string     `json:"error,omitempty" gorm:"size:500"`
+	PeriodStart   *time.Time `json:"period_start,omitempty"`
+	PeriodEnd     *time.Time `json:"period_end,omitempty"`
+	ScheduledFor  time.Time  `json:"scheduled_for"`
+	StartedAt     time.Time  `json:"started_at"`
+	FinishedAt    time.Time  `json:"finished_at"`
+	TriggeredByID *uint      `json:"triggered_by_id,omitempty"`
+}
+
+// ReportScheduleRequest represents the request payload for creating or updating a report
+// schedule. Email deliveries need recipients and webhook deliveries a URL.
+type ReportScheduleRequest struct {
+	ReportID      uint     `json:"report_id" validate:"required"`
+	Name          string   `json:"name" validate:"required,max=100"`
+	Cron          string   `json:"cron" validate:"required,max=100"`
+	Timezone      string   `json:"timezone" validate:"max=64"`
+	Period        string   `json:"period" validate:"omitempty,oneof=day week month"`
+	Format        string   `json:"format" validate:"required,oneof=csv pdf"`
+	Delivery      string   `json:"delivery" validate:"required,oneof=email webhook"`
+	Recipients    []string `json:"recipients" validate:"required_if=Delivery email,max=20,dive,email,max=255"`
+	WebhookURL    string   `json:"webhook_url" validate:"required_if=Delivery webhook,omitempty,url,max=500"`
+	WebhookSecret string   `json:"webhook_secret" validate:"max=255"`
+	IsActive      *bool    `json:"is_active"`
+}
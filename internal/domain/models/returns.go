@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// OrderReturns are refunds of a paid order, in full or for some of its lines. The amount is paid
+// back through the payments the order was paid with, and returned goods are optionally put back
+// into stock. Number is derived from the ID like order numbers. Amounts are in minor currency
+// units.
+type OrderReturns struct {
+	ID          uint                 `json:"id" gorm:"primaryKey"`
+	OrderID     uint                 `json:"order_id" gorm:"not null;index"`
+	Number      string               `json:"number" gorm:"not null;size:30;index"`
+	Reason      string               `json:"reason" gorm:"not null;size:255"`
+	ItemCount   int64                `json:"item_count" gorm:"not null;default:0"`
+	Amount      int64                `json:"amount" gorm:"not null;default:0"`
+	CreatedByID *uint                `json:"created_by_id,omitempty" gorm:"index"`
+	Items       []OrderReturnItems   `json:"items,omitempty" gorm:"foreignKey:ReturnID"`
+	Refunds     []OrderReturnRefunds `json:"refunds,omitempty" gorm:"foreignKey:ReturnID"`
+	CreatedAt   time.Time            `json:"created_at" gorm:"index"`
+}
+
+// OrderReturnItems are the lines of a return. Amount is the share of the order line's total,
+// discount and tax included, that the returned quantity is worth.
+type OrderReturnItems struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	ReturnID    uint           `json:"return_id" gorm:"not null;index"`
+	OrderItemID uint           `json:"order_item_id" gorm:"not null;index"`
+	ProductID   uint           `json:"product_id" gorm:"not null;index"`
+	VariantID   *uint          `json:"variant_id" gorm:"index"`
+	Quantity    int64          `json:"quantity" gorm:"not null"`
+	Amount      int64          `json:"amount" gorm:"not null"`
+	Restocked   bool           `json:"restocked" gorm:"not null;default:false"`
+	Serials     JSONStringList `json:"serials,omitempty" gorm:"type:jsonb;not null;default:'[]'"`
+	CreatedAt   time.Time      `json:"created_at" gorm:"index"`
+}
+
+// OrderReturnRefunds record which payments a return was paid back through
+type OrderReturnRefunds struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ReturnID  uint      `json:"return_id" gorm:"not null;index"`
+	PaymentID uint      `json:"payment_id" gorm:"not null;index"`
+	Method    string    `json:"method" gorm:"not null;size:30"`
+	Amount    int64     `json:"amount" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RefundOrderRequest represents the request payload for refunding an order. Without items every
+// line is refunded for what is left of it. Restock puts returned goods back into stock and
+// defaults to true; lines can override it.
+type RefundOrderRequest struct {
+	Items   []RefundItemRequest `json:"items" validate:"omitempty,dive"`
+	Restock *bool               `json:"restock"`
+	Reason  string              `json:"reason" validate:"required,max=255"`
+}
+
+// RefundItemRequest is one returned line of an order. Lines of serialized products list the
+// serials returned.
+type RefundItemRequest struct {
+	OrderItemID uint     `json:"order_item_id" validate:"required"`
+	Quantity    int64    `json:"quantity" validate:"required,min=1"`
+	Restock     *bool    `json:"restock"`
+	Serials     []string `json:"serials" validate:"omitempty,max=1000,dive,required,max=100"`
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Setting value types
+const (
+	SettingTypeString = "string"
+	SettingTypeInt    = "int"
+	SettingTypeNumber = "number"
+	SettingTypeBool   = "bool"
+	SettingTypeJSON   = "json"
+)
+
+// Setting scopes, from the broadest to the most specific. A location setting overrides
+// the tenant's, which overrides the global one.
+const (
+	SettingScopeGlobal   = "global"
+	SettingScopeTenant   = "tenant"
+	SettingScopeLocation = "location"
+)
+
+// Setting change actions recorded in the history
+const (
+	SettingActionCreated  = "created"
+	SettingActionUpdated  = "updated"
+	SettingActionDeleted  = "deleted"
+	SettingActionRestored = "restored"
+)
+
+// Settings holds a typed business setting for one scope. ScopeID is empty for global
+// settings, the tenant ID for tenant settings, and the location name for location settings.
+type Settings struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Key         string         `json:"key" gorm:"not null;size:100;uniqueIndex:idx_settings_key_scope"`
+	Type        string         `json:"type" gorm:"not null;size:20"`
+	Scope       string         `json:"scope" gorm:"not null;size:20;uniqueIndex:idx_settings_key_scope"`
+	ScopeID     string         `json:"scope_id" gorm:"not null;default:'';size:100;uniqueIndex:idx_settings_key_scope"`
+	Value       JSONValue      `json:"value" gorm:"type:jsonb;not null"`
+	Description string         `json:"description" gorm:"size:255"`
+	Version     int            `json:"version" gorm:"not null;default:1"`
+	UpdatedByID *uint          `json:"updated_by_id,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// SettingChanges records every version of a setting together with who made the change
+type SettingChanges struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	SettingID uint       `json:"setting_id" gorm:"not null;index:idx_setting_changes_setting_created"`
+	Action    string     `json:"action" gorm:"not null;size:20"`
+	Version   int        `json:"version" gorm:"not null"`
+	OldValue  *JSONValue `json:"old_value" gorm:"type:jsonb"`
+	NewValue  *JSONValue `json:"new_value" gorm:"type:jsonb"`
+	ActorID   *uint      `json:"actor_id" gorm:"index"`
+	CreatedAt time.Time  `json:"created_at" gorm:"index:idx_setting_changes_setting_created"`
+}
+
+// CreateSettingRequest represents the request payload for creating a setting
+type CreateSettingRequest struct {
+	Key         string    `json:"key" validate:"required,max=100"`
+	Type        string    `json:"type" validate:"required,oneof=string int number bool json"`
+	Scope       string    `json:"scope" validate:"required,oneof=global tenant location"`
+	ScopeID     string    `json:"scope_id" validate:"required_unless=Scope global,max=100"`
+	Value       JSONValue `json:"value"`
+	Description string    `json:"description" validate:"max=255"`
+}
+
+// UpdateSettingRequest represents the request payload for changing a setting's value
+type UpdateSettingRequest struct {
+	Value       JSONValue `json:"value"`
+	Description string    `json:"description" validate:"max=255"`
+	// Version must match the stored version when set, so concurrent edits are not lost
+	Version int `json:"version" validate:"min=0"`
+}
+
+// EffectiveSettingQuery selects the tenant and location a setting is resolved for.
+// Without a tenant ID the tenant serving the request is used.
+type EffectiveSettingQuery struct {
+	TenantID uint   `form:"tenant_id"`
+	Location string `form:"location" validate:"max=100"`
+}
+
+// EffectiveSetting is the value of a setting after scope overrides are applied
+type EffectiveSetting struct {
+	Key     string    `json:"key"`
+	Type    string    `json:"type"`
+	Value   JSONValue `json:"value"`
+	Scope   string    `json:"scope"`
+	ScopeID string    `json:"scope_id"`
+	Version int       `json:"version"`
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Shift statuses
+const (
+	ShiftStatusOpen   = "open"
+	ShiftStatusClosed = "closed"
+)
+
+// Shifts are a cashier's time on a terminal, from opening the register to closing it. A
+// terminal and a user each have at most one open shift, and terminals only record sales
+// inside one. Opening a shift opens the cashier's cash drawer with the float; closing it
+// closes the drawer with the cash counted and compares what was counted for every payment
+// method with what the shift took. Location and StoreID are those of the terminal. Amounts are
+// in minor currency units.
+type Shifts struct {
+	ID                  uint        `json:"id" gorm:"primaryKey"`
+	UserID              *uint       `json:"user_id,omitempty" gorm:"index;uniqueIndex:idx_shifts_open_user,where:status = 'open'"`
+	DeviceID            uint        `json:"device_id" gorm:"not null;index;uniqueIndex:idx_shifts_open_device,where:status = 'open'"`
+	Location            string      `json:"location" gorm:"not null;size:100;index"`
+	StoreID             *uint       `json:"store_id,omitempty" gorm:"index"`
+	Status              string      `json:"status" gorm:"not null;size:20;index"`
+	OpeningFloat        int64       `json:"opening_float" gorm:"not null;default:0"`
+	CashDrawerSessionID *uint       `json:"cash_drawer_session_id,omitempty" gorm:"index"`
+	Totals              ShiftTotals `json:"totals" gorm:"type:jsonb;not null;default:'[]'"`
+	OpeningNote         string      `json:"opening_note,omitempty" gorm:"size:255"`
+	ClosingNote         string      `json:"closing_note,omitempty" gorm:"size:255"`
+	ClosedByID          *uint       `json:"closed_by_id,omitempty" gorm:"index"`
+	OpenedAt            time.Time   `json:"opened_at" gorm:"index"`
+	ClosedAt            *time.Time  `json:"closed_at,omitempty"`
+	CreatedAt           time.Time   `json:"created_at"`
+	UpdatedAt           time.Time   `json:"updated_at"`
+}
+
+// ShiftTotal is what a shift took with one payment method. Expected for cash is what the
+// drawer should hold, float included; for other methods it is what their payments add up to,
+// minus refunds. Counted and Variance (counted minus expected) are set when the shift closes.
+type ShiftTotal struct {
+	Method   string `json:"method"`
+	Expected int64  `json:"expected"`
+	Counted  *int64 `json:"counted"`
+	Variance *int64 `json:"variance"`
+}
+
+// ShiftTotals are the totals of a shift per payment method stored in a JSONB column
+type ShiftTotals []ShiftTotal
+
+// Value implements driver.Valuer
+func (t ShiftTotals) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (t *ShiftTotals) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*t = ShiftTotals{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for ShiftTotals: %T", value)
+	}
+
+	result := ShiftTotals{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*t = result
+	return nil
+}
+
+// OpenShiftRequest represents the request payload for opening a shift. DeviceID is only needed
+// when the request doesn't come from the terminal itself.
+type OpenShiftRequest struct {
+	DeviceID     *uint  `json:"device_id" validate:"omitempty,min=1"`
+	OpeningFloat int64  `json:"opening_float" validate:"min=0"`
+	Note         string `json:"note" validate:"max=255"`
+}
+
+// CloseShiftRequest represents the request payload for closing a shift with what was counted
+// per payment method, e.g. the cash in the drawer and the card terminal's batch total. Cash
+// must be counted.
+type CloseShiftRequest struct {
+	Counted map[string]int64 `json:"counted" validate:"required,dive,keys,max=30,endkeys,min=0"`
+	Note    string           `json:"note" validate:"max=255"`
+}
+
+// ShiftReport sums up a shift: the orders it rang up and its totals per payment method, live
+// while it is open
+type ShiftReport struct {
+	Shift      Shifts            `json:"shift"`
+	OrderCount int64             `json:"order_count"`
+	Sales      int64             `json:"sales"`
+	Totals     ShiftTotals       `json:"totals"`
+	Drawer     *CashDrawerReport `json:"drawer,omitempty"`
+}
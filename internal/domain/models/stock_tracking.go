@@ -0,0 +1,172 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Product tracking modes. Serialized products are stocked unit by unit under serial numbers;
+// batch-tracked products by batch or lot. Untracked products leave Tracking empty.
+const (
+	ProductTrackingSerial = "serial"
+	ProductTrackingBatch  = "batch"
+)
+
+// How sales of batches past their expiry date are handled: refused, or sold and flagged on the
+// batch allocation of the line
+const (
+	ExpiredStockBlock = "block"
+	ExpiredStockWarn  = "warn"
+)
+
+// Serial number statuses. Serials are in stock at a store until they are sold, or written off
+// by an adjustment; sold serials come back into stock when they are returned.
+const (
+	SerialStatusInStock = "in_stock"
+	SerialStatusSold    = "sold"
+	SerialStatusRemoved = "removed"
+)
+
+// StockSerials are the units of serialized products. Serials are unique per product and keep
+// their row for life: StoreID is where the unit is or was last in stock, and OrderID the order
+// it was last sold with.
+type StockSerials struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	ProductID   uint       `json:"product_id" gorm:"not null;uniqueIndex:idx_stock_serials_product_serial"`
+	VariantID   *uint      `json:"variant_id" gorm:"index"`
+	Serial      string     `json:"serial" gorm:"not null;size:100;uniqueIndex:idx_stock_serials_product_serial;index"`
+	StoreID     *uint      `json:"store_id" gorm:"index"`
+	Status      string     `json:"status" gorm:"not null;size:20;index"`
+	OrderID     *uint      `json:"order_id,omitempty" gorm:"index"`
+	ReceivedAt  time.Time  `json:"received_at"`
+	SoldAt      *time.Time `json:"sold_at,omitempty"`
+	LastMovedAt time.Time  `json:"last_moved_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// StockBatches hold the quantity on hand of a batch or lot of a product, or one of its variants,
+// at a store. Like stock levels they only change through stock movements, which may take them
+// below zero. Batches of perishable goods expire at ExpiresAt; sales take stock from the batches
+// that expire first.
+type StockBatches struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	ProductID  uint       `json:"product_id" gorm:"not null;index"`
+	VariantID  *uint      `json:"variant_id" gorm:"index"`
+	StoreID    *uint      `json:"store_id" gorm:"index"`
+	Batch      string     `json:"batch" gorm:"not null;size:100;index"`
+	Quantity   int64      `json:"quantity" gorm:"not null;default:0"`
+	ExpiresAt  *time.Time `json:"expires_at" gorm:"index"`
+	ReceivedAt time.Time  `json:"received_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// BatchAllocation is the quantity of an order line taken from one batch. Returned counts the
+// units of it returned since, and Expired flags stock sold past its expiry date.
+type BatchAllocation struct {
+	Batch    string `json:"batch"`
+	Quantity int64  `json:"quantity"`
+	Returned int64  `json:"returned"`
+	Expired  bool   `json:"expired,omitempty"`
+}
+
+// BatchAllocations are the batches an order line was taken from, stored in a JSONB column
+type BatchAllocations []BatchAllocation
+
+// Value implements driver.Valuer
+func (a BatchAllocations) Value() (driver.Value, error) {
+	if a == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (a *BatchAllocations) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*a = BatchAllocations{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for BatchAllocations: %T", value)
+	}
+
+	result := BatchAllocations{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*a = result
+	return nil
+}
+
+// StockReceiptItem is one line of goods received. Serialized products list a serial per unit
+// and batch-tracked products name the batch, with its expiry date if it is perishable. Unit is
+// the unit of measure Quantity is counted in, e.g. cases, and defaults to the product's stock
+// unit; serials are listed per stock unit.
+type StockReceiptItem struct {
+	ProductID uint       `json:"product_id" validate:"required"`
+	VariantID *uint      `json:"variant_id"`
+	Quantity  int64      `json:"quantity" validate:"required,min=1"`
+	Unit      string     `json:"unit" validate:"max=20"`
+	Serials   []string   `json:"serials" validate:"omitempty,max=1000,dive,required,max=100"`
+	Batch     string     `json:"batch" validate:"max=100"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// StockReceiptRequest represents the request payload for receiving goods into stock, e.g. a
+// supplier delivery. StoreID is set by the server to the store the request works in, which
+// receives the goods.
+type StockReceiptRequest struct {
+	Reference string             `json:"reference" validate:"max=100"`
+	Note      string             `json:"note" validate:"max=255"`
+	Items     []StockReceiptItem `json:"items" validate:"required,min=1,dive"`
+	StoreID   *uint              `json:"-"`
+}
+
+// ExpiringStockQuery sets how many days ahead the expiring stock report looks; it defaults to 30
+type ExpiringStockQuery struct {
+	Days int `form:"days" validate:"omitempty,min=1,max=365"`
+}
+
+// BatchRecallQuery selects the batch of a product a recall report is for
+type BatchRecallQuery struct {
+	ProductID uint   `form:"product_id" validate:"required"`
+	Batch     string `form:"batch" validate:"required,max=100"`
+}
+
+// BatchRecallSale is an order that sold units of a recalled batch, with the customer to
+// contact. Quantity leaves out units already returned.
+type BatchRecallSale struct {
+	OrderID       uint       `json:"order_id"`
+	Number        string     `json:"number"`
+	Status        string     `json:"status"`
+	StoreID       *uint      `json:"store_id"`
+	PlacedAt      *time.Time `json:"placed_at"`
+	Quantity      int64      `json:"quantity"`
+	CustomerID    *uint      `json:"customer_id"`
+	CustomerName  string     `json:"customer_name,omitempty"`
+	CustomerEmail string     `json:"customer_email,omitempty"`
+	CustomerPhone string     `json:"customer_phone,omitempty"`
+}
+
+// BatchRecall reports where a batch of a product went: what is still on hand per store and
+// which orders sold it
+type BatchRecall struct {
+	ProductID uint              `json:"product_id"`
+	Batch     string            `json:"batch"`
+	OnHand    int64             `json:"on_hand"`
+	Sold      int64             `json:"sold"`
+	Stock     []StockBatches    `json:"stock"`
+	Sales     []BatchRecallSale `json:"sales"`
+}
@@ -0,0 +1,103 @@
+package models
+
+import "time"
+
+// Stocktake statuses. Stocktakes are counted while open and then either approved, which posts
+// the differences as adjustments, or cancelled.
+const (
+	StocktakeStatusOpen      = "open"
+	StocktakeStatusApproved  = "approved"
+	StocktakeStatusCancelled = "cancelled"
+)
+
+// Stocktakes are inventory count sessions of a store. Staff record counted quantities while the
+// stocktake is open; approving it adjusts the stock of every counted line to its count. Lines
+// that were not counted are left alone, so a stocktake can cover part of a store. Number is
+// derived from the ID like order numbers.
+type Stocktakes struct {
+	ID            uint             `json:"id" gorm:"primaryKey"`
+	Number        string           `json:"number" gorm:"not null;size:30;index"`
+	StoreID       *uint            `json:"store_id" gorm:"index"`
+	Status        string           `json:"status" gorm:"not null;default:'open';size:20;index"`
+	Note          string           `json:"note" gorm:"size:255"`
+	CreatedByID   *uint            `json:"created_by_id,omitempty" gorm:"index"`
+	ApprovedByID  *uint            `json:"approved_by_id,omitempty" gorm:"index"`
+	CancelledByID *uint            `json:"cancelled_by_id,omitempty" gorm:"index"`
+	ApprovedAt    *time.Time       `json:"approved_at,omitempty"`
+	CancelledAt   *time.Time       `json:"cancelled_at,omitempty"`
+	Items         []StocktakeItems `json:"items,omitempty" gorm:"foreignKey:StocktakeID"`
+	CreatedAt     time.Time        `json:"created_at" gorm:"index"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+// StocktakeItems are the counted lines of a stocktake. ExpectedQuantity is the system stock
+// the count was compared with when the stocktake was approved.
+type StocktakeItems struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	StocktakeID      uint      `json:"stocktake_id" gorm:"not null;index"`
+	ProductID        uint      `json:"product_id" gorm:"not null;index"`
+	VariantID        *uint     `json:"variant_id" gorm:"index"`
+	CountedQuantity  int64     `json:"counted_quantity" gorm:"not null;default:0"`
+	ExpectedQuantity *int64    `json:"expected_quantity,omitempty"`
+	CountedByID      *uint     `json:"counted_by_id,omitempty" gorm:"index"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// CreateStocktakeRequest represents the request payload for opening a stocktake. StoreID is set
+// by the server to the store the request works in.
+type CreateStocktakeRequest struct {
+	Note    string `json:"note" validate:"max=255"`
+	StoreID *uint  `json:"-"`
+}
+
+// StocktakeCountItem is a counted quantity of a product or variant
+type StocktakeCountItem struct {
+	ProductID uint  `json:"product_id" validate:"required"`
+	VariantID *uint `json:"variant_id"`
+	Quantity  int64 `json:"quantity" validate:"min=0"`
+}
+
+// StocktakeCountRequest represents the request payload for recording counts. Each count
+// replaces the quantity counted so far for its product or variant.
+type StocktakeCountRequest struct {
+	Items []StocktakeCountItem `json:"items" validate:"required,min=1,dive"`
+}
+
+// StocktakeScanRequest represents a batch of barcode scans; every scan counts one unit
+type StocktakeScanRequest struct {
+	Barcodes []string `json:"barcodes" validate:"required,min=1,dive,required,max=100"`
+}
+
+// StocktakeScanResult reports how many scans of a batch were counted and which barcodes did
+// not resolve to a countable product or variant
+type StocktakeScanResult struct {
+	Counted   int              `json:"counted"`
+	Unmatched []string         `json:"unmatched"`
+	Items     []StocktakeItems `json:"items"`
+}
+
+// StocktakeReportLine compares a counted line with the system stock. Difference is the
+// adjustment approving the stocktake posts.
+type StocktakeReportLine struct {
+	ProductID    uint    `json:"product_id"`
+	VariantID    *uint   `json:"variant_id"`
+	ProductName  string  `json:"product_name"`
+	VariantTitle *string `json:"variant_title"`
+	SKU          string  `json:"sku"`
+	Counted      int64   `json:"counted"`
+	Expected     int64   `json:"expected"`
+	Difference   int64   `json:"difference"`
+}
+
+// StocktakeReport is the difference report of a stocktake. Open stocktakes are compared with
+// the current stock, approved ones with the stock they were approved against.
+type StocktakeReport struct {
+	StocktakeID uint                  `json:"stocktake_id"`
+	Number      string                `json:"number"`
+	Status      string                `json:"status"`
+	Lines       []StocktakeReportLine `json:"lines"`
+	Counted     int                   `json:"counted"`
+	Mismatched  int                   `json:"mismatched"`
+	Surplus     int64                 `json:"surplus"`
+	Shortage    int64                 `json:"shortage"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Storage quota scopes
+const (
+	StorageScopeTenant = "tenant"
+	StorageScopeStore  = "store"
+)
+
+// StorageOwner is who an uploaded file is charged to: the tenant of the request's host and the
+// store it was made in. Zero IDs stand for requests without a tenant or store.
+type StorageOwner struct {
+	TenantID uint
+	StoreID  uint
+}
+
+// StorageUsages counts the bytes and files kept in storage for a tenant in a store. It is updated
+// in the transaction that records or removes the files, so it never drifts from them.
+type StorageUsages struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	TenantID  uint      `json:"tenant_id" gorm:"not null;default:0;uniqueIndex:idx_storage_usages_tenant_store"`
+	StoreID   uint      `json:"store_id" gorm:"not null;default:0;uniqueIndex:idx_storage_usages_tenant_store"`
+	Bytes     int64     `json:"bytes" gorm:"not null;default:0"`
+	Files     int64     `json:"files" gorm:"not null;default:0"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StorageQuotas are the bytes a tenant, and each of its stores, may keep in storage; 0 means
+// unlimited
+type StorageQuotas struct {
+	TenantBytes int64 `json:"tenant_bytes"`
+	StoreBytes  int64 `json:"store_bytes"`
+}
+
+// StorageStoreUsage is a tenant's usage in one of its stores; store 0 holds files uploaded
+// outside any store
+type StorageStoreUsage struct {
+	StoreID   uint   `json:"store_id"`
+	StoreName string `json:"store_name,omitempty"`
+	Bytes     int64  `json:"bytes"`
+	Files     int64  `json:"files"`
+}
+
+// StorageTenantUsage is a tenant's usage across its stores; tenant 0 holds files uploaded on
+// hosts without a tenant
+type StorageTenantUsage struct {
+	TenantID   uint                `json:"tenant_id"`
+	TenantName string              `json:"tenant_name,omitempty"`
+	Bytes      int64               `json:"bytes"`
+	Files      int64               `json:"files"`
+	Stores     []StorageStoreUsage `json:"stores"`
+}
+
+// StorageUsageReport is the storage usage of every tenant against the configured quotas
+type StorageUsageReport struct {
+	Quotas  StorageQuotas        `json:"quotas"`
+	Tenants []StorageTenantUsage `json:"tenants"`
+}
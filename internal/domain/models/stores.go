@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Stores are the shops and branches of a business. Stock is kept per store, and orders, shifts
+// and terminals belong to the store they were made in or are set up at. Code is the short
+// name staff know the store by; LegalName and TaxID identify the business the store belongs to
+// on its invoices. Inactive stores are kept for history but can't be worked in.
+type Stores struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	Code      string         `json:"code" gorm:"not null;size:20;uniqueIndex:idx_stores_code,where:deleted_at IS NULL"`
+	Name      string         `json:"name" gorm:"not null;size:255"`
+	Address   string         `json:"address" gorm:"type:text"`
+	Phone     string         `json:"phone" gorm:"size:50"`
+	Email     string         `json:"email" gorm:"size:255"`
+	LegalName string         `json:"legal_name" gorm:"size:255"`
+	TaxID     string         `json:"tax_id" gorm:"size:50"`
+	Active    bool           `json:"active" gorm:"not null;default:true;index"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// UserStores assign users to the stores they work in. Users without assignments may work in
+// every store; admins always may.
+type UserStores struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_user_stores_user_store"`
+	StoreID   uint      `json:"store_id" gorm:"not null;index;uniqueIndex:idx_user_stores_user_store"`
+	Store     *Stores   `json:"store,omitempty" gorm:"foreignKey:StoreID"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StoreRequest represents the request payload for creating or updating a store
+type StoreRequest struct {
+	Code      string `json:"code" validate:"required,max=20"`
+	Name      string `json:"name" validate:"required,max=255"`
+	Address   string `json:"address" validate:"max=2000"`
+	Phone     string `json:"phone" validate:"omitempty,max=50"`
+	Email     string `json:"email" validate:"omitempty,email,max=255"`
+	LegalName string `json:"legal_name" validate:"max=255"`
+	TaxID     string `json:"tax_id" validate:"max=50"`
+	Active    *bool  `json:"active"`
+}
+
+// SetUserStoresRequest replaces the stores a user is assigned to; an empty list lets the user
+// work in every store
+type SetUserStoresRequest struct {
+	StoreIDs []uint `json:"store_ids" validate:"max=100,dive,min=1"`
+}
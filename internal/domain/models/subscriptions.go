@@ -0,0 +1,107 @@
+package models
+
+import "time"
+
+// Subscription statuses, matching the Stripe subscription statuses
+const (
+	SubscriptionStatusTrialing          = "trialing"
+	SubscriptionStatusActive            = "active"
+	SubscriptionStatusPastDue           = "past_due"
+	SubscriptionStatusUnpaid            = "unpaid"
+	SubscriptionStatusCanceled          = "canceled"
+	SubscriptionStatusIncomplete        = "incomplete"
+	SubscriptionStatusIncompleteExpired = "incomplete_expired"
+)
+
+// Plan resources limited by a subscription
+const (
+	PlanResourceUsers     = "users"
+	PlanResourceLocations = "locations"
+	PlanResourceAPICalls  = "api_calls"
+)
+
+// Plans describes what a subscription allows. A limit of 0 means unlimited.
+type Plans struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	Code              string    `json:"code" gorm:"not null;size:50;uniqueIndex"`
+	Name              string    `json:"name" gorm:"not null;size:100"`
+	MaxUsers          int       `json:"max_users" gorm:"not null;default:0"`
+	MaxLocations      int       `json:"max_locations" gorm:"not null;default:0"`
+	MaxAPICallsPerDay int       `json:"max_api_calls_per_day" gorm:"not null;default:0"`
+	StripePriceID     string    `json:"stripe_price_id" gorm:"size:100;index"`
+	IsActive          bool      `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// Subscriptions links a tenant to its plan and the Stripe subscription billing it
+type Subscriptions struct {
+	ID                   uint       `json:"id" gorm:"primaryKey"`
+	TenantID             uint       `json:"tenant_id" gorm:"not null;uniqueIndex"`
+	PlanID               uint       `json:"plan_id" gorm:"not null;index"`
+	Plan                 *Plans     `json:"plan,omitempty" gorm:"foreignKey:PlanID"`
+	Status               string     `json:"status" gorm:"not null;default:'active';size:30;index"`
+	StripeCustomerID     string     `json:"stripe_customer_id,omitempty" gorm:"size:100;index"`
+	StripeSubscriptionID string     `json:"stripe_subscription_id,omitempty" gorm:"size:100;index"`
+	CurrentPeriodEnd     *time.Time `json:"current_period_end,omitempty"`
+	CanceledAt           *time.Time `json:"canceled_at,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
+
+// IsUsable reports whether the subscription still grants access to the API.
+// Past-due subscriptions keep working while Stripe retries the payment.
+func (s Subscriptions) IsUsable() bool {
+	switch s.Status {
+	case SubscriptionStatusCanceled, SubscriptionStatusUnpaid, SubscriptionStatusIncompleteExpired:
+		return false
+	}
+	return true
+}
+
+// BillingEvents records processed Stripe webhook events so redeliveries are ignored
+type BillingEvents struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	StripeEventID string    `json:"stripe_event_id" gorm:"not null;size:100;uniqueIndex"`
+	Type          string    `json:"type" gorm:"not null;size:100"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// CreatePlanRequest represents the request payload for creating a plan
+type CreatePlanRequest struct {
+	Code              string `json:"code" validate:"required,max=50"`
+	Name              string `json:"name" validate:"required,max=100"`
+	MaxUsers          int    `json:"max_users" validate:"min=0"`
+	MaxLocations      int    `json:"max_locations" validate:"min=0"`
+	MaxAPICallsPerDay int    `json:"max_api_calls_per_day" validate:"min=0"`
+	StripePriceID     string `json:"stripe_price_id" validate:"max=100"`
+}
+
+// UpdatePlanRequest represents the request payload for updating a plan
+type UpdatePlanRequest struct {
+	Name              string `json:"name" validate:"required,max=100"`
+	MaxUsers          int    `json:"max_users" validate:"min=0"`
+	MaxLocations      int    `json:"max_locations" validate:"min=0"`
+	MaxAPICallsPerDay int    `json:"max_api_calls_per_day" validate:"min=0"`
+	StripePriceID     string `json:"stripe_price_id" validate:"max=100"`
+	IsActive          *bool  `json:"is_active"`
+}
+
+// UpdateSubscriptionRequest represents an administrator moving the tenant to another plan
+type UpdateSubscriptionRequest struct {
+	PlanCode string `json:"plan_code" validate:"required,max=50"`
+}
+
+// SubscriptionUsage reports the tenant's current consumption against its plan limits
+type SubscriptionUsage struct {
+	Users         int64 `json:"users"`
+	Locations     int64 `json:"locations"`
+	APICallsToday int64 `json:"api_calls_today"`
+}
+
+// SubscriptionResponse represents the tenant's subscription, plan and usage
+type SubscriptionResponse struct {
+	Tenant       Tenants           `json:"tenant"`
+	Subscription *Subscriptions    `json:"subscription"`
+	Usage        SubscriptionUsage `json:"usage"`
+}
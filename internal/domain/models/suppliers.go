@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Supplier payment terms. Net terms give the store PaymentDays after delivery to pay.
+const (
+	SupplierTermsPrepaid    = "prepaid"
+	SupplierTermsOnDelivery = "on_delivery"
+	SupplierTermsNet        = "net"
+)
+
+// Suppliers are the businesses products are bought from, with who to contact there and the
+// terms they are paid on. Inactive suppliers are kept for history but not ordered from.
+type Suppliers struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	Name         string         `json:"name" gorm:"not null;size:255;index"`
+	ContactName  string         `json:"contact_name" gorm:"size:255"`
+	Email        string         `json:"email" gorm:"size:255"`
+	Phone        string         `json:"phone" gorm:"size:50"`
+	Address      string         `json:"address" gorm:"type:text"`
+	Website      string         `json:"website" gorm:"size:255"`
+	TaxNumber    string         `json:"tax_number" gorm:"size:100"`
+	PaymentTerms string         `json:"payment_terms" gorm:"not null;size:20;default:'on_delivery'"`
+	PaymentDays  int            `json:"payment_days" gorm:"not null;default:0"`
+	Notes        string         `json:"notes" gorm:"type:text"`
+	Active       bool           `json:"active" gorm:"not null;default:true;index"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// ProductSuppliers link products to the suppliers they are bought from, in order of preference:
+// Position 0 is the preferred supplier. Cost is what the supplier charges per unit, in minor
+// currency units; SupplierSKU is the supplier's own code for the product.
+type ProductSuppliers struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	ProductID        uint       `json:"product_id" gorm:"not null;uniqueIndex:idx_product_suppliers_product_supplier"`
+	SupplierID       uint       `json:"supplier_id" gorm:"not null;index;uniqueIndex:idx_product_suppliers_product_supplier"`
+	Supplier         *Suppliers `json:"supplier,omitempty" gorm:"foreignKey:SupplierID"`
+	Product          *Products  `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	SupplierSKU      string     `json:"supplier_sku" gorm:"size:100"`
+	Cost             int64      `json:"cost" gorm:"not null;default:0"`
+	LeadTimeDays     int        `json:"lead_time_days" gorm:"not null;default:0"`
+	MinOrderQuantity int64      `json:"min_order_quantity" gorm:"not null;default:0"`
+	Position         int        `json:"position" gorm:"not null;default:0"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// SupplierRequest represents the request payload for creating or updating a supplier.
+// PaymentDays only applies to net terms.
+type SupplierRequest struct {
+	Name         string `json:"name" validate:"required,max=255"`
+	ContactName  string `json:"contact_name" validate:"max=255"`
+	Email        string `json:"email" validate:"omitempty,email,max=255"`
+	Phone        string `json:"phone" validate:"omitempty,max=50"`
+	Address      string `json:"address" validate:"max=2000"`
+	Website      string `json:"website" validate:"omitempty,url,max=255"`
+	TaxNumber    string `json:"tax_number" validate:"max=100"`
+	PaymentTerms string `json:"payment_terms" validate:"omitempty,oneof=prepaid on_delivery net"`
+	PaymentDays  int    `json:"payment_days" validate:"min=0,max=365"`
+	Notes        string `json:"notes" validate:"max=5000"`
+	Active       *bool  `json:"active"`
+}
+
+// ProductSupplierInput is one supplier of a product
+type ProductSupplierInput struct {
+	SupplierID       uint   `json:"supplier_id" validate:"required"`
+	SupplierSKU      string `json:"supplier_sku" validate:"max=100"`
+	Cost             int64  `json:"cost" validate:"min=0"`
+	LeadTimeDays     int    `json:"lead_time_days" validate:"min=0,max=365"`
+	MinOrderQuantity int64  `json:"min_order_quantity" validate:"min=0"`
+}
+
+// SetProductSuppliersRequest replaces the suppliers of a product, the preferred one first
+type SetProductSuppliersRequest struct {
+	Suppliers []ProductSupplierInput `json:"suppliers" validate:"max=50,dive"`
+}
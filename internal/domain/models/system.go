@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// UpdateReadOnlyModeRequest represents the request payload for toggling read-only mode
+type UpdateReadOnlyModeRequest struct {
+	Enabled *bool  `json:"enabled" validate:"required"`
+	Reason  string `json:"reason" validate:"max=255"`
+}
+
+// Health statuses. A degraded service still serves traffic, with a dependency it can do without
+// down; a disabled dependency isn't configured.
+const (
+	HealthStatusUp       = "up"
+	HealthStatusDown     = "down"
+	HealthStatusDegraded = "degraded"
+	HealthStatusDisabled = "disabled"
+)
+
+// HealthCheck is the outcome of checking one dependency. Pending lists what the dependency is
+// still waiting for, e.g. the tables of migrations that haven't run.
+type HealthCheck struct {
+	Status    string   `json:"status"`
+	LatencyMs float64  `json:"latency_ms"`
+	Error     string   `json:"error,omitempty"`
+	Pending   []string `json:"pending,omitempty"`
+}
+
+// HealthResponse is returned by the liveness and readiness probes. Liveness reports no checks.
+type HealthResponse struct {
+	Status    string                 `json:"status"`
+	Checks    map[string]HealthCheck `json:"checks,omitempty"`
+	CheckedAt time.Time              `json:"checked_at"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DiningAreas group the tables of a restaurant, e.g. the terrace or the bar, and belong to the
+// store they are in. Position orders areas on the floor plan.
+type DiningAreas struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	StoreID   *uint          `json:"store_id" gorm:"index"`
+	Name      string         `json:"name" gorm:"not null;size:100"`
+	Position  int            `json:"position" gorm:"not null;default:0"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// DiningTables are the tables of an area. A table has at most one open ticket, the cart its
+// orders are rung up in until the bill is settled; inactive tables can't be seated.
+type DiningTables struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	AreaID    uint           `json:"area_id" gorm:"not null;index"`
+	Name      string         `json:"name" gorm:"not null;size:50"`
+	Seats     int            `json:"seats" gorm:"not null;default:0"`
+	Position  int            `json:"position" gorm:"not null;default:0"`
+	Active    bool           `json:"active" gorm:"not null;default:true;index"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// DiningAreaRequest represents the request payload for creating or updating a dining area
+type DiningAreaRequest struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	StoreID  *uint  `json:"store_id" validate:"omitempty,min=1"`
+	Position int    `json:"position" validate:"min=0"`
+}
+
+// DiningTableRequest represents the request payload for creating or updating a table
+type DiningTableRequest struct {
+	AreaID   uint   `json:"area_id" validate:"required"`
+	Name     string `json:"name" validate:"required,max=50"`
+	Seats    int    `json:"seats" validate:"min=0,max=1000"`
+	Position int    `json:"position" validate:"min=0"`
+	Active   *bool  `json:"active"`
+}
+
+// DiningTableStatus is a table on the floor plan with its open ticket, if it has one
+type DiningTableStatus struct {
+	ID              uint       `json:"id"`
+	AreaID          uint       `json:"area_id"`
+	AreaName        string     `json:"area_name"`
+	Name            string     `json:"name"`
+	Seats           int        `json:"seats"`
+	Active          bool       `json:"active"`
+	TicketID        *uint      `json:"ticket_id"`
+	TicketLines     int64      `json:"ticket_lines"`
+	TicketOpenedAt  *time.Time `json:"ticket_opened_at"`
+	TicketUpdatedAt *time.Time `json:"ticket_updated_at"`
+}
+
+// MoveTicketRequest names the other table of a ticket transfer or merge
+type MoveTicketRequest struct {
+	TableID uint `json:"table_id" validate:"required"`
+}
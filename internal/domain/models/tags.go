@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Tags are free-form labels for segmenting users (e.g., "trainee", "night-shift")
+type Tags struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"unique;not null;size:50"`
+	Color       string    `json:"color" gorm:"size:20"`
+	Description string    `json:"description" gorm:"size:255"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// UserTags is the join table between users and tags
+type UserTags struct {
+	UserID    uint      `json:"user_id" gorm:"primaryKey"`
+	TagID     uint      `json:"tag_id" gorm:"primaryKey;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TagRequest represents the request payload for creating or updating a tag
+type TagRequest struct {
+	Name        string `json:"name" validate:"required,max=50"`
+	Color       string `json:"color" validate:"omitempty,hexcolor"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+// AssignUserTagsRequest represents the request payload for adding tags to a user
+type AssignUserTagsRequest struct {
+	TagIDs []uint `json:"tag_ids" validate:"required,min=1,dive,min=1"`
+}
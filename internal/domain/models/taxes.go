@@ -0,0 +1,125 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TaxClasses group products taxed alike, e.g. standard, reduced or exempt goods. Products
+// without a class are taxed at the rate given with each line.
+type TaxClasses struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"not null;size:100;uniqueIndex:idx_tax_classes_name,where:deleted_at IS NULL"`
+	Description string         `json:"description" gorm:"size:255"`
+	Rates       []TaxRates     `json:"rates,omitempty" gorm:"foreignKey:TaxClassID"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TaxRates are the taxes charged on a class in a region. Region is the location of the store
+// or terminal selling; rates without a region apply wherever the class has no rates of the
+// selling region. The rates of a class in a region add up, e.g. a state and a city sales tax,
+// and are either all included in prices (VAT-style) or all added on top. Rate is in basis
+// points (1000 = 10%).
+type TaxRates struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	TaxClassID uint           `json:"tax_class_id" gorm:"not null;index"`
+	Name       string         `json:"name" gorm:"not null;size:100"`
+	Region     string         `json:"region" gorm:"not null;size:100;index"`
+	Rate       int64          `json:"rate" gorm:"not null"`
+	Inclusive  bool           `json:"inclusive" gorm:"not null;default:false"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// LineTax is the tax one rate charges on an order line
+type LineTax struct {
+	TaxRateID *uint  `json:"tax_rate_id,omitempty"`
+	Name      string `json:"name"`
+	Rate      int64  `json:"rate"`
+	Inclusive bool   `json:"inclusive"`
+	Amount    int64  `json:"amount"`
+}
+
+// LineTaxes are the taxes of an order line stored in a JSONB column
+type LineTaxes []LineTax
+
+// Value implements driver.Valuer
+func (l LineTaxes) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (l *LineTaxes) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = LineTaxes{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for LineTaxes: %T", value)
+	}
+
+	result := LineTaxes{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*l = result
+	return nil
+}
+
+// TaxBreakdown is the tax one rate charges on a cart or order. Taxable is the amount taxed
+// at the rate, without tax.
+type TaxBreakdown struct {
+	TaxRateID *uint  `json:"tax_rate_id,omitempty" gorm:"index"`
+	Name      string `json:"name" gorm:"not null;size:100"`
+	Rate      int64  `json:"rate" gorm:"not null"`
+	Inclusive bool   `json:"inclusive" gorm:"not null;default:false"`
+	Taxable   int64  `json:"taxable" gorm:"not null"`
+	Amount    int64  `json:"amount" gorm:"not null"`
+}
+
+// OrderTaxes are the tax breakdown of an order, kept for receipts and tax reports
+type OrderTaxes struct {
+	ID      uint `json:"id" gorm:"primaryKey"`
+	OrderID uint `json:"order_id" gorm:"not null;index"`
+	TaxBreakdown
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// CreateTaxClassRequest represents the request payload for creating a tax class
+type CreateTaxClassRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+// UpdateTaxClassRequest represents the request payload for updating a tax class
+type UpdateTaxClassRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+// TaxRateRequest represents the request payload for adding or changing a tax rate
+type TaxRateRequest struct {
+	Name      string `json:"name" validate:"required,max=100"`
+	Region    string `json:"region" validate:"max=100"`
+	Rate      int64  `json:"rate" validate:"min=0,max=10000"`
+	Inclusive bool   `json:"inclusive"`
+}
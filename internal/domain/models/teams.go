@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Team member roles
+const (
+	TeamRoleLead   = "lead"
+	TeamRoleMember = "member"
+)
+
+type Teams struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"not null;size:100;uniqueIndex:idx_teams_name,where:deleted_at IS NULL"`
+	Description string         `json:"description" gorm:"size:255"`
+	Members     []TeamMembers  `json:"members,omitempty" gorm:"foreignKey:TeamID"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+type TeamMembers struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TeamID    uint      `json:"team_id" gorm:"not null;uniqueIndex:idx_team_members_team_user"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_team_members_team_user;index"`
+	Role      string    `json:"role" gorm:"not null;default:'member';size:20"`
+	User      *Users    `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateTeamRequest represents the request payload for creating a team
+type CreateTeamRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+// UpdateTeamRequest represents the request payload for updating a team
+type UpdateTeamRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+// AddTeamMemberRequest represents the request payload for adding a member to a team
+type AddTeamMemberRequest struct {
+	UserID uint   `json:"user_id" validate:"required"`
+	Role   string `json:"role" validate:"required,oneof=lead member"`
+}
+
+// UpdateTeamMemberRequest represents the request payload for changing a member's team role
+type UpdateTeamMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=lead member"`
+}
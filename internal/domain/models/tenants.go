@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// Tenant provisioning statuses
+const (
+	TenantStatusPendingVerification = "pending_verification"
+	TenantStatusActive              = "active"
+)
+
+// Tenants holds the per-domain settings of a tenant. Requests are matched to a tenant by
+// their Host header; hosts without a tenant fall back to the global configuration.
+type Tenants struct {
+	ID                 uint           `json:"id" gorm:"primaryKey"`
+	Name               string         `json:"name" gorm:"not null;size:100"`
+	Host               string         `json:"host" gorm:"not null;size:255;uniqueIndex"`
+	CORSAllowedOrigins JSONStringList `json:"cors_allowed_origins" gorm:"column:cors_allowed_origins;type:jsonb;not null;default:'[]'"`
+	CookieDomain       string         `json:"cookie_domain" gorm:"size:255"`
+	CookieSecure       bool           `json:"cookie_secure" gorm:"not null;default:false"`
+	IsActive           bool           `json:"is_active" gorm:"not null;default:true"`
+	// Self-serve signups stay pending until the owner verifies their email address
+	Status                string     `json:"status" gorm:"not null;default:'active';size:30;index"`
+	OwnerID               *uint      `json:"owner_id,omitempty" gorm:"index"`
+	VerificationTokenHash *string    `json:"-" gorm:"size:64;index"`
+	VerificationExpiresAt *time.Time `json:"-"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+}
+
+// CreateTenantRequest represents the request payload for creating a tenant
+type CreateTenantRequest struct {
+	Name               string   `json:"name" validate:"required,max=100"`
+	Host               string   `json:"host" validate:"required,hostname,max=255"`
+	CORSAllowedOrigins []string `json:"cors_allowed_origins" validate:"max=20,dive,url"`
+	CookieDomain       string   `json:"cookie_domain" validate:"omitempty,max=255"`
+	CookieSecure       bool     `json:"cookie_secure"`
+	IsActive           *bool    `json:"is_active"`
+}
+
+// UpdateTenantRequest represents the request payload for updating a tenant
+type UpdateTenantRequest struct {
+	Name               string   `json:"name" validate:"required,max=100"`
+	Host               string   `json:"host" validate:"required,hostname,max=255"`
+	CORSAllowedOrigins []string `json:"cors_allowed_origins" validate:"max=20,dive,url"`
+	CookieDomain       string   `json:"cookie_domain" validate:"omitempty,max=255"`
+	CookieSecure       bool     `json:"cookie_secure"`
+	IsActive           *bool    `json:"is_active"`
+}
+
+// TenantSignupRequest represents the public request payload for registering a new tenant
+type TenantSignupRequest struct {
+	TenantName    string `json:"tenant_name" validate:"required,max=100"`
+	Host          string `json:"host" validate:"required,hostname,max=90"`
+	AdminName     string `json:"admin_name" validate:"required,max=100"`
+	AdminUsername string `json:"admin_username" validate:"required,min=3,max=50"`
+	AdminEmail    string `json:"admin_email" validate:"required,email,max=255"`
+	AdminPassword string `json:"admin_password" validate:"required,min=8"`
+	DemoData      bool   `json:"demo_data"`
+}
+
+// VerifyTenantSignupRequest represents the request payload for verifying a tenant signup
+type VerifyTenantSignupRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// TenantProvisioningStatus reports how far a tenant signup has progressed
+type TenantProvisioningStatus struct {
+	Host     string `json:"host"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	IsActive bool   `json:"is_active"`
+}
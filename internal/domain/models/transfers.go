@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// Stock transfer statuses. Transfers are requested, approved, sent and then received at the
+// destination; they can be cancelled until they are received.
+const (
+	TransferStatusRequested = "requested"
+	TransferStatusApproved  = "approved"
+	TransferStatusSent      = "sent"
+	TransferStatusReceived  = "received"
+	TransferStatusCancelled = "cancelled"
+)
+
+// StockTransfers move stock from one store to another. Stock moves when the destination
+// receives the goods, out of the source and into the destination in one transaction, so until
+// then the lines of sent transfers are in transit. Number is derived from the ID like order
+// numbers.
+type StockTransfers struct {
+	ID                 uint                 `json:"id" gorm:"primaryKey"`
+	Number             string               `json:"number" gorm:"not null;size:30;index"`
+	SourceStoreID      uint                 `json:"source_store_id" gorm:"not null;index"`
+	DestinationStoreID uint                 `json:"destination_store_id" gorm:"not null;index"`
+	Status             string               `json:"status" gorm:"not null;default:'requested';size:20;index"`
+	Note               string               `json:"note" gorm:"size:255"`
+	RequestedByID      *uint                `json:"requested_by_id,omitempty" gorm:"index"`
+	ApprovedByID       *uint                `json:"approved_by_id,omitempty" gorm:"index"`
+	SentByID           *uint                `json:"sent_by_id,omitempty" gorm:"index"`
+	ReceivedByID       *uint                `json:"received_by_id,omitempty" gorm:"index"`
+	CancelledByID      *uint                `json:"cancelled_by_id,omitempty" gorm:"index"`
+	ApprovedAt         *time.Time           `json:"approved_at,omitempty"`
+	SentAt             *time.Time           `json:"sent_at,omitempty"`
+	ReceivedAt         *time.Time           `json:"received_at,omitempty"`
+	CancelledAt        *time.Time           `json:"cancelled_at,omitempty"`
+	Items              []StockTransferItems `json:"items,omitempty" gorm:"foreignKey:TransferID"`
+	CreatedAt          time.Time            `json:"created_at" gorm:"index"`
+	UpdatedAt          time.Time            `json:"updated_at"`
+}
+
+// StockTransferItems are the lines of a transfer. Serials and Batch name the units of tracked
+// products that move.
+type StockTransferItems struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	TransferID uint           `json:"transfer_id" gorm:"not null;index"`
+	ProductID  uint           `json:"product_id" gorm:"not null;index"`
+	VariantID  *uint          `json:"variant_id" gorm:"index"`
+	Quantity   int64          `json:"quantity" gorm:"not null"`
+	Serials    JSONStringList `json:"serials,omitempty" gorm:"type:jsonb;not null;default:'[]'"`
+	Batch      string         `json:"batch,omitempty" gorm:"size:100"`
+}
+
+// CreateStockTransferRequest represents the request payload for requesting a transfer
+type CreateStockTransferRequest struct {
+	SourceStoreID      uint                       `json:"source_store_id" validate:"required"`
+	DestinationStoreID uint                       `json:"destination_store_id" validate:"required,nefield=SourceStoreID"`
+	Note               string                     `json:"note" validate:"max=255"`
+	Items              []StockTransferItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+// StockTransferItemRequest is one line of a transfer request. Lines of serialized products
+// list a serial per unit, and lines of batch-tracked products name the batch.
+type StockTransferItemRequest struct {
+	ProductID uint     `json:"product_id" validate:"required"`
+	VariantID *uint    `json:"variant_id"`
+	Quantity  int64    `json:"quantity" validate:"required,min=1"`
+	Serials   []string `json:"serials" validate:"omitempty,max=1000,dive,required,max=100"`
+	Batch     string   `json:"batch" validate:"max=100"`
+}
+
+// InTransitStock is a line of a sent transfer that hasn't been received yet
+type InTransitStock struct {
+	TransferID         uint      `json:"transfer_id"`
+	Number             string    `json:"number"`
+	SourceStoreID      uint      `json:"source_store_id"`
+	DestinationStoreID uint      `json:"destination_store_id"`
+	ProductID          uint      `json:"product_id"`
+	VariantID          *uint     `json:"variant_id"`
+	ProductName        string    `json:"product_name"`
+	VariantTitle       *string   `json:"variant_title"`
+	SKU                string    `json:"sku"`
+	Quantity           int64     `json:"quantity"`
+	SentAt             time.Time `json:"sent_at"`
+}
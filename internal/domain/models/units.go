@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// Unit dimensions. Units of the same dimension with a factor convert into each other.
+const (
+	UnitDimensionCount  = "count"
+	UnitDimensionMass   = "mass"
+	UnitDimensionVolume = "volume"
+)
+
+// UnitEach is the unit products are stocked in unless they are given another one
+const UnitEach = "ea"
+
+// DefaultUnits are the units every database starts with
+var DefaultUnits = []UnitsOfMeasure{
+	{Code: UnitEach, Name: "Each", Dimension: UnitDimensionCount, Factor: 1},
+	{Code: "g", Name: "Gram", Dimension: UnitDimensionMass, Factor: 1},
+	{Code: "kg", Name: "Kilogram", Dimension: UnitDimensionMass, Factor: 1000},
+	{Code: "ml", Name: "Milliliter", Dimension: UnitDimensionVolume, Factor: 1},
+	{Code: "l", Name: "Liter", Dimension: UnitDimensionVolume, Factor: 1000},
+}
+
+// UnitsOfMeasure are the units quantities are counted in. Code is what products, requests and
+// the stock ledger refer to the unit by and never changes. Factor is the number of the
+// dimension's smallest unit in one of the unit (a kilogram is 1000 grams); units without a
+// factor, such as cases, only convert through the units of a product.
+type UnitsOfMeasure struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Code      string    `json:"code" gorm:"not null;size:20;uniqueIndex"`
+	Name      string    `json:"name" gorm:"not null;size:100"`
+	Dimension string    `json:"dimension" gorm:"not null;size:20"`
+	Factor    int64     `json:"factor" gorm:"not null;default:0"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ProductUnits are the packagings a product is bought or counted in besides the unit it is
+// stocked in, each worth Factor stock units, e.g. a case of 12
+type ProductUnits struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ProductID uint      `json:"product_id" gorm:"not null;uniqueIndex:idx_product_units_product_unit"`
+	Unit      string    `json:"unit" gorm:"not null;size:20;index;uniqueIndex:idx_product_units_product_unit"`
+	Factor    int64     `json:"factor" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateUnitRequest represents the request payload for creating a unit of measure
+type CreateUnitRequest struct {
+	Code      string `json:"code" validate:"required,max=20"`
+	Name      string `json:"name" validate:"required,max=100"`
+	Dimension string `json:"dimension" validate:"required,oneof=count mass volume"`
+	Factor    int64  `json:"factor" validate:"min=0"`
+}
+
+// UpdateUnitRequest represents the request payload for renaming a unit of measure; its code,
+// dimension and factor are fixed once quantities are counted in it
+type UpdateUnitRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// ProductUnitInput is one packaging of a product
+type ProductUnitInput struct {
+	Unit   string `json:"unit" validate:"required,max=20"`
+	Factor int64  `json:"factor" validate:"required,min=1"`
+}
+
+// SetProductUnitsRequest replaces the packagings of a product
+type SetProductUnitsRequest struct {
+	Units []ProductUnitInput `json:"units" validate:"max=20,dive"`
+}
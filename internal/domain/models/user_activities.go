@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Activity event types
+const (
+	ActivityRegistered           = "registered"
+	ActivityLogin                = "login"
+	ActivityLoginFailed          = "login_failed"
+	ActivityUserCreated          = "user_created"
+	ActivityProfileUpdated       = "profile_updated"
+	ActivityRoleChanged          = "role_changed"
+	ActivityPasswordChanged      = "password_changed"
+	ActivityUserDeleted          = "user_deleted"
+	ActivityUserSoftDeleted      = "user_soft_deleted"
+	ActivityUserRestored         = "user_restored"
+	ActivityStatusChanged        = "status_changed"
+	ActivityEmailChangeRequested = "email_change_requested"
+	ActivityEmailChanged         = "email_changed"
+	ActivityPasswordReset        = "password_reset"
+	ActivityUserMerged           = "user_merged"
+	ActivityTagsChanged          = "tags_changed"
+	ActivityStockAdjusted        = "stock_adjusted"
+	ActivityStockReceived        = "stock_received"
+	ActivityOrderCreated         = "order_created"
+	ActivityOrderStatusChanged   = "order_status_changed"
+	ActivityPaymentRecorded      = "payment_recorded"
+	ActivityPaymentRefunded      = "payment_refunded"
+	ActivityOrderReturned        = "order_returned"
+	ActivityCashDrawerOpened     = "cash_drawer_opened"
+	ActivityCashDrawerClosed     = "cash_drawer_closed"
+	ActivityCashMovementRecorded = "cash_movement_recorded"
+	ActivityShiftOpened          = "shift_opened"
+	ActivityShiftClosed          = "shift_closed"
+)
+
+type UserActivities struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"not null;index:idx_user_activities_user_created"`
+	ActorID     *uint     `json:"actor_id" gorm:"index"`
+	EventType   string    `json:"event_type" gorm:"not null;size:50;index"`
+	Description string    `json:"description" gorm:"size:255"`
+	Metadata    JSONMap   `json:"metadata" gorm:"type:jsonb;not null;default:'{}'"`
+	IPAddress   string    `json:"ip_address" gorm:"size:45"`
+	UserAgent   string    `json:"user_agent" gorm:"size:255"`
+	CreatedAt   time.Time `json:"created_at" gorm:"index:idx_user_activities_user_created"`
+}
+
+// ActivityActor describes who performed an action and from where
+type ActivityActor struct {
+	UserID    uint
+	IPAddress string
+	UserAgent string
+}
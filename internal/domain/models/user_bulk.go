@@ -0,0 +1,33 @@
+package models
+
+// Bulk user actions
+const (
+	BulkActionSoftDelete = "soft-delete"
+	BulkActionRestore    = "restore"
+	BulkActionSetRole    = "set-role"
+	BulkActionSuspend    = "suspend"
+)
+
+// BulkUserRequest represents the request payload for applying one action to many users
+type BulkUserRequest struct {
+	Action string `json:"action" validate:"required,oneof=soft-delete restore set-role suspend"`
+	IDs    []uint `json:"ids" validate:"required,min=1,dive,min=1"`
+	Role   string `json:"role" validate:"required_if=Action set-role,omitempty,oneof=admin user"`
+	Reason string `json:"reason" validate:"max=255"`
+}
+
+// BulkUserResult is the outcome of a bulk action for a single user
+type BulkUserResult struct {
+	ID      uint   `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkUserResponse summarizes a bulk action. Users that could not be changed are reported
+// individually and skipped; the rest are changed together in one transaction.
+type BulkUserResponse struct {
+	Action    string           `json:"action"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkUserResult `json:"results"`
+}
@@ -0,0 +1,45 @@
+package models
+
+// MergeUsersRequest represents the request payload for merging a duplicate account into a primary one
+type MergeUsersRequest struct {
+	PrimaryID   uint `json:"primary_id" validate:"required"`
+	DuplicateID uint `json:"duplicate_id" validate:"required,nefield=PrimaryID"`
+}
+
+// MergedRecords counts the records moved from the duplicate to the primary user
+type MergedRecords struct {
+	Activities          int64 `json:"activities"`
+	LoginEvents         int64 `json:"login_events"`
+	TeamMemberships     int64 `json:"team_memberships"`
+	PrintJobs           int64 `json:"print_jobs"`
+	ReportDefinitions   int64 `json:"report_definitions"`
+	ReportSchedules     int64 `json:"report_schedules"`
+	Tenants             int64 `json:"tenants"`
+	StockMovements      int64 `json:"stock_movements"`
+	StockTransfers      int64 `json:"stock_transfers"`
+	Stocktakes          int64 `json:"stocktakes"`
+	Orders              int64 `json:"orders"`
+	Imports             int64 `json:"imports"`
+	Payments            int64 `json:"payments"`
+	CashDrawerSessions  int64 `json:"cash_drawer_sessions"`
+	Shifts              int64 `json:"shifts"`
+	OrderReturns        int64 `json:"order_returns"`
+	ReceiptEmails       int64 `json:"receipt_emails"`
+	Coupons             int64 `json:"coupons"`
+	Customers           int64 `json:"customers"`
+	LoyaltyTransactions int64 `json:"loyalty_transactions"`
+	GiftCards           int64 `json:"gift_cards"`
+	Invoices            int64 `json:"invoices"`
+	Quotes              int64 `json:"quotes"`
+	Notifications       int64 `json:"notifications"`
+	Settings            bool  `json:"settings"`
+}
+
+// MergeUsersResponse describes a completed merge. Metadata keys present on both accounts
+// keep the primary's value and are listed in MetadataConflicts.
+type MergeUsersResponse struct {
+	Primary           Users         `json:"primary"`
+	DuplicateID       uint          `json:"duplicate_id"`
+	Reassigned        MergedRecords `json:"reassigned"`
+	MetadataConflicts []string      `json:"metadata_conflicts"`
+}
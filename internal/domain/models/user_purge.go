@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// User purge modes
+const (
+	UserPurgeModeDelete    = "delete"
+	UserPurgeModeAnonymize = "anonymize"
+)
+
+// PurgedUser identifies a user removed (or due to be removed) by a purge
+type PurgedUser struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// UserPurgeReport summarizes a purge run. In dry-run mode it lists the users that would be purged.
+type UserPurgeReport struct {
+	Mode          string       `json:"mode"`
+	DryRun        bool         `json:"dry_run"`
+	RetentionDays int          `json:"retention_days"`
+	Cutoff        time.Time    `json:"cutoff"`
+	Count         int          `json:"count"`
+	Users         []PurgedUser `json:"users"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// UserQuotas overrides the API request quota of the user's role for a single user
+type UserQuotas struct {
+	UserID uint `json:"user_id" gorm:"primaryKey"`
+	// Requests allowed per quota window; 0 means unlimited
+	Limit       int       `json:"limit" gorm:"column:max_requests;not null"`
+	UpdatedByID *uint     `json:"updated_by_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// UpdateUserQuotaRequest sets a user's quota override; a null limit falls back to the role quota
+type UpdateUserQuotaRequest struct {
+	Limit *int `json:"limit" validate:"omitempty,min=0"`
+}
+
+// UserQuotaStatus reports a user's quota and their usage in the current window
+type UserQuotaStatus struct {
+	UserID uint   `json:"user_id"`
+	Role   string `json:"role"`
+	// Requests allowed per window; 0 means unlimited
+	Limit     int       `json:"limit"`
+	Override  bool      `json:"override"`
+	Used      int64     `json:"used"`
+	Remaining int64     `json:"remaining"`
+	Window    string    `json:"window"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
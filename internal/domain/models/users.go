@@ -7,17 +7,79 @@ import (
 	"gorm.io/gorm"
 )
 
+// User roles
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// User statuses
+const (
+	UserStatusPending   = "pending"
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+)
+
+// How the auth middleware keeps a user's role and status current while their token is valid
+const (
+	// Compare the cached user with the current claims version on every request
+	ClaimsModeStrict = "strict"
+	// Trust the cached user until it expires
+	ClaimsModePerformance = "performance"
+)
+
+// UserStatusTransitions lists the statuses each status may move to
+var UserStatusTransitions = map[string][]string{
+	UserStatusPending:   {UserStatusActive},
+	UserStatusActive:    {UserStatusSuspended},
+	UserStatusSuspended: {UserStatusActive},
+}
+
 type Users struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Username  string         `json:"username" gorm:"unique;not null;size:50"`
-	Email     string         `json:"email" gorm:"unique;not null;size:255"`
-	Password  string         `json:"-" gorm:"not null"` // "-" means don't include in JSON
-	Name      string         `json:"name" gorm:"not null;size:100"`
-	Role      string         `json:"role" gorm:"not null;default:'user';size:20"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
-	IsDeleted bool           `json:"is_deleted" gorm:"default:false"`
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	Username        string         `json:"username" gorm:"unique;not null;size:50"`
+	Email           string         `json:"email" gorm:"unique;not null;size:255"`
+	Password        string         `json:"-" gorm:"not null"` // "-" means don't include in JSON
+	Name            string         `json:"name" gorm:"not null;size:100"`
+	Role            string         `json:"role" gorm:"not null;default:'user';size:20"`
+	Status          string         `json:"status" gorm:"not null;default:'active';size:20;index"`
+	StatusReason    string         `json:"status_reason,omitempty" gorm:"size:255"`
+	StatusChangedAt *time.Time     `json:"status_changed_at,omitempty"`
+	ClaimsVersion   int64          `json:"claims_version" gorm:"not null;default:1"` // Bumped whenever the role or status changes
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+	IsDeleted       bool           `json:"is_deleted" gorm:"default:false"`
+	SoftDeletedAt   *time.Time     `json:"soft_deleted_at,omitempty" gorm:"index"`
+	PurgedAt        *time.Time     `json:"purged_at,omitempty"`
+	Metadata        JSONMap        `json:"metadata" gorm:"type:jsonb;not null;default:'{}'"`
+	LastLoginAt     *time.Time     `json:"last_login_at" gorm:"index"`
+	LastLoginIP     string         `json:"last_login_ip" gorm:"size:45"`
+	// Set after an administrator resets the password; cleared once the user picks a new one
+	MustChangePassword     bool       `json:"must_change_password" gorm:"not null;default:false"`
+	PasswordResetTokenHash *string    `json:"-" gorm:"size:64;index"`
+	PasswordResetExpiresAt *time.Time `json:"-"`
+	// Email changes wait here until the new address is confirmed
+	PendingEmail         *string    `json:"pending_email,omitempty" gorm:"size:255"`
+	EmailChangeTokenHash *string    `json:"-" gorm:"size:64;index"`
+	EmailChangeExpiresAt *time.Time `json:"-"`
+	// Set on a duplicate account once it has been merged into another user
+	MergedIntoID *uint `json:"merged_into_id,omitempty" gorm:"index"`
+	// Identifier assigned by the identity provider that provisions the user through SCIM
+	ExternalID *string `json:"external_id,omitempty" gorm:"size:255;uniqueIndex"`
+	// Preloaded by user listings and lookups
+	Tags []Tags `json:"tags,omitempty" gorm:"many2many:user_tags"`
+	// Filled from Redis presence in user listings; omitted when presence is unknown
+	IsOnline *bool `json:"is_online,omitempty" gorm:"-"`
+}
+
+// OnlineUser is a user seen recently by the auth middleware
+type OnlineUser struct {
+	ID         uint      `json:"id"`
+	Username   string    `json:"username"`
+	Name       string    `json:"name"`
+	Role       string    `json:"role"`
+	LastSeenAt time.Time `json:"last_seen_at"`
 }
 
 // RegisterRequest represents the registration request payload
@@ -28,6 +90,21 @@ type RegisterRequest struct {
 	Name     string `json:"name" validate:"required,max=100"`
 }
 
+// AvailabilityQuery represents the query parameters for checking a username or email before registering
+type AvailabilityQuery struct {
+	Username string `form:"username" validate:"required_without=Email,omitempty,min=3,max=50"`
+	Email    string `form:"email" validate:"required_without=Username,omitempty,email,max=255"`
+}
+
+// AvailabilityResponse reports which of the requested values are still free.
+// Email availability is only reported to administrators so emails cannot be enumerated.
+type AvailabilityResponse struct {
+	Username          string `json:"username,omitempty"`
+	UsernameAvailable *bool  `json:"username_available,omitempty"`
+	Email             string `json:"email,omitempty"`
+	EmailAvailable    *bool  `json:"email_available,omitempty"`
+}
+
 // RegisterResponse represents the registration response payload
 type RegisterResponse struct {
 	ID       uint   `json:"id"`
@@ -35,6 +112,8 @@ type RegisterResponse struct {
 	Email    string `json:"email"`
 	Name     string `json:"name"`
 	Role     string `json:"role"`
+
+	MustChangePassword bool `json:"must_change_password,omitempty"`
 }
 
 // LoginRequest represents the login request payload
@@ -66,13 +145,34 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
+// Reasons an access token is inactive
+const (
+	TokenInactiveInvalid      = "invalid"
+	TokenInactiveExpired      = "expired"
+	TokenInactiveUserNotFound = "user_not_found"
+	TokenInactiveSuspended    = "suspended"
+	TokenInactivePending      = "pending"
+)
+
+// TokenIntrospection describes an access token: whether it would be accepted, and whose it is.
+// User, IssuedAt and ExpiresAt are only set for active tokens.
+type TokenIntrospection struct {
+	Active    bool
+	Reason    string
+	User      *Users
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
 // CreateUserRequest represents the request payload for creating a user
 type CreateUserRequest struct {
-	Username string `json:"username" validate:"required,min=3,max=50"`
-	Email    string `json:"email" validate:"required,email,max=255"`
-	Password string `json:"password" validate:"required,min=6"`
-	Name     string `json:"name" validate:"required,max=100"`
-	Role     string `json:"role" validate:"required,oneof=admin user"`
+	Username string  `json:"username" validate:"required,min=3,max=50"`
+	Email    string  `json:"email" validate:"required,email,max=255"`
+	Password string  `json:"password" validate:"required,min=6"`
+	Name     string  `json:"name" validate:"required,max=100"`
+	Role     string  `json:"role" validate:"required,oneof=admin user"`
+	Status   string  `json:"status" validate:"omitempty,oneof=pending active"`
+	Metadata JSONMap `json:"metadata"`
 }
 
 // CreateUserResponse represents the response payload for creating a user
@@ -82,6 +182,8 @@ type CreateUserResponse struct {
 	Email     string    `json:"email"`
 	Name      string    `json:"name"`
 	Role      string    `json:"role"`
+	Status    string    `json:"status"`
+	Metadata  JSONMap   `json:"metadata"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -91,4 +193,46 @@ type UpdateUserRequest struct {
 	Name     string `json:"name" validate:"required,max=100"`
 	Role     string `json:"role" validate:"required,oneof=admin user"`
 	Password string `json:"password,omitempty" validate:"omitempty,min=6"`
+	// Metadata replaces the stored attributes when present
+	Metadata JSONMap `json:"metadata,omitempty"`
+}
+
+// UpdateUserStatusRequest represents the request payload for suspending or reactivating a user
+type UpdateUserStatusRequest struct {
+	Reason string `json:"reason" validate:"max=255"`
+}
+
+// ConfirmEmailRequest represents the request payload for confirming an email change
+type ConfirmEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// Password reset methods
+const (
+	PasswordResetTemporary = "temporary"
+	PasswordResetEmail     = "email"
+)
+
+// AdminResetPasswordRequest represents the request payload for an administrator resetting a user's password
+type AdminResetPasswordRequest struct {
+	Method string `json:"method" validate:"omitempty,oneof=temporary email"`
+}
+
+// AdminResetPasswordResponse is returned once; the temporary password is never shown again
+type AdminResetPasswordResponse struct {
+	UserID            uint   `json:"user_id"`
+	Method            string `json:"method"`
+	TemporaryPassword string `json:"temporary_password,omitempty"`
+}
+
+// ResetPasswordRequest represents the request payload for setting a new password from a reset link
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+// ChangePasswordRequest represents the request payload for a user changing their own password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=6,nefield=CurrentPassword"`
 }
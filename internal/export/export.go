@@ -0,0 +1,98 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format represents a supported export file format
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// ParseFormat converts a query parameter into a Format, defaulting to CSV
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	default:
+		return "", fmt.Errorf("unsupported export format: %s", value)
+	}
+}
+
+// ContentType returns the MIME type for the format
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Filename builds a timestamped download filename for the format
+func (f Format) Filename(prefix string) string {
+	return fmt.Sprintf("%s-%s.%s", prefix, time.Now().Format("20060102-150405"), f)
+}
+
+// Writer writes tabular rows to an export file
+type Writer interface {
+	WriteRow(values []string) error
+	Close() error
+}
+
+// NewWriter creates a writer for the given format
+func NewWriter(format Format, w io.Writer, sheetName string) (Writer, error) {
+	if format == FormatXLSX {
+		return NewXLSXWriter(w, sheetName)
+	}
+	return NewCSVWriter(w), nil
+}
+
+// formulaPrefixes are the first characters that make spreadsheet apps read a cell as a formula
+const formulaPrefixes = "=+-@\t\r"
+
+// EscapeFormula stops a spreadsheet app from running a cell as a formula by prefixing it with
+// a quote when it starts like one. Numbers, negative ones included, are left alone.
+func EscapeFormula(value string) string {
+	if value == "" || !strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return value
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return "'" + value
+}
+
+// CSVWriter writes rows as comma-separated values. Cells that would run as formulas in a
+// spreadsheet app are escaped with EscapeFormula.
+type CSVWriter struct {
+	writer *csv.Writer
+}
+
+// NewCSVWriter creates a new CSV writer
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{writer: csv.NewWriter(w)}
+}
+
+// WriteRow writes a single row
+func (w *CSVWriter) WriteRow(values []string) error {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = EscapeFormula(value)
+	}
+	return w.writer.Write(escaped)
+}
+
+// Close flushes any buffered rows
+func (w *CSVWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
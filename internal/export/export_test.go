@@ -0,0 +1,45 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: ""},
+		{value: "Jane Doe", want: "Jane Doe"},
+		{value: "jane@example.com", want: "jane@example.com"},
+		{value: "=HYPERLINK(\"http://evil.example\")", want: "'=HYPERLINK(\"http://evil.example\")"},
+		{value: "+1+1", want: "'+1+1"},
+		{value: "-2+3", want: "'-2+3"},
+		{value: "@SUM(A1:A2)", want: "'@SUM(A1:A2)"},
+		{value: "\t=1", want: "'\t=1"},
+		{value: "\r=1", want: "'\r=1"},
+		{value: "-42", want: "-42"},
+		{value: "+3.5", want: "+3.5"},
+	}
+	for _, tt := range tests {
+		if got := EscapeFormula(tt.value); got != tt.want {
+			t.Errorf("EscapeFormula(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCSVWriterEscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewCSVWriter(&buf)
+	if err := writer.WriteRow([]string{"=1+1", "Jane", "-5"}); err != nil {
+		t.Fatalf("failed to write row: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close writer: %v", err)
+	}
+
+	if got, want := buf.String(), "'=1+1,Jane,-5\n"; got != want {
+		t.Errorf("wrote %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+package export
+
+import (
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// XLSXWriter writes rows into a single-sheet XLSX workbook.
+// Rows go through excelize's stream writer, which spills them to a temporary file once they
+// no longer fit in memory, and the workbook is written out when the writer is closed.
+type XLSXWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+// NewXLSXWriter creates a new XLSX writer with a single sheet of the given name
+func NewXLSXWriter(w io.Writer, sheetName string) (*XLSXWriter, error) {
+	file := excelize.NewFile()
+	if err := file.SetSheetName(file.GetSheetName(0), sheetName); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	stream, err := file.NewStreamWriter(sheetName)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &XLSXWriter{out: w, file: file, stream: stream}, nil
+}
+
+// WriteRow writes a single row
+func (w *XLSXWriter) WriteRow(values []string) error {
+	w.row++
+
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	cells := make([]interface{}, len(values))
+	for i, value := range values {
+		cells[i] = value
+	}
+	return w.stream.SetRow(cell, cells)
+}
+
+// Close finishes the sheet, writes the workbook and removes its temporary files
+func (w *XLSXWriter) Close() error {
+	defer w.file.Close()
+
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+	_, err := w.file.WriteTo(w.out)
+	return err
+}
@@ -0,0 +1,252 @@
+// Package factories builds realistic records for seeding and tests. Values are derived from a
+// seed, so the same seed and the same sequence of calls always produce the same records.
+//
+// Every factory takes overrides that run after the defaults are filled in:
+//
+//	f := factories.New(db, "demo")
+//	admin, err := f.CreateUser(func(u *models.Users) { u.Role = models.RoleAdmin })
+package factories
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/anonymize"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Password is the password of every user built by the factories
+const Password = "password123"
+
+var productAdjectives = []string{
+	"Classic", "Organic", "Spicy", "Iced", "Roasted", "Fresh", "Smoked", "Crispy", "Vanilla", "Golden",
+	"Wild", "Toasted", "Sparkling", "Dark", "Honey", "Mini", "Double", "Seasonal", "Lemon", "Herbal",
+}
+
+var productNouns = []string{
+	"Latte", "Bagel", "Burrito", "Salad", "Croissant", "Smoothie", "Espresso", "Wrap", "Muffin", "Soup",
+	"Sandwich", "Tea", "Cookie", "Pizza Slice", "Granola Bar", "Lemonade", "Noodle Bowl", "Taco", "Brownie", "Juice",
+}
+
+var categoryNames = []string{
+	"Beverages", "Bakery", "Snacks", "Hot Food", "Cold Food", "Desserts", "Breakfast", "Merchandise",
+}
+
+// Factory builds and inserts records
+type Factory struct {
+	db    *gorm.DB
+	faker *anonymize.Faker
+	rand  *rand.Rand
+	// Records built so far, per kind; numbers records and keeps unique fields unique
+	counts map[string]int
+	// Bcrypt hash of Password, computed once
+	passwordHash string
+}
+
+// New creates a factory inserting into db. db may be nil when records are only built.
+func New(db *gorm.DB, seed string) *Factory {
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	return &Factory{
+		db:     db,
+		faker:  anonymize.NewFaker(seed),
+		rand:   rand.New(rand.NewSource(int64(h.Sum64()))),
+		counts: map[string]int{},
+	}
+}
+
+// next returns the number of the next record of a kind, starting at 1
+func (f *Factory) next(kind string) int {
+	f.counts[kind]++
+	return f.counts[kind]
+}
+
+// pick returns a random item of the list
+func (f *Factory) pick(items []string) string {
+	return items[f.rand.Intn(len(items))]
+}
+
+// hashedPassword returns the bcrypt hash of Password. The minimum cost keeps large seeds fast.
+func (f *Factory) hashedPassword() (string, error) {
+	if f.passwordHash == "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(Password), bcrypt.MinCost)
+		if err != nil {
+			return "", err
+		}
+		f.passwordHash = string(hash)
+	}
+	return f.passwordHash, nil
+}
+
+// create inserts a built record
+func (f *Factory) create(record interface{}) error {
+	if f.db == nil {
+		return fmt.Errorf("factories: no database to create %T in", record)
+	}
+	return f.db.Create(record).Error
+}
+
+// BuildUser builds an active user who can log in with Password
+func (f *Factory) BuildUser(overrides ...func(*models.Users)) (models.Users, error) {
+	n := f.next("user")
+	key := fmt.Sprintf("user-%d", n)
+
+	password, err := f.hashedPassword()
+	if err != nil {
+		return models.Users{}, err
+	}
+
+	// Username and email follow the name, numbered so they stay unique
+	name := f.faker.Value(anonymize.KindName, key)
+	handle := fmt.Sprintf("%s%d", strings.ReplaceAll(strings.ToLower(name), " ", "."), n)
+
+	user := models.Users{
+		Username: handle,
+		Email:    handle + "@example.com",
+		Name:     name,
+		Password: password,
+		Role:     models.RoleUser,
+		Status:   models.UserStatusActive,
+	}
+	for _, override := range overrides {
+		override(&user)
+	}
+	return user, nil
+}
+
+// CreateUser builds and inserts a user
+func (f *Factory) CreateUser(overrides ...func(*models.Users)) (*models.Users, error) {
+	user, err := f.BuildUser(overrides...)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.create(&user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// BuildCategory builds a top-level category
+func (f *Factory) BuildCategory(overrides ...func(*models.Categories)) models.Categories {
+	n := f.next("category")
+
+	name := categoryNames[(n-1)%len(categoryNames)]
+	if n > len(categoryNames) {
+		name = fmt.Sprintf("%s %d", name, (n-1)/len(categoryNames)+1)
+	}
+
+	category := models.Categories{
+		Name:     name,
+		Position: n - 1,
+	}
+	for _, override := range overrides {
+		override(&category)
+	}
+	return category
+}
+
+// CreateCategory builds and inserts a category
+func (f *Factory) CreateCategory(overrides ...func(*models.Categories)) (*models.Categories, error) {
+	category := f.BuildCategory(overrides...)
+	if err := f.create(&category); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// BuildProduct builds an active, uncategorized product with a unique SKU and barcode. Prices
+// are between 1.00 and 25.00 with a cost of 30-60% of the price.
+func (f *Factory) BuildProduct(overrides ...func(*models.Products)) models.Products {
+	n := f.next("product")
+
+	adjective := f.pick(productAdjectives)
+	noun := f.pick(productNouns)
+	price := int64(100 + f.rand.Intn(2401))
+	cost := price * int64(30+f.rand.Intn(31)) / 100
+
+	product := models.Products{
+		Name:        adjective + " " + noun,
+		SKU:         fmt.Sprintf("SKU-%06d", n),
+		Barcode:     ean13(fmt.Sprintf("200%09d", n)),
+		Description: fmt.Sprintf("%s %s, made fresh daily.", adjective, strings.ToLower(noun)),
+		Price:       price,
+		Cost:        cost,
+		Status:      models.ProductStatusActive,
+	}
+	for _, override := range overrides {
+		override(&product)
+	}
+	return product
+}
+
+// CreateProduct builds and inserts a product
+func (f *Factory) CreateProduct(overrides ...func(*models.Products)) (*models.Products, error) {
+	product := f.BuildProduct(overrides...)
+	if err := f.create(&product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// BuildOrder builds a placed order selling 1 to 3 of each product at its price, with totals
+// computed from the lines. Orders built by the factories take no stock.
+func (f *Factory) BuildOrder(products []models.Products, overrides ...func(*models.Orders)) models.Orders {
+	n := f.next("order")
+
+	// Placed when it is built, like CreatedAt
+	placedAt := time.Now()
+	order := models.Orders{
+		Number:   fmt.Sprintf("ORD-%06d", n),
+		Status:   models.OrderStatusPlaced,
+		PlacedAt: &placedAt,
+	}
+	for _, product := range products {
+		quantity := int64(1 + f.rand.Intn(3))
+		item := models.OrderItems{
+			ProductID: product.ID,
+			Name:      product.Name,
+			SKU:       product.SKU,
+			Quantity:  quantity,
+			UnitPrice: product.Price,
+			UnitCost:  product.Cost,
+			Subtotal:  quantity * product.Price,
+			Total:     quantity * product.Price,
+		}
+		order.Items = append(order.Items, item)
+		order.ItemCount += item.Quantity
+		order.Subtotal += item.Subtotal
+		order.Total += item.Total
+	}
+	for _, override := range overrides {
+		override(&order)
+	}
+	return order
+}
+
+// CreateOrder builds and inserts an order with its items
+func (f *Factory) CreateOrder(products []models.Products, overrides ...func(*models.Orders)) (*models.Orders, error) {
+	order := f.BuildOrder(products, overrides...)
+	if err := f.create(&order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// ean13 appends the check digit to a 12 digit code. Codes starting with 2 are reserved for
+// in-store use, so generated barcodes never clash with real products.
+func ean13(code string) string {
+	sum := 0
+	for i, r := range code {
+		digit := int(r - '0')
+		if i%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return fmt.Sprintf("%s%d", code, (10-sum%10)%10)
+}
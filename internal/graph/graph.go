@@ -0,0 +1,174 @@
+// Package graph serves the GraphQL API of the dashboard, so a dashboard can fetch users,
+// products, orders and reports in one request instead of one REST call each. Queries are
+// resolved by the services behind the REST routes.
+package graph
+
+//go:generate go run github.com/99designs/gqlgen generate
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"gorm.io/gorm"
+)
+
+// NewSchema returns the executable schema, resolving queries with the services and authorizing
+// fields with the permissions
+func NewSchema(userService *services.UserService, productService *services.ProductService, orderService *services.OrderService, reportService *services.ReportService, permissionService *services.PermissionService) graphql.ExecutableSchema {
+	resolver := &Resolver{
+		userService:       userService,
+		productService:    productService,
+		orderService:      orderService,
+		reportService:     reportService,
+		permissionService: permissionService,
+	}
+	return NewExecutableSchema(Config{
+		Resolvers: resolver,
+		Directives: DirectiveRoot{
+			HasRole:       hasRole,
+			HasPermission: resolver.hasPermission,
+		},
+	})
+}
+
+// userKey is the context key of the signed in user
+type userKey struct{}
+
+// WithUser returns the context of a query sent by a signed in user
+func WithUser(ctx context.Context, user models.RegisterResponse) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFrom returns the user who sent the query
+func UserFrom(ctx context.Context) (models.RegisterResponse, bool) {
+	user, ok := ctx.Value(userKey{}).(models.RegisterResponse)
+	return user, ok
+}
+
+// hasRole resolves the field for users with one of the roles, as middleware.RequireRole
+func hasRole(ctx context.Context, obj interface{}, next graphql.Resolver, roles []string) (interface{}, error) {
+	user, ok := UserFrom(ctx)
+	if !ok {
+		return nil, newError("Authentication required", common.CodeUnauthorized, nil)
+	}
+	if !slices.Contains(roles, user.Role) {
+		return nil, newError("Insufficient permissions", common.CodeForbidden, nil)
+	}
+	return next(ctx)
+}
+
+// hasPermission resolves the field for users whose role holds the permission, as
+// middleware.RequirePermission
+func (r *Resolver) hasPermission(ctx context.Context, obj interface{}, next graphql.Resolver, permission string) (interface{}, error) {
+	user, ok := UserFrom(ctx)
+	if !ok {
+		return nil, newError("Authentication required", common.CodeUnauthorized, nil)
+	}
+	allowed, err := r.permissionService.HasPermission(user.Role, permission)
+	if err != nil {
+		return nil, internalError(ctx, "GraphQL: failed to check permission", err)
+	}
+	if !allowed {
+		return nil, newError("Insufficient permissions", common.CodeForbidden, map[string]interface{}{
+			"permission": permission,
+		})
+	}
+	return next(ctx)
+}
+
+// newError returns an error of the field being resolved with the code and details the REST
+// API would answer with, in the error's extensions
+func newError(message string, code string, details map[string]interface{}) *gqlerror.Error {
+	extensions := map[string]interface{}{"code": code}
+	if details != nil {
+		extensions["details"] = details
+	}
+	return &gqlerror.Error{Message: message, Extensions: extensions}
+}
+
+// internalError logs an unexpected error and returns one that doesn't leak it to the client
+func internalError(ctx context.Context, message string, err error) *gqlerror.Error {
+	slog.ErrorContext(ctx, message, "path", graphql.GetPath(ctx).String(), "error", err)
+	return newError("Internal server error", common.CodeInternalError, nil)
+}
+
+// user returns the user with the ID, or nil when there is none
+func (r *Resolver) user(ctx context.Context, id uint) (*models.Users, error) {
+	result, err := r.userService.GetUserById(id, nil)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, internalError(ctx, "GraphQL: failed to fetch user", err)
+	}
+	user, _ := result.(models.Users)
+	return &user, nil
+}
+
+// inStoreScope reports whether a record of the store may be seen by the request, which may be
+// limited to some stores like the REST routes
+func inStoreScope(ctx context.Context, storeID *uint) bool {
+	storeIDs, ok := pagination.StoresFromContext(ctx)
+	return !ok || storeID != nil && slices.Contains(storeIDs, *storeID)
+}
+
+// queryParams converts page arguments into the query parameters of the REST listings, with
+// the same defaults and limits
+func queryParams(ctx context.Context, page *PageInput) (pagination.QueryParams, error) {
+	params := pagination.QueryParams{Page: 1, PageSize: 10}
+	if page != nil {
+		if page.Page != nil {
+			params.Page = *page.Page
+		}
+		if page.PageSize != nil {
+			params.PageSize = *page.PageSize
+		}
+		if page.Search != nil {
+			params.Search = *page.Search
+		}
+		if page.SortBy != nil {
+			params.SortBy = *page.SortBy
+		}
+		if page.SortDesc != nil {
+			params.SortDesc = *page.SortDesc
+		}
+		params.Filters = page.Filters
+	}
+	if params.Page < 1 || params.PageSize < 1 {
+		return params, newError("Invalid page arguments", common.CodeInvalidRequest, map[string]interface{}{
+			"page":     params.Page,
+			"pageSize": params.PageSize,
+		})
+	}
+
+	if err := params.BindContext(ctx); err != nil {
+		if exceeded, ok := limits.AsExceeded(err); ok {
+			return params, newError("Limit exceeded", common.CodeLimitExceeded, map[string]interface{}{
+				"limit":  exceeded.Limit,
+				"max":    exceeded.Max,
+				"actual": exceeded.Actual,
+			})
+		}
+		return params, newError("Invalid page arguments", common.CodeInvalidRequest, nil)
+	}
+	return params, nil
+}
+
+// pointers returns pointers to the rows of a page of models, as the generated code wants them
+func pointers[T any](data interface{}) []*T {
+	rows, _ := data.([]T)
+	result := make([]*T, len(rows))
+	for i := range rows {
+		result[i] = &rows[i]
+	}
+	return result
+}
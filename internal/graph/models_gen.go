@@ -0,0 +1,61 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package graph
+
+import (
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+)
+
+type OrderPage struct {
+	Data       []*models.Orders `json:"data"`
+	Total      int              `json:"total"`
+	Page       int              `json:"page"`
+	PageSize   int              `json:"pageSize"`
+	TotalPages int              `json:"totalPages"`
+}
+
+// Paging, search, filters and sorting of a list, as the REST listing's query parameters
+type PageInput struct {
+	Page     *int    `json:"page,omitempty"`
+	PageSize *int    `json:"pageSize,omitempty"`
+	Search   *string `json:"search,omitempty"`
+	SortBy   *string `json:"sortBy,omitempty"`
+	SortDesc *bool   `json:"sortDesc,omitempty"`
+	// Exact match filters by field, e.g. {status: "paid"}
+	Filters map[string]any `json:"filters,omitempty"`
+}
+
+type ProductPage struct {
+	Data       []*models.Products `json:"data"`
+	Total      int                `json:"total"`
+	Page       int                `json:"page"`
+	PageSize   int                `json:"pageSize"`
+	TotalPages int                `json:"totalPages"`
+}
+
+type Query struct {
+}
+
+type ReportPage struct {
+	Data       []*models.ReportDefinitions `json:"data"`
+	Total      int                         `json:"total"`
+	Page       int                         `json:"page"`
+	PageSize   int                         `json:"pageSize"`
+	TotalPages int                         `json:"totalPages"`
+}
+
+type ReportResult struct {
+	Rows       []map[string]any `json:"rows"`
+	Total      int              `json:"total"`
+	Page       int              `json:"page"`
+	PageSize   int              `json:"pageSize"`
+	TotalPages int              `json:"totalPages"`
+}
+
+type UserPage struct {
+	Data       []*models.Users `json:"data"`
+	Total      int             `json:"total"`
+	Page       int             `json:"page"`
+	PageSize   int             `json:"pageSize"`
+	TotalPages int             `json:"totalPages"`
+}
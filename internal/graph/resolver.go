@@ -0,0 +1,13 @@
+package graph
+
+import "github.com/Aebroyx/the-blade-api/internal/services"
+
+// Resolver resolves queries with the services of the REST API. The resolvers of each field are
+// in schema.resolvers.go.
+type Resolver struct {
+	userService       *services.UserService
+	productService    *services.ProductService
+	orderService      *services.OrderService
+	reportService     *services.ReportService
+	permissionService *services.PermissionService
+}
@@ -0,0 +1,231 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.66
+
+import (
+	"context"
+
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+)
+
+// CreatedBy is the resolver for the createdBy field.
+func (r *orderResolver) CreatedBy(ctx context.Context, obj *models.Orders) (*models.Users, error) {
+	if obj.CreatedByID == nil {
+		return nil, nil
+	}
+	return r.user(ctx, *obj.CreatedByID)
+}
+
+// Items is the resolver for the items field.
+func (r *orderResolver) Items(ctx context.Context, obj *models.Orders) ([]*models.OrderItems, error) {
+	// Listings don't load the items, so they are fetched with the order when selected
+	items := obj.Items
+	if items == nil {
+		order, err := r.orderService.GetOrderById(obj.ID)
+		if err != nil {
+			return nil, internalError(ctx, "GraphQL: failed to fetch order items", err)
+		}
+		items = order.Items
+	}
+	return pointers[models.OrderItems](items), nil
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*models.Users, error) {
+	user, ok := UserFrom(ctx)
+	if !ok {
+		return nil, newError("Authentication required", common.CodeUnauthorized, nil)
+	}
+	me, err := r.user(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if me == nil {
+		return nil, newError("User not found", common.CodeNotFound, nil)
+	}
+	return me, nil
+}
+
+// Users is the resolver for the users field.
+func (r *queryResolver) Users(ctx context.Context, page *PageInput) (*UserPage, error) {
+	params, err := queryParams(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+	response, err := r.userService.GetAllUsers(params, nil)
+	if err != nil {
+		return nil, internalError(ctx, "GraphQL: failed to fetch users", err)
+	}
+	return &UserPage{
+		Data:       pointers[models.Users](response.Data),
+		Total:      int(response.Total),
+		Page:       response.Page,
+		PageSize:   response.PageSize,
+		TotalPages: response.TotalPages,
+	}, nil
+}
+
+// User is the resolver for the user field.
+func (r *queryResolver) User(ctx context.Context, id uint) (*models.Users, error) {
+	return r.user(ctx, id)
+}
+
+// Products is the resolver for the products field.
+func (r *queryResolver) Products(ctx context.Context, page *PageInput) (*ProductPage, error) {
+	params, err := queryParams(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+	response, err := r.productService.GetAllProducts(params, "")
+	if err != nil {
+		return nil, internalError(ctx, "GraphQL: failed to fetch products", err)
+	}
+	return &ProductPage{
+		Data:       pointers[models.Products](response.Data),
+		Total:      int(response.Total),
+		Page:       response.Page,
+		PageSize:   response.PageSize,
+		TotalPages: response.TotalPages,
+	}, nil
+}
+
+// Product is the resolver for the product field.
+func (r *queryResolver) Product(ctx context.Context, id uint) (*models.Products, error) {
+	product, err := r.productService.GetProductById(id)
+	if err != nil {
+		if err.Error() == "product not found" {
+			return nil, nil
+		}
+		return nil, internalError(ctx, "GraphQL: failed to fetch product", err)
+	}
+	return product, nil
+}
+
+// Orders is the resolver for the orders field.
+func (r *queryResolver) Orders(ctx context.Context, page *PageInput) (*OrderPage, error) {
+	params, err := queryParams(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+	response, err := r.orderService.GetAllOrders(params)
+	if err != nil {
+		return nil, internalError(ctx, "GraphQL: failed to fetch orders", err)
+	}
+	return &OrderPage{
+		Data:       pointers[models.Orders](response.Data),
+		Total:      int(response.Total),
+		Page:       response.Page,
+		PageSize:   response.PageSize,
+		TotalPages: response.TotalPages,
+	}, nil
+}
+
+// Order is the resolver for the order field.
+func (r *queryResolver) Order(ctx context.Context, id uint) (*models.Orders, error) {
+	order, err := r.orderService.GetOrderById(id)
+	if err != nil {
+		if err.Error() == "order not found" {
+			return nil, nil
+		}
+		return nil, internalError(ctx, "GraphQL: failed to fetch order", err)
+	}
+	if !inStoreScope(ctx, order.StoreID) {
+		return nil, nil
+	}
+	return order, nil
+}
+
+// Reports is the resolver for the reports field.
+func (r *queryResolver) Reports(ctx context.Context, page *PageInput) (*ReportPage, error) {
+	params, err := queryParams(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+	response, err := r.reportService.GetAllReportDefinitions(params)
+	if err != nil {
+		return nil, internalError(ctx, "GraphQL: failed to fetch reports", err)
+	}
+	return &ReportPage{
+		Data:       pointers[models.ReportDefinitions](response.Data),
+		Total:      int(response.Total),
+		Page:       response.Page,
+		PageSize:   response.PageSize,
+		TotalPages: response.TotalPages,
+	}, nil
+}
+
+// Report is the resolver for the report field.
+func (r *queryResolver) Report(ctx context.Context, id uint) (*models.ReportDefinitions, error) {
+	definition, err := r.reportService.GetReportDefinitionById(id)
+	if err != nil {
+		if err.Error() == "report not found" {
+			return nil, nil
+		}
+		return nil, internalError(ctx, "GraphQL: failed to fetch report", err)
+	}
+	return definition, nil
+}
+
+// Dimensions is the resolver for the dimensions field.
+func (r *reportResolver) Dimensions(ctx context.Context, obj *models.ReportDefinitions) ([]string, error) {
+	return obj.Dimensions, nil
+}
+
+// Measures is the resolver for the measures field.
+func (r *reportResolver) Measures(ctx context.Context, obj *models.ReportDefinitions) ([]string, error) {
+	return obj.Measures, nil
+}
+
+// Filters is the resolver for the filters field.
+func (r *reportResolver) Filters(ctx context.Context, obj *models.ReportDefinitions) (map[string]any, error) {
+	return obj.Filters, nil
+}
+
+// Run is the resolver for the run field.
+func (r *reportResolver) Run(ctx context.Context, obj *models.ReportDefinitions, page *PageInput) (*ReportResult, error) {
+	params, err := queryParams(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+	response, err := r.reportService.RunReport(obj.ID, params)
+	if err != nil {
+		switch err.Error() {
+		case "report entity is no longer available", "report references an unknown dimension", "report references an unknown measure":
+			return nil, newError(err.Error(), common.CodeValidationError, nil)
+		}
+		return nil, internalError(ctx, "GraphQL: failed to run report", err)
+	}
+	rows, _ := response.Data.([]map[string]interface{})
+	return &ReportResult{
+		Rows:       rows,
+		Total:      int(response.Total),
+		Page:       response.Page,
+		PageSize:   response.PageSize,
+		TotalPages: response.TotalPages,
+	}, nil
+}
+
+// Metadata is the resolver for the metadata field.
+func (r *userResolver) Metadata(ctx context.Context, obj *models.Users) (map[string]any, error) {
+	return obj.Metadata, nil
+}
+
+// Order returns OrderResolver implementation.
+func (r *Resolver) Order() OrderResolver { return &orderResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// Report returns ReportResolver implementation.
+func (r *Resolver) Report() ReportResolver { return &reportResolver{r} }
+
+// User returns UserResolver implementation.
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+type orderResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type reportResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ActivityHandler struct {
+	activityService *services.ActivityService
+}
+
+func NewActivityHandler(activityService *services.ActivityService) *ActivityHandler {
+	return &ActivityHandler{
+		activityService: activityService,
+	}
+}
+
+// activityActor describes the authenticated user and client making the request
+func activityActor(c *gin.Context) models.ActivityActor {
+	actor := models.ActivityActor{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if user, ok := middleware.CurrentUser(c); ok {
+		actor.UserID = user.ID
+	}
+	return actor
+}
+
+// GetUserActivity handles GET /api/user/:id/activity
+func (h *ActivityHandler) GetUserActivity(c *gin.Context) {
+	userID, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	h.sendActivity(c, userID)
+}
+
+// GetMyActivity handles GET /api/me/activity
+func (h *ActivityHandler) GetMyActivity(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		common.SendError(c, http.StatusUnauthorized, "Unauthorized", common.CodeUnauthorized, nil)
+		return
+	}
+
+	h.sendActivity(c, user.ID)
+}
+
+// GetUserLogins handles GET /api/user/:id/logins
+func (h *ActivityHandler) GetUserLogins(c *gin.Context) {
+	userID, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.activityService.GetUserLogins(userID, params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch login history", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Login history fetched successfully", response)
+}
+
+// sendActivity binds the pagination parameters and sends the user's activity timeline
+func (h *ActivityHandler) sendActivity(c *gin.Context, userID uint) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.activityService.GetUserActivity(userID, params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch activity", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Activity fetched successfully", response)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type AnalyticsHandler struct {
+	analyticsService *services.AnalyticsService
+	validate         *validator.Validate
+}
+
+func NewAnalyticsHandler(analyticsService *services.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: analyticsService,
+		validate:         validator.New(),
+	}
+}
+
+// sendAnalyticsError maps analytics service errors to API responses
+func sendAnalyticsError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "invalid date range":
+		common.SendError(c, http.StatusBadRequest, "The range must start before it ends", common.CodeValidationError, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch analytics", common.CodeInternalError, nil)
+	}
+}
+
+// GetSalesAnalytics handles GET /api/analytics/sales
+func (h *AnalyticsHandler) GetSalesAnalytics(c *gin.Context) {
+	var query models.SalesAnalyticsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(query); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	response, err := h.analyticsService.GetSalesAnalytics(c.Request.Context(), &query)
+	if err != nil {
+		sendAnalyticsError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Sales analytics fetched successfully", response)
+}
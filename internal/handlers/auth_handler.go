@@ -4,13 +4,24 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Aebroyx/the-blade-api/internal/common"
 	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
 	"github.com/Aebroyx/the-blade-api/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
 
+// cookieProfile returns the cookie domain and secure flag for the tenant serving the request.
+// Hosts without a tenant get host-only, non-secure cookies.
+func cookieProfile(c *gin.Context) (string, bool) {
+	if tenant, ok := middleware.CurrentTenant(c); ok {
+		return tenant.CookieDomain, tenant.CookieSecure
+	}
+	return "", false
+}
+
 type AuthHandler struct {
 	userService *services.UserService
 	validate    *validator.Validate
@@ -39,7 +50,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	// Register user
-	user, err := h.userService.Register(&req)
+	user, err := h.userService.Register(&req, activityActor(c))
 	if err != nil {
 		switch err.Error() {
 		case "username already exists":
@@ -56,6 +67,33 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	c.JSON(http.StatusCreated, user)
 }
 
+// CheckAvailability handles GET /api/auth/check-availability.
+// Anyone can check a username; email availability is only answered for administrators.
+func (h *AuthHandler) CheckAvailability(c *gin.Context) {
+	var query models.AvailabilityQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	// Validate query parameters
+	if err := h.validate.Struct(query); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	user, ok := middleware.CurrentUser(c)
+	includeEmail := ok && user.Role == models.RoleAdmin
+
+	response, err := h.userService.CheckAvailability(&query, includeEmail)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to check availability", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Availability checked successfully", response)
+}
+
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req models.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -70,26 +108,32 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Login user
-	response, err := h.userService.Login(&req)
+	response, err := h.userService.Login(&req, activityActor(c))
 	if err != nil {
 		switch err.Error() {
 		case "invalid username or password":
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+		case "account suspended":
+			common.SendError(c, http.StatusForbidden, "Account suspended", common.CodeAccountSuspended, nil)
+		case "account pending":
+			common.SendError(c, http.StatusForbidden, "Account pending activation", common.CodeAccountPending, nil)
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		}
 		return
 	}
 
+	domain, secure := cookieProfile(c)
+
 	// Set access token cookie
 	c.SetCookie(
 		"access_token",
 		response.Token.AccessToken,
 		int(response.Token.ExpiresIn),
-		"/",   // path
-		"",    // domain (empty for current domain)
-		false, // secure (set to false for development)
-		true,  // httpOnly
+		"/",    // path
+		domain, // domain (tenant cookie domain, empty for current domain)
+		secure, // secure (tenant setting, false for development)
+		true,   // httpOnly
 	)
 
 	// Set refresh token cookie (7 days)
@@ -98,8 +142,8 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		response.Token.RefreshToken,
 		int(7*24*time.Hour.Seconds()), // 7 days
 		"/",                           // path
-		"",                            // domain (empty for current domain)
-		false,                         // secure (set to false for development)
+		domain,                        // domain (tenant cookie domain, empty for current domain)
+		secure,                        // secure (tenant setting, false for development)
 		true,                          // httpOnly
 	)
 
@@ -110,26 +154,28 @@ func (h *AuthHandler) Login(c *gin.Context) {
 }
 
 func (h *AuthHandler) Logout(c *gin.Context) {
+	domain, secure := cookieProfile(c)
+
 	// Clear access token cookie by setting it to expire immediately
 	c.SetCookie(
 		"access_token",
 		"",
-		-1,    // MaxAge -1 means delete immediately
-		"/",   // path
-		"",    // domain (empty for current domain)
-		false, // secure (set to false for development)
-		true,  // httpOnly
+		-1,     // MaxAge -1 means delete immediately
+		"/",    // path
+		domain, // domain (tenant cookie domain, empty for current domain)
+		secure, // secure (tenant setting, false for development)
+		true,   // httpOnly
 	)
 
 	// Clear refresh token cookie
 	c.SetCookie(
 		"refresh_token",
 		"",
-		-1,    // MaxAge -1 means delete immediately
-		"/",   // path
-		"",    // domain (empty for current domain)
-		false, // secure (set to false for development)
-		true,  // httpOnly
+		-1,     // MaxAge -1 means delete immediately
+		"/",    // path
+		domain, // domain (tenant cookie domain, empty for current domain)
+		secure, // secure (tenant setting, false for development)
+		true,   // httpOnly
 	)
 
 	c.JSON(http.StatusOK, gin.H{
@@ -146,3 +192,99 @@ func (h *AuthHandler) GetMe(c *gin.Context) {
 
 	c.JSON(http.StatusOK, user)
 }
+
+// ConfirmEmail handles POST /api/auth/confirm-email with the token from the confirmation link
+func (h *AuthHandler) ConfirmEmail(c *gin.Context) {
+	var req models.ConfirmEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	user, err := h.userService.ConfirmEmailChange(req.Token, activityActor(c))
+	if err != nil {
+		switch err.Error() {
+		case "invalid email confirmation token":
+			common.SendError(c, http.StatusBadRequest, "Invalid email confirmation token", common.CodeInvalidRequest, nil)
+		case "email confirmation token expired":
+			common.SendError(c, http.StatusGone, "Email confirmation link has expired", common.CodeInvalidRequest, nil)
+		case "email already exists":
+			common.SendError(c, http.StatusConflict, "Email already exists", common.CodeEmailExists, nil)
+		default:
+			common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		}
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Email confirmed successfully", user)
+}
+
+// ResetPassword handles POST /api/auth/reset-password with the token from a reset link
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	if err := h.userService.ResetPassword(req.Token, req.Password, activityActor(c)); err != nil {
+		switch err.Error() {
+		case "invalid password reset token":
+			common.SendError(c, http.StatusBadRequest, "Invalid password reset token", common.CodeInvalidResetToken, nil)
+		case "password reset token expired":
+			common.SendError(c, http.StatusGone, "Password reset link has expired", common.CodeInvalidResetToken, nil)
+		default:
+			common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		}
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Password reset successfully", nil)
+}
+
+// ChangePassword handles PUT /api/me/password
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		common.SendError(c, http.StatusUnauthorized, "Unauthorized", common.CodeUnauthorized, nil)
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	if err := h.userService.ChangePassword(user.ID, &req, activityActor(c)); err != nil {
+		switch err.Error() {
+		case "user not found":
+			common.SendError(c, http.StatusNotFound, "User not found", common.CodeNotFound, nil)
+		case "current password is incorrect":
+			common.SendError(c, http.StatusBadRequest, "Current password is incorrect", common.CodeValidationError, nil)
+		default:
+			common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		}
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Password changed successfully", nil)
+}
@@ -0,0 +1,396 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type CartHandler struct {
+	cartService *services.CartService
+	validate    *validator.Validate
+}
+
+func NewCartHandler(cartService *services.CartService) *CartHandler {
+	return &CartHandler{
+		cartService: cartService,
+		validate:    validator.New(),
+	}
+}
+
+// sendCartError maps cart service errors to API responses
+func sendCartError(c *gin.Context, err error) {
+	if exceeded, ok := limits.AsExceeded(err); ok {
+		common.SendError(c, http.StatusUnprocessableEntity, "Limit exceeded", common.CodeLimitExceeded, exceeded)
+		return
+	}
+
+	switch err.Error() {
+	case "cart line not found":
+		common.SendError(c, http.StatusNotFound, "Cart line not found", common.CodeNotFound, nil)
+	case "cart is empty":
+		common.SendError(c, http.StatusBadRequest, "Cart is empty", common.CodeValidationError, nil)
+	case "parked sale not found":
+		common.SendError(c, http.StatusNotFound, "Parked sale not found", common.CodeNotFound, nil)
+	case "cart not empty":
+		common.SendError(c, http.StatusConflict, "The cart has a sale in progress; park or clear it first", common.CodeConflict, nil)
+	default:
+		sendCouponError(c, err)
+	}
+}
+
+// cartOwner returns whose cart the request works on: the ticket of the table resolved by
+// TableHandler.TicketTable, the terminal's cart, or the signed-in user's
+func cartOwner(c *gin.Context) (models.CartOwner, bool) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		common.SendError(c, http.StatusUnauthorized, "Unauthorized", common.CodeUnauthorized, nil)
+		return models.CartOwner{}, false
+	}
+
+	owner := models.CartOwner{UserID: user.ID}
+	if device, ok := middleware.CurrentDevice(c); ok {
+		owner.DeviceID = &device.ID
+		owner.Location = device.Location
+	}
+	if store, ok := middleware.CurrentStore(c); ok {
+		owner.StoreID = &store.ID
+	}
+	if value, ok := c.Get(ticketTableKey); ok {
+		owner.TableID = &value.(*models.DiningTables).ID
+	}
+	return owner, true
+}
+
+// GetCart handles GET /api/cart
+func (h *CartHandler) GetCart(c *gin.Context) {
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+
+	cart, err := h.cartService.GetCart(c.Request.Context(), owner)
+	if err != nil {
+		sendCartError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Cart fetched successfully", cart)
+}
+
+// UpdateCart handles PUT /api/cart
+func (h *CartHandler) UpdateCart(c *gin.Context) {
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	cart, err := h.cartService.UpdateCart(c.Request.Context(), owner, &req)
+	if err != nil {
+		sendCartError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Cart updated successfully", cart)
+}
+
+// ClearCart handles DELETE /api/cart
+func (h *CartHandler) ClearCart(c *gin.Context) {
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+
+	if err := h.cartService.ClearCart(c.Request.Context(), owner); err != nil {
+		sendCartError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Cart cleared successfully", nil)
+}
+
+// AddItem handles POST /api/cart/items
+func (h *CartHandler) AddItem(c *gin.Context) {
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+
+	var req models.AddCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	cart, err := h.cartService.AddItem(c.Request.Context(), owner, &req)
+	if err != nil {
+		sendCartError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Item added to cart successfully", cart)
+}
+
+// UpdateItem handles PUT /api/cart/items/:lineId
+func (h *CartHandler) UpdateItem(c *gin.Context) {
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+	lineID, ok := binding.ID(c, "lineId")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	cart, err := h.cartService.UpdateItem(c.Request.Context(), owner, lineID, &req)
+	if err != nil {
+		sendCartError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Cart item updated successfully", cart)
+}
+
+// RemoveItem handles DELETE /api/cart/items/:lineId
+func (h *CartHandler) RemoveItem(c *gin.Context) {
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+	lineID, ok := binding.ID(c, "lineId")
+	if !ok {
+		return
+	}
+
+	cart, err := h.cartService.RemoveItem(c.Request.Context(), owner, lineID)
+	if err != nil {
+		sendCartError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Cart item removed successfully", cart)
+}
+
+// SetCustomer handles PUT /api/cart/customer
+func (h *CartHandler) SetCustomer(c *gin.Context) {
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+
+	var req models.SetCustomerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	cart, err := h.cartService.SetCustomer(c.Request.Context(), owner, &req)
+	if err != nil {
+		sendCartError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Cart customer updated successfully", cart)
+}
+
+// ApplyCoupon handles POST /api/cart/apply-coupon
+func (h *CartHandler) ApplyCoupon(c *gin.Context) {
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+
+	var req models.ApplyCouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	cart, err := h.cartService.ApplyCoupon(c.Request.Context(), owner, &req)
+	if err != nil {
+		sendCartError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Coupon applied successfully", cart)
+}
+
+// RemoveCoupon handles DELETE /api/cart/coupon
+func (h *CartHandler) RemoveCoupon(c *gin.Context) {
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+
+	cart, err := h.cartService.RemoveCoupon(c.Request.Context(), owner)
+	if err != nil {
+		sendCartError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Coupon removed successfully", cart)
+}
+
+// Checkout handles POST /api/cart/checkout
+func (h *CartHandler) Checkout(c *gin.Context) {
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.cartService.Checkout(c.Request.Context(), owner, activityActor(c))
+	if err != nil {
+		sendCartError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Order placed successfully", order)
+}
+
+// ParkCart handles POST /api/cart/park
+func (h *CartHandler) ParkCart(c *gin.Context) {
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+
+	var req models.ParkCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	cart, err := h.cartService.ParkCart(c.Request.Context(), owner, &req)
+	if err != nil {
+		sendCartError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Sale parked successfully", cart)
+}
+
+// GetParkedSales handles GET /api/parked-sales
+func (h *CartHandler) GetParkedSales(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.cartService.GetParkedSales(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch parked sales", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Parked sales fetched successfully", response)
+}
+
+// GetParkedSale handles GET /api/parked-sales/:id
+func (h *CartHandler) GetParkedSale(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+
+	cart, err := h.cartService.GetParkedSale(c.Request.Context(), owner, id)
+	if err != nil {
+		sendCartError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Parked sale fetched successfully", cart)
+}
+
+// ResumeParkedSale handles POST /api/parked-sales/:id/resume
+func (h *CartHandler) ResumeParkedSale(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+
+	cart, err := h.cartService.ResumeParkedSale(c.Request.Context(), owner, id)
+	if err != nil {
+		sendCartError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Sale resumed successfully", cart)
+}
+
+// VoidParkedSale handles DELETE /api/parked-sales/:id
+func (h *CartHandler) VoidParkedSale(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+
+	if err := h.cartService.VoidParkedSale(c.Request.Context(), owner, id); err != nil {
+		sendCartError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Parked sale voided successfully", nil)
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type CashDrawerHandler struct {
+	cashDrawerService *services.CashDrawerService
+	validate          *validator.Validate
+}
+
+func NewCashDrawerHandler(cashDrawerService *services.CashDrawerService) *CashDrawerHandler {
+	return &CashDrawerHandler{
+		cashDrawerService: cashDrawerService,
+		validate:          validator.New(),
+	}
+}
+
+// sendCashDrawerError maps cash drawer service errors to API responses
+func sendCashDrawerError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "cash drawer not found":
+		common.SendError(c, http.StatusNotFound, "Cash drawer not found", common.CodeNotFound, nil)
+	case "no open cash drawer":
+		common.SendError(c, http.StatusNotFound, "You have no open cash drawer", common.CodeNotFound, nil)
+	case "device not found":
+		common.SendError(c, http.StatusBadRequest, "Device not found", common.CodeValidationError, nil)
+	case "device not active":
+		common.SendError(c, http.StatusBadRequest, "Device is not active", common.CodeValidationError, nil)
+	case "paid-out exceeds drawer cash":
+		common.SendError(c, http.StatusBadRequest, "Paid-out exceeds the cash in the drawer", common.CodeValidationError, nil)
+	case "cash drawer belongs to another user":
+		common.SendError(c, http.StatusForbidden, "Cash drawers are operated by the cashier who opened them", common.CodeForbidden, nil)
+	case "cash drawer already open":
+		common.SendError(c, http.StatusConflict, "You already have an open cash drawer", common.CodeConflict, nil)
+	case "cash drawer closed":
+		common.SendError(c, http.StatusConflict, "Cash drawer is closed", common.CodeConflict, nil)
+	case "cash drawer belongs to an open shift":
+		common.SendError(c, http.StatusConflict, "This cash drawer is closed together with its shift", common.CodeConflict, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// GetAllDrawers handles GET /api/cash-drawers
+func (h *CashDrawerHandler) GetAllDrawers(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.cashDrawerService.GetAllDrawers(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch cash drawers", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Cash drawers fetched successfully", response)
+}
+
+// OpenDrawer handles POST /api/cash-drawers/open
+func (h *CashDrawerHandler) OpenDrawer(c *gin.Context) {
+	var req models.OpenCashDrawerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	// Drawers opened from a terminal belong to it, whatever device the body names
+	if device, ok := middleware.CurrentDevice(c); ok {
+		req.DeviceID = &device.ID
+	}
+
+	session, err := h.cashDrawerService.OpenDrawer(&req, activityActor(c))
+	if err != nil {
+		sendCashDrawerError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Cash drawer opened successfully", session)
+}
+
+// GetCurrentDrawer handles GET /api/cash-drawers/current
+func (h *CashDrawerHandler) GetCurrentDrawer(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		common.SendError(c, http.StatusUnauthorized, "Unauthorized", common.CodeUnauthorized, nil)
+		return
+	}
+
+	report, err := h.cashDrawerService.GetCurrentDrawer(user.ID)
+	if err != nil {
+		sendCashDrawerError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Cash drawer fetched successfully", report)
+}
+
+// GetDrawerReport handles GET /api/cash-drawers/:id
+func (h *CashDrawerHandler) GetDrawerReport(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	report, err := h.cashDrawerService.GetDrawerReport(id)
+	if err != nil {
+		sendCashDrawerError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Cash drawer fetched successfully", report)
+}
+
+// RecordMovement handles POST /api/cash-drawers/:id/movements
+func (h *CashDrawerHandler) RecordMovement(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.CashMovementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	movement, err := h.cashDrawerService.RecordMovement(id, &req, activityActor(c))
+	if err != nil {
+		sendCashDrawerError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Cash movement recorded successfully", movement)
+}
+
+// CloseDrawer handles POST /api/cash-drawers/:id/close
+func (h *CashDrawerHandler) CloseDrawer(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.CloseCashDrawerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	report, err := h.cashDrawerService.CloseDrawer(id, &req, activityActor(c))
+	if err != nil {
+		sendCashDrawerError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Cash drawer closed successfully", report)
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type CategoryHandler struct {
+	categoryService *services.CategoryService
+	validate        *validator.Validate
+}
+
+func NewCategoryHandler(categoryService *services.CategoryService) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService: categoryService,
+		validate:        validator.New(),
+	}
+}
+
+// sendCategoryError maps category service errors to API responses
+func sendCategoryError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "category not found":
+		common.SendError(c, http.StatusNotFound, "Category not found", common.CodeNotFound, nil)
+	case "parent category not found":
+		common.SendError(c, http.StatusBadRequest, "Parent category not found", common.CodeValidationError, nil)
+	case "category cannot be moved under itself":
+		common.SendError(c, http.StatusBadRequest, "Category cannot be moved under itself or its subcategories", common.CodeValidationError, nil)
+	case "category ids must list every subcategory once":
+		common.SendError(c, http.StatusBadRequest, "Category IDs must list every subcategory of the parent exactly once", common.CodeValidationError, nil)
+	case "category name already exists":
+		common.SendError(c, http.StatusConflict, "Category name already exists", common.CodeConflict, nil)
+	case "category has subcategories":
+		common.SendError(c, http.StatusConflict, "Category has subcategories", common.CodeConflict, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// GetCategoryTree handles GET /api/categories
+func (h *CategoryHandler) GetCategoryTree(c *gin.Context) {
+	tree, err := h.categoryService.GetCategoryTree()
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch categories", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Categories fetched successfully", tree)
+}
+
+// GetCategoryById handles GET /api/categories/:id
+func (h *CategoryHandler) GetCategoryById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	category, err := h.categoryService.GetCategoryById(id)
+	if err != nil {
+		sendCategoryError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Category fetched successfully", category)
+}
+
+// CreateCategory handles POST /api/categories
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var req models.CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	category, err := h.categoryService.CreateCategory(&req)
+	if err != nil {
+		sendCategoryError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Category created successfully", category)
+}
+
+// UpdateCategory handles PUT /api/categories/:id
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	category, err := h.categoryService.UpdateCategory(id, &req)
+	if err != nil {
+		sendCategoryError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Category updated successfully", category)
+}
+
+// DeleteCategory handles DELETE /api/categories/:id
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	category, err := h.categoryService.DeleteCategory(id)
+	if err != nil {
+		sendCategoryError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Category deleted successfully", category)
+}
+
+// ReorderCategories handles PUT /api/categories/reorder
+func (h *CategoryHandler) ReorderCategories(c *gin.Context) {
+	var req models.ReorderCategoriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	if err := limits.Check(limits.MaxBulkIDs, len(req.CategoryIDs)); err != nil {
+		sendBindError(c, "Invalid request body", err)
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	categories, err := h.categoryService.ReorderCategories(&req)
+	if err != nil {
+		sendCategoryError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Categories reordered successfully", categories)
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// changeStreamWait is how long the stream waits for changes before sending a keepalive comment
+const changeStreamWait = 25 * time.Second
+
+type ChangeFeedHandler struct {
+	changeFeedService *services.ChangeFeedService
+	validate          *validator.Validate
+}
+
+func NewChangeFeedHandler(changeFeedService *services.ChangeFeedService) *ChangeFeedHandler {
+	return &ChangeFeedHandler{
+		changeFeedService: changeFeedService,
+		validate:          validator.New(),
+	}
+}
+
+// sendChangeFeedError maps change feed errors to API responses
+func sendChangeFeedError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "change feed cursor expired":
+		common.SendError(c, http.StatusGone, "Changes since this cursor are no longer available, refetch and start over", common.CodeCursorExpired, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch changes", common.CodeInternalError, nil)
+	}
+}
+
+// GetChanges handles GET /api/changes. It long-polls for up to wait seconds when there are no new changes.
+func (h *ChangeFeedHandler) GetChanges(c *gin.Context) {
+	var query models.ChangeFeedQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(query); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	// Without a cursor, hand out the current position so the client can start following from there
+	if query.Since == nil {
+		latest, err := h.changeFeedService.Latest()
+		if err != nil {
+			sendChangeFeedError(c, err)
+			return
+		}
+		common.SendSuccess(c, http.StatusOK, "Changes fetched successfully", &models.ChangeFeedResponse{
+			Events:  []models.ChangeEvents{},
+			LastSeq: latest,
+		})
+		return
+	}
+
+	response, err := h.changeFeedService.Wait(c.Request.Context(), *query.Since, query.Limit, time.Duration(query.Wait)*time.Second)
+	if err != nil {
+		sendChangeFeedError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Changes fetched successfully", response)
+}
+
+// StreamChanges handles GET /api/changes/stream as server-sent events. Each change is sent as a
+// "change" event whose ID is its sequence number, so reconnecting clients resume through Last-Event-ID.
+func (h *ChangeFeedHandler) StreamChanges(c *gin.Context) {
+	cursor := c.GetHeader("Last-Event-ID")
+	if cursor == "" {
+		cursor = c.Query("since")
+	}
+
+	var since uint64
+	if cursor != "" {
+		parsed, err := strconv.ParseUint(cursor, 10, 64)
+		if err != nil {
+			common.SendError(c, http.StatusBadRequest, "Invalid change cursor", common.CodeInvalidRequest, nil)
+			return
+		}
+		since = parsed
+	} else {
+		latest, err := h.changeFeedService.Latest()
+		if err != nil {
+			sendChangeFeedError(c, err)
+			return
+		}
+		since = latest
+	}
+
+	// Check the cursor before committing to a stream, so an expired one gets a proper error response
+	if _, err := h.changeFeedService.Since(since, 1); err != nil {
+		sendChangeFeedError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: 3000\n\n")
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		response, err := h.changeFeedService.Wait(c.Request.Context(), since, 0, changeStreamWait)
+		if err != nil {
+			if err.Error() == "change feed cursor expired" {
+				fmt.Fprintf(w, "event: reset\ndata: {}\n\n")
+			} else {
+				slog.WarnContext(c.Request.Context(), "Change stream failed", "error", err)
+			}
+			return false
+		}
+
+		if len(response.Events) == 0 {
+			fmt.Fprintf(w, ": keepalive\n\n")
+			return c.Request.Context().Err() == nil
+		}
+
+		for _, event := range response.Events {
+			data, err := json.Marshal(event)
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to encode change event", "change_event_id", event.ID, "error", err)
+				return false
+			}
+			fmt.Fprintf(w, "id: %d\nevent: change\ndata: %s\n\n", event.ID, data)
+		}
+		since = response.LastSeq
+		return true
+	})
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type CouponHandler struct {
+	couponService *services.CouponService
+	validate      *validator.Validate
+}
+
+func NewCouponHandler(couponService *services.CouponService) *CouponHandler {
+	return &CouponHandler{
+		couponService: couponService,
+		validate:      validator.New(),
+	}
+}
+
+// sendCouponError maps coupon service errors to API responses
+func sendCouponError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "coupon not found":
+		common.SendError(c, http.StatusNotFound, "Coupon not found", common.CodeNotFound, nil)
+	case "promotion not found":
+		common.SendError(c, http.StatusBadRequest, "Promotion not found", common.CodeValidationError, nil)
+	case "promotion is not coupon-only":
+		common.SendError(c, http.StatusBadRequest, "Coupons can only give coupon-only promotions", common.CodeValidationError, nil)
+	case "coupon expires in the past":
+		common.SendError(c, http.StatusBadRequest, "Coupon must expire in the future", common.CodeValidationError, nil)
+	case "coupon code already exists":
+		common.SendError(c, http.StatusConflict, "Coupon code already exists", common.CodeConflict, nil)
+	case "coupon inactive":
+		common.SendError(c, http.StatusUnprocessableEntity, "Coupon is not active", common.CodeValidationError, nil)
+	case "coupon expired":
+		common.SendError(c, http.StatusUnprocessableEntity, "Coupon has expired", common.CodeValidationError, nil)
+	case "coupon usage limit reached":
+		common.SendError(c, http.StatusUnprocessableEntity, "Coupon has been used up", common.CodeLimitExceeded, nil)
+	case "coupon customer limit reached":
+		common.SendError(c, http.StatusUnprocessableEntity, "Customer has used up this coupon", common.CodeLimitExceeded, nil)
+	case "coupon requires a customer":
+		common.SendError(c, http.StatusBadRequest, "Coupon is limited per customer, a customer is required", common.CodeValidationError, nil)
+	case "coupon reservation not found":
+		common.SendError(c, http.StatusConflict, "Coupon is no longer reserved for the cart, apply it again", common.CodeConflict, nil)
+	case "coupon busy":
+		common.SendError(c, http.StatusConflict, "Coupon is being applied elsewhere, try again", common.CodeConflict, nil)
+	case "cart has no coupon":
+		common.SendError(c, http.StatusNotFound, "Cart has no coupon", common.CodeNotFound, nil)
+	default:
+		sendOrderError(c, err)
+	}
+}
+
+// GetAllCoupons handles GET /api/coupons
+func (h *CouponHandler) GetAllCoupons(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.couponService.GetAllCoupons(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch coupons", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Coupons fetched successfully", response)
+}
+
+// GetCouponById handles GET /api/coupons/:id
+func (h *CouponHandler) GetCouponById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	coupon, err := h.couponService.GetCouponById(id)
+	if err != nil {
+		sendCouponError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Coupon fetched successfully", coupon)
+}
+
+// GetCouponRedemptions handles GET /api/coupons/:id/redemptions
+func (h *CouponHandler) GetCouponRedemptions(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	redemptions, err := h.couponService.GetCouponRedemptions(id)
+	if err != nil {
+		sendCouponError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Coupon redemptions fetched successfully", redemptions)
+}
+
+// CreateCoupons handles POST /api/coupons
+func (h *CouponHandler) CreateCoupons(c *gin.Context) {
+	var req models.CreateCouponsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	coupons, err := h.couponService.CreateCoupons(&req, activityActor(c))
+	if err != nil {
+		sendCouponError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Coupons created successfully", coupons)
+}
+
+// UpdateCoupon handles PUT /api/coupons/:id
+func (h *CouponHandler) UpdateCoupon(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	coupon, err := h.couponService.UpdateCoupon(id, &req)
+	if err != nil {
+		sendCouponError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Coupon updated successfully", coupon)
+}
+
+// DeleteCoupon handles DELETE /api/coupons/:id
+func (h *CouponHandler) DeleteCoupon(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	coupon, err := h.couponService.DeleteCoupon(id)
+	if err != nil {
+		sendCouponError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Coupon deleted successfully", coupon)
+}
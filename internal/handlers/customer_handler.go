@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type CustomerHandler struct {
+	customerService *services.CustomerService
+	validate        *validator.Validate
+}
+
+func NewCustomerHandler(customerService *services.CustomerService) *CustomerHandler {
+	return &CustomerHandler{
+		customerService: customerService,
+		validate:        validator.New(),
+	}
+}
+
+// sendCustomerError maps customer service errors to API responses
+func sendCustomerError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "customer not found":
+		common.SendError(c, http.StatusNotFound, "Customer not found", common.CodeNotFound, nil)
+	case "customer email already exists":
+		common.SendError(c, http.StatusConflict, "Another customer has this email", common.CodeEmailExists, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// GetAllCustomers handles GET /api/customers
+func (h *CustomerHandler) GetAllCustomers(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.customerService.GetAllCustomers(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch customers", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Customers fetched successfully", response)
+}
+
+// GetCustomerById handles GET /api/customers/:id
+func (h *CustomerHandler) GetCustomerById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	customer, err := h.customerService.GetCustomerById(id)
+	if err != nil {
+		sendCustomerError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Customer fetched successfully", customer)
+}
+
+// GetCustomerOrders handles GET /api/customers/:id/orders
+func (h *CustomerHandler) GetCustomerOrders(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.customerService.GetCustomerOrders(id, params)
+	if err != nil {
+		sendCustomerError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Customer orders fetched successfully", response)
+}
+
+// CreateCustomer handles POST /api/customers
+func (h *CustomerHandler) CreateCustomer(c *gin.Context) {
+	var req models.CreateCustomerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	customer, err := h.customerService.CreateCustomer(&req, activityActor(c))
+	if err != nil {
+		sendCustomerError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Customer created successfully", customer)
+}
+
+// UpdateCustomer handles PUT /api/customers/:id
+func (h *CustomerHandler) UpdateCustomer(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCustomerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	customer, err := h.customerService.UpdateCustomer(id, &req)
+	if err != nil {
+		sendCustomerError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Customer updated successfully", customer)
+}
+
+// DeleteCustomer handles DELETE /api/customers/:id
+func (h *CustomerHandler) DeleteCustomer(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	customer, err := h.customerService.DeleteCustomer(id)
+	if err != nil {
+		sendCustomerError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Customer deleted successfully", customer)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type DeviceConfigHandler struct {
+	deviceConfigService *services.DeviceConfigService
+	validate            *validator.Validate
+}
+
+func NewDeviceConfigHandler(deviceConfigService *services.DeviceConfigService) *DeviceConfigHandler {
+	return &DeviceConfigHandler{
+		deviceConfigService: deviceConfigService,
+		validate:            validator.New(),
+	}
+}
+
+// GetTerminalConfig handles GET /api/terminal/config
+func (h *DeviceConfigHandler) GetTerminalConfig(c *gin.Context) {
+	device, ok := middleware.CurrentDevice(c)
+	if !ok {
+		common.SendError(c, http.StatusUnauthorized, "Device token required", common.CodeInvalidDeviceToken, nil)
+		return
+	}
+
+	config, err := h.deviceConfigService.GetTerminalConfig(device.ID)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		return
+	}
+
+	etag, err := services.TerminalConfigETag(config)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		return
+	}
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "no-cache")
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Terminal config fetched successfully", config)
+}
+
+// GetDeviceConfig handles GET /api/devices/:id/config
+func (h *DeviceConfigHandler) GetDeviceConfig(c *gin.Context) {
+	var uri struct {
+		ID uint `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid device ID", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	config, err := h.deviceConfigService.GetTerminalConfig(uri.ID)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Device config fetched successfully", config)
+}
+
+// UpdateDeviceConfig handles PUT /api/devices/:id/config
+func (h *DeviceConfigHandler) UpdateDeviceConfig(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateDeviceConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	config, err := h.deviceConfigService.UpdateDeviceConfig(id, &req)
+	if err != nil {
+		switch err.Error() {
+		case "device not found":
+			common.SendError(c, http.StatusNotFound, "Device not found", common.CodeNotFound, nil)
+		default:
+			common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		}
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Device config updated successfully", config)
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type DeviceHandler struct {
+	deviceService *services.DeviceService
+	validate      *validator.Validate
+}
+
+func NewDeviceHandler(deviceService *services.DeviceService) *DeviceHandler {
+	return &DeviceHandler{
+		deviceService: deviceService,
+		validate:      validator.New(),
+	}
+}
+
+// sendDeviceError maps device service errors to API responses
+func sendDeviceError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "device not found":
+		common.SendError(c, http.StatusNotFound, "Device not found", common.CodeNotFound, nil)
+	case "invalid pairing code":
+		common.SendError(c, http.StatusBadRequest, "Invalid pairing code", common.CodeInvalidPairingCode, nil)
+	case "pairing code expired":
+		common.SendError(c, http.StatusBadRequest, "Pairing code expired", common.CodePairingCodeExpired, nil)
+	case "store not found":
+		common.SendError(c, http.StatusBadRequest, "Store not found", common.CodeValidationError, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// GetAllDevices handles GET /api/devices
+func (h *DeviceHandler) GetAllDevices(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.deviceService.GetAllDevices(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch devices", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Devices fetched successfully", response)
+}
+
+// GetDeviceById handles GET /api/devices/:id
+func (h *DeviceHandler) GetDeviceById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	device, err := h.deviceService.GetDeviceById(id)
+	if err != nil {
+		sendDeviceError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Device fetched successfully", device)
+}
+
+// CreateDevice handles POST /api/devices
+func (h *DeviceHandler) CreateDevice(c *gin.Context) {
+	var req models.CreateDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	response, err := h.deviceService.CreateDevice(&req)
+	if err != nil {
+		sendDeviceError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Device registered successfully", response)
+}
+
+// UpdateDevice handles PUT /api/devices/:id
+func (h *DeviceHandler) UpdateDevice(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	device, err := h.deviceService.UpdateDevice(id, &req)
+	if err != nil {
+		sendDeviceError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Device updated successfully", device)
+}
+
+// RegeneratePairingCode handles POST /api/devices/:id/pairing-code
+func (h *DeviceHandler) RegeneratePairingCode(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.deviceService.RegeneratePairingCode(id)
+	if err != nil {
+		sendDeviceError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Pairing code issued successfully", response)
+}
+
+// DeactivateDevice handles PUT /api/devices/:id/deactivate
+func (h *DeviceHandler) DeactivateDevice(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	device, err := h.deviceService.DeactivateDevice(id)
+	if err != nil {
+		sendDeviceError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Device deactivated successfully", device)
+}
+
+// DeleteDevice handles DELETE /api/devices/:id
+func (h *DeviceHandler) DeleteDevice(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	device, err := h.deviceService.DeleteDevice(id)
+	if err != nil {
+		sendDeviceError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Device deleted successfully", device)
+}
+
+// PairDevice handles POST /api/devices/pair
+func (h *DeviceHandler) PairDevice(c *gin.Context) {
+	var req models.PairDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	response, err := h.deviceService.PairDevice(&req)
+	if err != nil {
+		sendDeviceError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Device paired successfully", response)
+}
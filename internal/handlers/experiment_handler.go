@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ExperimentHandler struct {
+	experimentService *services.ExperimentService
+	validate          *validator.Validate
+}
+
+func NewExperimentHandler(experimentService *services.ExperimentService) *ExperimentHandler {
+	return &ExperimentHandler{
+		experimentService: experimentService,
+		validate:          validator.New(),
+	}
+}
+
+// sendExperimentError maps experiment service errors to API responses
+func sendExperimentError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "experiment not found":
+		common.SendError(c, http.StatusNotFound, "Experiment not found", common.CodeNotFound, nil)
+	case "experiment key already exists":
+		common.SendError(c, http.StatusConflict, "Experiment key already exists", common.CodeConflict, nil)
+	case "experiment key and subject type can not change after it has started", "experiment has already started":
+		common.SendError(c, http.StatusConflict, err.Error(), common.CodeConflict, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// bindExperiment binds and validates an experiment request
+func (h *ExperimentHandler) bindExperiment(c *gin.Context) (*models.ExperimentRequest, bool) {
+	var req models.ExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return nil, false
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return nil, false
+	}
+
+	if problems := services.ValidateExperiment(&req); len(problems) > 0 {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, problems)
+		return nil, false
+	}
+
+	return &req, true
+}
+
+// GetAllExperiments handles GET /api/experiments
+func (h *ExperimentHandler) GetAllExperiments(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.experimentService.GetAllExperiments(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch experiments", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Experiments fetched successfully", response)
+}
+
+// GetExperimentById handles GET /api/experiments/:id
+func (h *ExperimentHandler) GetExperimentById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	experiment, err := h.experimentService.GetExperimentById(id)
+	if err != nil {
+		sendExperimentError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Experiment fetched successfully", experiment)
+}
+
+// CreateExperiment handles POST /api/experiments
+func (h *ExperimentHandler) CreateExperiment(c *gin.Context) {
+	req, ok := h.bindExperiment(c)
+	if !ok {
+		return
+	}
+
+	experiment, err := h.experimentService.CreateExperiment(req)
+	if err != nil {
+		sendExperimentError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Experiment created successfully", experiment)
+}
+
+// UpdateExperiment handles PUT /api/experiments/:id
+func (h *ExperimentHandler) UpdateExperiment(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := h.bindExperiment(c)
+	if !ok {
+		return
+	}
+
+	experiment, err := h.experimentService.UpdateExperiment(id, req)
+	if err != nil {
+		sendExperimentError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Experiment updated successfully", experiment)
+}
+
+// UpdateExperimentStatus handles PUT /api/experiments/:id/status
+func (h *ExperimentHandler) UpdateExperimentStatus(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateExperimentStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	experiment, err := h.experimentService.UpdateExperimentStatus(id, req.Status)
+	if err != nil {
+		sendExperimentError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Experiment status updated successfully", experiment)
+}
+
+// DeleteExperiment handles DELETE /api/experiments/:id
+func (h *ExperimentHandler) DeleteExperiment(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	experiment, err := h.experimentService.DeleteExperiment(id)
+	if err != nil {
+		sendExperimentError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Experiment deleted successfully", experiment)
+}
+
+// GetExperimentAssignments handles GET /api/experiments/:id/assignments
+func (h *ExperimentHandler) GetExperimentAssignments(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.experimentService.GetExperimentAssignments(id, params)
+	if err != nil {
+		sendExperimentError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Experiment assignments fetched successfully", response)
+}
+
+// GetMyExperiments handles GET /api/me/experiments
+func (h *ExperimentHandler) GetMyExperiments(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		common.SendError(c, http.StatusUnauthorized, "Unauthorized", common.CodeUnauthorized, nil)
+		return
+	}
+
+	variants, err := h.experimentService.GetSubjectExperiments(models.ExperimentSubjectUser, user.ID)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch experiments", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Experiments fetched successfully", variants)
+}
+
+// GetTerminalExperiments handles GET /api/terminal/experiments
+func (h *ExperimentHandler) GetTerminalExperiments(c *gin.Context) {
+	device, ok := middleware.CurrentDevice(c)
+	if !ok {
+		common.SendError(c, http.StatusUnauthorized, "Device token required", common.CodeInvalidDeviceToken, nil)
+		return
+	}
+
+	variants, err := h.experimentService.GetSubjectExperiments(models.ExperimentSubjectDevice, device.ID)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch experiments", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Experiments fetched successfully", variants)
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type GiftCardHandler struct {
+	giftCardService *services.GiftCardService
+	validate        *validator.Validate
+}
+
+func NewGiftCardHandler(giftCardService *services.GiftCardService) *GiftCardHandler {
+	return &GiftCardHandler{
+		giftCardService: giftCardService,
+		validate:        validator.New(),
+	}
+}
+
+// sendGiftCardError maps gift card service errors to API responses
+func sendGiftCardError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "gift card not found":
+		common.SendError(c, http.StatusNotFound, "Gift card not found", common.CodeNotFound, nil)
+	case "customer not found":
+		common.SendError(c, http.StatusBadRequest, "Customer not found", common.CodeValidationError, nil)
+	case "gift card void":
+		common.SendError(c, http.StatusConflict, "The gift card has been voided", common.CodeConflict, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// GetAllGiftCards handles GET /api/gift-cards
+func (h *GiftCardHandler) GetAllGiftCards(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.giftCardService.GetAllGiftCards(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch gift cards", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Gift cards fetched successfully", response)
+}
+
+// CheckBalance handles POST /api/gift-cards/balance. The code is sent in the body rather than
+// the URL so it doesn't end up in access logs.
+func (h *GiftCardHandler) CheckBalance(c *gin.Context) {
+	var req models.GiftCardBalanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	balance, err := h.giftCardService.CheckBalance(req.Code)
+	if err != nil {
+		sendGiftCardError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Gift card balance fetched successfully", balance)
+}
+
+// GetGiftCardById handles GET /api/gift-cards/:id
+func (h *GiftCardHandler) GetGiftCardById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	card, err := h.giftCardService.GetGiftCardById(id)
+	if err != nil {
+		sendGiftCardError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Gift card fetched successfully", card)
+}
+
+// GetTransactions handles GET /api/gift-cards/:id/transactions
+func (h *GiftCardHandler) GetTransactions(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.giftCardService.GetTransactions(id, params)
+	if err != nil {
+		sendGiftCardError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Gift card transactions fetched successfully", response)
+}
+
+// IssueGiftCard handles POST /api/gift-cards
+func (h *GiftCardHandler) IssueGiftCard(c *gin.Context) {
+	var req models.IssueGiftCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	card, err := h.giftCardService.IssueGiftCard(&req, activityActor(c))
+	if err != nil {
+		sendGiftCardError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Gift card issued successfully", card)
+}
+
+// VoidGiftCard handles PUT /api/gift-cards/:id/void
+func (h *GiftCardHandler) VoidGiftCard(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.VoidGiftCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	card, err := h.giftCardService.VoidGiftCard(id, &req, activityActor(c))
+	if err != nil {
+		sendGiftCardError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Gift card voided successfully", card)
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/Aebroyx/the-blade-api/internal/graph"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type GraphQLHandler struct {
+	server *handler.Server
+}
+
+// NewGraphQLHandler serves queries of the schema sent as JSON in a POST. Queries selecting
+// more fields than the deployment's complexity limit are rejected before they run.
+func NewGraphQLHandler(schema graphql.ExecutableSchema) *GraphQLHandler {
+	server := handler.New(schema)
+	server.AddTransport(transport.POST{})
+	server.Use(extension.Introspection{})
+	server.Use(&extension.ComplexityLimit{Func: func(ctx context.Context, rc *graphql.OperationContext) int {
+		return limits.Get(limits.MaxGraphQLComplexity)
+	}})
+
+	return &GraphQLHandler{server: server}
+}
+
+// Query handles POST /api/graphql. Responses follow GraphQL over HTTP rather than the API's
+// envelope: errors of single fields are listed next to the data of the others.
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	ctx := c.Request.Context()
+	if user, ok := middleware.CurrentUser(c); ok {
+		ctx = graph.WithUser(ctx, user)
+	}
+	h.server.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	healthService *services.HealthService
+}
+
+func NewHealthHandler(healthService *services.HealthService) *HealthHandler {
+	return &HealthHandler{
+		healthService: healthService,
+	}
+}
+
+// Liveness handles GET /healthz. Probes read the bare JSON body rather than the API envelope.
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, h.healthService.Live())
+}
+
+// Readiness handles GET /readyz, answering 503 while the instance can't serve requests so load
+// balancers route around it. A degraded instance still serves and answers 200.
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	response := h.healthService.Ready(c.Request.Context())
+	status := http.StatusOK
+	if response.Status == models.HealthStatusDown {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, response)
+}
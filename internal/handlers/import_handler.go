@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ImportHandler struct {
+	importService *services.ImportService
+	validate      *validator.Validate
+}
+
+func NewImportHandler(importService *services.ImportService) *ImportHandler {
+	return &ImportHandler{
+		importService: importService,
+		validate:      validator.New(),
+	}
+}
+
+// GetAllImports handles GET /api/imports
+func (h *ImportHandler) GetAllImports(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.importService.GetAllImports(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch imports", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Imports fetched successfully", response)
+}
+
+// GetImportById handles GET /api/imports/:id
+func (h *ImportHandler) GetImportById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	imp, err := h.importService.GetImportById(id)
+	if err != nil {
+		if err.Error() == "import not found" {
+			common.SendError(c, http.StatusNotFound, "Import not found", common.CodeNotFound, nil)
+			return
+		}
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Import fetched successfully", imp)
+}
+
+// DeleteImport handles DELETE /api/imports/:id. A queued import is cancelled and its file
+// removed.
+func (h *ImportHandler) DeleteImport(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	imp, err := h.importService.DeleteImport(id)
+	if err != nil {
+		switch err.Error() {
+		case "import not found":
+			common.SendError(c, http.StatusNotFound, "Import not found", common.CodeNotFound, nil)
+		case "import is processing":
+			common.SendError(c, http.StatusConflict, "The import is being processed and can't be deleted", common.CodeConflict, nil)
+		default:
+			common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		}
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Import deleted successfully", imp)
+}
+
+// readImportFile reads the file of an import upload, answering the request when it can't
+func readImportFile(c *gin.Context) (string, []byte, bool) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		common.SendError(c, http.StatusBadRequest, "An import file is required", common.CodeInvalidRequest, err.Error())
+		return "", nil, false
+	}
+	file, err := header.Open()
+	if err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid import file", common.CodeInvalidRequest, err.Error())
+		return "", nil, false
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid import file", common.CodeInvalidRequest, err.Error())
+		return "", nil, false
+	}
+	return header.Filename, data, true
+}
+
+// sendImportError maps errors of checking an import upload to API responses
+func sendImportError(c *gin.Context, err error) {
+	var fileErr *services.ImportFileError
+	if errors.As(err, &fileErr) {
+		common.SendError(c, http.StatusBadRequest, "The file can't be imported", common.CodeValidationError, fileErr.Errors)
+		return
+	}
+	if exceeded, ok := limits.AsExceeded(err); ok {
+		common.SendError(c, http.StatusUnprocessableEntity, "Limit exceeded", common.CodeLimitExceeded, exceeded)
+		return
+	}
+	var quotaErr *services.StorageQuotaError
+	if errors.As(err, &quotaErr) {
+		common.SendError(c, http.StatusRequestEntityTooLarge, "Storage quota exceeded", common.CodeStorageQuotaExceeded, quotaErr)
+		return
+	}
+	common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+}
+
+// CreateImport handles POST /api/imports. The file is sent as multipart form data along with
+// the entity, an optional format and an optional column mapping.
+func (h *ImportHandler) CreateImport(c *gin.Context) {
+	var req models.CreateImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	filename, data, ok := readImportFile(c)
+	if !ok {
+		return
+	}
+
+	imp, err := h.importService.CreateImport(&req, filename, data, storageOwner(c), activityActor(c))
+	if err != nil {
+		sendImportError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusAccepted, "Import queued successfully", imp)
+}
+
+// ImportProducts handles POST /api/products/import. The CSV, JSON or XLSX file is sent as
+// multipart form data along with an optional format, column mapping and dry_run flag. Small
+// files are answered with the finished import; larger ones are queued, answered with 202, and
+// followed through GET /api/imports/:id.
+func (h *ImportHandler) ImportProducts(c *gin.Context) {
+	var req models.ProductImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	filename, data, ok := readImportFile(c)
+	if !ok {
+		return
+	}
+
+	imp, queued, err := h.importService.ImportProducts(c.Request.Context(), &req, filename, data, storageOwner(c), activityActor(c))
+	if err != nil {
+		sendImportError(c, err)
+		return
+	}
+
+	if queued {
+		common.SendSuccess(c, http.StatusAccepted, "Import queued successfully", imp)
+		return
+	}
+	common.SendSuccess(c, http.StatusOK, "Import processed successfully", imp)
+}
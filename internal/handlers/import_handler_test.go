@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Aebroyx/the-blade-api/internal/apiversion"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/Aebroyx/the-blade-api/internal/testutil"
+	"github.com/gin-gonic/gin"
+)
+
+// uploadImport sends a customers import of a CSV file
+func uploadImport(t *testing.T, env *testutil.Env, api http.Handler, file string, user *models.Users) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("entity", models.ImportEntityCustomers); err != nil {
+		t.Fatalf("failed to write form: %v", err)
+	}
+	part, err := form.CreateFormFile("file", "customers.csv")
+	if err != nil {
+		t.Fatalf("failed to write form: %v", err)
+	}
+	if _, err := part.Write([]byte(file)); err != nil {
+		t.Fatalf("failed to write form: %v", err)
+	}
+	if err := form.Close(); err != nil {
+		t.Fatalf("failed to write form: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/imports", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.AddCookie(&http.Cookie{Name: "access_token", Value: env.Token(t, *user)})
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+	return rec
+}
+
+// storedBytes returns the bytes charged to requests outside any tenant and store
+func storedBytes(t *testing.T, env *testutil.Env) int64 {
+	t.Helper()
+
+	var usage models.StorageUsages
+	if err := env.DB.Where("tenant_id = 0 AND store_id = 0").Limit(1).Find(&usage).Error; err != nil {
+		t.Fatalf("failed to read storage usage: %v", err)
+	}
+	return usage.Bytes
+}
+
+func TestImportsChargeStorageQuota(t *testing.T) {
+	env := testutil.New(t)
+	file := "name\nJane Doe\n"
+	quotaService := services.NewStorageQuotaService(env.DB, models.StorageQuotas{TenantBytes: int64(len(file)) + 4})
+	importService := services.NewImportService(env.DB, services.NewProductService(env.DB, env.Cache, nil), quotaService)
+	importHandler := NewImportHandler(importService)
+	api := env.API(apiversion.NewRegistrar("v1", func(public *gin.RouterGroup, protected *gin.RouterGroup) {
+		imports := protected.Group("/imports", middleware.RequireRole(models.RoleAdmin))
+		imports.POST("", importHandler.CreateImport)
+		imports.DELETE("/:id", importHandler.DeleteImport)
+	}))
+	admin := env.CreateUser(t, models.RoleAdmin)
+
+	// A queued file is charged until the import is deleted
+	var imp models.Imports
+	testutil.Decode(t, uploadImport(t, env, api, file, &admin), http.StatusAccepted, &imp)
+	if used := storedBytes(t, env); used != int64(len(file)) {
+		t.Errorf("queued import uses %d bytes, want %d", used, len(file))
+	}
+	testutil.Decode(t, uploadImport(t, env, api, file, &admin), http.StatusRequestEntityTooLarge, nil)
+
+	path := "/api/v1/imports/" + strconv.FormatUint(uint64(imp.ID), 10)
+	testutil.Decode(t, env.Request(t, api, http.MethodDelete, path, nil, &admin), http.StatusOK, nil)
+	if used := storedBytes(t, env); used != 0 {
+		t.Errorf("deleted import still uses %d bytes", used)
+	}
+	testutil.Decode(t, env.Request(t, api, http.MethodDelete, path, nil, &admin), http.StatusNotFound, nil)
+
+	// The file is released once the import is processed and drops it
+	testutil.Decode(t, uploadImport(t, env, api, file, &admin), http.StatusAccepted, &imp)
+	if err := importService.ProcessImports(context.Background()); err != nil {
+		t.Fatalf("failed to process imports: %v", err)
+	}
+	if used := storedBytes(t, env); used != 0 {
+		t.Errorf("processed import still uses %d bytes", used)
+	}
+}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type InventoryHandler struct {
+	inventoryService *services.InventoryService
+	validate         *validator.Validate
+}
+
+func NewInventoryHandler(inventoryService *services.InventoryService) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryService: inventoryService,
+		validate:         validator.New(),
+	}
+}
+
+// sendInventoryError maps inventory service errors to API responses
+func sendInventoryError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "product not found":
+		common.SendError(c, http.StatusBadRequest, "Product not found", common.CodeValidationError, nil)
+	case "variant not found":
+		common.SendError(c, http.StatusBadRequest, "Variant not found for the product", common.CodeValidationError, nil)
+	case "variant required":
+		common.SendError(c, http.StatusBadRequest, "Products with variants are stocked per variant; a variant is required", common.CodeValidationError, nil)
+	case "serials required":
+		common.SendError(c, http.StatusBadRequest, "The product is serialized; list a serial number per unit", common.CodeValidationError, nil)
+	case "serials don't match quantity":
+		common.SendError(c, http.StatusBadRequest, "List exactly one serial number per unit", common.CodeValidationError, nil)
+	case "duplicate serial":
+		common.SendError(c, http.StatusBadRequest, "A serial number is listed more than once", common.CodeValidationError, nil)
+	case "serial not in stock":
+		common.SendError(c, http.StatusConflict, "A serial number is not in stock at this store", common.CodeConflict, nil)
+	case "serial already in stock":
+		common.SendError(c, http.StatusConflict, "A serial number is already in stock", common.CodeConflict, nil)
+	case "serial not sold on order":
+		common.SendError(c, http.StatusBadRequest, "A serial number was not sold on the order line or was already returned", common.CodeValidationError, nil)
+	case "product not serialized":
+		common.SendError(c, http.StatusBadRequest, "The product is not tracked by serial number", common.CodeValidationError, nil)
+	case "batch required":
+		common.SendError(c, http.StatusBadRequest, "The product is batch-tracked; a batch is required", common.CodeValidationError, nil)
+	case "batch expired":
+		common.SendError(c, http.StatusConflict, "The batch is past its expiry date and can't be sold", common.CodeConflict, nil)
+	case "batch not in stock":
+		common.SendError(c, http.StatusConflict, "The batch has not been received at this store", common.CodeConflict, nil)
+	case "product not batch tracked":
+		common.SendError(c, http.StatusBadRequest, "The product is not tracked by batch", common.CodeValidationError, nil)
+	case "batch not found":
+		common.SendError(c, http.StatusNotFound, "Batch not found", common.CodeNotFound, nil)
+	case "product is a bundle":
+		common.SendError(c, http.StatusBadRequest, "Bundles hold no stock of their own; their components do", common.CodeValidationError, nil)
+	case "unit not found":
+		common.SendError(c, http.StatusBadRequest, "Unit of measure not found", common.CodeValidationError, nil)
+	case "unit not convertible":
+		common.SendError(c, http.StatusBadRequest, "The unit doesn't convert into the product's stock unit", common.CodeValidationError, nil)
+	case "quantity not in whole units":
+		common.SendError(c, http.StatusBadRequest, "The quantity doesn't convert into a whole number of stock units", common.CodeValidationError, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// GetStockLevels handles GET /api/inventory/stock
+func (h *InventoryHandler) GetStockLevels(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.inventoryService.GetStockLevels(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch stock levels", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Stock levels fetched successfully", response)
+}
+
+// GetStockMovements handles GET /api/inventory/movements
+func (h *InventoryHandler) GetStockMovements(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.inventoryService.GetStockMovements(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch stock movements", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Stock movements fetched successfully", response)
+}
+
+// GetLowStock handles GET /api/inventory/low-stock
+func (h *InventoryHandler) GetLowStock(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.inventoryService.GetLowStock(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch low-stock products", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Low-stock products fetched successfully", response)
+}
+
+// AdjustStock handles POST /api/inventory/adjustments
+func (h *InventoryHandler) AdjustStock(c *gin.Context) {
+	var req models.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	if err := limits.Check(limits.MaxBulkIDs, len(req.Items)); err != nil {
+		sendBindError(c, "Invalid request body", err)
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	if store, ok := middleware.CurrentStore(c); ok {
+		req.StoreID = &store.ID
+	}
+
+	movements, err := h.inventoryService.AdjustStock(&req, activityActor(c))
+	if err != nil {
+		sendInventoryError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Stock adjusted successfully", movements)
+}
+
+// ReceiveStock handles POST /api/inventory/receipts
+func (h *InventoryHandler) ReceiveStock(c *gin.Context) {
+	var req models.StockReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	if err := limits.Check(limits.MaxBulkIDs, len(req.Items)); err != nil {
+		sendBindError(c, "Invalid request body", err)
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	if store, ok := middleware.CurrentStore(c); ok {
+		req.StoreID = &store.ID
+	}
+
+	movements, err := h.inventoryService.ReceiveStock(&req, activityActor(c))
+	if err != nil {
+		sendInventoryError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Stock received successfully", movements)
+}
+
+// GetSerials handles GET /api/inventory/serials
+func (h *InventoryHandler) GetSerials(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.inventoryService.GetSerials(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch serial numbers", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Serial numbers fetched successfully", response)
+}
+
+// GetBatches handles GET /api/inventory/batches
+func (h *InventoryHandler) GetBatches(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.inventoryService.GetBatches(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch batches", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Batches fetched successfully", response)
+}
+
+// GetExpiringStock handles GET /api/inventory/expiring
+func (h *InventoryHandler) GetExpiringStock(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	var query models.ExpiringStockQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(query); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+	if query.Days == 0 {
+		query.Days = 30
+	}
+
+	response, err := h.inventoryService.GetExpiringStock(params, query.Days)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch expiring stock", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Expiring stock fetched successfully", response)
+}
+
+// GetBatchRecall handles GET /api/inventory/batches/recall
+func (h *InventoryHandler) GetBatchRecall(c *gin.Context) {
+	var query models.BatchRecallQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(query); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	recall, err := h.inventoryService.GetBatchRecall(&query)
+	if err != nil {
+		sendInventoryError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Batch recall report fetched successfully", recall)
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type InvoiceHandler struct {
+	invoiceService *services.InvoiceService
+	validate       *validator.Validate
+}
+
+func NewInvoiceHandler(invoiceService *services.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceService: invoiceService,
+		validate:       validator.New(),
+	}
+}
+
+// sendInvoiceError maps invoice service errors to API responses
+func sendInvoiceError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "invoice not found":
+		common.SendError(c, http.StatusNotFound, "Invoice not found", common.CodeNotFound, nil)
+	case "customer not found":
+		common.SendError(c, http.StatusBadRequest, "The order's customer no longer exists", common.CodeValidationError, nil)
+	case "order has no customer":
+		common.SendError(c, http.StatusBadRequest, "Only orders sold to a customer can be invoiced", common.CodeValidationError, nil)
+	case "due date in the past":
+		common.SendError(c, http.StatusBadRequest, "The due date can't be in the past", common.CodeValidationError, nil)
+	case "order can't be invoiced":
+		common.SendError(c, http.StatusConflict, "Draft, cancelled and refunded orders can't be invoiced", common.CodeConflict, nil)
+	case "order already invoiced":
+		common.SendError(c, http.StatusConflict, "The order has already been invoiced; void its invoice first", common.CodeConflict, nil)
+	case "invoice void":
+		common.SendError(c, http.StatusConflict, "The invoice has been voided", common.CodeConflict, nil)
+	case "payment exceeds balance":
+		common.SendError(c, http.StatusBadRequest, "Payment exceeds the invoice balance", common.CodeValidationError, nil)
+	default:
+		sendReceiptError(c, err)
+	}
+}
+
+// GetAllInvoices handles GET /api/invoices
+func (h *InvoiceHandler) GetAllInvoices(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.invoiceService.GetAllInvoices(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch invoices", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Invoices fetched successfully", response)
+}
+
+// GetInvoiceById handles GET /api/invoices/:id
+func (h *InvoiceHandler) GetInvoiceById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	invoice, err := h.invoiceService.GetInvoiceById(id)
+	if err != nil {
+		sendInvoiceError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Invoice fetched successfully", invoice)
+}
+
+// GetInvoicePDF handles GET /api/invoices/:id/pdf
+func (h *InvoiceHandler) GetInvoicePDF(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var query models.InvoicePDFQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(query); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	var tenantID uint
+	if tenant, ok := middleware.CurrentTenant(c); ok {
+		tenantID = tenant.ID
+	}
+	if device, ok := middleware.CurrentDevice(c); ok && query.Location == "" {
+		query.Location = device.Location
+	}
+
+	content, number, err := h.invoiceService.RenderInvoice(c.Request.Context(), id, tenantID, query.Location)
+	if err != nil {
+		sendInvoiceError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="invoice-%s.pdf"`, number))
+	c.Data(http.StatusOK, "application/pdf", content)
+}
+
+// CreateInvoice handles POST /api/invoices
+func (h *InvoiceHandler) CreateInvoice(c *gin.Context) {
+	var req models.CreateInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	invoice, err := h.invoiceService.CreateInvoice(&req, activityActor(c))
+	if err != nil {
+		if err.Error() == "order not found" {
+			common.SendError(c, http.StatusBadRequest, "Order not found", common.CodeValidationError, nil)
+			return
+		}
+		sendInvoiceError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Invoice created successfully", invoice)
+}
+
+// RecordPayment handles POST /api/invoices/:id/payments
+func (h *InvoiceHandler) RecordPayment(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.InvoicePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	invoice, err := h.invoiceService.RecordPayment(id, &req, activityActor(c))
+	if err != nil {
+		sendInvoiceError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Invoice payment recorded successfully", invoice)
+}
+
+// VoidInvoice handles PUT /api/invoices/:id/void
+func (h *InvoiceHandler) VoidInvoice(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.VoidInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	invoice, err := h.invoiceService.VoidInvoice(id, &req, activityActor(c))
+	if err != nil {
+		sendInvoiceError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Invoice voided successfully", invoice)
+}
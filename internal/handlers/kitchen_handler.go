@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type KitchenHandler struct {
+	kitchenService *services.KitchenService
+}
+
+func NewKitchenHandler(kitchenService *services.KitchenService) *KitchenHandler {
+	return &KitchenHandler{
+		kitchenService: kitchenService,
+	}
+}
+
+// sendKitchenError maps kitchen service errors to API responses
+func sendKitchenError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "order not found":
+		common.SendError(c, http.StatusNotFound, "Order not found", common.CodeNotFound, nil)
+	case "order item not found":
+		common.SendError(c, http.StatusNotFound, "Order item not found", common.CodeNotFound, nil)
+	case "order not in kitchen":
+		common.SendError(c, http.StatusConflict, "The order is not on the kitchen display", common.CodeConflict, nil)
+	case "invalid kitchen transition":
+		common.SendError(c, http.StatusConflict, "The item has already been started or done", common.CodeConflict, nil)
+	default:
+		sendChangeFeedError(c, err)
+	}
+}
+
+// GetTickets handles GET /api/kitchen/tickets
+func (h *KitchenHandler) GetTickets(c *gin.Context) {
+	tickets, err := h.kitchenService.GetTickets(middleware.StoreScope(c))
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch kitchen tickets", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Kitchen tickets fetched successfully", tickets)
+}
+
+// GetTicket handles GET /api/kitchen/tickets/:id, where :id is the order's
+func (h *KitchenHandler) GetTicket(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	ticket, err := h.kitchenService.GetTicket(id)
+	if err != nil {
+		sendKitchenError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Kitchen ticket fetched successfully", ticket)
+}
+
+// StartItem handles PUT /api/kitchen/tickets/:id/items/:itemId/start
+func (h *KitchenHandler) StartItem(c *gin.Context) {
+	h.acknowledge(c, "Item started successfully", h.kitchenService.StartItem)
+}
+
+// CompleteItem handles PUT /api/kitchen/tickets/:id/items/:itemId/done
+func (h *KitchenHandler) CompleteItem(c *gin.Context) {
+	h.acknowledge(c, "Item done successfully", h.kitchenService.CompleteItem)
+}
+
+// acknowledge records the kitchen's progress on the item in the :itemId parameter with one of
+// the service's steps
+func (h *KitchenHandler) acknowledge(c *gin.Context, message string, step func(orderID uint, itemID uint, actor models.ActivityActor) (*models.KitchenTicket, error)) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+	itemID, ok := binding.ID(c, "itemId")
+	if !ok {
+		return
+	}
+
+	ticket, err := step(id, itemID, activityActor(c))
+	if err != nil {
+		sendKitchenError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, message, ticket)
+}
+
+// StreamTickets handles GET /api/kitchen/stream as server-sent events. New connections get a
+// "snapshot" event with the open tickets, then a "ticket" event whenever one changes; tickets
+// that left the display are sent once more with open set to false. Event IDs are change feed
+// sequence numbers, so reconnecting displays resume through Last-Event-ID.
+func (h *KitchenHandler) StreamTickets(c *gin.Context) {
+	storeIDs := middleware.StoreScope(c)
+
+	var since uint64
+	var snapshot []models.KitchenTicket
+	if cursor := c.GetHeader("Last-Event-ID"); cursor != "" {
+		parsed, err := strconv.ParseUint(cursor, 10, 64)
+		if err != nil {
+			common.SendError(c, http.StatusBadRequest, "Invalid change cursor", common.CodeInvalidRequest, nil)
+			return
+		}
+		since = parsed
+		// Check the cursor before committing to a stream, so an expired one gets a proper error response
+		if err := h.kitchenService.CheckCursor(since); err != nil {
+			sendKitchenError(c, err)
+			return
+		}
+	} else {
+		// The position is taken before the tickets, so changes in between are sent again rather than missed
+		latest, err := h.kitchenService.Latest()
+		if err != nil {
+			sendKitchenError(c, err)
+			return
+		}
+		since = latest
+		if snapshot, err = h.kitchenService.GetTickets(storeIDs); err != nil {
+			sendKitchenError(c, err)
+			return
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: 3000\n\n")
+	if snapshot != nil {
+		data, err := json.Marshal(snapshot)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to encode kitchen snapshot", "error", err)
+			return
+		}
+		fmt.Fprintf(c.Writer, "id: %d\nevent: snapshot\ndata: %s\n\n", since, data)
+	}
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		events, lastSeq, err := h.kitchenService.WaitTickets(c.Request.Context(), since, storeIDs, changeStreamWait)
+		if err != nil {
+			if err.Error() == "change feed cursor expired" {
+				fmt.Fprintf(w, "event: reset\ndata: {}\n\n")
+			} else {
+				slog.WarnContext(c.Request.Context(), "Kitchen stream failed", "error", err)
+			}
+			return false
+		}
+
+		if lastSeq == since {
+			fmt.Fprintf(w, ": keepalive\n\n")
+			return c.Request.Context().Err() == nil
+		}
+
+		for _, event := range events {
+			data, err := json.Marshal(event.Ticket)
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to encode kitchen ticket", "order_id", event.Ticket.OrderID, "error", err)
+				return false
+			}
+			fmt.Fprintf(w, "id: %d\nevent: ticket\ndata: %s\n\n", event.Seq, data)
+		}
+		since = lastSeq
+		return true
+	})
+}
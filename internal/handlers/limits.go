@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/gin-gonic/gin"
+)
+
+// sendBindError responds to a failed bind, reporting exceeded limits with LIMIT_EXCEEDED
+func sendBindError(c *gin.Context, message string, err error) {
+	if exceeded, ok := limits.AsExceeded(err); ok {
+		common.SendError(c, http.StatusUnprocessableEntity, "Limit exceeded", common.CodeLimitExceeded, exceeded)
+		return
+	}
+	common.SendError(c, http.StatusBadRequest, message, common.CodeInvalidRequest, err.Error())
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type LoyaltyHandler struct {
+	loyaltyService *services.LoyaltyService
+	validate       *validator.Validate
+}
+
+func NewLoyaltyHandler(loyaltyService *services.LoyaltyService) *LoyaltyHandler {
+	return &LoyaltyHandler{
+		loyaltyService: loyaltyService,
+		validate:       validator.New(),
+	}
+}
+
+// sendLoyaltyError maps loyalty service errors to API responses
+func sendLoyaltyError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "loyalty rule not found":
+		common.SendError(c, http.StatusNotFound, "Loyalty rule not found", common.CodeNotFound, nil)
+	case "category not found":
+		common.SendError(c, http.StatusBadRequest, "Category not found", common.CodeValidationError, nil)
+	case "insufficient loyalty points":
+		common.SendError(c, http.StatusConflict, "The customer doesn't have enough loyalty points", common.CodeConflict, nil)
+	default:
+		sendCustomerError(c, err)
+	}
+}
+
+// GetAllLoyaltyRules handles GET /api/loyalty-rules
+func (h *LoyaltyHandler) GetAllLoyaltyRules(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.loyaltyService.GetAllLoyaltyRules(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch loyalty rules", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Loyalty rules fetched successfully", response)
+}
+
+// GetLoyaltyRuleById handles GET /api/loyalty-rules/:id
+func (h *LoyaltyHandler) GetLoyaltyRuleById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	rule, err := h.loyaltyService.GetLoyaltyRuleById(id)
+	if err != nil {
+		sendLoyaltyError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Loyalty rule fetched successfully", rule)
+}
+
+// CreateLoyaltyRule handles POST /api/loyalty-rules
+func (h *LoyaltyHandler) CreateLoyaltyRule(c *gin.Context) {
+	var req models.LoyaltyRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	rule, err := h.loyaltyService.CreateLoyaltyRule(&req)
+	if err != nil {
+		sendLoyaltyError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Loyalty rule created successfully", rule)
+}
+
+// UpdateLoyaltyRule handles PUT /api/loyalty-rules/:id
+func (h *LoyaltyHandler) UpdateLoyaltyRule(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.LoyaltyRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	rule, err := h.loyaltyService.UpdateLoyaltyRule(id, &req)
+	if err != nil {
+		sendLoyaltyError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Loyalty rule updated successfully", rule)
+}
+
+// DeleteLoyaltyRule handles DELETE /api/loyalty-rules/:id
+func (h *LoyaltyHandler) DeleteLoyaltyRule(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	rule, err := h.loyaltyService.DeleteLoyaltyRule(id)
+	if err != nil {
+		sendLoyaltyError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Loyalty rule deleted successfully", rule)
+}
+
+// GetBalance handles GET /api/customers/:id/loyalty
+func (h *LoyaltyHandler) GetBalance(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	balance, err := h.loyaltyService.GetBalance(id)
+	if err != nil {
+		sendLoyaltyError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Loyalty balance fetched successfully", balance)
+}
+
+// GetTransactions handles GET /api/customers/:id/loyalty/transactions
+func (h *LoyaltyHandler) GetTransactions(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.loyaltyService.GetTransactions(id, params)
+	if err != nil {
+		sendLoyaltyError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Loyalty transactions fetched successfully", response)
+}
+
+// AdjustPoints handles POST /api/customers/:id/loyalty/adjustments
+func (h *LoyaltyHandler) AdjustPoints(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.LoyaltyAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	entry, err := h.loyaltyService.AdjustPoints(id, &req, activityActor(c))
+	if err != nil {
+		sendLoyaltyError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Loyalty points adjusted successfully", entry)
+}
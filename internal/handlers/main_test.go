@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/Aebroyx/the-blade-api/internal/apiversion"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/mailer"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/payments"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/Aebroyx/the-blade-api/internal/testutil"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	testutil.Main(m)
+}
+
+// salesAPI serves the order, payment and return routes of v1, wired as the server wires them.
+// Hooks are subscribed with OrderService.AfterTransition.
+func salesAPI(env *testutil.Env, hooks ...services.OrderEventHook) http.Handler {
+	activityService := services.NewActivityService(env.DB)
+	notificationService := services.NewNotificationService(env.DB, mailer.New(env.Config))
+	inventoryService := services.NewInventoryService(env.DB, env.Config, activityService, notificationService)
+	orderService := services.NewOrderService(env.DB, services.NewPromotionService(env.DB), activityService)
+	orderService.OnTransition(inventoryService.OrderStockHook)
+	for _, hook := range hooks {
+		orderService.AfterTransition(hook)
+	}
+
+	paymentProviders := payments.NewRegistry()
+	paymentProviders.Register(payments.MethodCash, payments.Cash{})
+	loyaltyService := services.NewLoyaltyService(env.DB, env.Config.LoyaltyPointValue)
+	orderService.OnTransition(loyaltyService.OrderLoyaltyHook)
+	giftCardService := services.NewGiftCardService(env.DB)
+	orderService.OnTransition(giftCardService.OrderGiftCardHook)
+	paymentService := services.NewPaymentService(env.DB, orderService, loyaltyService, giftCardService, paymentProviders, activityService, "")
+	returnService := services.NewReturnService(env.DB, orderService, inventoryService, paymentService, activityService)
+	returnService.OnReturn(loyaltyService.ReturnLoyaltyHook)
+	returnService.OnReturn(giftCardService.ReturnGiftCardHook)
+	permissionService := services.NewPermissionService(env.DB, env.Cache, env.Config.AuthClaimsMode)
+
+	orderHandler := NewOrderHandler(orderService)
+	paymentHandler := NewPaymentHandler(paymentService, paymentProviders)
+	returnHandler := NewReturnHandler(returnService)
+
+	return env.API(apiversion.NewRegistrar("v1", func(public *gin.RouterGroup, protected *gin.RouterGroup) {
+		orders := protected.Group("/orders")
+		{
+			orders.POST("", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate), orderHandler.CreateOrder)
+			orders.GET("/:id", middleware.RequirePermission(permissionService, models.PermissionOrdersView), orderHandler.GetOrderById)
+			orders.PUT("/:id/place", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate), orderHandler.PlaceOrder)
+			orders.PUT("/:id/fulfill", middleware.RequirePermission(permissionService, models.PermissionOrdersManage), orderHandler.FulfillOrder)
+			orders.PUT("/:id/complete", middleware.RequirePermission(permissionService, models.PermissionOrdersManage), orderHandler.CompleteOrder)
+			orders.PUT("/:id/cancel", middleware.RequirePermission(permissionService, models.PermissionOrdersManage), orderHandler.CancelOrder)
+			orders.POST("/:id/payments", middleware.RequirePermission(permissionService, models.PermissionOrdersCreate), paymentHandler.CreatePayment)
+			orders.POST("/:id/payments/:paymentId/refund", middleware.RequirePermission(permissionService, models.PermissionOrdersManage), paymentHandler.RefundPayment)
+			orders.POST("/:id/refund", middleware.RequirePermission(permissionService, models.PermissionOrdersRefund), returnHandler.RefundOrder)
+			orders.GET("/:id/returns", middleware.RequirePermission(permissionService, models.PermissionOrdersView), returnHandler.GetOrderReturns)
+		}
+	}))
+}
+
+// stockOf returns the stock of a product across every store
+func stockOf(t *testing.T, env *testutil.Env, productID uint) int64 {
+	t.Helper()
+
+	var quantity int64
+	err := env.DB.Model(&models.StockLevels{}).
+		Where("product_id = ?", productID).
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&quantity).Error
+	if err != nil {
+		t.Fatalf("failed to read stock: %v", err)
+	}
+	return quantity
+}
+
+// payInCash pays an amount of an order in cash
+func payInCash(t *testing.T, env *testutil.Env, api http.Handler, order *models.Orders, amount int64, user *models.Users) models.Payments {
+	t.Helper()
+
+	var payment models.Payments
+	rec := env.Request(t, api, http.MethodPost, orderPath(order, "/payments"), models.CreatePaymentRequest{
+		Method: payments.MethodCash,
+		Amount: amount,
+	}, user)
+	testutil.Decode(t, rec, http.StatusCreated, &payment)
+	return payment
+}
+
+// orderPath returns the path of an order's route under v1, e.g. orderPath(order, "/place")
+func orderPath(order *models.Orders, route string) string {
+	return "/api/v1/orders/" + strconv.FormatUint(uint64(order.ID), 10) + route
+}
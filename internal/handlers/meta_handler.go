@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/contract"
+	"github.com/gin-gonic/gin"
+)
+
+type MetaHandler struct {
+	routes func() gin.RoutesInfo
+
+	once     sync.Once
+	document *contract.Document
+	err      error
+}
+
+// NewMetaHandler describes the routes returned by routes. The routes are read on the first
+// request, once the router is complete.
+func NewMetaHandler(routes func() gin.RoutesInfo) *MetaHandler {
+	return &MetaHandler{routes: routes}
+}
+
+// GetContract handles GET /api/meta/contract, the route and DTO registry client SDKs are
+// generated from. The contract only changes with a deploy, so it is built once and served with
+// its revision as the ETag.
+func (h *MetaHandler) GetContract(c *gin.Context) {
+	h.once.Do(func() {
+		h.document, h.err = contract.Build(h.routes())
+	})
+	if h.err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to build the API contract", common.CodeInternalError, h.err.Error())
+		return
+	}
+
+	etag := `"` + h.document.Revision + `"`
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "API contract fetched successfully", h.document)
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type MetricsHandler struct {
+	exposition http.Handler
+	token      string
+}
+
+// NewMetricsHandler serves the metrics of the registry, to scrapers presenting the token as a
+// bearer token when one is set
+func NewMetricsHandler(registry *prometheus.Registry, token string) *MetricsHandler {
+	return &MetricsHandler{
+		exposition: promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry}),
+		token:      token,
+	}
+}
+
+// GetMetrics handles GET /metrics
+func (h *MetricsHandler) GetMetrics(c *gin.Context) {
+	if h.token != "" {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+			common.SendError(c, http.StatusUnauthorized, "Invalid metrics token", common.CodeUnauthorized, nil)
+			return
+		}
+	}
+
+	h.exposition.ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+	validate            *validator.Validate
+}
+
+func NewNotificationHandler(notificationService *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		validate:            validator.New(),
+	}
+}
+
+// GetMyNotificationPreferences handles GET /api/me/notification-preferences
+func (h *NotificationHandler) GetMyNotificationPreferences(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		common.SendError(c, http.StatusUnauthorized, "Unauthorized", common.CodeUnauthorized, nil)
+		return
+	}
+
+	preferences, err := h.notificationService.GetPreferences(user.ID)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Notification preferences fetched successfully", preferences)
+}
+
+// UpdateMyNotificationPreferences handles PUT /api/me/notification-preferences
+func (h *NotificationHandler) UpdateMyNotificationPreferences(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		common.SendError(c, http.StatusUnauthorized, "Unauthorized", common.CodeUnauthorized, nil)
+		return
+	}
+
+	var req models.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	preferences, err := h.notificationService.UpdatePreferences(user.ID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "unknown notification event type":
+			common.SendError(c, http.StatusBadRequest, "Unknown notification event type", common.CodeValidationError, nil)
+		case "critical notifications cannot be disabled":
+			common.SendError(c, http.StatusBadRequest, "Critical notifications cannot be disabled", common.CodeValidationError, nil)
+		default:
+			common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		}
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Notification preferences updated successfully", preferences)
+}
+
+// GetMyNotifications handles GET /api/me/notifications
+func (h *NotificationHandler) GetMyNotifications(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		common.SendError(c, http.StatusUnauthorized, "Unauthorized", common.CodeUnauthorized, nil)
+		return
+	}
+
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.notificationService.GetNotifications(user.ID, params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch notifications", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Notifications fetched successfully", response)
+}
+
+// MarkMyNotificationRead handles PUT /api/me/notifications/:id/read
+func (h *NotificationHandler) MarkMyNotificationRead(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		common.SendError(c, http.StatusUnauthorized, "Unauthorized", common.CodeUnauthorized, nil)
+		return
+	}
+
+	notification, err := h.notificationService.MarkNotificationRead(user.ID, id)
+	if err != nil {
+		if err.Error() == "notification not found" {
+			common.SendError(c, http.StatusNotFound, "Notification not found", common.CodeNotFound, nil)
+			return
+		}
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Notification marked as read", notification)
+}
+
+// MarkAllMyNotificationsRead handles PUT /api/me/notifications/read
+func (h *NotificationHandler) MarkAllMyNotificationsRead(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		common.SendError(c, http.StatusUnauthorized, "Unauthorized", common.CodeUnauthorized, nil)
+		return
+	}
+
+	response, err := h.notificationService.MarkAllNotificationsRead(user.ID)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Notifications marked as read", response)
+}
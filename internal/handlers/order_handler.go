@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type OrderHandler struct {
+	orderService *services.OrderService
+	validate     *validator.Validate
+}
+
+func NewOrderHandler(orderService *services.OrderService) *OrderHandler {
+	return &OrderHandler{
+		orderService: orderService,
+		validate:     validator.New(),
+	}
+}
+
+// sendOrderError maps order service errors to API responses
+func sendOrderError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "order not found":
+		common.SendError(c, http.StatusNotFound, "Order not found", common.CodeNotFound, nil)
+	case "product not found":
+		common.SendError(c, http.StatusBadRequest, "Product not found", common.CodeValidationError, nil)
+	case "product not available":
+		common.SendError(c, http.StatusBadRequest, "Product is not available for sale", common.CodeValidationError, nil)
+	case "variant not found":
+		common.SendError(c, http.StatusBadRequest, "Variant not found for the product", common.CodeValidationError, nil)
+	case "variant required":
+		common.SendError(c, http.StatusBadRequest, "Products with variants are sold per variant; a variant is required", common.CodeValidationError, nil)
+	case "discount exceeds line amount":
+		common.SendError(c, http.StatusBadRequest, "Discount exceeds the line amount", common.CodeValidationError, nil)
+	case "gift card price fixed":
+		common.SendError(c, http.StatusBadRequest, "Gift cards sell at their price, without price overrides or discounts", common.CodeValidationError, nil)
+	case "bundle has no components":
+		common.SendError(c, http.StatusBadRequest, "The bundle has no components to sell", common.CodeValidationError, nil)
+	case "customer not found":
+		common.SendError(c, http.StatusBadRequest, "Customer not found", common.CodeValidationError, nil)
+	case "no open shift":
+		common.SendError(c, http.StatusConflict, "Open a shift on this terminal before recording sales", common.CodeConflict, nil)
+	case "invalid status transition":
+		common.SendError(c, http.StatusConflict, err.Error(), common.CodeConflict, nil)
+	default:
+		sendInventoryError(c, err)
+	}
+}
+
+// GetAllOrders handles GET /api/orders
+func (h *OrderHandler) GetAllOrders(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.orderService.GetAllOrders(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch orders", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Orders fetched successfully", response)
+}
+
+// GetOrderById handles GET /api/orders/:id
+func (h *OrderHandler) GetOrderById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	order, err := h.orderService.GetOrderById(id)
+	if err != nil {
+		sendOrderError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Order fetched successfully", order)
+}
+
+// CreateOrder handles POST /api/orders
+func (h *OrderHandler) CreateOrder(c *gin.Context) {
+	var req models.CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	if err := limits.Check(limits.MaxOrderItems, len(req.Items)); err != nil {
+		sendBindError(c, "Invalid request body", err)
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	// Terminals sell at the tax rates of their location, inside their open shift
+	if device, ok := middleware.CurrentDevice(c); ok {
+		req.DeviceID = &device.ID
+		if req.Region == "" {
+			req.Region = device.Location
+		}
+	}
+	if store, ok := middleware.CurrentStore(c); ok {
+		req.StoreID = &store.ID
+	}
+
+	order, err := h.orderService.CreateOrder(&req, activityActor(c))
+	if err != nil {
+		sendOrderError(c, err)
+		return
+	}
+
+	message := "Order placed successfully"
+	if req.Draft {
+		message = "Draft order saved successfully"
+	}
+	common.SendSuccess(c, http.StatusCreated, message, order)
+}
+
+// transitionOrder binds the optional reason and moves the order to the given status
+func (h *OrderHandler) transitionOrder(c *gin.Context, status string, message string) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.OrderStatusRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+			return
+		}
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	order, err := h.orderService.TransitionOrder(id, status, req.Reason, activityActor(c))
+	if err != nil {
+		sendOrderError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, message, order)
+}
+
+// SetOrderCustomer handles PUT /api/orders/:id/customer
+func (h *OrderHandler) SetOrderCustomer(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.SetCustomerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	order, err := h.orderService.SetOrderCustomer(id, &req)
+	if err != nil {
+		sendOrderError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Order customer updated successfully", order)
+}
+
+// PlaceOrder handles PUT /api/orders/:id/place
+func (h *OrderHandler) PlaceOrder(c *gin.Context) {
+	h.transitionOrder(c, models.OrderStatusPlaced, "Order placed successfully")
+}
+
+// FulfillOrder handles PUT /api/orders/:id/fulfill
+func (h *OrderHandler) FulfillOrder(c *gin.Context) {
+	h.transitionOrder(c, models.OrderStatusFulfilled, "Order fulfilled successfully")
+}
+
+// CompleteOrder handles PUT /api/orders/:id/complete
+func (h *OrderHandler) CompleteOrder(c *gin.Context) {
+	h.transitionOrder(c, models.OrderStatusCompleted, "Order completed successfully")
+}
+
+// CancelOrder handles PUT /api/orders/:id/cancel
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	h.transitionOrder(c, models.OrderStatusCancelled, "Order cancelled successfully")
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/testutil"
+)
+
+func TestOrderWorkflow(t *testing.T) {
+	env := testutil.New(t)
+	api := salesAPI(env)
+	cashier := env.CreateUser(t, models.RoleAdmin)
+	product, err := env.Factory.CreateProduct()
+	if err != nil {
+		t.Fatalf("failed to create product: %v", err)
+	}
+
+	var order models.Orders
+	rec := env.Request(t, api, http.MethodPost, "/api/v1/orders", models.CreateOrderRequest{
+		Draft: true,
+		Items: []models.OrderItemRequest{{ProductID: product.ID, Quantity: 3}},
+	}, &cashier)
+	testutil.Decode(t, rec, http.StatusCreated, &order)
+	if order.Status != models.OrderStatusDraft {
+		t.Fatalf("new draft is %s", order.Status)
+	}
+	if order.Total != 3*product.Price {
+		t.Errorf("total is %d, want %d", order.Total, 3*product.Price)
+	}
+	if stock := stockOf(t, env, product.ID); stock != 0 {
+		t.Errorf("draft took stock: %d", stock)
+	}
+
+	testutil.Decode(t, env.Request(t, api, http.MethodPut, orderPath(&order, "/place"), nil, &cashier), http.StatusOK, &order)
+	if order.Status != models.OrderStatusPlaced {
+		t.Fatalf("placed order is %s", order.Status)
+	}
+	if stock := stockOf(t, env, product.ID); stock != -3 {
+		t.Errorf("stock after placing is %d, want -3", stock)
+	}
+
+	// Orders are paid once their payments cover the total
+	payInCash(t, env, api, &order, order.Total-1, &cashier)
+	testutil.Decode(t, env.Request(t, api, http.MethodGet, orderPath(&order, ""), nil, &cashier), http.StatusOK, &order)
+	if order.Status != models.OrderStatusPlaced {
+		t.Fatalf("partly paid order is %s", order.Status)
+	}
+	payInCash(t, env, api, &order, 1, &cashier)
+	testutil.Decode(t, env.Request(t, api, http.MethodGet, orderPath(&order, ""), nil, &cashier), http.StatusOK, &order)
+	if order.Status != models.OrderStatusPaid {
+		t.Fatalf("fully paid order is %s", order.Status)
+	}
+
+	// Paid orders are returned rather than cancelled
+	testutil.Decode(t, env.Request(t, api, http.MethodPut, orderPath(&order, "/cancel"), nil, &cashier), http.StatusConflict, nil)
+
+	testutil.Decode(t, env.Request(t, api, http.MethodPut, orderPath(&order, "/fulfill"), nil, &cashier), http.StatusOK, &order)
+	testutil.Decode(t, env.Request(t, api, http.MethodPut, orderPath(&order, "/complete"), nil, &cashier), http.StatusOK, nil)
+	testutil.Decode(t, env.Request(t, api, http.MethodGet, orderPath(&order, ""), nil, &cashier), http.StatusOK, &order)
+	if order.Status != models.OrderStatusCompleted || order.CompletedAt == nil {
+		t.Errorf("completed order is %s, completed at %v", order.Status, order.CompletedAt)
+	}
+	testutil.Decode(t, env.Request(t, api, http.MethodPut, orderPath(&order, "/fulfill"), nil, &cashier), http.StatusConflict, nil)
+	if stock := stockOf(t, env, product.ID); stock != -3 {
+		t.Errorf("stock after completing is %d, want -3", stock)
+	}
+}
+
+func TestCancelPlacedOrderPutsStockBack(t *testing.T) {
+	env := testutil.New(t)
+	api := salesAPI(env)
+	cashier := env.CreateUser(t, models.RoleAdmin)
+	product, err := env.Factory.CreateProduct()
+	if err != nil {
+		t.Fatalf("failed to create product: %v", err)
+	}
+
+	var order models.Orders
+	rec := env.Request(t, api, http.MethodPost, "/api/v1/orders", models.CreateOrderRequest{
+		Items: []models.OrderItemRequest{{ProductID: product.ID, Quantity: 2}},
+	}, &cashier)
+	testutil.Decode(t, rec, http.StatusCreated, &order)
+	if stock := stockOf(t, env, product.ID); stock != -2 {
+		t.Errorf("stock after placing is %d, want -2", stock)
+	}
+
+	testutil.Decode(t, env.Request(t, api, http.MethodPut, orderPath(&order, "/cancel"), models.OrderStatusRequest{Reason: "Changed mind"}, &cashier), http.StatusOK, &order)
+	if order.Status != models.OrderStatusCancelled || order.StatusReason != "Changed mind" {
+		t.Errorf("cancelled order is %s with reason %q", order.Status, order.StatusReason)
+	}
+	if stock := stockOf(t, env, product.ID); stock != 0 {
+		t.Errorf("stock after cancelling is %d, want 0", stock)
+	}
+	testutil.Decode(t, env.Request(t, api, http.MethodPut, orderPath(&order, "/place"), nil, &cashier), http.StatusConflict, nil)
+}
+
+func TestCreateOrderNeedsPermission(t *testing.T) {
+	env := testutil.New(t)
+	api := salesAPI(env)
+	user := env.CreateUser(t, models.RoleUser)
+	product, err := env.Factory.CreateProduct()
+	if err != nil {
+		t.Fatalf("failed to create product: %v", err)
+	}
+
+	body := models.CreateOrderRequest{Items: []models.OrderItemRequest{{ProductID: product.ID, Quantity: 1}}}
+	testutil.Decode(t, env.Request(t, api, http.MethodPost, "/api/v1/orders", body, nil), http.StatusUnauthorized, nil)
+	testutil.Decode(t, env.Request(t, api, http.MethodPost, "/api/v1/orders", body, &user), http.StatusForbidden, nil)
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/payments"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type PaymentHandler struct {
+	paymentService *services.PaymentService
+	providers      *payments.Registry
+	validate       *validator.Validate
+}
+
+func NewPaymentHandler(paymentService *services.PaymentService, providers *payments.Registry) *PaymentHandler {
+	return &PaymentHandler{
+		paymentService: paymentService,
+		providers:      providers,
+		validate:       validator.New(),
+	}
+}
+
+// sendPaymentError maps payment service errors to API responses
+func sendPaymentError(c *gin.Context, err error) {
+	if declined, ok := payments.AsDeclined(err); ok {
+		common.SendError(c, http.StatusPaymentRequired, "Payment declined", common.CodePaymentRequired, declined.Reason)
+		return
+	}
+
+	switch err.Error() {
+	case "order not found":
+		common.SendError(c, http.StatusNotFound, "Order not found", common.CodeNotFound, nil)
+	case "payment not found":
+		common.SendError(c, http.StatusNotFound, "Payment not found", common.CodeNotFound, nil)
+	case "unsupported payment method":
+		common.SendError(c, http.StatusBadRequest, "Unsupported payment method", common.CodeValidationError, nil)
+	case "tendered amount only applies to cash":
+		common.SendError(c, http.StatusBadRequest, "A tendered amount only applies to cash payments", common.CodeValidationError, nil)
+	case "tendered amount below payment":
+		common.SendError(c, http.StatusBadRequest, "Tendered amount is below the payment amount", common.CodeValidationError, nil)
+	case "payment exceeds balance":
+		common.SendError(c, http.StatusBadRequest, "Payment exceeds the order's outstanding balance", common.CodeValidationError, nil)
+	case "refund exceeds payment":
+		common.SendError(c, http.StatusBadRequest, "Refund exceeds the amount left on the payment", common.CodeValidationError, nil)
+	case "order has no customer":
+		common.SendError(c, http.StatusBadRequest, "Only orders with a customer can be paid with loyalty points", common.CodeValidationError, nil)
+	case "amount not in whole points":
+		common.SendError(c, http.StatusBadRequest, "Loyalty payments must be worth a whole number of points", common.CodeValidationError, nil)
+	case "insufficient loyalty points":
+		common.SendError(c, http.StatusConflict, "The customer doesn't have enough loyalty points", common.CodeConflict, nil)
+	case "gift card not found":
+		common.SendError(c, http.StatusBadRequest, "Gift card not found", common.CodeValidationError, nil)
+	case "gift card void":
+		common.SendError(c, http.StatusConflict, "The gift card has been voided", common.CodeConflict, nil)
+	case "insufficient gift card balance":
+		common.SendError(c, http.StatusConflict, "The gift card doesn't have enough balance", common.CodeConflict, nil)
+	case "order not payable":
+		common.SendError(c, http.StatusConflict, "Only placed orders take payments", common.CodeConflict, nil)
+	case "payment not authorized":
+		common.SendError(c, http.StatusConflict, "Only authorized payments can be captured", common.CodeConflict, nil)
+	case "payment not refundable":
+		common.SendError(c, http.StatusConflict, "Only captured payments can be refunded", common.CodeConflict, nil)
+	case "invalid status transition":
+		common.SendError(c, http.StatusConflict, err.Error(), common.CodeConflict, nil)
+	default:
+		sendOrderError(c, err)
+	}
+}
+
+// GetPaymentMethods handles GET /api/payments/methods
+func (h *PaymentHandler) GetPaymentMethods(c *gin.Context) {
+	common.SendSuccess(c, http.StatusOK, "Payment methods fetched successfully", h.providers.Methods())
+}
+
+// GetOrderPayments handles GET /api/orders/:id/payments
+func (h *PaymentHandler) GetOrderPayments(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.paymentService.GetOrderPayments(id)
+	if err != nil {
+		sendPaymentError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Payments fetched successfully", response)
+}
+
+// CreatePayment handles POST /api/orders/:id/payments
+func (h *PaymentHandler) CreatePayment(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.CreatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	payment, err := h.paymentService.CreatePayment(c.Request.Context(), id, &req, activityActor(c))
+	if err != nil {
+		sendPaymentError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Payment recorded successfully", payment)
+}
+
+// CapturePayment handles POST /api/orders/:id/payments/:paymentId/capture
+func (h *PaymentHandler) CapturePayment(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+	paymentID, ok := binding.ID(c, "paymentId")
+	if !ok {
+		return
+	}
+
+	payment, err := h.paymentService.CapturePayment(c.Request.Context(), id, paymentID, activityActor(c))
+	if err != nil {
+		sendPaymentError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Payment captured successfully", payment)
+}
+
+// RefundPayment handles POST /api/orders/:id/payments/:paymentId/refund
+func (h *PaymentHandler) RefundPayment(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+	paymentID, ok := binding.ID(c, "paymentId")
+	if !ok {
+		return
+	}
+
+	var req models.RefundPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	payment, err := h.paymentService.RefundPayment(c.Request.Context(), id, paymentID, &req, activityActor(c))
+	if err != nil {
+		sendPaymentError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Payment refunded successfully", payment)
+}
+
+// GetReconciliation handles GET /api/payments/reconciliation
+func (h *PaymentHandler) GetReconciliation(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.paymentService.GetReconciliation(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch reconciliation", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Reconciliation fetched successfully", response)
+}
+
+// StripeWebhook handles POST /api/webhooks/stripe
+func (h *PaymentHandler) StripeWebhook(c *gin.Context) {
+	payload, err := c.GetRawData()
+	if err != nil {
+		sendBindError(c, "Invalid request body", err)
+		return
+	}
+
+	if err := h.paymentService.ReceiveStripeWebhook(payload, c.GetHeader("Stripe-Signature")); err != nil {
+		switch err.Error() {
+		case "invalid webhook signature":
+			common.SendError(c, http.StatusBadRequest, "Invalid webhook signature", common.CodeInvalidWebhookSignature, nil)
+		case "invalid webhook payload":
+			common.SendError(c, http.StatusBadRequest, "Invalid webhook payload", common.CodeInvalidRequest, nil)
+		default:
+			slog.ErrorContext(c.Request.Context(), "Stripe webhook: failed to store event", "error", err)
+			common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		}
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Webhook received successfully", nil)
+}
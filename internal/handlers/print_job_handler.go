@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	// maxPrintJobWait caps how long a terminal can long-poll for its next job
+	maxPrintJobWait = 30 * time.Second
+	// printJobPollInterval is how often the queue is checked while long-polling
+	printJobPollInterval = time.Second
+)
+
+type PrintJobHandler struct {
+	printJobService *services.PrintJobService
+	validate        *validator.Validate
+}
+
+func NewPrintJobHandler(printJobService *services.PrintJobService) *PrintJobHandler {
+	return &PrintJobHandler{
+		printJobService: printJobService,
+		validate:        validator.New(),
+	}
+}
+
+// sendPrintJobError maps print job service errors to API responses
+func sendPrintJobError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "print job not found":
+		common.SendError(c, http.StatusNotFound, "Print job not found", common.CodeNotFound, nil)
+	case "device not found":
+		common.SendError(c, http.StatusNotFound, "Device not found", common.CodeNotFound, nil)
+	case "device is not active":
+		common.SendError(c, http.StatusConflict, "Device is not active", common.CodeDeviceDeactivated, nil)
+	case "device is in another store":
+		common.SendError(c, http.StatusForbidden, "Device is in another store", common.CodeForbidden, nil)
+	case "print job is not being printed", "print job can no longer be cancelled", "print job can not be retried":
+		common.SendError(c, http.StatusConflict, err.Error(), common.CodeConflict, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// GetAllPrintJobs handles GET /api/print-jobs
+func (h *PrintJobHandler) GetAllPrintJobs(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.printJobService.GetAllPrintJobs(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch print jobs", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Print jobs fetched successfully", response)
+}
+
+// GetPrintJobById handles GET /api/print-jobs/:id
+func (h *PrintJobHandler) GetPrintJobById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	job, err := h.printJobService.GetPrintJobById(id)
+	if err != nil {
+		sendPrintJobError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Print job fetched successfully", job)
+}
+
+// CreatePrintJob handles POST /api/print-jobs
+func (h *PrintJobHandler) CreatePrintJob(c *gin.Context) {
+	var req models.CreatePrintJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	if store, ok := middleware.CurrentStore(c); ok {
+		req.StoreID = &store.ID
+	}
+
+	user, _ := middleware.CurrentUser(c)
+	job, err := h.printJobService.CreatePrintJob(&req, user.ID)
+	if err != nil {
+		sendPrintJobError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Print job queued successfully", job)
+}
+
+// CancelPrintJob handles PUT /api/print-jobs/:id/cancel
+func (h *PrintJobHandler) CancelPrintJob(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	job, err := h.printJobService.CancelPrintJob(id)
+	if err != nil {
+		sendPrintJobError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Print job cancelled successfully", job)
+}
+
+// RetryPrintJob handles PUT /api/print-jobs/:id/retry
+func (h *PrintJobHandler) RetryPrintJob(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	job, err := h.printJobService.RetryPrintJob(id)
+	if err != nil {
+		sendPrintJobError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Print job requeued successfully", job)
+}
+
+// ClaimNextPrintJob handles GET /api/terminal/print-jobs/next.
+// Terminals can pass wait=<seconds> to long-poll until a job is available.
+func (h *PrintJobHandler) ClaimNextPrintJob(c *gin.Context) {
+	device, ok := middleware.CurrentDevice(c)
+	if !ok {
+		common.SendError(c, http.StatusUnauthorized, "Device token required", common.CodeInvalidDeviceToken, nil)
+		return
+	}
+
+	seconds, ok := binding.Int(c, "wait", 0, 0)
+	if !ok {
+		return
+	}
+	wait := min(time.Duration(seconds)*time.Second, maxPrintJobWait)
+
+	deadline := time.Now().Add(wait)
+	for {
+		job, err := h.printJobService.ClaimNextPrintJob(device.ID)
+		if err != nil {
+			sendPrintJobError(c, err)
+			return
+		}
+		if job != nil {
+			common.SendSuccess(c, http.StatusOK, "Print job claimed successfully", job)
+			return
+		}
+
+		if time.Now().Add(printJobPollInterval).After(deadline) {
+			c.Status(http.StatusNoContent)
+			return
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-time.After(printJobPollInterval):
+		}
+	}
+}
+
+// AckPrintJob handles POST /api/terminal/print-jobs/:id/ack
+func (h *PrintJobHandler) AckPrintJob(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	device, ok := middleware.CurrentDevice(c)
+	if !ok {
+		common.SendError(c, http.StatusUnauthorized, "Device token required", common.CodeInvalidDeviceToken, nil)
+		return
+	}
+
+	var req models.AckPrintJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	job, err := h.printJobService.AckPrintJob(device.ID, id, &req)
+	if err != nil {
+		sendPrintJobError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Print job acknowledged successfully", job)
+}
@@ -0,0 +1,407 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/export"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ProductHandler struct {
+	productService *services.ProductService
+	validate       *validator.Validate
+}
+
+func NewProductHandler(productService *services.ProductService) *ProductHandler {
+	return &ProductHandler{
+		productService: productService,
+		validate:       validator.New(),
+	}
+}
+
+// sendProductError maps product service errors to API responses
+func sendProductError(c *gin.Context, err error) {
+	if exceeded, ok := limits.AsExceeded(err); ok {
+		common.SendError(c, http.StatusUnprocessableEntity, "Limit exceeded", common.CodeLimitExceeded, exceeded)
+		return
+	}
+
+	switch err.Error() {
+	case "product not found":
+		common.SendError(c, http.StatusNotFound, "Product not found", common.CodeNotFound, nil)
+	case "variant not found":
+		common.SendError(c, http.StatusNotFound, "Variant not found", common.CodeNotFound, nil)
+	case "product has no options":
+		common.SendError(c, http.StatusBadRequest, "Product has no options to generate variants from", common.CodeValidationError, nil)
+	case "duplicate option name":
+		common.SendError(c, http.StatusBadRequest, "Option names must be unique", common.CodeValidationError, nil)
+	case "duplicate option value":
+		common.SendError(c, http.StatusBadRequest, "Option values must be unique within an option", common.CodeValidationError, nil)
+	case "category not found":
+		common.SendError(c, http.StatusBadRequest, "Category not found", common.CodeValidationError, nil)
+	case "tax class not found":
+		common.SendError(c, http.StatusBadRequest, "Tax class not found", common.CodeValidationError, nil)
+	case "sku already exists":
+		common.SendError(c, http.StatusConflict, "SKU already exists", common.CodeConflict, nil)
+	case "unit not found":
+		common.SendError(c, http.StatusBadRequest, "Unit of measure not found", common.CodeValidationError, nil)
+	case "variant required":
+		common.SendError(c, http.StatusBadRequest, "The component has variants; a variant is required", common.CodeValidationError, nil)
+	case "invalid bundle":
+		common.SendError(c, http.StatusBadRequest, "Bundles can't be gift cards or tracked by serial or batch", common.CodeValidationError, nil)
+	case "product not a bundle":
+		common.SendError(c, http.StatusBadRequest, "Product is not a bundle", common.CodeValidationError, nil)
+	case "product is a bundle", "invalid bundle component":
+		common.SendError(c, http.StatusBadRequest, "Bundle components can't be bundles, gift cards or tracked products, nor the bundle itself", common.CodeValidationError, nil)
+	case "duplicate bundle component":
+		common.SendError(c, http.StatusBadRequest, "Each component may only be listed once", common.CodeValidationError, nil)
+	case "product unit in use":
+		common.SendError(c, http.StatusConflict, "The product's unit can't change once its stock has moved", common.CodeConflict, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// GetAllProducts handles GET /api/products. variants=nest includes each product's variants,
+// variants=flatten lists every variant as a row of its own.
+func (h *ProductHandler) GetAllProducts(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	variants, ok := binding.Enum(c, "variants", "", models.ProductVariantsNest, models.ProductVariantsFlatten)
+	if !ok {
+		return
+	}
+
+	response, err := h.productService.GetAllProducts(params, variants)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch products", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Products fetched successfully", response)
+}
+
+// GetProductById handles GET /api/products/:id
+func (h *ProductHandler) GetProductById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	product, err := h.productService.GetProductById(id)
+	if err != nil {
+		sendProductError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Product fetched successfully", product)
+}
+
+// LookupBarcode handles GET /api/products/barcode/:code
+func (h *ProductHandler) LookupBarcode(c *gin.Context) {
+	result, err := h.productService.LookupBarcode(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		sendProductError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Barcode resolved successfully", result)
+}
+
+// productExportColumns are the columns of a catalog export. They are the import's field names,
+// so an edited export can be uploaded to POST /api/products/import as it is.
+var productExportColumns = []string{"name", "sku", "barcode", "description", "price", "cost", "category", "status", "reorder_point"}
+
+// ExportProducts handles GET /api/products/export. It takes the search and filters of the
+// product listing; prices are in minor currency units.
+func (h *ProductHandler) ExportProducts(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid export format", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, format.Filename("products")))
+	c.Status(http.StatusOK)
+
+	writer, err := export.NewWriter(format, c.Writer, "Products")
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to create products export writer", "error", err)
+		return
+	}
+
+	if err := writer.WriteRow(productExportColumns); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to write products export header", "error", err)
+		return
+	}
+
+	err = h.productService.ExportProducts(params, func(product *models.Products, category string) error {
+		reorderPoint := ""
+		if product.ReorderPoint != nil {
+			reorderPoint = strconv.FormatInt(*product.ReorderPoint, 10)
+		}
+		return writer.WriteRow([]string{
+			product.Name,
+			product.SKU,
+			product.Barcode,
+			product.Description,
+			strconv.FormatInt(product.Price, 10),
+			strconv.FormatInt(product.Cost, 10),
+			category,
+			product.Status,
+			reorderPoint,
+		})
+	})
+	if err != nil {
+		// Headers are already sent at this point, so the error can only be logged
+		slog.ErrorContext(c.Request.Context(), "Failed to export products", "error", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to finalize products export", "error", err)
+	}
+}
+
+// CreateProduct handles POST /api/products
+func (h *ProductHandler) CreateProduct(c *gin.Context) {
+	var req models.CreateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	product, err := h.productService.CreateProduct(&req)
+	if err != nil {
+		sendProductError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Product created successfully", product)
+}
+
+// UpdateProduct handles PUT /api/products/:id
+func (h *ProductHandler) UpdateProduct(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	product, err := h.productService.UpdateProduct(id, &req)
+	if err != nil {
+		sendProductError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Product updated successfully", product)
+}
+
+// DeleteProduct handles DELETE /api/products/:id
+func (h *ProductHandler) DeleteProduct(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	product, err := h.productService.DeleteProduct(id)
+	if err != nil {
+		sendProductError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Product deleted successfully", product)
+}
+
+// GetProductVariants handles GET /api/products/:id/variants
+func (h *ProductHandler) GetProductVariants(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.productService.GetProductVariants(id)
+	if err != nil {
+		sendProductError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Product variants fetched successfully", response)
+}
+
+// SetProductOptions handles PUT /api/products/:id/options
+func (h *ProductHandler) SetProductOptions(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.SetProductOptionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	response, err := h.productService.SetProductOptions(id, &req)
+	if err != nil {
+		sendProductError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Product options updated successfully", response)
+}
+
+// GetBundleComponents handles GET /api/products/:id/components
+func (h *ProductHandler) GetBundleComponents(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	components, err := h.productService.GetBundleComponents(id)
+	if err != nil {
+		sendProductError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Bundle components fetched successfully", components)
+}
+
+// SetBundleComponents handles PUT /api/products/:id/components
+func (h *ProductHandler) SetBundleComponents(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.SetBundleComponentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	components, err := h.productService.SetBundleComponents(id, &req)
+	if err != nil {
+		sendProductError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Bundle components updated successfully", components)
+}
+
+// GenerateProductVariants handles POST /api/products/:id/variants/generate
+func (h *ProductHandler) GenerateProductVariants(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.productService.GenerateProductVariants(id)
+	if err != nil {
+		sendProductError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Product variants generated successfully", response)
+}
+
+// UpdateProductVariant handles PUT /api/products/:id/variants/:variantId
+func (h *ProductHandler) UpdateProductVariant(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+	variantID, ok := binding.ID(c, "variantId")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateProductVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	variant, err := h.productService.UpdateProductVariant(id, variantID, &req)
+	if err != nil {
+		sendProductError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Product variant updated successfully", variant)
+}
+
+// DeleteProductVariant handles DELETE /api/products/:id/variants/:variantId
+func (h *ProductHandler) DeleteProductVariant(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+	variantID, ok := binding.ID(c, "variantId")
+	if !ok {
+		return
+	}
+
+	variant, err := h.productService.DeleteProductVariant(id, variantID)
+	if err != nil {
+		sendProductError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Product variant deleted successfully", variant)
+}
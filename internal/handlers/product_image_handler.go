@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ProductImageHandler struct {
+	productImageService *services.ProductImageService
+	validate            *validator.Validate
+}
+
+func NewProductImageHandler(productImageService *services.ProductImageService) *ProductImageHandler {
+	return &ProductImageHandler{
+		productImageService: productImageService,
+		validate:            validator.New(),
+	}
+}
+
+// sendProductImageError maps product image service errors to API responses
+func sendProductImageError(c *gin.Context, err error) {
+	if exceeded, ok := limits.AsExceeded(err); ok {
+		common.SendError(c, http.StatusUnprocessableEntity, "Limit exceeded", common.CodeLimitExceeded, exceeded)
+		return
+	}
+	var quotaErr *services.StorageQuotaError
+	if errors.As(err, &quotaErr) {
+		common.SendError(c, http.StatusRequestEntityTooLarge, "Storage quota exceeded", common.CodeStorageQuotaExceeded, quotaErr)
+		return
+	}
+
+	switch err.Error() {
+	case "product not found":
+		common.SendError(c, http.StatusNotFound, "Product not found", common.CodeNotFound, nil)
+	case "image not found":
+		common.SendError(c, http.StatusNotFound, "Image not found", common.CodeNotFound, nil)
+	case "unsupported image type":
+		common.SendError(c, http.StatusBadRequest, "Images must be JPEG, PNG or GIF files", common.CodeValidationError, nil)
+	case "invalid image order":
+		common.SendError(c, http.StatusBadRequest, "The order must list every image of the product once", common.CodeValidationError, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// storageOwner returns who the files a request uploads are charged to: the tenant of its host
+// and the store it works in
+func storageOwner(c *gin.Context) models.StorageOwner {
+	var owner models.StorageOwner
+	if tenant, ok := middleware.CurrentTenant(c); ok {
+		owner.TenantID = tenant.ID
+	}
+	if store, ok := middleware.CurrentStore(c); ok {
+		owner.StoreID = store.ID
+	}
+	return owner
+}
+
+// GetProductImages handles GET /api/products/:id/images
+func (h *ProductImageHandler) GetProductImages(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	images, err := h.productImageService.GetProductImages(id)
+	if err != nil {
+		sendProductImageError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Product images fetched successfully", images)
+}
+
+// UploadProductImages handles POST /api/products/:id/images. The images are sent as multipart
+// form data, one or more files in the files field.
+func (h *ProductImageHandler) UploadProductImages(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+	headers := form.File["files"]
+	if len(headers) == 0 {
+		common.SendError(c, http.StatusBadRequest, "At least one image file is required", common.CodeInvalidRequest, nil)
+		return
+	}
+
+	uploads := make([]models.ProductImageUpload, 0, len(headers))
+	for _, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			common.SendError(c, http.StatusBadRequest, "Invalid image file", common.CodeInvalidRequest, err.Error())
+			return
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			common.SendError(c, http.StatusBadRequest, "Invalid image file", common.CodeInvalidRequest, err.Error())
+			return
+		}
+		uploads = append(uploads, models.ProductImageUpload{Filename: header.Filename, Data: data})
+	}
+
+	images, err := h.productImageService.UploadProductImages(c.Request.Context(), id, storageOwner(c), uploads)
+	if err != nil {
+		sendProductImageError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Product images uploaded successfully", images)
+}
+
+// ReorderProductImages handles PUT /api/products/:id/images/order
+func (h *ProductImageHandler) ReorderProductImages(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.ReorderProductImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	images, err := h.productImageService.ReorderProductImages(id, &req)
+	if err != nil {
+		sendProductImageError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Product images reordered successfully", images)
+}
+
+// SetPrimaryProductImage handles PUT /api/products/:id/images/:imageId/primary
+func (h *ProductImageHandler) SetPrimaryProductImage(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+	imageID, ok := binding.ID(c, "imageId")
+	if !ok {
+		return
+	}
+
+	images, err := h.productImageService.SetPrimaryProductImage(id, imageID)
+	if err != nil {
+		sendProductImageError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Primary image updated successfully", images)
+}
+
+// DeleteProductImage handles DELETE /api/products/:id/images/:imageId
+func (h *ProductImageHandler) DeleteProductImage(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+	imageID, ok := binding.ID(c, "imageId")
+	if !ok {
+		return
+	}
+
+	images, err := h.productImageService.DeleteProductImage(c.Request.Context(), id, imageID)
+	if err != nil {
+		sendProductImageError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Product image deleted successfully", images)
+}
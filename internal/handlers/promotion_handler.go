@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type PromotionHandler struct {
+	promotionService *services.PromotionService
+	validate         *validator.Validate
+}
+
+func NewPromotionHandler(promotionService *services.PromotionService) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: promotionService,
+		validate:         validator.New(),
+	}
+}
+
+// sendPromotionError maps promotion service errors to API responses
+func sendPromotionError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "promotion not found":
+		common.SendError(c, http.StatusNotFound, "Promotion not found", common.CodeNotFound, nil)
+	case "percentage exceeds 100%":
+		common.SendError(c, http.StatusBadRequest, "Percentage promotions take at most 10000 basis points (100%)", common.CodeValidationError, nil)
+	case "promotion ends before it starts":
+		common.SendError(c, http.StatusBadRequest, "Promotion must end after it starts", common.CodeValidationError, nil)
+	case "product not found":
+		common.SendError(c, http.StatusBadRequest, "Product not found", common.CodeValidationError, nil)
+	case "category not found":
+		common.SendError(c, http.StatusBadRequest, "Category not found", common.CodeValidationError, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// GetAllPromotions handles GET /api/promotions
+func (h *PromotionHandler) GetAllPromotions(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.promotionService.GetAllPromotions(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch promotions", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Promotions fetched successfully", response)
+}
+
+// GetPromotionById handles GET /api/promotions/:id
+func (h *PromotionHandler) GetPromotionById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	promotion, err := h.promotionService.GetPromotionById(id)
+	if err != nil {
+		sendPromotionError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Promotion fetched successfully", promotion)
+}
+
+// CreatePromotion handles POST /api/promotions
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	var req models.CreatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	promotion, err := h.promotionService.CreatePromotion(&req)
+	if err != nil {
+		sendPromotionError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Promotion created successfully", promotion)
+}
+
+// UpdatePromotion handles PUT /api/promotions/:id
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	promotion, err := h.promotionService.UpdatePromotion(id, &req)
+	if err != nil {
+		sendPromotionError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Promotion updated successfully", promotion)
+}
+
+// DeletePromotion handles DELETE /api/promotions/:id
+func (h *PromotionHandler) DeletePromotion(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	promotion, err := h.promotionService.DeletePromotion(id)
+	if err != nil {
+		sendPromotionError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Promotion deleted successfully", promotion)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type QuotaHandler struct {
+	quotaService *services.QuotaService
+	validate     *validator.Validate
+}
+
+func NewQuotaHandler(quotaService *services.QuotaService) *QuotaHandler {
+	return &QuotaHandler{
+		quotaService: quotaService,
+		validate:     validator.New(),
+	}
+}
+
+// sendQuotaError maps quota service errors to API responses
+func sendQuotaError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "user not found":
+		common.SendError(c, http.StatusNotFound, "User not found", common.CodeNotFound, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// GetUserQuota handles GET /api/user/:id/quota
+func (h *QuotaHandler) GetUserQuota(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	status, err := h.quotaService.GetQuota(c.Request.Context(), id)
+	if err != nil {
+		sendQuotaError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Quota fetched successfully", status)
+}
+
+// UpdateUserQuota handles PUT /api/user/:id/quota
+func (h *QuotaHandler) UpdateUserQuota(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateUserQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	status, err := h.quotaService.UpdateQuota(c.Request.Context(), id, &req, activityActor(c))
+	if err != nil {
+		sendQuotaError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Quota updated successfully", status)
+}
+
+// ResetUserQuota handles POST /api/user/:id/quota/reset
+func (h *QuotaHandler) ResetUserQuota(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	status, err := h.quotaService.ResetQuota(c.Request.Context(), id)
+	if err != nil {
+		sendQuotaError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Quota reset successfully", status)
+}
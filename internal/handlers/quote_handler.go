@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type QuoteHandler struct {
+	quoteService *services.QuoteService
+	validate     *validator.Validate
+}
+
+func NewQuoteHandler(quoteService *services.QuoteService) *QuoteHandler {
+	return &QuoteHandler{
+		quoteService: quoteService,
+		validate:     validator.New(),
+	}
+}
+
+// sendQuoteError maps quote service errors to API responses
+func sendQuoteError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "quote not found":
+		common.SendError(c, http.StatusNotFound, "Quote not found", common.CodeNotFound, nil)
+	case err.Error() == "expiry date in the past":
+		common.SendError(c, http.StatusBadRequest, "The expiry date must be in the future", common.CodeValidationError, nil)
+	case err.Error() == "quote has no email":
+		common.SendError(c, http.StatusBadRequest, "The quote has no email to send it to; an email is required", common.CodeValidationError, nil)
+	case err.Error() == "quote closed":
+		common.SendError(c, http.StatusConflict, "Only draft and sent quotes can be revised or sent", common.CodeConflict, nil)
+	case err.Error() == "quote expired":
+		common.SendError(c, http.StatusConflict, "The quote has expired; revise it with a new expiry date", common.CodeConflict, nil)
+	case err.Error() == "quote not accepted":
+		common.SendError(c, http.StatusConflict, "Only accepted quotes can be converted into orders", common.CodeConflict, nil)
+	case err.Error() == "quote already converted":
+		common.SendError(c, http.StatusConflict, "The quote has already been converted into an order", common.CodeConflict, nil)
+	case strings.HasPrefix(err.Error(), "failed to send"):
+		common.SendError(c, http.StatusServiceUnavailable, "The quote email couldn't be sent; try again later", common.CodeInternalError, err.Error())
+	case strings.HasPrefix(err.Error(), "invalid receipt template"):
+		sendReceiptError(c, err)
+	default:
+		sendOrderError(c, err)
+	}
+}
+
+// bindQuoteRequest binds and validates a quote draft or revision, stopping the request when it
+// is invalid
+func (h *QuoteHandler) bindQuoteRequest(c *gin.Context) (*models.QuoteRequest, bool) {
+	var req models.QuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return nil, false
+	}
+
+	if err := limits.Check(limits.MaxOrderItems, len(req.Items)); err != nil {
+		sendBindError(c, "Invalid request body", err)
+		return nil, false
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return nil, false
+	}
+	return &req, true
+}
+
+// quoteLocation returns the location whose receipt template a quote is shown with: the one
+// asked for, or the terminal's
+func quoteLocation(c *gin.Context, location string) (uint, string) {
+	var tenantID uint
+	if tenant, ok := middleware.CurrentTenant(c); ok {
+		tenantID = tenant.ID
+	}
+	if device, ok := middleware.CurrentDevice(c); ok && location == "" {
+		location = device.Location
+	}
+	return tenantID, location
+}
+
+// GetAllQuotes handles GET /api/quotes
+func (h *QuoteHandler) GetAllQuotes(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.quoteService.GetAllQuotes(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch quotes", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Quotes fetched successfully", response)
+}
+
+// GetQuoteById handles GET /api/quotes/:id
+func (h *QuoteHandler) GetQuoteById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	quote, err := h.quoteService.GetQuoteById(id)
+	if err != nil {
+		sendQuoteError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Quote fetched successfully", quote)
+}
+
+// GetQuoteVersions handles GET /api/quotes/:id/versions
+func (h *QuoteHandler) GetQuoteVersions(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	versions, err := h.quoteService.GetQuoteVersions(id)
+	if err != nil {
+		sendQuoteError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Quote versions fetched successfully", versions)
+}
+
+// GetQuotePDF handles GET /api/quotes/:id/pdf
+func (h *QuoteHandler) GetQuotePDF(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var query models.QuotePDFQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(query); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	tenantID, location := quoteLocation(c, query.Location)
+	content, number, err := h.quoteService.RenderQuote(c.Request.Context(), id, tenantID, location)
+	if err != nil {
+		sendQuoteError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="quote-%s.pdf"`, number))
+	c.Data(http.StatusOK, "application/pdf", content)
+}
+
+// CreateQuote handles POST /api/quotes
+func (h *QuoteHandler) CreateQuote(c *gin.Context) {
+	req, ok := h.bindQuoteRequest(c)
+	if !ok {
+		return
+	}
+
+	// Terminals quote at the tax rates of their location
+	if device, ok := middleware.CurrentDevice(c); ok && req.Region == "" {
+		req.Region = device.Location
+	}
+	if store, ok := middleware.CurrentStore(c); ok {
+		req.StoreID = &store.ID
+	}
+
+	quote, err := h.quoteService.CreateQuote(req, activityActor(c))
+	if err != nil {
+		sendQuoteError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Quote created successfully", quote)
+}
+
+// UpdateQuote handles PUT /api/quotes/:id
+func (h *QuoteHandler) UpdateQuote(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := h.bindQuoteRequest(c)
+	if !ok {
+		return
+	}
+
+	quote, err := h.quoteService.UpdateQuote(id, req, activityActor(c))
+	if err != nil {
+		sendQuoteError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Quote updated successfully", quote)
+}
+
+// SendQuote handles POST /api/quotes/:id/send
+func (h *QuoteHandler) SendQuote(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.SendQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	var tenantID uint
+	tenantID, req.Location = quoteLocation(c, req.Location)
+	quote, err := h.quoteService.SendQuote(c.Request.Context(), id, &req, tenantID)
+	if err != nil {
+		sendQuoteError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Quote sent successfully", quote)
+}
+
+// AcceptQuote handles PUT /api/quotes/:id/accept
+func (h *QuoteHandler) AcceptQuote(c *gin.Context) {
+	h.answer(c, "Quote accepted successfully", h.quoteService.AcceptQuote)
+}
+
+// RejectQuote handles PUT /api/quotes/:id/reject
+func (h *QuoteHandler) RejectQuote(c *gin.Context) {
+	h.answer(c, "Quote rejected successfully", h.quoteService.RejectQuote)
+}
+
+// answer records the customer's answer to the quote in the :id parameter with one of the
+// service's steps
+func (h *QuoteHandler) answer(c *gin.Context, message string, step func(id uint, req *models.QuoteStatusRequest) (*models.Quotes, error)) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.QuoteStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	quote, err := step(id, &req)
+	if err != nil {
+		sendQuoteError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, message, quote)
+}
+
+// ConvertQuote handles POST /api/quotes/:id/convert
+func (h *QuoteHandler) ConvertQuote(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.ConvertQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	// Terminals sell inside their open shift
+	var deviceID *uint
+	if device, ok := middleware.CurrentDevice(c); ok {
+		deviceID = &device.ID
+	}
+
+	order, err := h.quoteService.ConvertQuote(id, &req, deviceID, activityActor(c))
+	if err != nil {
+		sendQuoteError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Order created from quote successfully", order)
+}
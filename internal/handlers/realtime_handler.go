@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/realtime"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// realtimeWriteWait is how long a write to the client may take
+	realtimeWriteWait = 10 * time.Second
+	// realtimePongWait is how long the client may stay silent, pongs included, before the
+	// connection is considered dead
+	realtimePongWait = 60 * time.Second
+	// realtimePingPeriod is how often the client is pinged; shorter than realtimePongWait
+	realtimePingPeriod = realtimePongWait * 9 / 10
+	// realtimeMaxMessage caps the size of a client message
+	realtimeMaxMessage = 4096
+)
+
+// realtimeTopicPermissions is the permission users need to subscribe to each topic. Orders,
+// stock and print jobs are further limited to the stores the user may work in.
+var realtimeTopicPermissions = map[string]string{
+	models.RealtimeTopicOrders:    models.PermissionOrdersView,
+	models.RealtimeTopicStock:     models.PermissionInventoryView,
+	models.RealtimeTopicUsers:     models.PermissionUsersView,
+	models.RealtimeTopicPrintJobs: models.PermissionPrintJobsView,
+}
+
+// realtimeTerminalTopics are the topics terminals may subscribe to; they only get the events
+// of their own device
+var realtimeTerminalTopics = []string{
+	models.RealtimeTopicPrintJobs,
+	models.RealtimeTopicTerminalConfig,
+}
+
+type RealtimeHandler struct {
+	hub               *realtime.Hub
+	permissionService *services.PermissionService
+	storeService      *services.StoreService
+	allowedOrigin     string
+	upgrader          websocket.Upgrader
+}
+
+// NewRealtimeHandler streams the events of the hub. Browsers may only connect from the origins
+// CORS allows, since the connection is authenticated by cookie.
+func NewRealtimeHandler(hub *realtime.Hub, permissionService *services.PermissionService, storeService *services.StoreService, allowedOrigin string) *RealtimeHandler {
+	return &RealtimeHandler{
+		hub:               hub,
+		permissionService: permissionService,
+		storeService:      storeService,
+		allowedOrigin:     allowedOrigin,
+		upgrader: websocket.Upgrader{
+			// Origins are checked by Connect before upgrading, against the tenant's CORS origins
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// realtimeSession is what differs between the connections of users and of terminals
+type realtimeSession struct {
+	access realtime.Access
+	// allow answers with an error when the connection may not follow the topic, or nil
+	allow func(topic string) *models.RealtimeServerMessage
+	// expired fires when the credentials the connection was opened with expire
+	expired <-chan time.Time
+}
+
+// originAllowed reports whether the request comes from an origin CORS allows. Clients other
+// than browsers send no Origin.
+func (h *RealtimeHandler) originAllowed(c *gin.Context) bool {
+	origin := c.GetHeader("Origin")
+	if origin == "" {
+		return true
+	}
+	if tenant, ok := middleware.CurrentTenant(c); ok && len(tenant.CORSAllowedOrigins) > 0 {
+		return slices.Contains(tenant.CORSAllowedOrigins, origin)
+	}
+	return origin == h.allowedOrigin
+}
+
+// tokenExpiry returns when the request's access token expires. The auth middleware has
+// already verified the token.
+func tokenExpiry(c *gin.Context) (time.Time, bool) {
+	accessToken, err := c.Cookie("access_token")
+	if err != nil {
+		return time.Time{}, false
+	}
+	claims := &models.Claims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(accessToken, claims); err != nil || claims.ExpiresAt == nil {
+		return time.Time{}, false
+	}
+	return claims.ExpiresAt.Time, true
+}
+
+// Connect handles GET /api/ws, upgrading to a WebSocket that sends the events of the topics the
+// client subscribes to, e.g. {"type": "subscribe", "topics": ["orders", "stock"]}. Events are
+// JSON objects with a type, topic, data and occurred_at. Store events are limited to the store
+// picked with X-Store-ID or, without one, to the stores the user is assigned to, like the REST
+// listings. The connection is closed when the access token it was opened with expires.
+func (h *RealtimeHandler) Connect(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		common.SendError(c, http.StatusUnauthorized, "Authentication required", common.CodeUnauthorized, nil)
+		return
+	}
+	if !h.originAllowed(c) {
+		common.SendError(c, http.StatusForbidden, "Origin not allowed", common.CodeForbidden, nil)
+		return
+	}
+
+	var access realtime.Access
+	if store, ok := middleware.CurrentStore(c); ok {
+		access.Stores = []uint{store.ID}
+	} else {
+		stores, err := h.storeService.AccessibleStores(user)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to load the stores of a realtime client", "user_id", user.ID, "error", err)
+			common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+			return
+		}
+		access.Stores = stores
+	}
+
+	session := realtimeSession{
+		access: access,
+		allow: func(topic string) *models.RealtimeServerMessage {
+			permission, ok := realtimeTopicPermissions[topic]
+			if !ok {
+				reply := realtimeError(common.CodeValidationError, "Unknown topic "+topic)
+				if slices.Contains(realtimeTerminalTopics, topic) {
+					reply = realtimeError(common.CodeForbidden, "Only terminals may follow "+topic)
+				}
+				return &reply
+			}
+			allowed, err := h.permissionService.HasPermission(user.Role, permission)
+			if err != nil {
+				slog.Error("Failed to check realtime topic permission", "user_id", user.ID, "topic", topic, "error", err)
+				reply := realtimeError(common.CodeInternalError, "Failed to check permissions")
+				return &reply
+			}
+			if !allowed {
+				reply := realtimeError(common.CodeForbidden, "Not allowed to follow "+topic)
+				return &reply
+			}
+			return nil
+		},
+	}
+	if expiry, ok := tokenExpiry(c); ok {
+		timer := time.NewTimer(time.Until(expiry))
+		defer timer.Stop()
+		session.expired = timer.C
+	}
+
+	h.serve(c, session)
+}
+
+// ConnectTerminal handles GET /api/terminal/ws, the WebSocket of terminals authenticated by
+// their device token. Terminals follow their print jobs and configuration: print job events
+// tell them to claim the next job, and terminal config events carry the ETag of the new
+// configuration to fetch.
+func (h *RealtimeHandler) ConnectTerminal(c *gin.Context) {
+	device, ok := middleware.CurrentDevice(c)
+	if !ok {
+		common.SendError(c, http.StatusUnauthorized, "Device token required", common.CodeInvalidDeviceToken, nil)
+		return
+	}
+	if !h.originAllowed(c) {
+		common.SendError(c, http.StatusForbidden, "Origin not allowed", common.CodeForbidden, nil)
+		return
+	}
+
+	h.serve(c, realtimeSession{
+		access: realtime.Access{Device: device.ID},
+		allow: func(topic string) *models.RealtimeServerMessage {
+			if slices.Contains(realtimeTerminalTopics, topic) {
+				return nil
+			}
+			if _, ok := realtimeTopicPermissions[topic]; ok {
+				reply := realtimeError(common.CodeForbidden, "Not allowed to follow "+topic)
+				return &reply
+			}
+			reply := realtimeError(common.CodeValidationError, "Unknown topic "+topic)
+			return &reply
+		},
+	})
+}
+
+// serve upgrades the request and streams events to the connection until it closes
+func (h *RealtimeHandler) serve(c *gin.Context, session realtimeSession) {
+	if !websocket.IsWebSocketUpgrade(c.Request) {
+		common.SendError(c, http.StatusBadRequest, "WebSocket upgrade required", common.CodeInvalidRequest, nil)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already answered the request
+		slog.WarnContext(c.Request.Context(), "WebSocket upgrade failed", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	client := h.hub.Register(session.access)
+	defer h.hub.Unregister(client)
+
+	// Client messages are read on their own goroutine and answered through replies, so only
+	// this one writes to the connection
+	replies := make(chan models.RealtimeServerMessage)
+	closed := make(chan struct{})
+	defer close(closed)
+	read := make(chan struct{})
+	go func() {
+		defer close(read)
+		readMessages(conn, client, session, replies, closed)
+	}()
+
+	ping := time.NewTicker(realtimePingPeriod)
+	defer ping.Stop()
+
+	for {
+		var err error
+		select {
+		case event := <-client.Events():
+			err = writeRealtime(conn, event)
+		case reply := <-replies:
+			err = writeRealtime(conn, reply)
+		case <-ping.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(realtimeWriteWait))
+		case <-client.Dropped():
+			closeRealtime(conn, websocket.CloseTryAgainLater, "Too far behind")
+			return
+		case <-session.expired:
+			closeRealtime(conn, websocket.ClosePolicyViolation, "Access token expired")
+			return
+		case <-read:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// readMessages answers the client's messages until the connection fails or is closed
+func readMessages(conn *websocket.Conn, client *realtime.Client, session realtimeSession, replies chan<- models.RealtimeServerMessage, closed <-chan struct{}) {
+	conn.SetReadLimit(realtimeMaxMessage)
+	conn.SetReadDeadline(time.Now().Add(realtimePongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(realtimePongWait))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(realtimePongWait))
+
+		var message models.RealtimeClientMessage
+		reply := realtimeError(common.CodeInvalidRequest, "Messages must be JSON objects with a type")
+		if err := json.Unmarshal(data, &message); err == nil {
+			reply = answerRealtime(client, session, message)
+		}
+
+		select {
+		case replies <- reply:
+		case <-closed:
+			return
+		}
+	}
+}
+
+// answerRealtime handles a client message. Subscriptions are all or nothing: when any topic is
+// unknown or not allowed, none of them is subscribed.
+func answerRealtime(client *realtime.Client, session realtimeSession, message models.RealtimeClientMessage) models.RealtimeServerMessage {
+	switch message.Type {
+	case models.RealtimePing:
+		return models.RealtimeServerMessage{Type: models.RealtimePong}
+	case models.RealtimeSubscribe:
+		if len(message.Topics) == 0 {
+			return realtimeError(common.CodeValidationError, "Topics are required")
+		}
+		for _, topic := range message.Topics {
+			if reply := session.allow(topic); reply != nil {
+				return *reply
+			}
+		}
+		client.Subscribe(message.Topics...)
+		return models.RealtimeServerMessage{Type: models.RealtimeSubscribed, Topics: message.Topics}
+	case models.RealtimeUnsubscribe:
+		client.Unsubscribe(message.Topics...)
+		return models.RealtimeServerMessage{Type: models.RealtimeUnsubscribed, Topics: message.Topics}
+	}
+	return realtimeError(common.CodeInvalidRequest, "Unknown message type")
+}
+
+// realtimeError is an error answer to a client message
+func realtimeError(code string, message string) models.RealtimeServerMessage {
+	return models.RealtimeServerMessage{Type: models.RealtimeError, Code: code, Message: message}
+}
+
+// writeRealtime sends a message to the client as JSON
+func writeRealtime(conn *websocket.Conn, message interface{}) error {
+	conn.SetWriteDeadline(time.Now().Add(realtimeWriteWait))
+	return conn.WriteJSON(message)
+}
+
+// closeRealtime tells the client why the connection is being closed
+func closeRealtime(conn *websocket.Conn, code int, reason string) {
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(realtimeWriteWait))
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/apiversion"
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/realtime"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/Aebroyx/the-blade-api/internal/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// realtimeTimeout is how long a test waits for a realtime message that should arrive
+const realtimeTimeout = 5 * time.Second
+
+// dialRealtime connects the user to the WebSocket of the server
+func dialRealtime(t *testing.T, env *testutil.Env, server *httptest.Server, user models.Users) *websocket.Conn {
+	t.Helper()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/ws"
+	header := http.Header{"Cookie": {"access_token=" + env.Token(t, user)}}
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// subscribeRealtime subscribes the connection to topics and returns the answer
+func subscribeRealtime(t *testing.T, conn *websocket.Conn, topics ...string) models.RealtimeServerMessage {
+	t.Helper()
+
+	if err := conn.WriteJSON(models.RealtimeClientMessage{Type: models.RealtimeSubscribe, Topics: topics}); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	var reply models.RealtimeServerMessage
+	readRealtime(t, conn, &reply)
+	return reply
+}
+
+// readRealtime decodes the next message of the connection into out
+func readRealtime(t *testing.T, conn *websocket.Conn, out interface{}) {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(realtimeTimeout))
+	if err := conn.ReadJSON(out); err != nil {
+		t.Fatalf("no message arrived: %v", err)
+	}
+}
+
+// waitForRelays waits until hubs are subscribed to the events channel, so events aren't
+// published before they listen
+func waitForRelays(t *testing.T, env *testutil.Env, hubs int64) {
+	t.Helper()
+
+	client, ok := env.Cache.Client()
+	if !ok {
+		t.Fatal("cache has no Redis client")
+	}
+	deadline := time.Now().Add(realtimeTimeout)
+	for {
+		subscribers, err := client.PubSubNumSub(context.Background(), cache.EventsChannel).Result()
+		if err == nil && subscribers[cache.EventsChannel] >= hubs {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("hubs did not subscribe to the events channel: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRealtimeFansOutOrdersAcrossInstances(t *testing.T) {
+	env := testutil.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The order is placed on one instance and streamed to the client by another
+	placing, streaming := realtime.NewHub(env.Cache), realtime.NewHub(env.Cache)
+	go placing.Run(ctx)
+	go streaming.Run(ctx)
+	waitForRelays(t, env, 2)
+
+	api := salesAPI(env, placing.OrderCreatedHook)
+	realtimeHandler := NewRealtimeHandler(streaming, services.NewPermissionService(env.DB, env.Cache, env.Config.AuthClaimsMode), services.NewStoreService(env.DB), env.Config.CORSAllowedOrigins)
+	server := httptest.NewServer(env.API(apiversion.NewRegistrar("v1", func(public *gin.RouterGroup, protected *gin.RouterGroup) {
+		protected.GET("/ws", realtimeHandler.Connect)
+	})))
+	defer server.Close()
+
+	cashier := env.CreateUser(t, models.RoleAdmin)
+	conn := dialRealtime(t, env, server, cashier)
+	if reply := subscribeRealtime(t, conn, models.RealtimeTopicOrders); reply.Type != models.RealtimeSubscribed {
+		t.Fatalf("subscribing answered %+v", reply)
+	}
+
+	product, err := env.Factory.CreateProduct()
+	if err != nil {
+		t.Fatalf("failed to create product: %v", err)
+	}
+	var order models.Orders
+	rec := env.Request(t, api, http.MethodPost, "/api/v1/orders", models.CreateOrderRequest{
+		Items: []models.OrderItemRequest{{ProductID: product.ID, Quantity: 1}},
+	}, &cashier)
+	testutil.Decode(t, rec, http.StatusCreated, &order)
+
+	var event struct {
+		Type  string `json:"type"`
+		Topic string `json:"topic"`
+		Data  struct {
+			ID     uint   `json:"id"`
+			Number string `json:"number"`
+			Total  int64  `json:"total"`
+		} `json:"data"`
+	}
+	readRealtime(t, conn, &event)
+	if event.Type != models.RealtimeOrderCreated || event.Topic != models.RealtimeTopicOrders {
+		t.Fatalf("got %s on %s, want %s on %s", event.Type, event.Topic, models.RealtimeOrderCreated, models.RealtimeTopicOrders)
+	}
+	if event.Data.ID != order.ID || event.Data.Number != order.Number || event.Data.Total != order.Total {
+		t.Errorf("event is about order %d %s for %d, want %d %s for %d", event.Data.ID, event.Data.Number, event.Data.Total, order.ID, order.Number, order.Total)
+	}
+}
+
+func TestRealtimeTopicsNeedPermission(t *testing.T) {
+	env := testutil.New(t)
+	hub := realtime.NewHub(env.Cache)
+	realtimeHandler := NewRealtimeHandler(hub, services.NewPermissionService(env.DB, env.Cache, env.Config.AuthClaimsMode), services.NewStoreService(env.DB), env.Config.CORSAllowedOrigins)
+	server := httptest.NewServer(env.API(apiversion.NewRegistrar("v1", func(public *gin.RouterGroup, protected *gin.RouterGroup) {
+		protected.GET("/ws", realtimeHandler.Connect)
+	})))
+	defer server.Close()
+
+	conn := dialRealtime(t, env, server, env.CreateUser(t, models.RoleUser))
+	if reply := subscribeRealtime(t, conn, models.RealtimeTopicOrders); reply.Type != models.RealtimeError || reply.Code != common.CodeForbidden {
+		t.Errorf("subscribing without permission answered %+v", reply)
+	}
+	if reply := subscribeRealtime(t, conn, models.RealtimeTopicPrintJobs, models.RealtimeTopicTerminalConfig); reply.Code != common.CodeForbidden {
+		t.Errorf("subscribing to terminal topics answered %+v", reply)
+	}
+}
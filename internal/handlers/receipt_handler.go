@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/receipt"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ReceiptHandler struct {
+	receiptService *services.ReceiptService
+	validate       *validator.Validate
+}
+
+func NewReceiptHandler(receiptService *services.ReceiptService) *ReceiptHandler {
+	return &ReceiptHandler{
+		receiptService: receiptService,
+		validate:       validator.New(),
+	}
+}
+
+// sendReceiptError maps receipt service errors to API responses
+func sendReceiptError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "order not found":
+		common.SendError(c, http.StatusNotFound, "Order not found", common.CodeNotFound, nil)
+	case err.Error() == "order has no customer email":
+		common.SendError(c, http.StatusBadRequest, "Order has no customer with an email; an email is required", common.CodeValidationError, nil)
+	case err.Error() == "order has no receipt":
+		common.SendError(c, http.StatusConflict, "Draft orders have no receipt", common.CodeConflict, nil)
+	case strings.HasPrefix(err.Error(), "invalid receipt template"):
+		common.SendError(c, http.StatusInternalServerError, "The receipt template setting is invalid", common.CodeInternalError, err.Error())
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// GetReceipt handles GET /api/orders/:id/receipt
+func (h *ReceiptHandler) GetReceipt(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var query models.ReceiptQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(query); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	format := receipt.FormatPDF
+	if query.Format != "" {
+		format = receipt.Format(query.Format)
+	}
+
+	// Terminals print with the template of their location
+	var tenantID uint
+	if tenant, ok := middleware.CurrentTenant(c); ok {
+		tenantID = tenant.ID
+	}
+	if device, ok := middleware.CurrentDevice(c); ok && query.Location == "" {
+		query.Location = device.Location
+	}
+
+	content, number, err := h.receiptService.RenderReceipt(c.Request.Context(), id, format, tenantID, query.Location)
+	if err != nil {
+		sendReceiptError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="receipt-%s.%s"`, number, format.Extension()))
+	c.Data(http.StatusOK, format.ContentType(), content)
+}
+
+// EmailReceipt handles POST /api/orders/:id/receipt/email
+func (h *ReceiptHandler) EmailReceipt(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.EmailReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	var tenantID uint
+	if tenant, ok := middleware.CurrentTenant(c); ok {
+		tenantID = tenant.ID
+	}
+	if device, ok := middleware.CurrentDevice(c); ok && req.Location == "" {
+		req.Location = device.Location
+	}
+
+	email, err := h.receiptService.EmailReceipt(id, &req, tenantID, activityActor(c))
+	if err != nil {
+		sendReceiptError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusAccepted, "Receipt email queued successfully", email)
+}
+
+// GetReceiptEmails handles GET /api/orders/:id/receipt/emails
+func (h *ReceiptHandler) GetReceiptEmails(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	emails, err := h.receiptService.GetReceiptEmails(id)
+	if err != nil {
+		sendReceiptError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Receipt emails fetched successfully", emails)
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ReportHandler struct {
+	reportService *services.ReportService
+	validate      *validator.Validate
+}
+
+func NewReportHandler(reportService *services.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+		validate:      validator.New(),
+	}
+}
+
+// sendReportError maps report service errors to API responses
+func sendReportError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "report not found":
+		common.SendError(c, http.StatusNotFound, "Report not found", common.CodeNotFound, nil)
+	case "report name already exists":
+		common.SendError(c, http.StatusConflict, "Report name already exists", common.CodeConflict, nil)
+	case "report entity is no longer available", "report references an unknown dimension", "report references an unknown measure":
+		common.SendError(c, http.StatusUnprocessableEntity, err.Error(), common.CodeValidationError, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// bindReportDefinition binds and validates a report definition request
+func (h *ReportHandler) bindReportDefinition(c *gin.Context) (*models.ReportDefinitionRequest, bool) {
+	var req models.ReportDefinitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return nil, false
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return nil, false
+	}
+
+	// Validate against the report whitelist
+	if problems := services.ValidateReportDefinition(&req); len(problems) > 0 {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, problems)
+		return nil, false
+	}
+
+	return &req, true
+}
+
+// GetReportEntities handles GET /api/reports/entities
+func (h *ReportHandler) GetReportEntities(c *gin.Context) {
+	common.SendSuccess(c, http.StatusOK, "Report entities fetched successfully", h.reportService.GetReportEntities())
+}
+
+// GetAllReportDefinitions handles GET /api/reports
+func (h *ReportHandler) GetAllReportDefinitions(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.reportService.GetAllReportDefinitions(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch reports", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Reports fetched successfully", response)
+}
+
+// GetReportDefinitionById handles GET /api/reports/:id
+func (h *ReportHandler) GetReportDefinitionById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	definition, err := h.reportService.GetReportDefinitionById(id)
+	if err != nil {
+		sendReportError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Report fetched successfully", definition)
+}
+
+// CreateReportDefinition handles POST /api/reports
+func (h *ReportHandler) CreateReportDefinition(c *gin.Context) {
+	req, ok := h.bindReportDefinition(c)
+	if !ok {
+		return
+	}
+
+	user, _ := middleware.CurrentUser(c)
+	definition, err := h.reportService.CreateReportDefinition(req, user.ID)
+	if err != nil {
+		sendReportError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Report created successfully", definition)
+}
+
+// UpdateReportDefinition handles PUT /api/reports/:id
+func (h *ReportHandler) UpdateReportDefinition(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := h.bindReportDefinition(c)
+	if !ok {
+		return
+	}
+
+	definition, err := h.reportService.UpdateReportDefinition(id, req)
+	if err != nil {
+		sendReportError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Report updated successfully", definition)
+}
+
+// DeleteReportDefinition handles DELETE /api/reports/:id
+func (h *ReportHandler) DeleteReportDefinition(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	definition, err := h.reportService.DeleteReportDefinition(id)
+	if err != nil {
+		sendReportError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Report deleted successfully", definition)
+}
+
+// RunReport handles GET /api/reports/:id/run
+func (h *ReportHandler) RunReport(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.reportService.RunReport(id, params)
+	if err != nil {
+		sendReportError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Report executed successfully", response)
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ReportScheduleHandler struct {
+	reportScheduleService *services.ReportScheduleService
+	validate              *validator.Validate
+}
+
+func NewReportScheduleHandler(reportScheduleService *services.ReportScheduleService) *ReportScheduleHandler {
+	return &ReportScheduleHandler{
+		reportScheduleService: reportScheduleService,
+		validate:              validator.New(),
+	}
+}
+
+// sendReportScheduleError maps report schedule service errors to API responses
+func sendReportScheduleError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "report schedule not found":
+		common.SendError(c, http.StatusNotFound, "Report schedule not found", common.CodeNotFound, nil)
+	case "report not found":
+		common.SendError(c, http.StatusUnprocessableEntity, "Report not found", common.CodeValidationError, nil)
+	case "invalid cron expression", "unknown time zone", "invalid webhook url":
+		common.SendError(c, http.StatusUnprocessableEntity, err.Error(), common.CodeValidationError, nil)
+	case "webhook address not allowed":
+		common.SendError(c, http.StatusUnprocessableEntity, "The webhook must be on a public address", common.CodeValidationError, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// bindReportSchedule binds and validates a report schedule request
+func (h *ReportScheduleHandler) bindReportSchedule(c *gin.Context) (*models.ReportScheduleRequest, bool) {
+	var req models.ReportScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return nil, false
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return nil, false
+	}
+
+	return &req, true
+}
+
+// GetAllReportSchedules handles GET /api/report-schedules
+func (h *ReportScheduleHandler) GetAllReportSchedules(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.reportScheduleService.GetAllReportSchedules(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch report schedules", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Report schedules fetched successfully", response)
+}
+
+// GetReportScheduleById handles GET /api/report-schedules/:id
+func (h *ReportScheduleHandler) GetReportScheduleById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	schedule, err := h.reportScheduleService.GetReportScheduleById(id)
+	if err != nil {
+		sendReportScheduleError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Report schedule fetched successfully", schedule)
+}
+
+// CreateReportSchedule handles POST /api/report-schedules
+func (h *ReportScheduleHandler) CreateReportSchedule(c *gin.Context) {
+	req, ok := h.bindReportSchedule(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.reportScheduleService.CreateReportSchedule(c.Request.Context(), req, activityActor(c))
+	if err != nil {
+		sendReportScheduleError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Report schedule created successfully", schedule)
+}
+
+// UpdateReportSchedule handles PUT /api/report-schedules/:id
+func (h *ReportScheduleHandler) UpdateReportSchedule(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := h.bindReportSchedule(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.reportScheduleService.UpdateReportSchedule(c.Request.Context(), id, req)
+	if err != nil {
+		sendReportScheduleError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Report schedule updated successfully", schedule)
+}
+
+// DeleteReportSchedule handles DELETE /api/report-schedules/:id
+func (h *ReportScheduleHandler) DeleteReportSchedule(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	schedule, err := h.reportScheduleService.DeleteReportSchedule(id)
+	if err != nil {
+		sendReportScheduleError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Report schedule deleted successfully", schedule)
+}
+
+// GetReportScheduleRuns handles GET /api/report-schedules/:id/runs
+func (h *ReportScheduleHandler) GetReportScheduleRuns(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.reportScheduleService.GetReportScheduleRuns(id, params)
+	if err != nil {
+		sendReportScheduleError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Report schedule runs fetched successfully", response)
+}
+
+// RunReportSchedule handles POST /api/report-schedules/:id/run. The run is returned whether or
+// not it was delivered; failed runs carry their error.
+func (h *ReportScheduleHandler) RunReportSchedule(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	run, err := h.reportScheduleService.RunReportSchedule(c.Request.Context(), id, activityActor(c))
+	if err != nil {
+		sendReportScheduleError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Report schedule run", run)
+}
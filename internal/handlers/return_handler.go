@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ReturnHandler struct {
+	returnService *services.ReturnService
+	validate      *validator.Validate
+}
+
+func NewReturnHandler(returnService *services.ReturnService) *ReturnHandler {
+	return &ReturnHandler{
+		returnService: returnService,
+		validate:      validator.New(),
+	}
+}
+
+// sendReturnError maps return service errors to API responses; payment failures are mapped
+// like payment errors
+func sendReturnError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "order item not found":
+		common.SendError(c, http.StatusBadRequest, "Order item not found on the order", common.CodeValidationError, nil)
+	case "duplicate order item":
+		common.SendError(c, http.StatusBadRequest, "Each order item may be returned once per refund", common.CodeValidationError, nil)
+	case "return exceeds quantity sold":
+		common.SendError(c, http.StatusBadRequest, "Return exceeds the quantity sold that hasn't been returned yet", common.CodeValidationError, nil)
+	case "order not refundable":
+		common.SendError(c, http.StatusConflict, "Only paid orders can be refunded", common.CodeConflict, nil)
+	case "nothing left to refund":
+		common.SendError(c, http.StatusConflict, "Every line of the order has already been refunded", common.CodeConflict, nil)
+	case "refund exceeds payments":
+		common.SendError(c, http.StatusConflict, "Refund exceeds what is left of the order's captured payments", common.CodeConflict, nil)
+	case "gift card spent":
+		common.SendError(c, http.StatusConflict, "A gift card sold on the returned lines has already been spent", common.CodeConflict, nil)
+	default:
+		sendPaymentError(c, err)
+	}
+}
+
+// RefundOrder handles POST /api/orders/:id/refund
+func (h *ReturnHandler) RefundOrder(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.RefundOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	ret, err := h.returnService.RefundOrder(c.Request.Context(), id, &req, activityActor(c))
+	if err != nil {
+		sendReturnError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Order refunded successfully", ret)
+}
+
+// GetOrderReturns handles GET /api/orders/:id/returns
+func (h *ReturnHandler) GetOrderReturns(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	returns, err := h.returnService.GetOrderReturns(id)
+	if err != nil {
+		sendReturnError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Returns fetched successfully", returns)
+}
+
+// GetAllReturns handles GET /api/returns
+func (h *ReturnHandler) GetAllReturns(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.returnService.GetAllReturns(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch returns", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Returns fetched successfully", response)
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/testutil"
+)
+
+// createPaidOrder places an order for a number of new products, as the factories build it,
+// and pays it in full in cash
+func createPaidOrder(t *testing.T, env *testutil.Env, api http.Handler, user *models.Users, products int) *models.Orders {
+	t.Helper()
+
+	var sold []models.Products
+	for range products {
+		product, err := env.Factory.CreateProduct()
+		if err != nil {
+			t.Fatalf("failed to create product: %v", err)
+		}
+		sold = append(sold, *product)
+	}
+	order, err := env.Factory.CreateOrder(sold)
+	if err != nil {
+		t.Fatalf("failed to create order: %v", err)
+	}
+
+	payInCash(t, env, api, order, order.Total, user)
+	order.Status = models.OrderStatusPaid
+	return order
+}
+
+func TestRefundOrder(t *testing.T) {
+	env := testutil.New(t)
+	api := salesAPI(env)
+	manager := env.CreateUser(t, models.RoleAdmin)
+	order := createPaidOrder(t, env, api, &manager, 2)
+	first, second := order.Items[0], order.Items[1]
+
+	// A line is refunded through the payment and put back into stock
+	var ret models.OrderReturns
+	rec := env.Request(t, api, http.MethodPost, orderPath(order, "/refund"), models.RefundOrderRequest{
+		Items:  []models.RefundItemRequest{{OrderItemID: first.ID, Quantity: first.Quantity}},
+		Reason: "Damaged",
+	}, &manager)
+	testutil.Decode(t, rec, http.StatusCreated, &ret)
+	if ret.Amount != first.Total || ret.ItemCount != first.Quantity {
+		t.Errorf("return of %d items for %d, want %d items for %d", ret.ItemCount, ret.Amount, first.Quantity, first.Total)
+	}
+	if len(ret.Refunds) != 1 || ret.Refunds[0].Amount != first.Total {
+		t.Errorf("return refunds are %+v, want one of %d", ret.Refunds, first.Total)
+	}
+	if stock := stockOf(t, env, first.ProductID); stock != first.Quantity {
+		t.Errorf("stock after restocking is %d, want %d", stock, first.Quantity)
+	}
+
+	var reloaded models.Orders
+	testutil.Decode(t, env.Request(t, api, http.MethodGet, orderPath(order, ""), nil, &manager), http.StatusOK, &reloaded)
+	if reloaded.Status != models.OrderStatusPaid {
+		t.Errorf("partly returned order is %s", reloaded.Status)
+	}
+
+	// Returning the rest refunds the order
+	restock := false
+	rec = env.Request(t, api, http.MethodPost, orderPath(order, "/refund"), models.RefundOrderRequest{
+		Restock: &restock,
+		Reason:  "Customer return",
+	}, &manager)
+	testutil.Decode(t, rec, http.StatusCreated, &ret)
+	if ret.Amount != second.Total {
+		t.Errorf("second return is for %d, want %d", ret.Amount, second.Total)
+	}
+	if stock := stockOf(t, env, second.ProductID); stock != 0 {
+		t.Errorf("unrestocked return moved stock to %d", stock)
+	}
+
+	testutil.Decode(t, env.Request(t, api, http.MethodGet, orderPath(order, ""), nil, &manager), http.StatusOK, &reloaded)
+	if reloaded.Status != models.OrderStatusRefunded {
+		t.Errorf("fully returned order is %s", reloaded.Status)
+	}
+
+	var payment models.Payments
+	if err := env.DB.Where("order_id = ?", order.ID).First(&payment).Error; err != nil {
+		t.Fatalf("failed to load payment: %v", err)
+	}
+	if payment.Status != models.PaymentStatusRefunded || payment.RefundedAmount != order.Total {
+		t.Errorf("payment is %s with %d refunded, want %s with %d", payment.Status, payment.RefundedAmount, models.PaymentStatusRefunded, order.Total)
+	}
+
+	var returns []models.OrderReturns
+	testutil.Decode(t, env.Request(t, api, http.MethodGet, orderPath(order, "/returns"), nil, &manager), http.StatusOK, &returns)
+	if len(returns) != 2 {
+		t.Errorf("order has %d returns, want 2", len(returns))
+	}
+
+	rec = env.Request(t, api, http.MethodPost, orderPath(order, "/refund"), models.RefundOrderRequest{Reason: "Again"}, &manager)
+	testutil.Decode(t, rec, http.StatusConflict, nil)
+}
+
+func TestRefundOrderRejectsWhatPaymentsDontCover(t *testing.T) {
+	env := testutil.New(t)
+	api := salesAPI(env)
+	manager := env.CreateUser(t, models.RoleAdmin)
+
+	product, err := env.Factory.CreateProduct()
+	if err != nil {
+		t.Fatalf("failed to create product: %v", err)
+	}
+	order, err := env.Factory.CreateOrder([]models.Products{*product})
+	if err != nil {
+		t.Fatalf("failed to create order: %v", err)
+	}
+
+	// Unpaid orders have nothing to refund
+	refund := models.RefundOrderRequest{Reason: "Damaged"}
+	testutil.Decode(t, env.Request(t, api, http.MethodPost, orderPath(order, "/refund"), refund, &manager), http.StatusConflict, nil)
+
+	// Part of the payment is refunded outside any return, so it no longer covers the order
+	payment := payInCash(t, env, api, order, order.Total, &manager)
+	path := orderPath(order, "/payments/"+strconv.FormatUint(uint64(payment.ID), 10)+"/refund")
+	rec := env.Request(t, api, http.MethodPost, path, models.RefundPaymentRequest{Amount: 1, Reason: "Goodwill"}, &manager)
+	testutil.Decode(t, rec, http.StatusOK, nil)
+
+	rec = env.Request(t, api, http.MethodPost, orderPath(order, "/refund"), refund, &manager)
+	testutil.Decode(t, rec, http.StatusConflict, nil)
+	if !strings.Contains(rec.Body.String(), "captured payments") {
+		t.Errorf("refund rejected for another reason: %s", rec.Body.String())
+	}
+
+	var returns int64
+	if err := env.DB.Model(&models.OrderReturns{}).Where("order_id = ?", order.ID).Count(&returns).Error; err != nil {
+		t.Fatalf("failed to count returns: %v", err)
+	}
+	if returns != 0 {
+		t.Errorf("rejected refund recorded %d returns", returns)
+	}
+}
+
+func TestRefundOrderNeedsPermission(t *testing.T) {
+	env := testutil.New(t)
+	api := salesAPI(env)
+	manager := env.CreateUser(t, models.RoleAdmin)
+	user := env.CreateUser(t, models.RoleUser)
+	order := createPaidOrder(t, env, api, &manager, 1)
+
+	rec := env.Request(t, api, http.MethodPost, orderPath(order, "/refund"), models.RefundOrderRequest{Reason: "Damaged"}, &user)
+	testutil.Decode(t, rec, http.StatusForbidden, nil)
+}
+
+func TestRefundOrderVoidsReturnedGiftCards(t *testing.T) {
+	env := testutil.New(t)
+	api := salesAPI(env)
+	manager := env.CreateUser(t, models.RoleAdmin)
+	product, err := env.Factory.CreateProduct(func(p *models.Products) { p.GiftCard = true })
+	if err != nil {
+		t.Fatalf("failed to create product: %v", err)
+	}
+
+	// Gift cards sell at their price only
+	price := product.Price - 1
+	rec := env.Request(t, api, http.MethodPost, "/api/v1/orders", models.CreateOrderRequest{
+		Items: []models.OrderItemRequest{{ProductID: product.ID, Quantity: 1, UnitPrice: &price}},
+	}, &manager)
+	testutil.Decode(t, rec, http.StatusBadRequest, nil)
+
+	var order models.Orders
+	rec = env.Request(t, api, http.MethodPost, "/api/v1/orders", models.CreateOrderRequest{
+		Items: []models.OrderItemRequest{{ProductID: product.ID, Quantity: 3}},
+	}, &manager)
+	testutil.Decode(t, rec, http.StatusCreated, &order)
+	payInCash(t, env, api, &order, order.Total, &manager)
+
+	var cards []models.GiftCards
+	if err := env.DB.Where("order_id = ?", order.ID).Order("id").Find(&cards).Error; err != nil {
+		t.Fatalf("failed to load gift cards: %v", err)
+	}
+	var issued int64
+	for _, card := range cards {
+		issued += card.InitialBalance
+	}
+	if len(cards) != 3 || issued != order.Items[0].Total {
+		t.Fatalf("issued %d cards worth %d, want 3 worth %d", len(cards), issued, order.Items[0].Total)
+	}
+
+	// Two cards are left unspent, so returning all three is blocked
+	if err := env.DB.Model(&cards[0]).Update("balance", cards[0].Balance-1).Error; err != nil {
+		t.Fatalf("failed to spend gift card: %v", err)
+	}
+	item := order.Items[0]
+	rec = env.Request(t, api, http.MethodPost, orderPath(&order, "/refund"), models.RefundOrderRequest{
+		Items:  []models.RefundItemRequest{{OrderItemID: item.ID, Quantity: 3}},
+		Reason: "Unwanted",
+	}, &manager)
+	testutil.Decode(t, rec, http.StatusConflict, nil)
+
+	rec = env.Request(t, api, http.MethodPost, orderPath(&order, "/refund"), models.RefundOrderRequest{
+		Items:  []models.RefundItemRequest{{OrderItemID: item.ID, Quantity: 2}},
+		Reason: "Unwanted",
+	}, &manager)
+	testutil.Decode(t, rec, http.StatusCreated, nil)
+
+	var active int64
+	if err := env.DB.Model(&models.GiftCards{}).Where("order_id = ? AND status = ?", order.ID, models.GiftCardStatusActive).Count(&active).Error; err != nil {
+		t.Fatalf("failed to count gift cards: %v", err)
+	}
+	if active != 1 {
+		t.Errorf("%d gift cards are active after returning two of three, want 1", active)
+	}
+}
+
+func TestRefundOrderTakesBackReturnedLinesPoints(t *testing.T) {
+	env := testutil.New(t)
+	api := salesAPI(env)
+	manager := env.CreateUser(t, models.RoleAdmin)
+	product, err := env.Factory.CreateProduct(func(p *models.Products) { p.Price = 1000 })
+	if err != nil {
+		t.Fatalf("failed to create product: %v", err)
+	}
+	customer := models.Customers{Name: "Loyal Customer"}
+	if err := env.DB.Create(&customer).Error; err != nil {
+		t.Fatalf("failed to create customer: %v", err)
+	}
+	if err := env.DB.Create(&models.LoyaltyRules{Name: "1 point per 1.00", Spend: 100, Points: 1, Active: true}).Error; err != nil {
+		t.Fatalf("failed to create loyalty rule: %v", err)
+	}
+
+	var order models.Orders
+	rec := env.Request(t, api, http.MethodPost, "/api/v1/orders", models.CreateOrderRequest{
+		CustomerID: &customer.ID,
+		Items:      []models.OrderItemRequest{{ProductID: product.ID, Quantity: 4}},
+	}, &manager)
+	testutil.Decode(t, rec, http.StatusCreated, &order)
+	payInCash(t, env, api, &order, order.Total, &manager)
+
+	points := func() int64 {
+		t.Helper()
+		if err := env.DB.First(&customer, customer.ID).Error; err != nil {
+			t.Fatalf("failed to load customer: %v", err)
+		}
+		return customer.LoyaltyPoints
+	}
+	if got := points(); got != 40 {
+		t.Fatalf("customer earned %d points, want 40", got)
+	}
+
+	item := order.Items[0]
+	rec = env.Request(t, api, http.MethodPost, orderPath(&order, "/refund"), models.RefundOrderRequest{
+		Items:  []models.RefundItemRequest{{OrderItemID: item.ID, Quantity: 1}},
+		Reason: "Damaged",
+	}, &manager)
+	testutil.Decode(t, rec, http.StatusCreated, nil)
+	if got := points(); got != 30 {
+		t.Errorf("customer has %d points after returning a quarter of the order, want 30", got)
+	}
+
+	rec = env.Request(t, api, http.MethodPost, orderPath(&order, "/refund"), models.RefundOrderRequest{Reason: "Unwanted"}, &manager)
+	testutil.Decode(t, rec, http.StatusCreated, nil)
+	if got := points(); got != 0 {
+		t.Errorf("customer has %d points after returning the whole order, want 0", got)
+	}
+}
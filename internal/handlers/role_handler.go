@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type RoleHandler struct {
+	userService       *services.UserService
+	permissionService *services.PermissionService
+	validate          *validator.Validate
+}
+
+func NewRoleHandler(userService *services.UserService, permissionService *services.PermissionService) *RoleHandler {
+	return &RoleHandler{
+		userService:       userService,
+		permissionService: permissionService,
+		validate:          validator.New(),
+	}
+}
+
+// bindRoleUsers validates the role route parameter and binds the user IDs
+func (h *RoleHandler) bindRoleUsers(c *gin.Context) (string, *models.RoleUsersRequest, bool) {
+	role := c.Param("role")
+	if role != models.RoleAdmin && role != models.RoleUser {
+		common.SendError(c, http.StatusNotFound, "Role not found", common.CodeNotFound, nil)
+		return "", nil, false
+	}
+
+	var req models.RoleUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return "", nil, false
+	}
+
+	if err := limits.Check(limits.MaxBulkIDs, len(req.UserIDs)); err != nil {
+		sendBindError(c, "Invalid request body", err)
+		return "", nil, false
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return "", nil, false
+	}
+
+	return role, &req, true
+}
+
+// AssignRole handles POST /api/roles/:role/users/assign
+func (h *RoleHandler) AssignRole(c *gin.Context) {
+	role, req, ok := h.bindRoleUsers(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.userService.AssignRole(role, req.UserIDs, activityActor(c))
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to assign role", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Role assigned successfully", response)
+}
+
+// RemoveRole handles POST /api/roles/:role/users/remove
+func (h *RoleHandler) RemoveRole(c *gin.Context) {
+	role, req, ok := h.bindRoleUsers(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.userService.RemoveRole(role, req.UserIDs, activityActor(c))
+	if err != nil {
+		if err.Error() == "cannot remove the default role" {
+			common.SendError(c, http.StatusBadRequest, "Cannot remove the default role", common.CodeValidationError, nil)
+			return
+		}
+		common.SendError(c, http.StatusInternalServerError, "Failed to remove role", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Role removed successfully", response)
+}
+
+// GetPermissions handles GET /api/permissions
+func (h *RoleHandler) GetPermissions(c *gin.Context) {
+	permissions, err := h.permissionService.GetPermissions()
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch permissions", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Permissions fetched successfully", permissions)
+}
+
+// bindPermissionRoles binds and validates the roles of a grant or revoke
+func (h *RoleHandler) bindPermissionRoles(c *gin.Context) (*models.PermissionRolesRequest, bool) {
+	var req models.PermissionRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return nil, false
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return nil, false
+	}
+
+	return &req, true
+}
+
+// sendPermissionError maps permission service errors to API responses
+func sendPermissionError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "permission not found":
+		common.SendError(c, http.StatusNotFound, "Permission not found", common.CodeNotFound, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// GrantPermission handles POST /api/permissions/:permission/grant
+func (h *RoleHandler) GrantPermission(c *gin.Context) {
+	req, ok := h.bindPermissionRoles(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.permissionService.GrantPermission(c.Param("permission"), req.Roles, activityActor(c))
+	if err != nil {
+		sendPermissionError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Permission granted successfully", response)
+}
+
+// RevokePermission handles POST /api/permissions/:permission/revoke
+func (h *RoleHandler) RevokePermission(c *gin.Context) {
+	req, ok := h.bindPermissionRoles(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.permissionService.RevokePermission(c.Param("permission"), req.Roles)
+	if err != nil {
+		sendPermissionError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Permission revoked successfully", response)
+}
@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// scimContentType is the media type of SCIM requests and responses
+const scimContentType = "application/scim+json"
+
+type SCIMHandler struct {
+	scimService *services.SCIMService
+}
+
+func NewSCIMHandler(scimService *services.SCIMService) *SCIMHandler {
+	return &SCIMHandler{
+		scimService: scimService,
+	}
+}
+
+// sendSCIM writes a SCIM response
+func sendSCIM(c *gin.Context, status int, body interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		sendSCIMErrorStatus(c, http.StatusInternalServerError, "", "Internal server error")
+		return
+	}
+	c.Data(status, scimContentType, data)
+}
+
+// sendSCIMErrorStatus writes a SCIM error response
+func sendSCIMErrorStatus(c *gin.Context, status int, scimType string, detail string) {
+	data, _ := json.Marshal(models.SCIMError{
+		Schemas:  []string{models.SCIMSchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+	c.Data(status, scimContentType, data)
+}
+
+// sendSCIMError maps SCIM and user service errors to SCIM error responses
+func sendSCIMError(c *gin.Context, err error) {
+	var requestErr *services.SCIMRequestError
+	if errors.As(err, &requestErr) {
+		sendSCIMErrorStatus(c, http.StatusBadRequest, requestErr.ScimType, requestErr.Detail)
+		return
+	}
+
+	switch err.Error() {
+	case "user not found":
+		sendSCIMErrorStatus(c, http.StatusNotFound, "", "User not found")
+	case "team not found":
+		sendSCIMErrorStatus(c, http.StatusNotFound, "", "Group not found")
+	case "username already exists":
+		sendSCIMErrorStatus(c, http.StatusConflict, "uniqueness", "userName is already taken")
+	case "email already exists":
+		sendSCIMErrorStatus(c, http.StatusConflict, "uniqueness", "email is already taken")
+	case "external id already exists":
+		sendSCIMErrorStatus(c, http.StatusConflict, "uniqueness", "externalId is already taken")
+	case "team name already exists":
+		sendSCIMErrorStatus(c, http.StatusConflict, "uniqueness", "displayName is already taken")
+	default:
+		slog.ErrorContext(c.Request.Context(), "SCIM request failed", "method", c.Request.Method, "path", c.Request.URL.Path, "error", err)
+		sendSCIMErrorStatus(c, http.StatusInternalServerError, "", "Internal server error")
+	}
+}
+
+// bindSCIM binds a SCIM request body, responding on failure
+func bindSCIM(c *gin.Context, target interface{}) bool {
+	if err := c.ShouldBindJSON(target); err != nil {
+		sendSCIMErrorStatus(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return false
+	}
+	return true
+}
+
+// bindSCIMList binds the query of a SCIM list request, responding on failure
+func bindSCIMList(c *gin.Context) (*models.SCIMListQuery, bool) {
+	var query models.SCIMListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		sendSCIMErrorStatus(c, http.StatusBadRequest, "invalidValue", err.Error())
+		return nil, false
+	}
+	return &query, true
+}
+
+// GetServiceProviderConfig handles GET /scim/v2/ServiceProviderConfig
+func (h *SCIMHandler) GetServiceProviderConfig(c *gin.Context) {
+	sendSCIM(c, http.StatusOK, gin.H{
+		"schemas":        []string{models.SCIMSchemaServiceProviderConfig},
+		"patch":          gin.H{"supported": true},
+		"bulk":           gin.H{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         gin.H{"supported": true, "maxResults": limits.Get(limits.MaxPageSize)},
+		"changePassword": gin.H{"supported": true},
+		"sort":           gin.H{"supported": false},
+		"etag":           gin.H{"supported": false},
+		"authenticationSchemes": []gin.H{{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "Authentication with the SCIM token configured for this deployment",
+			"primary":     true,
+		}},
+	})
+}
+
+// GetUsers handles GET /scim/v2/Users
+func (h *SCIMHandler) GetUsers(c *gin.Context) {
+	query, ok := bindSCIMList(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.scimService.GetUsers(query)
+	if err != nil {
+		sendSCIMError(c, err)
+		return
+	}
+
+	sendSCIM(c, http.StatusOK, response)
+}
+
+// GetUser handles GET /scim/v2/Users/:id
+func (h *SCIMHandler) GetUser(c *gin.Context) {
+	user, err := h.scimService.GetUser(c.Param("id"))
+	if err != nil {
+		sendSCIMError(c, err)
+		return
+	}
+
+	sendSCIM(c, http.StatusOK, user)
+}
+
+// CreateUser handles POST /scim/v2/Users
+func (h *SCIMHandler) CreateUser(c *gin.Context) {
+	var req models.SCIMUser
+	if !bindSCIM(c, &req) {
+		return
+	}
+
+	user, err := h.scimService.CreateUser(&req, activityActor(c))
+	if err != nil {
+		sendSCIMError(c, err)
+		return
+	}
+
+	c.Header("Location", user.Meta.Location)
+	sendSCIM(c, http.StatusCreated, user)
+}
+
+// ReplaceUser handles PUT /scim/v2/Users/:id
+func (h *SCIMHandler) ReplaceUser(c *gin.Context) {
+	var req models.SCIMUser
+	if !bindSCIM(c, &req) {
+		return
+	}
+
+	user, err := h.scimService.ReplaceUser(c.Param("id"), &req, activityActor(c))
+	if err != nil {
+		sendSCIMError(c, err)
+		return
+	}
+
+	sendSCIM(c, http.StatusOK, user)
+}
+
+// PatchUser handles PATCH /scim/v2/Users/:id
+func (h *SCIMHandler) PatchUser(c *gin.Context) {
+	var req models.SCIMPatchRequest
+	if !bindSCIM(c, &req) {
+		return
+	}
+
+	user, err := h.scimService.PatchUser(c.Param("id"), &req, activityActor(c))
+	if err != nil {
+		sendSCIMError(c, err)
+		return
+	}
+
+	sendSCIM(c, http.StatusOK, user)
+}
+
+// DeleteUser handles DELETE /scim/v2/Users/:id
+func (h *SCIMHandler) DeleteUser(c *gin.Context) {
+	if err := h.scimService.DeleteUser(c.Param("id"), activityActor(c)); err != nil {
+		sendSCIMError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetGroups handles GET /scim/v2/Groups
+func (h *SCIMHandler) GetGroups(c *gin.Context) {
+	query, ok := bindSCIMList(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.scimService.GetGroups(query)
+	if err != nil {
+		sendSCIMError(c, err)
+		return
+	}
+
+	sendSCIM(c, http.StatusOK, response)
+}
+
+// GetGroup handles GET /scim/v2/Groups/:id
+func (h *SCIMHandler) GetGroup(c *gin.Context) {
+	group, err := h.scimService.GetGroup(c.Param("id"))
+	if err != nil {
+		sendSCIMError(c, err)
+		return
+	}
+
+	sendSCIM(c, http.StatusOK, group)
+}
+
+// CreateGroup handles POST /scim/v2/Groups
+func (h *SCIMHandler) CreateGroup(c *gin.Context) {
+	var req models.SCIMGroup
+	if !bindSCIM(c, &req) {
+		return
+	}
+
+	group, err := h.scimService.CreateGroup(&req, activityActor(c))
+	if err != nil {
+		sendSCIMError(c, err)
+		return
+	}
+
+	c.Header("Location", group.Meta.Location)
+	sendSCIM(c, http.StatusCreated, group)
+}
+
+// ReplaceGroup handles PUT /scim/v2/Groups/:id
+func (h *SCIMHandler) ReplaceGroup(c *gin.Context) {
+	var req models.SCIMGroup
+	if !bindSCIM(c, &req) {
+		return
+	}
+
+	group, err := h.scimService.ReplaceGroup(c.Param("id"), &req, activityActor(c))
+	if err != nil {
+		sendSCIMError(c, err)
+		return
+	}
+
+	sendSCIM(c, http.StatusOK, group)
+}
+
+// PatchGroup handles PATCH /scim/v2/Groups/:id
+func (h *SCIMHandler) PatchGroup(c *gin.Context) {
+	var req models.SCIMPatchRequest
+	if !bindSCIM(c, &req) {
+		return
+	}
+
+	group, err := h.scimService.PatchGroup(c.Param("id"), &req, activityActor(c))
+	if err != nil {
+		sendSCIMError(c, err)
+		return
+	}
+
+	sendSCIM(c, http.StatusOK, group)
+}
+
+// DeleteGroup handles DELETE /scim/v2/Groups/:id
+func (h *SCIMHandler) DeleteGroup(c *gin.Context) {
+	if err := h.scimService.DeleteGroup(c.Param("id"), activityActor(c)); err != nil {
+		sendSCIMError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type SettingHandler struct {
+	settingService *services.SettingService
+	validate       *validator.Validate
+}
+
+func NewSettingHandler(settingService *services.SettingService) *SettingHandler {
+	return &SettingHandler{
+		settingService: settingService,
+		validate:       validator.New(),
+	}
+}
+
+// sendSettingError maps setting service errors to API responses
+func sendSettingError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "setting not found":
+		common.SendError(c, http.StatusNotFound, "Setting not found", common.CodeNotFound, nil)
+	case "tenant not found":
+		common.SendError(c, http.StatusNotFound, "Tenant not found", common.CodeNotFound, nil)
+	case "setting already exists":
+		common.SendError(c, http.StatusConflict, "Setting already exists", common.CodeConflict, nil)
+	case "setting version mismatch":
+		common.SendError(c, http.StatusConflict, "Setting was changed by someone else", common.CodeConflict, nil)
+	case "invalid setting key", "value does not match the setting type", "global settings have no scope id":
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// GetAllSettings handles GET /api/settings
+func (h *SettingHandler) GetAllSettings(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.settingService.GetAllSettings(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch settings", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Settings fetched successfully", response)
+}
+
+// GetSettingById handles GET /api/settings/:id
+func (h *SettingHandler) GetSettingById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	setting, err := h.settingService.GetSettingById(id)
+	if err != nil {
+		sendSettingError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Setting fetched successfully", setting)
+}
+
+// GetEffectiveSetting handles GET /api/settings/effective/:key
+func (h *SettingHandler) GetEffectiveSetting(c *gin.Context) {
+	var query models.EffectiveSettingQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(query); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	if query.TenantID == 0 {
+		if tenant, ok := middleware.CurrentTenant(c); ok {
+			query.TenantID = tenant.ID
+		}
+	}
+
+	setting, err := h.settingService.Effective(c.Request.Context(), c.Param("key"), query.TenantID, query.Location)
+	if err != nil {
+		sendSettingError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Setting fetched successfully", setting)
+}
+
+// CreateSetting handles POST /api/settings
+func (h *SettingHandler) CreateSetting(c *gin.Context) {
+	var req models.CreateSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	setting, err := h.settingService.CreateSetting(&req, activityActor(c))
+	if err != nil {
+		sendSettingError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Setting created successfully", setting)
+}
+
+// UpdateSetting handles PUT /api/settings/:id
+func (h *SettingHandler) UpdateSetting(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	setting, err := h.settingService.UpdateSetting(id, &req, activityActor(c))
+	if err != nil {
+		sendSettingError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Setting updated successfully", setting)
+}
+
+// DeleteSetting handles DELETE /api/settings/:id
+func (h *SettingHandler) DeleteSetting(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	setting, err := h.settingService.DeleteSetting(id, activityActor(c))
+	if err != nil {
+		sendSettingError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Setting deleted successfully", setting)
+}
+
+// GetSettingHistory handles GET /api/settings/:id/history
+func (h *SettingHandler) GetSettingHistory(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.settingService.GetSettingHistory(id, params)
+	if err != nil {
+		sendSettingError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Setting history fetched successfully", response)
+}
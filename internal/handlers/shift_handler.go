@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ShiftHandler struct {
+	shiftService *services.ShiftService
+	validate     *validator.Validate
+}
+
+func NewShiftHandler(shiftService *services.ShiftService) *ShiftHandler {
+	return &ShiftHandler{
+		shiftService: shiftService,
+		validate:     validator.New(),
+	}
+}
+
+// sendShiftError maps shift service errors to API responses
+func sendShiftError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "shift not found":
+		common.SendError(c, http.StatusNotFound, "Shift not found", common.CodeNotFound, nil)
+	case "no open shift":
+		common.SendError(c, http.StatusNotFound, "You have no open shift", common.CodeNotFound, nil)
+	case "device required":
+		common.SendError(c, http.StatusBadRequest, "Shifts are opened on a terminal; a device is required", common.CodeValidationError, nil)
+	case "counted cash required":
+		common.SendError(c, http.StatusBadRequest, "The cash in the drawer must be counted", common.CodeValidationError, nil)
+	case "shift belongs to another user":
+		common.SendError(c, http.StatusForbidden, "Shifts are closed by the cashier who opened them", common.CodeForbidden, nil)
+	case "shift already open":
+		common.SendError(c, http.StatusConflict, "You or this terminal already have an open shift", common.CodeConflict, nil)
+	case "shift closed":
+		common.SendError(c, http.StatusConflict, "Shift is closed", common.CodeConflict, nil)
+	default:
+		sendCashDrawerError(c, err)
+	}
+}
+
+// GetAllShifts handles GET /api/shifts
+func (h *ShiftHandler) GetAllShifts(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.shiftService.GetAllShifts(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch shifts", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Shifts fetched successfully", response)
+}
+
+// OpenShift handles POST /api/shifts/open
+func (h *ShiftHandler) OpenShift(c *gin.Context) {
+	var req models.OpenShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	var terminal *models.Devices
+	if device, ok := middleware.CurrentDevice(c); ok {
+		terminal = &device
+	}
+
+	shift, err := h.shiftService.OpenShift(&req, terminal, activityActor(c))
+	if err != nil {
+		sendShiftError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Shift opened successfully", shift)
+}
+
+// GetCurrentShift handles GET /api/shifts/current
+func (h *ShiftHandler) GetCurrentShift(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		common.SendError(c, http.StatusUnauthorized, "Unauthorized", common.CodeUnauthorized, nil)
+		return
+	}
+
+	report, err := h.shiftService.GetCurrentShift(user.ID)
+	if err != nil {
+		sendShiftError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Shift fetched successfully", report)
+}
+
+// GetShiftReport handles GET /api/shifts/:id
+func (h *ShiftHandler) GetShiftReport(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	report, err := h.shiftService.GetShiftReport(id)
+	if err != nil {
+		sendShiftError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Shift fetched successfully", report)
+}
+
+// CloseShift handles POST /api/shifts/:id/close
+func (h *ShiftHandler) CloseShift(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.CloseShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	report, err := h.shiftService.CloseShift(id, &req, activityActor(c))
+	if err != nil {
+		sendShiftError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Shift closed successfully", report)
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type SLOHandler struct {
+	sloService *services.SLOService
+}
+
+func NewSLOHandler(sloService *services.SLOService) *SLOHandler {
+	return &SLOHandler{
+		sloService: sloService,
+	}
+}
+
+// GetSLOStatus handles GET /api/admin/slo, reporting the burn rate of every response time
+// objective as seen by this API instance
+func (h *SLOHandler) GetSLOStatus(c *gin.Context) {
+	common.SendSuccess(c, http.StatusOK, "SLO status fetched successfully", h.sloService.GetReport())
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type StocktakeHandler struct {
+	stocktakeService *services.StocktakeService
+	validate         *validator.Validate
+}
+
+func NewStocktakeHandler(stocktakeService *services.StocktakeService) *StocktakeHandler {
+	return &StocktakeHandler{
+		stocktakeService: stocktakeService,
+		validate:         validator.New(),
+	}
+}
+
+// sendStocktakeError maps stocktake service errors to API responses
+func sendStocktakeError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "stocktake not found":
+		common.SendError(c, http.StatusNotFound, "Stocktake not found", common.CodeNotFound, nil)
+	case "store not found":
+		common.SendError(c, http.StatusBadRequest, "Store not found", common.CodeValidationError, nil)
+	case "store inactive":
+		common.SendError(c, http.StatusBadRequest, "Stock of an inactive store can't be counted", common.CodeValidationError, nil)
+	case "duplicate stocktake line":
+		common.SendError(c, http.StatusBadRequest, "A product or variant is counted once per request", common.CodeValidationError, nil)
+	case "stocktake has no counts":
+		common.SendError(c, http.StatusBadRequest, "Nothing has been counted in this stocktake", common.CodeValidationError, nil)
+	case "stocktake already open":
+		common.SendError(c, http.StatusConflict, "A stocktake is already open for this store", common.CodeConflict, nil)
+	case "stocktake closed":
+		common.SendError(c, http.StatusConflict, "The stocktake has been approved or cancelled", common.CodeConflict, nil)
+	default:
+		sendInventoryError(c, err)
+	}
+}
+
+// GetAllStocktakes handles GET /api/stocktakes
+func (h *StocktakeHandler) GetAllStocktakes(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.stocktakeService.GetAllStocktakes(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch stocktakes", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Stocktakes fetched successfully", response)
+}
+
+// GetStocktakeById handles GET /api/stocktakes/:id
+func (h *StocktakeHandler) GetStocktakeById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	stocktake, err := h.stocktakeService.GetStocktakeById(id)
+	if err != nil {
+		sendStocktakeError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Stocktake fetched successfully", stocktake)
+}
+
+// GetStocktakeReport handles GET /api/stocktakes/:id/report
+func (h *StocktakeHandler) GetStocktakeReport(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	report, err := h.stocktakeService.GetStocktakeReport(id)
+	if err != nil {
+		sendStocktakeError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Stocktake report fetched successfully", report)
+}
+
+// CreateStocktake handles POST /api/stocktakes
+func (h *StocktakeHandler) CreateStocktake(c *gin.Context) {
+	var req models.CreateStocktakeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	if store, ok := middleware.CurrentStore(c); ok {
+		req.StoreID = &store.ID
+	}
+
+	stocktake, err := h.stocktakeService.CreateStocktake(&req, activityActor(c))
+	if err != nil {
+		sendStocktakeError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Stocktake opened successfully", stocktake)
+}
+
+// RecordCounts handles POST /api/stocktakes/:id/counts
+func (h *StocktakeHandler) RecordCounts(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.StocktakeCountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	if err := limits.Check(limits.MaxBulkIDs, len(req.Items)); err != nil {
+		sendBindError(c, "Invalid request body", err)
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	stocktake, err := h.stocktakeService.RecordCounts(id, &req, activityActor(c))
+	if err != nil {
+		sendStocktakeError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Counts recorded successfully", stocktake)
+}
+
+// RecordScans handles POST /api/stocktakes/:id/scans
+func (h *StocktakeHandler) RecordScans(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.StocktakeScanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	if err := limits.Check(limits.MaxBulkIDs, len(req.Barcodes)); err != nil {
+		sendBindError(c, "Invalid request body", err)
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	result, err := h.stocktakeService.RecordScans(id, &req, activityActor(c))
+	if err != nil {
+		sendStocktakeError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Scans recorded successfully", result)
+}
+
+// ApproveStocktake handles PUT /api/stocktakes/:id/approve
+func (h *StocktakeHandler) ApproveStocktake(c *gin.Context) {
+	h.close(c, "Stocktake approved successfully", h.stocktakeService.ApproveStocktake)
+}
+
+// CancelStocktake handles PUT /api/stocktakes/:id/cancel
+func (h *StocktakeHandler) CancelStocktake(c *gin.Context) {
+	h.close(c, "Stocktake cancelled successfully", h.stocktakeService.CancelStocktake)
+}
+
+// close closes the stocktake in the :id parameter with one of the service's steps
+func (h *StocktakeHandler) close(c *gin.Context, message string, step func(id uint, actor models.ActivityActor) (*models.Stocktakes, error)) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	stocktake, err := step(id, activityActor(c))
+	if err != nil {
+		sendStocktakeError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, message, stocktake)
+}
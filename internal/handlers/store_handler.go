@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type StoreHandler struct {
+	storeService *services.StoreService
+	validate     *validator.Validate
+}
+
+func NewStoreHandler(storeService *services.StoreService) *StoreHandler {
+	return &StoreHandler{
+		storeService: storeService,
+		validate:     validator.New(),
+	}
+}
+
+// sendStoreError maps store service errors to API responses
+func sendStoreError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "store not found":
+		common.SendError(c, http.StatusNotFound, "Store not found", common.CodeNotFound, nil)
+	case "user not found":
+		common.SendError(c, http.StatusNotFound, "User not found", common.CodeNotFound, nil)
+	case "store code already exists":
+		common.SendError(c, http.StatusConflict, "A store with this code already exists", common.CodeConflict, nil)
+	case "store has devices":
+		common.SendError(c, http.StatusConflict, "Terminals are still set up at this store; move them first", common.CodeConflict, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// GetAllStores handles GET /api/stores
+func (h *StoreHandler) GetAllStores(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.storeService.GetAllStores(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch stores", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Stores fetched successfully", response)
+}
+
+// GetStoreById handles GET /api/stores/:id
+func (h *StoreHandler) GetStoreById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	store, err := h.storeService.GetStoreById(id)
+	if err != nil {
+		sendStoreError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Store fetched successfully", store)
+}
+
+// CreateStore handles POST /api/stores
+func (h *StoreHandler) CreateStore(c *gin.Context) {
+	var req models.StoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	store, err := h.storeService.CreateStore(&req)
+	if err != nil {
+		sendStoreError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Store created successfully", store)
+}
+
+// UpdateStore handles PUT /api/stores/:id
+func (h *StoreHandler) UpdateStore(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.StoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	store, err := h.storeService.UpdateStore(id, &req)
+	if err != nil {
+		sendStoreError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Store updated successfully", store)
+}
+
+// DeleteStore handles DELETE /api/stores/:id
+func (h *StoreHandler) DeleteStore(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	store, err := h.storeService.DeleteStore(id)
+	if err != nil {
+		sendStoreError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Store deleted successfully", store)
+}
+
+// GetUserStores handles GET /api/users/:id/stores
+func (h *StoreHandler) GetUserStores(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	stores, err := h.storeService.GetUserStores(id)
+	if err != nil {
+		sendStoreError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "User stores fetched successfully", stores)
+}
+
+// SetUserStores handles PUT /api/users/:id/stores
+func (h *StoreHandler) SetUserStores(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.SetUserStoresRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	stores, err := h.storeService.SetUserStores(id, &req)
+	if err != nil {
+		sendStoreError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "User stores updated successfully", stores)
+}
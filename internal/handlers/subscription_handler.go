@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type SubscriptionHandler struct {
+	subscriptionService *services.SubscriptionService
+	validate            *validator.Validate
+}
+
+func NewSubscriptionHandler(subscriptionService *services.SubscriptionService) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		subscriptionService: subscriptionService,
+		validate:            validator.New(),
+	}
+}
+
+// sendSubscriptionError maps subscription service errors to API responses
+func sendSubscriptionError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "plan not found":
+		common.SendError(c, http.StatusNotFound, "Plan not found", common.CodeNotFound, nil)
+	case "plan code already exists":
+		common.SendError(c, http.StatusConflict, "Plan code already exists", common.CodeConflict, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// currentTenant responds with 404 when the request host has no tenant
+func currentTenant(c *gin.Context) (models.Tenants, bool) {
+	tenant, ok := middleware.CurrentTenant(c)
+	if !ok {
+		common.SendError(c, http.StatusNotFound, "No tenant for this host", common.CodeNotFound, nil)
+	}
+	return tenant, ok
+}
+
+// GetSubscription handles GET /api/admin/subscription
+func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
+	tenant, ok := currentTenant(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.subscriptionService.GetSubscription(tenant)
+	if err != nil {
+		sendSubscriptionError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Subscription fetched successfully", response)
+}
+
+// UpdateSubscription handles PUT /api/admin/subscription
+func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
+	tenant, ok := currentTenant(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	response, err := h.subscriptionService.ChangePlan(tenant, req.PlanCode)
+	if err != nil {
+		sendSubscriptionError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Subscription updated successfully", response)
+}
+
+// GetAllPlans handles GET /api/admin/plans
+func (h *SubscriptionHandler) GetAllPlans(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.subscriptionService.GetAllPlans(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch plans", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Plans fetched successfully", response)
+}
+
+// CreatePlan handles POST /api/admin/plans
+func (h *SubscriptionHandler) CreatePlan(c *gin.Context) {
+	var req models.CreatePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	plan, err := h.subscriptionService.CreatePlan(&req)
+	if err != nil {
+		sendSubscriptionError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Plan created successfully", plan)
+}
+
+// UpdatePlan handles PUT /api/admin/plans/:id
+func (h *SubscriptionHandler) UpdatePlan(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdatePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	plan, err := h.subscriptionService.UpdatePlan(id, &req)
+	if err != nil {
+		sendSubscriptionError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Plan updated successfully", plan)
+}
+
+// StripeWebhook handles POST /api/billing/stripe/webhook
+func (h *SubscriptionHandler) StripeWebhook(c *gin.Context) {
+	payload, err := c.GetRawData()
+	if err != nil {
+		sendBindError(c, "Invalid request body", err)
+		return
+	}
+
+	if err := h.subscriptionService.HandleStripeWebhook(payload, c.GetHeader("Stripe-Signature")); err != nil {
+		switch err.Error() {
+		case "invalid webhook signature":
+			common.SendError(c, http.StatusBadRequest, "Invalid webhook signature", common.CodeInvalidWebhookSignature, nil)
+		case "invalid webhook payload":
+			common.SendError(c, http.StatusBadRequest, "Invalid webhook payload", common.CodeInvalidRequest, nil)
+		default:
+			slog.ErrorContext(c.Request.Context(), "Stripe webhook: failed to apply event", "error", err)
+			common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		}
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Webhook processed successfully", nil)
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type SupplierHandler struct {
+	supplierService *services.SupplierService
+	validate        *validator.Validate
+}
+
+func NewSupplierHandler(supplierService *services.SupplierService) *SupplierHandler {
+	return &SupplierHandler{
+		supplierService: supplierService,
+		validate:        validator.New(),
+	}
+}
+
+// sendSupplierError maps supplier service errors to API responses
+func sendSupplierError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "supplier not found":
+		common.SendError(c, http.StatusNotFound, "Supplier not found", common.CodeNotFound, nil)
+	case "product not found":
+		common.SendError(c, http.StatusNotFound, "Product not found", common.CodeNotFound, nil)
+	case "supplier already exists":
+		common.SendError(c, http.StatusConflict, "A supplier with this name already exists", common.CodeConflict, nil)
+	case "duplicate supplier":
+		common.SendError(c, http.StatusBadRequest, "A supplier can only be listed once per product", common.CodeValidationError, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// GetAllSuppliers handles GET /api/suppliers
+func (h *SupplierHandler) GetAllSuppliers(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.supplierService.GetAllSuppliers(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch suppliers", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Suppliers fetched successfully", response)
+}
+
+// GetSupplierById handles GET /api/suppliers/:id
+func (h *SupplierHandler) GetSupplierById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	supplier, err := h.supplierService.GetSupplierById(id)
+	if err != nil {
+		sendSupplierError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Supplier fetched successfully", supplier)
+}
+
+// GetSupplierProducts handles GET /api/suppliers/:id/products
+func (h *SupplierHandler) GetSupplierProducts(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.supplierService.GetSupplierProducts(id, params)
+	if err != nil {
+		sendSupplierError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Supplier products fetched successfully", response)
+}
+
+// CreateSupplier handles POST /api/suppliers
+func (h *SupplierHandler) CreateSupplier(c *gin.Context) {
+	var req models.SupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	supplier, err := h.supplierService.CreateSupplier(&req)
+	if err != nil {
+		sendSupplierError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Supplier created successfully", supplier)
+}
+
+// UpdateSupplier handles PUT /api/suppliers/:id
+func (h *SupplierHandler) UpdateSupplier(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.SupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	supplier, err := h.supplierService.UpdateSupplier(id, &req)
+	if err != nil {
+		sendSupplierError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Supplier updated successfully", supplier)
+}
+
+// DeleteSupplier handles DELETE /api/suppliers/:id
+func (h *SupplierHandler) DeleteSupplier(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	supplier, err := h.supplierService.DeleteSupplier(id)
+	if err != nil {
+		sendSupplierError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Supplier deleted successfully", supplier)
+}
+
+// GetProductSuppliers handles GET /api/products/:id/suppliers
+func (h *SupplierHandler) GetProductSuppliers(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	suppliers, err := h.supplierService.GetProductSuppliers(id)
+	if err != nil {
+		sendSupplierError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Product suppliers fetched successfully", suppliers)
+}
+
+// SetProductSuppliers handles PUT /api/products/:id/suppliers
+func (h *SupplierHandler) SetProductSuppliers(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.SetProductSuppliersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	suppliers, err := h.supplierService.SetProductSuppliers(id, &req)
+	if err != nil {
+		sendSupplierError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Product suppliers updated successfully", suppliers)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/license"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/schema"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type SystemHandler struct {
+	cache        *cache.Cache
+	readOnly     *middleware.ReadOnlyMode
+	license      *license.Manager
+	storageQuota *services.StorageQuotaService
+	validate     *validator.Validate
+}
+
+func NewSystemHandler(cache *cache.Cache, readOnly *middleware.ReadOnlyMode, license *license.Manager, storageQuota *services.StorageQuotaService) *SystemHandler {
+	return &SystemHandler{
+		cache:        cache,
+		readOnly:     readOnly,
+		license:      license,
+		storageQuota: storageQuota,
+		validate:     validator.New(),
+	}
+}
+
+// GetCacheStatus handles GET /api/system/cache, reporting the Redis state and time spent degraded
+func (h *SystemHandler) GetCacheStatus(c *gin.Context) {
+	common.SendSuccess(c, http.StatusOK, "Cache status fetched successfully", h.cache.Stats())
+}
+
+// GetReadOnlyMode handles GET /api/system/read-only
+func (h *SystemHandler) GetReadOnlyMode(c *gin.Context) {
+	common.SendSuccess(c, http.StatusOK, "Read-only mode fetched successfully", h.readOnly.Status())
+}
+
+// UpdateReadOnlyMode handles PUT /api/system/read-only
+func (h *SystemHandler) UpdateReadOnlyMode(c *gin.Context) {
+	var req models.UpdateReadOnlyModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	status := h.readOnly.Set(*req.Enabled, req.Reason)
+
+	user, _ := middleware.CurrentUser(c)
+	slog.InfoContext(c.Request.Context(), "Read-only mode set", "enabled", status.Enabled, "user_id", user.ID, "reason", req.Reason)
+
+	common.SendSuccess(c, http.StatusOK, "Read-only mode updated successfully", status)
+}
+
+// GetLicense handles GET /api/system/license, reporting the license status and unlocked features
+func (h *SystemHandler) GetLicense(c *gin.Context) {
+	common.SendSuccess(c, http.StatusOK, "License fetched successfully", h.license.Entitlements())
+}
+
+// GetStorageUsage handles GET /api/system/storage, reporting the bytes and files every tenant
+// and store keeps in storage against the configured quotas
+func (h *SystemHandler) GetStorageUsage(c *gin.Context) {
+	report, err := h.storageQuota.GetUsage(c.Request.Context())
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch storage usage", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Storage usage fetched successfully", report)
+}
+
+// GetSchemaVersions handles GET /api/system/schemas, listing the routes that serve more than one response schema version
+func (h *SystemHandler) GetSchemaVersions(c *gin.Context) {
+	common.SendSuccess(c, http.StatusOK, "Schema versions fetched successfully", schema.Endpoints())
+}
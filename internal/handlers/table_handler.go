@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// ticketTableKey is the context key of the table whose ticket a request works on
+const ticketTableKey = "ticket_table"
+
+type TableHandler struct {
+	tableService *services.TableService
+	cartService  *services.CartService
+	validate     *validator.Validate
+}
+
+func NewTableHandler(tableService *services.TableService, cartService *services.CartService) *TableHandler {
+	return &TableHandler{
+		tableService: tableService,
+		cartService:  cartService,
+		validate:     validator.New(),
+	}
+}
+
+// sendTableError maps table service errors to API responses
+func sendTableError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "dining area not found":
+		common.SendError(c, http.StatusNotFound, "Dining area not found", common.CodeNotFound, nil)
+	case "table not found":
+		common.SendError(c, http.StatusNotFound, "Table not found", common.CodeNotFound, nil)
+	case "store not found":
+		common.SendError(c, http.StatusBadRequest, "Store not found", common.CodeValidationError, nil)
+	case "table inactive":
+		common.SendError(c, http.StatusBadRequest, "Tickets can't be opened at an inactive table", common.CodeValidationError, nil)
+	case "same table":
+		common.SendError(c, http.StatusBadRequest, "The ticket is already at this table", common.CodeValidationError, nil)
+	case "dining area has tables":
+		common.SendError(c, http.StatusConflict, "The dining area still has tables; move or delete them first", common.CodeConflict, nil)
+	case "table has open ticket":
+		common.SendError(c, http.StatusConflict, "The table has an open ticket", common.CodeConflict, nil)
+	default:
+		sendCartError(c, err)
+	}
+}
+
+// TicketTable resolves the table in the :id parameter for the ticket routes, which then work
+// on the table's cart
+func (h *TableHandler) TicketTable() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := binding.ID(c, "id")
+		if !ok {
+			c.Abort()
+			return
+		}
+
+		var storeID *uint
+		if store, ok := middleware.CurrentStore(c); ok {
+			storeID = &store.ID
+		}
+		table, err := h.tableService.GetTicketTable(id, storeID)
+		if err != nil {
+			sendTableError(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Set(ticketTableKey, table)
+		c.Next()
+	}
+}
+
+// GetAllDiningAreas handles GET /api/dining-areas
+func (h *TableHandler) GetAllDiningAreas(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.tableService.GetAllDiningAreas(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch dining areas", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Dining areas fetched successfully", response)
+}
+
+// GetDiningAreaById handles GET /api/dining-areas/:id
+func (h *TableHandler) GetDiningAreaById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	area, err := h.tableService.GetDiningAreaById(id)
+	if err != nil {
+		sendTableError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Dining area fetched successfully", area)
+}
+
+// CreateDiningArea handles POST /api/dining-areas
+func (h *TableHandler) CreateDiningArea(c *gin.Context) {
+	var req models.DiningAreaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	area, err := h.tableService.CreateDiningArea(&req)
+	if err != nil {
+		sendTableError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Dining area created successfully", area)
+}
+
+// UpdateDiningArea handles PUT /api/dining-areas/:id
+func (h *TableHandler) UpdateDiningArea(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.DiningAreaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	area, err := h.tableService.UpdateDiningArea(id, &req)
+	if err != nil {
+		sendTableError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Dining area updated successfully", area)
+}
+
+// DeleteDiningArea handles DELETE /api/dining-areas/:id
+func (h *TableHandler) DeleteDiningArea(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	area, err := h.tableService.DeleteDiningArea(id)
+	if err != nil {
+		sendTableError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Dining area deleted successfully", area)
+}
+
+// GetAllTables handles GET /api/tables
+func (h *TableHandler) GetAllTables(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.tableService.GetAllTables(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch tables", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Tables fetched successfully", response)
+}
+
+// GetTableById handles GET /api/tables/:id
+func (h *TableHandler) GetTableById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	table, err := h.tableService.GetTableById(id)
+	if err != nil {
+		sendTableError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Table fetched successfully", table)
+}
+
+// CreateTable handles POST /api/tables
+func (h *TableHandler) CreateTable(c *gin.Context) {
+	var req models.DiningTableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	table, err := h.tableService.CreateTable(&req)
+	if err != nil {
+		sendTableError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Table created successfully", table)
+}
+
+// UpdateTable handles PUT /api/tables/:id
+func (h *TableHandler) UpdateTable(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.DiningTableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	table, err := h.tableService.UpdateTable(id, &req)
+	if err != nil {
+		sendTableError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Table updated successfully", table)
+}
+
+// DeleteTable handles DELETE /api/tables/:id
+func (h *TableHandler) DeleteTable(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	table, err := h.tableService.DeleteTable(id)
+	if err != nil {
+		sendTableError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Table deleted successfully", table)
+}
+
+// TransferTicket handles PUT /api/tables/:id/ticket/transfer
+func (h *TableHandler) TransferTicket(c *gin.Context) {
+	h.moveTicket(c, "Ticket transferred successfully", h.cartService.TransferTicket)
+}
+
+// MergeTicket handles POST /api/tables/:id/ticket/merge
+func (h *TableHandler) MergeTicket(c *gin.Context) {
+	h.moveTicket(c, "Tickets merged successfully", h.cartService.MergeTicket)
+}
+
+// moveTicket moves the ticket of the request's table to the table in the body with one of the
+// cart service's steps
+func (h *TableHandler) moveTicket(c *gin.Context, message string, step func(ctx context.Context, owner models.CartOwner, tableID uint) (*models.CartResponse, error)) {
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+
+	var req models.MoveTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	var storeID *uint
+	if store, ok := middleware.CurrentStore(c); ok {
+		storeID = &store.ID
+	}
+	if _, err := h.tableService.GetTicketTable(req.TableID, storeID); err != nil {
+		sendTableError(c, err)
+		return
+	}
+
+	cart, err := step(c.Request.Context(), owner, req.TableID)
+	if err != nil {
+		sendTableError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, message, cart)
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type TagHandler struct {
+	tagService *services.TagService
+	validate   *validator.Validate
+}
+
+func NewTagHandler(tagService *services.TagService) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+		validate:   validator.New(),
+	}
+}
+
+// sendTagError maps tag service errors to API responses
+func sendTagError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "tag not found":
+		common.SendError(c, http.StatusNotFound, "Tag not found", common.CodeNotFound, nil)
+	case "user not found":
+		common.SendError(c, http.StatusNotFound, "User not found", common.CodeNotFound, nil)
+	case "user does not have this tag":
+		common.SendError(c, http.StatusNotFound, "User does not have this tag", common.CodeNotFound, nil)
+	case "tag name already exists":
+		common.SendError(c, http.StatusConflict, "Tag name already exists", common.CodeConflict, nil)
+	case "invalid tag name":
+		common.SendError(c, http.StatusBadRequest, "Tag names are lowercase letters, digits, dashes, or underscores", common.CodeValidationError, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// bindTag binds and validates a tag request
+func (h *TagHandler) bindTag(c *gin.Context) (*models.TagRequest, bool) {
+	var req models.TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return nil, false
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return nil, false
+	}
+
+	return &req, true
+}
+
+// GetAllTags handles GET /api/tags
+func (h *TagHandler) GetAllTags(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.tagService.GetAllTags(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch tags", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Tags fetched successfully", response)
+}
+
+// CreateTag handles POST /api/tags
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	req, ok := h.bindTag(c)
+	if !ok {
+		return
+	}
+
+	tag, err := h.tagService.CreateTag(req)
+	if err != nil {
+		sendTagError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Tag created successfully", tag)
+}
+
+// UpdateTag handles PUT /api/tags/:id
+func (h *TagHandler) UpdateTag(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := h.bindTag(c)
+	if !ok {
+		return
+	}
+
+	tag, err := h.tagService.UpdateTag(id, req)
+	if err != nil {
+		sendTagError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Tag updated successfully", tag)
+}
+
+// DeleteTag handles DELETE /api/tags/:id
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	tag, err := h.tagService.DeleteTag(id)
+	if err != nil {
+		sendTagError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Tag deleted successfully", tag)
+}
+
+// AssignUserTags handles POST /api/user/:id/tags
+func (h *TagHandler) AssignUserTags(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.AssignUserTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	if err := limits.Check(limits.MaxBulkIDs, len(req.TagIDs)); err != nil {
+		sendBindError(c, "Invalid request body", err)
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	tags, err := h.tagService.AssignUserTags(id, &req, activityActor(c))
+	if err != nil {
+		sendTagError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Tags assigned successfully", tags)
+}
+
+// RemoveUserTag handles DELETE /api/user/:id/tags/:tagId
+func (h *TagHandler) RemoveUserTag(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+	tagID, ok := binding.ID(c, "tagId")
+	if !ok {
+		return
+	}
+
+	tags, err := h.tagService.RemoveUserTag(id, tagID, activityActor(c))
+	if err != nil {
+		sendTagError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Tag removed successfully", tags)
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type TaxHandler struct {
+	taxService *services.TaxService
+	validate   *validator.Validate
+}
+
+func NewTaxHandler(taxService *services.TaxService) *TaxHandler {
+	return &TaxHandler{
+		taxService: taxService,
+		validate:   validator.New(),
+	}
+}
+
+// sendTaxError maps tax service errors to API responses
+func sendTaxError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "tax class not found":
+		common.SendError(c, http.StatusNotFound, "Tax class not found", common.CodeNotFound, nil)
+	case "tax rate not found":
+		common.SendError(c, http.StatusNotFound, "Tax rate not found", common.CodeNotFound, nil)
+	case "tax class already exists":
+		common.SendError(c, http.StatusConflict, "Tax class already exists", common.CodeConflict, nil)
+	case "tax class in use":
+		common.SendError(c, http.StatusConflict, "Tax class is assigned to products", common.CodeConflict, nil)
+	case "tax rates of a region must all be inclusive or exclusive":
+		common.SendError(c, http.StatusBadRequest, "Tax rates of a class in a region must all be inclusive or all exclusive", common.CodeValidationError, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// GetAllTaxClasses handles GET /api/tax-classes
+func (h *TaxHandler) GetAllTaxClasses(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.taxService.GetAllTaxClasses(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch tax classes", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Tax classes fetched successfully", response)
+}
+
+// GetTaxClassById handles GET /api/tax-classes/:id
+func (h *TaxHandler) GetTaxClassById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	class, err := h.taxService.GetTaxClassById(id)
+	if err != nil {
+		sendTaxError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Tax class fetched successfully", class)
+}
+
+// CreateTaxClass handles POST /api/tax-classes
+func (h *TaxHandler) CreateTaxClass(c *gin.Context) {
+	var req models.CreateTaxClassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	class, err := h.taxService.CreateTaxClass(&req)
+	if err != nil {
+		sendTaxError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Tax class created successfully", class)
+}
+
+// UpdateTaxClass handles PUT /api/tax-classes/:id
+func (h *TaxHandler) UpdateTaxClass(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTaxClassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	class, err := h.taxService.UpdateTaxClass(id, &req)
+	if err != nil {
+		sendTaxError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Tax class updated successfully", class)
+}
+
+// DeleteTaxClass handles DELETE /api/tax-classes/:id
+func (h *TaxHandler) DeleteTaxClass(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	class, err := h.taxService.DeleteTaxClass(id)
+	if err != nil {
+		sendTaxError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Tax class deleted successfully", class)
+}
+
+// CreateTaxRate handles POST /api/tax-classes/:id/rates
+func (h *TaxHandler) CreateTaxRate(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.TaxRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	rate, err := h.taxService.CreateTaxRate(id, &req)
+	if err != nil {
+		sendTaxError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Tax rate created successfully", rate)
+}
+
+// UpdateTaxRate handles PUT /api/tax-classes/:id/rates/:rateId
+func (h *TaxHandler) UpdateTaxRate(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+	rateID, ok := binding.ID(c, "rateId")
+	if !ok {
+		return
+	}
+
+	var req models.TaxRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	rate, err := h.taxService.UpdateTaxRate(id, rateID, &req)
+	if err != nil {
+		sendTaxError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Tax rate updated successfully", rate)
+}
+
+// DeleteTaxRate handles DELETE /api/tax-classes/:id/rates/:rateId
+func (h *TaxHandler) DeleteTaxRate(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+	rateID, ok := binding.ID(c, "rateId")
+	if !ok {
+		return
+	}
+
+	rate, err := h.taxService.DeleteTaxRate(id, rateID)
+	if err != nil {
+		sendTaxError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Tax rate deleted successfully", rate)
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type TeamHandler struct {
+	teamService *services.TeamService
+	validate    *validator.Validate
+}
+
+func NewTeamHandler(teamService *services.TeamService) *TeamHandler {
+	return &TeamHandler{
+		teamService: teamService,
+		validate:    validator.New(),
+	}
+}
+
+// sendTeamError maps team service errors to API responses
+func sendTeamError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "team not found":
+		common.SendError(c, http.StatusNotFound, "Team not found", common.CodeNotFound, nil)
+	case "team member not found":
+		common.SendError(c, http.StatusNotFound, "Team member not found", common.CodeNotFound, nil)
+	case "user not found":
+		common.SendError(c, http.StatusNotFound, "User not found", common.CodeNotFound, nil)
+	case "team name already exists":
+		common.SendError(c, http.StatusConflict, "Team name already exists", common.CodeConflict, nil)
+	case "user is already a team member":
+		common.SendError(c, http.StatusConflict, "User is already a team member", common.CodeConflict, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// GetAllTeams handles GET /api/teams
+func (h *TeamHandler) GetAllTeams(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.teamService.GetAllTeams(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch teams", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Teams fetched successfully", response)
+}
+
+// GetTeamById handles GET /api/teams/:id
+func (h *TeamHandler) GetTeamById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	team, err := h.teamService.GetTeamById(id)
+	if err != nil {
+		sendTeamError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Team fetched successfully", team)
+}
+
+// CreateTeam handles POST /api/teams
+func (h *TeamHandler) CreateTeam(c *gin.Context) {
+	var req models.CreateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	team, err := h.teamService.CreateTeam(&req)
+	if err != nil {
+		sendTeamError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Team created successfully", team)
+}
+
+// UpdateTeam handles PUT /api/teams/:id
+func (h *TeamHandler) UpdateTeam(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	team, err := h.teamService.UpdateTeam(id, &req)
+	if err != nil {
+		sendTeamError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Team updated successfully", team)
+}
+
+// DeleteTeam handles DELETE /api/teams/:id
+func (h *TeamHandler) DeleteTeam(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	team, err := h.teamService.DeleteTeam(id)
+	if err != nil {
+		sendTeamError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Team deleted successfully", team)
+}
+
+// GetTeamMembers handles GET /api/teams/:id/members
+func (h *TeamHandler) GetTeamMembers(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	members, err := h.teamService.GetTeamMembers(id)
+	if err != nil {
+		sendTeamError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Team members fetched successfully", members)
+}
+
+// AddTeamMember handles POST /api/teams/:id/members
+func (h *TeamHandler) AddTeamMember(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.AddTeamMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	member, err := h.teamService.AddTeamMember(id, &req)
+	if err != nil {
+		sendTeamError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Team member added successfully", member)
+}
+
+// UpdateTeamMember handles PUT /api/teams/:id/members/:userId
+func (h *TeamHandler) UpdateTeamMember(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := binding.ID(c, "userId")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTeamMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	member, err := h.teamService.UpdateTeamMember(id, userID, &req)
+	if err != nil {
+		sendTeamError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Team member updated successfully", member)
+}
+
+// RemoveTeamMember handles DELETE /api/teams/:id/members/:userId
+func (h *TeamHandler) RemoveTeamMember(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := binding.ID(c, "userId")
+	if !ok {
+		return
+	}
+
+	member, err := h.teamService.RemoveTeamMember(id, userID)
+	if err != nil {
+		sendTeamError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Team member removed successfully", member)
+}
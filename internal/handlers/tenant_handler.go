@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type TenantHandler struct {
+	tenantService *services.TenantService
+	validate      *validator.Validate
+}
+
+func NewTenantHandler(tenantService *services.TenantService) *TenantHandler {
+	return &TenantHandler{
+		tenantService: tenantService,
+		validate:      validator.New(),
+	}
+}
+
+// sendTenantError maps tenant service errors to API responses
+func sendTenantError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "tenant not found":
+		common.SendError(c, http.StatusNotFound, "Tenant not found", common.CodeNotFound, nil)
+	case "tenant host already exists":
+		common.SendError(c, http.StatusConflict, "Tenant host already exists", common.CodeConflict, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// GetAllTenants handles GET /api/tenants
+func (h *TenantHandler) GetAllTenants(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.tenantService.GetAllTenants(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch tenants", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Tenants fetched successfully", response)
+}
+
+// GetTenantById handles GET /api/tenants/:id
+func (h *TenantHandler) GetTenantById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	tenant, err := h.tenantService.GetTenantById(id)
+	if err != nil {
+		sendTenantError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Tenant fetched successfully", tenant)
+}
+
+// CreateTenant handles POST /api/tenants
+func (h *TenantHandler) CreateTenant(c *gin.Context) {
+	var req models.CreateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	tenant, err := h.tenantService.CreateTenant(&req)
+	if err != nil {
+		sendTenantError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Tenant created successfully", tenant)
+}
+
+// UpdateTenant handles PUT /api/tenants/:id
+func (h *TenantHandler) UpdateTenant(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	tenant, err := h.tenantService.UpdateTenant(id, &req)
+	if err != nil {
+		sendTenantError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Tenant updated successfully", tenant)
+}
+
+// DeleteTenant handles DELETE /api/tenants/:id
+func (h *TenantHandler) DeleteTenant(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	tenant, err := h.tenantService.DeleteTenant(id)
+	if err != nil {
+		sendTenantError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Tenant deleted successfully", tenant)
+}
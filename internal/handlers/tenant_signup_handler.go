@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type TenantSignupHandler struct {
+	tenantSignupService *services.TenantSignupService
+	validate            *validator.Validate
+}
+
+func NewTenantSignupHandler(tenantSignupService *services.TenantSignupService) *TenantSignupHandler {
+	return &TenantSignupHandler{
+		tenantSignupService: tenantSignupService,
+		validate:            validator.New(),
+	}
+}
+
+// sendTenantSignupError maps tenant signup errors to API responses
+func sendTenantSignupError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "tenant not found":
+		common.SendError(c, http.StatusNotFound, "Tenant not found", common.CodeNotFound, nil)
+	case "tenant host already exists":
+		common.SendError(c, http.StatusConflict, "Tenant host already exists", common.CodeConflict, nil)
+	case "username already exists":
+		common.SendError(c, http.StatusConflict, "Username already exists", common.CodeUsernameExists, nil)
+	case "email already exists":
+		common.SendError(c, http.StatusConflict, "Email already exists", common.CodeEmailExists, nil)
+	case "invalid verification token", "verification token expired":
+		common.SendError(c, http.StatusBadRequest, "Invalid or expired verification token", common.CodeInvalidRequest, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// Signup handles POST /api/tenants/signup
+func (h *TenantSignupHandler) Signup(c *gin.Context) {
+	var req models.TenantSignupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	status, err := h.tenantSignupService.Signup(&req, activityActor(c))
+	if err != nil {
+		sendTenantSignupError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Tenant registered, check your email to verify it", status)
+}
+
+// VerifySignup handles POST /api/tenants/signup/verify
+func (h *TenantSignupHandler) VerifySignup(c *gin.Context) {
+	var req models.VerifyTenantSignupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	status, err := h.tenantSignupService.VerifySignup(req.Token)
+	if err != nil {
+		sendTenantSignupError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Tenant verified successfully", status)
+}
+
+// GetProvisioningStatus handles GET /api/tenants/signup/status?host=
+func (h *TenantSignupHandler) GetProvisioningStatus(c *gin.Context) {
+	host := c.Query("host")
+	if host == "" {
+		common.SendError(c, http.StatusBadRequest, "Host is required", common.CodeInvalidRequest, nil)
+		return
+	}
+
+	status, err := h.tenantSignupService.GetProvisioningStatus(host)
+	if err != nil {
+		sendTenantSignupError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Provisioning status fetched successfully", status)
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type TransferHandler struct {
+	transferService *services.TransferService
+	validate        *validator.Validate
+}
+
+func NewTransferHandler(transferService *services.TransferService) *TransferHandler {
+	return &TransferHandler{
+		transferService: transferService,
+		validate:        validator.New(),
+	}
+}
+
+// sendTransferError maps transfer service errors to API responses
+func sendTransferError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "transfer not found":
+		common.SendError(c, http.StatusNotFound, "Transfer not found", common.CodeNotFound, nil)
+	case "store not found":
+		common.SendError(c, http.StatusBadRequest, "Store not found", common.CodeValidationError, nil)
+	case "store inactive":
+		common.SendError(c, http.StatusBadRequest, "Stock can't be transferred from or to an inactive store", common.CodeValidationError, nil)
+	case "duplicate transfer line":
+		common.SendError(c, http.StatusBadRequest, "A product or variant is listed once per transfer", common.CodeValidationError, nil)
+	case "invalid status transition":
+		common.SendError(c, http.StatusConflict, err.Error(), common.CodeConflict, nil)
+	default:
+		sendInventoryError(c, err)
+	}
+}
+
+// GetAllTransfers handles GET /api/transfers
+func (h *TransferHandler) GetAllTransfers(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.transferService.GetAllTransfers(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch transfers", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Transfers fetched successfully", response)
+}
+
+// GetInTransit handles GET /api/transfers/in-transit
+func (h *TransferHandler) GetInTransit(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.transferService.GetInTransit(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch stock in transit", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Stock in transit fetched successfully", response)
+}
+
+// GetTransferById handles GET /api/transfers/:id
+func (h *TransferHandler) GetTransferById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	transfer, err := h.transferService.GetTransferById(id)
+	if err != nil {
+		sendTransferError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Transfer fetched successfully", transfer)
+}
+
+// CreateTransfer handles POST /api/transfers
+func (h *TransferHandler) CreateTransfer(c *gin.Context) {
+	var req models.CreateStockTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	transfer, err := h.transferService.CreateTransfer(&req, activityActor(c))
+	if err != nil {
+		sendTransferError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Transfer requested successfully", transfer)
+}
+
+// ApproveTransfer handles PUT /api/transfers/:id/approve
+func (h *TransferHandler) ApproveTransfer(c *gin.Context) {
+	h.transition(c, "Transfer approved successfully", h.transferService.ApproveTransfer)
+}
+
+// SendTransfer handles PUT /api/transfers/:id/send
+func (h *TransferHandler) SendTransfer(c *gin.Context) {
+	h.transition(c, "Transfer sent successfully", h.transferService.SendTransfer)
+}
+
+// ReceiveTransfer handles PUT /api/transfers/:id/receive
+func (h *TransferHandler) ReceiveTransfer(c *gin.Context) {
+	h.transition(c, "Transfer received successfully", h.transferService.ReceiveTransfer)
+}
+
+// CancelTransfer handles PUT /api/transfers/:id/cancel
+func (h *TransferHandler) CancelTransfer(c *gin.Context) {
+	h.transition(c, "Transfer cancelled successfully", h.transferService.CancelTransfer)
+}
+
+// transition moves the transfer in the :id parameter along with one of the service's steps
+func (h *TransferHandler) transition(c *gin.Context, message string, step func(id uint, actor models.ActivityActor) (*models.StockTransfers, error)) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	transfer, err := step(id, activityActor(c))
+	if err != nil {
+		sendTransferError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, message, transfer)
+}
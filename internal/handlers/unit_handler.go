@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type UnitHandler struct {
+	unitService *services.UnitService
+	validate    *validator.Validate
+}
+
+func NewUnitHandler(unitService *services.UnitService) *UnitHandler {
+	return &UnitHandler{
+		unitService: unitService,
+		validate:    validator.New(),
+	}
+}
+
+// sendUnitError maps unit service errors to API responses
+func sendUnitError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "unit not found":
+		common.SendError(c, http.StatusNotFound, "Unit of measure not found", common.CodeNotFound, nil)
+	case "product not found":
+		common.SendError(c, http.StatusNotFound, "Product not found", common.CodeNotFound, nil)
+	case "unit already exists":
+		common.SendError(c, http.StatusConflict, "Unit of measure already exists", common.CodeConflict, nil)
+	case "unit in use":
+		common.SendError(c, http.StatusConflict, "Unit of measure is used by products or the stock ledger", common.CodeConflict, nil)
+	case "unit is the stock unit":
+		common.SendError(c, http.StatusBadRequest, "The product is stocked in this unit; it can't also be a packaging", common.CodeValidationError, nil)
+	case "duplicate unit":
+		common.SendError(c, http.StatusBadRequest, "Each unit may only be listed once", common.CodeValidationError, nil)
+	default:
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+	}
+}
+
+// GetAllUnits handles GET /api/units
+func (h *UnitHandler) GetAllUnits(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.unitService.GetAllUnits(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch units of measure", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Units of measure fetched successfully", response)
+}
+
+// GetUnitById handles GET /api/units/:id
+func (h *UnitHandler) GetUnitById(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	unit, err := h.unitService.GetUnitById(id)
+	if err != nil {
+		sendUnitError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Unit of measure fetched successfully", unit)
+}
+
+// CreateUnit handles POST /api/units
+func (h *UnitHandler) CreateUnit(c *gin.Context) {
+	var req models.CreateUnitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	unit, err := h.unitService.CreateUnit(&req)
+	if err != nil {
+		sendUnitError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusCreated, "Unit of measure created successfully", unit)
+}
+
+// UpdateUnit handles PUT /api/units/:id
+func (h *UnitHandler) UpdateUnit(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateUnitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	unit, err := h.unitService.UpdateUnit(id, &req)
+	if err != nil {
+		sendUnitError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Unit of measure updated successfully", unit)
+}
+
+// DeleteUnit handles DELETE /api/units/:id
+func (h *UnitHandler) DeleteUnit(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	unit, err := h.unitService.DeleteUnit(id)
+	if err != nil {
+		sendUnitError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Unit of measure deleted successfully", unit)
+}
+
+// GetProductUnits handles GET /api/products/:id/units
+func (h *UnitHandler) GetProductUnits(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	units, err := h.unitService.GetProductUnits(id)
+	if err != nil {
+		sendUnitError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Product units fetched successfully", units)
+}
+
+// SetProductUnits handles PUT /api/products/:id/units
+func (h *UnitHandler) SetProductUnits(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.SetProductUnitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	units, err := h.unitService.SetProductUnits(id, &req)
+	if err != nil {
+		sendUnitError(c, err)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Product units updated successfully", units)
+}
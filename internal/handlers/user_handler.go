@@ -1,10 +1,19 @@
 package handlers
 
 import (
+	"fmt"
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Aebroyx/the-blade-api/internal/common"
 	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/export"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
 	"github.com/Aebroyx/the-blade-api/internal/pagination"
 	"github.com/Aebroyx/the-blade-api/internal/services"
 	"github.com/gin-gonic/gin"
@@ -27,7 +36,7 @@ func NewUserHandler(userService *services.UserService) *UserHandler {
 func (h *UserHandler) GetAllUsers(c *gin.Context) {
 	var params pagination.QueryParams
 	if err := params.Bind(c); err != nil {
-		common.SendError(c, http.StatusBadRequest, "Invalid query parameters", common.CodeInvalidRequest, err.Error())
+		sendBindError(c, "Invalid query parameters", err)
 		return
 	}
 
@@ -37,8 +46,14 @@ func (h *UserHandler) GetAllUsers(c *gin.Context) {
 		return
 	}
 
+	fields, err := services.ParseUserFields(c.Query("fields"))
+	if err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid fields parameter", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
 	// Get users with pagination, search, and filters
-	response, err := h.userService.GetAllUsers(params)
+	response, err := h.userService.GetAllUsers(params, fields)
 	if err != nil {
 		common.SendError(c, http.StatusInternalServerError, "Failed to fetch users", common.CodeInternalError, err.Error())
 		return
@@ -47,8 +62,169 @@ func (h *UserHandler) GetAllUsers(c *gin.Context) {
 	common.SendSuccess(c, http.StatusOK, "Users fetched successfully", response)
 }
 
+// GetOnlineUsers handles GET /api/users/online
+func (h *UserHandler) GetOnlineUsers(c *gin.Context) {
+	users, err := h.userService.GetOnlineUsers()
+	if err != nil {
+		if err.Error() == "presence unavailable" {
+			common.SendError(c, http.StatusServiceUnavailable, "Presence is unavailable while Redis is down", common.CodeInternalError, nil)
+			return
+		}
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch online users", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Online users fetched successfully", users)
+}
+
+// GetDeletedUsers handles GET /api/users/deleted
+func (h *UserHandler) GetDeletedUsers(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	// Validate query parameters
+	if err := h.validate.Struct(params); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	response, err := h.userService.GetDeletedUsers(params)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to fetch deleted users", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Deleted users fetched successfully", response)
+}
+
+// userExportColumn is a column a users export may include
+type userExportColumn struct {
+	name   string
+	header string
+	value  func(user *models.Users) string
+}
+
+// userExportColumns are the columns of a users export, in the order they are written when the
+// columns parameter doesn't pick some
+var userExportColumns = []userExportColumn{
+	{"id", "ID", func(u *models.Users) string { return strconv.FormatUint(uint64(u.ID), 10) }},
+	{"username", "Username", func(u *models.Users) string { return u.Username }},
+	{"email", "Email", func(u *models.Users) string { return u.Email }},
+	{"name", "Name", func(u *models.Users) string { return u.Name }},
+	{"role", "Role", func(u *models.Users) string { return u.Role }},
+	{"status", "Status", func(u *models.Users) string { return u.Status }},
+	{"created_at", "Created At", func(u *models.Users) string { return u.CreatedAt.Format(time.RFC3339) }},
+	{"updated_at", "Updated At", func(u *models.Users) string { return u.UpdatedAt.Format(time.RFC3339) }},
+}
+
+// parseUserExportColumns picks the export columns named by a comma-separated columns parameter,
+// in the order given, or every column when it is empty
+func parseUserExportColumns(raw string) ([]userExportColumn, error) {
+	if strings.TrimSpace(raw) == "" {
+		return userExportColumns, nil
+	}
+
+	var columns []userExportColumn
+	seen := map[string]bool{}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		i := slices.IndexFunc(userExportColumns, func(column userExportColumn) bool { return column.name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown column: %s", name)
+		}
+		seen[name] = true
+		columns = append(columns, userExportColumns[i])
+	}
+	return columns, nil
+}
+
+// ExportUsers handles GET /api/users/export. It takes the search, filters and sorting of the user
+// listing, and the columns to write, e.g. columns=username,email,role.
+func (h *UserHandler) ExportUsers(c *gin.Context) {
+	var params pagination.QueryParams
+	if err := params.Bind(c); err != nil {
+		sendBindError(c, "Invalid query parameters", err)
+		return
+	}
+
+	// Validate query parameters
+	if err := h.validate.Struct(params); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+	if err := h.userService.CheckExportQuery(params); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid query parameters", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	columns, err := parseUserExportColumns(c.Query("columns"))
+	if err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid columns parameter", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid export format", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, format.Filename("users")))
+	c.Status(http.StatusOK)
+
+	writer, err := export.NewWriter(format, c.Writer, "Users")
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to create users export writer", "error", err)
+		return
+	}
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.header
+	}
+	if err := writer.WriteRow(header); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to write users export header", "error", err)
+		return
+	}
+
+	err = h.userService.ExportUsers(params, func(user *models.Users) error {
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = column.value(user)
+		}
+		return writer.WriteRow(row)
+	})
+	if err != nil {
+		// Headers are already sent at this point, so the error can only be logged
+		slog.ErrorContext(c.Request.Context(), "Failed to export users", "error", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to finalize users export", "error", err)
+	}
+}
+
+// GetUserById handles GET /api/user/:id
 func (h *UserHandler) GetUserById(c *gin.Context) {
-	user, err := h.userService.GetUserById(c.Param("id"))
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	fields, err := services.ParseUserFields(c.Query("fields"))
+	if err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid fields parameter", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	user, err := h.userService.GetUserById(id, fields)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
@@ -77,8 +253,12 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
+	if !validateUserMetadata(c, req.Metadata) {
+		return
+	}
+
 	// Create user
-	user, err := h.userService.CreateUser(&req)
+	user, err := h.userService.CreateUser(&req, activityActor(c))
 	if err != nil {
 		switch err.Error() {
 		case "username already exists":
@@ -95,6 +275,11 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 }
 
 func (h *UserHandler) UpdateUser(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
 	var req models.UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
@@ -107,10 +292,19 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	if !validateUserMetadata(c, req.Metadata) {
+		return
+	}
+
 	// Update user
-	user, err := h.userService.UpdateUser(c.Param("id"), &req)
+	user, err := h.userService.UpdateUser(id, &req, activityActor(c))
 	if err != nil {
-		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		switch err.Error() {
+		case "email already exists":
+			common.SendError(c, http.StatusConflict, "Email already exists", common.CodeEmailExists, nil)
+		default:
+			common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		}
 		return
 	}
 
@@ -118,7 +312,12 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 }
 
 func (h *UserHandler) DeleteUser(c *gin.Context) {
-	user, err := h.userService.DeleteUser(c.Param("id"))
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.DeleteUser(id, activityActor(c))
 	if err != nil {
 		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
 		return
@@ -128,7 +327,12 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 }
 
 func (h *UserHandler) SoftDeleteUser(c *gin.Context) {
-	user, err := h.userService.SoftDeleteUser(c.Param("id"))
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.SoftDeleteUser(id, activityActor(c))
 	if err != nil {
 		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
 		return
@@ -136,3 +340,192 @@ func (h *UserHandler) SoftDeleteUser(c *gin.Context) {
 
 	common.SendSuccess(c, http.StatusOK, "User soft deleted successfully", user)
 }
+
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.RestoreUser(id, activityActor(c))
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			common.SendError(c, http.StatusNotFound, "User not found", common.CodeNotFound, nil)
+		case "user is not deleted":
+			common.SendError(c, http.StatusConflict, "User is not deleted", common.CodeConflict, nil)
+		case "user has been purged":
+			common.SendError(c, http.StatusGone, "User has been purged", common.CodeConflict, nil)
+		case "username already exists":
+			common.SendError(c, http.StatusConflict, "Username already exists", common.CodeUsernameExists, nil)
+		case "email already exists":
+			common.SendError(c, http.StatusConflict, "Email already exists", common.CodeEmailExists, nil)
+		default:
+			common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		}
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "User restored successfully", user)
+}
+
+// changeUserStatus binds the optional reason and moves the user to the given status
+func (h *UserHandler) changeUserStatus(c *gin.Context, status string, message string) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateUserStatusRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+			return
+		}
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	user, err := h.userService.ChangeUserStatus(id, status, req.Reason, activityActor(c))
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			common.SendError(c, http.StatusNotFound, "User not found", common.CodeNotFound, nil)
+		case "invalid status transition", "cannot suspend yourself":
+			common.SendError(c, http.StatusConflict, err.Error(), common.CodeConflict, nil)
+		default:
+			common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		}
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, message, user)
+}
+
+// SuspendUser handles PUT /api/user/:id/suspend
+func (h *UserHandler) SuspendUser(c *gin.Context) {
+	h.changeUserStatus(c, models.UserStatusSuspended, "User suspended successfully")
+}
+
+// ReactivateUser handles PUT /api/user/:id/reactivate. It also activates pending users.
+func (h *UserHandler) ReactivateUser(c *gin.Context) {
+	h.changeUserStatus(c, models.UserStatusActive, "User activated successfully")
+}
+
+// BulkUpdateUsers handles POST /api/users/bulk
+func (h *UserHandler) BulkUpdateUsers(c *gin.Context) {
+	var req models.BulkUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	if err := limits.Check(limits.MaxBulkIDs, len(req.IDs)); err != nil {
+		sendBindError(c, "Invalid request body", err)
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	response, err := h.userService.BulkUpdateUsers(&req, activityActor(c))
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Failed to apply bulk action", common.CodeInternalError, err.Error())
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Bulk action applied successfully", response)
+}
+
+// MergeUsers handles POST /api/users/merge
+func (h *UserHandler) MergeUsers(c *gin.Context) {
+	var req models.MergeUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	response, err := h.userService.MergeUsers(&req, activityActor(c))
+	if err != nil {
+		switch err.Error() {
+		case "primary user not found", "duplicate user not found":
+			common.SendError(c, http.StatusNotFound, "User not found", common.CodeNotFound, err.Error())
+		case "cannot merge a user into itself", "cannot merge away your own account":
+			common.SendError(c, http.StatusBadRequest, err.Error(), common.CodeValidationError, nil)
+		case "primary user is deleted", "duplicate user is already merged":
+			common.SendError(c, http.StatusConflict, err.Error(), common.CodeConflict, nil)
+		case "user has been purged":
+			common.SendError(c, http.StatusGone, "User has been purged", common.CodeConflict, nil)
+		default:
+			common.SendError(c, http.StatusInternalServerError, "Failed to merge users", common.CodeInternalError, nil)
+		}
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Users merged successfully", response)
+}
+
+// ResetUserPassword handles POST /api/user/:id/reset-password
+func (h *UserHandler) ResetUserPassword(c *gin.Context) {
+	id, ok := binding.ID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.AdminResetPasswordRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+			return
+		}
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, err.Error())
+		return
+	}
+
+	response, err := h.userService.AdminResetPassword(id, req.Method, activityActor(c))
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			common.SendError(c, http.StatusNotFound, "User not found", common.CodeNotFound, nil)
+		case "invalid password reset method":
+			common.SendError(c, http.StatusBadRequest, "Invalid password reset method", common.CodeValidationError, nil)
+		default:
+			common.SendError(c, http.StatusInternalServerError, "Failed to reset password", common.CodeInternalError, nil)
+		}
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Password reset successfully", response)
+}
+
+// validateUserMetadata enforces the metadata key limit and per-key rules, responding on failure
+func validateUserMetadata(c *gin.Context, metadata models.JSONMap) bool {
+	if err := limits.Check(limits.MaxMetadataKeys, len(metadata)); err != nil {
+		sendBindError(c, "Invalid metadata", err)
+		return false
+	}
+
+	if problems := services.ValidateUserMetadata(metadata); len(problems) > 0 {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, problems)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type UserPurgeHandler struct {
+	userPurgeService *services.UserPurgeService
+}
+
+func NewUserPurgeHandler(userPurgeService *services.UserPurgeService) *UserPurgeHandler {
+	return &UserPurgeHandler{
+		userPurgeService: userPurgeService,
+	}
+}
+
+// PurgeUsers handles POST /api/users/purge.
+// Pass dry_run=true to only report which users would be purged.
+func (h *UserPurgeHandler) PurgeUsers(c *gin.Context) {
+	dryRun, ok := binding.Bool(c, "dry_run", false)
+	if !ok {
+		return
+	}
+
+	report, err := h.userPurgeService.PurgeUsers(dryRun)
+	if err != nil {
+		if err.Error() == "purge already running" {
+			common.SendError(c, http.StatusConflict, "A purge is already running", common.CodeConflict, nil)
+			return
+		}
+		common.SendError(c, http.StatusInternalServerError, "Failed to purge users", common.CodeInternalError, err.Error())
+		return
+	}
+
+	message := "Users purged successfully"
+	if dryRun {
+		message = "Purge report generated successfully"
+	}
+	common.SendSuccess(c, http.StatusOK, message, report)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type UserSettingsHandler struct {
+	userSettingsService *services.UserSettingsService
+}
+
+func NewUserSettingsHandler(userSettingsService *services.UserSettingsService) *UserSettingsHandler {
+	return &UserSettingsHandler{
+		userSettingsService: userSettingsService,
+	}
+}
+
+// GetMySettings handles GET /api/me/settings
+func (h *UserSettingsHandler) GetMySettings(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		common.SendError(c, http.StatusUnauthorized, "Unauthorized", common.CodeUnauthorized, nil)
+		return
+	}
+
+	settings, err := h.userSettingsService.GetUserSettings(user.ID)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Settings fetched successfully", settings)
+}
+
+// UpdateMySettings handles PUT /api/me/settings
+func (h *UserSettingsHandler) UpdateMySettings(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		common.SendError(c, http.StatusUnauthorized, "Unauthorized", common.CodeUnauthorized, nil)
+		return
+	}
+
+	var req models.JSONMap
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate known settings keys
+	if problems := services.ValidateUserSettings(req); len(problems) > 0 {
+		common.SendError(c, http.StatusBadRequest, "Validation failed", common.CodeValidationError, problems)
+		return
+	}
+
+	settings, err := h.userSettingsService.UpdateUserSettings(user.ID, req)
+	if err != nil {
+		common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+		return
+	}
+
+	common.SendSuccess(c, http.StatusOK, "Settings updated successfully", settings)
+}
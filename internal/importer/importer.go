@@ -0,0 +1,190 @@
+// Package importer reads records exported by other systems. Files are CSV or an XLSX workbook
+// with a header row, or a JSON array of flat objects; a mapping renames their columns to the fields an import expects,
+// so exports from another POS can be loaded without editing them first.
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format represents a supported import file format
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+	FormatXLSX Format = "xlsx"
+)
+
+// ParseFormat converts a form value into a Format, falling back to the file extension
+func ParseFormat(value string, filename string) (Format, error) {
+	if value == "" {
+		value = strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+	}
+	switch Format(value) {
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	default:
+		return "", fmt.Errorf("unsupported import format: %s", value)
+	}
+}
+
+// Record is one row of an import, keyed by field name
+type Record map[string]string
+
+// Read reads the records of a file. mapping maps field names to the file's column names;
+// columns that are not mapped keep their own name.
+func Read(format Format, data []byte, mapping map[string]string) ([]Record, error) {
+	var rows []map[string]string
+	var err error
+	switch format {
+	case FormatJSON:
+		rows, err = readJSON(data)
+	case FormatXLSX:
+		rows, err = readXLSX(data)
+	default:
+		rows, err = readCSV(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Invert the mapping to look fields up by column
+	fields := make(map[string]string, len(mapping))
+	for field, column := range mapping {
+		fields[column] = field
+	}
+
+	records := make([]Record, len(rows))
+	for i, row := range rows {
+		record := make(Record, len(row))
+		for column, value := range row {
+			if field, ok := fields[column]; ok {
+				column = field
+			}
+			record[column] = strings.TrimSpace(value)
+		}
+		records[i] = record
+	}
+	return records, nil
+}
+
+// readCSV reads a CSV file with a header row
+func readCSV(data []byte) ([]map[string]string, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("file is empty")
+		}
+		return nil, err
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	var rows []map[string]string
+	for {
+		values, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			row[column] = values[i]
+		}
+		rows = append(rows, row)
+	}
+}
+
+// readJSON reads a JSON array of flat objects. Numbers and booleans are kept as written.
+func readJSON(data []byte) ([]map[string]string, error) {
+	var objects []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &objects); err != nil {
+		return nil, fmt.Errorf("file must be a JSON array of objects: %v", err)
+	}
+
+	rows := make([]map[string]string, len(objects))
+	for i, object := range objects {
+		row := make(map[string]string, len(object))
+		for key, raw := range object {
+			var text string
+			if err := json.Unmarshal(raw, &text); err == nil {
+				row[key] = text
+				continue
+			}
+			if string(raw) == "null" {
+				row[key] = ""
+				continue
+			}
+			if len(raw) > 0 && (raw[0] == '{' || raw[0] == '[') {
+				return nil, fmt.Errorf("object %d: %s must not be nested", i+1, key)
+			}
+			row[key] = string(raw)
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// Amount parses an amount in minor currency units. Amounts written with a decimal point, e.g.
+// 12.50, are converted from major units with up to two decimals.
+func Amount(value string) (int64, error) {
+	whole, fraction, decimal := strings.Cut(value, ".")
+	if !decimal {
+		return strconv.ParseInt(value, 10, 64)
+	}
+	if len(fraction) > 2 {
+		return 0, fmt.Errorf("%q has more than two decimals", value)
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+
+	negative := strings.HasPrefix(whole, "-")
+	units, err := strconv.ParseInt(strings.TrimPrefix(whole, "-"), 10, 64)
+	if err != nil && whole != "" && whole != "-" {
+		return 0, fmt.Errorf("%q is not an amount", value)
+	}
+	cents, err := strconv.ParseInt(fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an amount", value)
+	}
+	amount := units*100 + cents
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// timeLayouts are the layouts Time accepts, most specific first
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Time parses a timestamp written as RFC 3339, or as a date with an optional time in UTC
+func Time(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date or time", value)
+}
@@ -0,0 +1,209 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// xlsxWorkbookPart lists the sheets of a workbook by relationship ID
+type xlsxWorkbookPart struct {
+	Sheets []struct {
+		RelationshipID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxRelationshipsPart maps relationship IDs to the parts they point at
+type xlsxRelationshipsPart struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is text that is either plain or split into formatted runs
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+// xlsxSharedStringsPart holds the strings cells refer to by index
+type xlsxSharedStringsPart struct {
+	Items []xlsxText `xml:"si"`
+}
+
+// xlsxSheetPart holds the rows of a worksheet
+type xlsxSheetPart struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSX reads the first worksheet of an XLSX workbook, whose first row is the header.
+// Cells are read as they are stored: numbers keep their digits and dates their serial number.
+func readXLSX(data []byte) ([]map[string]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.New("file is not an XLSX workbook")
+	}
+	parts := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		parts[file.Name] = file
+	}
+
+	sheetName, err := xlsxFirstSheet(parts)
+	if err != nil {
+		return nil, err
+	}
+	var shared xlsxSharedStringsPart
+	if file, ok := parts["xl/sharedStrings.xml"]; ok {
+		if err := decodeXLSXPart(file, &shared); err != nil {
+			return nil, err
+		}
+	}
+	file, ok := parts[sheetName]
+	if !ok {
+		return nil, errors.New("workbook has no worksheet")
+	}
+	var sheet xlsxSheetPart
+	if err := decodeXLSXPart(file, &sheet); err != nil {
+		return nil, err
+	}
+
+	var grid [][]string
+	for _, row := range sheet.Rows {
+		var values []string
+		for i, cell := range row.Cells {
+			column := i
+			if cell.Ref != "" {
+				column = xlsxColumn(cell.Ref)
+			}
+			value := cell.Value
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err != nil || index < 0 || index >= len(shared.Items) {
+					return nil, fmt.Errorf("cell %s refers to a missing shared string", cell.Ref)
+				}
+				value = shared.Items[index].String()
+			case "inlineStr":
+				value = cell.Inline.String()
+			case "b":
+				value = strconv.FormatBool(cell.Value == "1")
+			}
+			for len(values) <= column {
+				values = append(values, "")
+			}
+			values[column] = value
+		}
+		grid = append(grid, values)
+	}
+	if len(grid) == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	header := grid[0]
+	var rows []map[string]string
+	for _, values := range grid[1:] {
+		row := make(map[string]string, len(header))
+		blank := true
+		for i, column := range header {
+			column = strings.TrimSpace(column)
+			if column == "" {
+				continue
+			}
+			value := ""
+			if i < len(values) {
+				value = values[i]
+			}
+			if strings.TrimSpace(value) != "" {
+				blank = false
+			}
+			row[column] = value
+		}
+		// Spreadsheets often carry formatted but empty rows below the data
+		if !blank {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// xlsxFirstSheet returns the name of the part holding the workbook's first worksheet
+func xlsxFirstSheet(parts map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	workbookFile, ok := parts["xl/workbook.xml"]
+	if !ok {
+		return "", errors.New("file is not an XLSX workbook")
+	}
+	var workbook xlsxWorkbookPart
+	if err := decodeXLSXPart(workbookFile, &workbook); err != nil {
+		return "", err
+	}
+	relsFile, ok := parts["xl/_rels/workbook.xml.rels"]
+	if !ok || len(workbook.Sheets) == 0 {
+		return fallback, nil
+	}
+	var rels xlsxRelationshipsPart
+	if err := decodeXLSXPart(relsFile, &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID == workbook.Sheets[0].RelationshipID {
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+			return path.Join("xl", rel.Target), nil
+		}
+	}
+	return fallback, nil
+}
+
+// decodeXLSXPart decodes an XML part of the workbook
+func decodeXLSXPart(file *zip.File, v any) error {
+	reader, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("file is not an XLSX workbook: %v", err)
+	}
+	defer reader.Close()
+	if err := xml.NewDecoder(reader).Decode(v); err != nil {
+		return fmt.Errorf("file is not an XLSX workbook: %v", err)
+	}
+	return nil
+}
+
+// xlsxColumn converts the column letters of a cell reference, e.g. AB12, to a zero-based index
+func xlsxColumn(ref string) int {
+	column := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		column = column*26 + int(r-'A'+1)
+	}
+	return column - 1
+}
@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Every runs fn once per interval until the context is cancelled.
+// The first run happens one interval after start, and errors are logged rather than stopping the job.
+func Every(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	if interval <= 0 {
+		slog.WarnContext(ctx, "Job not started, its interval must be positive", "job", name)
+		return
+	}
+
+	slog.InfoContext(ctx, "Job scheduled", "job", name, "interval", interval.String())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			started := time.Now()
+			if err := fn(ctx); err != nil {
+				slog.ErrorContext(ctx, "Job failed", "job", name, "duration_ms", time.Since(started).Milliseconds(), "error", err)
+				continue
+			}
+			slog.DebugContext(ctx, "Job finished", "job", name, "duration_ms", time.Since(started).Milliseconds())
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package license
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Features a license can unlock
+const (
+	FeatureMultiStore    = "multi_store"
+	FeatureEcommerceSync = "ecommerce_sync"
+)
+
+// Status describes the state of the installed license
+type Status string
+
+const (
+	StatusValid   Status = "valid"   // Within its validity period
+	StatusGrace   Status = "grace"   // Expired, but features stay unlocked until the grace period ends
+	StatusExpired Status = "expired" // Past the grace period; licensed features are locked
+	StatusMissing Status = "missing" // No license file configured or found
+	StatusInvalid Status = "invalid" // The file could not be read or its signature does not verify
+)
+
+// PublicKey is the base64 Ed25519 key license files are verified with. It is set at build time:
+//
+//	go build -ldflags "-X github.com/Aebroyx/the-blade-api/internal/license.PublicKey=<key>"
+var PublicKey string
+
+// File is the license file as shipped to a customer: a base64 JSON payload and its signature
+type File struct {
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+// Payload describes what a license grants
+type Payload struct {
+	LicenseID string    `json:"license_id"`
+	Customer  string    `json:"customer"`
+	Features  []string  `json:"features"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	GraceDays int       `json:"grace_days"`
+}
+
+// Entitlements reports the installed license and the features it currently unlocks
+type Entitlements struct {
+	Status            Status     `json:"status"`
+	LicenseID         string     `json:"license_id,omitempty"`
+	Customer          string     `json:"customer,omitempty"`
+	Features          []string   `json:"features"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	GraceEndsAt       *time.Time `json:"grace_ends_at,omitempty"`
+	Error             string     `json:"error,omitempty"`
+	LastLoadedAt      time.Time  `json:"last_loaded_at"`
+	AvailableFeatures []string   `json:"available_features"`
+}
+
+// allFeatures lists every feature a license can unlock, reported alongside the entitlements
+var allFeatures = []string{FeatureMultiStore, FeatureEcommerceSync}
+
+// Manager holds the license loaded from disk. Expiry is evaluated on every check, so a
+// license lapses on time even if the file is not reloaded.
+type Manager struct {
+	path      string
+	publicKey ed25519.PublicKey
+
+	mu       sync.RWMutex
+	payload  *Payload
+	err      error
+	loadedAt time.Time
+}
+
+// NewManager creates a manager for the license file at path and loads it
+func NewManager(path string) *Manager {
+	m := &Manager{path: path}
+
+	if PublicKey != "" {
+		key, err := base64.StdEncoding.DecodeString(PublicKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			slog.Error("License: the built-in public key is invalid, licenses cannot be verified")
+		} else {
+			m.publicKey = ed25519.PublicKey(key)
+		}
+	}
+
+	m.Reload()
+	return m
+}
+
+// Reload reads and verifies the license file again, e.g. after it was replaced
+func (m *Manager) Reload() {
+	payload, err := m.load()
+
+	m.mu.Lock()
+	m.payload = payload
+	m.err = err
+	m.loadedAt = time.Now()
+	m.mu.Unlock()
+
+	switch {
+	case errors.Is(err, os.ErrNotExist) || m.path == "":
+		slog.Info("License: no license installed, licensed features are locked")
+	case err != nil:
+		slog.Error("License: failed to load license", "path", m.path, "error", err)
+	case time.Now().After(payload.ExpiresAt):
+		slog.Warn("License: expired, licensed features lock after the grace period",
+			"license_id", payload.LicenseID, "expired_at", payload.ExpiresAt.Format(time.RFC3339), "grace_days", payload.GraceDays)
+	default:
+		slog.Info("License: loaded", "license_id", payload.LicenseID, "customer", payload.Customer, "expires_at", payload.ExpiresAt.Format(time.RFC3339))
+	}
+}
+
+// Refresh reloads the license; it matches the signature expected by jobs.Every
+func (m *Manager) Refresh(ctx context.Context) error {
+	m.Reload()
+	return nil
+}
+
+// load reads the license file and verifies its signature
+func (m *Manager) load() (*Payload, error) {
+	if m.path == "" {
+		return nil, os.ErrNotExist
+	}
+
+	data, err := os.ReadFile(m.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("malformed license file: %w", err)
+	}
+
+	payloadBytes, err := base64.StdEncoding.DecodeString(file.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("malformed license payload: %w", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(file.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("malformed license signature: %w", err)
+	}
+
+	if m.publicKey == nil {
+		return nil, errors.New("this build has no license public key")
+	}
+	if !ed25519.Verify(m.publicKey, payloadBytes, signature) {
+		return nil, errors.New("license signature does not verify")
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(payloadBytes, &payload); err != nil {
+		return nil, fmt.Errorf("malformed license payload: %w", err)
+	}
+	return &payload, nil
+}
+
+// status evaluates the license at the given time. Callers hold the read lock.
+func (m *Manager) status(now time.Time) Status {
+	switch {
+	case m.payload == nil && (m.err == nil || errors.Is(m.err, os.ErrNotExist)):
+		return StatusMissing
+	case m.payload == nil:
+		return StatusInvalid
+	case now.Before(m.payload.ExpiresAt):
+		return StatusValid
+	case now.Before(m.graceEndsAt()):
+		return StatusGrace
+	default:
+		return StatusExpired
+	}
+}
+
+// graceEndsAt is when features lock after expiry. Callers hold the read lock.
+func (m *Manager) graceEndsAt() time.Time {
+	return m.payload.ExpiresAt.AddDate(0, 0, m.payload.GraceDays)
+}
+
+// Has reports whether the license currently unlocks the feature
+func (m *Manager) Has(feature string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	switch m.status(time.Now()) {
+	case StatusValid, StatusGrace:
+	default:
+		return false
+	}
+	for _, f := range m.payload.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// Entitlements reports the license state and the features it currently unlocks
+func (m *Manager) Entitlements() Entitlements {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	status := m.status(time.Now())
+	entitlements := Entitlements{
+		Status:            status,
+		Features:          []string{},
+		LastLoadedAt:      m.loadedAt,
+		AvailableFeatures: allFeatures,
+	}
+	if status == StatusInvalid {
+		entitlements.Error = m.err.Error()
+	}
+	if m.payload == nil {
+		return entitlements
+	}
+
+	expiresAt := m.payload.ExpiresAt
+	graceEndsAt := m.graceEndsAt()
+	entitlements.LicenseID = m.payload.LicenseID
+	entitlements.Customer = m.payload.Customer
+	entitlements.ExpiresAt = &expiresAt
+	entitlements.GraceEndsAt = &graceEndsAt
+	if status == StatusValid || status == StatusGrace {
+		entitlements.Features = append(entitlements.Features, m.payload.Features...)
+	}
+	return entitlements
+}
@@ -0,0 +1,131 @@
+package limits
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Names of the limits in the registry
+const (
+	MaxRequestBodyBytes  = "max_request_body_bytes"
+	MaxPageSize          = "max_page_size"
+	MaxFiltersPerQuery   = "max_filters_per_query"
+	MaxBulkIDs           = "max_bulk_ids"
+	MaxOrderItems        = "max_order_items"
+	MaxMetadataKeys      = "max_metadata_keys"
+	MaxProductVariants   = "max_product_variants"
+	MaxImportBytes       = "max_import_bytes"
+	MaxImportRows        = "max_import_rows"
+	MaxImageUploadBytes  = "max_image_upload_bytes"
+	MaxProductImages     = "max_product_images"
+	MaxGraphQLComplexity = "max_graphql_complexity"
+)
+
+// defaults are used for every limit a deployment does not override
+var defaults = map[string]int{
+	MaxRequestBodyBytes: 1 << 20, // 1 MB
+	MaxPageSize:         100,
+	MaxFiltersPerQuery:  20,
+	MaxBulkIDs:          500,
+	MaxOrderItems:       200,
+	MaxMetadataKeys:     50,
+	MaxProductVariants:  100,
+	MaxImportBytes:      20 << 20, // 20 MB
+	MaxImportRows:       50000,
+	MaxImageUploadBytes: 25 << 20, // 25 MB
+	MaxProductImages:    20,
+	// Fields selected by one GraphQL query, nested fields included
+	MaxGraphQLComplexity: 500,
+}
+
+var (
+	mu     sync.RWMutex
+	values = copyDefaults()
+)
+
+// ExceededError reports which limit was exceeded and by how much
+type ExceededError struct {
+	Limit  string `json:"limit"`
+	Max    int    `json:"max"`
+	Actual int    `json:"actual"`
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("limit exceeded: %s is %d, got %d", e.Limit, e.Max, e.Actual)
+}
+
+// copyDefaults returns a fresh copy of the default limits
+func copyDefaults() map[string]int {
+	result := make(map[string]int, len(defaults))
+	for name, value := range defaults {
+		result[name] = value
+	}
+	return result
+}
+
+// Names lists every limit in the registry
+func Names() []string {
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Configure applies deployment overrides on top of the defaults
+func Configure(overrides map[string]int) error {
+	next := copyDefaults()
+	for name, value := range overrides {
+		if _, ok := defaults[name]; !ok {
+			return fmt.Errorf("unknown limit %q", name)
+		}
+		if value < 1 {
+			return fmt.Errorf("limit %q must be at least 1", name)
+		}
+		next[name] = value
+	}
+
+	mu.Lock()
+	values = next
+	mu.Unlock()
+	return nil
+}
+
+// Get returns the current value of a limit
+func Get(name string) int {
+	mu.RLock()
+	defer mu.RUnlock()
+	return values[name]
+}
+
+// All returns the current value of every limit
+func All() map[string]int {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	result := make(map[string]int, len(values))
+	for name, value := range values {
+		result[name] = value
+	}
+	return result
+}
+
+// Check returns an *ExceededError when actual is above the limit
+func Check(name string, actual int) error {
+	if max := Get(name); actual > max {
+		return &ExceededError{Limit: name, Max: max, Actual: actual}
+	}
+	return nil
+}
+
+// AsExceeded reports whether err is, or wraps, an *ExceededError
+func AsExceeded(err error) (*ExceededError, bool) {
+	var exceeded *ExceededError
+	if errors.As(err, &exceeded) {
+		return exceeded, true
+	}
+	return nil, false
+}
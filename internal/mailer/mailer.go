@@ -0,0 +1,139 @@
+package mailer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+
+	"github.com/Aebroyx/the-blade-api/internal/config"
+)
+
+// Message is a plain text email, optionally with files attached
+type Message struct {
+	To          string
+	Subject     string
+	Body        string
+	Attachments []Attachment
+}
+
+// Attachment is a file attached to an email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Mailer sends emails
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// New returns an SMTP mailer when SMTP is configured, otherwise a mailer that only logs
+func New(cfg *config.Config) Mailer {
+	if cfg.SMTPHost == "" {
+		slog.Warn("SMTP_HOST not set, emails will be logged instead of sent")
+		return &LogMailer{}
+	}
+	return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+}
+
+// SMTPMailer sends emails through an SMTP server
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPMailer{
+		addr: fmt.Sprintf("%s:%s", host, port),
+		auth: auth,
+		from: from,
+	}
+}
+
+// Send delivers the message
+func (m *SMTPMailer) Send(msg Message) error {
+	if strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return fmt.Errorf("invalid email header")
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\n", m.from)
+	fmt.Fprintf(&body, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&body, "Subject: %s\r\n", msg.Subject)
+	body.WriteString("MIME-Version: 1.0\r\n")
+	text := strings.ReplaceAll(msg.Body, "\n", "\r\n")
+	if len(msg.Attachments) == 0 {
+		body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+		body.WriteString("\r\n")
+		body.WriteString(text)
+	} else if err := writeMultipart(&body, text, msg.Attachments); err != nil {
+		return err
+	}
+
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, []byte(body.String()))
+}
+
+// writeMultipart writes the text and the attachments as a multipart/mixed body, attachments
+// base64 encoded
+func writeMultipart(w io.Writer, text string, attachments []Attachment) error {
+	parts := multipart.NewWriter(w)
+	fmt.Fprintf(w, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", parts.Boundary())
+
+	part, err := parts.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=UTF-8"}})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(part, text); err != nil {
+		return err
+	}
+
+	for _, attachment := range attachments {
+		if strings.ContainsAny(attachment.Filename, "\r\n\"") {
+			return fmt.Errorf("invalid attachment filename")
+		}
+		part, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {fmt.Sprintf(`attachment; filename="%s"`, attachment.Filename)},
+		})
+		if err != nil {
+			return err
+		}
+		// Base64 lines may be at most 76 characters long
+		encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+		for len(encoded) > 76 {
+			if _, err := io.WriteString(part, encoded[:76]+"\r\n"); err != nil {
+				return err
+			}
+			encoded = encoded[76:]
+		}
+		if _, err := io.WriteString(part, encoded); err != nil {
+			return err
+		}
+	}
+	return parts.Close()
+}
+
+// LogMailer logs emails instead of sending them, for development
+type LogMailer struct{}
+
+// Send logs the message
+func (m *LogMailer) Send(msg Message) error {
+	attachments := make([]string, len(msg.Attachments))
+	for i, attachment := range msg.Attachments {
+		attachments[i] = fmt.Sprintf("%s (%d bytes)", attachment.Filename, len(attachment.Data))
+	}
+	slog.Info("Email", "to", msg.To, "subject", msg.Subject, "body", msg.Body, "attachments", attachments)
+	return nil
+}
@@ -0,0 +1,20 @@
+// Package metrics registers the Prometheus metrics of the process. Series are kept per set of
+// label values for the lifetime of the process, so labels must come from bounded sets such as
+// route templates and status codes, never from IDs.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// NewRegistry returns a registry holding the Go runtime and process metrics; the API's own
+// metrics are registered on it by their owners
+func NewRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/Aebroyx/the-blade-api/internal/apiversion"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/gin-gonic/gin"
+)
+
+// APIVersion reports the API version serving the request. Requests sent to the deprecated
+// unversioned alias are told so, with a link to the versioned path to move to.
+func APIVersion(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header(apiversion.Header, version)
+		if _, ok := apiversion.AliasOf(c.Request.Context()); ok {
+			c.Writer.Header().Add("Vary", apiversion.Header)
+			c.Header("Deprecation", "true")
+			c.Header("Link", "<"+c.Request.URL.Path+`>; rel="successor-version"`)
+		}
+		c.Next()
+	}
+}
+
+// NoRoute answers requests that matched no route: 400 UNSUPPORTED_API_VERSION when they ask
+// for an API version that isn't served, 404 NOT_FOUND otherwise
+func NoRoute(versions []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		requested := apiversion.Of(path)
+		if requested == "" && apiversion.IsAPIPath(path) {
+			// Negotiate leaves unversioned paths alone when the version asked for isn't one
+			requested = c.GetHeader(apiversion.Header)
+		}
+		if requested != "" && !slices.Contains(versions, requested) {
+			common.SendError(c, http.StatusBadRequest, "Unsupported API version", common.CodeUnsupportedAPIVersion, gin.H{
+				"requested": requested,
+				"versions":  versions,
+			})
+			return
+		}
+
+		common.SendError(c, http.StatusNotFound, "Route not found", common.CodeNotFound, nil)
+	}
+}
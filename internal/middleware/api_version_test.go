@@ -0,0 +1,132 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Aebroyx/the-blade-api/internal/apiversion"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/testutil"
+	"github.com/gin-gonic/gin"
+)
+
+// versionedAPI serves GET /hello and the protected GET /me under v1 and v2, each answering
+// with the version that served it
+func versionedAPI(env *testutil.Env) http.Handler {
+	registrar := func(version string) apiversion.Registrar {
+		answer := func(c *gin.Context) {
+			common.SendSuccess(c, http.StatusOK, "OK", version)
+		}
+		return apiversion.NewRegistrar(version, func(public *gin.RouterGroup, protected *gin.RouterGroup) {
+			public.GET("/hello", answer)
+			protected.GET("/me", answer)
+		})
+	}
+	return env.API(registrar("v1"), registrar("v2"))
+}
+
+// get sends a GET asking for a version in the X-API-Version header, when one is given
+func get(t *testing.T, env *testutil.Env, api http.Handler, path string, version string, user *models.Users) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if version != "" {
+		req.Header.Set(apiversion.Header, version)
+	}
+	if user != nil {
+		req.AddCookie(&http.Cookie{Name: "access_token", Value: env.Token(t, *user)})
+	}
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPIVersionNegotiation(t *testing.T) {
+	env := testutil.New(t)
+	api := versionedAPI(env)
+	user := env.CreateUser(t, models.RoleUser)
+
+	tests := []struct {
+		name      string
+		path      string
+		version   string
+		user      *models.Users
+		served    string
+		successor string
+	}{
+		{name: "versioned path", path: "/api/v1/hello", served: "v1"},
+		{name: "versioned path ignores header", path: "/api/v2/hello", version: "v1", served: "v2"},
+		{name: "alias defaults to v1", path: "/api/hello", served: "v1", successor: "/api/v1/hello"},
+		{name: "alias with version", path: "/api/hello", version: "v2", served: "v2", successor: "/api/v2/hello"},
+		{name: "alias with version number", path: "/api/hello", version: "2", served: "v2", successor: "/api/v2/hello"},
+		{name: "protected alias", path: "/api/me", version: "v2", user: &user, served: "v2", successor: "/api/v2/me"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(t, env, api, tt.path, tt.version, tt.user)
+			var served string
+			testutil.Decode(t, rec, http.StatusOK, &served)
+			if served != tt.served {
+				t.Errorf("served by %s, want %s", served, tt.served)
+			}
+			if header := rec.Header().Get(apiversion.Header); header != tt.served {
+				t.Errorf("%s is %q, want %q", apiversion.Header, header, tt.served)
+			}
+
+			deprecation, link := rec.Header().Get("Deprecation"), rec.Header().Get("Link")
+			if tt.successor == "" {
+				if deprecation != "" || link != "" {
+					t.Errorf("versioned path is deprecated: Deprecation %q, Link %q", deprecation, link)
+				}
+				return
+			}
+			if deprecation != "true" {
+				t.Errorf("alias has Deprecation %q", deprecation)
+			}
+			if want := "<" + tt.successor + `>; rel="successor-version"`; link != want {
+				t.Errorf("alias has Link %q, want %q", link, want)
+			}
+		})
+	}
+}
+
+func TestAPIVersionNegotiationRejectsUnknownVersions(t *testing.T) {
+	env := testutil.New(t)
+	api := versionedAPI(env)
+
+	tests := []struct {
+		name    string
+		path    string
+		version string
+		status  int
+		code    string
+	}{
+		{name: "unknown versioned path", path: "/api/v3/hello", status: http.StatusBadRequest, code: common.CodeUnsupportedAPIVersion},
+		{name: "alias asking for unknown version", path: "/api/hello", version: "v3", status: http.StatusBadRequest, code: common.CodeUnsupportedAPIVersion},
+		{name: "alias asking for no version", path: "/api/hello", version: "latest", status: http.StatusBadRequest, code: common.CodeUnsupportedAPIVersion},
+		{name: "unknown route", path: "/api/v1/missing", status: http.StatusNotFound, code: common.CodeNotFound},
+		{name: "protected route without user", path: "/api/me", status: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(t, env, api, tt.path, tt.version, nil)
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if tt.code == "" {
+				return
+			}
+
+			var response common.ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.Code != tt.code {
+				t.Errorf("code is %s, want %s", response.Code, tt.code)
+			}
+		})
+	}
+}
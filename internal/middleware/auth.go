@@ -3,20 +3,80 @@ package middleware
 import (
 	"context"
 	"encoding/json"
-	"fmt"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/Aebroyx/the-blade-api/internal/apiversion"
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/common"
 	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/services"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
-// Auth middleware with Redis caching
-func Auth(jwtSecret string, db *gorm.DB, redisClient *redis.Client) gin.HandlerFunc {
+// rejectInactiveUser aborts the request when the account is suspended or still pending.
+// Returns true when the request was rejected.
+func rejectInactiveUser(c *gin.Context, user models.Users) bool {
+	switch user.Status {
+	case models.UserStatusSuspended:
+		common.SendError(c, http.StatusForbidden, "Account suspended", common.CodeAccountSuspended, nil)
+	case models.UserStatusPending:
+		common.SendError(c, http.StatusForbidden, "Account pending activation", common.CodeAccountPending, nil)
+	default:
+		return false
+	}
+	c.Abort()
+	return true
+}
+
+// passwordChangeRoutes stay reachable while a password change is required
+var passwordChangeRoutes = map[string]bool{
+	"/api/me":          true,
+	"/api/me/password": true,
+	"/api/auth/logout": true,
+}
+
+// requirePasswordChange aborts the request when the user has to change their password first.
+// Returns true when the request was rejected.
+func requirePasswordChange(c *gin.Context, user models.Users) bool {
+	if !user.MustChangePassword || passwordChangeRoutes[apiversion.Strip(c.FullPath())] {
+		return false
+	}
+	common.SendError(c, http.StatusForbidden, "Password change required", common.CodePasswordChangeRequired, nil)
+	c.Abort()
+	return true
+}
+
+// claimsVersion returns the user's current claims version, cached so that strict mode costs one
+// Redis read per request. While the cache is unavailable it is read from the database.
+func claimsVersion(ctx context.Context, db *gorm.DB, userCache *cache.Cache, userID uint) (int64, error) {
+	key := cache.ClaimsVersionKey(userID)
+	if data, err := userCache.Get(ctx, key); err == nil {
+		if version, err := strconv.ParseInt(string(data), 10, 64); err == nil {
+			return version, nil
+		}
+	}
+
+	var version int64
+	if err := db.Model(&models.Users{}).Where("id = ?", userID).Select("claims_version").Scan(&version).Error; err != nil {
+		return 0, err
+	}
+	if err := userCache.Set(ctx, key, []byte(strconv.FormatInt(version, 10)), time.Hour); err != nil && !errors.Is(err, cache.ErrUnavailable) {
+		slog.WarnContext(ctx, "Auth middleware: failed to cache claims version", "user_id", userID, "error", err)
+	}
+	return version, nil
+}
+
+// Auth middleware with Redis caching. Each authenticated request also refreshes the user's presence.
+// The role and status always come from the user record, never from the token. In strict claims
+// mode a cached user older than the user's claims version is reloaded, so role and status changes
+// apply on the next request; in performance mode the cached user is trusted until it expires.
+func Auth(jwtSecret string, claimsMode string, db *gorm.DB, userCache *cache.Cache, presence *services.PresenceService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get access token from cookie
 		accessToken, err := c.Cookie("access_token")
@@ -60,27 +120,47 @@ func Auth(jwtSecret string, db *gorm.DB, redisClient *redis.Client) gin.HandlerF
 		}
 
 		var user models.Users
-		userKey := fmt.Sprintf("user:%d", claims.UserID)
+		userKey := cache.UserKey(claims.UserID)
 
-		// Try to get user from Redis first
-		if redisClient != nil {
-			userData, err := redisClient.Get(context.Background(), userKey).Bytes()
-			if err == nil {
-				// Cache hit - unmarshal from Redis
-				if err := json.Unmarshal(userData, &user); err == nil {
-					log.Printf("Auth middleware: user found in Redis cache for ID %d", claims.UserID)
-					goto setUserContext
+		// Try to get user from the cache first; while Redis is degraded this goes straight to the database
+		if userData, err := userCache.Get(context.Background(), userKey); err == nil {
+			// Cache hit - unmarshal from Redis
+			if err := json.Unmarshal(userData, &user); err == nil {
+				slog.DebugContext(c.Request.Context(), "Auth middleware: user found in Redis cache", "user_id", claims.UserID)
+				if claimsMode == models.ClaimsModeStrict {
+					version, err := claimsVersion(context.Background(), db, userCache, claims.UserID)
+					if err != nil {
+						slog.WarnContext(c.Request.Context(), "Auth middleware: failed to check claims version", "user_id", claims.UserID, "error", err)
+						common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+						c.Abort()
+						return
+					}
+					if user.ClaimsVersion != version {
+						slog.DebugContext(c.Request.Context(), "Auth middleware: cached user is outdated, reloading from database", "user_id", claims.UserID)
+						goto loadUser
+					}
 				}
+				// On a sample of hits, check the cached user still matches the database
+				if userCache.Sample() {
+					var fresh models.Users
+					if err := db.First(&fresh, claims.UserID).Error; err == nil {
+						if freshJSON, err := json.Marshal(fresh); err == nil {
+							userCache.Verify(userKey, userData, freshJSON)
+						}
+					}
+				}
+				goto setUserContext
 			}
-			// If we get here, either Redis is not available or cache miss
-			log.Printf("Auth middleware: Redis cache miss for user ID %d, falling back to database", claims.UserID)
+		} else if errors.Is(err, cache.ErrMiss) {
+			slog.DebugContext(c.Request.Context(), "Auth middleware: Redis cache miss, falling back to database", "user_id", claims.UserID)
 		}
 
+	loadUser:
 		// DEVELOPMENT MODE: Uncomment this block to use database directly
 		/*
 			// Get user from database
 			if err := db.First(&user, claims.UserID).Error; err != nil {
-				log.Printf("Auth middleware: user not found in database for ID %d", claims.UserID)
+				slog.WarnContext(c.Request.Context(), "Auth middleware: user not found in database", "user_id", claims.UserID)
 				c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 				c.Abort()
 				return
@@ -89,41 +169,54 @@ func Auth(jwtSecret string, db *gorm.DB, redisClient *redis.Client) gin.HandlerF
 
 		// PRODUCTION MODE: Get user from database and cache in Redis
 		if err := db.First(&user, claims.UserID).Error; err != nil {
-			log.Printf("Auth middleware: user not found in database for ID %d", claims.UserID)
+			slog.WarnContext(c.Request.Context(), "Auth middleware: user not found in database", "user_id", claims.UserID)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 			c.Abort()
 			return
 		}
 
-		// Cache user data in Redis if client is available
-		if redisClient != nil {
+		// Cache user data in Redis unless the cache is degraded
+		if userCache.Available() {
 			userJSON, err := json.Marshal(user)
 			if err == nil {
 				// Cache for 1 hour
-				err = redisClient.Set(context.Background(), userKey, userJSON, time.Hour).Err()
-				if err != nil {
-					log.Printf("Auth middleware: failed to cache user in Redis: %v", err)
+				if err := userCache.Set(context.Background(), userKey, userJSON, time.Hour); err != nil {
+					slog.WarnContext(c.Request.Context(), "Auth middleware: failed to cache user in Redis", "user_id", claims.UserID, "error", err)
 				} else {
-					log.Printf("Auth middleware: cached user in Redis for ID %d", claims.UserID)
+					slog.DebugContext(c.Request.Context(), "Auth middleware: cached user in Redis", "user_id", claims.UserID)
 				}
 			}
 		}
 
 	setUserContext:
+		if rejectInactiveUser(c, user) {
+			return
+		}
+
+		if requirePasswordChange(c, user) {
+			return
+		}
+
 		// Create user response object
 		userResponse := models.RegisterResponse{
-			ID:       user.ID,
-			Username: user.Username,
-			Email:    user.Email,
-			Name:     user.Name,
-			Role:     user.Role,
+			ID:                 user.ID,
+			Username:           user.Username,
+			Email:              user.Email,
+			Name:               user.Name,
+			Role:               user.Role,
+			MustChangePassword: user.MustChangePassword,
 		}
 
-		log.Printf("Auth middleware: setting user in context: %+v", userResponse)
+		slog.DebugContext(c.Request.Context(), "Auth middleware: setting user in context", "user_id", userResponse.ID, "role", userResponse.Role)
 
 		// Set user in context
 		c.Set("user", userResponse)
 
+		// Mark the user as online; skipped while Redis is degraded
+		if err := presence.Touch(context.Background(), user.ID); err != nil && !errors.Is(err, cache.ErrUnavailable) {
+			slog.WarnContext(c.Request.Context(), "Auth middleware: failed to refresh presence", "user_id", user.ID, "error", err)
+		}
+
 		c.Next()
 	}
 }
@@ -176,22 +269,31 @@ func AuthWithoutRedis(jwtSecret string, db *gorm.DB) gin.HandlerFunc {
 		// Get user from database
 		var user models.Users
 		if err := db.First(&user, claims.UserID).Error; err != nil {
-			log.Printf("Auth middleware: user not found in database for ID %d", claims.UserID)
+			slog.WarnContext(c.Request.Context(), "Auth middleware: user not found in database", "user_id", claims.UserID)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 			c.Abort()
 			return
 		}
 
+		if rejectInactiveUser(c, user) {
+			return
+		}
+
+		if requirePasswordChange(c, user) {
+			return
+		}
+
 		// Create user response object
 		userResponse := models.RegisterResponse{
-			ID:       user.ID,
-			Username: user.Username,
-			Email:    user.Email,
-			Name:     user.Name,
-			Role:     user.Role,
+			ID:                 user.ID,
+			Username:           user.Username,
+			Email:              user.Email,
+			Name:               user.Name,
+			Role:               user.Role,
+			MustChangePassword: user.MustChangePassword,
 		}
 
-		log.Printf("Auth middleware: setting user in context: %+v", userResponse)
+		slog.DebugContext(c.Request.Context(), "Auth middleware: setting user in context", "user_id", userResponse.ID, "role", userResponse.Role)
 
 		// Set user in context
 		c.Set("user", userResponse)
@@ -199,3 +301,42 @@ func AuthWithoutRedis(jwtSecret string, db *gorm.DB) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// OptionalAuth sets the user in context when the request carries a valid access token,
+// and lets anonymous requests through untouched. Use it on public routes whose response
+// depends on who is asking.
+func OptionalAuth(jwtSecret string, db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accessToken, err := c.Cookie("access_token")
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		claims := &models.Claims{}
+		token, err := jwt.ParseWithClaims(accessToken, claims, func(token *jwt.Token) (interface{}, error) {
+			return []byte(jwtSecret), nil
+		})
+		if err != nil || !token.Valid {
+			c.Next()
+			return
+		}
+
+		var user models.Users
+		if err := db.First(&user, claims.UserID).Error; err != nil || user.Status != models.UserStatusActive {
+			c.Next()
+			return
+		}
+
+		c.Set("user", models.RegisterResponse{
+			ID:                 user.ID,
+			Username:           user.Username,
+			Email:              user.Email,
+			Name:               user.Name,
+			Role:               user.Role,
+			MustChangePassword: user.MustChangePassword,
+		})
+
+		c.Next()
+	}
+}
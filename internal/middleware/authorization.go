@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/binding"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CurrentUser returns the authenticated user set by the auth middleware
+func CurrentUser(c *gin.Context) (models.RegisterResponse, bool) {
+	value, exists := c.Get("user")
+	if !exists {
+		return models.RegisterResponse{}, false
+	}
+	user, ok := value.(models.RegisterResponse)
+	return user, ok
+}
+
+// RequireRole only lets users with one of the given roles through.
+// It must be registered after one of the auth middlewares.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := CurrentUser(c)
+		if !ok {
+			common.SendError(c, http.StatusUnauthorized, "Authentication required", common.CodeUnauthorized, nil)
+			c.Abort()
+			return
+		}
+
+		for _, role := range roles {
+			if user.Role == role {
+				c.Next()
+				return
+			}
+		}
+
+		common.SendError(c, http.StatusForbidden, "Insufficient permissions", common.CodeForbidden, nil)
+		c.Abort()
+	}
+}
+
+// RequirePermission only lets users whose role holds the permission through.
+// Admins hold every permission. It must be registered after one of the auth middlewares.
+func RequirePermission(permissions *services.PermissionService, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := CurrentUser(c)
+		if !ok {
+			common.SendError(c, http.StatusUnauthorized, "Authentication required", common.CodeUnauthorized, nil)
+			c.Abort()
+			return
+		}
+
+		allowed, err := permissions.HasPermission(user.Role, permission)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Permission middleware: failed to check permission", "permission", permission, "role", user.Role, "error", err)
+			common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+			c.Abort()
+			return
+		}
+		if !allowed {
+			common.SendError(c, http.StatusForbidden, "Insufficient permissions", common.CodeForbidden, gin.H{
+				"permission": permission,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireTeamRole only lets members of the team identified by the given route
+// parameter through, optionally restricted to specific team roles.
+// Admins are always allowed so they can manage every team.
+func RequireTeamRole(db *gorm.DB, param string, teamRoles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := CurrentUser(c)
+		if !ok {
+			common.SendError(c, http.StatusUnauthorized, "Authentication required", common.CodeUnauthorized, nil)
+			c.Abort()
+			return
+		}
+
+		if user.Role == models.RoleAdmin {
+			c.Next()
+			return
+		}
+
+		teamID, ok := binding.ID(c, param)
+		if !ok {
+			c.Abort()
+			return
+		}
+
+		var member models.TeamMembers
+		if err := db.Where("team_id = ? AND user_id = ?", teamID, user.ID).First(&member).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				slog.ErrorContext(c.Request.Context(), "Authorization middleware: failed to load team membership", "user_id", user.ID, "error", err)
+				common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+				c.Abort()
+				return
+			}
+			common.SendError(c, http.StatusForbidden, "Not a member of this team", common.CodeForbidden, nil)
+			c.Abort()
+			return
+		}
+
+		if len(teamRoles) == 0 {
+			c.Set("team_role", member.Role)
+			c.Next()
+			return
+		}
+
+		for _, role := range teamRoles {
+			if member.Role == role {
+				c.Set("team_role", member.Role)
+				c.Next()
+				return
+			}
+		}
+
+		common.SendError(c, http.StatusForbidden, "Insufficient team permissions", common.CodeForbidden, nil)
+		c.Abort()
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORS sets the CORS headers for the request. Tenants with their own allowed origins
+// only get the request Origin echoed back when it is in their list; every other request
+// uses the global allowed origin.
+func CORS(defaultOrigin string) gin.HandlerFunc {
+	if defaultOrigin == "" {
+		defaultOrigin = "http://localhost:3001" // fallback
+	}
+
+	return func(c *gin.Context) {
+		// Log incoming request
+		slog.DebugContext(c.Request.Context(), "Incoming request", "method", c.Request.Method, "path", c.Request.URL.Path)
+
+		allowedOrigin := defaultOrigin
+		if tenant, ok := CurrentTenant(c); ok && len(tenant.CORSAllowedOrigins) > 0 {
+			allowedOrigin = ""
+			origin := c.GetHeader("Origin")
+			for _, candidate := range tenant.CORSAllowedOrigins {
+				if candidate == origin {
+					allowedOrigin = origin
+					break
+				}
+			}
+			c.Writer.Header().Add("Vary", "Origin")
+		}
+
+		// Set CORS headers
+		if allowedOrigin != "" {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
+		}
+		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, X-Device-Token, X-Store-ID, If-None-Match, X-API-Schema-Version, X-API-Version, X-Request-ID")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag, X-API-Schema-Version, X-API-Version, Deprecation, Link, X-Request-ID")
+		c.Writer.Header().Set("Access-Control-Max-Age", "86400") // 24 hours
+
+		// Handle preflight
+		if c.Request.Method == "OPTIONS" {
+			slog.DebugContext(c.Request.Context(), "Handling OPTIONS request", "path", c.Request.URL.Path)
+			c.AbortWithStatus(204)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// DeviceTokenHeader is the header paired terminals use to identify themselves
+const DeviceTokenHeader = "X-Device-Token"
+
+// lastSeenResolution limits how often last_seen_at is written for a busy terminal
+const lastSeenResolution = time.Minute
+
+// Device resolves the terminal that sent the request from the X-Device-Token header.
+// Requests without the header pass through untouched; requests with an unknown or
+// revoked token are rejected so a deactivated register cannot keep operating.
+func Device(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader(DeviceTokenHeader)
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		var device models.Devices
+		if err := db.Where("token_hash = ?", services.HashDeviceToken(token)).First(&device).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				common.SendError(c, http.StatusUnauthorized, "Invalid device token", common.CodeInvalidDeviceToken, nil)
+				c.Abort()
+				return
+			}
+			slog.ErrorContext(c.Request.Context(), "Device middleware: failed to load device", "error", err)
+			common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+			c.Abort()
+			return
+		}
+
+		if device.Status != models.DeviceStatusActive {
+			common.SendError(c, http.StatusForbidden, "Device is deactivated", common.CodeDeviceDeactivated, nil)
+			c.Abort()
+			return
+		}
+
+		now := time.Now()
+		if device.LastSeenAt == nil || now.Sub(*device.LastSeenAt) > lastSeenResolution {
+			if err := db.Model(&device).UpdateColumn("last_seen_at", now).Error; err != nil {
+				slog.WarnContext(c.Request.Context(), "Device middleware: failed to update last seen", "device_id", device.ID, "error", err)
+			}
+			device.LastSeenAt = &now
+		}
+
+		c.Set("device", device)
+
+		c.Next()
+	}
+}
+
+// CurrentDevice returns the terminal resolved by the device middleware, if any
+func CurrentDevice(c *gin.Context) (models.Devices, bool) {
+	value, exists := c.Get("device")
+	if !exists {
+		return models.Devices{}, false
+	}
+	device, ok := value.(models.Devices)
+	return device, ok
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/license"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireFeature only lets requests through when the installed license unlocks the feature
+func RequireFeature(manager *license.Manager, feature string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !manager.Has(feature) {
+			common.SendError(c, http.StatusForbidden, "Feature not included in the license", common.CodeFeatureNotLicensed, gin.H{
+				"feature": feature,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
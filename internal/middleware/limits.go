@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/apiversion"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimit rejects request bodies above the max_request_body_bytes limit, or the limit given
+// for the route in routeLimits, e.g. for file uploads. Bodies without a declared length are cut
+// off once they reach the limit.
+func BodyLimit(routeLimits map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := limits.MaxRequestBodyBytes
+		if name, ok := routeLimits[apiversion.Strip(c.FullPath())]; ok {
+			limit = name
+		}
+		max := limits.Get(limit)
+
+		if c.Request.ContentLength > int64(max) {
+			common.SendError(c, http.StatusRequestEntityTooLarge, "Request body too large", common.CodeLimitExceeded, &limits.ExceededError{
+				Limit:  limit,
+				Max:    max,
+				Actual: int(c.Request.ContentLength),
+			})
+			c.Abort()
+			return
+		}
+
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(max))
+		}
+
+		c.Next()
+	}
+}
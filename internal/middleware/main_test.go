@@ -0,0 +1,11 @@
+package middleware_test
+
+import (
+	"testing"
+
+	"github.com/Aebroyx/the-blade-api/internal/testutil"
+)
+
+func TestMain(m *testing.M) {
+	testutil.Main(m)
+}
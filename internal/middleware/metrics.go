@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that matched no route, so probing for paths can't create a
+// series per path
+const unmatchedRoute = "unmatched"
+
+// Metrics counts and times requests by route template, method and status, and tracks the
+// requests in flight
+func Metrics(m *metrics.HTTP) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+
+		inFlight := m.InFlight.WithLabelValues(route, method)
+		inFlight.Inc()
+		defer inFlight.Dec()
+		started := time.Now()
+
+		c.Next()
+
+		status := strconv.Itoa(c.Writer.Status())
+		m.Requests.WithLabelValues(route, method, status).Inc()
+		m.Duration.WithLabelValues(route, method, status).Observe(time.Since(started).Seconds())
+	}
+}
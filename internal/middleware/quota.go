@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// UserQuota counts each request against the authenticated user's API quota and reports it in
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers. Users over quota get
+// 429 QUOTA_EXCEEDED until the window resets. It must be registered after one of the auth middlewares.
+func UserQuota(quotas *services.QuotaService, policy cache.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := CurrentUser(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		status, err := quotas.Count(context.Background(), user.ID, user.Role)
+		if errors.Is(err, cache.ErrUnavailable) {
+			if policy.RateLimit == cache.RateLimitDeny {
+				common.SendError(c, http.StatusServiceUnavailable, "Usage limits cannot be checked right now", common.CodeUsageUnavailable, nil)
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "Quota middleware: failed to count request", "user_id", user.ID, "error", err)
+			c.Next()
+			return
+		}
+		if status.Limit == 0 {
+			c.Next()
+			return
+		}
+
+		setQuotaHeaders(c, status)
+		if status.Used > int64(status.Limit) {
+			retryAfter := int(time.Until(status.ResetsAt).Seconds()) + 1
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			common.SendError(c, http.StatusTooManyRequests, "API request quota exceeded", common.CodeQuotaExceeded, gin.H{
+				"limit":     status.Limit,
+				"window":    status.Window,
+				"resets_at": status.ResetsAt,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// setQuotaHeaders reports the user's quota on the response, unless a rate limit leaves fewer
+// requests
+func setQuotaHeaders(c *gin.Context, status *models.UserQuotaStatus) {
+	setRateLimitHeaders(c, status.Limit, status.Remaining, status.ResetsAt.Unix())
+}
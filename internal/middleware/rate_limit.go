@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit limits each client to the rule's requests on the routes it guards, reporting the
+// client's bucket in X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers.
+// Clients are the signed in user once an auth middleware ran, and the client IP before. Attach
+// it globally and again, with a tighter rule, to route groups that need one; each rule has its
+// own buckets. While Redis is down the cache's rate limit policy decides whether requests are
+// limited per instance, let through or rejected.
+func RateLimit(limiter *ratelimit.Limiter, rule ratelimit.Rule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !rule.Enabled() {
+			c.Next()
+			return
+		}
+
+		subject := "ip:" + c.ClientIP()
+		if user, ok := CurrentUser(c); ok {
+			subject = "user:" + strconv.FormatUint(uint64(user.ID), 10)
+		}
+
+		result, err := limiter.Take(c.Request.Context(), rule, subject)
+		if err != nil {
+			if !errors.Is(err, cache.ErrUnavailable) {
+				slog.WarnContext(c.Request.Context(), "Rate limit middleware: failed to take a token", "rule", rule.Name, "error", err)
+				c.Next()
+				return
+			}
+			if limiter.Policy() == cache.RateLimitDeny {
+				common.SendError(c, http.StatusServiceUnavailable, "Rate limits cannot be checked right now", common.CodeUsageUnavailable, nil)
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		setRateLimitHeaders(c, result.Limit, int64(result.Remaining), result.Reset.Unix())
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+			common.SendError(c, http.StatusTooManyRequests, "Too many requests", common.CodeRateLimited, gin.H{
+				"limit":  rule.Limit,
+				"window": rule.Period.String(),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// setRateLimitHeaders reports a limit in the X-RateLimit headers, unless a limit reported
+// earlier in the request has fewer requests remaining, so clients see the limit they'll hit first
+func setRateLimitHeaders(c *gin.Context, limit int, remaining int64, reset int64) {
+	if current := c.Writer.Header().Get("X-RateLimit-Remaining"); current != "" {
+		if reported, err := strconv.ParseInt(current, 10, 64); err == nil && reported <= remaining {
+			return
+		}
+	}
+	c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
+	c.Header("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+}
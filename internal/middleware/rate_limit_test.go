@@ -0,0 +1,56 @@
+package middleware_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/apiversion"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/ratelimit"
+	"github.com/Aebroyx/the-blade-api/internal/testutil"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitSharesBucketsAcrossInstances(t *testing.T) {
+	env := testutil.New(t)
+	rule := ratelimit.Rule{Name: "test", Limit: 3, Period: time.Minute}
+	ok := func(c *gin.Context) {
+		common.SendSuccess(c, http.StatusOK, "OK", nil)
+	}
+
+	// Each instance has a limiter of its own, so only Redis can share the buckets
+	instance := func() http.Handler {
+		limiter := ratelimit.NewLimiter(env.Cache)
+		return env.API(apiversion.NewRegistrar("v1", func(public *gin.RouterGroup, protected *gin.RouterGroup) {
+			public.GET("/ping", middleware.RateLimit(limiter, rule), ok)
+			protected.GET("/me", middleware.RateLimit(limiter, rule), ok)
+		}))
+	}
+	instances := []http.Handler{instance(), instance()}
+
+	for i := range rule.Limit {
+		rec := env.Request(t, instances[i%2], http.MethodGet, "/api/v1/ping", nil, nil)
+		testutil.Decode(t, rec, http.StatusOK, nil)
+		if remaining := rec.Header().Get("X-RateLimit-Remaining"); remaining != strconv.Itoa(rule.Limit-i-1) {
+			t.Errorf("request %d has %s remaining, want %d", i+1, remaining, rule.Limit-i-1)
+		}
+	}
+
+	rec := env.Request(t, instances[1], http.MethodGet, "/api/v1/ping", nil, nil)
+	testutil.Decode(t, rec, http.StatusTooManyRequests, nil)
+	if retry, err := strconv.Atoi(rec.Header().Get("Retry-After")); err != nil || retry < 1 {
+		t.Errorf("limited response has Retry-After %q", rec.Header().Get("Retry-After"))
+	}
+
+	// Signed in users are limited per user rather than per IP
+	user := env.CreateUser(t, models.RoleUser)
+	rec = env.Request(t, instances[0], http.MethodGet, "/api/v1/me", nil, &user)
+	testutil.Decode(t, rec, http.StatusOK, nil)
+	if remaining := rec.Header().Get("X-RateLimit-Remaining"); remaining != strconv.Itoa(rule.Limit-1) {
+		t.Errorf("user's first request has %s remaining, want %d", remaining, rule.Limit-1)
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/apiversion"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/gin-gonic/gin"
+)
+
+// readOnlyAllowedRoutes stay writable in read-only mode so admins can still sign in and turn it
+// off, and GraphQL, which only reads, keeps answering; routes are given without their API version
+var readOnlyAllowedRoutes = map[string]bool{
+	"/api/auth/login":       true,
+	"/api/auth/logout":      true,
+	"/api/system/read-only": true,
+	"/api/graphql":          true,
+}
+
+// ReadOnlyStatus describes the current read-only state
+type ReadOnlyStatus struct {
+	Enabled bool       `json:"enabled"`
+	Reason  string     `json:"reason,omitempty"`
+	Since   *time.Time `json:"since,omitempty"`
+}
+
+// ReadOnlyMode holds the read-only toggle shared by the middleware and the admin endpoint.
+// The toggle lives in this process only; each instance starts from the READ_ONLY_MODE setting.
+type ReadOnlyMode struct {
+	mu     sync.RWMutex
+	status ReadOnlyStatus
+}
+
+func NewReadOnlyMode(enabled bool, reason string) *ReadOnlyMode {
+	mode := &ReadOnlyMode{}
+	mode.Set(enabled, reason)
+	return mode
+}
+
+// Set turns read-only mode on or off
+func (m *ReadOnlyMode) Set(enabled bool, reason string) ReadOnlyStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !enabled {
+		m.status = ReadOnlyStatus{}
+		return m.status
+	}
+
+	since := time.Now()
+	if m.status.Enabled && m.status.Since != nil {
+		since = *m.status.Since
+	}
+	m.status = ReadOnlyStatus{Enabled: true, Reason: reason, Since: &since}
+	return m.status
+}
+
+// Status returns the current read-only state
+func (m *ReadOnlyMode) Status() ReadOnlyStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
+
+// ReadOnly rejects every mutating request with 503 READ_ONLY while read-only mode is on
+func ReadOnly(mode *ReadOnlyMode) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		status := mode.Status()
+		if !status.Enabled || readOnlyAllowedRoutes[apiversion.Strip(c.FullPath())] {
+			c.Next()
+			return
+		}
+
+		common.SendError(c, http.StatusServiceUnavailable, "API is in read-only mode", common.CodeReadOnly, status)
+		c.Abort()
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// maxRequestIDLength bounds IDs taken from callers
+const maxRequestIDLength = 128
+
+// RequestID gives each request an ID: the one a gateway or client sent, when it is safe to log,
+// or a new one. The ID is returned in the X-Request-ID header and as the request_id field of error
+// responses, and bound to the request's context so every line logged for the request carries it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(common.RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Set(common.RequestIDKey, id)
+		c.Header(common.RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logger.With(c.Request.Context(), "request_id", id))
+
+		c.Next()
+	}
+}
+
+// CurrentRequestID returns the ID of the request
+func CurrentRequestID(c *gin.Context) string {
+	return c.GetString(common.RequestIDKey)
+}
+
+// validRequestID accepts IDs of letters, digits and the punctuation of UUIDs and trace IDs, so a
+// caller can't inject anything into logs or headers
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns 16 random bytes in hex
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
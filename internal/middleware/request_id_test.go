@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/gin-gonic/gin"
+)
+
+// serveError runs a request through RequestID to a handler answering with an error
+func serveError(t *testing.T, sentID string) (*httptest.ResponseRecorder, common.ErrorResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/fail", func(c *gin.Context) {
+		common.SendError(c, http.StatusConflict, "Already taken", common.CodeConflict, map[string]string{"field": "name"})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	if sentID != "" {
+		req.Header.Set(common.RequestIDHeader, sentID)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var body common.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("error response is not JSON: %v", err)
+	}
+	return rec, body
+}
+
+func TestErrorResponseCarriesSentRequestID(t *testing.T) {
+	rec, body := serveError(t, "gateway-7f3a")
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if body.RequestID != "gateway-7f3a" {
+		t.Errorf("request_id = %q, want the ID the caller sent", body.RequestID)
+	}
+	if got := rec.Header().Get(common.RequestIDHeader); got != "gateway-7f3a" {
+		t.Errorf("%s header = %q, want the ID the caller sent", common.RequestIDHeader, got)
+	}
+	// The ID sits beside the details, which keep the shape the handler gave them
+	details, ok := body.Details.(map[string]any)
+	if !ok || details["field"] != "name" || len(details) != 1 {
+		t.Errorf("details = %#v, want the handler's details unchanged", body.Details)
+	}
+}
+
+func TestErrorResponseCarriesGeneratedRequestID(t *testing.T) {
+	for _, sent := range []string{"", "bad id\nwith newline"} {
+		rec, body := serveError(t, sent)
+
+		if body.RequestID == "" || body.RequestID == sent {
+			t.Fatalf("sent %q: request_id = %q, want a generated ID", sent, body.RequestID)
+		}
+		if got := rec.Header().Get(common.RequestIDHeader); got != body.RequestID {
+			t.Errorf("sent %q: %s header = %q, want %q", sent, common.RequestIDHeader, got, body.RequestID)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogger logs each request once it is served, with its route, status and latency and the
+// tenant, user and device it was made by; the request ID comes with the request's context. Server errors are logged as errors and client errors
+// as warnings.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		args := []any{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"status", status,
+			"latency_ms", float64(time.Since(started).Microseconds()) / 1000,
+			"bytes", c.Writer.Size(),
+			"client_ip", c.ClientIP(),
+		}
+		if tenant, ok := CurrentTenant(c); ok {
+			args = append(args, "tenant_id", tenant.ID)
+		}
+		if user, ok := CurrentUser(c); ok {
+			args = append(args, "user_id", user.ID)
+		}
+		if device, ok := CurrentDevice(c); ok {
+			args = append(args, "device_id", device.ID)
+		}
+		if len(c.Errors) > 0 {
+			args = append(args, "errors", c.Errors.String())
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		slog.Log(c.Request.Context(), level, "Request served", args...)
+	}
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/schema"
+	"github.com/gin-gonic/gin"
+)
+
+// shapingWriter holds back the response so it can be reshaped into an older schema version
+type shapingWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *shapingWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *shapingWriter) WriteHeaderNow() {}
+
+func (w *shapingWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *shapingWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *shapingWriter) Status() int {
+	return w.status
+}
+
+func (w *shapingWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *shapingWriter) Written() bool {
+	return w.body.Len() > 0
+}
+
+// SchemaVersion serves each client the response schema version it pins with the
+// X-API-Schema-Version header, or the route's default version when it sends none.
+// Handlers always produce the current version; older versions are reshaped from it.
+// Unknown versions get 400 UNSUPPORTED_SCHEMA_VERSION. It is registered on the group of each
+// API version, since every API version has schema versions of its own.
+func SchemaVersion() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", schema.Header)
+
+		endpoint := schema.Lookup(c.Request.Method, c.FullPath())
+		version, err := endpoint.Resolve(c.GetHeader(schema.Header))
+		if err != nil {
+			common.SendError(c, http.StatusBadRequest, "Unsupported schema version", common.CodeUnsupportedSchemaVersion, gin.H{
+				"oldest":  endpoint.Oldest,
+				"current": endpoint.Current,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Writer.Header().Set(schema.Header, strconv.Itoa(version))
+		if version == endpoint.Current {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &shapingWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = original
+
+		body := writer.body.Bytes()
+		contentType := original.Header().Get("Content-Type")
+		if writer.status >= 200 && writer.status < 300 && strings.HasPrefix(contentType, "application/json") {
+			shaped, err := endpoint.Shape(body, version)
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Schema middleware: failed to shape response", "method", endpoint.Method, "route", endpoint.Path, "version", version, "error", err)
+				common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+				return
+			}
+			body = shaped
+		} else {
+			// Errors are not versioned and are passed through as written
+			original.Header().Set(schema.Header, strconv.Itoa(endpoint.Current))
+		}
+
+		original.WriteHeader(writer.status)
+		if _, err := original.Write(body); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Schema middleware: failed to write response", "error", err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Aebroyx/the-blade-api/internal/apiversion"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/schema"
+	"github.com/Aebroyx/the-blade-api/internal/testutil"
+	"github.com/gin-gonic/gin"
+)
+
+func TestSchemaVersionReshapesPinnedResponses(t *testing.T) {
+	env := testutil.New(t)
+
+	// Version 2 of the v1 route renamed "amount" to "total"; v2 of the API starts at version 1
+	schema.Register(http.MethodGet, "/api/v1/receipt", 2, 1, map[int]schema.Downgrade{
+		1: func(data any) any {
+			receipt := data.(map[string]any)
+			receipt["amount"] = receipt["total"]
+			delete(receipt, "total")
+			return receipt
+		},
+	})
+	registrar := func(version string) apiversion.Registrar {
+		return apiversion.NewRegistrar(version, func(public *gin.RouterGroup, protected *gin.RouterGroup) {
+			public.GET("/receipt", func(c *gin.Context) {
+				common.SendSuccess(c, http.StatusOK, "OK", gin.H{"total": 1050})
+			})
+		})
+	}
+	api := env.API(registrar("v1"), registrar("v2"))
+
+	tests := []struct {
+		name    string
+		path    string
+		pinned  string
+		status  int
+		served  string
+		field   string
+		errCode string
+	}{
+		{name: "default version", path: "/api/v1/receipt", status: http.StatusOK, served: "1", field: "amount"},
+		{name: "pinned current version", path: "/api/v1/receipt", pinned: "2", status: http.StatusOK, served: "2", field: "total"},
+		{name: "pinned old version", path: "/api/v1/receipt", pinned: "1", status: http.StatusOK, served: "1", field: "amount"},
+		{name: "unserved version", path: "/api/v1/receipt", pinned: "3", status: http.StatusBadRequest, errCode: common.CodeUnsupportedSchemaVersion},
+		{name: "other API version", path: "/api/v2/receipt", status: http.StatusOK, served: "1", field: "total"},
+		{name: "alias", path: "/api/receipt", status: http.StatusOK, served: "1", field: "amount"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.pinned != "" {
+				req.Header.Set(schema.Header, tt.pinned)
+			}
+			rec := httptest.NewRecorder()
+			api.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+
+			if tt.errCode != "" {
+				var response common.ErrorResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if response.Code != tt.errCode {
+					t.Errorf("code is %s, want %s", response.Code, tt.errCode)
+				}
+				return
+			}
+
+			if served := rec.Header().Get(schema.Header); served != tt.served {
+				t.Errorf("%s is %q, want %q", schema.Header, served, tt.served)
+			}
+			var data map[string]any
+			testutil.Decode(t, rec, http.StatusOK, &data)
+			if _, ok := data[tt.field]; !ok || len(data) != 1 {
+				t.Errorf("response data is %v, want only %s", data, tt.field)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// SCIMAuth only lets requests carrying the configured SCIM bearer token through.
+// Failures are reported in the SCIM error format identity providers expect.
+func SCIMAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		presented, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="scim"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.SCIMError{
+				Schemas: []string{models.SCIMSchemaError},
+				Status:  strconv.Itoa(http.StatusUnauthorized),
+				Detail:  "Authentication required",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
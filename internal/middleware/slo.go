@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/apiversion"
+	"github.com/Aebroyx/the-blade-api/internal/slo"
+	"github.com/gin-gonic/gin"
+)
+
+// SLO times each request against the response time objective of its route group, matched
+// without the API version. Requests that matched no route aren't counted.
+func SLO(tracker *slo.Tracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started := time.Now()
+
+		c.Next()
+
+		if route := c.FullPath(); route != "" {
+			tracker.Observe(apiversion.Strip(route), time.Since(started))
+		}
+	}
+}
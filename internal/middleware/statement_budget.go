@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"log/slog"
+
+	"github.com/Aebroyx/the-blade-api/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+// StatementBudget counts the SQL statements a request runs with its context and logs requests
+// running more than budget, which usually means a relation is loaded once per row. Paginated
+// listings run with the request's context; other queries are counted once they use it too.
+func StatementBudget(budget int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, counter := database.WithStatementCounter(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if count := counter.Count(); count > int64(budget) {
+			slog.WarnContext(c.Request.Context(), "SQL statement budget exceeded", "method", c.Request.Method, "route", c.FullPath(), "statements", count, "budget", budget)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/license"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// StoreHeader is the header clients use to pick the store they work in
+const StoreHeader = "X-Store-ID"
+
+// Store resolves the store a request works in: the one in the X-Store-ID header, or else the
+// store of the terminal sending it. Listings of the request are scoped to the store. Requests
+// naming no store and coming from no store's terminal work across the stores the user is
+// assigned to, or all stores for admins and users assigned to none. Picking a store takes a
+// license with multi-store support, and terminals only work in their own store. It must be
+// registered after one of the auth middlewares.
+func Store(stores *services.StoreService, manager *license.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var storeID uint
+		if header := c.GetHeader(StoreHeader); header != "" {
+			if !manager.Has(license.FeatureMultiStore) {
+				common.SendError(c, http.StatusForbidden, "Feature not included in the license", common.CodeFeatureNotLicensed, gin.H{
+					"feature": license.FeatureMultiStore,
+				})
+				c.Abort()
+				return
+			}
+			id, err := strconv.ParseUint(header, 10, 32)
+			if err != nil || id == 0 {
+				common.SendError(c, http.StatusBadRequest, "Invalid "+StoreHeader+" header", common.CodeInvalidRequest, nil)
+				c.Abort()
+				return
+			}
+			storeID = uint(id)
+		}
+
+		if device, ok := CurrentDevice(c); ok && device.StoreID != nil {
+			if storeID != 0 && storeID != *device.StoreID {
+				common.SendError(c, http.StatusForbidden, "Terminal is set up at another store", common.CodeForbidden, nil)
+				c.Abort()
+				return
+			}
+			storeID = *device.StoreID
+		}
+
+		if storeID == 0 {
+			if user, ok := CurrentUser(c); ok {
+				assigned, err := stores.AccessibleStores(user)
+				if err != nil {
+					slog.ErrorContext(c.Request.Context(), "Store middleware: failed to load assigned stores", "user_id", user.ID, "error", err)
+					common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+					c.Abort()
+					return
+				}
+				if assigned != nil {
+					c.Set("stores", assigned)
+					c.Request = c.Request.WithContext(pagination.WithStores(c.Request.Context(), assigned))
+				}
+			}
+			c.Next()
+			return
+		}
+
+		user, ok := CurrentUser(c)
+		if !ok {
+			common.SendError(c, http.StatusUnauthorized, "Unauthorized", common.CodeUnauthorized, nil)
+			c.Abort()
+			return
+		}
+
+		store, err := stores.ResolveStore(storeID, user)
+		if err != nil {
+			switch err.Error() {
+			case "store not found":
+				common.SendError(c, http.StatusNotFound, "Store not found", common.CodeNotFound, nil)
+			case "store inactive":
+				common.SendError(c, http.StatusForbidden, "Store is inactive", common.CodeForbidden, nil)
+			case "store not assigned":
+				common.SendError(c, http.StatusForbidden, "You are not assigned to this store", common.CodeForbidden, nil)
+			default:
+				slog.ErrorContext(c.Request.Context(), "Store middleware: failed to resolve store", "store_id", storeID, "error", err)
+				common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set("store", *store)
+		c.Request = c.Request.WithContext(pagination.WithStores(c.Request.Context(), []uint{store.ID}))
+
+		c.Next()
+	}
+}
+
+// CurrentStore returns the store resolved by the store middleware, if any
+func CurrentStore(c *gin.Context) (models.Stores, bool) {
+	value, exists := c.Get("store")
+	if !exists {
+		return models.Stores{}, false
+	}
+	store, ok := value.(models.Stores)
+	return store, ok
+}
+
+// StoreScope returns the stores a request may see records of: the store it works in, or else
+// the stores the user is assigned to. It is nil when the request may see every store.
+func StoreScope(c *gin.Context) []uint {
+	if store, ok := CurrentStore(c); ok {
+		return []uint{store.ID}
+	}
+	if value, exists := c.Get("stores"); exists {
+		if storeIDs, ok := value.([]uint); ok {
+			return storeIDs
+		}
+	}
+	return nil
+}
+
+// StoreRecord makes sure the record in the :id parameter belongs to a store the request may
+// see, answering as if it didn't exist otherwise. storeOf looks up the store of the record,
+// which is named in the not found message. Invalid IDs pass through for the handler to reject.
+// It must be registered after Store.
+func StoreRecord(name string, storeOf func(id uint) (*uint, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := StoreScope(c)
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if scope == nil || err != nil {
+			c.Next()
+			return
+		}
+
+		storeID, err := storeOf(uint(id))
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.ErrorContext(c.Request.Context(), "Store middleware: failed to look up the store of a record", "record", name, "id", id, "error", err)
+			common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+			c.Abort()
+			return
+		}
+		if err != nil || storeID == nil || !slices.Contains(scope, *storeID) {
+			common.SendError(c, http.StatusNotFound, name+" not found", common.CodeNotFound, nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,80 @@
+package middleware_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/Aebroyx/the-blade-api/internal/apiversion"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/license"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/Aebroyx/the-blade-api/internal/testutil"
+	"github.com/gin-gonic/gin"
+)
+
+func TestStoreScopesRequestsWithoutHeader(t *testing.T) {
+	env := testutil.New(t)
+	orderService := services.NewOrderService(env.DB, services.NewPromotionService(env.DB), services.NewActivityService(env.DB))
+	store := middleware.Store(services.NewStoreService(env.DB), license.NewManager(""))
+	api := env.API(apiversion.NewRegistrar("v1", func(public *gin.RouterGroup, protected *gin.RouterGroup) {
+		scoped := protected.Group("", store)
+		scoped.GET("/scope", func(c *gin.Context) {
+			common.SendSuccess(c, http.StatusOK, "OK", middleware.StoreScope(c))
+		})
+		scoped.GET("/orders/:id", middleware.StoreRecord("Order", orderService.OrderStore), func(c *gin.Context) {
+			common.SendSuccess(c, http.StatusOK, "OK", nil)
+		})
+	}))
+
+	var stores [2]models.Stores
+	for i := range stores {
+		stores[i] = models.Stores{Code: "S" + strconv.Itoa(i+1), Name: "Store " + strconv.Itoa(i+1), Active: true}
+		if err := env.DB.Create(&stores[i]).Error; err != nil {
+			t.Fatalf("failed to create store: %v", err)
+		}
+	}
+	var orders [2]*models.Orders
+	for i := range orders {
+		order, err := env.Factory.CreateOrder(nil, func(o *models.Orders) { o.StoreID = &stores[i].ID })
+		if err != nil {
+			t.Fatalf("failed to create order: %v", err)
+		}
+		orders[i] = order
+	}
+
+	clerk := env.CreateUser(t, models.RoleUser)
+	if err := env.DB.Create(&models.UserStores{UserID: clerk.ID, StoreID: stores[0].ID}).Error; err != nil {
+		t.Fatalf("failed to assign store: %v", err)
+	}
+	admin := env.CreateUser(t, models.RoleAdmin)
+
+	var scope []uint
+	testutil.Decode(t, env.Request(t, api, http.MethodGet, "/api/v1/scope", nil, &clerk), http.StatusOK, &scope)
+	if len(scope) != 1 || scope[0] != stores[0].ID {
+		t.Errorf("assigned user is scoped to %v, want [%d]", scope, stores[0].ID)
+	}
+	testutil.Decode(t, env.Request(t, api, http.MethodGet, "/api/v1/scope", nil, &admin), http.StatusOK, &scope)
+	if scope != nil {
+		t.Errorf("admin is scoped to %v, want every store", scope)
+	}
+
+	tests := []struct {
+		name   string
+		user   *models.Users
+		order  *models.Orders
+		status int
+	}{
+		{name: "order of an assigned store", user: &clerk, order: orders[0], status: http.StatusOK},
+		{name: "order of another store", user: &clerk, order: orders[1], status: http.StatusNotFound},
+		{name: "admin sees every store", user: &admin, order: orders[1], status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "/api/v1/orders/" + strconv.FormatUint(uint64(tt.order.ID), 10)
+			testutil.Decode(t, env.Request(t, api, http.MethodGet, path, nil, tt.user), tt.status, nil)
+		})
+	}
+}
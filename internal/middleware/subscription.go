@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/Aebroyx/the-blade-api/internal/apiversion"
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// inactiveSubscriptionRoutes stay writable when the subscription lapsed so admins can sign in and fix billing;
+// GraphQL only reads, as GET routes do
+var inactiveSubscriptionRoutes = map[string]bool{
+	"/api/auth/login":             true,
+	"/api/auth/logout":            true,
+	"/api/admin/subscription":     true,
+	"/api/billing/stripe/webhook": true,
+	"/api/graphql":                true,
+}
+
+// Plan enforces the subscription of the tenant serving the request. Lapsed subscriptions
+// get 402 PAYMENT_REQUIRED on mutating requests, and each request counts against the plan's
+// daily API call limit. Hosts without a tenant or subscription are not limited.
+func Plan(subscriptions *services.SubscriptionService, policy cache.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenant, ok := CurrentTenant(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		subscription, err := subscriptions.GetTenantSubscription(tenant.ID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Plan middleware: failed to load subscription", "tenant_id", tenant.ID, "error", err)
+			c.Next()
+			return
+		}
+		if subscription == nil || subscription.Plan == nil {
+			c.Next()
+			return
+		}
+		c.Set("subscription", *subscription)
+
+		if !subscription.IsUsable() && !inactiveSubscriptionRoutes[apiversion.Strip(c.FullPath())] {
+			switch c.Request.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				common.SendError(c, http.StatusPaymentRequired, "Subscription is not active", common.CodePaymentRequired, gin.H{
+					"status": subscription.Status,
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		plan := subscription.Plan
+		if plan.MaxAPICallsPerDay == 0 {
+			c.Next()
+			return
+		}
+
+		count, err := subscriptions.CountAPICall(context.Background(), tenant.ID)
+		if errors.Is(err, cache.ErrUnavailable) {
+			if policy.RateLimit == cache.RateLimitDeny {
+				common.SendError(c, http.StatusServiceUnavailable, "Usage limits cannot be checked right now", common.CodeUsageUnavailable, nil)
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+		if count > int64(plan.MaxAPICallsPerDay) {
+			common.SendError(c, http.StatusTooManyRequests, "Daily API call limit reached", common.CodePlanLimitExceeded, &services.PlanLimitError{
+				Resource: models.PlanResourceAPICalls,
+				Limit:    plan.MaxAPICallsPerDay,
+				Plan:     plan.Code,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// CurrentSubscription returns the subscription loaded by the plan middleware, if any
+func CurrentSubscription(c *gin.Context) (models.Subscriptions, bool) {
+	value, exists := c.Get("subscription")
+	if !exists {
+		return models.Subscriptions{}, false
+	}
+	subscription, ok := value.(models.Subscriptions)
+	return subscription, ok
+}
+
+// PlanCapacity rejects requests that would add a user or location beyond the plan limit.
+// For locations the JSON body's "location" field is inspected; the body is left readable.
+func PlanCapacity(subscriptions *services.SubscriptionService, resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subscription, ok := CurrentSubscription(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		var location string
+		if resource == models.PlanResourceLocations {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				common.SendError(c, http.StatusBadRequest, "Invalid request body", common.CodeInvalidRequest, err.Error())
+				c.Abort()
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+			var payload struct {
+				Location string `json:"location"`
+			}
+			if err := json.Unmarshal(body, &payload); err == nil {
+				location = payload.Location
+			}
+		}
+
+		if err := subscriptions.CheckCapacity(&subscription, resource, location); err != nil {
+			var limitErr *services.PlanLimitError
+			if errors.As(err, &limitErr) {
+				common.SendError(c, http.StatusPaymentRequired, "Plan limit reached", common.CodePlanLimitExceeded, limitErr)
+				c.Abort()
+				return
+			}
+			slog.ErrorContext(c.Request.Context(), "Plan middleware: failed to check capacity", "resource", resource, "error", err)
+			common.SendError(c, http.StatusInternalServerError, "Internal server error", common.CodeInternalError, nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"log/slog"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// Tenant resolves the tenant serving the request from its Host header.
+// Unknown hosts pass through without a tenant and use the global settings.
+func Tenant(tenantService *services.TenantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenant, err := tenantService.ResolveHost(c.Request.Host)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Tenant middleware: failed to resolve host", "host", c.Request.Host, "error", err)
+		} else if tenant != nil {
+			c.Set("tenant", *tenant)
+		}
+
+		c.Next()
+	}
+}
+
+// CurrentTenant returns the tenant resolved by the tenant middleware, if any
+func CurrentTenant(c *gin.Context) (models.Tenants, bool) {
+	value, exists := c.Get("tenant")
+	if !exists {
+		return models.Tenants{}, false
+	}
+	tenant, ok := value.(models.Tenants)
+	return tenant, ok
+}
@@ -0,0 +1,118 @@
+package pagination
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// FieldSet is a sparse fieldset requested through a fields query parameter (e.g., fields=id,name,role).
+// Only the requested columns are selected and only the requested JSON fields are returned.
+type FieldSet struct {
+	names   []string
+	columns []string
+}
+
+// ParseFields parses a comma-separated fields parameter. Allowed maps each JSON field name to
+// its column; computed fields map to an empty column and are kept in the response only. The
+// key field is always included so records stay identifiable. Returns nil when no fields were requested.
+func ParseFields(raw string, allowed map[string]string, key string) (*FieldSet, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	set := &FieldSet{}
+	seen := map[string]bool{}
+	add := func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		set.names = append(set.names, name)
+		if column := allowed[name]; column != "" {
+			set.columns = append(set.columns, column)
+		}
+	}
+
+	add(key)
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := allowed[name]; !ok {
+			return nil, fmt.Errorf("unknown field: %s", name)
+		}
+		add(name)
+	}
+
+	return set, nil
+}
+
+// Has reports whether the field was requested. A nil field set includes every field.
+func (f *FieldSet) Has(name string) bool {
+	if f == nil {
+		return true
+	}
+	for _, n := range f.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectFields returns the columns to select, or nil to select every column
+func (f *FieldSet) SelectFields() []SelectField {
+	if f == nil {
+		return nil
+	}
+	fields := make([]SelectField, len(f.columns))
+	for i, column := range f.columns {
+		fields[i] = SelectField{Field: column}
+	}
+	return fields
+}
+
+// Shape drops every JSON field that was not requested from a record or a list of records.
+// A nil field set returns the value unchanged.
+func (f *FieldSet) Shape(value interface{}) (interface{}, error) {
+	if f == nil {
+		return value, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		var records []map[string]json.RawMessage
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, err
+		}
+		shaped := make([]map[string]json.RawMessage, len(records))
+		for i, record := range records {
+			shaped[i] = f.pick(record)
+		}
+		return shaped, nil
+	}
+
+	var record map[string]json.RawMessage
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return f.pick(record), nil
+}
+
+// pick keeps the requested fields of one record
+func (f *FieldSet) pick(record map[string]json.RawMessage) map[string]json.RawMessage {
+	shaped := make(map[string]json.RawMessage, len(f.names))
+	for _, name := range f.names {
+		if value, ok := record[name]; ok {
+			shaped[name] = value
+		}
+	}
+	return shaped
+}
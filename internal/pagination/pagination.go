@@ -1,14 +1,19 @@
 package pagination
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
+	"unicode"
 
+	"github.com/Aebroyx/the-blade-api/internal/limits"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // JoinType represents the type of join
@@ -37,12 +42,33 @@ type SelectField struct {
 // QueryParams represents the common query parameters for pagination
 type QueryParams struct {
 	Page     int                    `json:"page" form:"page" binding:"min=1"`
-	PageSize int                    `json:"pageSize" form:"pageSize" binding:"min=1,max=100"`
+	PageSize int                    `json:"pageSize" form:"pageSize" binding:"min=1"`
 	Search   string                 `json:"search" form:"search"`
 	Filters  map[string]interface{} `json:"filters" form:"filters" binding:"dive"`
 	SortBy   string                 `json:"sortBy" form:"sortBy"`
 	SortDesc bool                   `json:"sortDesc" form:"sortDesc"`
 	Dates    map[string]DateRange   `json:"dates" form:"dates"`
+
+	// Context of the request the parameters were bound from; queries run with it
+	ctx context.Context
+}
+
+// storeKey is the context key of the stores a request works in
+type storeKey struct{}
+
+// WithStores returns a context of a request working in some stores. Listings bound from it only
+// return rows of those stores when their config has a StoreFilter.
+func WithStores(ctx context.Context, storeIDs []uint) context.Context {
+	return context.WithValue(ctx, storeKey{}, storeIDs)
+}
+
+// StoresFromContext returns the stores a request works in, if it is limited to any
+func StoresFromContext(ctx context.Context) ([]uint, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	storeIDs, ok := ctx.Value(storeKey{}).([]uint)
+	return storeIDs, ok && len(storeIDs) > 0
 }
 
 // Custom binding for filters
@@ -51,28 +77,98 @@ func (qp *QueryParams) Bind(c *gin.Context) error {
 		return err
 	}
 
-	// Handle filters separately
+	// Handle filters and date ranges separately
 	filters := make(map[string]interface{})
+	dates := make(map[string]DateRange)
 	for key, values := range c.Request.URL.Query() {
+		if len(values) == 0 {
+			continue
+		}
+
 		if strings.HasPrefix(key, "filters[") && strings.HasSuffix(key, "]") {
 			// Extract the field name from filters[field]
 			field := key[8 : len(key)-1]
-			if len(values) > 0 {
-				filters[field] = values[0]
+			filters[field] = values[0]
+			continue
+		}
+
+		if strings.HasPrefix(key, "dates[") && strings.HasSuffix(key, "]") {
+			// Extract the field name and bound from dates[field][start] or dates[field][end]
+			parts := strings.SplitN(key[6:len(key)-1], "][", 2)
+			if len(parts) != 2 {
+				return fmt.Errorf("invalid date range parameter: %s", key)
+			}
+			field, bound := parts[0], parts[1]
+
+			isEnd := bound == "end"
+			if !isEnd && bound != "start" {
+				return fmt.Errorf("invalid date range bound: %s", key)
 			}
+
+			value, err := parseDate(values[0], isEnd)
+			if err != nil {
+				return fmt.Errorf("invalid date for %s: %w", key, err)
+			}
+
+			dateRange := dates[field]
+			if isEnd {
+				dateRange.End = &value
+			} else {
+				dateRange.Start = &value
+			}
+			dates[field] = dateRange
 		}
 	}
 	qp.Filters = filters
+	qp.Dates = dates
+	return qp.BindContext(c.Request.Context())
+}
+
+// BindContext prepares parameters given some other way than a query string, such as GraphQL
+// arguments: queries run with the request's context and the deployment's query limits apply
+func (qp *QueryParams) BindContext(ctx context.Context) error {
+	qp.ctx = ctx
+
+	// Enforce the deployment's query limits
+	if err := limits.Check(limits.MaxPageSize, qp.PageSize); err != nil {
+		return err
+	}
+	if err := limits.Check(limits.MaxFiltersPerQuery, len(qp.Filters)+len(qp.Dates)); err != nil {
+		return err
+	}
 
 	return nil
 }
 
+// parseDate parses an RFC3339 timestamp or a plain YYYY-MM-DD date.
+// Plain dates used as an end bound cover the whole day.
+func parseDate(value string, isEnd bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if isEnd {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
 // DateRange represents a date range filter
 type DateRange struct {
 	Start *time.Time `json:"start" form:"start"`
 	End   *time.Time `json:"end" form:"end"`
 }
 
+// FullTextSearch configures Postgres full-text search on a tsvector column
+type FullTextSearch struct {
+	Column   string // tsvector column to match against (e.g., "search_vector")
+	Language string // Text search configuration, defaults to "simple"
+}
+
 // DateField represents a date field configuration
 type DateField struct {
 	Start string // Database column name for start date
@@ -81,21 +177,28 @@ type DateField struct {
 
 // PaginationConfig holds the configuration for pagination
 type PaginationConfig struct {
-	Model         interface{}            // The model to query (e.g., &models.Users{})
-	BaseCondition map[string]interface{} // Base conditions (e.g., is_deleted = false)
-	SearchFields  []string               // Fields to search in (e.g., ["name", "email", "username"])
-	FilterFields  map[string]string      // Fields that can be filtered (e.g., {"role": "role"})
-	DateFields    map[string]DateField   // Fields that are dates
-	SortFields    []string               // Fields that can be sorted
-	DefaultSort   string                 // Default sort field
-	DefaultOrder  string                 // Default sort order ("ASC" or "DESC")
-	Relations     []string               // Relations to preload
-	Joins         []JoinConfig           // Joins to apply
-	SelectFields  []SelectField          // Custom select fields
-	GroupBy       []string               // Group by clauses
-	Having        []string               // Having clauses
-	Distinct      bool                   // Whether to use DISTINCT
-	TableAlias    string                 // Alias for the main table
+	Model            interface{}            // The model to query (e.g., &models.Users{})
+	BaseCondition    map[string]interface{} // Base conditions (e.g., is_deleted = false)
+	Conditions       []string               // Raw conditions always applied (e.g., "is_deleted = true OR deleted_at IS NOT NULL")
+	Unscoped         bool                   // Include rows soft-deleted through gorm's DeletedAt
+	SearchFields     []string               // Fields to search in (e.g., ["name", "email", "username"])
+	FullText         *FullTextSearch        // Ranked full-text search, used instead of SearchFields on Postgres
+	FilterFields     map[string]string      // Fields that can be filtered (e.g., {"role": "role"})
+	CustomFilters    map[string]string      // Filters mapped to a raw condition with one placeholder (e.g., {"team_id": "id IN (SELECT user_id FROM team_members WHERE team_id = ?)"})
+	StoreFilter      string                 // Raw condition with one placeholder scoping rows to a store the request works in (e.g., "store_id = ?"); see WithStores
+	JSONFilterFields map[string]string      // Filter prefixes mapped to JSONB columns (e.g., {"metadata": "metadata"} allows filters[metadata.department]=kitchen)
+	DateFields       map[string]DateField   // Fields that are dates
+	SortFields       []string               // Fields that can be sorted
+	DefaultSort      string                 // Default sort field
+	DefaultOrder     string                 // Default sort order ("ASC" or "DESC")
+	Relations        []string               // Relations to preload
+	Joins            []JoinConfig           // Joins to apply
+	SelectFields     []SelectField          // Custom select fields
+	GroupBy          []string               // Group by clauses
+	Having           []string               // Having clauses
+	Distinct         bool                   // Whether to use DISTINCT
+	TableAlias       string                 // Alias for the main table
+	ScanIntoMaps     bool                   // Return rows as maps, for aggregated selects that don't match the model
 }
 
 // PaginatedResponse represents the standard pagination response
@@ -117,9 +220,21 @@ func NewPaginator(db *gorm.DB) *Paginator {
 	return &Paginator{db: db}
 }
 
+// withContext returns a paginator running its queries with the context the parameters were
+// bound from, so they are cancelled with the request and count towards its statement budget
+func (p *Paginator) withContext(params QueryParams) *Paginator {
+	if params.ctx == nil {
+		return p
+	}
+	return &Paginator{db: p.db.WithContext(params.ctx)}
+}
+
 // buildSelectClause builds the SELECT clause for the query
 func (p *Paginator) buildSelectClause(config PaginationConfig) *gorm.DB {
 	query := p.db.Model(config.Model)
+	if config.Unscoped {
+		query = query.Unscoped()
+	}
 
 	// Apply table alias if provided
 	if config.TableAlias != "" {
@@ -167,19 +282,37 @@ func (p *Paginator) buildWhereClause(query *gorm.DB, params QueryParams, config
 	for field, value := range config.BaseCondition {
 		query = query.Where(field+" = ?", value)
 	}
+	for _, condition := range config.Conditions {
+		query = query.Where("(" + condition + ")")
+	}
+	if storeIDs, ok := StoresFromContext(params.ctx); ok && config.StoreFilter != "" {
+		// The filter is matched once per store, since it takes a single store
+		conditions := make([]string, len(storeIDs))
+		args := make([]interface{}, len(storeIDs))
+		for i, storeID := range storeIDs {
+			conditions[i] = "(" + config.StoreFilter + ")"
+			args[i] = storeID
+		}
+		query = query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
 
 	// Apply search if provided
-	if params.Search != "" && len(config.SearchFields) > 0 {
-		searchQuery := "%" + params.Search + "%"
-		searchConditions := make([]string, len(config.SearchFields))
-		searchArgs := make([]interface{}, len(config.SearchFields))
-
-		for i, field := range config.SearchFields {
-			searchConditions[i] = field + " ILIKE ?"
-			searchArgs[i] = searchQuery
-		}
+	if tsQuery, language, ok := p.fullTextQuery(params, config); ok {
+		query = query.Where(config.FullText.Column+" @@ to_tsquery(?, ?)", language, tsQuery)
+	} else if params.Search != "" && len(config.SearchFields) > 0 {
+		// Every word has to match at least one of the search fields
+		for _, term := range strings.Fields(params.Search) {
+			searchQuery := "%" + term + "%"
+			searchConditions := make([]string, len(config.SearchFields))
+			searchArgs := make([]interface{}, len(config.SearchFields))
+
+			for i, field := range config.SearchFields {
+				searchConditions[i] = field + " ILIKE ?"
+				searchArgs[i] = searchQuery
+			}
 
-		query = query.Where(strings.Join(searchConditions, " OR "), searchArgs...)
+			query = query.Where("("+strings.Join(searchConditions, " OR ")+")", searchArgs...)
+		}
 	}
 
 	// Apply filters
@@ -189,6 +322,30 @@ func (p *Paginator) buildWhereClause(query *gorm.DB, params QueryParams, config
 		}
 	}
 
+	// Apply custom filters
+	for field, value := range params.Filters {
+		if condition, ok := config.CustomFilters[field]; ok && value != nil {
+			query = query.Where(condition, value)
+		}
+	}
+
+	// Apply JSONB path filters (e.g., filters[metadata.department]=kitchen)
+	for field, value := range params.Filters {
+		prefix, path, found := strings.Cut(field, ".")
+		if !found || value == nil {
+			continue
+		}
+		column, ok := config.JSONFilterFields[prefix]
+		if !ok {
+			continue
+		}
+		keys := strings.Split(path, ".")
+		if !validJSONPath(keys) {
+			continue
+		}
+		query = query.Where(column+" #>> ? = ?", "{"+strings.Join(keys, ",")+"}", value)
+	}
+
 	// Apply date range filters if configured
 	if len(config.DateFields) > 0 && len(params.Dates) > 0 {
 		for field, dateRange := range params.Dates {
@@ -206,6 +363,76 @@ func (p *Paginator) buildWhereClause(query *gorm.DB, params QueryParams, config
 	return query
 }
 
+// Check rejects filters, date ranges and sorts the config doesn't allow. Listings ignore them,
+// but exports check first so a mistyped filter doesn't silently download every row.
+func (c PaginationConfig) Check(params QueryParams) error {
+	for field := range params.Filters {
+		if _, ok := c.FilterFields[field]; ok {
+			continue
+		}
+		if _, ok := c.CustomFilters[field]; ok {
+			continue
+		}
+		if prefix, path, found := strings.Cut(field, "."); found {
+			if _, ok := c.JSONFilterFields[prefix]; ok && validJSONPath(strings.Split(path, ".")) {
+				continue
+			}
+		}
+		return fmt.Errorf("unknown filter: %s", field)
+	}
+	for field := range params.Dates {
+		if _, ok := c.DateFields[field]; !ok {
+			return fmt.Errorf("unknown date field: %s", field)
+		}
+	}
+	if params.SortBy != "" && !slices.Contains(c.SortFields, params.SortBy) {
+		return fmt.Errorf("unknown sort field: %s", params.SortBy)
+	}
+	return nil
+}
+
+// validJSONPath reports whether every key of a JSONB filter path is a plain identifier
+func validJSONPath(keys []string) bool {
+	for _, key := range keys {
+		if key == "" {
+			return false
+		}
+		for _, r := range key {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// fullTextQuery builds a prefix-matching tsquery for the search term when full-text
+// search is configured and the database supports it
+func (p *Paginator) fullTextQuery(params QueryParams, config PaginationConfig) (string, string, bool) {
+	if params.Search == "" || config.FullText == nil || p.db.Dialector.Name() != "postgres" {
+		return "", "", false
+	}
+
+	// Split on anything that isn't a letter or digit so tsquery operators in the input are ignored
+	terms := strings.FieldsFunc(params.Search, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(terms) == 0 {
+		return "", "", false
+	}
+
+	for i, term := range terms {
+		terms[i] = strings.ToLower(term) + ":*"
+	}
+
+	language := config.FullText.Language
+	if language == "" {
+		language = "simple"
+	}
+
+	return strings.Join(terms, " & "), language, true
+}
+
 // buildGroupByClause builds the GROUP BY and HAVING clauses
 func (p *Paginator) buildGroupByClause(query *gorm.DB, config PaginationConfig) *gorm.DB {
 	if len(config.GroupBy) > 0 {
@@ -219,8 +446,48 @@ func (p *Paginator) buildGroupByClause(query *gorm.DB, config PaginationConfig)
 	return query
 }
 
+// buildOrderClause builds the ORDER BY clause, falling back to the default sort
+// when the requested field is not whitelisted. Full-text searches without an explicit
+// sort are ordered by relevance first.
+func (p *Paginator) buildOrderClause(query *gorm.DB, params QueryParams, config PaginationConfig) *gorm.DB {
+	if params.SortBy == "" {
+		if tsQuery, language, ok := p.fullTextQuery(params, config); ok {
+			query = query.Order(clause.OrderBy{Expression: clause.Expr{
+				SQL:  "ts_rank(" + config.FullText.Column + ", to_tsquery(?, ?)) DESC",
+				Vars: []interface{}{language, tsQuery},
+			}})
+		}
+		params.SortBy = config.DefaultSort
+	}
+
+	if params.SortBy == "" {
+		return query
+	}
+
+	// Validate sort field
+	isValidSort := false
+	for _, field := range config.SortFields {
+		if field == params.SortBy {
+			isValidSort = true
+			break
+		}
+	}
+
+	if !isValidSort {
+		params.SortBy = config.DefaultSort
+	}
+
+	sortOrder := "ASC"
+	if params.SortDesc {
+		sortOrder = "DESC"
+	}
+	return query.Order(fmt.Sprintf("%s %s", params.SortBy, sortOrder))
+}
+
 // Paginate executes the pagination query based on the provided parameters and config
 func (p *Paginator) Paginate(params QueryParams, config PaginationConfig) (*PaginatedResponse, error) {
+	p = p.withContext(params)
+
 	// Set default values
 	if params.Page < 1 {
 		params.Page = 1
@@ -228,9 +495,6 @@ func (p *Paginator) Paginate(params QueryParams, config PaginationConfig) (*Pagi
 	if params.PageSize < 1 {
 		params.PageSize = 10
 	}
-	if params.SortBy == "" {
-		params.SortBy = config.DefaultSort
-	}
 	if config.DefaultOrder == "" {
 		config.DefaultOrder = "DESC"
 	}
@@ -249,26 +513,7 @@ func (p *Paginator) Paginate(params QueryParams, config PaginationConfig) (*Pagi
 	}
 
 	// Apply sorting
-	if params.SortBy != "" {
-		// Validate sort field
-		isValidSort := false
-		for _, field := range config.SortFields {
-			if field == params.SortBy {
-				isValidSort = true
-				break
-			}
-		}
-
-		if !isValidSort {
-			params.SortBy = config.DefaultSort
-		}
-
-		sortOrder := "ASC"
-		if params.SortDesc {
-			sortOrder = "DESC"
-		}
-		query = query.Order(fmt.Sprintf("%s %s", params.SortBy, sortOrder))
-	}
+	query = p.buildOrderClause(query, params, config)
 
 	// Apply relations if any
 	if len(config.Relations) > 0 {
@@ -280,13 +525,22 @@ func (p *Paginator) Paginate(params QueryParams, config PaginationConfig) (*Pagi
 	query = query.Offset(offset).Limit(params.PageSize)
 
 	// Execute query
-	// Create a slice of the model type
-	modelType := reflect.TypeOf(config.Model).Elem()
-	sliceType := reflect.SliceOf(modelType)
-	result := reflect.MakeSlice(sliceType, 0, 0).Interface()
-
-	if err := query.Find(&result).Error; err != nil {
-		return nil, fmt.Errorf("failed to fetch data: %w", err)
+	var result interface{}
+	if config.ScanIntoMaps {
+		rows := []map[string]interface{}{}
+		if err := query.Find(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch data: %w", err)
+		}
+		result = rows
+	} else {
+		// Create a slice of the model type
+		modelType := reflect.TypeOf(config.Model).Elem()
+		sliceType := reflect.SliceOf(modelType)
+		result = reflect.MakeSlice(sliceType, 0, 0).Interface()
+
+		if err := query.Find(&result).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch data: %w", err)
+		}
 	}
 
 	// Calculate total pages
@@ -300,3 +554,46 @@ func (p *Paginator) Paginate(params QueryParams, config PaginationConfig) (*Pagi
 		TotalPages: totalPages,
 	}, nil
 }
+
+// Stream executes the query without page limits and calls fn for every matching record.
+// Records are scanned one by one from the result set so large exports don't have to be
+// held in memory. Relations are not preloaded in this mode. With ScanIntoMaps the records are
+// map[string]interface{} rows.
+func (p *Paginator) Stream(params QueryParams, config PaginationConfig, fn func(record interface{}) error) error {
+	p = p.withContext(params)
+
+	// Build the query step by step
+	query := p.buildSelectClause(config)
+	query = p.buildJoinClause(query, config)
+	query = p.buildWhereClause(query, params, config)
+	query = p.buildGroupByClause(query, config)
+	query = p.buildOrderClause(query, params, config)
+
+	rows, err := query.Rows()
+	if err != nil {
+		return fmt.Errorf("failed to fetch data: %w", err)
+	}
+	defer rows.Close()
+
+	modelType := reflect.TypeOf(config.Model).Elem()
+	for rows.Next() {
+		var record interface{}
+		if config.ScanIntoMaps {
+			row := map[string]interface{}{}
+			if err := query.ScanRows(rows, &row); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			record = row
+		} else {
+			record = reflect.New(modelType).Interface()
+			if err := query.ScanRows(rows, record); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
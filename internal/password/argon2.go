@@ -0,0 +1,103 @@
+package password
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2id hashes passwords with argon2id. Hashes use the PHC string format:
+// $argon2id$v=19$m=<memory KiB>,t=<iterations>,p=<parallelism>$<salt>$<key>
+type Argon2id struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  int
+	KeyLength   uint32
+}
+
+// NewArgon2id returns an argon2id hasher with the parameters recommended by RFC 9106 for
+// memory-constrained environments
+func NewArgon2id() *Argon2id {
+	return &Argon2id{
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+func (h *Argon2id) ID() string {
+	return AlgorithmArgon2id
+}
+
+func (h *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Memory, h.Iterations, h.Parallelism, encode(salt), encode(key)), nil
+}
+
+func (h *Argon2id) Identifies(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+// argon2idHash is a parsed argon2id hash
+type argon2idHash struct {
+	version     int
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+// parse reads the parameters, salt and key of a hash
+func (h *Argon2id) parse(hash string) (*argon2idHash, error) {
+	fields, err := splitHash(hash, AlgorithmArgon2id, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed argon2idHash
+	if _, err := fmt.Sscanf(fields[0], "v=%d", &parsed.version); err != nil {
+		return nil, ErrMalformedHash
+	}
+	if _, err := fmt.Sscanf(fields[1], "m=%d,t=%d,p=%d", &parsed.memory, &parsed.iterations, &parsed.parallelism); err != nil {
+		return nil, ErrMalformedHash
+	}
+	if parsed.salt, err = decode(fields[2]); err != nil {
+		return nil, ErrMalformedHash
+	}
+	if parsed.key, err = decode(fields[3]); err != nil || len(parsed.key) == 0 {
+		return nil, ErrMalformedHash
+	}
+	return &parsed, nil
+}
+
+func (h *Argon2id) Verify(hash string, password string) (bool, error) {
+	parsed, err := h.parse(hash)
+	if err != nil {
+		return false, err
+	}
+	if parsed.version != argon2.Version {
+		return false, fmt.Errorf("unsupported argon2 version %d", parsed.version)
+	}
+	key := argon2.IDKey([]byte(password), parsed.salt, parsed.iterations, parsed.memory, parsed.parallelism, uint32(len(parsed.key)))
+	return equalKeys(key, parsed.key), nil
+}
+
+func (h *Argon2id) Outdated(hash string) bool {
+	parsed, err := h.parse(hash)
+	if err != nil {
+		return true
+	}
+	return parsed.memory != h.Memory || parsed.iterations != h.Iterations ||
+		parsed.parallelism != h.Parallelism || uint32(len(parsed.key)) != h.KeyLength
+}
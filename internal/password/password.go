@@ -0,0 +1,115 @@
+// Package password hashes and verifies passwords. New hashes use the configured algorithm,
+// while hashes made by any supported algorithm keep verifying, so deployments can switch
+// algorithms without resetting passwords. Every hash carries its algorithm and parameters:
+//
+//	$2a$10$...                          bcrypt
+//	$argon2id$v=19$m=65536,t=3,p=2$...  argon2id
+//	$scrypt$ln=15,r=8,p=1$...           scrypt
+package password
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Supported algorithms
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmScrypt   = "scrypt"
+)
+
+// Algorithms lists every supported algorithm
+var Algorithms = []string{AlgorithmBcrypt, AlgorithmArgon2id, AlgorithmScrypt}
+
+// ErrUnknownAlgorithm is returned for hashes no supported algorithm made
+var ErrUnknownAlgorithm = errors.New("unknown password hash algorithm")
+
+// ErrMalformedHash is returned for hashes whose parameters can't be read
+var ErrMalformedHash = errors.New("malformed password hash")
+
+// Hasher is one password hashing algorithm
+type Hasher interface {
+	// ID is the algorithm's identifier, one of Algorithms
+	ID() string
+	// Hash hashes a password with a random salt
+	Hash(password string) (string, error)
+	// Identifies reports whether the hash was made by this algorithm
+	Identifies(hash string) bool
+	// Verify reports whether the password matches a hash made by this algorithm
+	Verify(hash string, password string) (bool, error)
+	// Outdated reports whether the hash was made with other parameters than the hasher uses
+	Outdated(hash string) bool
+}
+
+// Manager hashes new passwords with the configured algorithm and verifies hashes of every
+// supported algorithm
+type Manager struct {
+	current Hasher
+	hashers []Hasher
+}
+
+// New creates a manager hashing new passwords with the given algorithm
+func New(algorithm string) (*Manager, error) {
+	hashers := []Hasher{NewBcrypt(), NewArgon2id(), NewScrypt()}
+	for _, hasher := range hashers {
+		if hasher.ID() == algorithm {
+			return &Manager{current: hasher, hashers: hashers}, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported password hash algorithm %q", algorithm)
+}
+
+// Algorithm returns the algorithm new passwords are hashed with
+func (m *Manager) Algorithm() string {
+	return m.current.ID()
+}
+
+// Hash hashes a password with the configured algorithm
+func (m *Manager) Hash(password string) (string, error) {
+	return m.current.Hash(password)
+}
+
+// Verify reports whether the password matches the hash, whichever algorithm made it
+func (m *Manager) Verify(hash string, password string) (bool, error) {
+	for _, hasher := range m.hashers {
+		if hasher.Identifies(hash) {
+			return hasher.Verify(hash, password)
+		}
+	}
+	return false, ErrUnknownAlgorithm
+}
+
+// NeedsRehash reports whether the hash was made by another algorithm or with other parameters
+// than the configured ones. Callers rehash the password once they have it in plain text, e.g.
+// after a successful login.
+func (m *Manager) NeedsRehash(hash string) bool {
+	return !m.current.Identifies(hash) || m.current.Outdated(hash)
+}
+
+// encode encodes salts and keys the way PHC strings do: base64 without padding
+func encode(data []byte) string {
+	return base64.RawStdEncoding.EncodeToString(data)
+}
+
+// decode decodes a salt or key of a PHC string
+func decode(value string) ([]byte, error) {
+	return base64.RawStdEncoding.DecodeString(value)
+}
+
+// equalKeys compares derived keys in constant time
+func equalKeys(a []byte, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// splitHash splits a "$id$field$field..." hash into its fields after the identifier
+func splitHash(hash string, id string, fields int) ([]string, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != fields+2 || parts[0] != "" || parts[1] != id {
+		return nil, ErrMalformedHash
+	}
+	return parts[2:], nil
+}
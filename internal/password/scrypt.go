@@ -0,0 +1,104 @@
+package password
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Scrypt hashes passwords with scrypt. Hashes use a PHC style string with the CPU/memory cost
+// as a power of two: $scrypt$ln=<log2 N>,r=<block size>,p=<parallelism>$<salt>$<key>
+type Scrypt struct {
+	LogN        uint8
+	BlockSize   int
+	Parallelism int
+	SaltLength  int
+	KeyLength   int
+}
+
+// NewScrypt returns a scrypt hasher with N=2^15, r=8 and p=1
+func NewScrypt() *Scrypt {
+	return &Scrypt{
+		LogN:        15,
+		BlockSize:   8,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+func (h *Scrypt) ID() string {
+	return AlgorithmScrypt
+}
+
+func (h *Scrypt) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := scrypt.Key([]byte(password), salt, 1<<h.LogN, h.BlockSize, h.Parallelism, h.KeyLength)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s",
+		h.LogN, h.BlockSize, h.Parallelism, encode(salt), encode(key)), nil
+}
+
+func (h *Scrypt) Identifies(hash string) bool {
+	return strings.HasPrefix(hash, "$scrypt$")
+}
+
+// scryptHash is a parsed scrypt hash
+type scryptHash struct {
+	logN        uint8
+	blockSize   int
+	parallelism int
+	salt        []byte
+	key         []byte
+}
+
+// parse reads the parameters, salt and key of a hash
+func (h *Scrypt) parse(hash string) (*scryptHash, error) {
+	fields, err := splitHash(hash, AlgorithmScrypt, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed scryptHash
+	if _, err := fmt.Sscanf(fields[0], "ln=%d,r=%d,p=%d", &parsed.logN, &parsed.blockSize, &parsed.parallelism); err != nil {
+		return nil, ErrMalformedHash
+	}
+	if parsed.logN < 1 || parsed.logN > 30 {
+		return nil, ErrMalformedHash
+	}
+	if parsed.salt, err = decode(fields[1]); err != nil {
+		return nil, ErrMalformedHash
+	}
+	if parsed.key, err = decode(fields[2]); err != nil || len(parsed.key) == 0 {
+		return nil, ErrMalformedHash
+	}
+	return &parsed, nil
+}
+
+func (h *Scrypt) Verify(hash string, password string) (bool, error) {
+	parsed, err := h.parse(hash)
+	if err != nil {
+		return false, err
+	}
+	key, err := scrypt.Key([]byte(password), parsed.salt, 1<<parsed.logN, parsed.blockSize, parsed.parallelism, len(parsed.key))
+	if err != nil {
+		return false, err
+	}
+	return equalKeys(key, parsed.key), nil
+}
+
+func (h *Scrypt) Outdated(hash string) bool {
+	parsed, err := h.parse(hash)
+	if err != nil {
+		return true
+	}
+	return parsed.logN != h.LogN || parsed.blockSize != h.BlockSize ||
+		parsed.parallelism != h.Parallelism || len(parsed.key) != h.KeyLength
+}
@@ -0,0 +1,88 @@
+package payments
+
+import "context"
+
+// Cash takes payments in cash. The money is in the drawer as soon as it is handed over, so
+// authorizing captures it, and refunds are paid out of the drawer.
+type Cash struct{}
+
+// Authorize implements Provider
+func (Cash) Authorize(ctx context.Context, req Request) (Result, error) {
+	return Result{Reference: req.Reference, Captured: true}, nil
+}
+
+// Capture implements Provider
+func (Cash) Capture(ctx context.Context, reference string, amount int64) error {
+	return nil
+}
+
+// Refund implements Provider
+func (Cash) Refund(ctx context.Context, reference string, amount int64) error {
+	return nil
+}
+
+// ExternalTerminal records card payments taken on a standalone terminal. The terminal has
+// already charged the card, so the payment is captured once the cashier enters its approval
+// code; refunds are run on the terminal too.
+type ExternalTerminal struct{}
+
+// Authorize implements Provider
+func (ExternalTerminal) Authorize(ctx context.Context, req Request) (Result, error) {
+	if req.Reference == "" {
+		return Result{}, &DeclinedError{Reason: "enter the approval code printed by the card terminal"}
+	}
+	return Result{Reference: req.Reference, Captured: true}, nil
+}
+
+// Capture implements Provider
+func (ExternalTerminal) Capture(ctx context.Context, reference string, amount int64) error {
+	return nil
+}
+
+// Refund implements Provider
+func (ExternalTerminal) Refund(ctx context.Context, reference string, amount int64) error {
+	return nil
+}
+
+// Loyalty takes payments in the loyalty points of the order's customer. The points are taken
+// from and given back to the customer's balance together with the payment, so the provider
+// has nothing to do.
+type Loyalty struct{}
+
+// Authorize implements Provider
+func (Loyalty) Authorize(ctx context.Context, req Request) (Result, error) {
+	return Result{Reference: req.OrderNumber, Captured: true}, nil
+}
+
+// Capture implements Provider
+func (Loyalty) Capture(ctx context.Context, reference string, amount int64) error {
+	return nil
+}
+
+// Refund implements Provider
+func (Loyalty) Refund(ctx context.Context, reference string, amount int64) error {
+	return nil
+}
+
+// GiftCard takes payments from the balance of a gift card, whose code is the payment's
+// reference. Like loyalty points, the balance is taken and given back together with the
+// payment; the code itself is not kept on the payment, since it is enough to spend the card.
+type GiftCard struct{}
+
+// Authorize implements Provider
+func (GiftCard) Authorize(ctx context.Context, req Request) (Result, error) {
+	if req.Reference == "" {
+		return Result{}, &DeclinedError{Reason: "enter the gift card code"}
+	}
+	return Result{Reference: req.OrderNumber, Captured: true}, nil
+}
+
+// Capture implements Provider
+func (GiftCard) Capture(ctx context.Context, reference string, amount int64) error {
+	return nil
+}
+
+// Refund implements Provider
+func (GiftCard) Refund(ctx context.Context, reference string, amount int64) error {
+	return nil
+}
@@ -0,0 +1,108 @@
+// Package payments abstracts how money is taken. Every payment method is served by a Provider,
+// so cash, card terminals and online gateways go through the same authorize, capture and refund
+// flow and the rest of the API never talks to a gateway directly.
+package payments
+
+import (
+	"context"
+	"errors"
+	"sort"
+)
+
+// Payment methods served by the built-in providers
+const (
+	MethodCash = "cash"
+	// Cards taken on a standalone terminal that isn't connected to the API; the cashier
+	// enters the approval code it prints
+	MethodCard = "card"
+	// Cards and wallets charged through Stripe PaymentIntents
+	MethodStripe = "stripe"
+	// Loyalty points of the order's customer
+	MethodLoyalty = "loyalty"
+	// Gift cards; the reference is the card's code
+	MethodGiftCard = "gift_card"
+)
+
+// Request describes a payment a provider is asked to authorize. Amount is in minor currency
+// units.
+type Request struct {
+	OrderID     uint
+	OrderNumber string
+	Amount      int64
+	// Reference entered at the till, e.g. the approval code of a card terminal, or the
+	// payment method collected by the client for online providers
+	Reference string
+	// Capture asks providers that can to take the money together with the authorization
+	Capture bool
+	// IdempotencyKey is the same when a request is retried, so online providers charge once
+	IdempotencyKey string
+}
+
+// Result is a provider's answer. Reference identifies the payment at the provider and is what
+// later captures and refunds refer to; Captured is set when the provider took the money
+// together with the authorization. Pending payments are still being confirmed, e.g. by the
+// customer's bank, and the provider reports the outcome later.
+type Result struct {
+	Reference string
+	Captured  bool
+	Pending   bool
+}
+
+// DeclinedError reports that a provider declined a payment. The payment is recorded as failed
+// with the reason.
+type DeclinedError struct {
+	Reason string
+}
+
+func (e *DeclinedError) Error() string {
+	return "payment declined: " + e.Reason
+}
+
+// AsDeclined unwraps a DeclinedError
+func AsDeclined(err error) (*DeclinedError, bool) {
+	var declined *DeclinedError
+	ok := errors.As(err, &declined)
+	return declined, ok
+}
+
+// Provider takes payments for one or more methods
+type Provider interface {
+	// Authorize reserves the amount, or takes it outright for methods without a separate capture
+	Authorize(ctx context.Context, req Request) (Result, error)
+	// Capture takes an authorized amount
+	Capture(ctx context.Context, reference string, amount int64) error
+	// Refund returns part or all of a captured amount
+	Refund(ctx context.Context, reference string, amount int64) error
+}
+
+// Registry maps payment methods to their providers. Providers are registered at startup; the
+// registry is not synchronized.
+type Registry struct {
+	providers map[string]Provider
+}
+
+// NewRegistry creates a registry without providers
+func NewRegistry() *Registry {
+	return &Registry{providers: map[string]Provider{}}
+}
+
+// Register serves a payment method with a provider, replacing any previous one
+func (r *Registry) Register(method string, provider Provider) {
+	r.providers[method] = provider
+}
+
+// Provider returns the provider of a payment method
+func (r *Registry) Provider(method string) (Provider, bool) {
+	provider, ok := r.providers[method]
+	return provider, ok
+}
+
+// Methods lists the payment methods with a provider
+func (r *Registry) Methods() []string {
+	methods := make([]string, 0, len(r.providers))
+	for method := range r.providers {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
@@ -0,0 +1,170 @@
+// Package ratelimit limits how often a client may call the API with token buckets. A bucket
+// holds up to Limit tokens and refills completely over Period, so a client may burst up to
+// Limit requests and then make Limit requests per Period. Buckets live in Redis so every API
+// instance shares them, and in memory per instance when Redis isn't there.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+)
+
+// pruneInterval is how often full buckets are dropped from memory
+const pruneInterval = time.Minute
+
+// Rule limits the requests of each client to Limit per Period. Rules with no limit allow
+// everything.
+type Rule struct {
+	Name   string
+	Limit  int
+	Period time.Duration
+}
+
+// Enabled reports whether the rule limits anything
+func (r Rule) Enabled() bool {
+	return r.Limit > 0 && r.Period > 0
+}
+
+// ParseRule parses a rule written as limit/period, e.g. "100/1m"; an empty value or a limit of
+// 0 disables it
+func ParseRule(name string, value string) (Rule, error) {
+	rule := Rule{Name: name}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return rule, nil
+	}
+
+	limit, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return rule, fmt.Errorf("%q must look like 100/1m", value)
+	}
+	var err error
+	if rule.Limit, err = strconv.Atoi(limit); err != nil || rule.Limit < 0 {
+		return rule, fmt.Errorf("%q must have a limit of 0 or more", value)
+	}
+	if rule.Period, err = time.ParseDuration(period); err != nil || rule.Period < time.Second {
+		return rule, fmt.Errorf("%q must have a period of at least 1s", value)
+	}
+	return rule, nil
+}
+
+// Result is the state of a client's bucket after a request. Reset is when the bucket will be
+// full again; RetryAfter is how long a rejected client must wait for a token.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Time
+	RetryAfter time.Duration
+}
+
+// result describes a bucket holding tokens after the request
+func (r Rule) result(allowed bool, tokens float64, now time.Time) Result {
+	perToken := r.Period / time.Duration(r.Limit)
+	result := Result{
+		Allowed:   allowed,
+		Limit:     r.Limit,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     now.Add(time.Duration((float64(r.Limit) - tokens) * float64(perToken))),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) * float64(perToken))
+	}
+	return result
+}
+
+// Limiter takes tokens from the clients' buckets
+type Limiter struct {
+	cache  *cache.Cache
+	memory *memory
+}
+
+func NewLimiter(appCache *cache.Cache) *Limiter {
+	return &Limiter{
+		cache:  appCache,
+		memory: newMemory(),
+	}
+}
+
+// Policy returns what rate limiting does while Redis is down
+func (l *Limiter) Policy() string {
+	return l.cache.Policy().RateLimit
+}
+
+// Take takes a token from the subject's bucket for the rule. Without Redis buckets are kept in
+// memory; while Redis is down they are too under the local policy, and otherwise
+// cache.ErrUnavailable is returned for the caller to apply the policy.
+func (l *Limiter) Take(ctx context.Context, rule Rule, subject string) (Result, error) {
+	now := time.Now()
+	key := cache.RateLimitKey(rule.Name, subject)
+	if l.cache.State() != cache.StateDisabled {
+		allowed, tokens, err := l.cache.TakeToken(ctx, key, rule.Limit, rule.Period)
+		if err == nil {
+			return rule.result(allowed, tokens, now), nil
+		}
+		if l.cache.Policy().RateLimit != cache.RateLimitLocal {
+			return Result{}, err
+		}
+	}
+
+	allowed, tokens := l.memory.take(key, rule, now)
+	return rule.result(allowed, tokens, now), nil
+}
+
+// bucket is a token bucket kept in memory
+type bucket struct {
+	tokens float64
+	at     time.Time
+	full   time.Time
+}
+
+// memory holds the buckets of one API instance
+type memory struct {
+	mu         sync.Mutex
+	buckets    map[string]*bucket
+	lastPruned time.Time
+}
+
+func newMemory() *memory {
+	return &memory{buckets: map[string]*bucket{}, lastPruned: time.Now()}
+}
+
+// take refills the bucket at key and takes a token if one is left, like the Redis script
+func (m *memory) take(key string, rule Rule, now time.Time) (bool, float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now.Sub(m.lastPruned) > pruneInterval {
+		for k, b := range m.buckets {
+			if now.After(b.full) {
+				delete(m.buckets, k)
+			}
+		}
+		m.lastPruned = now
+	}
+
+	capacity := float64(rule.Limit)
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, at: now}
+		m.buckets[key] = b
+	}
+	perSecond := capacity / rule.Period.Seconds()
+	b.tokens = math.Min(capacity, b.tokens+math.Max(0, now.Sub(b.at).Seconds())*perSecond)
+	b.at = now
+
+	allowed := false
+	if b.tokens >= 1 {
+		b.tokens--
+		allowed = true
+	}
+	b.full = now.Add(time.Duration((capacity - b.tokens) / perSecond * float64(time.Second)))
+	return allowed, b.tokens
+}
@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Rule
+		enabled bool
+		wantErr bool
+	}{
+		{value: "100/1m", want: Rule{Name: "api", Limit: 100, Period: time.Minute}, enabled: true},
+		{value: " 5/30s ", want: Rule{Name: "api", Limit: 5, Period: 30 * time.Second}, enabled: true},
+		{value: "", want: Rule{Name: "api"}},
+		{value: "0/1m", want: Rule{Name: "api", Period: time.Minute}},
+		{value: "100", wantErr: true},
+		{value: "many/1m", wantErr: true},
+		{value: "-1/1m", wantErr: true},
+		{value: "100/soon", wantErr: true},
+		{value: "100/500ms", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			rule, err := ParseRule("api", tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parsed %q as %+v, want an error", tt.value, rule)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to parse %q: %v", tt.value, err)
+			}
+			if rule != tt.want {
+				t.Errorf("parsed %q as %+v, want %+v", tt.value, rule, tt.want)
+			}
+			if rule.Enabled() != tt.enabled {
+				t.Errorf("%q enabled is %v, want %v", tt.value, rule.Enabled(), tt.enabled)
+			}
+		})
+	}
+}
+
+func TestMemoryTake(t *testing.T) {
+	rule := Rule{Name: "test", Limit: 3, Period: 3 * time.Second}
+	start := time.Now()
+
+	tests := []struct {
+		name    string
+		key     string
+		after   time.Duration
+		allowed bool
+		tokens  float64
+	}{
+		{name: "full bucket", key: "a", allowed: true, tokens: 2},
+		{name: "burst", key: "a", allowed: true, tokens: 1},
+		{name: "last token", key: "a", allowed: true, tokens: 0},
+		{name: "empty bucket", key: "a", allowed: false, tokens: 0},
+		{name: "other client", key: "b", allowed: true, tokens: 2},
+		{name: "half a token refilled", key: "a", after: 500 * time.Millisecond, allowed: false, tokens: 0.5},
+		{name: "one token refilled", key: "a", after: time.Second, allowed: true, tokens: 0},
+		{name: "refill stops at the limit", key: "a", after: time.Minute, allowed: true, tokens: 2},
+	}
+
+	memory := newMemory()
+	for _, tt := range tests {
+		allowed, tokens := memory.take(tt.key, rule, start.Add(tt.after))
+		if allowed != tt.allowed || tokens != tt.tokens {
+			t.Errorf("%s: allowed %v with %v tokens left, want %v with %v", tt.name, allowed, tokens, tt.allowed, tt.tokens)
+		}
+	}
+}
+
+func TestMemoryPrunesFullBuckets(t *testing.T) {
+	rule := Rule{Name: "test", Limit: 2, Period: time.Second}
+	memory := newMemory()
+	now := memory.lastPruned
+
+	memory.take("idle", rule, now)
+	memory.take("busy", rule, now.Add(pruneInterval))
+	memory.take("busy", rule, now.Add(pruneInterval+time.Millisecond))
+
+	if _, ok := memory.buckets["idle"]; ok {
+		t.Error("bucket refilled long ago was not pruned")
+	}
+	if _, ok := memory.buckets["busy"]; !ok {
+		t.Error("bucket still refilling was pruned")
+	}
+}
+
+func TestRuleResult(t *testing.T) {
+	rule := Rule{Name: "test", Limit: 10, Period: 10 * time.Second}
+	now := time.Now()
+
+	allowed := rule.result(true, 7.5, now)
+	if !allowed.Allowed || allowed.Limit != 10 || allowed.Remaining != 7 || allowed.RetryAfter != 0 {
+		t.Errorf("allowed result is %+v", allowed)
+	}
+	if want := now.Add(2500 * time.Millisecond); !allowed.Reset.Equal(want) {
+		t.Errorf("bucket is full again at %v, want %v", allowed.Reset, want)
+	}
+
+	rejected := rule.result(false, 0.25, now)
+	if rejected.Allowed || rejected.Remaining != 0 || rejected.RetryAfter != 750*time.Millisecond {
+		t.Errorf("rejected result is %+v", rejected)
+	}
+}
+
+func TestLimiterWithoutRedisLimitsInMemory(t *testing.T) {
+	limiter := NewLimiter(cache.Disabled())
+	rule := Rule{Name: "test", Limit: 2, Period: time.Minute}
+	ctx := context.Background()
+
+	for i := range rule.Limit {
+		result, err := limiter.Take(ctx, rule, "ip:192.0.2.1")
+		if err != nil || !result.Allowed {
+			t.Fatalf("request %d: %+v, %v", i+1, result, err)
+		}
+	}
+	result, err := limiter.Take(ctx, rule, "ip:192.0.2.1")
+	if err != nil || result.Allowed || result.RetryAfter <= 0 {
+		t.Errorf("request over the limit: %+v, %v", result, err)
+	}
+
+	// Rules have buckets of their own
+	other := Rule{Name: "other", Limit: 2, Period: time.Minute}
+	if result, err := limiter.Take(ctx, other, "ip:192.0.2.1"); err != nil || !result.Allowed {
+		t.Errorf("request under another rule: %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,102 @@
+package realtime
+
+import (
+	"log/slog"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+)
+
+// storeScope is the store of an event about store data, 0 for data outside any store
+func storeScope(storeID *uint) *uint {
+	scope := uint(0)
+	if storeID != nil {
+		scope = *storeID
+	}
+	return &scope
+}
+
+// OrderCreatedHook announces orders once they are placed, directly or from a draft; subscribe it
+// with OrderService.AfterTransition
+func (h *Hub) OrderCreatedHook(transition services.OrderTransition) {
+	if transition.From != models.OrderStatusDraft || transition.To != models.OrderStatusPlaced {
+		return
+	}
+
+	order := transition.Order
+	h.Publish(models.RealtimeEvent{
+		Type:  models.RealtimeOrderCreated,
+		Topic: models.RealtimeTopicOrders,
+		Data: models.JSONMap{
+			"id":            order.ID,
+			"number":        order.Number,
+			"status":        order.Status,
+			"item_count":    order.ItemCount,
+			"total":         order.Total,
+			"customer_id":   order.CustomerID,
+			"store_id":      order.StoreID,
+			"table_id":      order.TableID,
+			"created_by_id": order.CreatedByID,
+			"placed_at":     order.PlacedAt,
+		},
+		Store: storeScope(order.StoreID),
+	})
+}
+
+// StockChangedHook announces the new quantity of every stock level that changed; subscribe it
+// with InventoryService.AfterStockChange
+func (h *Hub) StockChangedHook(levels []models.StockLevels) {
+	for _, level := range levels {
+		h.Publish(models.RealtimeEvent{
+			Type:  models.RealtimeStockChanged,
+			Topic: models.RealtimeTopicStock,
+			Data:  level,
+			Store: storeScope(level.StoreID),
+		})
+	}
+}
+
+// UserUpdatedHook announces updated users; subscribe it with ChangeFeedService.AfterPublish
+func (h *Hub) UserUpdatedHook(events []models.ChangeEvents) {
+	for _, event := range events {
+		if event.Entity != models.ChangeEntityUser || event.Action != models.ChangeActionUpdated {
+			continue
+		}
+		h.Publish(models.RealtimeEvent{
+			Type:  models.RealtimeUserUpdated,
+			Topic: models.RealtimeTopicUsers,
+			Data:  models.JSONMap{"id": event.EntityID},
+		})
+	}
+}
+
+// PrintJobChangedHook announces print jobs to the staff of the device's store and to the device
+// itself, which claims the job once it is queued; subscribe it with PrintJobService.AfterChange
+func (h *Hub) PrintJobChangedHook(event services.PrintJobEvent) {
+	device := event.Job.DeviceID
+	h.Publish(models.RealtimeEvent{
+		Type:   models.RealtimePrintJobUpdated,
+		Topic:  models.RealtimeTopicPrintJobs,
+		Data:   event.Job,
+		Store:  storeScope(event.StoreID),
+		Device: &device,
+	})
+}
+
+// TerminalConfigUpdatedHook tells a terminal its configuration changed, with the ETag to fetch
+// it by; subscribe it with DeviceConfigService.AfterUpdate
+func (h *Hub) TerminalConfigUpdatedHook(config models.TerminalConfigResponse) {
+	etag, err := services.TerminalConfigETag(&config)
+	if err != nil {
+		slog.Error("Failed to compute terminal config ETag", "device_id", config.DeviceID, "error", err)
+		return
+	}
+
+	device := config.DeviceID
+	h.Publish(models.RealtimeEvent{
+		Type:   models.RealtimeTerminalConfigUpdated,
+		Topic:  models.RealtimeTopicTerminalConfig,
+		Data:   models.JSONMap{"device_id": config.DeviceID, "etag": etag},
+		Device: &device,
+	})
+}
@@ -0,0 +1,233 @@
+// Package realtime pushes domain events to WebSocket clients. Each instance holds the clients
+// connected to it, and events published on any instance are fanned out to every other one over
+// Redis pub/sub, so clients see events wherever they happened. While Redis is unavailable events
+// only reach the clients of the instance they happened on.
+package realtime
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// clientBuffer is how many events a client may fall behind before it is dropped
+const clientBuffer = 64
+
+// resubscribeInterval is how long to wait before subscribing to Redis again while it is away
+const resubscribeInterval = 5 * time.Second
+
+// envelope is an event as sent over Redis. Origin names the instance that published it, which
+// has already delivered it to its own clients. The event's scope isn't part of its JSON, so it
+// travels beside it.
+type envelope struct {
+	Origin string               `json:"origin"`
+	Store  *uint                `json:"store,omitempty"`
+	Device *uint                `json:"device,omitempty"`
+	Event  models.RealtimeEvent `json:"event"`
+}
+
+// Hub hands published events to the clients subscribed to their topic
+type Hub struct {
+	cache  *cache.Cache
+	origin string
+
+	mu      sync.RWMutex
+	clients map[*Client]struct{}
+}
+
+func NewHub(cache *cache.Cache) *Hub {
+	origin := make([]byte, 8)
+	_, _ = rand.Read(origin)
+
+	return &Hub{
+		cache:   cache,
+		origin:  hex.EncodeToString(origin),
+		clients: map[*Client]struct{}{},
+	}
+}
+
+// Access is what a connection may see: the stores it works in and, for terminals, the device
+// it is
+type Access struct {
+	// Stores limits the connection to the events of these stores; nil allows every store
+	Stores []uint
+	// Device is the terminal of the connection, 0 for users. Terminals only get the events
+	// addressed to them.
+	Device uint
+}
+
+// Client is a connection's view of the hub: what it may see, the topics it follows and the
+// events waiting to be sent to it
+type Client struct {
+	access  Access
+	events  chan models.RealtimeEvent
+	dropped chan struct{}
+	drop    sync.Once
+
+	mu     sync.Mutex
+	topics map[string]bool
+}
+
+// Register adds a client with the given access, following no topics until it subscribes
+func (h *Hub) Register(access Access) *Client {
+	client := &Client{
+		access:  access,
+		events:  make(chan models.RealtimeEvent, clientBuffer),
+		dropped: make(chan struct{}),
+		topics:  map[string]bool{},
+	}
+
+	h.mu.Lock()
+	h.clients[client] = struct{}{}
+	h.mu.Unlock()
+	return client
+}
+
+// Unregister removes a client once its connection is closed
+func (h *Hub) Unregister(client *Client) {
+	h.mu.Lock()
+	delete(h.clients, client)
+	h.mu.Unlock()
+}
+
+// Events returns the events to send to the client
+func (c *Client) Events() <-chan models.RealtimeEvent {
+	return c.events
+}
+
+// Dropped returns a channel that is closed when the client fell too far behind and gets no
+// more events; its connection should be closed
+func (c *Client) Dropped() <-chan struct{} {
+	return c.dropped
+}
+
+// Subscribe starts sending the client the events of the topics
+func (c *Client) Subscribe(topics ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, topic := range topics {
+		c.topics[topic] = true
+	}
+}
+
+// Unsubscribe stops sending the client the events of the topics
+func (c *Client) Unsubscribe(topics ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, topic := range topics {
+		delete(c.topics, topic)
+	}
+}
+
+// wants reports whether the client follows the event's topic and may see the event
+func (c *Client) wants(event models.RealtimeEvent) bool {
+	if c.access.Device != 0 && (event.Device == nil || *event.Device != c.access.Device) {
+		return false
+	}
+	if c.access.Stores != nil && event.Store != nil && !slices.Contains(c.access.Stores, *event.Store) {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.topics[event.Topic]
+}
+
+// deliver hands the event to this instance's clients that want it. Clients too far behind to
+// take it are dropped rather than holding up everyone else.
+func (h *Hub) deliver(event models.RealtimeEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.clients {
+		if !client.wants(event) {
+			continue
+		}
+		select {
+		case client.events <- event:
+		default:
+			client.drop.Do(func() { close(client.dropped) })
+		}
+	}
+}
+
+// Publish hands the event to the subscribed clients of this instance and fans it out to the
+// other instances. Failing to reach Redis is logged rather than returned, since the change the
+// event announces has already happened.
+func (h *Hub) Publish(event models.RealtimeEvent) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	h.deliver(event)
+
+	client, ok := h.cache.Client()
+	if !ok {
+		return
+	}
+	data, err := json.Marshal(envelope{Origin: h.origin, Store: event.Store, Device: event.Device, Event: event})
+	if err != nil {
+		slog.Error("Failed to encode realtime event", "type", event.Type, "error", err)
+		return
+	}
+	if err := client.Publish(context.Background(), cache.EventsChannel, data).Err(); err != nil {
+		slog.Warn("Failed to fan out realtime event", "type", event.Type, "error", err)
+	}
+}
+
+// Run hands the events other instances publish to this instance's clients until ctx is done,
+// subscribing again whenever Redis comes back
+func (h *Hub) Run(ctx context.Context) {
+	for {
+		if client, ok := h.cache.Client(); ok {
+			h.relay(ctx, client)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resubscribeInterval):
+		}
+	}
+}
+
+// relay delivers the events received on the Redis channel until ctx is done or the
+// subscription fails
+func (h *Hub) relay(ctx context.Context, client *redis.Client) {
+	pubsub := client.Subscribe(ctx, cache.EventsChannel)
+	defer pubsub.Close()
+	if _, err := pubsub.Receive(ctx); err != nil {
+		if ctx.Err() == nil {
+			slog.WarnContext(ctx, "Failed to subscribe to realtime events", "error", err)
+		}
+		return
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+			var received envelope
+			if err := json.Unmarshal([]byte(message.Payload), &received); err != nil {
+				slog.WarnContext(ctx, "Dropped malformed realtime event", "error", err)
+				continue
+			}
+			if received.Origin == h.origin {
+				continue
+			}
+			received.Event.Store, received.Event.Device = received.Store, received.Device
+			h.deliver(received.Event)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package realtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// eventTimeout is how long a test waits for an event that should arrive
+const eventTimeout = 2 * time.Second
+
+// receive returns the next event of the client, failing the test when none arrives
+func receive(t *testing.T, client *Client) models.RealtimeEvent {
+	t.Helper()
+	select {
+	case event := <-client.Events():
+		return event
+	case <-time.After(eventTimeout):
+		t.Fatal("no event arrived")
+		return models.RealtimeEvent{}
+	}
+}
+
+// expectNone fails the test when the client has an event waiting
+func expectNone(t *testing.T, client *Client) {
+	t.Helper()
+	select {
+	case event := <-client.Events():
+		t.Fatalf("unexpected %s event", event.Type)
+	default:
+	}
+}
+
+func storeEvent(topic string, storeID uint) models.RealtimeEvent {
+	return models.RealtimeEvent{Type: "test", Topic: topic, Data: storeID, Store: &storeID}
+}
+
+func TestHubDeliversSubscribedTopicsOnly(t *testing.T) {
+	hub := NewHub(cache.Disabled())
+	orders := hub.Register(Access{})
+	orders.Subscribe(models.RealtimeTopicOrders)
+	stock := hub.Register(Access{})
+	stock.Subscribe(models.RealtimeTopicStock)
+
+	hub.Publish(storeEvent(models.RealtimeTopicOrders, 1))
+
+	if event := receive(t, orders); event.OccurredAt.IsZero() {
+		t.Error("published event has no occurred_at")
+	}
+	expectNone(t, stock)
+
+	orders.Unsubscribe(models.RealtimeTopicOrders)
+	hub.Publish(storeEvent(models.RealtimeTopicOrders, 1))
+	expectNone(t, orders)
+}
+
+func TestHubLimitsStoreEventsToClientStores(t *testing.T) {
+	hub := NewHub(cache.Disabled())
+	everywhere := hub.Register(Access{})
+	storeOne := hub.Register(Access{Stores: []uint{1}})
+	for _, client := range []*Client{everywhere, storeOne} {
+		client.Subscribe(models.RealtimeTopicOrders, models.RealtimeTopicUsers)
+	}
+
+	hub.Publish(storeEvent(models.RealtimeTopicOrders, 2))
+	if event := receive(t, everywhere); event.Data != uint(2) {
+		t.Errorf("unrestricted client got store %v, want 2", event.Data)
+	}
+	expectNone(t, storeOne)
+
+	// Orders outside any store are store data too, so clients limited to stores don't get them
+	hub.OrderCreatedHook(services.OrderTransition{
+		Order: &models.Orders{ID: 7},
+		From:  models.OrderStatusDraft,
+		To:    models.OrderStatusPlaced,
+	})
+	receive(t, everywhere)
+	expectNone(t, storeOne)
+
+	hub.Publish(storeEvent(models.RealtimeTopicOrders, 1))
+	receive(t, everywhere)
+	if event := receive(t, storeOne); event.Data != uint(1) {
+		t.Errorf("store client got store %v, want 1", event.Data)
+	}
+
+	// Events about no store reach every subscriber
+	hub.UserUpdatedHook([]models.ChangeEvents{{Entity: models.ChangeEntityUser, Action: models.ChangeActionUpdated, EntityID: 3}})
+	receive(t, everywhere)
+	receive(t, storeOne)
+}
+
+func TestHubAddressesTerminals(t *testing.T) {
+	hub := NewHub(cache.Disabled())
+	terminal := hub.Register(Access{Device: 4})
+	otherTerminal := hub.Register(Access{Device: 5})
+	staff := hub.Register(Access{Stores: []uint{1}})
+	for _, client := range []*Client{terminal, otherTerminal, staff} {
+		client.Subscribe(models.RealtimeTopicPrintJobs, models.RealtimeTopicTerminalConfig, models.RealtimeTopicOrders)
+	}
+
+	storeID := uint(1)
+	hub.PrintJobChangedHook(services.PrintJobEvent{
+		Job:     models.PrintJobs{ID: 9, DeviceID: 4, Status: models.PrintJobStatusQueued},
+		StoreID: &storeID,
+	})
+	if event := receive(t, terminal); event.Type != models.RealtimePrintJobUpdated {
+		t.Errorf("terminal got %s, want %s", event.Type, models.RealtimePrintJobUpdated)
+	}
+	receive(t, staff)
+	expectNone(t, otherTerminal)
+
+	hub.TerminalConfigUpdatedHook(models.TerminalConfigResponse{DeviceID: 5, FeatureFlags: map[string]bool{}})
+	event := receive(t, otherTerminal)
+	if data, ok := event.Data.(models.JSONMap); !ok || data["etag"] == "" {
+		t.Errorf("terminal config event carries no ETag: %v", event.Data)
+	}
+	expectNone(t, terminal)
+
+	// Terminals only get the events addressed to them
+	hub.Publish(storeEvent(models.RealtimeTopicOrders, 1))
+	receive(t, staff)
+	expectNone(t, terminal)
+	expectNone(t, otherTerminal)
+}
+
+func TestHubDropsClientsTooFarBehind(t *testing.T) {
+	hub := NewHub(cache.Disabled())
+	slow := hub.Register(Access{})
+	slow.Subscribe(models.RealtimeTopicStock)
+
+	for i := 0; i <= clientBuffer; i++ {
+		hub.Publish(storeEvent(models.RealtimeTopicStock, 1))
+	}
+
+	select {
+	case <-slow.Dropped():
+	default:
+		t.Fatal("client behind by more than its buffer was not dropped")
+	}
+}
+
+func TestHubFansOutOverRedis(t *testing.T) {
+	server := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newHub := func() *Hub {
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { client.Close() })
+		return NewHub(cache.New(ctx, client, cache.Policy{}))
+	}
+	publisher, receiver := newHub(), newHub()
+	go publisher.Run(ctx)
+	go receiver.Run(ctx)
+
+	// Wait for both instances to subscribe so the event isn't published before they listen
+	deadline := time.Now().Add(eventTimeout)
+	for server.PubSubNumSub(cache.EventsChannel)[cache.EventsChannel] < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("hubs did not subscribe to the events channel")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	local := publisher.Register(Access{})
+	remote := receiver.Register(Access{Stores: []uint{1}})
+	remoteOtherStore := receiver.Register(Access{Stores: []uint{2}})
+	for _, client := range []*Client{local, remote, remoteOtherStore} {
+		client.Subscribe(models.RealtimeTopicOrders)
+	}
+
+	publisher.Publish(storeEvent(models.RealtimeTopicOrders, 1))
+
+	event := receive(t, remote)
+	if event.Type != "test" || event.Topic != models.RealtimeTopicOrders {
+		t.Errorf("relayed event is %s on %s", event.Type, event.Topic)
+	}
+	if event.Store == nil || *event.Store != 1 {
+		t.Errorf("relayed event lost its store: %v", event.Store)
+	}
+	receive(t, local)
+
+	// The publisher skips its own events coming back from Redis, and the store scope still
+	// applies on the receiving instance
+	time.Sleep(100 * time.Millisecond)
+	expectNone(t, local)
+	expectNone(t, remoteOtherStore)
+}
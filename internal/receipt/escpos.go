@@ -0,0 +1,36 @@
+package receipt
+
+import "bytes"
+
+// ESC/POS commands understood by thermal receipt printers
+var (
+	escposInit    = []byte{0x1b, '@'}
+	escposBoldOn  = []byte{0x1b, 'E', 1}
+	escposBoldOff = []byte{0x1b, 'E', 0}
+	// Feed a few lines so the receipt clears the cutter, then cut leaving a hinge
+	escposFeedCut = []byte{0x1d, 'V', 66, 3}
+)
+
+// ESCPOS renders laid-out lines as ESC/POS commands. Printers default to an ASCII-compatible
+// code page, so other characters are printed as "?".
+func ESCPOS(lines []Line) []byte {
+	var b bytes.Buffer
+	b.Write(escposInit)
+	for _, line := range lines {
+		if line.Bold {
+			b.Write(escposBoldOn)
+		}
+		for _, r := range line.Text {
+			if r < 0x20 || r > 0x7e {
+				r = '?'
+			}
+			b.WriteByte(byte(r))
+		}
+		if line.Bold {
+			b.Write(escposBoldOff)
+		}
+		b.WriteByte('\n')
+	}
+	b.Write(escposFeedCut)
+	return b.Bytes()
+}
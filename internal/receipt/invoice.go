@@ -0,0 +1,278 @@
+package receipt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// InvoiceWidth is the width in characters invoices and quotes are laid out at, which fits across
+// an A4 page
+const InvoiceWidth = 90
+
+// Invoice is what an invoice shows. Amounts are in minor currency units.
+type Invoice struct {
+	Number     string
+	Status     string
+	IssuedAt   time.Time
+	DueAt      time.Time
+	Seller     Party
+	Buyer      Party
+	Lines      []InvoiceLine
+	Subtotal   int64
+	Discount   int64
+	Taxes      []Tax
+	Total      int64
+	Payments   []InvoicePayment
+	AmountPaid int64
+	Balance    int64
+	Note       string
+	Terms      string
+}
+
+// Party is the seller or buyer of an invoice or quote. Address may span several lines.
+type Party struct {
+	Name    string
+	Company string
+	Address string
+	TaxID   string
+	Email   string
+	Phone   string
+}
+
+// InvoiceLine is a line of an invoice or quote. TaxRate is in basis points; Total is before tax.
+type InvoiceLine struct {
+	Description string
+	SKU         string
+	Quantity    int64
+	UnitPrice   int64
+	Discount    int64
+	TaxRate     int64
+	Total       int64
+}
+
+// InvoicePayment is a payment towards an invoice
+type InvoicePayment struct {
+	Date      time.Time
+	Method    string
+	Reference string
+	Amount    int64
+}
+
+// Widths of the numeric columns of invoice lines; the description takes the rest of the line
+const (
+	invoiceQtyWidth    = 6
+	invoicePriceWidth  = 14
+	invoiceDiscWidth   = 12
+	invoiceTaxWidth    = 8
+	invoiceAmountWidth = 15
+)
+
+// invoiceBanners flag invoices that are settled or no longer stand
+var invoiceBanners = map[string]string{
+	"paid": "*** PAID ***",
+	"void": "*** VOID ***",
+}
+
+// party adds the details of an invoice's seller or buyer
+func (l *layout) party(p Party, bold bool) {
+	if p.Company != "" {
+		l.wrap(p.Company, bold)
+		bold = false
+	}
+	l.wrap(p.Name, bold)
+	for _, line := range strings.Split(p.Address, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			l.wrap(line, false)
+		}
+	}
+	if p.TaxID != "" {
+		l.wrap("Tax ID: "+p.TaxID, false)
+	}
+	if p.Email != "" {
+		l.wrap(p.Email, false)
+	}
+	if p.Phone != "" {
+		l.wrap(p.Phone, false)
+	}
+}
+
+// invoiceRow adds a row of the invoice line table, breaking the description over further lines
+func (l *layout) invoiceRow(description string, qty string, price string, discount string, tax string, amount string, bold bool) {
+	descWidth := l.template.Width - invoiceQtyWidth - invoicePriceWidth - invoiceDiscWidth - invoiceTaxWidth - invoiceAmountWidth
+	numbers := fmt.Sprintf("%*s%*s%*s%*s%*s", invoiceQtyWidth, qty, invoicePriceWidth, price, invoiceDiscWidth, discount, invoiceTaxWidth, tax, invoiceAmountWidth, amount)
+
+	wrapped := &layout{template: Template{Width: descWidth - 1}}
+	wrapped.wrap(description, bold)
+	for i, line := range wrapped.lines {
+		text := fmt.Sprintf("%-*s", descWidth, line.Text)
+		if i == 0 {
+			text += numbers
+		}
+		l.add(text, bold)
+	}
+}
+
+// lineTable adds the lines of an invoice or quote as a table
+func (l *layout) lineTable(lines []InvoiceLine) {
+	t := l.template
+	l.invoiceRow("Description", "Qty", "Unit price", "Discount", "Tax", "Amount", true)
+	l.separator()
+	for _, line := range lines {
+		discount := ""
+		if line.Discount > 0 {
+			discount = t.Money(-line.Discount)
+		}
+		l.invoiceRow(line.Description, fmt.Sprint(line.Quantity), t.Money(line.UnitPrice), discount, taxRate(line.TaxRate), t.Money(line.Total), false)
+		if t.ShowSKU && line.SKU != "" {
+			l.invoiceRow("  "+line.SKU, "", "", "", "", "", false)
+		}
+	}
+	l.separator()
+}
+
+// totals adds the subtotal, discount, tax breakdown and total of an invoice or quote
+func (l *layout) totals(subtotal int64, discount int64, taxes []Tax, total int64) {
+	t := l.template
+	l.columns("Subtotal", t.Money(subtotal), false)
+	if discount > 0 {
+		l.columns("Discount", t.Money(-discount), false)
+	}
+	for _, tax := range taxes {
+		label := tax.Name
+		if label == "" {
+			label = "Tax"
+		}
+		label += " " + taxRate(tax.Rate)
+		if tax.Inclusive {
+			label = "incl. " + label
+		}
+		l.columns(label, t.Money(tax.Amount), false)
+	}
+	l.columns("TOTAL", t.Money(total), true)
+}
+
+// paragraphs adds text of several lines under an optional heading, after a blank line
+func (l *layout) paragraphs(heading string, text string) {
+	if text == "" {
+		return
+	}
+	l.add("", false)
+	if heading != "" {
+		l.add(heading, true)
+	}
+	for _, line := range strings.Split(text, "\n") {
+		l.wrap(line, false)
+	}
+}
+
+// LayoutInvoice lays an invoice out as lines of InvoiceWidth, taking currency and SKUs from
+// the receipt template
+func LayoutInvoice(inv Invoice, t Template) []Line {
+	t.Width = InvoiceWidth
+	l := &layout{template: t}
+
+	l.party(inv.Seller, true)
+	l.add("", false)
+
+	l.columns("INVOICE", inv.Number, true)
+	l.columns("Issue date", inv.IssuedAt.Format("2006-01-02"), false)
+	l.columns("Due date", inv.DueAt.Format("2006-01-02"), false)
+	if banner, ok := invoiceBanners[inv.Status]; ok {
+		l.add("", false)
+		l.center(banner, true)
+	}
+	l.add("", false)
+
+	l.add("Bill to", true)
+	l.party(inv.Buyer, false)
+	l.separator()
+
+	l.lineTable(inv.Lines)
+	l.totals(inv.Subtotal, inv.Discount, inv.Taxes, inv.Total)
+
+	if len(inv.Payments) > 0 {
+		l.separator()
+		l.add("Payments", true)
+		for _, payment := range inv.Payments {
+			label := payment.Date.Format("2006-01-02") + "  " + paymentLabel(payment.Method)
+			if payment.Reference != "" {
+				label += " (" + payment.Reference + ")"
+			}
+			l.columns(label, t.Money(payment.Amount), false)
+		}
+		l.columns("Amount paid", t.Money(inv.AmountPaid), false)
+	}
+	l.columns("BALANCE DUE", t.Money(inv.Balance), true)
+
+	l.paragraphs("Terms", inv.Terms)
+	l.paragraphs("", inv.Note)
+	return l.lines
+}
+
+// RenderInvoice lays an invoice out and renders it as an A4 PDF
+func RenderInvoice(inv Invoice, t Template) []byte {
+	return PagedPDF(LayoutInvoice(inv, t), InvoiceWidth)
+}
+
+// Quote is what a quote shows. Amounts are in minor currency units.
+type Quote struct {
+	Number    string
+	Version   int
+	Status    string
+	Date      time.Time
+	ExpiresAt time.Time
+	Seller    Party
+	Buyer     Party
+	Lines     []InvoiceLine
+	Subtotal  int64
+	Discount  int64
+	Taxes     []Tax
+	Total     int64
+	Note      string
+	Terms     string
+}
+
+// quoteBanners flag quotes that no longer stand
+var quoteBanners = map[string]string{
+	"accepted": "*** ACCEPTED ***",
+	"rejected": "*** REJECTED ***",
+	"expired":  "*** EXPIRED ***",
+}
+
+// LayoutQuote lays a quote out like an invoice, as lines of InvoiceWidth
+func LayoutQuote(q Quote, t Template) []Line {
+	t.Width = InvoiceWidth
+	l := &layout{template: t}
+
+	l.party(q.Seller, true)
+	l.add("", false)
+
+	l.columns("QUOTE", q.Number, true)
+	if q.Version > 1 {
+		l.columns("Revision", fmt.Sprint(q.Version), false)
+	}
+	l.columns("Date", q.Date.Format("2006-01-02"), false)
+	l.columns("Valid until", q.ExpiresAt.Format("2006-01-02"), false)
+	if banner, ok := quoteBanners[q.Status]; ok {
+		l.add("", false)
+		l.center(banner, true)
+	}
+	l.add("", false)
+
+	l.add("Prepared for", true)
+	l.party(q.Buyer, false)
+	l.separator()
+
+	l.lineTable(q.Lines)
+	l.totals(q.Subtotal, q.Discount, q.Taxes, q.Total)
+
+	l.paragraphs("Terms", q.Terms)
+	l.paragraphs("", q.Note)
+	return l.lines
+}
+
+// RenderQuote lays a quote out and renders it as an A4 PDF
+func RenderQuote(q Quote, t Template) []byte {
+	return PagedPDF(LayoutQuote(q, t), InvoiceWidth)
+}
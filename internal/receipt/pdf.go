@@ -0,0 +1,117 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Receipt PDFs are set in Courier, one of the standard PDF fonts every reader has, so nothing
+// needs embedding. Courier glyphs are 0.6 em wide, which makes the page exactly as wide as
+// the receipt's lines.
+const (
+	pdfFontSize = 8.0
+	pdfLeading  = 10.0
+	pdfMargin   = 12.0
+)
+
+// A4 pages in points, and the margin of documents printed on them
+const (
+	a4Width  = 595.0
+	a4Height = 842.0
+	a4Margin = 48.0
+)
+
+// PDF renders laid-out lines as a single-page PDF as long as the receipt, for printing on roll
+// paper. Characters outside Latin-1 are printed as "?".
+func PDF(lines []Line, width int) []byte {
+	pageWidth := float64(width)*pdfFontSize*0.6 + 2*pdfMargin
+	pageHeight := float64(len(lines))*pdfLeading + 2*pdfMargin
+	return writePDF(pageWidth, pageHeight, pdfMargin, pdfFontSize, pdfLeading, [][]Line{lines})
+}
+
+// PagedPDF renders laid-out lines as a PDF of A4 pages, for documents such as invoices. The
+// font is sized so lines of the width fit across the page, and lines flow onto as many pages as
+// they need.
+func PagedPDF(lines []Line, width int) []byte {
+	fontSize := min(10, (a4Width-2*a4Margin)/(float64(width)*0.6))
+	leading := fontSize * 1.25
+	perPage := max(int((a4Height-2*a4Margin)/leading), 1)
+
+	var pages [][]Line
+	for len(lines) > perPage {
+		pages = append(pages, lines[:perPage])
+		lines = lines[perPage:]
+	}
+	pages = append(pages, lines)
+	return writePDF(a4Width, a4Height, a4Margin, fontSize, leading, pages)
+}
+
+// writePDF writes pages of lines set in Courier into a PDF document
+func writePDF(pageWidth float64, pageHeight float64, margin float64, fontSize float64, leading float64, pages [][]Line) []byte {
+	// Objects 1-4 are the catalog, page tree and fonts; each page adds a page and its content
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>",
+	}
+	kids := make([]string, len(pages))
+	for i, page := range pages {
+		var content bytes.Buffer
+		content.WriteString("BT\n")
+		fmt.Fprintf(&content, "%.2f TL\n", leading)
+		fmt.Fprintf(&content, "%.2f %.2f Td\n", margin, pageHeight-margin-fontSize)
+		for _, line := range page {
+			font := "F1"
+			if line.Bold {
+				font = "F2"
+			}
+			fmt.Fprintf(&content, "/%s %.2f Tf (%s) Tj T*\n", font, fontSize, pdfString(line.Text))
+		}
+		content.WriteString("ET\n")
+
+		pageObject := len(objects) + 1
+		kids[i] = fmt.Sprintf("%d 0 R", pageObject)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, pageObject+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+// pdfString escapes text for a PDF string literal, encoding it as Latin-1, which matches
+// WinAnsi for the characters receipts use
+func pdfString(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r <= 0x7e:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,334 @@
+// Package receipt lays out order receipts, invoices and quotes for printing. A receipt is laid
+// out once as lines of monospaced text sized for a receipt printer, then rendered as PDF,
+// ESC/POS commands or plain text, so every format prints the same receipt. Invoices and quotes
+// are laid out the same way, wide enough for A4 pages.
+package receipt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Format is an output format of a receipt
+type Format string
+
+const (
+	FormatPDF    Format = "pdf"
+	FormatESCPOS Format = "escpos"
+	FormatText   Format = "text"
+)
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatPDF:
+		return "application/pdf"
+	case FormatESCPOS:
+		return "application/octet-stream"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Extension returns the file extension of the format
+func (f Format) Extension() string {
+	switch f {
+	case FormatPDF:
+		return "pdf"
+	case FormatESCPOS:
+		return "bin"
+	default:
+		return "txt"
+	}
+}
+
+// TemplateSettingKey is the business setting holding the receipt template, so stores and
+// locations can each have their own
+const TemplateSettingKey = "receipt.template"
+
+// Template configures how receipts look. Width is in characters per line; 42 fits 80 mm paper
+// and 32 fits 58 mm. Amounts are shown with Decimals digits after the point and prefixed with
+// Currency, e.g. "$".
+type Template struct {
+	Header   []string `json:"header"`
+	Footer   []string `json:"footer"`
+	Width    int      `json:"width"`
+	Currency string   `json:"currency"`
+	Decimals int      `json:"decimals"`
+	ShowSKU  bool     `json:"show_sku"`
+}
+
+// Bounds of the template width
+const (
+	MinWidth = 24
+	MaxWidth = 64
+)
+
+// DefaultTemplate is used where no receipt template is configured
+func DefaultTemplate() Template {
+	return Template{
+		Footer:   []string{"Thank you!"},
+		Width:    42,
+		Decimals: 2,
+	}
+}
+
+// ParseTemplate reads a template from a setting value; fields it leaves out keep their default
+func ParseTemplate(value interface{}) (Template, error) {
+	template := DefaultTemplate()
+	data, err := json.Marshal(value)
+	if err != nil {
+		return Template{}, err
+	}
+	if err := json.Unmarshal(data, &template); err != nil {
+		return Template{}, fmt.Errorf("invalid receipt template: %v", err)
+	}
+	if template.Width < MinWidth || template.Width > MaxWidth {
+		return Template{}, fmt.Errorf("invalid receipt template: width must be between %d and %d", MinWidth, MaxWidth)
+	}
+	if template.Decimals < 0 || template.Decimals > 4 {
+		return Template{}, fmt.Errorf("invalid receipt template: decimals must be between 0 and 4")
+	}
+	return template, nil
+}
+
+// Receipt is what an order's receipt shows. Amounts are in minor currency units.
+type Receipt struct {
+	Number   string
+	Status   string
+	Date     time.Time
+	Cashier  string
+	Items    []Item
+	Subtotal int64
+	Discount int64
+	Taxes    []Tax
+	Total    int64
+	Payments []Payment
+}
+
+// Item is a line of a receipt
+type Item struct {
+	Name      string
+	SKU       string
+	Quantity  int64
+	UnitPrice int64
+	Discount  int64
+}
+
+// Tax is the tax charged at one rate, in basis points. Tax included in prices is listed but
+// not added to the total.
+type Tax struct {
+	Name      string
+	Rate      int64
+	Inclusive bool
+	Amount    int64
+}
+
+// Payment is a payment towards the order. Tendered and Change are set for cash.
+type Payment struct {
+	Method   string
+	Amount   int64
+	Tendered int64
+	Change   int64
+	Refunded int64
+}
+
+// Line is a line of a laid-out receipt, padded to the template width
+type Line struct {
+	Text string
+	Bold bool
+}
+
+// layout builds the lines of a receipt
+type layout struct {
+	template Template
+	lines    []Line
+}
+
+func (l *layout) add(text string, bold bool) {
+	l.lines = append(l.lines, Line{Text: text, Bold: bold})
+}
+
+// wrap adds text left-aligned, broken between words over as many lines as it needs. Words
+// longer than a line are broken where the line ends.
+func (l *layout) wrap(text string, bold bool) {
+	width := l.template.Width
+	runes := []rune(text)
+	for len(runes) > width {
+		cut := width
+		if space := strings.LastIndex(string(runes[:width+1]), " "); space > 0 {
+			cut = utf8.RuneCountInString(string(runes[:width+1])[:space])
+		}
+		l.add(strings.TrimRight(string(runes[:cut]), " "), bold)
+		runes = []rune(strings.TrimLeft(string(runes[cut:]), " "))
+	}
+	l.add(string(runes), bold)
+}
+
+// center adds text centered on the line
+func (l *layout) center(text string, bold bool) {
+	width := l.template.Width
+	runes := []rune(text)
+	if len(runes) > width {
+		l.wrap(text, bold)
+		return
+	}
+	l.add(strings.Repeat(" ", (width-len(runes))/2)+text, bold)
+}
+
+// columns adds a label on the left and an amount on the right, cutting the label short when
+// both don't fit
+func (l *layout) columns(left string, right string, bold bool) {
+	width := l.template.Width
+	room := width - utf8.RuneCountInString(right) - 1
+	runes := []rune(left)
+	if len(runes) > room {
+		runes = runes[:max(room, 0)]
+	}
+	gap := width - len(runes) - utf8.RuneCountInString(right)
+	l.add(string(runes)+strings.Repeat(" ", max(gap, 1))+right, bold)
+}
+
+func (l *layout) separator() {
+	l.add(strings.Repeat("-", l.template.Width), false)
+}
+
+// Money formats an amount in minor units, e.g. 1250 as "$12.50"
+func (t Template) Money(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	if t.Decimals == 0 {
+		return sign + t.Currency + strconv.FormatInt(amount, 10)
+	}
+	divisor := int64(1)
+	for range t.Decimals {
+		divisor *= 10
+	}
+	return fmt.Sprintf("%s%s%d.%0*d", sign, t.Currency, amount/divisor, t.Decimals, amount%divisor)
+}
+
+// taxRate formats a rate in basis points, e.g. 825 as "8.25%"
+func taxRate(rate int64) string {
+	return strconv.FormatFloat(float64(rate)/100, 'f', -1, 64) + "%"
+}
+
+// Layout lays a receipt out as lines of the template width
+func Layout(r Receipt, t Template) []Line {
+	l := &layout{template: t}
+
+	for i, header := range t.Header {
+		l.center(header, i == 0)
+	}
+	if len(t.Header) > 0 {
+		l.add("", false)
+	}
+
+	l.columns("Receipt", r.Number, true)
+	l.columns("Date", r.Date.Format("2006-01-02 15:04"), false)
+	if r.Cashier != "" {
+		l.columns("Served by", r.Cashier, false)
+	}
+	if banner, ok := statusBanners[r.Status]; ok {
+		l.add("", false)
+		l.center(banner, true)
+	}
+	l.separator()
+
+	for _, item := range r.Items {
+		l.wrap(item.Name, false)
+		if t.ShowSKU && item.SKU != "" {
+			l.wrap("  "+item.SKU, false)
+		}
+		l.columns(fmt.Sprintf("  %d x %s", item.Quantity, t.Money(item.UnitPrice)), t.Money(item.Quantity*item.UnitPrice), false)
+		if item.Discount > 0 {
+			l.columns("  Discount", t.Money(-item.Discount), false)
+		}
+	}
+	l.separator()
+
+	l.columns("Subtotal", t.Money(r.Subtotal), false)
+	if r.Discount > 0 {
+		l.columns("Discount", t.Money(-r.Discount), false)
+	}
+	for _, tax := range r.Taxes {
+		label := tax.Name
+		if label == "" {
+			label = "Tax"
+		}
+		label += " " + taxRate(tax.Rate)
+		if tax.Inclusive {
+			label = "incl. " + label
+		}
+		l.columns(label, t.Money(tax.Amount), false)
+	}
+	l.columns("TOTAL", t.Money(r.Total), true)
+
+	if len(r.Payments) > 0 {
+		l.separator()
+		for _, payment := range r.Payments {
+			l.columns(paymentLabel(payment.Method), t.Money(payment.Amount), false)
+			if payment.Change > 0 {
+				l.columns("  Tendered", t.Money(payment.Tendered), false)
+				l.columns("  Change", t.Money(payment.Change), false)
+			}
+			if payment.Refunded > 0 {
+				l.columns("  Refunded", t.Money(-payment.Refunded), false)
+			}
+		}
+	}
+
+	if len(t.Footer) > 0 {
+		l.add("", false)
+		for _, footer := range t.Footer {
+			l.center(footer, false)
+		}
+	}
+	return l.lines
+}
+
+// statusBanners flag receipts of orders that no longer stand
+var statusBanners = map[string]string{
+	"cancelled": "*** CANCELLED ***",
+	"refunded":  "*** REFUNDED ***",
+}
+
+// paymentLabel turns a payment method into a label, e.g. "gift_card" into "Gift card"
+func paymentLabel(method string) string {
+	label := strings.ReplaceAll(method, "_", " ")
+	if label == "" {
+		return label
+	}
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+// Render lays a receipt out and renders it in a format
+func Render(r Receipt, t Template, format Format) ([]byte, error) {
+	lines := Layout(r, t)
+	switch format {
+	case FormatPDF:
+		return PDF(lines, t.Width), nil
+	case FormatESCPOS:
+		return ESCPOS(lines), nil
+	case FormatText:
+		return Text(lines), nil
+	default:
+		return nil, fmt.Errorf("unsupported receipt format: %s", format)
+	}
+}
+
+// Text renders laid-out lines as plain text
+func Text(lines []Line) []byte {
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(strings.TrimRight(line.Text, " "))
+		b.WriteByte('\n')
+	}
+	return []byte(b.String())
+}
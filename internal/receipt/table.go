@@ -0,0 +1,111 @@
+package receipt
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Widest a column of a table gets; longer values are cut short
+const tableColumnWidth = 30
+
+// Table is a titled grid of values, such as the rows of a report
+type Table struct {
+	Title    string
+	Subtitle string
+	Header   []string
+	Rows     [][]string
+}
+
+// cell pads or cuts a value to the column width, right-aligning numbers
+func cell(value string, width int, numeric bool) string {
+	runes := []rune(value)
+	if len(runes) > width {
+		return string(runes[:width-1]) + "~"
+	}
+	if numeric {
+		return fmt.Sprintf("%*s", width, value)
+	}
+	return fmt.Sprintf("%-*s", width, value)
+}
+
+// isNumber reports whether a value reads as a number, e.g. "-12.50"
+func isNumber(value string) bool {
+	value = strings.TrimPrefix(value, "-")
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if (r < '0' || r > '9') && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// LayoutTable lays a table out with a column per header, each as wide as its widest value.
+// Columns holding only numbers are right-aligned. It returns the lines and their width.
+func LayoutTable(t Table) ([]Line, int) {
+	widths := make([]int, len(t.Header))
+	numeric := make([]bool, len(t.Header))
+	for i, header := range t.Header {
+		widths[i] = min(utf8.RuneCountInString(header), tableColumnWidth)
+		numeric[i] = len(t.Rows) > 0
+	}
+	for _, row := range t.Rows {
+		for i := range widths {
+			value := ""
+			if i < len(row) {
+				value = row[i]
+			}
+			widths[i] = min(max(widths[i], utf8.RuneCountInString(value)), tableColumnWidth)
+			if value != "" && !isNumber(value) {
+				numeric[i] = false
+			}
+		}
+	}
+
+	width := 0
+	for _, w := range widths {
+		width += w + 2
+	}
+	width = max(width-2, utf8.RuneCountInString(t.Title), utf8.RuneCountInString(t.Subtitle), 1)
+	l := &layout{template: Template{Width: width}}
+
+	row := func(values []string, bold bool) {
+		cells := make([]string, len(widths))
+		for i, w := range widths {
+			value := ""
+			if i < len(values) {
+				value = values[i]
+			}
+			cells[i] = cell(value, w, numeric[i] && !bold)
+		}
+		l.add(strings.TrimRight(strings.Join(cells, "  "), " "), bold)
+	}
+
+	if t.Title != "" {
+		l.add(t.Title, true)
+	}
+	if t.Subtitle != "" {
+		l.add(t.Subtitle, false)
+	}
+	if t.Title != "" || t.Subtitle != "" {
+		l.add("", false)
+	}
+	row(t.Header, true)
+	l.separator()
+	for _, values := range t.Rows {
+		row(values, false)
+	}
+	if len(t.Rows) == 0 {
+		l.add("No rows", false)
+	}
+	return l.lines, width
+}
+
+// RenderTable lays a table out and renders it as an A4 PDF
+func RenderTable(t Table) []byte {
+	lines, width := LayoutTable(t)
+	return PagedPDF(lines, width)
+}
@@ -0,0 +1,95 @@
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// healthService is the prefix of the health check methods, which need no token so probes can
+// call them
+const healthService = "/grpc.health.v1.Health/"
+
+// serverCodes are the status codes of calls that failed on our side
+var serverCodes = map[codes.Code]bool{
+	codes.Unknown:          true,
+	codes.DeadlineExceeded: true,
+	codes.Unimplemented:    true,
+	codes.Internal:         true,
+	codes.Unavailable:      true,
+	codes.DataLoss:         true,
+}
+
+// internalError logs an unexpected error and returns one that doesn't leak it to the caller
+func internalError(ctx context.Context, err error) error {
+	slog.ErrorContext(ctx, "gRPC call failed", "error", err)
+	return status.Error(codes.Internal, "internal server error")
+}
+
+// Authenticate rejects calls that don't carry the token as "authorization: Bearer <token>"
+// metadata. Health checks need no token.
+func Authenticate(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if strings.HasPrefix(info.FullMethod, healthService) {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+		}
+		sent, ok := strings.CutPrefix(values[0], "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Logger logs each call once it is served, with its method, status code and latency. Server
+// errors are logged as errors and the caller's errors as warnings, as RequestLogger does.
+func Logger() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		started := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		level := slog.LevelInfo
+		switch {
+		case serverCodes[code]:
+			level = slog.LevelError
+		case code != codes.OK:
+			level = slog.LevelWarn
+		}
+		slog.Log(ctx, level, "Call served",
+			"method", info.FullMethod,
+			"code", code.String(),
+			"latency_ms", float64(time.Since(started).Microseconds())/1000,
+		)
+		return resp, err
+	}
+}
+
+// Recover turns a panicking call into an Internal error instead of crashing the server
+func Recover() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				slog.ErrorContext(ctx, "gRPC call panicked", "method", info.FullMethod, "panic", fmt.Sprint(recovered), "stack", string(debug.Stack()))
+				err = status.Error(codes.Internal, "internal server error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
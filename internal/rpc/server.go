@@ -0,0 +1,311 @@
+// Package rpc serves the gRPC API of api/blade/v1 to internal services on a listener of its own.
+// Calls are answered by the services behind the REST API, so both stay consistent, without the
+// cookies, envelope and per-tenant middleware REST clients go through.
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	bladev1 "github.com/Aebroyx/the-blade-api/api/blade/v1"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+// Server implements the BladeService with the services of the REST API
+type Server struct {
+	bladev1.UnimplementedBladeServiceServer
+
+	userService      *services.UserService
+	productService   *services.ProductService
+	inventoryService *services.InventoryService
+}
+
+// NewServer returns a gRPC server of the BladeService and the standard health service. Calls
+// other than health checks must carry the token; each call is logged, and traced when traced is
+// set, continuing the trace of the caller's traceparent metadata.
+func NewServer(token string, traced bool, userService *services.UserService, productService *services.ProductService, inventoryService *services.InventoryService) *grpc.Server {
+	options := []grpc.ServerOption{grpc.ChainUnaryInterceptor(
+		Logger(),
+		Recover(),
+		Authenticate(token),
+	)}
+	if traced {
+		options = append(options, grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	}
+	server := grpc.NewServer(options...)
+	bladev1.RegisterBladeServiceServer(server, &Server{
+		userService:      userService,
+		productService:   productService,
+		inventoryService: inventoryService,
+	})
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	return server
+}
+
+// ListenAndServe serves gRPC on addr until the server is stopped
+func ListenAndServe(addr string, server *grpc.Server) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return server.Serve(listener)
+}
+
+// GetUser returns a user by ID, username or email
+func (s *Server) GetUser(ctx context.Context, req *bladev1.GetUserRequest) (*bladev1.User, error) {
+	var user *models.Users
+	var err error
+	switch lookup := req.GetLookup().(type) {
+	case *bladev1.GetUserRequest_Id:
+		var result interface{}
+		result, err = s.userService.GetUserById(uint(lookup.Id), nil)
+		if err == nil {
+			found := result.(models.Users)
+			user = &found
+		}
+	case *bladev1.GetUserRequest_Username:
+		user, err = s.userService.FindUser(ctx, lookup.Username, "")
+	case *bladev1.GetUserRequest_Email:
+		user, err = s.userService.FindUser(ctx, "", lookup.Email)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "id, username or email is required")
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	return userMessage(user), nil
+}
+
+// IntrospectToken reports whether an access token is valid and whose it is
+func (s *Server) IntrospectToken(ctx context.Context, req *bladev1.IntrospectTokenRequest) (*bladev1.IntrospectTokenResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	introspection, err := s.userService.IntrospectToken(ctx, req.GetToken())
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+
+	resp := &bladev1.IntrospectTokenResponse{
+		Active: introspection.Active,
+		Reason: introspection.Reason,
+	}
+	if introspection.Active {
+		resp.User = userMessage(introspection.User)
+		resp.IssuedAt = timestamp(introspection.IssuedAt)
+		resp.ExpiresAt = timestamp(introspection.ExpiresAt)
+	}
+	return resp, nil
+}
+
+// GetProduct returns a product by ID
+func (s *Server) GetProduct(ctx context.Context, req *bladev1.GetProductRequest) (*bladev1.Product, error) {
+	product, err := s.productService.GetProductById(uint(req.GetId()))
+	if err != nil {
+		if err.Error() == "product not found" {
+			return nil, status.Error(codes.NotFound, "product not found")
+		}
+		return nil, internalError(ctx, err)
+	}
+
+	return &bladev1.Product{
+		Id:           uint64(product.ID),
+		Name:         product.Name,
+		Sku:          product.SKU,
+		Barcode:      product.Barcode,
+		Description:  product.Description,
+		Price:        product.Price,
+		Cost:         product.Cost,
+		CategoryId:   optionalID(product.CategoryID),
+		TaxClassId:   optionalID(product.TaxClassID),
+		Status:       product.Status,
+		Unit:         product.Unit,
+		Bundle:       product.Bundle,
+		GiftCard:     product.GiftCard,
+		Tracking:     product.Tracking,
+		ReorderPoint: product.ReorderPoint,
+		ImageUrl:     product.ImageURL,
+		CreatedAt:    timestamp(product.CreatedAt),
+		UpdatedAt:    timestamp(product.UpdatedAt),
+	}, nil
+}
+
+// LookupBarcode resolves a scanned barcode to a product or variant and its stock
+func (s *Server) LookupBarcode(ctx context.Context, req *bladev1.LookupBarcodeRequest) (*bladev1.LookupBarcodeResponse, error) {
+	if req.GetBarcode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "barcode is required")
+	}
+
+	result, err := s.productService.LookupBarcode(ctx, req.GetBarcode())
+	if err != nil {
+		if err.Error() == "product not found" {
+			return nil, status.Error(codes.NotFound, "product not found")
+		}
+		return nil, internalError(ctx, err)
+	}
+
+	return &bladev1.LookupBarcodeResponse{
+		ProductId:    uint64(result.ProductID),
+		VariantId:    optionalID(result.VariantID),
+		Name:         result.Name,
+		VariantTitle: result.VariantTitle,
+		Sku:          result.SKU,
+		Barcode:      result.Barcode,
+		Price:        result.Price,
+		Status:       result.Status,
+		Stock:        result.Stock,
+	}, nil
+}
+
+// ListStockLevels lists stock levels per product, variant and store
+func (s *Server) ListStockLevels(ctx context.Context, req *bladev1.ListStockLevelsRequest) (*bladev1.ListStockLevelsResponse, error) {
+	params := pagination.QueryParams{
+		Page:     int(req.GetPage()),
+		PageSize: int(req.GetPageSize()),
+		Search:   req.GetSearch(),
+		Filters:  map[string]interface{}{},
+	}
+	if params.Page == 0 {
+		params.Page = 1
+	}
+	if params.PageSize == 0 {
+		params.PageSize = 10
+	}
+	if params.Page < 0 || params.PageSize < 0 {
+		return nil, status.Error(codes.InvalidArgument, "page and page_size must be positive")
+	}
+	if req.ProductId != nil {
+		params.Filters["product_id"] = req.GetProductId()
+	}
+	if req.VariantId != nil {
+		params.Filters["variant_id"] = req.GetVariantId()
+	}
+	if req.StoreId != nil {
+		params.Filters["store_id"] = req.GetStoreId()
+	}
+	if err := params.BindContext(ctx); err != nil {
+		if exceeded, ok := limits.AsExceeded(err); ok {
+			return nil, status.Error(codes.InvalidArgument, exceeded.Error())
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	response, err := s.inventoryService.GetStockLevels(params)
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+
+	rows, _ := response.Data.([]map[string]interface{})
+	levels := make([]*bladev1.StockLevel, 0, len(rows))
+	for _, row := range rows {
+		levels = append(levels, stockLevelMessage(row))
+	}
+	return &bladev1.ListStockLevelsResponse{
+		StockLevels: levels,
+		Total:       response.Total,
+		Page:        int32(response.Page),
+		PageSize:    int32(response.PageSize),
+		TotalPages:  int32(response.TotalPages),
+	}, nil
+}
+
+// userMessage converts a user, leaving out everything but its profile and status
+func userMessage(user *models.Users) *bladev1.User {
+	return &bladev1.User{
+		Id:                 uint64(user.ID),
+		Username:           user.Username,
+		Email:              user.Email,
+		Name:               user.Name,
+		Role:               user.Role,
+		Status:             user.Status,
+		MustChangePassword: user.MustChangePassword,
+		CreatedAt:          timestamp(user.CreatedAt),
+		UpdatedAt:          timestamp(user.UpdatedAt),
+	}
+}
+
+// stockLevelMessage converts a row of the stock level listing
+func stockLevelMessage(row map[string]interface{}) *bladev1.StockLevel {
+	level := &bladev1.StockLevel{
+		Id:           uint64(int64Of(row["id"])),
+		ProductId:    uint64(int64Of(row["product_id"])),
+		Quantity:     int64Of(row["quantity"]),
+		ProductName:  stringOf(row["product_name"]),
+		Sku:          stringOf(row["sku"]),
+		VariantTitle: optionalString(row["variant_title"]),
+	}
+	if row["variant_id"] != nil {
+		id := uint64(int64Of(row["variant_id"]))
+		level.VariantId = &id
+	}
+	if row["store_id"] != nil {
+		id := uint64(int64Of(row["store_id"]))
+		level.StoreId = &id
+	}
+	if updatedAt, ok := row["updated_at"].(time.Time); ok {
+		level.UpdatedAt = timestamp(updatedAt)
+	}
+	return level
+}
+
+// int64Of converts an integer column scanned into a map
+func int64Of(value interface{}) int64 {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case int32:
+		return int64(v)
+	case int:
+		return int64(v)
+	}
+	return 0
+}
+
+// stringOf converts a text column scanned into a map
+func stringOf(value interface{}) string {
+	s, _ := value.(string)
+	return s
+}
+
+// optionalString converts a nullable text column scanned into a map
+func optionalString(value interface{}) *string {
+	s, ok := value.(string)
+	if !ok {
+		return nil
+	}
+	return &s
+}
+
+// optionalID converts a nullable foreign key
+func optionalID(id *uint) *uint64 {
+	if id == nil {
+		return nil
+	}
+	value := uint64(*id)
+	return &value
+}
+
+// timestamp converts a time, leaving zero times unset
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
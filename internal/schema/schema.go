@@ -0,0 +1,135 @@
+// Package schema versions response shapes per route, so a breaking change to a response can be
+// rolled out client by client: handlers produce the route's current shape and clients pinned to
+// an older version get it reshaped by the route's downgrades.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Header carries the response schema version: clients send it to pin a version and
+// every response reports the version it was shaped with
+const Header = "X-API-Schema-Version"
+
+// Downgrade reshapes the decoded "data" of a response from one version to the version
+// before it. It receives the data as decoded by encoding/json with UseNumber.
+type Downgrade func(data any) any
+
+// Endpoint describes the schema versions of one route
+type Endpoint struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Current is the version handlers produce
+	Current int `json:"current"`
+	// Default is served to clients that do not pin a version. It stays behind Current while
+	// a breaking change is rolled out and is raised once clients have moved over.
+	Default int `json:"default"`
+	// Oldest is the oldest version still served
+	Oldest int `json:"oldest"`
+	// downgrades[v] turns the shape of version v+1 into version v
+	downgrades map[int]Downgrade
+}
+
+// endpoints holds every route with more than one schema version, keyed by method and route path.
+// Routes not listed here have a single version, 1.
+var endpoints = map[string]*Endpoint{}
+
+// Register adds a route's versions to the registry; call it before the router starts.
+// path is the route path as registered with gin, including its API version, e.g.
+// "/api/v1/user/:id": a breaking change is rolled out within an API version, and a new API
+// version starts over at version 1. downgrades must cover every version still served, from
+// the oldest to current-1.
+func Register(method string, path string, current int, defaultVersion int, downgrades map[int]Downgrade) {
+	oldest := current
+	for version := current - 1; version >= 1; version-- {
+		if _, ok := downgrades[version]; !ok {
+			break
+		}
+		oldest = version
+	}
+	if defaultVersion < oldest || defaultVersion > current {
+		panic(fmt.Sprintf("schema: default version %d of %s %s is not served", defaultVersion, method, path))
+	}
+
+	endpoints[key(method, path)] = &Endpoint{
+		Method:     method,
+		Path:       path,
+		Current:    current,
+		Default:    defaultVersion,
+		Oldest:     oldest,
+		downgrades: downgrades,
+	}
+}
+
+func key(method string, path string) string {
+	return method + " " + path
+}
+
+// single describes a route without registered versions
+func single(method string, path string) *Endpoint {
+	return &Endpoint{Method: method, Path: path, Current: 1, Default: 1, Oldest: 1}
+}
+
+// Lookup returns the schema versions of a route
+func Lookup(method string, path string) *Endpoint {
+	if endpoint, ok := endpoints[key(method, path)]; ok {
+		return endpoint
+	}
+	return single(method, path)
+}
+
+// Endpoints lists every route with more than one schema version
+func Endpoints() []Endpoint {
+	result := make([]Endpoint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		result = append(result, *endpoint)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Path != result[j].Path {
+			return result[i].Path < result[j].Path
+		}
+		return result[i].Method < result[j].Method
+	})
+	return result
+}
+
+// Resolve picks the version to serve for the requested header value. An empty value
+// resolves to the default version; anything else must be a version the route still serves.
+func (e *Endpoint) Resolve(requested string) (int, error) {
+	if requested == "" {
+		return e.Default, nil
+	}
+	version, err := strconv.Atoi(requested)
+	if err != nil || version < e.Oldest || version > e.Current {
+		return 0, fmt.Errorf("unsupported schema version %q", requested)
+	}
+	return version, nil
+}
+
+// Shape rewrites a JSON response body produced at the current version into the given
+// version. Only the "data" member of the response envelope is reshaped. Object keys are
+// written in sorted order, so the same data always produces the same bytes.
+func (e *Endpoint) Shape(body []byte, version int) ([]byte, error) {
+	if version == e.Current {
+		return body, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var envelope map[string]any
+	if err := decoder.Decode(&envelope); err != nil {
+		return nil, err
+	}
+
+	if data, ok := envelope["data"]; ok {
+		for v := e.Current - 1; v >= version; v-- {
+			data = e.downgrades[v](data)
+		}
+		envelope["data"] = data
+	}
+	return json.Marshal(envelope)
+}
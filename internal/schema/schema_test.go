@@ -0,0 +1,89 @@
+package schema
+
+import (
+	"net/http"
+	"testing"
+)
+
+// renameTotal is the downgrade of a test route whose version 2 renamed "amount" to "total"
+func renameTotal(data any) any {
+	object, ok := data.(map[string]any)
+	if !ok {
+		return data
+	}
+	object["amount"] = object["total"]
+	delete(object, "total")
+	return object
+}
+
+func TestRegister(t *testing.T) {
+	Register(http.MethodGet, "/api/v1/test/register", 3, 2, map[int]Downgrade{2: renameTotal, 1: renameTotal})
+	t.Cleanup(func() { delete(endpoints, key(http.MethodGet, "/api/v1/test/register")) })
+
+	endpoint := Lookup(http.MethodGet, "/api/v1/test/register")
+	if endpoint.Current != 3 || endpoint.Default != 2 || endpoint.Oldest != 1 {
+		t.Errorf("registered versions are %d/%d/%d, want current 3, default 2, oldest 1", endpoint.Current, endpoint.Default, endpoint.Oldest)
+	}
+
+	// Other API versions of the route have versions of their own
+	if other := Lookup(http.MethodGet, "/api/v2/test/register"); other.Current != 1 || other.Default != 1 || other.Oldest != 1 {
+		t.Errorf("unregistered route has versions %d/%d/%d, want 1", other.Current, other.Default, other.Oldest)
+	}
+}
+
+func TestRegisterRejectsUnservedDefault(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a default version without a downgrade did not panic")
+		}
+	}()
+	Register(http.MethodGet, "/api/v1/test/unserved", 3, 1, map[int]Downgrade{2: renameTotal})
+}
+
+func TestResolve(t *testing.T) {
+	endpoint := &Endpoint{Current: 3, Default: 2, Oldest: 2}
+
+	tests := []struct {
+		requested string
+		want      int
+		wantErr   bool
+	}{
+		{requested: "", want: 2},
+		{requested: "2", want: 2},
+		{requested: "3", want: 3},
+		{requested: "1", wantErr: true},
+		{requested: "4", wantErr: true},
+		{requested: "latest", wantErr: true},
+	}
+	for _, tt := range tests {
+		version, err := endpoint.Resolve(tt.requested)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("resolved %q to %d, want an error", tt.requested, version)
+			}
+			continue
+		}
+		if err != nil || version != tt.want {
+			t.Errorf("resolved %q to %d, %v, want %d", tt.requested, version, err, tt.want)
+		}
+	}
+}
+
+func TestShape(t *testing.T) {
+	endpoint := &Endpoint{Current: 2, Default: 1, Oldest: 1, downgrades: map[int]Downgrade{1: renameTotal}}
+	body := []byte(`{"success":true,"message":"OK","data":{"total":1050,"id":7}}`)
+
+	shaped, err := endpoint.Shape(body, 1)
+	if err != nil {
+		t.Fatalf("failed to shape: %v", err)
+	}
+	// Keys are sorted and numbers kept as written, so the same data always gives the same bytes
+	if want := `{"data":{"amount":1050,"id":7},"message":"OK","success":true}`; string(shaped) != want {
+		t.Errorf("shaped %s, want %s", shaped, want)
+	}
+
+	current, err := endpoint.Shape(body, 2)
+	if err != nil || string(current) != string(body) {
+		t.Errorf("current version was reshaped to %s, %v", current, err)
+	}
+}
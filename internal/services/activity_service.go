@@ -0,0 +1,150 @@
+package services
+
+import (
+	"log/slog"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// maxUserAgentLength matches the size of the user_agent column
+const maxUserAgentLength = 255
+
+type ActivityService struct {
+	db *gorm.DB
+}
+
+func NewActivityService(db *gorm.DB) *ActivityService {
+	return &ActivityService{db: db}
+}
+
+// Record stores an activity event for a user.
+// Failures are logged rather than returned so tracking never breaks the action being tracked.
+func (s *ActivityService) Record(userID uint, eventType string, actor models.ActivityActor, description string, metadata models.JSONMap) {
+	if err := s.db.Create(newActivity(userID, eventType, actor, description, metadata)).Error; err != nil {
+		slog.Error("Failed to record activity", "event", eventType, "user_id", userID, "error", err)
+	}
+}
+
+// RecordTx stores an activity event as part of an existing transaction
+func (s *ActivityService) RecordTx(tx *gorm.DB, userID uint, eventType string, actor models.ActivityActor, description string, metadata models.JSONMap) error {
+	return tx.Create(newActivity(userID, eventType, actor, description, metadata)).Error
+}
+
+// newActivity builds an activity record from the actor and event details
+func newActivity(userID uint, eventType string, actor models.ActivityActor, description string, metadata models.JSONMap) *models.UserActivities {
+	if metadata == nil {
+		metadata = models.JSONMap{}
+	}
+
+	userAgent := actor.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	activity := &models.UserActivities{
+		UserID:      userID,
+		EventType:   eventType,
+		Description: description,
+		Metadata:    metadata,
+		IPAddress:   actor.IPAddress,
+		UserAgent:   userAgent,
+	}
+	if actor.UserID != 0 {
+		actorID := actor.UserID
+		activity.ActorID = &actorID
+	}
+	return activity
+}
+
+// GetUserActivity retrieves a user's activity timeline with pagination, event type filters, and date ranges
+func (s *ActivityService) GetUserActivity(userID uint, params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model: &models.UserActivities{},
+		BaseCondition: map[string]interface{}{
+			"user_id": userID,
+		},
+		SearchFields: []string{"description"},
+		FilterFields: map[string]string{
+			"event_type": "event_type",
+			"actor_id":   "actor_id",
+		},
+		DateFields: map[string]pagination.DateField{
+			"created_at": {
+				Start: "created_at",
+				End:   "created_at",
+			},
+		},
+		SortFields: []string{
+			"event_type",
+			"created_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	if params.SortBy == "" {
+		params.SortDesc = true
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// RecordLogin stores a login attempt. Failures are logged rather than returned.
+func (s *ActivityService) RecordLogin(userID *uint, username string, success bool, failureReason string, actor models.ActivityActor) {
+	userAgent := actor.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	if len(username) > 50 {
+		username = username[:50]
+	}
+
+	event := &models.LoginEvents{
+		UserID:        userID,
+		Username:      username,
+		Success:       success,
+		FailureReason: failureReason,
+		IPAddress:     actor.IPAddress,
+		UserAgent:     userAgent,
+	}
+	if err := s.db.Create(event).Error; err != nil {
+		slog.Error("Failed to record login event", "username", username, "error", err)
+	}
+}
+
+// GetUserLogins retrieves a user's login history with pagination, outcome filters, and date ranges
+func (s *ActivityService) GetUserLogins(userID uint, params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model: &models.LoginEvents{},
+		BaseCondition: map[string]interface{}{
+			"user_id": userID,
+		},
+		FilterFields: map[string]string{
+			"success":        "success",
+			"failure_reason": "failure_reason",
+			"ip_address":     "ip_address",
+		},
+		DateFields: map[string]pagination.DateField{
+			"created_at": {
+				Start: "created_at",
+				End:   "created_at",
+			},
+		},
+		SortFields: []string{
+			"success",
+			"created_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	if params.SortBy == "" {
+		params.SortDesc = true
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
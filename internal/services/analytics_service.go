@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// analyticsCacheTTL bounds how long analytics are reused. Sales made since are not counted
+// until the cached result runs out.
+const analyticsCacheTTL = 5 * time.Minute
+
+// Sales analytics defaults
+const (
+	analyticsDefaultRange = 30 * 24 * time.Hour
+	analyticsDefaultLimit = 10
+)
+
+// soldOrdersSQL selects the orders paid in a range with what was refunded of them. Orders
+// count as sold when they were paid, whatever happened to them after.
+const soldOrdersSQL = `WITH sold AS (
+	SELECT o.id, o.status, o.total, o.discount_total, o.tax_total, o.created_by_id, o.paid_at,
+		COALESCE((SELECT SUM(p.refunded_amount) FROM payments p WHERE p.order_id = o.id), 0) AS refunded
+	FROM orders o
+	WHERE o.paid_at >= ? AND o.paid_at < ?
+)
+`
+
+// soldLinesSQL selects the lines of the orders sold in a range, returns taken off. Refunded
+// orders are left out as a whole.
+const soldLinesSQL = soldOrdersSQL + `, lines AS (
+	SELECT oi.product_id, oi.name, oi.sku, oi.quantity - oi.returned_quantity AS quantity, oi.total - oi.refunded_amount AS sales
+	FROM order_items oi
+	JOIN sold ON sold.id = oi.order_id
+	WHERE sold.status <> 'refunded'
+)
+`
+
+// AnalyticsService works out sales analytics with aggregate SQL, caching results for a short while
+type AnalyticsService struct {
+	db    *gorm.DB
+	cache *cache.Cache
+}
+
+func NewAnalyticsService(db *gorm.DB, cache *cache.Cache) *AnalyticsService {
+	return &AnalyticsService{
+		db:    db,
+		cache: cache,
+	}
+}
+
+// GetSalesAnalytics breaks down the sales of a range. Without an end the range runs to the
+// start of the next minute, so dashboards refreshing within a minute share a cached result.
+func (s *AnalyticsService) GetSalesAnalytics(ctx context.Context, query *models.SalesAnalyticsQuery) (*models.SalesAnalyticsResponse, error) {
+	to := time.Now().Truncate(time.Minute).Add(time.Minute)
+	if query.To != nil {
+		to = *query.To
+	}
+	from := to.Add(-analyticsDefaultRange)
+	if query.From != nil {
+		from = *query.From
+	}
+	if !from.Before(to) {
+		return nil, errors.New("invalid date range")
+	}
+	groupBy := query.GroupBy
+	if groupBy == "" {
+		groupBy = models.AnalyticsGroupByDay
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = analyticsDefaultLimit
+	}
+
+	key := cache.AnalyticsKey("sales", fmt.Sprintf("%d:%d:%s:%d", from.Unix(), to.Unix(), groupBy, limit))
+	if data, err := s.cache.Get(ctx, key); err == nil {
+		var cached models.SalesAnalyticsResponse
+		if err := json.Unmarshal(data, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	response, err := s.salesAnalytics(s.db.WithContext(ctx), from.UTC(), to.UTC(), groupBy, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(response); err == nil {
+		if err := s.cache.Set(ctx, key, data, analyticsCacheTTL); err != nil && !errors.Is(err, cache.ErrUnavailable) {
+			slog.WarnContext(ctx, "Failed to cache sales analytics", "error", err)
+		}
+	}
+	return response, nil
+}
+
+// salesAnalytics runs one aggregate query per breakdown
+func (s *AnalyticsService) salesAnalytics(db *gorm.DB, from, to time.Time, groupBy string, limit int) (*models.SalesAnalyticsResponse, error) {
+	response := &models.SalesAnalyticsResponse{
+		From:            from,
+		To:              to,
+		GroupBy:         groupBy,
+		Series:          []models.SalesPeriod{},
+		TopProducts:     []models.ProductSales{},
+		ByCategory:      []models.CategorySales{},
+		ByStaff:         []models.StaffSales{},
+		ByPaymentMethod: []models.PaymentMethodSales{},
+		GeneratedAt:     time.Now(),
+	}
+
+	if err := db.Raw(soldOrdersSQL+`SELECT COUNT(*) AS orders, COALESCE(SUM(total), 0) AS sales,
+		COALESCE(SUM(discount_total), 0) AS discounts, COALESCE(SUM(tax_total), 0) AS tax,
+		COALESCE(SUM(refunded), 0) AS refunds
+	FROM sold`, from, to).Scan(&response.Summary).Error; err != nil {
+		return nil, err
+	}
+	response.Summary.NetSales = response.Summary.Sales - response.Summary.Refunds
+	if response.Summary.Orders > 0 {
+		response.Summary.AverageOrderValue = response.Summary.Sales / response.Summary.Orders
+	}
+
+	// groupBy is one of the whitelisted groupings, passed as a parameter all the same
+	if err := db.Raw(soldOrdersSQL+`SELECT date_trunc(?, paid_at) AS period, COUNT(*) AS orders,
+		SUM(total) AS sales, SUM(total - refunded) AS net_sales
+	FROM sold
+	GROUP BY period
+	ORDER BY period`, from, to, groupBy).Scan(&response.Series).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Raw(soldLinesSQL+`SELECT product_id, MAX(name) AS name, MAX(sku) AS sku,
+		SUM(quantity) AS quantity, SUM(sales) AS sales
+	FROM lines
+	GROUP BY product_id
+	ORDER BY sales DESC, product_id
+	LIMIT ?`, from, to, limit).Scan(&response.TopProducts).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Raw(soldLinesSQL+`SELECT p.category_id, COALESCE(MAX(c.name), '') AS name,
+		SUM(lines.quantity) AS quantity, SUM(lines.sales) AS sales
+	FROM lines
+	LEFT JOIN products p ON p.id = lines.product_id
+	LEFT JOIN categories c ON c.id = p.category_id
+	GROUP BY p.category_id
+	ORDER BY sales DESC, p.category_id
+	LIMIT ?`, from, to, limit).Scan(&response.ByCategory).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Raw(soldOrdersSQL+`SELECT sold.created_by_id AS user_id, COALESCE(MAX(u.name), '') AS name,
+		COUNT(*) AS orders, SUM(sold.total - sold.refunded) AS sales
+	FROM sold
+	LEFT JOIN users u ON u.id = sold.created_by_id
+	GROUP BY sold.created_by_id
+	ORDER BY sales DESC, sold.created_by_id
+	LIMIT ?`, from, to, limit).Scan(&response.ByStaff).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Raw(soldOrdersSQL+`SELECT p.method, COUNT(*) AS payments, SUM(p.amount - p.refunded_amount) AS amount
+	FROM payments p
+	JOIN sold ON sold.id = p.order_id
+	WHERE p.status IN ?
+	GROUP BY p.method
+	ORDER BY amount DESC, p.method`, from, to, receiptPaymentStatuses).Scan(&response.ByPaymentMethod).Error; err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
@@ -0,0 +1,610 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// cartCacheTTL bounds how long an idle cart stays in Redis; the database keeps it regardless
+const cartCacheTTL = time.Hour
+
+// CartService keeps the carts terminals ring sales up in. Carts are read from Redis and fall
+// back to the database, which holds every cart and is written on every change under a row
+// lock, so checkout can turn a cart into an order atomically even while Redis is down.
+type CartService struct {
+	db      *gorm.DB
+	cache   *cache.Cache
+	orders  *OrderService
+	coupons *CouponService
+}
+
+func NewCartService(db *gorm.DB, cache *cache.Cache, orders *OrderService, coupons *CouponService) *CartService {
+	return &CartService{
+		db:      db,
+		cache:   cache,
+		orders:  orders,
+		coupons: coupons,
+	}
+}
+
+// cartLineRequest turns a cart line into the order line it is priced and placed as
+func cartLineRequest(line models.CartLine) models.OrderItemRequest {
+	return models.OrderItemRequest{
+		ProductID: line.ProductID,
+		VariantID: line.VariantID,
+		Quantity:  line.Quantity,
+		UnitPrice: line.UnitPrice,
+		Discount:  line.Discount,
+		TaxRate:   line.TaxRate,
+		Course:    line.Course,
+		Seat:      line.Seat,
+	}
+}
+
+// sameOptionalID reports whether two optional IDs are both unset or equal
+func sameOptionalID(a *uint, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// sameOptionalPrice reports whether two optional prices are both unset or equal
+func sameOptionalPrice(a *int64, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// loadCart returns the owner's cart, from Redis when it is cached. Owners without a cart get
+// an empty one that is not saved yet.
+func (s *CartService) loadCart(ctx context.Context, owner models.CartOwner) (*models.Carts, error) {
+	key := cache.CartKey(owner.Key())
+	if data, err := s.cache.Get(ctx, key); err == nil {
+		var cached models.Carts
+		if err := json.Unmarshal(data, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	var cart models.Carts
+	if err := s.db.WithContext(ctx).Where("owner = ?", owner.Key()).First(&cart).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.Carts{DeviceID: owner.DeviceID, TableID: owner.TableID, UserID: owner.UserID, Lines: models.CartLines{}}, nil
+		}
+		return nil, err
+	}
+
+	if data, err := json.Marshal(cart); err == nil {
+		if err := s.cache.Set(ctx, key, data, cartCacheTTL); err != nil && !errors.Is(err, cache.ErrUnavailable) {
+			slog.WarnContext(ctx, "Failed to cache cart", "owner", owner.Key(), "error", err)
+		}
+	}
+	return &cart, nil
+}
+
+// cartCoupon returns the coupon applied to the cart while it can be used
+func (s *CartService) cartCoupon(cart *models.Carts, response *models.CartResponse) (*models.Coupons, error) {
+	if cart.CouponID == nil {
+		return nil, nil
+	}
+
+	coupon, err := s.coupons.GetCouponById(*cart.CouponID)
+	if err != nil {
+		if err.Error() == "coupon not found" {
+			response.CouponError = err.Error()
+			return nil, nil
+		}
+		return nil, err
+	}
+	response.CouponCode = coupon.Code
+	if err := CheckCoupon(coupon, time.Now()); err != nil {
+		response.CouponError = err.Error()
+		return nil, nil
+	}
+	return coupon, nil
+}
+
+// priceCart prices the cart's lines from the catalog, taxed at the rates of the region, and
+// adds up the running totals
+func (s *CartService) priceCart(ctx context.Context, cart *models.Carts, region string) (*models.CartResponse, error) {
+	response := &models.CartResponse{
+		ID:         cart.ID,
+		DeviceID:   cart.DeviceID,
+		TableID:    cart.TableID,
+		CustomerID: cart.CustomerID,
+		Note:       cart.Note,
+		Items:      make([]models.CartItemResponse, len(cart.Lines)),
+		Taxes:      []models.TaxBreakdown{},
+		Promotions: []models.AppliedPromotion{},
+		Label:      cart.Label,
+		ParkedAt:   cart.ParkedAt,
+		UpdatedAt:  cart.UpdatedAt,
+	}
+	coupon, err := s.cartCoupon(cart, response)
+	if err != nil {
+		return nil, err
+	}
+	if len(cart.Lines) == 0 {
+		return response, nil
+	}
+
+	requests := make([]models.OrderItemRequest, len(cart.Lines))
+	for i, line := range cart.Lines {
+		requests[i] = cartLineRequest(line)
+	}
+	items, lineErrs, err := s.orders.priceItems(s.db.WithContext(ctx), requests, region)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := s.orders.discountItems(s.db.WithContext(ctx), items, lineErrs, coupon)
+	if err != nil {
+		return nil, err
+	}
+	response.Promotions = append(response.Promotions, applied...)
+
+	sellable := make([]models.OrderItems, 0, len(items))
+	for i, line := range cart.Lines {
+		if lineErrs[i] != nil {
+			response.Items[i] = models.CartItemResponse{
+				LineID:    line.ID,
+				ProductID: line.ProductID,
+				VariantID: line.VariantID,
+				Quantity:  line.Quantity,
+				Discount:  line.Discount,
+				TaxRate:   line.TaxRate,
+				Course:    line.Course,
+				Seat:      line.Seat,
+				Error:     lineErrs[i].Error(),
+			}
+			continue
+		}
+
+		item := items[i]
+		response.Items[i] = models.CartItemResponse{
+			LineID:       line.ID,
+			ProductID:    item.ProductID,
+			VariantID:    item.VariantID,
+			Name:         item.Name,
+			VariantTitle: item.VariantTitle,
+			SKU:          item.SKU,
+			Quantity:     item.Quantity,
+			UnitPrice:    item.UnitPrice,
+			Subtotal:     item.Subtotal,
+			Discount:     item.Discount,
+			TaxRate:      item.TaxRate,
+			Tax:          item.Tax,
+			Taxes:        item.Taxes,
+			Total:        item.Total,
+			Course:       item.Course,
+			Seat:         item.Seat,
+		}
+		sellable = append(sellable, item)
+		response.ItemCount += item.Quantity
+		response.Subtotal += item.Subtotal
+		response.DiscountTotal += item.Discount
+		response.TaxTotal += item.Tax
+		response.IncludedTax += includedTax(item)
+		response.Total += item.Total
+	}
+	response.Taxes = taxBreakdown(sellable)
+	return response, nil
+}
+
+// GetCart retrieves the owner's cart with its running totals
+func (s *CartService) GetCart(ctx context.Context, owner models.CartOwner) (*models.CartResponse, error) {
+	cart, err := s.loadCart(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	return s.priceCart(ctx, cart, owner.Location)
+}
+
+// lockCart loads the owner's cart for update, creating it on first use
+func lockCart(tx *gorm.DB, owner models.CartOwner) (*models.Carts, error) {
+	cart := models.Carts{
+		Owner:    owner.Key(),
+		DeviceID: owner.DeviceID,
+		TableID:  owner.TableID,
+		UserID:   owner.UserID,
+		Lines:    models.CartLines{},
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&cart).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("owner = ?", owner.Key()).First(&cart).Error; err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// updateCart applies a change to the owner's cart under a row lock and returns the cart with
+// its new totals
+func (s *CartService) updateCart(ctx context.Context, owner models.CartOwner, change func(tx *gorm.DB, cart *models.Carts) error) (*models.CartResponse, error) {
+	var cart *models.Carts
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		cart, err = lockCart(tx, owner)
+		if err != nil {
+			return err
+		}
+		if err := change(tx, cart); err != nil {
+			return err
+		}
+		cart.UserID = owner.UserID
+		return tx.Save(cart).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateCart(owner)
+	return s.priceCart(ctx, cart, owner.Location)
+}
+
+// invalidateCart drops the cached copy of the owner's cart
+func (s *CartService) invalidateCart(owner models.CartOwner) {
+	if err := s.cache.Delete(context.Background(), cache.CartKey(owner.Key())); err != nil {
+		slog.Warn("Failed to invalidate cart", "owner", owner.Key(), "error", err)
+	}
+}
+
+// checkCartLine makes sure a line can be sold as it is
+func (s *CartService) checkCartLine(tx *gorm.DB, line models.CartLine) error {
+	_, err := s.orders.buildOrderItems(tx, []models.OrderItemRequest{cartLineRequest(line)}, "")
+	return err
+}
+
+// AddItem adds a product to the cart. A line for the same product at the same price, tax rate,
+// course and seat is increased instead, and the new discount added to its own.
+func (s *CartService) AddItem(ctx context.Context, owner models.CartOwner, req *models.AddCartItemRequest) (*models.CartResponse, error) {
+	return s.updateCart(ctx, owner, func(tx *gorm.DB, cart *models.Carts) error {
+		for i, line := range cart.Lines {
+			if line.ProductID == req.ProductID && sameOptionalID(line.VariantID, req.VariantID) &&
+				sameOptionalPrice(line.UnitPrice, req.UnitPrice) && line.TaxRate == req.TaxRate &&
+				line.Course == req.Course && line.Seat == req.Seat {
+				line.Quantity += req.Quantity
+				line.Discount += req.Discount
+				if err := s.checkCartLine(tx, line); err != nil {
+					return err
+				}
+				cart.Lines[i] = line
+				return nil
+			}
+		}
+
+		if err := limits.Check(limits.MaxOrderItems, len(cart.Lines)+1); err != nil {
+			return err
+		}
+		line := models.CartLine{
+			ID:        cart.NextLineID,
+			ProductID: req.ProductID,
+			VariantID: req.VariantID,
+			Quantity:  req.Quantity,
+			UnitPrice: req.UnitPrice,
+			Discount:  req.Discount,
+			TaxRate:   req.TaxRate,
+			Course:    req.Course,
+			Seat:      req.Seat,
+		}
+		if err := s.checkCartLine(tx, line); err != nil {
+			return err
+		}
+		cart.Lines = append(cart.Lines, line)
+		cart.NextLineID++
+		return nil
+	})
+}
+
+// UpdateItem changes the quantity, price, discount, tax rate, course and seat of a cart line
+func (s *CartService) UpdateItem(ctx context.Context, owner models.CartOwner, lineID uint, req *models.UpdateCartItemRequest) (*models.CartResponse, error) {
+	return s.updateCart(ctx, owner, func(tx *gorm.DB, cart *models.Carts) error {
+		for i, line := range cart.Lines {
+			if line.ID != lineID {
+				continue
+			}
+			line.Quantity = req.Quantity
+			line.UnitPrice = req.UnitPrice
+			line.Discount = req.Discount
+			line.TaxRate = req.TaxRate
+			line.Course = req.Course
+			line.Seat = req.Seat
+			if err := s.checkCartLine(tx, line); err != nil {
+				return err
+			}
+			cart.Lines[i] = line
+			return nil
+		}
+		return errors.New("cart line not found")
+	})
+}
+
+// RemoveItem removes a line from the cart
+func (s *CartService) RemoveItem(ctx context.Context, owner models.CartOwner, lineID uint) (*models.CartResponse, error) {
+	return s.updateCart(ctx, owner, func(tx *gorm.DB, cart *models.Carts) error {
+		for i, line := range cart.Lines {
+			if line.ID == lineID {
+				cart.Lines = append(cart.Lines[:i], cart.Lines[i+1:]...)
+				return nil
+			}
+		}
+		return errors.New("cart line not found")
+	})
+}
+
+// UpdateCart changes the cart's note, which becomes the note of the order
+func (s *CartService) UpdateCart(ctx context.Context, owner models.CartOwner, req *models.UpdateCartRequest) (*models.CartResponse, error) {
+	return s.updateCart(ctx, owner, func(tx *gorm.DB, cart *models.Carts) error {
+		cart.Note = req.Note
+		return nil
+	})
+}
+
+// ApplyCoupon applies a coupon to the cart, reserving a use of it so limited coupons can't be
+// given out more often than allowed however many terminals apply them at once
+func (s *CartService) ApplyCoupon(ctx context.Context, owner models.CartOwner, req *models.ApplyCouponRequest) (*models.CartResponse, error) {
+	coupon, err := s.coupons.FindCoupon(ctx, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := s.coupons.Lock(ctx, coupon.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return s.updateCart(ctx, owner, func(tx *gorm.DB, cart *models.Carts) error {
+		// Per-customer limits default to the customer of the cart
+		customer := req.Customer
+		if customer == "" && cart.CustomerID != nil {
+			found, err := findCustomer(tx, *cart.CustomerID)
+			if err != nil {
+				return err
+			}
+			customer = customerKey(found)
+		}
+		return s.coupons.ReserveTx(tx, coupon.ID, cart, customer)
+	})
+}
+
+// RemoveCoupon removes the coupon from the cart and gives back the use it reserved
+func (s *CartService) RemoveCoupon(ctx context.Context, owner models.CartOwner) (*models.CartResponse, error) {
+	return s.updateCart(ctx, owner, func(tx *gorm.DB, cart *models.Carts) error {
+		if cart.CouponID == nil {
+			return errors.New("cart has no coupon")
+		}
+		return s.coupons.ReleaseTx(tx, cart)
+	})
+}
+
+// SetCustomer attaches a customer to the cart, who the order is sold to at checkout
+func (s *CartService) SetCustomer(ctx context.Context, owner models.CartOwner, req *models.SetCustomerRequest) (*models.CartResponse, error) {
+	return s.updateCart(ctx, owner, func(tx *gorm.DB, cart *models.Carts) error {
+		if req.CustomerID != nil {
+			if _, err := findCustomer(tx, *req.CustomerID); err != nil {
+				return err
+			}
+		}
+		cart.CustomerID = req.CustomerID
+		return nil
+	})
+}
+
+// ClearCart empties the owner's cart and gives back the coupon use it reserved
+func (s *CartService) ClearCart(ctx context.Context, owner models.CartOwner) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var cart models.Carts
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("owner = ?", owner.Key()).First(&cart).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		if err := s.coupons.ReleaseTx(tx, &cart); err != nil {
+			return err
+		}
+		return tx.Delete(&cart).Error
+	})
+	if err != nil {
+		return err
+	}
+	s.invalidateCart(owner)
+	return nil
+}
+
+// Checkout places the cart as an order and empties it in one transaction, redeeming its coupon.
+// The cart row stays locked until then, so a cart can't be checked out twice or changed while
+// it is placed.
+func (s *CartService) Checkout(ctx context.Context, owner models.CartOwner, actor models.ActivityActor) (*models.Orders, error) {
+	var order *models.Orders
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var cart models.Carts
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("owner = ?", owner.Key()).First(&cart).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("cart is empty")
+			}
+			return err
+		}
+		if len(cart.Lines) == 0 {
+			return errors.New("cart is empty")
+		}
+
+		req := models.CreateOrderRequest{
+			Note:       cart.Note,
+			Region:     owner.Location,
+			CustomerID: cart.CustomerID,
+			DeviceID:   owner.DeviceID,
+			StoreID:    owner.StoreID,
+			TableID:    owner.TableID,
+			Items:      make([]models.OrderItemRequest, len(cart.Lines)),
+		}
+		for i, line := range cart.Lines {
+			req.Items[i] = cartLineRequest(line)
+		}
+
+		var coupon *models.Coupons
+		var redemption *models.CouponRedemptions
+		if cart.CouponID != nil {
+			var err error
+			if coupon, redemption, err = s.coupons.CheckoutTx(tx, &cart); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		order, err = s.orders.CreateOrderTx(tx, &req, coupon, actor)
+		if err != nil {
+			return err
+		}
+		if coupon != nil {
+			if err := s.coupons.RedeemTx(tx, coupon, redemption, order); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&cart).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateCart(owner)
+	s.orders.OrderCreated(order, actor)
+	return order, nil
+}
+
+// lockTicketPair locks the carts of two owners, creating them on first use, in a fixed order
+// so requests moving tickets between the same two tables can't deadlock
+func lockTicketPair(tx *gorm.DB, from models.CartOwner, to models.CartOwner) (*models.Carts, *models.Carts, error) {
+	first, second := from, to
+	if second.Key() < first.Key() {
+		first, second = second, first
+	}
+	firstCart, err := lockCart(tx, first)
+	if err != nil {
+		return nil, nil, err
+	}
+	secondCart, err := lockCart(tx, second)
+	if err != nil {
+		return nil, nil, err
+	}
+	if first.Key() == from.Key() {
+		return firstCart, secondCart, nil
+	}
+	return secondCart, firstCart, nil
+}
+
+// TransferTicket moves the owner's ticket to another table with no open ticket, e.g. when the
+// guests change tables. The ticket keeps its lines, coupon and customer.
+func (s *CartService) TransferTicket(ctx context.Context, owner models.CartOwner, tableID uint) (*models.CartResponse, error) {
+	if owner.TableID != nil && *owner.TableID == tableID {
+		return nil, errors.New("same table")
+	}
+	target := owner
+	target.TableID = &tableID
+
+	var cart *models.Carts
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		source, existing, err := lockTicketPair(tx, owner, target)
+		if err != nil {
+			return err
+		}
+		if len(source.Lines) == 0 {
+			return errors.New("cart is empty")
+		}
+		if len(existing.Lines) > 0 {
+			return errors.New("table has open ticket")
+		}
+
+		// The target's empty cart makes way for the ticket
+		if err := s.coupons.ReleaseTx(tx, existing); err != nil {
+			return err
+		}
+		if err := tx.Delete(existing).Error; err != nil {
+			return err
+		}
+		source.Owner = target.Key()
+		source.TableID = target.TableID
+		source.UserID = owner.UserID
+		cart = source
+		return tx.Save(cart).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateCart(owner)
+	s.invalidateCart(target)
+	return s.priceCart(ctx, cart, target.Location)
+}
+
+// MergeTicket adds the lines of the owner's ticket to the ticket of another table and closes
+// it, e.g. when two parties join. The target keeps its coupon and customer and takes the
+// source's when it has none; a source coupon it can't take is given back.
+func (s *CartService) MergeTicket(ctx context.Context, owner models.CartOwner, tableID uint) (*models.CartResponse, error) {
+	if owner.TableID != nil && *owner.TableID == tableID {
+		return nil, errors.New("same table")
+	}
+	target := owner
+	target.TableID = &tableID
+
+	var cart *models.Carts
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		source, merged, err := lockTicketPair(tx, owner, target)
+		if err != nil {
+			return err
+		}
+		if len(source.Lines) == 0 {
+			return errors.New("cart is empty")
+		}
+		if err := limits.Check(limits.MaxOrderItems, len(merged.Lines)+len(source.Lines)); err != nil {
+			return err
+		}
+
+		// Lines are renumbered so line IDs stay unique on the merged ticket
+		for _, line := range source.Lines {
+			line.ID = merged.NextLineID
+			merged.Lines = append(merged.Lines, line)
+			merged.NextLineID++
+		}
+		if merged.CustomerID == nil {
+			merged.CustomerID = source.CustomerID
+		}
+		if merged.Note == "" {
+			merged.Note = source.Note
+		}
+		if source.CouponID != nil {
+			if merged.CouponID == nil {
+				err = s.coupons.MoveTx(tx, source, merged)
+			} else {
+				err = s.coupons.ReleaseTx(tx, source)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(source).Error; err != nil {
+			return err
+		}
+		merged.UserID = owner.UserID
+		cart = merged
+		return tx.Save(cart).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateCart(owner)
+	s.invalidateCart(target)
+	return s.priceCart(ctx, cart, target.Location)
+}
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CashDrawerService tracks the cash in tills. Cashiers open a drawer with a float, cash
+// payments and refunds are recorded on it as they happen, and closing it with the counted
+// cash reports any variance.
+type CashDrawerService struct {
+	db              *gorm.DB
+	activityService *ActivityService
+}
+
+func NewCashDrawerService(db *gorm.DB, activityService *ActivityService) *CashDrawerService {
+	return &CashDrawerService{
+		db:              db,
+		activityService: activityService,
+	}
+}
+
+// GetAllDrawers retrieves cash drawer sessions; sorting closed sessions by variance surfaces
+// the drawers that were furthest over or short
+func (s *CashDrawerService) GetAllDrawers(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model: &models.CashDrawerSessions{},
+		FilterFields: map[string]string{
+			"status":       "status",
+			"opened_by_id": "opened_by_id",
+			"device_id":    "device_id",
+		},
+		StoreFilter: "device_id IN (SELECT id FROM devices WHERE store_id = ?)",
+		DateFields: map[string]pagination.DateField{
+			"opened_at": {
+				Start: "opened_at",
+				End:   "opened_at",
+			},
+		},
+		SortFields: []string{
+			"opened_at",
+			"closed_at",
+			"expected",
+			"counted",
+			"variance",
+		},
+		DefaultSort:  "opened_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// drawerReport adds a session's movements up by type
+func drawerReport(session *models.CashDrawerSessions, movements []models.CashDrawerMovements) *models.CashDrawerReport {
+	report := &models.CashDrawerReport{
+		Session:      *session,
+		OpeningFloat: session.OpeningFloat,
+		Counted:      session.Counted,
+		Variance:     session.Variance,
+		Movements:    movements,
+	}
+	for _, movement := range movements {
+		switch movement.Type {
+		case models.CashMovementSale:
+			report.Sales += movement.Amount
+		case models.CashMovementRefund:
+			report.Refunds += movement.Amount
+		case models.CashMovementPaidIn:
+			report.PaidIn += movement.Amount
+		case models.CashMovementPaidOut:
+			report.PaidOut += movement.Amount
+		}
+	}
+	report.Expected = report.OpeningFloat + report.Sales + report.PaidIn - report.Refunds - report.PaidOut
+	return report
+}
+
+// loadDrawerReport loads the movements of a session and adds them up
+func loadDrawerReport(db *gorm.DB, session *models.CashDrawerSessions) (*models.CashDrawerReport, error) {
+	var movements []models.CashDrawerMovements
+	if err := db.Where("session_id = ?", session.ID).Order("id").Find(&movements).Error; err != nil {
+		return nil, err
+	}
+	return drawerReport(session, movements), nil
+}
+
+// GetDrawerReport retrieves a session with its movements and what the drawer should hold
+func (s *CashDrawerService) GetDrawerReport(id uint) (*models.CashDrawerReport, error) {
+	var session models.CashDrawerSessions
+	if err := s.db.Where("id = ?", id).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("cash drawer not found")
+		}
+		return nil, err
+	}
+	return loadDrawerReport(s.db, &session)
+}
+
+// GetCurrentDrawer retrieves the report of the drawer a user has open
+func (s *CashDrawerService) GetCurrentDrawer(userID uint) (*models.CashDrawerReport, error) {
+	var session models.CashDrawerSessions
+	if err := s.db.Where("opened_by_id = ? AND status = ?", userID, models.CashDrawerStatusOpen).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("no open cash drawer")
+		}
+		return nil, err
+	}
+	return loadDrawerReport(s.db, &session)
+}
+
+// activeDevice loads a device and checks that it may be used
+func activeDevice(tx *gorm.DB, id uint) (*models.Devices, error) {
+	var device models.Devices
+	if err := tx.Where("id = ?", id).First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("device not found")
+		}
+		return nil, err
+	}
+	if device.Status != models.DeviceStatusActive {
+		return nil, errors.New("device not active")
+	}
+	return &device, nil
+}
+
+// OpenDrawer opens a drawer for the acting user with the float counted into it
+func (s *CashDrawerService) OpenDrawer(req *models.OpenCashDrawerRequest, actor models.ActivityActor) (*models.CashDrawerSessions, error) {
+	var session *models.CashDrawerSessions
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if req.DeviceID != nil {
+			if _, err := activeDevice(tx, *req.DeviceID); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		session, err = s.openDrawerTx(tx, req, actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// openDrawerTx opens a drawer for the acting user in the caller's transaction
+func (s *CashDrawerService) openDrawerTx(tx *gorm.DB, req *models.OpenCashDrawerRequest, actor models.ActivityActor) (*models.CashDrawerSessions, error) {
+	open, err := lockOpenDrawer(tx, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if open != nil {
+		return nil, errors.New("cash drawer already open")
+	}
+
+	session := models.CashDrawerSessions{
+		DeviceID:     req.DeviceID,
+		OpenedByID:   actorID(actor),
+		Status:       models.CashDrawerStatusOpen,
+		OpeningFloat: req.OpeningFloat,
+		Expected:     req.OpeningFloat,
+		OpeningNote:  req.Note,
+		OpenedAt:     time.Now(),
+	}
+	if err := tx.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.activityService.RecordTx(tx, actor.UserID, models.ActivityCashDrawerOpened, actor, "Cash drawer opened", models.JSONMap{
+		"session_id":    session.ID,
+		"device_id":     session.DeviceID,
+		"opening_float": session.OpeningFloat,
+	}); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// lockOpenDrawer loads the drawer a user has open for update. Returns nil when there is none.
+func lockOpenDrawer(tx *gorm.DB, userID uint) (*models.CashDrawerSessions, error) {
+	var session models.CashDrawerSessions
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("opened_by_id = ? AND status = ?", userID, models.CashDrawerStatusOpen).
+		First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// lockOwnDrawer loads a session for update and checks that it is open and was opened by the
+// acting user
+func lockOwnDrawer(tx *gorm.DB, id uint, actor models.ActivityActor) (*models.CashDrawerSessions, error) {
+	var session models.CashDrawerSessions
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("cash drawer not found")
+		}
+		return nil, err
+	}
+	if session.OpenedByID == nil || *session.OpenedByID != actor.UserID {
+		return nil, errors.New("cash drawer belongs to another user")
+	}
+	if session.Status != models.CashDrawerStatusOpen {
+		return nil, errors.New("cash drawer closed")
+	}
+	return &session, nil
+}
+
+// addMovementTx records a movement and keeps the session's expected cash up to date
+func addMovementTx(tx *gorm.DB, session *models.CashDrawerSessions, movement *models.CashDrawerMovements) error {
+	if err := tx.Create(movement).Error; err != nil {
+		return err
+	}
+
+	delta := movement.Amount
+	if movement.Type == models.CashMovementRefund || movement.Type == models.CashMovementPaidOut {
+		delta = -delta
+	}
+	return tx.Model(session).Update("expected", gorm.Expr("expected + ?", delta)).Error
+}
+
+// recordCashTx records cash a payment put into or took out of the drawer the user has open.
+// Cash taken by a user without an open drawer isn't tracked.
+func recordCashTx(tx *gorm.DB, userID uint, movementType string, amount int64, paymentID uint) error {
+	if userID == 0 {
+		return nil
+	}
+	session, err := lockOpenDrawer(tx, userID)
+	if err != nil || session == nil {
+		return err
+	}
+	return addMovementTx(tx, session, &models.CashDrawerMovements{
+		SessionID:   session.ID,
+		Type:        movementType,
+		Amount:      amount,
+		PaymentID:   &paymentID,
+		CreatedByID: &userID,
+	})
+}
+
+// RecordMovement records a paid-in or paid-out on the acting user's open drawer
+func (s *CashDrawerService) RecordMovement(id uint, req *models.CashMovementRequest, actor models.ActivityActor) (*models.CashDrawerMovements, error) {
+	var movement models.CashDrawerMovements
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		session, err := lockOwnDrawer(tx, id, actor)
+		if err != nil {
+			return err
+		}
+
+		if req.Type == models.CashMovementPaidOut && req.Amount > session.Expected {
+			return errors.New("paid-out exceeds drawer cash")
+		}
+
+		movement = models.CashDrawerMovements{
+			SessionID:   session.ID,
+			Type:        req.Type,
+			Amount:      req.Amount,
+			Reason:      req.Reason,
+			CreatedByID: actorID(actor),
+		}
+		if err := addMovementTx(tx, session, &movement); err != nil {
+			return err
+		}
+
+		return s.activityService.RecordTx(tx, actor.UserID, models.ActivityCashMovementRecorded, actor, fmt.Sprintf("Cash %s recorded: %s", req.Type, req.Reason), models.JSONMap{
+			"session_id": session.ID,
+			"type":       movement.Type,
+			"amount":     movement.Amount,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &movement, nil
+}
+
+// CloseDrawer closes the acting user's drawer with the cash counted in it and reports the
+// variance against what it should hold
+func (s *CashDrawerService) CloseDrawer(id uint, req *models.CloseCashDrawerRequest, actor models.ActivityActor) (*models.CashDrawerReport, error) {
+	var report *models.CashDrawerReport
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		session, err := lockOwnDrawer(tx, id, actor)
+		if err != nil {
+			return err
+		}
+		var shifts int64
+		if err := tx.Model(&models.Shifts{}).Where("cash_drawer_session_id = ? AND status = ?", session.ID, models.ShiftStatusOpen).Count(&shifts).Error; err != nil {
+			return err
+		}
+		if shifts > 0 {
+			return errors.New("cash drawer belongs to an open shift")
+		}
+
+		report, err = s.closeDrawerTx(tx, session, *req.Counted, req.Note, actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// closeDrawerTx closes a locked drawer in the caller's transaction
+func (s *CashDrawerService) closeDrawerTx(tx *gorm.DB, session *models.CashDrawerSessions, counted int64, note string, actor models.ActivityActor) (*models.CashDrawerReport, error) {
+	report, err := loadDrawerReport(tx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	variance := counted - report.Expected
+	if err := tx.Model(session).Updates(map[string]interface{}{
+		"status":       models.CashDrawerStatusClosed,
+		"expected":     report.Expected,
+		"counted":      counted,
+		"variance":     variance,
+		"closing_note": note,
+		"closed_by_id": actorID(actor),
+		"closed_at":    now,
+	}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("id = ?", session.ID).First(session).Error; err != nil {
+		return nil, err
+	}
+	report.Session = *session
+	report.Counted = &counted
+	report.Variance = &variance
+
+	if err := s.activityService.RecordTx(tx, actor.UserID, models.ActivityCashDrawerClosed, actor, "Cash drawer closed", models.JSONMap{
+		"session_id": session.ID,
+		"expected":   report.Expected,
+		"counted":    counted,
+		"variance":   variance,
+	}); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
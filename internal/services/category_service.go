@@ -0,0 +1,283 @@
+package services
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// categorySubtreeSQL selects the IDs of a category and all of its descendants
+const categorySubtreeSQL = `WITH RECURSIVE subtree AS (
+	SELECT id FROM categories WHERE id = ? AND deleted_at IS NULL
+	UNION ALL
+	SELECT c.id FROM categories c JOIN subtree ON c.parent_id = subtree.id WHERE c.deleted_at IS NULL
+) SELECT id FROM subtree`
+
+type CategoryService struct {
+	db      *gorm.DB
+	changes *ChangeFeedService
+}
+
+func NewCategoryService(db *gorm.DB, changes *ChangeFeedService) *CategoryService {
+	return &CategoryService{
+		db:      db,
+		changes: changes,
+	}
+}
+
+// GetCategoryTree returns every category nested under its parent, siblings ordered by position
+func (s *CategoryService) GetCategoryTree() ([]*models.Categories, error) {
+	var categories []*models.Categories
+	if err := s.db.Order("position ASC, name ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]*models.Categories, len(categories))
+	for _, category := range categories {
+		category.Children = []*models.Categories{}
+		byID[category.ID] = category
+	}
+
+	roots := []*models.Categories{}
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+			continue
+		}
+		if parent, ok := byID[*category.ParentID]; ok {
+			parent.Children = append(parent.Children, category)
+		}
+	}
+	return roots, nil
+}
+
+// GetCategoryById retrieves a category by ID
+func (s *CategoryService) GetCategoryById(id uint) (*models.Categories, error) {
+	var category models.Categories
+	if err := s.db.Where("id = ?", id).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("category not found")
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// findParent makes sure the parent category exists. A nil parent is the top level.
+func (s *CategoryService) findParent(parentID *uint) error {
+	if parentID == nil {
+		return nil
+	}
+	var count int64
+	if err := s.db.Model(&models.Categories{}).Where("id = ?", *parentID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("parent category not found")
+	}
+	return nil
+}
+
+// siblings scopes a query to the children of a parent category
+func siblings(query *gorm.DB, parentID *uint) *gorm.DB {
+	if parentID == nil {
+		return query.Where("parent_id IS NULL")
+	}
+	return query.Where("parent_id = ?", *parentID)
+}
+
+// checkName makes sure no sibling category uses the name
+func (s *CategoryService) checkName(name string, parentID *uint, excludeID uint) error {
+	var count int64
+	query := siblings(s.db.Model(&models.Categories{}), parentID).Where("LOWER(name) = LOWER(?) AND id <> ?", name, excludeID)
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("category name already exists")
+	}
+	return nil
+}
+
+// nextPosition returns the position after the last child of a parent category
+func (s *CategoryService) nextPosition(parentID *uint) (int, error) {
+	var last *int
+	if err := siblings(s.db.Model(&models.Categories{}), parentID).Select("MAX(position)").Scan(&last).Error; err != nil {
+		return 0, err
+	}
+	if last == nil {
+		return 0, nil
+	}
+	return *last + 1, nil
+}
+
+// CreateCategory creates a category as the last child of its parent
+func (s *CategoryService) CreateCategory(req *models.CreateCategoryRequest) (*models.Categories, error) {
+	if err := s.findParent(req.ParentID); err != nil {
+		return nil, err
+	}
+	if err := s.checkName(req.Name, req.ParentID, 0); err != nil {
+		return nil, err
+	}
+
+	position, err := s.nextPosition(req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	category := models.Categories{
+		ParentID:    req.ParentID,
+		Name:        req.Name,
+		Description: req.Description,
+		Position:    position,
+	}
+	if err := s.db.Create(&category).Error; err != nil {
+		return nil, err
+	}
+
+	s.changes.Publish(models.ChangeEntityCategory, models.ChangeActionCreated, category.ID)
+	return &category, nil
+}
+
+// UpdateCategory renames a category or moves it under another parent. A moved category
+// keeps its subtree and becomes the last child of its new parent.
+func (s *CategoryService) UpdateCategory(id uint, req *models.UpdateCategoryRequest) (*models.Categories, error) {
+	category, err := s.GetCategoryById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	moved := !sameParent(category.ParentID, req.ParentID)
+	if moved {
+		if err := s.findParent(req.ParentID); err != nil {
+			return nil, err
+		}
+		if req.ParentID != nil {
+			subtree, err := s.subtreeIDs(category.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, subtreeID := range subtree {
+				if subtreeID == *req.ParentID {
+					return nil, errors.New("category cannot be moved under itself")
+				}
+			}
+		}
+	}
+	if err := s.checkName(req.Name, req.ParentID, category.ID); err != nil {
+		return nil, err
+	}
+
+	if moved {
+		position, err := s.nextPosition(req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		category.Position = position
+	}
+	category.ParentID = req.ParentID
+	category.Name = req.Name
+	category.Description = req.Description
+
+	if err := s.db.Save(category).Error; err != nil {
+		return nil, err
+	}
+
+	s.changes.Publish(models.ChangeEntityCategory, models.ChangeActionUpdated, category.ID)
+	return category, nil
+}
+
+// DeleteCategory soft deletes a category without subcategories. Its products are left
+// uncategorized.
+func (s *CategoryService) DeleteCategory(id uint) (*models.Categories, error) {
+	category, err := s.GetCategoryById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var children int64
+	if err := s.db.Model(&models.Categories{}).Where("parent_id = ?", category.ID).Count(&children).Error; err != nil {
+		return nil, err
+	}
+	if children > 0 {
+		return nil, errors.New("category has subcategories")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Products{}).Where("category_id = ?", category.ID).Update("category_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(category).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.changes.Publish(models.ChangeEntityCategory, models.ChangeActionDeleted, category.ID)
+	return category, nil
+}
+
+// ReorderCategories sets the order of a parent category's children. The request has to
+// list every child exactly once.
+func (s *CategoryService) ReorderCategories(req *models.ReorderCategoriesRequest) ([]*models.Categories, error) {
+	if err := s.findParent(req.ParentID); err != nil {
+		return nil, err
+	}
+
+	var children []*models.Categories
+	if err := siblings(s.db, req.ParentID).Find(&children).Error; err != nil {
+		return nil, err
+	}
+
+	requested := uniqueIDs(req.CategoryIDs)
+	if len(requested) != len(req.CategoryIDs) || len(requested) != len(children) {
+		return nil, errors.New("category ids must list every subcategory once")
+	}
+	position := make(map[uint]int, len(requested))
+	for i, id := range requested {
+		position[id] = i
+	}
+	for _, child := range children {
+		if _, ok := position[child.ID]; !ok {
+			return nil, errors.New("category ids must list every subcategory once")
+		}
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, child := range children {
+			child.Position = position[child.ID]
+			if err := tx.Model(child).Update("position", child.Position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(children, func(i, j int) bool { return children[i].Position < children[j].Position })
+	for _, child := range children {
+		s.changes.Publish(models.ChangeEntityCategory, models.ChangeActionUpdated, child.ID)
+	}
+	return children, nil
+}
+
+// subtreeIDs returns the IDs of a category and all of its descendants
+func (s *CategoryService) subtreeIDs(id uint) ([]uint, error) {
+	var ids []uint
+	if err := s.db.Raw(categorySubtreeSQL, id).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// sameParent reports whether two parent references point to the same category
+func sameParent(a *uint, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
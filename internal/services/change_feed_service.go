@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// changeFeedPollInterval is how often waiting clients check for changes written by other instances
+const changeFeedPollInterval = time.Second
+
+// defaultChangeFeedLimit caps the events returned per request when the client sets no limit
+const defaultChangeFeedLimit = 100
+
+// ChangeEventHook runs once changes are published, for pushing them elsewhere
+type ChangeEventHook func(events []models.ChangeEvents)
+
+// ChangeFeedService records entity-changed notices with increasing sequence numbers so clients
+// can keep local caches fresh. Waiting clients are woken right away by changes published on this
+// instance and pick up changes from other instances on the next poll.
+type ChangeFeedService struct {
+	db        *gorm.DB
+	retention time.Duration
+	hooks     []ChangeEventHook
+
+	mu   sync.Mutex
+	wake chan struct{}
+}
+
+func NewChangeFeedService(db *gorm.DB, retention time.Duration) *ChangeFeedService {
+	return &ChangeFeedService{
+		db:        db,
+		retention: retention,
+		wake:      make(chan struct{}),
+	}
+}
+
+// AfterPublish subscribes a hook to every published change. Hooks are not synchronized and must
+// be subscribed before the service is used.
+func (s *ChangeFeedService) AfterPublish(hook ChangeEventHook) {
+	s.hooks = append(s.hooks, hook)
+}
+
+// notify wakes every client waiting for changes
+func (s *ChangeFeedService) notify() {
+	s.mu.Lock()
+	close(s.wake)
+	s.wake = make(chan struct{})
+	s.mu.Unlock()
+}
+
+// woken returns a channel that is closed on the next published change
+func (s *ChangeFeedService) woken() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.wake
+}
+
+// Publish records that the entities changed. Call it after the change is committed.
+// Failures are logged rather than returned so the feed never breaks the change itself.
+func (s *ChangeFeedService) Publish(entity string, action string, ids ...uint) {
+	if len(ids) == 0 {
+		return
+	}
+
+	events := make([]models.ChangeEvents, len(ids))
+	for i, id := range ids {
+		events[i] = models.ChangeEvents{Entity: entity, EntityID: id, Action: action}
+	}
+	if err := s.db.Create(&events).Error; err != nil {
+		slog.Error("Failed to publish change", "entity", entity, "action", action, "records", len(ids), "error", err)
+		return
+	}
+
+	s.notify()
+	for _, hook := range s.hooks {
+		hook(events)
+	}
+}
+
+// Latest returns the most recent sequence number, or 0 when the feed is empty
+func (s *ChangeFeedService) Latest() (uint64, error) {
+	var latest uint64
+	err := s.db.Model(&models.ChangeEvents{}).Select("COALESCE(MAX(id), 0)").Scan(&latest).Error
+	return latest, err
+}
+
+// Since returns up to limit changes after the sequence number. Returns an error when older
+// changes have been pruned, since the client may have missed some and has to refetch.
+func (s *ChangeFeedService) Since(since uint64, limit int) (*models.ChangeFeedResponse, error) {
+	if limit <= 0 {
+		limit = defaultChangeFeedLimit
+	}
+
+	events := []models.ChangeEvents{}
+	if err := s.db.Where("id > ?", since).Order("id ASC").Limit(limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	if since > 0 && (len(events) == 0 || events[0].ID > since+1) {
+		var oldest uint64
+		if err := s.db.Model(&models.ChangeEvents{}).Select("COALESCE(MIN(id), 0)").Scan(&oldest).Error; err != nil {
+			return nil, err
+		}
+		if oldest > since+1 {
+			return nil, errors.New("change feed cursor expired")
+		}
+	}
+
+	response := &models.ChangeFeedResponse{Events: events, LastSeq: since}
+	if len(events) > 0 {
+		response.LastSeq = events[len(events)-1].ID
+	}
+	return response, nil
+}
+
+// Wait returns the changes after the sequence number, holding on for up to timeout until
+// there are any. An empty response means nothing changed in time.
+func (s *ChangeFeedService) Wait(ctx context.Context, since uint64, limit int, timeout time.Duration) (*models.ChangeFeedResponse, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	poll := time.NewTicker(changeFeedPollInterval)
+	defer poll.Stop()
+
+	for {
+		wake := s.woken()
+
+		response, err := s.Since(since, limit)
+		if err != nil || len(response.Events) > 0 {
+			return response, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return response, nil
+		case <-deadline.C:
+			return response, nil
+		case <-wake:
+		case <-poll.C:
+		}
+	}
+}
+
+// Prune drops changes older than the retention window; it is run by a background job
+func (s *ChangeFeedService) Prune(ctx context.Context) error {
+	cutoff := time.Now().Add(-s.retention)
+	result := s.db.WithContext(ctx).Where("created_at < ?", cutoff).Delete(&models.ChangeEvents{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		slog.InfoContext(ctx, "Pruned change feed events", "events", result.RowsAffected, "older_than", cutoff.Format(time.RFC3339))
+	}
+	return nil
+}
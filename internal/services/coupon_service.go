@@ -0,0 +1,438 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"log/slog"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	couponCodeLength = 8
+	// couponLockTTL bounds how long a reservation may hold a coupon's lock
+	couponLockTTL = 10 * time.Second
+	// couponLockWait is how long applying a coupon waits for others applying it
+	couponLockWait = 3 * time.Second
+)
+
+// CouponService manages coupon codes and the uses reserved and redeemed of them
+type CouponService struct {
+	db    *gorm.DB
+	cache *cache.Cache
+}
+
+func NewCouponService(db *gorm.DB, cache *cache.Cache) *CouponService {
+	return &CouponService{
+		db:    db,
+		cache: cache,
+	}
+}
+
+// normalizeCouponCode returns a code the way coupons are stored, so codes match regardless of
+// case
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// normalizeCouponCustomer returns a customer the way redemptions are stored
+func normalizeCouponCustomer(customer string) string {
+	return strings.ToLower(strings.TrimSpace(customer))
+}
+
+// generateCouponCode generates a random code from the pairing code alphabet
+func generateCouponCode(prefix string) (string, error) {
+	code := make([]byte, couponCodeLength)
+	max := big.NewInt(int64(len(pairingCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = pairingCodeAlphabet[n.Int64()]
+	}
+	return normalizeCouponCode(prefix) + string(code), nil
+}
+
+// GetAllCoupons retrieves coupons with pagination
+func (s *CouponService) GetAllCoupons(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.Coupons{},
+		SearchFields: []string{"code"},
+		FilterFields: map[string]string{
+			"promotion_id": "promotion_id",
+			"active":       "active",
+		},
+		CustomFilters: map[string]string{
+			"expired": "(expires_at IS NOT NULL AND expires_at <= NOW()) = ?",
+		},
+		DateFields: map[string]pagination.DateField{
+			"expires_at": {
+				Start: "expires_at",
+				End:   "expires_at",
+			},
+		},
+		SortFields: []string{
+			"code",
+			"used_count",
+			"expires_at",
+			"created_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetCouponById retrieves a coupon by ID
+func (s *CouponService) GetCouponById(id uint) (*models.Coupons, error) {
+	var coupon models.Coupons
+	if err := s.db.Where("id = ?", id).First(&coupon).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("coupon not found")
+		}
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+// GetCouponRedemptions retrieves the uses of a coupon, newest first
+func (s *CouponService) GetCouponRedemptions(id uint) ([]models.CouponRedemptions, error) {
+	if _, err := s.GetCouponById(id); err != nil {
+		return nil, err
+	}
+
+	var redemptions []models.CouponRedemptions
+	if err := s.db.Where("coupon_id = ?", id).Order("id DESC").Find(&redemptions).Error; err != nil {
+		return nil, err
+	}
+	return redemptions, nil
+}
+
+// CreateCoupons creates a coupon with the requested code, or generates Count coupons with
+// random codes. Coupons only give coupon-only promotions, which don't apply without them.
+func (s *CouponService) CreateCoupons(req *models.CreateCouponsRequest, actor models.ActivityActor) ([]models.Coupons, error) {
+	var promotion models.Promotions
+	if err := s.db.Where("id = ?", req.PromotionID).First(&promotion).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("promotion not found")
+		}
+		return nil, err
+	}
+	if !promotion.CouponOnly {
+		return nil, errors.New("promotion is not coupon-only")
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("coupon expires in the past")
+	}
+
+	codes := []string{normalizeCouponCode(req.Code)}
+	if req.Code == "" {
+		var err error
+		if codes, err = s.generateCodes(req.Prefix, req.Count); err != nil {
+			return nil, err
+		}
+	}
+
+	coupons := make([]models.Coupons, len(codes))
+	for i, code := range codes {
+		coupons[i] = models.Coupons{
+			Code:             code,
+			PromotionID:      promotion.ID,
+			UsageLimit:       req.UsageLimit,
+			PerCustomerLimit: req.PerCustomerLimit,
+			Active:           true,
+			ExpiresAt:        req.ExpiresAt,
+			CreatedByID:      actorID(actor),
+		}
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var taken int64
+		if err := tx.Model(&models.Coupons{}).Where("code IN ?", codes).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return errors.New("coupon code already exists")
+		}
+		return tx.CreateInBatches(&coupons, 100).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return coupons, nil
+}
+
+// generateCodes generates count distinct codes no coupon uses yet
+func (s *CouponService) generateCodes(prefix string, count int) ([]string, error) {
+	codes := make([]string, 0, count)
+	seen := map[string]bool{}
+	for attempt := 0; len(codes) < count; attempt++ {
+		if attempt == 5 {
+			return nil, errors.New("failed to generate unique coupon codes")
+		}
+
+		var batch []string
+		for len(codes)+len(batch) < count {
+			code, err := generateCouponCode(prefix)
+			if err != nil {
+				return nil, err
+			}
+			if !seen[code] {
+				seen[code] = true
+				batch = append(batch, code)
+			}
+		}
+
+		var taken []string
+		if err := s.db.Model(&models.Coupons{}).Where("code IN ?", batch).Pluck("code", &taken).Error; err != nil {
+			return nil, err
+		}
+		for _, code := range batch {
+			if !slices.Contains(taken, code) {
+				codes = append(codes, code)
+			}
+		}
+	}
+	return codes, nil
+}
+
+// UpdateCoupon changes a coupon's limits and expiry. Lowering a limit below the uses already
+// made stops further uses without undoing them.
+func (s *CouponService) UpdateCoupon(id uint, req *models.UpdateCouponRequest) (*models.Coupons, error) {
+	coupon, err := s.GetCouponById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	coupon.UsageLimit = req.UsageLimit
+	coupon.PerCustomerLimit = req.PerCustomerLimit
+	coupon.Active = *req.Active
+	coupon.ExpiresAt = req.ExpiresAt
+	if err := s.db.Save(coupon).Error; err != nil {
+		return nil, err
+	}
+	return coupon, nil
+}
+
+// DeleteCoupon soft-deletes a coupon; carts it was applied to lose its promotion
+func (s *CouponService) DeleteCoupon(id uint) (*models.Coupons, error) {
+	coupon, err := s.GetCouponById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.releaseTx(tx, "coupon_id = ?", coupon.ID); err != nil {
+			return err
+		}
+		return tx.Delete(coupon).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return coupon, nil
+}
+
+// FindCoupon looks up a coupon by code
+func (s *CouponService) FindCoupon(ctx context.Context, code string) (*models.Coupons, error) {
+	var coupon models.Coupons
+	if err := s.db.WithContext(ctx).Where("code = ?", normalizeCouponCode(code)).First(&coupon).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("coupon not found")
+		}
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+// Lock serializes reservations of a coupon across API instances, so checking its limits and
+// reserving a use happen as one step. While Redis is down the coupon's row lock, which
+// reservations take as well, does the job alone.
+func (s *CouponService) Lock(ctx context.Context, couponID uint) (func(), error) {
+	unlock, err := s.cache.Lock(ctx, cache.CouponLockKey(couponID), couponLockTTL, couponLockWait)
+	switch {
+	case errors.Is(err, cache.ErrUnavailable):
+		slog.WarnContext(ctx, "Cache unavailable, reserving coupon under its row lock only", "coupon_id", couponID)
+		return func() {}, nil
+	case errors.Is(err, cache.ErrLocked):
+		return nil, errors.New("coupon busy")
+	case err != nil:
+		return nil, err
+	}
+	return unlock, nil
+}
+
+// lockCouponTx loads a coupon for update
+func lockCouponTx(tx *gorm.DB, id uint) (*models.Coupons, error) {
+	var coupon models.Coupons
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&coupon).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("coupon not found")
+		}
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+// CheckCoupon makes sure a coupon can be used at a time, apart from its usage limits
+func CheckCoupon(coupon *models.Coupons, now time.Time) error {
+	if !coupon.Active {
+		return errors.New("coupon inactive")
+	}
+	if coupon.ExpiresAt != nil && !coupon.ExpiresAt.After(now) {
+		return errors.New("coupon expired")
+	}
+	return nil
+}
+
+// checkLimitsTx makes sure another use of a coupon stays within its limits. Redeemed uses and
+// reservations that haven't expired count, except the redemption excluded, which is the one
+// being checked.
+func checkLimitsTx(tx *gorm.DB, coupon *models.Coupons, customer string, excludeID uint, now time.Time) error {
+	uses := func() *gorm.DB {
+		return tx.Model(&models.CouponRedemptions{}).
+			Where("coupon_id = ? AND id <> ?", coupon.ID, excludeID).
+			Where("(status = ? OR (status = ? AND expires_at > ?))", models.CouponRedemptionRedeemed, models.CouponRedemptionReserved, now)
+	}
+
+	if coupon.UsageLimit != nil {
+		var count int64
+		if err := uses().Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= int64(*coupon.UsageLimit) {
+			return errors.New("coupon usage limit reached")
+		}
+	}
+
+	if coupon.PerCustomerLimit != nil {
+		if customer == "" {
+			return errors.New("coupon requires a customer")
+		}
+		var count int64
+		if err := uses().Where("customer = ?", customer).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= int64(*coupon.PerCustomerLimit) {
+			return errors.New("coupon customer limit reached")
+		}
+	}
+	return nil
+}
+
+// ReserveTx reserves a use of a coupon for a cart in the caller's transaction, replacing any
+// coupon the cart had. The caller holds the coupon's lock.
+func (s *CouponService) ReserveTx(tx *gorm.DB, couponID uint, cart *models.Carts, customer string) error {
+	coupon, err := lockCouponTx(tx, couponID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := CheckCoupon(coupon, now); err != nil {
+		return err
+	}
+
+	if err := s.releaseTx(tx, "cart_id = ?", cart.ID); err != nil {
+		return err
+	}
+	customer = normalizeCouponCustomer(customer)
+	if err := checkLimitsTx(tx, coupon, customer, 0, now); err != nil {
+		return err
+	}
+
+	expiresAt := now.Add(models.CouponReservationTTL)
+	redemption := models.CouponRedemptions{
+		CouponID:  coupon.ID,
+		CartID:    &cart.ID,
+		Customer:  customer,
+		Status:    models.CouponRedemptionReserved,
+		ExpiresAt: &expiresAt,
+	}
+	if err := tx.Create(&redemption).Error; err != nil {
+		return err
+	}
+	cart.CouponID = &coupon.ID
+	return nil
+}
+
+// releaseTx gives back the reservations matching a condition
+func (s *CouponService) releaseTx(tx *gorm.DB, query string, args ...interface{}) error {
+	return tx.Model(&models.CouponRedemptions{}).
+		Where("status = ?", models.CouponRedemptionReserved).
+		Where(query, args...).
+		Updates(map[string]interface{}{"status": models.CouponRedemptionReleased, "expires_at": nil}).Error
+}
+
+// ReleaseTx gives back the use a cart reserved, in the caller's transaction
+func (s *CouponService) ReleaseTx(tx *gorm.DB, cart *models.Carts) error {
+	cart.CouponID = nil
+	return s.releaseTx(tx, "cart_id = ?", cart.ID)
+}
+
+// MoveTx hands the use a cart reserved over to another cart, e.g. when table tickets are merged
+func (s *CouponService) MoveTx(tx *gorm.DB, from *models.Carts, to *models.Carts) error {
+	err := tx.Model(&models.CouponRedemptions{}).
+		Where("status = ? AND cart_id = ?", models.CouponRedemptionReserved, from.ID).
+		Update("cart_id", to.ID).Error
+	if err != nil {
+		return err
+	}
+	to.CouponID = from.CouponID
+	from.CouponID = nil
+	return nil
+}
+
+// CheckoutTx checks the coupon of a cart being checked out. A reservation that expired is
+// checked against the coupon's limits again, since its use may have been given to someone else.
+func (s *CouponService) CheckoutTx(tx *gorm.DB, cart *models.Carts) (*models.Coupons, *models.CouponRedemptions, error) {
+	coupon, err := lockCouponTx(tx, *cart.CouponID)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	if err := CheckCoupon(coupon, now); err != nil {
+		return nil, nil, err
+	}
+
+	var redemption models.CouponRedemptions
+	err = tx.Where("coupon_id = ? AND cart_id = ? AND status = ?", coupon.ID, cart.ID, models.CouponRedemptionReserved).
+		Order("id DESC").First(&redemption).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, errors.New("coupon reservation not found")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if redemption.ExpiresAt == nil || !redemption.ExpiresAt.After(now) {
+		if err := checkLimitsTx(tx, coupon, redemption.Customer, redemption.ID, now); err != nil {
+			return nil, nil, err
+		}
+	}
+	return coupon, &redemption, nil
+}
+
+// RedeemTx turns a cart's reservation into a use of the coupon on the order placed from it
+func (s *CouponService) RedeemTx(tx *gorm.DB, coupon *models.Coupons, redemption *models.CouponRedemptions, order *models.Orders) error {
+	err := tx.Model(redemption).Updates(map[string]interface{}{
+		"status":     models.CouponRedemptionRedeemed,
+		"order_id":   order.ID,
+		"cart_id":    nil,
+		"expires_at": nil,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(coupon).UpdateColumn("used_count", gorm.Expr("used_count + 1")).Error
+}
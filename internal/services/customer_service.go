@@ -0,0 +1,224 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// customerOrderStatuses are the statuses of orders that count as purchases
+var customerOrderStatuses = []string{
+	models.OrderStatusPlaced,
+	models.OrderStatusPaid,
+	models.OrderStatusFulfilled,
+	models.OrderStatusCompleted,
+	models.OrderStatusRefunded,
+}
+
+// CustomerService manages customers and their purchase history
+type CustomerService struct {
+	db *gorm.DB
+}
+
+func NewCustomerService(db *gorm.DB) *CustomerService {
+	return &CustomerService{
+		db: db,
+	}
+}
+
+// GetAllCustomers retrieves customers with pagination and search on name, email and phone
+func (s *CustomerService) GetAllCustomers(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.Customers{},
+		SearchFields: []string{"name", "email", "phone"},
+		FilterFields: map[string]string{
+			"email":            "email",
+			"phone":            "phone",
+			"marketing_opt_in": "marketing_opt_in",
+		},
+		DateFields: map[string]pagination.DateField{
+			"created_at": {
+				Start: "created_at",
+				End:   "created_at",
+			},
+		},
+		SortFields: []string{
+			"name",
+			"email",
+			"created_at",
+		},
+		DefaultSort:  "name",
+		DefaultOrder: "ASC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// findCustomer looks up a customer without its stats
+func findCustomer(db *gorm.DB, id uint) (*models.Customers, error) {
+	var customer models.Customers
+	if err := db.Where("id = ?", id).First(&customer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("customer not found")
+		}
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// GetCustomerById retrieves a customer with the sum of their purchases
+func (s *CustomerService) GetCustomerById(id uint) (*models.Customers, error) {
+	customer, err := findCustomer(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats models.CustomerStats
+	err = s.db.Model(&models.Orders{}).
+		Select("COUNT(*) AS order_count, COALESCE(SUM(total), 0) AS total_spent, MAX(placed_at) AS last_order_at").
+		Where("customer_id = ? AND status IN ?", customer.ID, customerOrderStatuses).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	customer.Stats = &stats
+	return customer, nil
+}
+
+// GetCustomerOrders retrieves a customer's purchase history, newest first
+func (s *CustomerService) GetCustomerOrders(id uint, params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	if _, err := findCustomer(s.db, id); err != nil {
+		return nil, err
+	}
+
+	config := pagination.PaginationConfig{
+		Model:         &models.Orders{},
+		BaseCondition: map[string]interface{}{"customer_id": id},
+		SearchFields:  []string{"number", "note"},
+		FilterFields: map[string]string{
+			"status": "status",
+		},
+		CustomFilters: map[string]string{
+			"product_id": "id IN (SELECT order_id FROM order_items WHERE product_id = ?)",
+		},
+		StoreFilter: "store_id = ?",
+		DateFields: map[string]pagination.DateField{
+			"created_at": {
+				Start: "created_at",
+				End:   "created_at",
+			},
+		},
+		SortFields: []string{
+			"number",
+			"total",
+			"created_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// checkCustomerEmail makes sure no other customer has the email
+func (s *CustomerService) checkCustomerEmail(email string, excludeID uint) error {
+	if email == "" {
+		return nil
+	}
+	var count int64
+	if err := s.db.Model(&models.Customers{}).Where("LOWER(email) = LOWER(?) AND id <> ?", email, excludeID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("customer email already exists")
+	}
+	return nil
+}
+
+// CreateCustomer creates a customer
+func (s *CustomerService) CreateCustomer(req *models.CreateCustomerRequest, actor models.ActivityActor) (*models.Customers, error) {
+	email := strings.TrimSpace(req.Email)
+	if err := s.checkCustomerEmail(email, 0); err != nil {
+		return nil, err
+	}
+
+	customer := models.Customers{
+		Name:           req.Name,
+		Email:          email,
+		Phone:          strings.TrimSpace(req.Phone),
+		Company:        strings.TrimSpace(req.Company),
+		TaxID:          strings.TrimSpace(req.TaxID),
+		BillingAddress: req.BillingAddress,
+		Notes:          req.Notes,
+		MarketingOptIn: req.MarketingOptIn,
+		CreatedByID:    actorID(actor),
+	}
+	if customer.MarketingOptIn {
+		now := time.Now()
+		customer.MarketingOptInAt = &now
+	}
+	if err := s.db.Create(&customer).Error; err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// UpdateCustomer updates a customer's details. Opting in again keeps the time of the
+// customer's original consent.
+func (s *CustomerService) UpdateCustomer(id uint, req *models.UpdateCustomerRequest) (*models.Customers, error) {
+	customer, err := findCustomer(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	email := strings.TrimSpace(req.Email)
+	if err := s.checkCustomerEmail(email, customer.ID); err != nil {
+		return nil, err
+	}
+
+	customer.Name = req.Name
+	customer.Email = email
+	customer.Phone = strings.TrimSpace(req.Phone)
+	customer.Company = strings.TrimSpace(req.Company)
+	customer.TaxID = strings.TrimSpace(req.TaxID)
+	customer.BillingAddress = req.BillingAddress
+	customer.Notes = req.Notes
+	switch {
+	case req.MarketingOptIn && !customer.MarketingOptIn:
+		now := time.Now()
+		customer.MarketingOptInAt = &now
+	case !req.MarketingOptIn:
+		customer.MarketingOptInAt = nil
+	}
+	customer.MarketingOptIn = req.MarketingOptIn
+	if err := s.db.Omit("LoyaltyPoints").Save(customer).Error; err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+// DeleteCustomer soft-deletes a customer; their orders keep referring to them
+func (s *CustomerService) DeleteCustomer(id uint) (*models.Customers, error) {
+	customer, err := findCustomer(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Delete(customer).Error; err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+// customerKey returns how a customer is identified for per-customer coupon limits: by email,
+// or by phone number for customers without one, the way cashiers would type them in
+func customerKey(customer *models.Customers) string {
+	if customer.Email != "" {
+		return customer.Email
+	}
+	return customer.Phone
+}
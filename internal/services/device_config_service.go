@@ -0,0 +1,118 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// DeviceConfigEventHook runs once a device's configuration is replaced, with the configuration
+// its terminal now gets
+type DeviceConfigEventHook func(config models.TerminalConfigResponse)
+
+type DeviceConfigService struct {
+	db    *gorm.DB
+	hooks []DeviceConfigEventHook
+}
+
+func NewDeviceConfigService(db *gorm.DB) *DeviceConfigService {
+	return &DeviceConfigService{db: db}
+}
+
+// AfterUpdate subscribes a hook to every replaced device configuration. Hooks are not
+// synchronized and must be subscribed before the service is used.
+func (s *DeviceConfigService) AfterUpdate(hook DeviceConfigEventHook) {
+	s.hooks = append(s.hooks, hook)
+}
+
+// GetTerminalConfig returns the effective configuration for a device,
+// falling back to defaults when the device has not been configured yet
+func (s *DeviceConfigService) GetTerminalConfig(deviceID uint) (*models.TerminalConfigResponse, error) {
+	var config models.DeviceConfigs
+	if err := s.db.Where("device_id = ?", deviceID).First(&config).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.TerminalConfigResponse{
+				DeviceID:       deviceID,
+				EnabledTenders: models.DefaultEnabledTenders,
+				TaxMode:        models.TaxModeExclusive,
+				FeatureFlags:   map[string]bool{},
+			}, nil
+		}
+		return nil, err
+	}
+
+	return toTerminalConfigResponse(&config), nil
+}
+
+// UpdateDeviceConfig replaces the configuration of a device
+func (s *DeviceConfigService) UpdateDeviceConfig(deviceID uint, req *models.UpdateDeviceConfigRequest) (*models.TerminalConfigResponse, error) {
+	var device models.Devices
+	if err := s.db.Where("id = ?", deviceID).First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("device not found")
+		}
+		return nil, err
+	}
+
+	var config models.DeviceConfigs
+	err := s.db.Where("device_id = ?", device.ID).First(&config).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	flags := models.JSONMap{}
+	for key, value := range req.FeatureFlags {
+		flags[key] = value
+	}
+
+	config.DeviceID = device.ID
+	config.ReceiptHeader = req.ReceiptHeader
+	config.EnabledTenders = req.EnabledTenders
+	config.TaxMode = req.TaxMode
+	config.FeatureFlags = flags
+
+	if err := s.db.Save(&config).Error; err != nil {
+		return nil, err
+	}
+
+	response := toTerminalConfigResponse(&config)
+	for _, hook := range s.hooks {
+		hook(*response)
+	}
+	return response, nil
+}
+
+// TerminalConfigETag computes a strong ETag for a configuration payload
+func TerminalConfigETag(config *models.TerminalConfigResponse) (string, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf(`"%s"`, hex.EncodeToString(sum[:16])), nil
+}
+
+// toTerminalConfigResponse converts a stored configuration to the terminal payload
+func toTerminalConfigResponse(config *models.DeviceConfigs) *models.TerminalConfigResponse {
+	flags := map[string]bool{}
+	for key, value := range config.FeatureFlags {
+		if enabled, ok := value.(bool); ok {
+			flags[key] = enabled
+		}
+	}
+
+	updatedAt := config.UpdatedAt
+	return &models.TerminalConfigResponse{
+		DeviceID:       config.DeviceID,
+		ReceiptHeader:  config.ReceiptHeader,
+		EnabledTenders: config.EnabledTenders,
+		TaxMode:        config.TaxMode,
+		FeatureFlags:   flags,
+		UpdatedAt:      &updatedAt,
+	}
+}
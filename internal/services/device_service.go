@@ -0,0 +1,254 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+)
+
+const (
+	pairingCodeLength   = 8
+	pairingCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // no 0/O/1/I to avoid typos on terminals
+	pairingCodeTTL      = 15 * time.Minute
+	deviceTokenBytes    = 32
+)
+
+type DeviceService struct {
+	db *gorm.DB
+}
+
+func NewDeviceService(db *gorm.DB) *DeviceService {
+	return &DeviceService{db: db}
+}
+
+// HashDeviceToken returns the stored representation of a device token
+func HashDeviceToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// generatePairingCode generates a short human-friendly pairing code
+func generatePairingCode() (string, error) {
+	code := make([]byte, pairingCodeLength)
+	max := big.NewInt(int64(len(pairingCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = pairingCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// generateDeviceToken generates a random device token
+func generateDeviceToken() (string, error) {
+	buf := make([]byte, deviceTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// GetAllDevices retrieves devices with pagination, search, and filters
+func (s *DeviceService) GetAllDevices(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.Devices{},
+		SearchFields: []string{"name", "location"},
+		FilterFields: map[string]string{
+			"status":   "status",
+			"location": "location",
+			"store_id": "store_id",
+		},
+		StoreFilter: "store_id = ?",
+		DateFields: map[string]pagination.DateField{
+			"last_seen_at": {
+				Start: "last_seen_at",
+				End:   "last_seen_at",
+			},
+		},
+		SortFields: []string{
+			"name",
+			"location",
+			"status",
+			"last_seen_at",
+			"created_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetDeviceById retrieves a device by ID
+func (s *DeviceService) GetDeviceById(id uint) (*models.Devices, error) {
+	var device models.Devices
+	if err := s.db.Where("id = ?", id).First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("device not found")
+		}
+		return nil, err
+	}
+	return &device, nil
+}
+
+// CreateDevice registers a new device and issues its first pairing code
+func (s *DeviceService) CreateDevice(req *models.CreateDeviceRequest) (*models.PairingCodeResponse, error) {
+	if req.StoreID != nil {
+		if _, err := findStore(s.db, *req.StoreID); err != nil {
+			return nil, err
+		}
+	}
+
+	device := models.Devices{
+		Name:     req.Name,
+		Location: req.Location,
+		StoreID:  req.StoreID,
+		Status:   models.DeviceStatusPending,
+	}
+
+	if err := s.db.Create(&device).Error; err != nil {
+		return nil, err
+	}
+
+	return s.issuePairingCode(&device)
+}
+
+// UpdateDevice updates a device's name and its location and store binding
+func (s *DeviceService) UpdateDevice(id uint, req *models.UpdateDeviceRequest) (*models.Devices, error) {
+	device, err := s.GetDeviceById(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.StoreID != nil {
+		if _, err := findStore(s.db, *req.StoreID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.db.Model(device).Updates(map[string]interface{}{
+		"name":     req.Name,
+		"location": req.Location,
+		"store_id": req.StoreID,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// RegeneratePairingCode issues a new pairing code, revoking the current device token.
+// This is used to re-pair a replaced or reset terminal.
+func (s *DeviceService) RegeneratePairingCode(id uint) (*models.PairingCodeResponse, error) {
+	device, err := s.GetDeviceById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issuePairingCode(device)
+}
+
+// issuePairingCode stores a new pairing code on the device and resets it to pending
+func (s *DeviceService) issuePairingCode(device *models.Devices) (*models.PairingCodeResponse, error) {
+	code, err := generatePairingCode()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(pairingCodeTTL)
+
+	if err := s.db.Model(device).Updates(map[string]interface{}{
+		"status":                  models.DeviceStatusPending,
+		"pairing_code":            code,
+		"pairing_code_expires_at": expiresAt,
+		"token_hash":              nil,
+		"deactivated_at":          nil,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return &models.PairingCodeResponse{
+		Device:      *device,
+		PairingCode: code,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// PairDevice exchanges a valid pairing code for a device token
+func (s *DeviceService) PairDevice(req *models.PairDeviceRequest) (*models.PairDeviceResponse, error) {
+	var device models.Devices
+	if err := s.db.Where("pairing_code = ?", req.PairingCode).First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid pairing code")
+		}
+		return nil, err
+	}
+
+	if device.PairingCodeExpiresAt == nil || time.Now().After(*device.PairingCodeExpiresAt) {
+		return nil, errors.New("pairing code expired")
+	}
+
+	token, err := generateDeviceToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	if err := s.db.Model(&device).Updates(map[string]interface{}{
+		"status":                  models.DeviceStatusActive,
+		"token_hash":              HashDeviceToken(token),
+		"pairing_code":            nil,
+		"pairing_code_expires_at": nil,
+		"paired_at":               now,
+		"last_seen_at":            now,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return &models.PairDeviceResponse{
+		Device:      device,
+		DeviceToken: token,
+	}, nil
+}
+
+// DeactivateDevice remotely deactivates a device, revoking its token
+func (s *DeviceService) DeactivateDevice(id uint) (*models.Devices, error) {
+	device, err := s.GetDeviceById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(device).Updates(map[string]interface{}{
+		"status":                  models.DeviceStatusDeactivated,
+		"token_hash":              nil,
+		"pairing_code":            nil,
+		"pairing_code_expires_at": nil,
+		"deactivated_at":          time.Now(),
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// DeleteDevice deletes a device from the registry
+func (s *DeviceService) DeleteDevice(id uint) (*models.Devices, error) {
+	device, err := s.GetDeviceById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Delete(device).Error; err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// experimentBuckets is the resolution of the rollout and variant hashing
+const experimentBuckets = 10000
+
+type ExperimentService struct {
+	db *gorm.DB
+}
+
+func NewExperimentService(db *gorm.DB) *ExperimentService {
+	return &ExperimentService{db: db}
+}
+
+// experimentBucket hashes a subject into a stable bucket for an experiment.
+// The salt keeps the rollout and variant buckets independent of each other.
+func experimentBucket(key, salt, subjectType string, subjectID uint) int {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%s:%s:%d", key, salt, subjectType, subjectID)))
+	return int(binary.BigEndian.Uint32(sum[:4]) % experimentBuckets)
+}
+
+// pickVariant deterministically selects a variant for a subject, or returns false when
+// the subject falls outside the experiment's rollout
+func pickVariant(experiment *models.Experiments, subjectID uint) (string, bool) {
+	if len(experiment.Variants) == 0 {
+		return "", false
+	}
+	if experimentBucket(experiment.Key, "rollout", experiment.SubjectType, subjectID) >= experiment.Rollout*experimentBuckets/100 {
+		return "", false
+	}
+
+	total := 0
+	for _, variant := range experiment.Variants {
+		total += variant.Weight
+	}
+
+	point := experimentBucket(experiment.Key, "variant", experiment.SubjectType, subjectID) * total / experimentBuckets
+	for _, variant := range experiment.Variants {
+		if point < variant.Weight {
+			return variant.Name, true
+		}
+		point -= variant.Weight
+	}
+	return experiment.Variants[len(experiment.Variants)-1].Name, true
+}
+
+// ValidateExperiment checks an experiment request and returns the problems found
+func ValidateExperiment(req *models.ExperimentRequest) map[string]string {
+	problems := map[string]string{}
+
+	seen := map[string]bool{}
+	for _, variant := range req.Variants {
+		if seen[variant.Name] {
+			problems["variants"] = fmt.Sprintf("duplicate variant %q", variant.Name)
+		}
+		seen[variant.Name] = true
+	}
+
+	return problems
+}
+
+// GetAllExperiments retrieves experiments with pagination and filters
+func (s *ExperimentService) GetAllExperiments(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.Experiments{},
+		SearchFields: []string{"key", "name", "description"},
+		FilterFields: map[string]string{
+			"status":       "status",
+			"subject_type": "subject_type",
+		},
+		SortFields: []string{
+			"key",
+			"name",
+			"status",
+			"created_at",
+		},
+		DefaultSort:  "key",
+		DefaultOrder: "ASC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetExperimentById retrieves an experiment by ID
+func (s *ExperimentService) GetExperimentById(id uint) (*models.Experiments, error) {
+	var experiment models.Experiments
+	if err := s.db.Where("id = ?", id).First(&experiment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("experiment not found")
+		}
+		return nil, err
+	}
+	return &experiment, nil
+}
+
+// CreateExperiment creates a new experiment in draft status
+func (s *ExperimentService) CreateExperiment(req *models.ExperimentRequest) (*models.Experiments, error) {
+	var existing models.Experiments
+	if err := s.db.Where("key = ?", req.Key).First(&existing).Error; err == nil {
+		return nil, errors.New("experiment key already exists")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	experiment := models.Experiments{
+		Key:         req.Key,
+		Name:        req.Name,
+		Description: req.Description,
+		SubjectType: req.SubjectType,
+		Status:      models.ExperimentStatusDraft,
+		Rollout:     100,
+		Variants:    req.Variants,
+	}
+	if req.Rollout != nil {
+		experiment.Rollout = *req.Rollout
+	}
+
+	if err := s.db.Create(&experiment).Error; err != nil {
+		return nil, err
+	}
+
+	return &experiment, nil
+}
+
+// UpdateExperiment updates an experiment. Once an experiment has started, its key and
+// subject type are fixed so existing assignments keep their meaning.
+func (s *ExperimentService) UpdateExperiment(id uint, req *models.ExperimentRequest) (*models.Experiments, error) {
+	experiment, err := s.GetExperimentById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if experiment.StartedAt != nil && (req.Key != experiment.Key || req.SubjectType != experiment.SubjectType) {
+		return nil, errors.New("experiment key and subject type can not change after it has started")
+	}
+
+	var existing models.Experiments
+	if err := s.db.Where("key = ? AND id <> ?", req.Key, experiment.ID).First(&existing).Error; err == nil {
+		return nil, errors.New("experiment key already exists")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	experiment.Key = req.Key
+	experiment.Name = req.Name
+	experiment.Description = req.Description
+	experiment.SubjectType = req.SubjectType
+	experiment.Variants = req.Variants
+	if req.Rollout != nil {
+		experiment.Rollout = *req.Rollout
+	}
+
+	if err := s.db.Save(experiment).Error; err != nil {
+		return nil, err
+	}
+
+	return experiment, nil
+}
+
+// UpdateExperimentStatus starts, stops, or resets an experiment
+func (s *ExperimentService) UpdateExperimentStatus(id uint, status string) (*models.Experiments, error) {
+	experiment, err := s.GetExperimentById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{"status": status}
+	now := time.Now()
+	switch status {
+	case models.ExperimentStatusRunning:
+		if experiment.StartedAt == nil {
+			updates["started_at"] = now
+		}
+		updates["stopped_at"] = nil
+	case models.ExperimentStatusStopped:
+		updates["stopped_at"] = now
+	case models.ExperimentStatusDraft:
+		if experiment.StartedAt != nil {
+			return nil, errors.New("experiment has already started")
+		}
+	}
+
+	if err := s.db.Model(experiment).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	return experiment, nil
+}
+
+// DeleteExperiment deletes an experiment and its assignments
+func (s *ExperimentService) DeleteExperiment(id uint) (*models.Experiments, error) {
+	experiment, err := s.GetExperimentById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("experiment_id = ?", experiment.ID).Delete(&models.ExperimentAssignments{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(experiment).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return experiment, nil
+}
+
+// GetExperimentAssignments retrieves the subjects assigned to an experiment
+func (s *ExperimentService) GetExperimentAssignments(id uint, params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	experiment, err := s.GetExperimentById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	config := pagination.PaginationConfig{
+		Model: &models.ExperimentAssignments{},
+		BaseCondition: map[string]interface{}{
+			"experiment_id": experiment.ID,
+		},
+		FilterFields: map[string]string{
+			"variant":    "variant",
+			"subject_id": "subject_id",
+		},
+		SortFields: []string{
+			"variant",
+			"created_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// assign returns the persisted variant for a subject, assigning one on first use.
+// Returns false when the subject is not enrolled in the experiment.
+func (s *ExperimentService) assign(experiment *models.Experiments, subjectID uint) (string, bool, error) {
+	var assignment models.ExperimentAssignments
+	err := s.db.Where("experiment_id = ? AND subject_type = ? AND subject_id = ?", experiment.ID, experiment.SubjectType, subjectID).
+		First(&assignment).Error
+	if err == nil {
+		return assignment.Variant, true, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", false, err
+	}
+
+	variant, ok := pickVariant(experiment, subjectID)
+	if !ok {
+		return "", false, nil
+	}
+
+	assignment = models.ExperimentAssignments{
+		ExperimentID: experiment.ID,
+		SubjectType:  experiment.SubjectType,
+		SubjectID:    subjectID,
+		Variant:      variant,
+	}
+	// A concurrent request may have assigned the subject first; both computed the same variant
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&assignment).Error; err != nil {
+		return "", false, err
+	}
+
+	return variant, true, nil
+}
+
+// VariantFor returns the variant of a running experiment for a subject.
+// Other modules (e.g., pricing or promotions) use this to trial rule changes on a subset of subjects;
+// ok is false when the experiment is not running or the subject is not enrolled.
+func (s *ExperimentService) VariantFor(key string, subjectType string, subjectID uint) (variant string, ok bool, err error) {
+	var experiment models.Experiments
+	if err := s.db.Where("key = ? AND status = ?", key, models.ExperimentStatusRunning).First(&experiment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	if experiment.SubjectType != subjectType {
+		return "", false, nil
+	}
+
+	return s.assign(&experiment, subjectID)
+}
+
+// GetSubjectExperiments returns the variants of every running experiment the subject is enrolled in, keyed by experiment key
+func (s *ExperimentService) GetSubjectExperiments(subjectType string, subjectID uint) (map[string]string, error) {
+	var experiments []models.Experiments
+	if err := s.db.Where("subject_type = ? AND status = ?", subjectType, models.ExperimentStatusRunning).
+		Order("key ASC").
+		Find(&experiments).Error; err != nil {
+		return nil, err
+	}
+
+	variants := map[string]string{}
+	for i := range experiments {
+		variant, ok, err := s.assign(&experiments[i], subjectID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			variants[experiments[i].Key] = variant
+		}
+	}
+
+	return variants, nil
+}
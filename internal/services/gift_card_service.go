@@ -0,0 +1,440 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// giftCardCodeLength is long enough that codes can't be guessed, since knowing one is enough to
+// spend the card
+const giftCardCodeLength = 16
+
+// GiftCardService issues gift cards and keeps their balances. Cards are paid with through the
+// gift card payment method; every change to a balance is recorded in the ledger in the same
+// transaction.
+type GiftCardService struct {
+	db *gorm.DB
+}
+
+func NewGiftCardService(db *gorm.DB) *GiftCardService {
+	return &GiftCardService{db: db}
+}
+
+// normalizeGiftCardCode returns a code the way cards are stored, so codes match regardless of
+// case and of the dashes and spaces they are printed with
+func normalizeGiftCardCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// maskGiftCardCode hides all but the last four characters of a code
+func maskGiftCardCode(code string) string {
+	if len(code) <= 4 {
+		return code
+	}
+	return strings.Repeat("*", len(code)-4) + code[len(code)-4:]
+}
+
+// generateGiftCardCode generates a random code from the pairing code alphabet
+func generateGiftCardCode() (string, error) {
+	code := make([]byte, giftCardCodeLength)
+	max := big.NewInt(int64(len(pairingCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = pairingCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// GetAllGiftCards retrieves gift cards with pagination and filters on status, customer and order
+func (s *GiftCardService) GetAllGiftCards(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.GiftCards{},
+		SearchFields: []string{"code", "note"},
+		FilterFields: map[string]string{
+			"status":      "status",
+			"customer_id": "customer_id",
+			"order_id":    "order_id",
+		},
+		DateFields: map[string]pagination.DateField{
+			"created_at": {
+				Start: "created_at",
+				End:   "created_at",
+			},
+		},
+		SortFields: []string{
+			"balance",
+			"initial_balance",
+			"created_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetGiftCardById retrieves a gift card
+func (s *GiftCardService) GetGiftCardById(id uint) (*models.GiftCards, error) {
+	var card models.GiftCards
+	if err := s.db.Where("id = ?", id).First(&card).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("gift card not found")
+		}
+		return nil, err
+	}
+	return &card, nil
+}
+
+// GetTransactions retrieves the ledger of a gift card, newest first
+func (s *GiftCardService) GetTransactions(id uint, params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	if _, err := s.GetGiftCardById(id); err != nil {
+		return nil, err
+	}
+
+	config := pagination.PaginationConfig{
+		Model:         &models.GiftCardTransactions{},
+		BaseCondition: map[string]interface{}{"gift_card_id": id},
+		FilterFields: map[string]string{
+			"type":     "type",
+			"order_id": "order_id",
+		},
+		DateFields: map[string]pagination.DateField{
+			"created_at": {
+				Start: "created_at",
+				End:   "created_at",
+			},
+		},
+		SortFields: []string{
+			"created_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// CheckBalance looks a card up by its code
+func (s *GiftCardService) CheckBalance(code string) (*models.GiftCardBalanceResponse, error) {
+	var card models.GiftCards
+	if err := s.db.Where("code = ?", normalizeGiftCardCode(code)).First(&card).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("gift card not found")
+		}
+		return nil, err
+	}
+	return &models.GiftCardBalanceResponse{
+		Code:    maskGiftCardCode(card.Code),
+		Balance: card.Balance,
+		Status:  card.Status,
+	}, nil
+}
+
+// issueGiftCardTx creates a card worth its initial balance under a fresh code and records the issue
+func issueGiftCardTx(tx *gorm.DB, card *models.GiftCards) error {
+	for attempt := 0; ; attempt++ {
+		code, err := generateGiftCardCode()
+		if err != nil {
+			return err
+		}
+		var taken int64
+		if err := tx.Model(&models.GiftCards{}).Where("code = ?", code).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken == 0 {
+			card.Code = code
+			break
+		}
+		if attempt == 5 {
+			return errors.New("could not generate a unique gift card code")
+		}
+	}
+
+	card.Balance = card.InitialBalance
+	card.Status = models.GiftCardStatusActive
+	if err := tx.Create(card).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.GiftCardTransactions{
+		GiftCardID:  card.ID,
+		Type:        models.GiftCardIssued,
+		Amount:      card.InitialBalance,
+		Balance:     card.Balance,
+		OrderID:     card.OrderID,
+		Reason:      card.Note,
+		CreatedByID: card.IssuedByID,
+	}).Error
+}
+
+// IssueGiftCard issues a gift card by hand, e.g. as a goodwill gesture
+func (s *GiftCardService) IssueGiftCard(req *models.IssueGiftCardRequest, actor models.ActivityActor) (*models.GiftCards, error) {
+	if req.CustomerID != nil {
+		if _, err := findCustomer(s.db, *req.CustomerID); err != nil {
+			return nil, err
+		}
+	}
+
+	card := models.GiftCards{
+		InitialBalance: req.Amount,
+		CustomerID:     req.CustomerID,
+		Note:           req.Note,
+		IssuedByID:     actorID(actor),
+	}
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		return issueGiftCardTx(tx, &card)
+	}); err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// lockGiftCard loads a card matching the condition and locks it for update
+func lockGiftCard(tx *gorm.DB, query string, args ...interface{}) (*models.GiftCards, error) {
+	var card models.GiftCards
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(query, args...).First(&card).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("gift card not found")
+		}
+		return nil, err
+	}
+	return &card, nil
+}
+
+// addGiftCardBalanceTx changes a locked card's balance by entry.Amount and records the entry with the
+// new balance. Value can only be taken from the balance the card has.
+func addGiftCardBalanceTx(tx *gorm.DB, card *models.GiftCards, entry *models.GiftCardTransactions) error {
+	balance := card.Balance + entry.Amount
+	if balance < 0 {
+		return errors.New("insufficient gift card balance")
+	}
+	if err := tx.Model(card).UpdateColumn("balance", balance).Error; err != nil {
+		return err
+	}
+	card.Balance = balance
+
+	entry.GiftCardID = card.ID
+	entry.Balance = balance
+	return tx.Create(entry).Error
+}
+
+// voidGiftCardTx voids a locked card, taking what is left on it
+func voidGiftCardTx(tx *gorm.DB, card *models.GiftCards, orderID *uint, reason string, actor models.ActivityActor) error {
+	if card.Status == models.GiftCardStatusVoid {
+		return nil
+	}
+	if card.Balance > 0 {
+		if err := addGiftCardBalanceTx(tx, card, &models.GiftCardTransactions{
+			Type:        models.GiftCardVoided,
+			Amount:      -card.Balance,
+			OrderID:     orderID,
+			Reason:      reason,
+			CreatedByID: actorID(actor),
+		}); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	card.Status = models.GiftCardStatusVoid
+	card.VoidedAt = &now
+	card.VoidedByID = actorID(actor)
+	return tx.Model(card).Updates(map[string]interface{}{
+		"status":       card.Status,
+		"voided_at":    card.VoidedAt,
+		"voided_by_id": card.VoidedByID,
+	}).Error
+}
+
+// VoidGiftCard voids a card, e.g. when it was lost, so its balance can't be spent anymore
+func (s *GiftCardService) VoidGiftCard(id uint, req *models.VoidGiftCardRequest, actor models.ActivityActor) (*models.GiftCards, error) {
+	var card *models.GiftCards
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		card, err = lockGiftCard(tx, "id = ?", id)
+		if err != nil {
+			return err
+		}
+		if card.Status == models.GiftCardStatusVoid {
+			return errors.New("gift card void")
+		}
+		return voidGiftCardTx(tx, card, nil, req.Reason, actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+// redeemTx takes a gift card payment from the card with the code the customer handed over. The
+// card is locked, so concurrent payments can't spend the same balance twice.
+func (s *GiftCardService) redeemTx(tx *gorm.DB, order *models.Orders, payment *models.Payments, code string, actor models.ActivityActor) error {
+	card, err := lockGiftCard(tx, "code = ?", normalizeGiftCardCode(code))
+	if err != nil {
+		return err
+	}
+	if card.Status != models.GiftCardStatusActive {
+		return errors.New("gift card void")
+	}
+	return addGiftCardBalanceTx(tx, card, &models.GiftCardTransactions{
+		Type:        models.GiftCardRedeemed,
+		Amount:      -payment.Amount,
+		OrderID:     &order.ID,
+		PaymentID:   &payment.ID,
+		CreatedByID: actorID(actor),
+	})
+}
+
+// refundTx puts the refunded part of a gift card payment back on the card it was paid with.
+// Voided cards are not credited, since whoever holds them may not be the customer anymore.
+func (s *GiftCardService) refundTx(tx *gorm.DB, order *models.Orders, payment *models.Payments, amount int64, actor models.ActivityActor) error {
+	var redeemed models.GiftCardTransactions
+	if err := tx.Where("payment_id = ? AND type = ?", payment.ID, models.GiftCardRedeemed).First(&redeemed).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("gift card not found")
+		}
+		return err
+	}
+	card, err := lockGiftCard(tx, "id = ?", redeemed.GiftCardID)
+	if err != nil {
+		return err
+	}
+	if card.Status != models.GiftCardStatusActive {
+		return errors.New("gift card void")
+	}
+	return addGiftCardBalanceTx(tx, card, &models.GiftCardTransactions{
+		Type:        models.GiftCardRefunded,
+		Amount:      amount,
+		OrderID:     &order.ID,
+		PaymentID:   &payment.ID,
+		CreatedByID: actorID(actor),
+	})
+}
+
+// giftCardProducts returns which of the products are gift cards
+func giftCardProducts(db *gorm.DB, productIDs []uint) (map[uint]bool, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+	var ids []uint
+	if err := db.Unscoped().Model(&models.Products{}).
+		Where("id IN ? AND gift_card", uniqueIDs(productIDs)).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	giftCards := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		giftCards[id] = true
+	}
+	return giftCards, nil
+}
+
+// giftCardValue is what the nth unit of a gift card line is worth: its share of the line's
+// total, with the cents rounding leaves spread over the units so the cards add up to the total
+func giftCardValue(item *models.OrderItems, n int64) int64 {
+	return item.Total*(n+1)/item.Quantity - item.Total*n/item.Quantity
+}
+
+// OrderGiftCardHook issues and voids the gift cards orders sell; subscribe it with
+// OrderService.OnTransition. Every unit of a gift card product becomes a card worth its share of
+// the line's total once the order is paid, for the order's customer. Cancelling or refunding
+// the order voids its cards, taking what is left on them.
+func (s *GiftCardService) OrderGiftCardHook(tx *gorm.DB, transition OrderTransition) error {
+	order := transition.Order
+	switch transition.To {
+	case models.OrderStatusPaid:
+		productIDs := make([]uint, len(order.Items))
+		for i, item := range order.Items {
+			productIDs[i] = item.ProductID
+		}
+		giftCards, err := giftCardProducts(tx, productIDs)
+		if err != nil {
+			return err
+		}
+
+		for i := range order.Items {
+			item := &order.Items[i]
+			if !giftCards[item.ProductID] {
+				continue
+			}
+			for n := range item.Quantity {
+				value := giftCardValue(item, n)
+				if value <= 0 {
+					continue
+				}
+				card := models.GiftCards{
+					InitialBalance: value,
+					CustomerID:     order.CustomerID,
+					OrderID:        &order.ID,
+					OrderItemID:    &item.ID,
+					Note:           "Sold on order " + order.Number,
+					IssuedByID:     actorID(transition.Actor),
+				}
+				if err := issueGiftCardTx(tx, &card); err != nil {
+					return err
+				}
+			}
+		}
+	case models.OrderStatusCancelled, models.OrderStatusRefunded:
+		var cards []models.GiftCards
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("order_id = ? AND status = ?", order.ID, models.GiftCardStatusActive).
+			Order("id").Find(&cards).Error; err != nil {
+			return err
+		}
+		for i := range cards {
+			if err := voidGiftCardTx(tx, &cards[i], &order.ID, transition.Reason, transition.Actor); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ReturnGiftCardHook voids the cards of the gift card units a return brings back; subscribe it
+// with ReturnService.OnReturn. Only cards nothing was spent from can be taken back, so the
+// return fails if the line doesn't have enough of them left.
+func (s *GiftCardService) ReturnGiftCardHook(tx *gorm.DB, ret OrderReturn) error {
+	productIDs := make([]uint, len(ret.Return.Items))
+	for i, item := range ret.Return.Items {
+		productIDs[i] = item.ProductID
+	}
+	giftCards, err := giftCardProducts(tx, productIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range ret.Return.Items {
+		// Units worth nothing were never issued a card
+		if !giftCards[item.ProductID] || item.Amount == 0 {
+			continue
+		}
+		var cards []models.GiftCards
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("order_item_id = ? AND status = ? AND balance = initial_balance", item.OrderItemID, models.GiftCardStatusActive).
+			Order("id").Limit(int(item.Quantity)).Find(&cards).Error; err != nil {
+			return err
+		}
+		if int64(len(cards)) < item.Quantity {
+			return errors.New("gift card spent")
+		}
+		for i := range cards {
+			if err := voidGiftCardTx(tx, &cards[i], &ret.Order.ID, ret.Reason, ret.Actor); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
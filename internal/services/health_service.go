@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/database"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// healthCheckTimeout bounds each dependency check so a hung dependency fails the probe instead
+// of outlasting it
+const healthCheckTimeout = 2 * time.Second
+
+type HealthService struct {
+	db    *gorm.DB
+	cache *cache.Cache
+}
+
+func NewHealthService(db *gorm.DB, cache *cache.Cache) *HealthService {
+	return &HealthService{
+		db:    db,
+		cache: cache,
+	}
+}
+
+// Live reports that the process is up and serving; it checks no dependencies so a database
+// outage doesn't get every instance restarted
+func (s *HealthService) Live() models.HealthResponse {
+	return models.HealthResponse{Status: models.HealthStatusUp, CheckedAt: time.Now()}
+}
+
+// Ready checks the dependencies requests need: the database answers and its migrations have
+// run, and Redis answers. The service is down without the database and degraded without Redis,
+// which it falls back from.
+func (s *HealthService) Ready(ctx context.Context) models.HealthResponse {
+	checks := map[string]models.HealthCheck{
+		"database":   s.check(ctx, s.pingDatabase),
+		"migrations": s.checkMigrations(ctx),
+		"redis":      s.checkRedis(ctx),
+	}
+
+	status := models.HealthStatusUp
+	switch {
+	case checks["database"].Status != models.HealthStatusUp, checks["migrations"].Status != models.HealthStatusUp:
+		status = models.HealthStatusDown
+	case checks["redis"].Status == models.HealthStatusDown:
+		status = models.HealthStatusDegraded
+	}
+	return models.HealthResponse{Status: status, Checks: checks, CheckedAt: time.Now()}
+}
+
+// check times a dependency check
+func (s *HealthService) check(ctx context.Context, fn func(ctx context.Context) error) models.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+	check := models.HealthCheck{
+		Status:    models.HealthStatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		check.Status = models.HealthStatusDown
+		check.Error = err.Error()
+	}
+	return check
+}
+
+// pingDatabase pings the connection pool, opening a connection if none is idle
+func (s *HealthService) pingDatabase(ctx context.Context) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// checkMigrations reports the tables still waiting for their migration
+func (s *HealthService) checkMigrations(ctx context.Context) models.HealthCheck {
+	var pending []string
+	check := s.check(ctx, func(ctx context.Context) error {
+		var err error
+		pending, err = database.PendingMigrations(ctx, s.db)
+		return err
+	})
+	if check.Status == models.HealthStatusUp && len(pending) > 0 {
+		check.Status = models.HealthStatusDown
+		check.Pending = pending
+	}
+	return check
+}
+
+// checkRedis pings Redis, unless no Redis is configured
+func (s *HealthService) checkRedis(ctx context.Context) models.HealthCheck {
+	if s.cache.State() == cache.StateDisabled {
+		return models.HealthCheck{Status: models.HealthStatusDisabled}
+	}
+	return s.check(ctx, s.cache.Ping)
+}
@@ -0,0 +1,927 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/importer"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// importClaimTimeout is how long an import may stay processing before it is considered
+// abandoned, e.g. by an instance that was stopped, and processed again. Its transaction was
+// rolled back with the instance, so nothing is imported twice.
+const importClaimTimeout = time.Hour
+
+// importBatchSize is how many records are inserted per statement, and how often the progress of
+// an import is recorded
+const importBatchSize = 500
+
+// productImportInlineRows is the most rows a catalog upload may have to be imported while the
+// client waits; larger files go to the background import job
+const productImportInlineRows = 1000
+
+// importOrderStatuses are the statuses imported orders may have; drafts never left the old POS
+var importOrderStatuses = []string{
+	models.OrderStatusPlaced,
+	models.OrderStatusPaid,
+	models.OrderStatusFulfilled,
+	models.OrderStatusCompleted,
+	models.OrderStatusCancelled,
+	models.OrderStatusRefunded,
+}
+
+// ImportFileError reports why an uploaded file can't be imported at all
+type ImportFileError struct {
+	Errors models.ImportRowErrors
+}
+
+func (e *ImportFileError) Error() string {
+	return fmt.Sprintf("invalid import file: %d problems", len(e.Errors))
+}
+
+// errImportRejected rolls back an import whose rows have errors
+var errImportRejected = errors.New("import has row errors")
+
+// errImportDryRun rolls back a dry run that would have succeeded
+var errImportDryRun = errors.New("import is a dry run")
+
+// ImportService loads products and order history exported by another POS. Files are checked
+// when they are uploaded and imported in the background, each in a single transaction: an
+// import with any bad row creates nothing and reports every problem, so it can be fixed and
+// uploaded again. Files waiting to be imported are charged to the storage usage of the tenant
+// and store they were uploaded in.
+type ImportService struct {
+	db       *gorm.DB
+	products *ProductService
+	quota    *StorageQuotaService
+}
+
+func NewImportService(db *gorm.DB, products *ProductService, quota *StorageQuotaService) *ImportService {
+	return &ImportService{
+		db:       db,
+		products: products,
+		quota:    quota,
+	}
+}
+
+// importOwner returns who an import's file is charged to
+func importOwner(imp *models.Imports) models.StorageOwner {
+	return models.StorageOwner{TenantID: imp.TenantID, StoreID: imp.StoreID}
+}
+
+// GetAllImports retrieves imports with pagination and filters on entity and status
+func (s *ImportService) GetAllImports(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.Imports{},
+		SearchFields: []string{"filename"},
+		FilterFields: map[string]string{
+			"entity":        "entity",
+			"status":        "status",
+			"created_by_id": "created_by_id",
+		},
+		SortFields: []string{
+			"status",
+			"total_rows",
+			"created_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetImportById retrieves an import with its row errors
+func (s *ImportService) GetImportById(id uint) (*models.Imports, error) {
+	var imp models.Imports
+	if err := s.db.Where("id = ?", id).First(&imp).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("import not found")
+		}
+		return nil, err
+	}
+	return &imp, nil
+}
+
+// prepareImport checks an uploaded file against the fields of the entity and returns the import
+// to queue. Upserts match rows on SKU, so only that column is required; rows creating products
+// still need a name.
+func prepareImport(entity string, formatName string, mappingJSON string, upsert bool, filename string, data []byte, actor models.ActivityActor) (*models.Imports, error) {
+	format, err := importer.ParseFormat(formatName, filename)
+	if err != nil {
+		return nil, &ImportFileError{Errors: models.ImportRowErrors{{Field: "format", Error: err.Error()}}}
+	}
+
+	fields := models.ImportFields[entity]
+	mapping := map[string]string{}
+	if mappingJSON != "" {
+		if err := json.Unmarshal([]byte(mappingJSON), &mapping); err != nil {
+			return nil, &ImportFileError{Errors: models.ImportRowErrors{{Field: "mapping", Error: "mapping must be a JSON object of field names to column names"}}}
+		}
+	}
+	var problems models.ImportRowErrors
+	for field := range mapping {
+		if _, ok := fields[field]; !ok {
+			problems = append(problems, models.ImportRowError{Field: field, Error: "unknown field"})
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &ImportFileError{Errors: problems}
+	}
+
+	records, err := importer.Read(format, data, mapping)
+	if err != nil {
+		return nil, &ImportFileError{Errors: models.ImportRowErrors{{Error: err.Error()}}}
+	}
+	if len(records) == 0 {
+		return nil, &ImportFileError{Errors: models.ImportRowErrors{{Error: "file has no records"}}}
+	}
+	if err := limits.Check(limits.MaxImportRows, len(records)); err != nil {
+		return nil, err
+	}
+
+	// Catch unmapped columns now rather than with one error per row in the background
+	for field, required := range fields {
+		if upsert {
+			required = field == "sku"
+		}
+		if _, ok := records[0][field]; required && !ok {
+			problems = append(problems, models.ImportRowError{Field: field, Error: "required field is not in the file; map it to a column"})
+		}
+	}
+	if len(problems) > 0 {
+		slices.SortFunc(problems, func(a, b models.ImportRowError) int { return strings.Compare(a.Field, b.Field) })
+		return nil, &ImportFileError{Errors: problems}
+	}
+
+	storedMapping := make(models.JSONMap, len(mapping))
+	for field, column := range mapping {
+		storedMapping[field] = column
+	}
+	return &models.Imports{
+		Entity:      entity,
+		Format:      string(format),
+		Filename:    filename,
+		Mapping:     storedMapping,
+		Data:        encodeImportData(format, data),
+		Upsert:      upsert,
+		Status:      models.ImportStatusPending,
+		TotalRows:   len(records),
+		Errors:      models.ImportRowErrors{},
+		CreatedByID: actorID(actor),
+	}, nil
+}
+
+// encodeImportData turns a file into text for the import's data column
+func encodeImportData(format importer.Format, data []byte) string {
+	if format == importer.FormatXLSX {
+		return base64.StdEncoding.EncodeToString(data)
+	}
+	return string(data)
+}
+
+// decodeImportData returns the file stored with an import
+func decodeImportData(imp *models.Imports) ([]byte, error) {
+	if importer.Format(imp.Format) == importer.FormatXLSX {
+		return base64.StdEncoding.DecodeString(imp.Data)
+	}
+	return []byte(imp.Data), nil
+}
+
+// saveImport records an import and charges its file to the owner, failing with a
+// *StorageQuotaError when the file doesn't fit in the owner's storage quota
+func (s *ImportService) saveImport(imp *models.Imports, owner models.StorageOwner) error {
+	imp.TenantID = owner.TenantID
+	imp.StoreID = owner.StoreID
+	imp.DataBytes = int64(len(imp.Data))
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(imp).Error; err != nil {
+			return err
+		}
+		return s.quota.chargeTx(tx, owner, imp.DataBytes, 1)
+	})
+}
+
+// CreateImport checks an uploaded file against the fields of the entity and queues it
+func (s *ImportService) CreateImport(req *models.CreateImportRequest, filename string, data []byte, owner models.StorageOwner, actor models.ActivityActor) (*models.Imports, error) {
+	imp, err := prepareImport(req.Entity, req.Format, req.Mapping, false, filename, data, actor)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.saveImport(imp, owner); err != nil {
+		return nil, err
+	}
+	return imp, nil
+}
+
+// DeleteImport removes an import, and its file when it wasn't imported yet. Imports being
+// processed can't be deleted until they finish or are abandoned.
+func (s *ImportService) DeleteImport(id uint) (*models.Imports, error) {
+	var imp models.Imports
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&imp).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("import not found")
+			}
+			return err
+		}
+		if imp.Status == models.ImportStatusProcessing && imp.StartedAt != nil && time.Since(*imp.StartedAt) < importClaimTimeout {
+			return errors.New("import is processing")
+		}
+		if err := tx.Delete(&imp).Error; err != nil {
+			return err
+		}
+		if imp.DataBytes == 0 {
+			return nil
+		}
+		return releaseStorageTx(tx, importOwner(&imp), imp.DataBytes, 1)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &imp, nil
+}
+
+// ImportProducts checks a catalog file and upserts its products by SKU. Files of up to
+// productImportInlineRows rows are imported right away and returned finished; larger ones are
+// queued for the background import job and reported as queued, to be followed through the
+// import's progress.
+func (s *ImportService) ImportProducts(ctx context.Context, req *models.ProductImportRequest, filename string, data []byte, owner models.StorageOwner, actor models.ActivityActor) (*models.Imports, bool, error) {
+	imp, err := prepareImport(models.ImportEntityProducts, req.Format, req.Mapping, true, filename, data, actor)
+	if err != nil {
+		return nil, false, err
+	}
+	imp.DryRun = req.DryRun
+
+	if imp.TotalRows > productImportInlineRows {
+		if err := s.saveImport(imp, owner); err != nil {
+			return nil, false, err
+		}
+		return imp, true, nil
+	}
+
+	now := time.Now()
+	imp.Status = models.ImportStatusProcessing
+	imp.StartedAt = &now
+	if err := s.saveImport(imp, owner); err != nil {
+		return nil, false, err
+	}
+	s.runImport(ctx, imp)
+
+	imp, err = s.GetImportById(imp.ID)
+	return imp, false, err
+}
+
+// ProcessImports imports every queued file. It is the entry point of the background import
+// job; claiming imports with a row lock keeps several API instances from importing the same
+// file.
+func (s *ImportService) ProcessImports(ctx context.Context) error {
+	for ctx.Err() == nil {
+		imp, err := s.claimImport(ctx)
+		if err != nil {
+			return err
+		}
+		if imp == nil {
+			return nil
+		}
+		s.runImport(ctx, imp)
+	}
+	return ctx.Err()
+}
+
+// claimImport marks the oldest queued import as processing. Returns nil when there is none.
+func (s *ImportService) claimImport(ctx context.Context) (*models.Imports, error) {
+	var imp models.Imports
+	now := time.Now()
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? OR (status = ? AND started_at < ?)",
+				models.ImportStatusPending,
+				models.ImportStatusProcessing, now.Add(-importClaimTimeout)).
+			Order("id ASC").
+			First(&imp).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&imp).Updates(map[string]interface{}{
+			"status":     models.ImportStatusProcessing,
+			"started_at": now,
+		}).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &imp, nil
+}
+
+// runImport imports the records of a claimed import and records the outcome. The file is
+// dropped either way, and taken off the owner's storage usage; failed imports are fixed and
+// uploaded again. Dry runs go through the whole import and roll it back at the end.
+func (s *ImportService) runImport(ctx context.Context, imp *models.Imports) {
+	mapping := make(map[string]string, len(imp.Mapping))
+	for field, column := range imp.Mapping {
+		if name, ok := column.(string); ok {
+			mapping[field] = name
+		}
+	}
+
+	var rowErrs models.ImportRowErrors
+	var products importedProducts
+	var records []importer.Record
+	data, err := decodeImportData(imp)
+	if err == nil {
+		records, err = importer.Read(importer.Format(imp.Format), data, mapping)
+	}
+	if err == nil {
+		err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			switch imp.Entity {
+			case models.ImportEntityProducts:
+				products, rowErrs, err = s.importProducts(tx, imp, records)
+			case models.ImportEntityOrders:
+				rowErrs, err = s.importOrders(tx, imp, records)
+			case models.ImportEntityCustomers:
+				rowErrs, err = s.importCustomers(tx, imp, records)
+			default:
+				err = fmt.Errorf("unknown import entity %s", imp.Entity)
+			}
+			if err != nil {
+				return err
+			}
+			if len(rowErrs) > 0 {
+				return errImportRejected
+			}
+			if imp.DryRun {
+				return errImportDryRun
+			}
+			return nil
+		})
+	}
+
+	created := len(records)
+	if imp.Entity == models.ImportEntityProducts {
+		created = len(products.created)
+	}
+	updates := map[string]interface{}{
+		"status":         models.ImportStatusCompleted,
+		"processed_rows": len(records),
+		"imported_rows":  len(records),
+		"created_rows":   created,
+		"updated_rows":   len(products.updated),
+		"errors":         models.ImportRowErrors{},
+		"data":           "",
+		"data_bytes":     0,
+		"finished_at":    time.Now(),
+	}
+	switch {
+	case errors.Is(err, errImportDryRun):
+		updates["imported_rows"] = 0
+	case err != nil:
+		if !errors.Is(err, errImportRejected) {
+			slog.ErrorContext(ctx, "Import failed", "import_id", imp.ID, "error", err)
+			rowErrs = models.ImportRowErrors{{Error: "the import could not be written; nothing was imported"}}
+		}
+		updates["status"] = models.ImportStatusFailed
+		updates["imported_rows"] = 0
+		updates["created_rows"] = 0
+		updates["updated_rows"] = 0
+		updates["errors"] = rowErrs
+	}
+	if err := s.finishImport(imp, updates); err != nil {
+		slog.ErrorContext(ctx, "Failed to record the outcome of an import", "import_id", imp.ID, "error", err)
+	}
+
+	if err != nil {
+		return
+	}
+	for _, productID := range products.created {
+		s.products.productChanged(productID, models.ChangeActionCreated)
+	}
+	for productID, previousBarcode := range products.updated {
+		s.products.productChanged(productID, models.ChangeActionUpdated, previousBarcode)
+	}
+}
+
+// finishImport records the outcome of an import and releases its file. The file's size is read
+// under a row lock, so an import that was deleted, or finished by another instance after being
+// abandoned, isn't released twice.
+func (s *ImportService) finishImport(imp *models.Imports, updates map[string]interface{}) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var stored models.Imports
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "tenant_id", "store_id", "data_bytes").
+			Where("id = ?", imp.ID).
+			First(&stored).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		if err := tx.Model(imp).Updates(updates).Error; err != nil {
+			return err
+		}
+		if stored.DataBytes == 0 {
+			return nil
+		}
+		return releaseStorageTx(tx, importOwner(&stored), stored.DataBytes, 1)
+	})
+}
+
+// recordProgress stores how many rows of a running import are done. It is written outside the
+// import's transaction so clients can follow it.
+func (s *ImportService) recordProgress(imp *models.Imports, rows int) {
+	if err := s.db.Model(imp).Update("processed_rows", rows).Error; err != nil {
+		slog.Warn("Failed to record the progress of an import", "import_id", imp.ID, "error", err)
+	}
+}
+
+// importRow collects the errors of one record while its fields are parsed
+type importRow struct {
+	number int
+	record importer.Record
+	errors models.ImportRowErrors
+}
+
+// fail records an error on a field of the row
+func (r *importRow) fail(field string, message string) {
+	r.errors = append(r.errors, models.ImportRowError{Row: r.number, Field: field, Error: message})
+}
+
+// text returns a field, failing on required fields that are empty and values that are too long
+func (r *importRow) text(field string, required bool, maxLength int) string {
+	value := r.record[field]
+	if value == "" && required {
+		r.fail(field, "is required")
+	}
+	if len(value) > maxLength {
+		r.fail(field, fmt.Sprintf("must be at most %d characters", maxLength))
+	}
+	return value
+}
+
+// amount returns an amount field, fallback when it is empty
+func (r *importRow) amount(field string, fallback int64) int64 {
+	value := r.record[field]
+	if value == "" {
+		return fallback
+	}
+	amount, err := importer.Amount(value)
+	if err != nil {
+		r.fail(field, err.Error())
+		return fallback
+	}
+	if amount < 0 {
+		r.fail(field, "must not be negative")
+	}
+	return amount
+}
+
+// integer returns an integer field between min and max, fallback when it is empty
+func (r *importRow) integer(field string, fallback int64, min int64, max int64) int64 {
+	value := r.record[field]
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		r.fail(field, fmt.Sprintf("%q is not a whole number", value))
+		return fallback
+	}
+	if parsed < min || parsed > max {
+		r.fail(field, fmt.Sprintf("must be between %d and %d", min, max))
+	}
+	return parsed
+}
+
+// takenSKUs returns which of the SKUs existing products and variants already use
+func takenSKUs(tx *gorm.DB, skus []string) (map[string]bool, error) {
+	taken := make(map[string]bool, len(skus))
+	for batch := range slices.Chunk(skus, importBatchSize) {
+		var found []string
+		if err := tx.Model(&models.Products{}).Where("sku IN ?", batch).Pluck("sku", &found).Error; err != nil {
+			return nil, err
+		}
+		var variants []string
+		if err := tx.Model(&models.ProductVariants{}).Where("sku IN ?", batch).Pluck("sku", &variants).Error; err != nil {
+			return nil, err
+		}
+		for _, sku := range append(found, variants...) {
+			taken[sku] = true
+		}
+	}
+	return taken, nil
+}
+
+// importedProducts are the products an import created, and those it updated with the barcode
+// they had before
+type importedProducts struct {
+	created []uint
+	updated map[uint]string
+}
+
+// importProducts creates a product per record. SKUs must be new, unless the import is an upsert:
+// rows with the SKU of an existing product then update it, and their empty cells leave its
+// values as they are. Categories are matched by name, ignoring case.
+func (s *ImportService) importProducts(tx *gorm.DB, imp *models.Imports, records []importer.Record) (importedProducts, models.ImportRowErrors, error) {
+	var result importedProducts
+
+	var categories []models.Categories
+	if err := tx.Select("id", "name").Find(&categories).Error; err != nil {
+		return result, nil, err
+	}
+	categoryIDs := make(map[string]uint, len(categories))
+	ambiguous := map[string]bool{}
+	for _, category := range categories {
+		name := strings.ToLower(category.Name)
+		if _, ok := categoryIDs[name]; ok {
+			ambiguous[name] = true
+		}
+		categoryIDs[name] = category.ID
+	}
+
+	skus := make([]string, 0, len(records))
+	for _, record := range records {
+		if record["sku"] != "" {
+			skus = append(skus, record["sku"])
+		}
+	}
+	taken, err := takenSKUs(tx, skus)
+	if err != nil {
+		return result, nil, err
+	}
+	existing := map[string]models.Products{}
+	if imp.Upsert {
+		for batch := range slices.Chunk(skus, importBatchSize) {
+			var found []models.Products
+			if err := tx.Where("sku IN ?", batch).Find(&found).Error; err != nil {
+				return result, nil, err
+			}
+			for _, product := range found {
+				existing[product.SKU] = product
+			}
+		}
+	}
+
+	var rowErrs models.ImportRowErrors
+	creates := make([]models.Products, 0, len(records))
+	var updates []models.Products
+	previousBarcodes := map[uint]string{}
+	seen := make(map[string]int, len(records))
+	for i, record := range records {
+		row := importRow{number: i + 1, record: record}
+		sku := row.text("sku", true, 100)
+		current, update := existing[sku]
+
+		product := models.Products{
+			SKU:      sku,
+			Status:   models.ProductStatusActive,
+			ImportID: &imp.ID,
+		}
+		if update {
+			product = current
+		}
+		if name := row.text("name", !update, 255); name != "" {
+			product.Name = name
+		}
+		if barcode := row.text("barcode", false, 100); barcode != "" {
+			product.Barcode = barcode
+		}
+		if description := row.text("description", false, 5000); description != "" {
+			product.Description = description
+		}
+		product.Price = row.amount("price", product.Price)
+		product.Cost = row.amount("cost", product.Cost)
+
+		if taken[sku] && !update {
+			row.fail("sku", "is already used by another product or variant")
+		} else if first, ok := seen[sku]; ok && sku != "" {
+			row.fail("sku", fmt.Sprintf("is also used in row %d", first))
+		}
+		seen[sku] = row.number
+
+		if status := strings.ToLower(record["status"]); status != "" {
+			if !slices.Contains([]string{models.ProductStatusActive, models.ProductStatusInactive, models.ProductStatusArchived}, status) {
+				row.fail("status", "must be active, inactive or archived")
+			}
+			product.Status = status
+		}
+		if record["reorder_point"] != "" {
+			reorderPoint := row.integer("reorder_point", 0, 0, 1<<31)
+			product.ReorderPoint = &reorderPoint
+		}
+		if name := strings.ToLower(record["category"]); name != "" {
+			categoryID, ok := categoryIDs[name]
+			switch {
+			case !ok:
+				row.fail("category", "no category has this name")
+			case ambiguous[name]:
+				row.fail("category", "several categories have this name")
+			default:
+				product.CategoryID = &categoryID
+			}
+		}
+
+		rowErrs = append(rowErrs, row.errors...)
+		if update {
+			previousBarcodes[product.ID] = current.Barcode
+			updates = append(updates, product)
+		} else {
+			creates = append(creates, product)
+		}
+	}
+	if len(rowErrs) > 0 {
+		return result, rowErrs, nil
+	}
+
+	done := 0
+	for batch := range slices.Chunk(creates, importBatchSize) {
+		if err := tx.Create(&batch).Error; err != nil {
+			return result, nil, err
+		}
+		for _, product := range batch {
+			result.created = append(result.created, product.ID)
+		}
+		done += len(batch)
+		s.recordProgress(imp, done)
+	}
+	result.updated = make(map[uint]string, len(updates))
+	for i := range updates {
+		if err := tx.Omit("Variants", "Images").Save(&updates[i]).Error; err != nil {
+			return result, nil, err
+		}
+		result.updated[updates[i].ID] = previousBarcodes[updates[i].ID]
+		done++
+		if done%importBatchSize == 0 {
+			s.recordProgress(imp, done)
+		}
+	}
+	return result, nil, nil
+}
+
+// orderStatusTimes sets the timestamps of the statuses an imported order went through
+func orderStatusTimes(order *models.Orders, at time.Time) {
+	order.CreatedAt = at
+	order.PlacedAt = &at
+	switch order.Status {
+	case models.OrderStatusCompleted:
+		order.CompletedAt = &at
+		fallthrough
+	case models.OrderStatusFulfilled:
+		order.FulfilledAt = &at
+		fallthrough
+	case models.OrderStatusPaid:
+		order.PaidAt = &at
+	case models.OrderStatusRefunded:
+		order.PaidAt = &at
+		order.RefundedAt = &at
+	case models.OrderStatusCancelled:
+		order.CancelledAt = &at
+	}
+}
+
+// importOrders creates past orders from records holding one line each; lines of the same order
+// share its number, and the order's date, status and note come from its first line. Lines refer
+// to products and variants by SKU, so products are imported first. Past orders take no stock
+// and keep the number they had, and are dated when they were placed so reports include them.
+func (s *ImportService) importOrders(tx *gorm.DB, imp *models.Imports, records []importer.Record) (models.ImportRowErrors, error) {
+	numbers := make([]string, 0, len(records))
+	skus := make([]string, 0, len(records))
+	for _, record := range records {
+		numbers = append(numbers, record["order_number"])
+		skus = append(skus, record["sku"])
+	}
+	numbers = slices.Compact(slices.Sorted(slices.Values(numbers)))
+	skus = slices.Compact(slices.Sorted(slices.Values(skus)))
+
+	// Deleted products and variants still resolve, since past sales may include them
+	existing := map[string]bool{}
+	productsBySKU := map[string]models.Products{}
+	variantsBySKU := map[string]models.ProductVariants{}
+	productsByID := map[uint]models.Products{}
+	for batch := range slices.Chunk(numbers, importBatchSize) {
+		var found []string
+		if err := tx.Model(&models.Orders{}).Where("number IN ?", batch).Pluck("number", &found).Error; err != nil {
+			return nil, err
+		}
+		for _, number := range found {
+			existing[number] = true
+		}
+	}
+	for batch := range slices.Chunk(skus, importBatchSize) {
+		var products []models.Products
+		if err := tx.Unscoped().Where("sku IN ?", batch).Find(&products).Error; err != nil {
+			return nil, err
+		}
+		for _, product := range products {
+			productsBySKU[product.SKU] = product
+			productsByID[product.ID] = product
+		}
+		var variants []models.ProductVariants
+		if err := tx.Unscoped().Where("sku IN ?", batch).Find(&variants).Error; err != nil {
+			return nil, err
+		}
+		for _, variant := range variants {
+			variantsBySKU[variant.SKU] = variant
+		}
+	}
+	var variantProductIDs []uint
+	for _, variant := range variantsBySKU {
+		if _, ok := productsByID[variant.ProductID]; !ok {
+			variantProductIDs = append(variantProductIDs, variant.ProductID)
+		}
+	}
+	if len(variantProductIDs) > 0 {
+		var products []models.Products
+		if err := tx.Unscoped().Where("id IN ?", uniqueIDs(variantProductIDs)).Find(&products).Error; err != nil {
+			return nil, err
+		}
+		for _, product := range products {
+			productsByID[product.ID] = product
+		}
+	}
+
+	var rowErrs models.ImportRowErrors
+	var orders []*models.Orders
+	byNumber := map[string]*models.Orders{}
+	for i, record := range records {
+		row := importRow{number: i + 1, record: record}
+		number := row.text("order_number", true, 30)
+
+		order, ok := byNumber[number]
+		if !ok {
+			order = &models.Orders{
+				Number:      number,
+				Status:      strings.ToLower(record["status"]),
+				Note:        row.text("note", false, 255),
+				CreatedByID: imp.CreatedByID,
+				ImportID:    &imp.ID,
+			}
+			byNumber[number] = order
+			orders = append(orders, order)
+
+			if existing[number] {
+				row.fail("order_number", "an order with this number already exists")
+			}
+			if order.Status == "" {
+				order.Status = models.OrderStatusCompleted
+			}
+			if !slices.Contains(importOrderStatuses, order.Status) {
+				row.fail("status", "must be one of "+strings.Join(importOrderStatuses, ", "))
+			}
+			placedAt, err := importer.Time(row.text("placed_at", true, 50))
+			if err != nil && record["placed_at"] != "" {
+				row.fail("placed_at", err.Error())
+			}
+			orderStatusTimes(order, placedAt)
+		}
+
+		item := models.OrderItems{
+			Quantity:  row.integer("quantity", 0, 1, 1<<31),
+			Discount:  row.amount("discount", 0),
+			Taxes:     manualLineTaxes(row.integer("tax_rate", 0, 0, 10000)),
+			CreatedAt: order.CreatedAt,
+		}
+		sku := row.text("sku", true, 100)
+		if variant, ok := variantsBySKU[sku]; ok {
+			product := productsByID[variant.ProductID]
+			item.ProductID = product.ID
+			item.VariantID = &variant.ID
+			item.Name = product.Name
+			item.VariantTitle = variant.Title
+			item.UnitPrice = variant.Price
+			item.UnitCost = variant.Cost
+		} else if product, ok := productsBySKU[sku]; ok {
+			item.ProductID = product.ID
+			item.Name = product.Name
+			item.UnitPrice = product.Price
+			item.UnitCost = product.Cost
+		} else if sku != "" {
+			row.fail("sku", "no product or variant has this SKU; import products first")
+		}
+		item.SKU = sku
+		if name := row.text("name", false, 255); name != "" {
+			item.Name = name
+		}
+		item.UnitPrice = row.amount("unit_price", item.UnitPrice)
+		item.UnitCost = row.amount("unit_cost", item.UnitCost)
+
+		item.Subtotal = item.Quantity * item.UnitPrice
+		if item.Discount > item.Subtotal {
+			row.fail("discount", "exceeds the line amount")
+		}
+		applyLineTax(&item)
+
+		order.Items = append(order.Items, item)
+		order.ItemCount += item.Quantity
+		order.Subtotal += item.Subtotal
+		order.DiscountTotal += item.Discount
+		order.TaxTotal += item.Tax
+		order.Total += item.Total
+		if len(order.Items) == limits.Get(limits.MaxOrderItems)+1 {
+			row.fail("order_number", fmt.Sprintf("order has more than %d lines", limits.Get(limits.MaxOrderItems)))
+		}
+
+		rowErrs = append(rowErrs, row.errors...)
+	}
+	if len(rowErrs) > 0 {
+		return rowErrs, nil
+	}
+
+	for _, order := range orders {
+		for _, tax := range taxBreakdown(order.Items) {
+			order.Taxes = append(order.Taxes, models.OrderTaxes{TaxBreakdown: tax, CreatedAt: order.CreatedAt})
+		}
+	}
+	for batch := range slices.Chunk(orders, importBatchSize) {
+		if err := tx.Create(batch).Error; err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// importCustomers creates a customer per record. Emails must be new, ignoring case; customers
+// who opted in to marketing are recorded as opting in when imported.
+func (s *ImportService) importCustomers(tx *gorm.DB, imp *models.Imports, records []importer.Record) (models.ImportRowErrors, error) {
+	emails := make([]string, 0, len(records))
+	for _, record := range records {
+		if email := strings.ToLower(strings.TrimSpace(record["email"])); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	taken := make(map[string]bool, len(emails))
+	for batch := range slices.Chunk(emails, importBatchSize) {
+		var found []string
+		if err := tx.Model(&models.Customers{}).Where("LOWER(email) IN ?", batch).Pluck("LOWER(email)", &found).Error; err != nil {
+			return nil, err
+		}
+		for _, email := range found {
+			taken[email] = true
+		}
+	}
+
+	var rowErrs models.ImportRowErrors
+	now := time.Now()
+	customers := make([]models.Customers, 0, len(records))
+	seen := make(map[string]int, len(records))
+	for i, record := range records {
+		row := importRow{number: i + 1, record: record}
+		customer := models.Customers{
+			Name:        row.text("name", true, 255),
+			Email:       strings.TrimSpace(row.text("email", false, 255)),
+			Phone:       strings.TrimSpace(row.text("phone", false, 50)),
+			Notes:       row.text("notes", false, 5000),
+			ImportID:    &imp.ID,
+			CreatedByID: imp.CreatedByID,
+		}
+
+		if email := strings.ToLower(customer.Email); email != "" {
+			if _, err := mail.ParseAddress(customer.Email); err != nil {
+				row.fail("email", "is not a valid email address")
+			} else if taken[email] {
+				row.fail("email", "is already used by another customer")
+			} else if first, ok := seen[email]; ok {
+				row.fail("email", fmt.Sprintf("is also used in row %d", first))
+			}
+			seen[email] = row.number
+		}
+
+		switch strings.ToLower(strings.TrimSpace(record["marketing_opt_in"])) {
+		case "", "false", "no", "0":
+		case "true", "yes", "1":
+			customer.MarketingOptIn = true
+			customer.MarketingOptInAt = &now
+		default:
+			row.fail("marketing_opt_in", "must be true or false")
+		}
+
+		rowErrs = append(rowErrs, row.errors...)
+		customers = append(customers, customer)
+	}
+	if len(rowErrs) > 0 {
+		return rowErrs, nil
+	}
+
+	return nil, tx.CreateInBatches(&customers, importBatchSize).Error
+}
@@ -0,0 +1,383 @@
+package services
+
+import (
+	"errors"
+	"log/slog"
+	"sort"
+
+	"github.com/Aebroyx/the-blade-api/internal/config"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// stockLevelUpsertSQL adds a quantity to a stock level, creating the level on first use, and
+// returns the new quantity. The conflict target is the index added by migrateStockLevels.
+const stockLevelUpsertSQL = `INSERT INTO stock_levels (product_id, variant_id, store_id, quantity, updated_at)
+VALUES (?, ?, ?, ?, NOW())
+ON CONFLICT (product_id, (COALESCE(variant_id, 0)), (COALESCE(store_id, 0)))
+DO UPDATE SET quantity = stock_levels.quantity + EXCLUDED.quantity, updated_at = EXCLUDED.updated_at
+RETURNING quantity`
+
+// StockEventHook runs once stock changes are committed, with the stock levels they changed
+type StockEventHook func(levels []models.StockLevels)
+
+// InventoryService keeps stock levels and the movements ledger. Stock only changes through
+// RecordMovements, so every level can be traced back to its movements.
+type InventoryService struct {
+	db              *gorm.DB
+	config          *config.Config
+	activityService *ActivityService
+	notifications   *NotificationService
+	eventHooks      []StockEventHook
+}
+
+func NewInventoryService(db *gorm.DB, config *config.Config, activityService *ActivityService, notifications *NotificationService) *InventoryService {
+	return &InventoryService{
+		db:              db,
+		config:          config,
+		activityService: activityService,
+		notifications:   notifications,
+	}
+}
+
+// AfterStockChange subscribes a hook to every committed stock change. Hooks are not
+// synchronized and must be subscribed before the service is used.
+func (s *InventoryService) AfterStockChange(hook StockEventHook) {
+	s.eventHooks = append(s.eventHooks, hook)
+}
+
+// stockChanged runs the hooks subscribed with AfterStockChange with the current stock levels the
+// movements changed. Callers of RecordMovements run it once their transaction has committed.
+// Failing to load the levels is logged rather than returned, since the stock has already changed.
+func (s *InventoryService) stockChanged(inputs []models.StockMovementInput) {
+	if len(s.eventHooks) == 0 || len(inputs) == 0 {
+		return
+	}
+
+	changed := make(map[stockLevelKey]bool, len(inputs))
+	productIDs := make([]uint, len(inputs))
+	for i, input := range inputs {
+		changed[stockKey(input)] = true
+		productIDs[i] = input.ProductID
+	}
+	var levels []models.StockLevels
+	if err := s.db.Where("product_id IN ?", uniqueIDs(productIDs)).Order("id").Find(&levels).Error; err != nil {
+		slog.Error("Failed to load changed stock levels", "products", len(changed), "error", err)
+		return
+	}
+	matched := make([]models.StockLevels, 0, len(changed))
+	for _, level := range levels {
+		if changed[stockKey(models.StockMovementInput{ProductID: level.ProductID, VariantID: level.VariantID, StoreID: level.StoreID})] {
+			matched = append(matched, level)
+		}
+	}
+	if len(matched) == 0 {
+		return
+	}
+
+	for _, hook := range s.eventHooks {
+		hook(matched)
+	}
+}
+
+// validMovementType reports whether the type is a known stock movement type
+func validMovementType(movementType string) bool {
+	switch movementType {
+	case models.StockMovementSale, models.StockMovementPurchase, models.StockMovementAdjustment, models.StockMovementTransfer, models.StockMovementReturn:
+		return true
+	}
+	return false
+}
+
+// RecordMovements applies stock changes and writes them to the ledger. It runs in the caller's
+// transaction so stock changes commit or roll back together with whatever caused them, e.g. a
+// sale. Products with variants track stock per variant. Stock may go negative, since sales
+// are never blocked by stock that was not counted in. Quantities given in another unit of
+// measure are converted into the product's stock unit. The serials and batches of tracked
+// products move along with their stock.
+func (s *InventoryService) RecordMovements(tx *gorm.DB, inputs []models.StockMovementInput) ([]models.StockMovements, error) {
+	if len(inputs) == 0 {
+		return []models.StockMovements{}, nil
+	}
+	if err := checkMovementTargets(tx, inputs); err != nil {
+		return nil, err
+	}
+	inputs, given, err := toStockUnits(tx, inputs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkTracking(tx, inputs); err != nil {
+		return nil, err
+	}
+
+	// Lock stock levels in a fixed order so concurrent movements can not deadlock
+	order := make([]int, len(inputs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return stockKey(inputs[order[a]]).less(stockKey(inputs[order[b]]))
+	})
+
+	movements := make([]models.StockMovements, len(inputs))
+	for _, i := range order {
+		input := inputs[i]
+		if !validMovementType(input.Type) {
+			return nil, errors.New("invalid stock movement type")
+		}
+		if input.Quantity == 0 {
+			return nil, errors.New("stock movement quantity must not be zero")
+		}
+
+		var balance int64
+		if err := tx.Raw(stockLevelUpsertSQL, input.ProductID, input.VariantID, input.StoreID, input.Quantity).Scan(&balance).Error; err != nil {
+			return nil, err
+		}
+
+		movement := models.StockMovements{
+			ProductID:    input.ProductID,
+			VariantID:    input.VariantID,
+			StoreID:      input.StoreID,
+			Type:         input.Type,
+			Quantity:     input.Quantity,
+			Balance:      balance,
+			Unit:         input.Unit,
+			UnitQuantity: given[i],
+			Reason:       input.Reason,
+			Reference:    input.Reference,
+			Note:         input.Note,
+			Serials:      models.JSONStringList(input.Serials),
+			Batch:        input.Batch,
+			CreatedByID:  input.CreatedByID,
+		}
+		if err := tx.Create(&movement).Error; err != nil {
+			return nil, err
+		}
+		movements[i] = movement
+	}
+
+	// Units are moved in the order given, so a transfer leaves its source before reaching its
+	// destination
+	for _, input := range inputs {
+		if err := s.trackMovement(tx, input); err != nil {
+			return nil, err
+		}
+	}
+
+	return movements, nil
+}
+
+// AdjustStock records stock changes outside of sales and purchases, such as damaged goods or
+// recount corrections. The movements and the audit entry of the user making the adjustment
+// are written in one transaction.
+func (s *InventoryService) AdjustStock(req *models.StockAdjustmentRequest, actor models.ActivityActor) ([]models.StockMovements, error) {
+	inputs := make([]models.StockMovementInput, len(req.Items))
+	items := make([]models.JSONMap, len(req.Items))
+	for i, item := range req.Items {
+		inputs[i] = models.StockMovementInput{
+			ProductID:   item.ProductID,
+			VariantID:   item.VariantID,
+			StoreID:     req.StoreID,
+			Type:        models.StockMovementAdjustment,
+			Quantity:    item.Quantity,
+			Unit:        item.Unit,
+			Reason:      req.Reason,
+			Reference:   req.Reference,
+			Note:        req.Note,
+			Serials:     item.Serials,
+			Batch:       item.Batch,
+			CreatedByID: actorID(actor),
+		}
+		items[i] = models.JSONMap{
+			"product_id": item.ProductID,
+			"variant_id": item.VariantID,
+			"quantity":   item.Quantity,
+			"unit":       item.Unit,
+		}
+	}
+
+	var movements []models.StockMovements
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		movements, err = s.RecordMovements(tx, inputs)
+		if err != nil {
+			return err
+		}
+
+		return s.activityService.RecordTx(tx, actor.UserID, models.ActivityStockAdjusted, actor, "Stock adjusted", models.JSONMap{
+			"reason":    req.Reason,
+			"reference": req.Reference,
+			"items":     items,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.stockChanged(inputs)
+	return movements, nil
+}
+
+// stockLevelKey identifies the stock level a movement changes
+type stockLevelKey struct {
+	product, variant, store uint
+}
+
+// stockKey returns the stock level a movement changes
+func stockKey(input models.StockMovementInput) stockLevelKey {
+	key := stockLevelKey{product: input.ProductID}
+	if input.VariantID != nil {
+		key.variant = *input.VariantID
+	}
+	if input.StoreID != nil {
+		key.store = *input.StoreID
+	}
+	return key
+}
+
+// less orders stock levels by product, variant and store
+func (k stockLevelKey) less(other stockLevelKey) bool {
+	if k.product != other.product {
+		return k.product < other.product
+	}
+	if k.variant != other.variant {
+		return k.variant < other.variant
+	}
+	return k.store < other.store
+}
+
+// checkMovementTargets makes sure every product exists, holds stock of its own rather than
+// through bundle components, and that variants are given exactly for products that have them
+func checkMovementTargets(tx *gorm.DB, inputs []models.StockMovementInput) error {
+	productIDs := make([]uint, 0, len(inputs))
+	for _, input := range inputs {
+		productIDs = append(productIDs, input.ProductID)
+	}
+	productIDs = uniqueIDs(productIDs)
+
+	var products []models.Products
+	if err := tx.Where("id IN ?", productIDs).Find(&products).Error; err != nil {
+		return err
+	}
+	if len(products) != len(productIDs) {
+		return errors.New("product not found")
+	}
+	for _, product := range products {
+		if product.Bundle {
+			return errors.New("product is a bundle")
+		}
+	}
+
+	var variants []models.ProductVariants
+	if err := tx.Where("product_id IN ?", productIDs).Find(&variants).Error; err != nil {
+		return err
+	}
+	hasVariants := map[uint]bool{}
+	variantProduct := map[uint]uint{}
+	for _, variant := range variants {
+		hasVariants[variant.ProductID] = true
+		variantProduct[variant.ID] = variant.ProductID
+	}
+
+	for _, input := range inputs {
+		if input.VariantID == nil {
+			if hasVariants[input.ProductID] {
+				return errors.New("variant required")
+			}
+			continue
+		}
+		if productID, ok := variantProduct[*input.VariantID]; !ok || productID != input.ProductID {
+			return errors.New("variant not found")
+		}
+	}
+	return nil
+}
+
+// GetStockLevels retrieves stock levels with the product's name and SKU, with pagination and
+// search on product name and SKU
+func (s *InventoryService) GetStockLevels(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model: &models.StockLevels{},
+		Joins: []pagination.JoinConfig{
+			{
+				Table:     "products",
+				Alias:     "p",
+				Type:      pagination.InnerJoin,
+				Condition: "p.id = stock_levels.product_id AND p.deleted_at IS NULL",
+			},
+			{
+				Table:     "product_variants",
+				Alias:     "pv",
+				Type:      pagination.LeftJoin,
+				Condition: "pv.id = stock_levels.variant_id",
+			},
+		},
+		SelectFields: []pagination.SelectField{
+			{Field: "stock_levels.id", Alias: "id"},
+			{Field: "stock_levels.product_id", Alias: "product_id"},
+			{Field: "stock_levels.variant_id", Alias: "variant_id"},
+			{Field: "stock_levels.store_id", Alias: "store_id"},
+			{Field: "stock_levels.quantity", Alias: "quantity"},
+			{Field: "stock_levels.updated_at", Alias: "updated_at"},
+			{Field: "p.name", Alias: "product_name"},
+			{Field: "pv.title", Alias: "variant_title"},
+			{Field: "COALESCE(pv.sku, p.sku)", Alias: "sku"},
+		},
+		ScanIntoMaps: true,
+		SearchFields: []string{"p.name", "p.sku", "pv.sku"},
+		FilterFields: map[string]string{
+			"product_id": "stock_levels.product_id",
+			"variant_id": "stock_levels.variant_id",
+			"store_id":   "stock_levels.store_id",
+		},
+		CustomFilters: map[string]string{
+			"category_id": "p.category_id IN (" + categorySubtreeSQL + ")",
+		},
+		StoreFilter: "stock_levels.store_id = ?",
+		SortFields: []string{
+			"product_name",
+			"sku",
+			"quantity",
+			"updated_at",
+		},
+		DefaultSort:  "product_name",
+		DefaultOrder: "ASC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetStockMovements retrieves the movements ledger with pagination and date filters
+func (s *InventoryService) GetStockMovements(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.StockMovements{},
+		SearchFields: []string{"reference", "note"},
+		FilterFields: map[string]string{
+			"product_id":    "product_id",
+			"variant_id":    "variant_id",
+			"store_id":      "store_id",
+			"type":          "type",
+			"reason":        "reason",
+			"reference":     "reference",
+			"created_by_id": "created_by_id",
+		},
+		StoreFilter: "store_id = ?",
+		DateFields: map[string]pagination.DateField{
+			"created_at": {
+				Start: "created_at",
+				End:   "created_at",
+			},
+		},
+		SortFields: []string{
+			"created_at",
+			"quantity",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
@@ -0,0 +1,477 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/receipt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultInvoiceDueDays is how long customers have to pay invoices issued without a due date
+const defaultInvoiceDueDays = 30
+
+// invoiceableOrderStatuses are the statuses of orders that can be invoiced: sold, and not
+// cancelled or refunded
+var invoiceableOrderStatuses = []string{
+	models.OrderStatusPlaced,
+	models.OrderStatusPaid,
+	models.OrderStatusFulfilled,
+	models.OrderStatusCompleted,
+}
+
+// InvoiceService issues invoices for orders sold to business customers and records the
+// payments made against them. Invoices are numbered per store from a locked sequence, so the
+// numbers of a store run without gaps.
+type InvoiceService struct {
+	db       *gorm.DB
+	orders   *OrderService
+	receipts *ReceiptService
+}
+
+func NewInvoiceService(db *gorm.DB, orders *OrderService, receipts *ReceiptService) *InvoiceService {
+	return &InvoiceService{
+		db:       db,
+		orders:   orders,
+		receipts: receipts,
+	}
+}
+
+// nextInvoiceNumber hands out the next number of a sequence, creating the sequence on first use.
+// The sequence row stays locked until the transaction ends, so concurrent invoices of the same
+// store wait their turn and a rolled back invoice gives its number back.
+func nextInvoiceNumber(tx *gorm.DB, scope string) (int64, error) {
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.InvoiceSequences{Scope: scope}).Error; err != nil {
+		return 0, err
+	}
+	var sequence models.InvoiceSequences
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("scope = ?", scope).First(&sequence).Error; err != nil {
+		return 0, err
+	}
+	sequence.LastNumber++
+	if err := tx.Model(&sequence).Update("last_number", sequence.LastNumber).Error; err != nil {
+		return 0, err
+	}
+	return sequence.LastNumber, nil
+}
+
+// findInvoice loads an invoice
+func findInvoice(db *gorm.DB, id uint) (*models.Invoices, error) {
+	var invoice models.Invoices
+	if err := db.Where("id = ?", id).First(&invoice).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invoice not found")
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// GetAllInvoices retrieves invoices with pagination, search on number and buyer, and filters
+// on status, customer, order and store. The overdue filter lists unpaid invoices past their
+// due date.
+func (s *InvoiceService) GetAllInvoices(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.Invoices{},
+		SearchFields: []string{"number", "buyer_name", "buyer_company"},
+		FilterFields: map[string]string{
+			"status":      "status",
+			"customer_id": "customer_id",
+			"order_id":    "order_id",
+			"store_id":    "store_id",
+		},
+		CustomFilters: map[string]string{
+			"overdue": "(balance > 0 AND status IN ('issued', 'partially_paid') AND due_at < NOW()) = ?",
+		},
+		StoreFilter: "store_id = ?",
+		DateFields: map[string]pagination.DateField{
+			"issued_at": {
+				Start: "issued_at",
+				End:   "issued_at",
+			},
+			"due_at": {
+				Start: "due_at",
+				End:   "due_at",
+			},
+		},
+		SortFields: []string{
+			"number",
+			"total",
+			"balance",
+			"issued_at",
+			"due_at",
+		},
+		DefaultSort:  "issued_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetInvoiceById retrieves an invoice with its lines, taxes and payments
+func (s *InvoiceService) GetInvoiceById(id uint) (*models.Invoices, error) {
+	var invoice models.Invoices
+	if err := s.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Taxes", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Payments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("paid_at").Order("id")
+	}).Where("id = ?", id).First(&invoice).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invoice not found")
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// CreateInvoice invoices an order to its customer. Seller details come from the order's store,
+// buyer details from the customer, and what the order was already paid at the till is recorded
+// against the invoice. An order is invoiced once, unless its invoice is voided.
+func (s *InvoiceService) CreateInvoice(req *models.CreateInvoiceRequest, actor models.ActivityActor) (*models.Invoices, error) {
+	var invoice models.Invoices
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		order, err := lockOrder(tx, req.OrderID)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(invoiceableOrderStatuses, order.Status) {
+			return errors.New("order can't be invoiced")
+		}
+		if order.CustomerID == nil {
+			return errors.New("order has no customer")
+		}
+		var invoiced int64
+		if err := tx.Model(&models.Invoices{}).Where("order_id = ? AND status <> ?", order.ID, models.InvoiceStatusVoid).Count(&invoiced).Error; err != nil {
+			return err
+		}
+		if invoiced > 0 {
+			return errors.New("order already invoiced")
+		}
+
+		customer, err := findCustomer(tx, *order.CustomerID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Preload("Components").Where("order_id = ?", order.ID).Order("id").Find(&order.Items).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("order_id = ?", order.ID).Order("id").Find(&order.Taxes).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		invoice = models.Invoices{
+			Status:        models.InvoiceStatusIssued,
+			OrderID:       order.ID,
+			CustomerID:    customer.ID,
+			StoreID:       order.StoreID,
+			BuyerName:     customer.Name,
+			BuyerCompany:  customer.Company,
+			BuyerAddress:  customer.BillingAddress,
+			BuyerTaxID:    customer.TaxID,
+			BuyerEmail:    customer.Email,
+			Subtotal:      order.Subtotal,
+			DiscountTotal: order.DiscountTotal,
+			TaxTotal:      order.TaxTotal,
+			IncludedTax:   order.IncludedTax,
+			Total:         order.Total,
+			Note:          strings.TrimSpace(req.Note),
+			Terms:         strings.TrimSpace(req.Terms),
+			IssuedAt:      now,
+			CreatedByID:   actorID(actor),
+		}
+
+		switch {
+		case req.DueAt != nil:
+			if req.DueAt.Before(now.Truncate(24 * time.Hour)) {
+				return errors.New("due date in the past")
+			}
+			invoice.DueAt = *req.DueAt
+		case req.DueDays != nil:
+			invoice.DueAt = now.AddDate(0, 0, *req.DueDays)
+		default:
+			invoice.DueAt = now.AddDate(0, 0, defaultInvoiceDueDays)
+		}
+
+		// Stores may have been deleted since the sale; their invoices still name them
+		scope := "store:none"
+		prefix := "INV-"
+		if order.StoreID != nil {
+			var store models.Stores
+			if err := tx.Unscoped().Where("id = ?", *order.StoreID).First(&store).Error; err != nil {
+				return err
+			}
+			scope = fmt.Sprintf("store:%d", store.ID)
+			prefix = "INV-" + store.Code + "-"
+			invoice.SellerName = store.Name
+			if store.LegalName != "" {
+				invoice.SellerName = store.LegalName
+			}
+			invoice.SellerAddress = store.Address
+			invoice.SellerTaxID = store.TaxID
+			invoice.SellerEmail = store.Email
+			invoice.SellerPhone = store.Phone
+		}
+		sequence, err := nextInvoiceNumber(tx, scope)
+		if err != nil {
+			return err
+		}
+		invoice.Sequence = sequence
+		invoice.Number = fmt.Sprintf("%s%06d", prefix, sequence)
+
+		for _, item := range order.Items {
+			description := item.Name
+			if item.VariantTitle != "" {
+				description += " - " + item.VariantTitle
+			}
+			invoice.Lines = append(invoice.Lines, models.InvoiceLines{
+				OrderItemID: item.ID,
+				Description: description,
+				SKU:         item.SKU,
+				Quantity:    item.Quantity,
+				UnitPrice:   item.UnitPrice,
+				Subtotal:    item.Subtotal,
+				Discount:    item.Discount,
+				TaxRate:     item.TaxRate,
+				Tax:         item.Tax,
+				Total:       item.Total,
+			})
+		}
+		for _, tax := range order.Taxes {
+			invoice.Taxes = append(invoice.Taxes, models.InvoiceTaxes{TaxBreakdown: tax.TaxBreakdown})
+		}
+
+		orderPayments, err := orderPayments(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		for _, payment := range orderPayments {
+			amount := payment.Amount - payment.RefundedAmount
+			if !slices.Contains(receiptPaymentStatuses, payment.Status) || amount <= 0 {
+				continue
+			}
+			paidAt := payment.CreatedAt
+			if payment.CapturedAt != nil {
+				paidAt = *payment.CapturedAt
+			}
+			invoice.Payments = append(invoice.Payments, models.InvoicePayments{
+				PaymentID:   &payment.ID,
+				Method:      payment.Method,
+				Amount:      amount,
+				Reference:   payment.Reference,
+				PaidAt:      paidAt,
+				CreatedByID: payment.CreatedByID,
+			})
+			invoice.AmountPaid += amount
+		}
+		settleInvoice(&invoice, now)
+
+		return tx.Create(&invoice).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetInvoiceById(invoice.ID)
+}
+
+// settleInvoice works out what is still due on an invoice and the status that follows from it
+func settleInvoice(invoice *models.Invoices, now time.Time) {
+	invoice.Balance = max(invoice.Total-invoice.AmountPaid, 0)
+	switch {
+	case invoice.Balance == 0:
+		invoice.Status = models.InvoiceStatusPaid
+		if invoice.PaidAt == nil {
+			invoice.PaidAt = &now
+		}
+	case invoice.AmountPaid > 0:
+		invoice.Status = models.InvoiceStatusPartiallyPaid
+	default:
+		invoice.Status = models.InvoiceStatusIssued
+	}
+}
+
+// RecordPayment records a payment received towards an invoice, such as a bank transfer. Once
+// the invoice is paid in full, its order is marked paid if it was still waiting for payment.
+func (s *InvoiceService) RecordPayment(id uint, req *models.InvoicePaymentRequest, actor models.ActivityActor) (*models.Invoices, error) {
+	var transition *OrderTransition
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var invoice models.Invoices
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&invoice).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("invoice not found")
+			}
+			return err
+		}
+		if invoice.Status == models.InvoiceStatusVoid {
+			return errors.New("invoice void")
+		}
+		if req.Amount > invoice.Balance {
+			return errors.New("payment exceeds balance")
+		}
+
+		now := time.Now()
+		payment := models.InvoicePayments{
+			InvoiceID:   invoice.ID,
+			Method:      strings.TrimSpace(req.Method),
+			Amount:      req.Amount,
+			Reference:   strings.TrimSpace(req.Reference),
+			PaidAt:      now,
+			CreatedByID: actorID(actor),
+		}
+		if req.PaidAt != nil {
+			payment.PaidAt = *req.PaidAt
+		}
+		if err := tx.Create(&payment).Error; err != nil {
+			return err
+		}
+
+		invoice.AmountPaid += req.Amount
+		settleInvoice(&invoice, now)
+		if err := tx.Model(&invoice).Updates(map[string]interface{}{
+			"amount_paid": invoice.AmountPaid,
+			"balance":     invoice.Balance,
+			"status":      invoice.Status,
+			"paid_at":     invoice.PaidAt,
+		}).Error; err != nil {
+			return err
+		}
+		if invoice.Status != models.InvoiceStatusPaid {
+			return nil
+		}
+
+		order, err := lockOrder(tx, invoice.OrderID)
+		if err != nil {
+			return err
+		}
+		if order.Status != models.OrderStatusPlaced {
+			return nil
+		}
+		if err := tx.Preload("Components").Where("order_id = ?", order.ID).Order("id").Find(&order.Items).Error; err != nil {
+			return err
+		}
+		paid, err := s.orders.paidTx(tx, order, "Invoice "+invoice.Number+" paid", actor)
+		if err != nil {
+			return err
+		}
+		transition = &paid
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if transition != nil {
+		s.orders.transitioned(*transition)
+	}
+	return s.GetInvoiceById(id)
+}
+
+// VoidInvoice voids an invoice issued in error. The invoice keeps its number, so the store's
+// numbering stays without gaps, and its order can be invoiced again.
+func (s *InvoiceService) VoidInvoice(id uint, req *models.VoidInvoiceRequest, actor models.ActivityActor) (*models.Invoices, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var invoice models.Invoices
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&invoice).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("invoice not found")
+			}
+			return err
+		}
+		if invoice.Status == models.InvoiceStatusVoid {
+			return errors.New("invoice void")
+		}
+
+		return tx.Model(&invoice).Updates(map[string]interface{}{
+			"status":       models.InvoiceStatusVoid,
+			"void_reason":  strings.TrimSpace(req.Reason),
+			"voided_at":    time.Now(),
+			"voided_by_id": actorID(actor),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetInvoiceById(id)
+}
+
+// RenderInvoice renders an invoice as a PDF with the currency of the receipt template of a
+// tenant and location, returning it with the invoice number
+func (s *InvoiceService) RenderInvoice(ctx context.Context, id uint, tenantID uint, location string) ([]byte, string, error) {
+	invoice, err := s.GetInvoiceById(id)
+	if err != nil {
+		return nil, "", err
+	}
+	template, err := s.receipts.Template(ctx, tenantID, location)
+	if err != nil {
+		return nil, "", err
+	}
+
+	inv := receipt.Invoice{
+		Number:   invoice.Number,
+		Status:   invoice.Status,
+		IssuedAt: invoice.IssuedAt,
+		DueAt:    invoice.DueAt,
+		Seller: receipt.Party{
+			Name:    invoice.SellerName,
+			Address: invoice.SellerAddress,
+			TaxID:   invoice.SellerTaxID,
+			Email:   invoice.SellerEmail,
+			Phone:   invoice.SellerPhone,
+		},
+		Buyer: receipt.Party{
+			Name:    invoice.BuyerName,
+			Company: invoice.BuyerCompany,
+			Address: invoice.BuyerAddress,
+			TaxID:   invoice.BuyerTaxID,
+			Email:   invoice.BuyerEmail,
+		},
+		Subtotal:   invoice.Subtotal,
+		Discount:   invoice.DiscountTotal,
+		Total:      invoice.Total,
+		AmountPaid: invoice.AmountPaid,
+		Balance:    invoice.Balance,
+		Note:       invoice.Note,
+		Terms:      invoice.Terms,
+	}
+	if invoice.Status == models.InvoiceStatusVoid {
+		inv.Balance = 0
+	}
+	for _, line := range invoice.Lines {
+		inv.Lines = append(inv.Lines, receipt.InvoiceLine{
+			Description: line.Description,
+			SKU:         line.SKU,
+			Quantity:    line.Quantity,
+			UnitPrice:   line.UnitPrice,
+			Discount:    line.Discount,
+			TaxRate:     line.TaxRate,
+			Total:       line.Subtotal - line.Discount,
+		})
+	}
+	for _, tax := range invoice.Taxes {
+		if tax.Amount != 0 {
+			inv.Taxes = append(inv.Taxes, receipt.Tax{Name: tax.Name, Rate: tax.Rate, Inclusive: tax.Inclusive, Amount: tax.Amount})
+		}
+	}
+	for _, payment := range invoice.Payments {
+		inv.Payments = append(inv.Payments, receipt.InvoicePayment{
+			Date:      payment.PaidAt,
+			Method:    payment.Method,
+			Reference: payment.Reference,
+			Amount:    payment.Amount,
+		})
+	}
+
+	return receipt.RenderInvoice(inv, template), invoice.Number, nil
+}
@@ -0,0 +1,304 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// kitchenTicketLimit caps the open tickets sent to a kitchen display at once
+const kitchenTicketLimit = 200
+
+// kitchenOrderStatuses are the statuses of orders whose tickets are on the kitchen display
+var kitchenOrderStatuses = []string{models.OrderStatusPlaced, models.OrderStatusPaid}
+
+// kitchenOpenItemStatuses are the kitchen statuses of lines still being worked on
+var kitchenOpenItemStatuses = []string{models.KitchenStatusQueued, models.KitchenStatusStarted}
+
+// KitchenService feeds kitchen displays the tickets of placed orders and records the kitchen's
+// progress on their lines. Ticket changes are published to the change feed, which wakes the
+// displays following it on every instance.
+type KitchenService struct {
+	db            *gorm.DB
+	changes       *ChangeFeedService
+	notifications *NotificationService
+}
+
+func NewKitchenService(db *gorm.DB, changes *ChangeFeedService, notifications *NotificationService) *KitchenService {
+	return &KitchenService{
+		db:            db,
+		changes:       changes,
+		notifications: notifications,
+	}
+}
+
+// kitchenTickets turns orders, with their items loaded, into kitchen tickets
+func (s *KitchenService) kitchenTickets(orders []models.Orders) ([]models.KitchenTicket, error) {
+	var tableIDs []uint
+	for _, order := range orders {
+		if order.TableID != nil {
+			tableIDs = append(tableIDs, *order.TableID)
+		}
+	}
+	tableNames := map[uint]string{}
+	if len(tableIDs) > 0 {
+		var tables []models.DiningTables
+		if err := s.db.Unscoped().Where("id IN ?", uniqueIDs(tableIDs)).Find(&tables).Error; err != nil {
+			return nil, err
+		}
+		for _, table := range tables {
+			tableNames[table.ID] = table.Name
+		}
+	}
+
+	tickets := make([]models.KitchenTicket, 0, len(orders))
+	for _, order := range orders {
+		ticket := models.KitchenTicket{
+			OrderID:  order.ID,
+			Number:   order.Number,
+			Status:   order.Status,
+			Note:     order.Note,
+			StoreID:  order.StoreID,
+			TableID:  order.TableID,
+			PlacedAt: order.PlacedAt,
+			Items:    []models.KitchenTicketItem{},
+		}
+		if order.TableID != nil {
+			ticket.TableName = tableNames[*order.TableID]
+		}
+		for _, item := range order.Items {
+			if item.KitchenStatus == "" {
+				continue
+			}
+			ticket.Items = append(ticket.Items, models.KitchenTicketItem{
+				ID:            item.ID,
+				Name:          item.Name,
+				VariantTitle:  item.VariantTitle,
+				Quantity:      item.Quantity,
+				Course:        item.Course,
+				Seat:          item.Seat,
+				KitchenStatus: item.KitchenStatus,
+				StartedAt:     item.KitchenStartedAt,
+				DoneAt:        item.KitchenDoneAt,
+			})
+			if slices.Contains(kitchenOpenItemStatuses, item.KitchenStatus) {
+				ticket.Open = true
+			}
+		}
+		if !slices.Contains(kitchenOrderStatuses, order.Status) {
+			ticket.Open = false
+		}
+		tickets = append(tickets, ticket)
+	}
+	return tickets, nil
+}
+
+// loadOrders returns orders matching a query with their items in course order
+func (s *KitchenService) loadOrders(query *gorm.DB) ([]models.Orders, error) {
+	orders := []models.Orders{}
+	err := query.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("course").Order("id")
+	}).Find(&orders).Error
+	return orders, err
+}
+
+// GetTickets retrieves the open tickets, oldest first, of the stores if any are given
+func (s *KitchenService) GetTickets(storeIDs []uint) ([]models.KitchenTicket, error) {
+	query := s.db.Where("status IN ?", kitchenOrderStatuses).
+		Where("id IN (SELECT order_id FROM order_items WHERE kitchen_status IN ?)", kitchenOpenItemStatuses)
+	if storeIDs != nil {
+		query = query.Where("store_id IN ?", storeIDs)
+	}
+	orders, err := s.loadOrders(query.Order("placed_at").Order("id").Limit(kitchenTicketLimit))
+	if err != nil {
+		return nil, err
+	}
+	return s.kitchenTickets(orders)
+}
+
+// GetTicket retrieves the kitchen ticket of an order, open or not
+func (s *KitchenService) GetTicket(orderID uint) (*models.KitchenTicket, error) {
+	orders, err := s.loadOrders(s.db.Where("id = ?", orderID))
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, errors.New("order not found")
+	}
+	tickets, err := s.kitchenTickets(orders)
+	if err != nil {
+		return nil, err
+	}
+	return &tickets[0], nil
+}
+
+// Latest returns the change feed position kitchen streams start following from
+func (s *KitchenService) Latest() (uint64, error) {
+	return s.changes.Latest()
+}
+
+// CheckCursor makes sure the changes after a stream's cursor are still available
+func (s *KitchenService) CheckCursor(since uint64) error {
+	_, err := s.changes.Since(since, 1)
+	return err
+}
+
+// WaitTickets returns the tickets that changed after the change feed position, holding on for
+// up to timeout until any did. Given stores, tickets of other stores are left out; the
+// response's LastSeq still moves past them.
+func (s *KitchenService) WaitTickets(ctx context.Context, since uint64, storeIDs []uint, timeout time.Duration) ([]models.KitchenTicketEvent, uint64, error) {
+	response, err := s.changes.Wait(ctx, since, 0, timeout)
+	if err != nil {
+		return nil, since, err
+	}
+
+	// A ticket that changed several times is sent once, at its latest change
+	seqs := map[uint]uint64{}
+	var orderIDs []uint
+	for _, event := range response.Events {
+		if event.Entity != models.ChangeEntityKitchenTicket {
+			continue
+		}
+		if _, ok := seqs[event.EntityID]; !ok {
+			orderIDs = append(orderIDs, event.EntityID)
+		}
+		seqs[event.EntityID] = event.ID
+	}
+	if len(orderIDs) == 0 {
+		return []models.KitchenTicketEvent{}, response.LastSeq, nil
+	}
+
+	orders, err := s.loadOrders(s.db.WithContext(ctx).Where("id IN ?", orderIDs))
+	if err != nil {
+		return nil, since, err
+	}
+	tickets, err := s.kitchenTickets(orders)
+	if err != nil {
+		return nil, since, err
+	}
+
+	events := make([]models.KitchenTicketEvent, 0, len(tickets))
+	for i := range tickets {
+		ticket := &tickets[i]
+		if storeIDs != nil && ticket.StoreID != nil && !slices.Contains(storeIDs, *ticket.StoreID) {
+			continue
+		}
+		events = append(events, models.KitchenTicketEvent{Seq: seqs[ticket.OrderID], Ticket: ticket})
+	}
+	slices.SortFunc(events, func(a, b models.KitchenTicketEvent) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+	return events, response.LastSeq, nil
+}
+
+// StartItem marks a queued line of an order started
+func (s *KitchenService) StartItem(orderID uint, itemID uint, actor models.ActivityActor) (*models.KitchenTicket, error) {
+	return s.updateItem(orderID, itemID, models.KitchenStatusStarted, actor)
+}
+
+// CompleteItem marks a line of an order done and tells whoever placed the order it is ready
+func (s *KitchenService) CompleteItem(orderID uint, itemID uint, actor models.ActivityActor) (*models.KitchenTicket, error) {
+	return s.updateItem(orderID, itemID, models.KitchenStatusDone, actor)
+}
+
+// updateItem moves a line of an open ticket to a kitchen status under a lock on its order.
+// Lines can be started once and be marked done whether they were started or not.
+func (s *KitchenService) updateItem(orderID uint, itemID uint, status string, actor models.ActivityActor) (*models.KitchenTicket, error) {
+	var order models.Orders
+	var item models.OrderItems
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", orderID).First(&order).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("order not found")
+			}
+			return err
+		}
+		if !slices.Contains(kitchenOrderStatuses, order.Status) {
+			return errors.New("order not in kitchen")
+		}
+		if err := tx.Where("id = ? AND order_id = ?", itemID, order.ID).First(&item).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("order item not found")
+			}
+			return err
+		}
+		if item.KitchenStatus == "" {
+			return errors.New("order not in kitchen")
+		}
+
+		now := time.Now()
+		columns := map[string]interface{}{"kitchen_status": status}
+		switch status {
+		case models.KitchenStatusStarted:
+			if item.KitchenStatus != models.KitchenStatusQueued {
+				return errors.New("invalid kitchen transition")
+			}
+			columns["kitchen_started_at"] = now
+		case models.KitchenStatusDone:
+			if item.KitchenStatus == models.KitchenStatusDone {
+				return errors.New("invalid kitchen transition")
+			}
+			columns["kitchen_done_at"] = now
+		}
+		return tx.Model(&item).Updates(columns).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.changes.Publish(models.ChangeEntityKitchenTicket, models.ChangeActionUpdated, order.ID)
+	if status == models.KitchenStatusDone {
+		s.notifyReady(&order, &item, actor)
+	}
+	return s.GetTicket(order.ID)
+}
+
+// notifyReady tells whoever placed an order that one of its lines is ready to be served.
+// Failures are logged; the kitchen's progress is recorded either way.
+func (s *KitchenService) notifyReady(order *models.Orders, item *models.OrderItems, actor models.ActivityActor) {
+	if order.CreatedByID == nil || *order.CreatedByID == actor.UserID {
+		return
+	}
+
+	title := fmt.Sprintf("%d × %s ready for order %s", item.Quantity, item.Name, order.Number)
+	data := models.JSONMap{
+		"order_id": order.ID,
+		"number":   order.Number,
+		"item_id":  item.ID,
+	}
+	if order.TableID != nil {
+		data["table_id"] = *order.TableID
+	}
+	if err := s.notifications.SendInApp(*order.CreatedByID, models.NotificationEventKitchenReady, title, title, data); err != nil {
+		slog.Warn("Failed to notify that an order item is ready", "order_item_id", item.ID, "order", order.Number, "error", err)
+	}
+}
+
+// OrderKitchenHook puts orders on the kitchen display when they are placed and takes them off
+// when they are fulfilled, completed, cancelled or refunded; subscribe it with
+// OrderService.AfterTransition
+func (s *KitchenService) OrderKitchenHook(transition OrderTransition) {
+	sent := slices.ContainsFunc(transition.Order.Items, func(item models.OrderItems) bool {
+		return item.KitchenStatus != ""
+	})
+	if !sent {
+		return
+	}
+
+	switch {
+	case transition.To == models.OrderStatusPlaced:
+		s.changes.Publish(models.ChangeEntityKitchenTicket, models.ChangeActionCreated, transition.Order.ID)
+	case slices.Contains(kitchenOrderStatuses, transition.From) && !slices.Contains(kitchenOrderStatuses, transition.To):
+		s.changes.Publish(models.ChangeEntityKitchenTicket, models.ChangeActionDeleted, transition.Order.ID)
+	case slices.Contains(kitchenOrderStatuses, transition.To):
+		s.changes.Publish(models.ChangeEntityKitchenTicket, models.ChangeActionUpdated, transition.Order.ID)
+	}
+}
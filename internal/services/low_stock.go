@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/mailer"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+)
+
+// productStockSQL is a product's stock summed over its variants and stores
+const productStockSQL = "COALESCE((SELECT SUM(stock_levels.quantity) FROM stock_levels WHERE stock_levels.product_id = products.id), 0)"
+
+// lowStockSQL matches products at or below their reorder point. Archived products are no longer
+// restocked, so they are left out.
+const lowStockSQL = "products.reorder_point IS NOT NULL AND products.status <> 'archived' AND " + productStockSQL + " <= products.reorder_point"
+
+// GetLowStock retrieves products at or below their reorder point, with pagination and search on
+// name and SKU. Products furthest below their reorder point come first.
+func (s *InventoryService) GetLowStock(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:      &models.Products{},
+		Conditions: []string{lowStockSQL},
+		SelectFields: []pagination.SelectField{
+			{Field: "products.id", Alias: "id"},
+			{Field: "products.name", Alias: "name"},
+			{Field: "products.sku", Alias: "sku"},
+			{Field: "products.category_id", Alias: "category_id"},
+			{Field: "products.status", Alias: "status"},
+			{Field: "products.reorder_point", Alias: "reorder_point"},
+			{Field: productStockSQL, Alias: "quantity"},
+			{Field: "products.reorder_point - " + productStockSQL, Alias: "shortfall"},
+			{Field: "products.low_stock_alerted_at", Alias: "alerted_at"},
+		},
+		ScanIntoMaps: true,
+		SearchFields: []string{"products.name", "products.sku"},
+		FilterFields: map[string]string{
+			"status": "products.status",
+		},
+		CustomFilters: map[string]string{
+			"category_id": "products.category_id IN (" + categorySubtreeSQL + ")",
+		},
+		SortFields: []string{
+			"name",
+			"sku",
+			"quantity",
+			"shortfall",
+		},
+		DefaultSort:  "shortfall",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// CheckLowStock alerts managers about products that fell to or below their reorder point since
+// the last check. It is the entry point of the background low-stock job. Each product is
+// alerted about once until its stock is back above the reorder point, and claiming products
+// with an update keeps several API instances from sending the same alert.
+func (s *InventoryService) CheckLowStock(ctx context.Context) error {
+	// Products that recovered can be alerted about again
+	if err := s.db.WithContext(ctx).Exec("UPDATE products SET low_stock_alerted_at = NULL WHERE low_stock_alerted_at IS NOT NULL AND NOT (" + lowStockSQL + ")").Error; err != nil {
+		return err
+	}
+
+	var products []models.LowStockProduct
+	err := s.db.WithContext(ctx).Raw("UPDATE products SET low_stock_alerted_at = NOW() WHERE low_stock_alerted_at IS NULL AND deleted_at IS NULL AND " + lowStockSQL +
+		" RETURNING id, name, sku, reorder_point, " + productStockSQL + " AS quantity").Scan(&products).Error
+	if err != nil {
+		return err
+	}
+	if len(products) == 0 {
+		return nil
+	}
+
+	managers, err := s.lowStockRecipients()
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "Alerting managers about products low on stock", "managers", len(managers), "products", len(products))
+
+	title, body := lowStockMessage(products)
+	data := models.JSONMap{"products": products}
+	for _, manager := range managers {
+		if err := s.notifications.SendInApp(manager.ID, models.NotificationEventLowStock, title, body, data); err != nil {
+			slog.ErrorContext(ctx, "Failed to add low-stock notification", "user_id", manager.ID, "error", err)
+		}
+
+		message := mailer.Message{
+			To:      manager.Email,
+			Subject: title,
+			Body: fmt.Sprintf("Hi %s,\n\n%s\n\nSee all products low on stock at %s/inventory/low-stock",
+				manager.Name, body, s.config.FrontendURL),
+		}
+		if err := s.notifications.SendEmail(manager.ID, models.NotificationEventLowStock, message); err != nil {
+			slog.ErrorContext(ctx, "Failed to send low-stock email", "user_id", manager.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// lowStockRecipients returns the active users who receive low-stock alerts: administrators and
+// users whose role was granted the inventory.alerts permission
+func (s *InventoryService) lowStockRecipients() ([]models.Users, error) {
+	var users []models.Users
+	err := s.db.Where("is_deleted = ? AND status = ?", false, models.UserStatusActive).
+		Where("role = ? OR role IN (SELECT role FROM role_permissions WHERE permission = ?)", models.RoleAdmin, models.PermissionInventoryAlerts).
+		Order("id ASC").
+		Find(&users).Error
+	return users, err
+}
+
+// lowStockMessage describes the products in a notification title and body
+func lowStockMessage(products []models.LowStockProduct) (string, string) {
+	title := fmt.Sprintf("%d products are low on stock", len(products))
+	if len(products) == 1 {
+		title = fmt.Sprintf("%s is low on stock", products[0].Name)
+	}
+
+	lines := make([]string, len(products))
+	for i, product := range products {
+		lines[i] = fmt.Sprintf("- %s (%s): %d in stock, reorder point %d", product.Name, product.SKU, product.Quantity, product.ReorderPoint)
+	}
+	return title, "The following products are at or below their reorder point:\n\n" + strings.Join(lines, "\n")
+}
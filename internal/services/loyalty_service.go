@@ -0,0 +1,422 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/payments"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoyaltyService runs the loyalty program: customers earn points on what they pay for orders
+// under the loyalty rules and redeem them as payments, each point worth pointValue minor
+// currency units. Every change to a balance is recorded in the ledger in the same transaction.
+type LoyaltyService struct {
+	db         *gorm.DB
+	pointValue int64
+}
+
+func NewLoyaltyService(db *gorm.DB, pointValue int64) *LoyaltyService {
+	return &LoyaltyService{
+		db:         db,
+		pointValue: max(pointValue, 1),
+	}
+}
+
+// GetAllLoyaltyRules retrieves loyalty rules with pagination
+func (s *LoyaltyService) GetAllLoyaltyRules(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.LoyaltyRules{},
+		SearchFields: []string{"name"},
+		FilterFields: map[string]string{
+			"active":      "active",
+			"category_id": "category_id",
+		},
+		SortFields: []string{
+			"name",
+			"created_at",
+		},
+		DefaultSort:  "name",
+		DefaultOrder: "ASC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetLoyaltyRuleById retrieves a loyalty rule
+func (s *LoyaltyService) GetLoyaltyRuleById(id uint) (*models.LoyaltyRules, error) {
+	var rule models.LoyaltyRules
+	if err := s.db.Where("id = ?", id).First(&rule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("loyalty rule not found")
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// applyLoyaltyRule checks a rule's category and copies the request onto the rule
+func (s *LoyaltyService) applyLoyaltyRule(rule *models.LoyaltyRules, req *models.LoyaltyRuleRequest) error {
+	if req.CategoryID != nil {
+		var count int64
+		if err := s.db.Model(&models.Categories{}).Where("id = ?", *req.CategoryID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New("category not found")
+		}
+	}
+
+	rule.Name = req.Name
+	rule.CategoryID = req.CategoryID
+	rule.Spend = req.Spend
+	rule.Points = req.Points
+	if req.Active != nil {
+		rule.Active = *req.Active
+	}
+	return nil
+}
+
+// CreateLoyaltyRule creates a loyalty rule, active unless asked otherwise. Orders already paid
+// keep the points they earned.
+func (s *LoyaltyService) CreateLoyaltyRule(req *models.LoyaltyRuleRequest) (*models.LoyaltyRules, error) {
+	rule := models.LoyaltyRules{Active: true}
+	if err := s.applyLoyaltyRule(&rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// UpdateLoyaltyRule updates a loyalty rule
+func (s *LoyaltyService) UpdateLoyaltyRule(id uint, req *models.LoyaltyRuleRequest) (*models.LoyaltyRules, error) {
+	rule, err := s.GetLoyaltyRuleById(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyLoyaltyRule(rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(rule).Error; err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteLoyaltyRule soft-deletes a loyalty rule
+func (s *LoyaltyService) DeleteLoyaltyRule(id uint) (*models.LoyaltyRules, error) {
+	rule, err := s.GetLoyaltyRuleById(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Delete(rule).Error; err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// GetBalance retrieves a customer's points and what they are worth
+func (s *LoyaltyService) GetBalance(customerID uint) (*models.LoyaltyBalanceResponse, error) {
+	customer, err := findCustomer(s.db, customerID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LoyaltyBalanceResponse{
+		CustomerID: customer.ID,
+		Points:     customer.LoyaltyPoints,
+		PointValue: s.pointValue,
+		Value:      max(customer.LoyaltyPoints, 0) * s.pointValue,
+	}, nil
+}
+
+// GetTransactions retrieves the ledger of a customer's points, newest first
+func (s *LoyaltyService) GetTransactions(customerID uint, params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	if _, err := findCustomer(s.db, customerID); err != nil {
+		return nil, err
+	}
+
+	config := pagination.PaginationConfig{
+		Model:         &models.LoyaltyTransactions{},
+		BaseCondition: map[string]interface{}{"customer_id": customerID},
+		FilterFields: map[string]string{
+			"type":     "type",
+			"order_id": "order_id",
+		},
+		DateFields: map[string]pagination.DateField{
+			"created_at": {
+				Start: "created_at",
+				End:   "created_at",
+			},
+		},
+		SortFields: []string{
+			"created_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// addPointsTx changes a customer's balance by entry.Points and records the entry with the new
+// balance. Unless allowNegative is set, points can only be taken from the balance they have.
+// Deleted customers keep earning and losing points for orders sold before they were deleted.
+func addPointsTx(tx *gorm.DB, customerID uint, entry *models.LoyaltyTransactions, allowNegative bool) error {
+	var customer models.Customers
+	if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", customerID).First(&customer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("customer not found")
+		}
+		return err
+	}
+
+	balance := customer.LoyaltyPoints + entry.Points
+	if balance < 0 && entry.Points < 0 && !allowNegative {
+		return errors.New("insufficient loyalty points")
+	}
+	if err := tx.Model(&customer).UpdateColumn("loyalty_points", balance).Error; err != nil {
+		return err
+	}
+
+	entry.CustomerID = customer.ID
+	entry.Balance = balance
+	return tx.Create(entry).Error
+}
+
+// AdjustPoints adds points to or takes them from a customer's balance by hand, e.g. as a
+// goodwill gesture or to correct a mistake
+func (s *LoyaltyService) AdjustPoints(customerID uint, req *models.LoyaltyAdjustmentRequest, actor models.ActivityActor) (*models.LoyaltyTransactions, error) {
+	if _, err := findCustomer(s.db, customerID); err != nil {
+		return nil, err
+	}
+
+	entry := models.LoyaltyTransactions{
+		Type:        models.LoyaltyAdjusted,
+		Points:      req.Points,
+		Reason:      req.Reason,
+		CreatedByID: actorID(actor),
+	}
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		return addPointsTx(tx, customerID, &entry, false)
+	}); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// paymentPoints converts a loyalty payment amount to points; only whole points can be paid with
+func (s *LoyaltyService) paymentPoints(amount int64) (int64, error) {
+	if amount%s.pointValue != 0 {
+		return 0, errors.New("amount not in whole points")
+	}
+	return amount / s.pointValue, nil
+}
+
+// redeemTx takes the points of a loyalty payment from the customer of its order
+func (s *LoyaltyService) redeemTx(tx *gorm.DB, order *models.Orders, payment *models.Payments, actor models.ActivityActor) error {
+	if order.CustomerID == nil {
+		return errors.New("order has no customer")
+	}
+	points, err := s.paymentPoints(payment.Amount)
+	if err != nil {
+		return err
+	}
+	return addPointsTx(tx, *order.CustomerID, &models.LoyaltyTransactions{
+		Type:        models.LoyaltyRedeemed,
+		Points:      -points,
+		OrderID:     &order.ID,
+		PaymentID:   &payment.ID,
+		CreatedByID: actorID(actor),
+	}, false)
+}
+
+// refundTx gives back the points of a refunded part of a loyalty payment
+func (s *LoyaltyService) refundTx(tx *gorm.DB, order *models.Orders, payment *models.Payments, amount int64, actor models.ActivityActor) error {
+	if order.CustomerID == nil {
+		return errors.New("order has no customer")
+	}
+	points, err := s.paymentPoints(amount)
+	if err != nil {
+		return err
+	}
+	return addPointsTx(tx, *order.CustomerID, &models.LoyaltyTransactions{
+		Type:        models.LoyaltyRefunded,
+		Points:      points,
+		OrderID:     &order.ID,
+		PaymentID:   &payment.ID,
+		CreatedByID: actorID(actor),
+	}, true)
+}
+
+// orderPoints works out the points an order earns. Each line earns under the most generous
+// active rule of its product's category or a category above it, or else of the rules without a
+// category, on its amount after discounts and before tax. The part of the order paid with
+// points earns nothing. With unreturned set, lines only count for what hasn't been returned.
+func orderPoints(tx *gorm.DB, order *models.Orders, unreturned bool) (int64, error) {
+	var rules []models.LoyaltyRules
+	if err := tx.Where("active").Order("id").Find(&rules).Error; err != nil {
+		return 0, err
+	}
+	if len(rules) == 0 || len(order.Items) == 0 {
+		return 0, nil
+	}
+
+	productIDs := make([]uint, len(order.Items))
+	for i, item := range order.Items {
+		productIDs[i] = item.ProductID
+	}
+	var rows []struct {
+		ProductID  uint
+		CategoryID uint
+	}
+	if err := tx.Raw(productCategoriesSQL, uniqueIDs(productIDs)).Scan(&rows).Error; err != nil {
+		return 0, err
+	}
+	categories := map[uint]map[uint]bool{}
+	for _, row := range rows {
+		if categories[row.ProductID] == nil {
+			categories[row.ProductID] = map[uint]bool{}
+		}
+		categories[row.ProductID][row.CategoryID] = true
+	}
+
+	// Amounts are added up per rule before converting, so small lines still count
+	spend := map[int]int64{}
+	for _, item := range order.Items {
+		best := -1
+		for i, rule := range rules {
+			if rule.CategoryID != nil && !categories[item.ProductID][*rule.CategoryID] {
+				continue
+			}
+			if best >= 0 {
+				current := rules[best]
+				if current.CategoryID != nil && rule.CategoryID == nil {
+					continue
+				}
+				sameKind := (current.CategoryID == nil) == (rule.CategoryID == nil)
+				if sameKind && rule.Points*current.Spend <= current.Points*rule.Spend {
+					continue
+				}
+			}
+			best = i
+		}
+		if best >= 0 {
+			amount := item.Subtotal - item.Discount - includedTax(item)
+			if unreturned {
+				amount = amount * (item.Quantity - item.ReturnedQuantity) / item.Quantity
+			}
+			spend[best] += amount
+		}
+	}
+
+	var points int64
+	for i, amount := range spend {
+		if amount > 0 {
+			points += amount / rules[i].Spend * rules[i].Points
+		}
+	}
+
+	var paidWithPoints int64
+	if err := tx.Model(&models.Payments{}).
+		Where("order_id = ? AND method = ? AND status = ?", order.ID, payments.MethodLoyalty, models.PaymentStatusCaptured).
+		Select("COALESCE(SUM(amount - refunded_amount), 0)").Scan(&paidWithPoints).Error; err != nil {
+		return 0, err
+	}
+	if paidWithPoints > 0 && order.Total > 0 {
+		points = points * max(order.Total-paidWithPoints, 0) / order.Total
+	}
+	return points, nil
+}
+
+// OrderLoyaltyHook keeps loyalty balances in step with orders; subscribe it with
+// OrderService.OnTransition. The customer earns points when their order is paid and loses
+// what it earned if it is cancelled or refunded. Points spent meanwhile can leave the balance
+// negative.
+func (s *LoyaltyService) OrderLoyaltyHook(tx *gorm.DB, transition OrderTransition) error {
+	order := transition.Order
+	if order.CustomerID == nil {
+		return nil
+	}
+
+	switch transition.To {
+	case models.OrderStatusPaid:
+		points, err := orderPoints(tx, order, false)
+		if err != nil || points == 0 {
+			return err
+		}
+		return addPointsTx(tx, *order.CustomerID, &models.LoyaltyTransactions{
+			Type:        models.LoyaltyEarned,
+			Points:      points,
+			OrderID:     &order.ID,
+			CreatedByID: actorID(transition.Actor),
+		}, true)
+	case models.OrderStatusCancelled, models.OrderStatusRefunded:
+		var earned int64
+		if err := tx.Model(&models.LoyaltyTransactions{}).
+			Where("order_id = ? AND type IN ?", order.ID, []string{models.LoyaltyEarned, models.LoyaltyReversed}).
+			Select("COALESCE(SUM(points), 0)").Scan(&earned).Error; err != nil {
+			return err
+		}
+		if earned <= 0 {
+			return nil
+		}
+		return addPointsTx(tx, *order.CustomerID, &models.LoyaltyTransactions{
+			Type:        models.LoyaltyReversed,
+			Points:      -earned,
+			OrderID:     &order.ID,
+			Reason:      transition.Reason,
+			CreatedByID: actorID(transition.Actor),
+		}, true)
+	}
+	return nil
+}
+
+// ReturnLoyaltyHook takes back the points the lines of a return earned; subscribe it with
+// ReturnService.OnReturn. The order keeps the share of its points that its unreturned lines
+// earn, so returning every line takes back everything it earned.
+func (s *LoyaltyService) ReturnLoyaltyHook(tx *gorm.DB, ret OrderReturn) error {
+	order := ret.Order
+	if order.CustomerID == nil {
+		return nil
+	}
+
+	var totals struct {
+		Earned int64
+		Net    int64
+	}
+	if err := tx.Model(&models.LoyaltyTransactions{}).
+		Where("order_id = ? AND type IN ?", order.ID, []string{models.LoyaltyEarned, models.LoyaltyReversed}).
+		Select("COALESCE(SUM(points) FILTER (WHERE type = ?), 0) AS earned, COALESCE(SUM(points), 0) AS net", models.LoyaltyEarned).
+		Scan(&totals).Error; err != nil {
+		return err
+	}
+	if totals.Net <= 0 {
+		return nil
+	}
+
+	sold, err := orderPoints(tx, order, false)
+	if err != nil || sold == 0 {
+		return err
+	}
+	kept, err := orderPoints(tx, order, true)
+	if err != nil {
+		return err
+	}
+	reversed := totals.Net - totals.Earned*kept/sold
+	if reversed <= 0 {
+		return nil
+	}
+	return addPointsTx(tx, *order.CustomerID, &models.LoyaltyTransactions{
+		Type:        models.LoyaltyReversed,
+		Points:      -reversed,
+		OrderID:     &order.ID,
+		Reason:      ret.Reason,
+		CreatedByID: actorID(ret.Actor),
+	}, true)
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/mailer"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationService dispatches notifications to users, honoring their notification
+// preferences. Every sender goes through it instead of using a channel directly.
+type NotificationService struct {
+	db     *gorm.DB
+	mailer mailer.Mailer
+}
+
+func NewNotificationService(db *gorm.DB, mailer mailer.Mailer) *NotificationService {
+	return &NotificationService{
+		db:     db,
+		mailer: mailer,
+	}
+}
+
+// notificationEvent looks up an event type
+func notificationEvent(eventType string) (models.NotificationEvent, bool) {
+	for _, event := range models.NotificationEvents {
+		if event.Type == eventType {
+			return event, true
+		}
+	}
+	return models.NotificationEvent{}, false
+}
+
+// Allowed reports whether the user wants notifications of the event type on the channel.
+// Critical events are always allowed.
+func (s *NotificationService) Allowed(userID uint, channel string, eventType string) (bool, error) {
+	if event, ok := notificationEvent(eventType); ok && event.Critical {
+		return true, nil
+	}
+
+	var preference models.NotificationPreferences
+	err := s.db.Where("user_id = ? AND channel = ? AND event_type = ?", userID, channel, eventType).First(&preference).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return preference.Enabled, nil
+}
+
+// SendEmail emails the user about an event unless they opted out of it. Opted-out messages
+// are dropped without an error.
+func (s *NotificationService) SendEmail(userID uint, eventType string, msg mailer.Message) error {
+	allowed, err := s.Allowed(userID, models.NotificationChannelEmail, eventType)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		slog.Debug("Skipping email, the user opted out", "event", eventType, "user_id", userID)
+		return nil
+	}
+	return s.mailer.Send(msg)
+}
+
+// SendExternalEmail emails someone who isn't a user, such as a customer. Without a user there
+// are no preferences, so the message is always sent and delivery errors are returned.
+func (s *NotificationService) SendExternalEmail(eventType string, msg mailer.Message) error {
+	if err := s.mailer.Send(msg); err != nil {
+		return fmt.Errorf("failed to send %s email: %w", eventType, err)
+	}
+	return nil
+}
+
+// SendInApp adds a notification to the user's inbox unless they opted out of it
+func (s *NotificationService) SendInApp(userID uint, eventType string, title string, body string, data models.JSONMap) error {
+	allowed, err := s.Allowed(userID, models.NotificationChannelInApp, eventType)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return nil
+	}
+
+	if data == nil {
+		data = models.JSONMap{}
+	}
+	notification := models.Notifications{
+		UserID:    userID,
+		EventType: eventType,
+		Title:     title,
+		Body:      body,
+		Data:      data,
+	}
+	return s.db.Create(&notification).Error
+}
+
+// GetNotifications retrieves the user's inbox, newest first. filters[unread]=true lists unread
+// notifications only.
+func (s *NotificationService) GetNotifications(userID uint, params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:         &models.Notifications{},
+		BaseCondition: map[string]interface{}{"user_id": userID},
+		SearchFields:  []string{"title", "body"},
+		FilterFields: map[string]string{
+			"event_type": "event_type",
+		},
+		CustomFilters: map[string]string{
+			"unread": "(read_at IS NULL) = ?",
+		},
+		DateFields: map[string]pagination.DateField{
+			"created_at": {
+				Start: "created_at",
+				End:   "created_at",
+			},
+		},
+		SortFields: []string{
+			"created_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// MarkNotificationRead marks one of the user's notifications as read
+func (s *NotificationService) MarkNotificationRead(userID uint, id uint) (*models.Notifications, error) {
+	var notification models.Notifications
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("notification not found")
+		}
+		return nil, err
+	}
+
+	if notification.ReadAt == nil {
+		now := time.Now()
+		if err := s.db.Model(&notification).Update("read_at", now).Error; err != nil {
+			return nil, err
+		}
+		notification.ReadAt = &now
+	}
+	return &notification, nil
+}
+
+// MarkAllNotificationsRead marks every unread notification of the user as read
+func (s *NotificationService) MarkAllNotificationsRead(userID uint) (*models.MarkNotificationsReadResponse, error) {
+	result := s.db.Model(&models.Notifications{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &models.MarkNotificationsReadResponse{Updated: result.RowsAffected}, nil
+}
+
+// GetPreferences returns the user's preferences for every channel and event type
+func (s *NotificationService) GetPreferences(userID uint) (*models.NotificationPreferencesResponse, error) {
+	var rows []models.NotificationPreferences
+	if err := s.db.Where("user_id = ?", userID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	stored := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		stored[row.Channel+":"+row.EventType] = row.Enabled
+	}
+
+	response := &models.NotificationPreferencesResponse{
+		Preferences: []models.NotificationPreference{},
+		Events:      models.NotificationEvents,
+	}
+	for _, event := range models.NotificationEvents {
+		for _, channel := range models.NotificationChannels {
+			enabled, ok := stored[channel+":"+event.Type]
+			if !ok || event.Critical {
+				enabled = true
+			}
+			response.Preferences = append(response.Preferences, models.NotificationPreference{
+				Channel:   channel,
+				EventType: event.Type,
+				Enabled:   &enabled,
+				Critical:  event.Critical,
+			})
+		}
+	}
+	return response, nil
+}
+
+// UpdatePreferences stores the given preferences and returns all of the user's preferences
+func (s *NotificationService) UpdatePreferences(userID uint, req *models.UpdateNotificationPreferencesRequest) (*models.NotificationPreferencesResponse, error) {
+	rows := make([]models.NotificationPreferences, 0, len(req.Preferences))
+	seen := map[string]int{}
+	for _, preference := range req.Preferences {
+		event, ok := notificationEvent(preference.EventType)
+		if !ok {
+			return nil, errors.New("unknown notification event type")
+		}
+		if event.Critical && !*preference.Enabled {
+			return nil, errors.New("critical notifications cannot be disabled")
+		}
+		row := models.NotificationPreferences{
+			UserID:    userID,
+			Channel:   preference.Channel,
+			EventType: preference.EventType,
+			Enabled:   *preference.Enabled,
+		}
+		// A preference listed twice keeps its last value
+		key := preference.Channel + ":" + preference.EventType
+		if i, ok := seen[key]; ok {
+			rows[i] = row
+			continue
+		}
+		seen[key] = len(rows)
+		rows = append(rows, row)
+	}
+
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "channel"}, {Name: "event_type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPreferences(userID)
+}
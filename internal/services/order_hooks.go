@@ -0,0 +1,134 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// orderStatusMessages are the notification titles of order status changes, by new status
+var orderStatusMessages = map[string]string{
+	models.OrderStatusPaid:      "Order %s was paid",
+	models.OrderStatusFulfilled: "Order %s was fulfilled",
+	models.OrderStatusCompleted: "Order %s was completed",
+	models.OrderStatusCancelled: "Order %s was cancelled",
+	models.OrderStatusRefunded:  "Order %s was refunded",
+}
+
+// orderStockMovements returns the stock movements of an order's items, of the given type and
+// taking (direction -1) or putting back (direction 1) the quantity sold, with the serials of
+// serialized products and a movement per batch lines of batch-tracked products were taken from.
+// Bundles move the stock of their components instead. Quantities and serials already returned
+// were counted back in with their return and are left out.
+func orderStockMovements(transition OrderTransition, movementType string, direction int64) []models.StockMovementInput {
+	order := transition.Order
+	movements := make([]models.StockMovementInput, 0, len(order.Items))
+	for i := range order.Items {
+		item := &order.Items[i]
+		movement := models.StockMovementInput{
+			ProductID:   item.ProductID,
+			VariantID:   item.VariantID,
+			StoreID:     order.StoreID,
+			Type:        movementType,
+			Unit:        item.Unit,
+			Reference:   order.Number,
+			OrderID:     &order.ID,
+			CreatedByID: actorID(transition.Actor),
+		}
+		if len(item.Components) > 0 {
+			// Bundles take their components from stock, counted in the components' stock units
+			movement.Unit = ""
+			for _, component := range item.Components {
+				if quantity := component.PerBundle * (item.Quantity - item.ReturnedQuantity); quantity > 0 {
+					movement.ProductID = component.ProductID
+					movement.VariantID = component.VariantID
+					movement.Quantity = direction * quantity
+					movements = append(movements, movement)
+				}
+			}
+			continue
+		}
+		if len(item.Batches) > 0 {
+			for _, allocation := range item.Batches {
+				if quantity := allocation.Quantity - allocation.Returned; quantity > 0 {
+					movement.Quantity = direction * quantity
+					movement.Batch = allocation.Batch
+					movements = append(movements, movement)
+				}
+			}
+			continue
+		}
+		if quantity := item.Quantity - item.ReturnedQuantity; quantity > 0 {
+			movement.Quantity = direction * quantity
+			movement.Serials = unreturnedSerials(item)
+			movements = append(movements, movement)
+		}
+	}
+	return movements
+}
+
+// OrderStockHook keeps stock in step with orders; subscribe it with OrderService.OnTransition.
+// Placing an order takes its stock, from the batches that expire first for batch-tracked
+// products. Cancelling it, or refunding it before it was fulfilled, puts the stock back since the
+// goods never left the store; goods returned after fulfilment are counted back in by the return
+// that refunds them.
+func (s *InventoryService) OrderStockHook(tx *gorm.DB, transition OrderTransition) error {
+	movementType, direction, ok := orderStockChange(transition)
+	if !ok {
+		return nil
+	}
+	if transition.To == models.OrderStatusPlaced {
+		if err := s.allocateOrderBatches(tx, transition.Order); err != nil {
+			return err
+		}
+	}
+	_, err := s.RecordMovements(tx, orderStockMovements(transition, movementType, direction))
+	return err
+}
+
+// OrderStockChangedHook reports the stock an order moved once its transition is committed;
+// subscribe it with OrderService.AfterTransition
+func (s *InventoryService) OrderStockChangedHook(transition OrderTransition) {
+	if movementType, direction, ok := orderStockChange(transition); ok {
+		s.stockChanged(orderStockMovements(transition, movementType, direction))
+	}
+}
+
+// orderStockChange returns the movement type and direction of the stock a transition moves, as
+// described on OrderStockHook, or false when it moves none
+func orderStockChange(transition OrderTransition) (string, int64, bool) {
+	switch {
+	case transition.To == models.OrderStatusPlaced:
+		return models.StockMovementSale, -1, true
+	case transition.To == models.OrderStatusCancelled && transition.From != models.OrderStatusDraft,
+		transition.To == models.OrderStatusRefunded && transition.From == models.OrderStatusPaid:
+		return models.StockMovementReturn, 1, true
+	}
+	return "", 0, false
+}
+
+// OrderStatusHook tells whoever placed an order when someone else moves it along; subscribe it
+// with OrderService.AfterTransition
+func (s *NotificationService) OrderStatusHook(transition OrderTransition) {
+	order := transition.Order
+	format, ok := orderStatusMessages[transition.To]
+	if !ok || order.CreatedByID == nil || *order.CreatedByID == transition.Actor.UserID {
+		return
+	}
+
+	title := fmt.Sprintf(format, order.Number)
+	body := title
+	if transition.Reason != "" {
+		body = fmt.Sprintf("%s: %s", title, transition.Reason)
+	}
+	data := models.JSONMap{
+		"order_id": order.ID,
+		"number":   order.Number,
+		"from":     transition.From,
+		"to":       transition.To,
+	}
+	if err := s.SendInApp(*order.CreatedByID, models.NotificationEventOrderStatus, title, body, data); err != nil {
+		logHookError(transition, "the notification", err)
+	}
+}
@@ -0,0 +1,590 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrderTransition is a change of an order's status as seen by the hooks subscribed to it.
+// Orders placed directly are reported as moving from draft to placed.
+type OrderTransition struct {
+	Order  *models.Orders
+	From   string
+	To     string
+	Reason string
+	Actor  models.ActivityActor
+}
+
+// OrderTransitionHook runs in the transaction that changes an order's status; returning an
+// error rolls the change back
+type OrderTransitionHook func(tx *gorm.DB, transition OrderTransition) error
+
+// OrderEventHook runs once a status change is committed, for side effects that can't be rolled
+// back, such as notifications
+type OrderEventHook func(transition OrderTransition)
+
+// OrderService places orders, keeps their totals and moves them through their statuses. Totals
+// are always computed here from the line items; clients only send quantities, prices, discounts
+// and tax rates. Other subsystems follow orders by subscribing hooks at startup.
+type OrderService struct {
+	db              *gorm.DB
+	promotions      *PromotionService
+	activityService *ActivityService
+	transitionHooks []OrderTransitionHook
+	eventHooks      []OrderEventHook
+}
+
+func NewOrderService(db *gorm.DB, promotions *PromotionService, activityService *ActivityService) *OrderService {
+	return &OrderService{
+		db:              db,
+		promotions:      promotions,
+		activityService: activityService,
+	}
+}
+
+// OnTransition subscribes a hook to every status change, run inside its transaction. Hooks are
+// not synchronized and must be subscribed before the service is used.
+func (s *OrderService) OnTransition(hook OrderTransitionHook) {
+	s.transitionHooks = append(s.transitionHooks, hook)
+}
+
+// AfterTransition subscribes a hook to every committed status change. Hooks are not
+// synchronized and must be subscribed before the service is used.
+func (s *OrderService) AfterTransition(hook OrderEventHook) {
+	s.eventHooks = append(s.eventHooks, hook)
+}
+
+// transitioned runs the hooks subscribed with AfterTransition
+func (s *OrderService) transitioned(transition OrderTransition) {
+	for _, hook := range s.eventHooks {
+		hook(transition)
+	}
+}
+
+// orderStatusColumns returns the columns to update when an order reaches a status: the status
+// itself and the timestamp of reaching it
+func orderStatusColumns(status string, reason string, now time.Time) map[string]interface{} {
+	columns := map[string]interface{}{
+		"status":        status,
+		"status_reason": reason,
+	}
+	switch status {
+	case models.OrderStatusPlaced:
+		columns["placed_at"] = now
+	case models.OrderStatusPaid:
+		columns["paid_at"] = now
+	case models.OrderStatusFulfilled:
+		columns["fulfilled_at"] = now
+	case models.OrderStatusCompleted:
+		columns["completed_at"] = now
+	case models.OrderStatusCancelled:
+		columns["cancelled_at"] = now
+	case models.OrderStatusRefunded:
+		columns["refunded_at"] = now
+	}
+	return columns
+}
+
+// orderNumber formats the number of an order from its ID
+func orderNumber(id uint) string {
+	return fmt.Sprintf("ORD-%06d", id)
+}
+
+// lineTax returns the tax of a line amount at a rate in basis points, rounded half up
+func lineTax(amount int64, rate int64) int64 {
+	return (amount*rate + 5000) / 10000
+}
+
+// GetAllOrders retrieves orders without their items, with pagination, search on number and
+// note, and filters on status, cashier, store, table and the products sold
+func (s *OrderService) GetAllOrders(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.Orders{},
+		SearchFields: []string{"number", "note"},
+		FilterFields: map[string]string{
+			"status":        "status",
+			"number":        "number",
+			"created_by_id": "created_by_id",
+			"device_id":     "device_id",
+			"store_id":      "store_id",
+			"table_id":      "table_id",
+		},
+		CustomFilters: map[string]string{
+			"product_id": "id IN (SELECT order_id FROM order_items WHERE product_id = ?)",
+		},
+		StoreFilter: "store_id = ?",
+		DateFields: map[string]pagination.DateField{
+			"created_at": {
+				Start: "created_at",
+				End:   "created_at",
+			},
+		},
+		SortFields: []string{
+			"number",
+			"status",
+			"item_count",
+			"total",
+			"created_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// OrderStore returns the store an order was sold in, nil for orders outside any store
+func (s *OrderService) OrderStore(id uint) (*uint, error) {
+	var order models.Orders
+	if err := s.db.Select("id", "store_id").Where("id = ?", id).First(&order).Error; err != nil {
+		return nil, err
+	}
+	return order.StoreID, nil
+}
+
+// GetOrderById retrieves an order with its items, promotions and taxes
+func (s *OrderService) GetOrderById(id uint) (*models.Orders, error) {
+	var order models.Orders
+	if err := s.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Items.Components").Preload("Promotions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Taxes", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("id = ?", id).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("order not found")
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// buildOrderItems prices the requested lines from the catalog, failing on the first line that
+// can't be sold
+func (s *OrderService) buildOrderItems(db *gorm.DB, items []models.OrderItemRequest, region string) ([]models.OrderItems, error) {
+	orderItems, lineErrs, err := s.priceItems(db, items, region)
+	if err != nil {
+		return nil, err
+	}
+	for _, lineErr := range lineErrs {
+		if lineErr != nil {
+			return nil, lineErr
+		}
+	}
+	return orderItems, nil
+}
+
+// priceItems prices the requested lines from the catalog and taxes them at the rates of the
+// region. Only active products and variants can be sold, and products with variants only per
+// variant; lines that can't be sold get an error of their own and a zero item, so carts can
+// still show the rest.
+func (s *OrderService) priceItems(db *gorm.DB, items []models.OrderItemRequest, region string) ([]models.OrderItems, []error, error) {
+	productIDs := make([]uint, 0, len(items))
+	variantIDs := make([]uint, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+		if item.VariantID != nil {
+			variantIDs = append(variantIDs, *item.VariantID)
+		}
+	}
+	// Bundles are priced from their components, which are loaded along with them
+	components, err := bundleComponents(db, uniqueIDs(productIDs))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, bundle := range components {
+		for _, component := range bundle {
+			productIDs = append(productIDs, component.ProductID)
+			if component.VariantID != nil {
+				variantIDs = append(variantIDs, *component.VariantID)
+			}
+		}
+	}
+	productIDs = uniqueIDs(productIDs)
+	variantIDs = uniqueIDs(variantIDs)
+
+	var products []models.Products
+	if err := db.Where("id IN ?", productIDs).Find(&products).Error; err != nil {
+		return nil, nil, err
+	}
+	productsByID := make(map[uint]models.Products, len(products))
+	var classIDs []uint
+	for _, product := range products {
+		productsByID[product.ID] = product
+		if product.TaxClassID != nil {
+			classIDs = append(classIDs, *product.TaxClassID)
+		}
+	}
+	taxes, err := classTaxes(db, uniqueIDs(classIDs), region)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var withVariants []uint
+	if err := db.Model(&models.ProductVariants{}).Where("product_id IN ?", productIDs).Distinct().Pluck("product_id", &withVariants).Error; err != nil {
+		return nil, nil, err
+	}
+	hasVariants := make(map[uint]bool, len(withVariants))
+	for _, productID := range withVariants {
+		hasVariants[productID] = true
+	}
+
+	variantsByID := map[uint]models.ProductVariants{}
+	if len(variantIDs) > 0 {
+		var variants []models.ProductVariants
+		if err := db.Where("id IN ?", variantIDs).Find(&variants).Error; err != nil {
+			return nil, nil, err
+		}
+		for _, variant := range variants {
+			variantsByID[variant.ID] = variant
+		}
+	}
+
+	orderItems := make([]models.OrderItems, len(items))
+	lineErrs := make([]error, len(items))
+	for i, item := range items {
+		orderItem, err := priceItem(item, productsByID, variantsByID, hasVariants, taxes, components)
+		if err != nil {
+			lineErrs[i] = err
+			continue
+		}
+		orderItems[i] = orderItem
+	}
+
+	return orderItems, lineErrs, nil
+}
+
+// discountItems applies the promotions running now to the lines that can be sold, including
+// the promotion of a coupon if one is given. Gift cards are never discounted. lineErrs are the
+// errors priceItems gave the lines, or nil when every line can be sold.
+func (s *OrderService) discountItems(db *gorm.DB, items []models.OrderItems, lineErrs []error, coupon *models.Coupons) ([]models.AppliedPromotion, error) {
+	productIDs := make([]uint, len(items))
+	for i, item := range items {
+		productIDs[i] = item.ProductID
+	}
+	giftCards, err := giftCardProducts(db, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	sellable := make([]*models.OrderItems, 0, len(items))
+	for i := range items {
+		if (lineErrs == nil || lineErrs[i] == nil) && !giftCards[items[i].ProductID] {
+			sellable = append(sellable, &items[i])
+		}
+	}
+	var couponPromotionIDs []uint
+	if coupon != nil {
+		couponPromotionIDs = append(couponPromotionIDs, coupon.PromotionID)
+	}
+	return s.promotions.ApplyPromotions(db, sellable, couponPromotionIDs, time.Now())
+}
+
+// priceItem prices one line from the loaded products and variants, the taxes of their tax
+// classes and the components of bundles
+func priceItem(item models.OrderItemRequest, productsByID map[uint]models.Products, variantsByID map[uint]models.ProductVariants, hasVariants map[uint]bool, taxes map[uint]models.LineTaxes, components map[uint][]models.BundleComponents) (models.OrderItems, error) {
+	product, ok := productsByID[item.ProductID]
+	if !ok {
+		return models.OrderItems{}, errors.New("product not found")
+	}
+	if product.Status != models.ProductStatusActive {
+		return models.OrderItems{}, errors.New("product not available")
+	}
+	if item.VariantID == nil && hasVariants[product.ID] {
+		return models.OrderItems{}, errors.New("variant required")
+	}
+	// Gift cards are worth what was paid for them, so they sell at their price only
+	if product.GiftCard && (item.UnitPrice != nil || item.Discount > 0) {
+		return models.OrderItems{}, errors.New("gift card price fixed")
+	}
+
+	orderItem := models.OrderItems{
+		ProductID: product.ID,
+		Name:      product.Name,
+		SKU:       product.SKU,
+		Quantity:  item.Quantity,
+		Unit:      product.Unit,
+		UnitPrice: product.Price,
+		UnitCost:  product.Cost,
+		Discount:  item.Discount,
+		Taxes:     manualLineTaxes(item.TaxRate),
+		Course:    item.Course,
+		Seat:      item.Seat,
+		Serials:   models.JSONStringList(item.Serials),
+		Batch:     item.Batch,
+	}
+	if product.TaxClassID != nil {
+		orderItem.Taxes = append(models.LineTaxes{}, taxes[*product.TaxClassID]...)
+	}
+	if item.VariantID != nil {
+		variant, ok := variantsByID[*item.VariantID]
+		if !ok || variant.ProductID != product.ID {
+			return models.OrderItems{}, errors.New("variant not found")
+		}
+		if variant.Status != models.ProductStatusActive {
+			return models.OrderItems{}, errors.New("product not available")
+		}
+		orderItem.VariantID = &variant.ID
+		orderItem.VariantTitle = variant.Title
+		orderItem.SKU = variant.SKU
+		orderItem.UnitPrice = variant.Price
+		orderItem.UnitCost = variant.Cost
+	}
+	if product.Bundle {
+		if err := priceBundle(&orderItem, product, components[product.ID], productsByID, variantsByID); err != nil {
+			return models.OrderItems{}, err
+		}
+	}
+	if item.UnitPrice != nil {
+		orderItem.UnitPrice = *item.UnitPrice
+	}
+
+	orderItem.Subtotal = orderItem.Quantity * orderItem.UnitPrice
+	if orderItem.Discount > orderItem.Subtotal {
+		return models.OrderItems{}, errors.New("discount exceeds line amount")
+	}
+	applyLineTax(&orderItem)
+	return orderItem, nil
+}
+
+// SetOrderCustomer attaches a customer to an order, e.g. one who asked for their purchase to
+// be recorded after paying, or detaches it
+func (s *OrderService) SetOrderCustomer(id uint, req *models.SetCustomerRequest) (*models.Orders, error) {
+	order, err := s.GetOrderById(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.CustomerID != nil {
+		if _, err := findCustomer(s.db, *req.CustomerID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.db.Model(order).Update("customer_id", req.CustomerID).Error; err != nil {
+		return nil, err
+	}
+	order.CustomerID = req.CustomerID
+	return order, nil
+}
+
+// CreateOrder places an order, or saves it as a draft. The order, its items, the audit entry
+// and whatever the transition hooks write, e.g. the stock taken by the sale, are written in one
+// transaction, so an order never exists without its stock movements.
+func (s *OrderService) CreateOrder(req *models.CreateOrderRequest, actor models.ActivityActor) (*models.Orders, error) {
+	var order *models.Orders
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = s.CreateOrderTx(tx, req, nil, actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.OrderCreated(order, actor)
+	return order, nil
+}
+
+// OrderCreated runs the AfterTransition hooks of an order created with CreateOrderTx once the
+// caller's transaction has committed
+func (s *OrderService) OrderCreated(order *models.Orders, actor models.ActivityActor) {
+	if order.Status == models.OrderStatusPlaced {
+		s.transitioned(OrderTransition{Order: order, From: models.OrderStatusDraft, To: models.OrderStatusPlaced, Actor: actor})
+	}
+}
+
+// CreateOrderTx places an order in the caller's transaction, so whatever the order is made
+// from, e.g. a cart, is used up together with placing it. A coupon the caller has checked
+// adds its promotion. The caller runs OrderCreated after committing.
+func (s *OrderService) CreateOrderTx(tx *gorm.DB, req *models.CreateOrderRequest, coupon *models.Coupons, actor models.ActivityActor) (*models.Orders, error) {
+	items, err := s.buildOrderItems(tx, req.Items, req.Region)
+	if err != nil {
+		return nil, err
+	}
+	var applied []models.AppliedPromotion
+	if !req.QuotedPrices {
+		if applied, err = s.discountItems(tx, items, nil, coupon); err != nil {
+			return nil, err
+		}
+	}
+
+	order := models.Orders{
+		Status:      models.OrderStatusDraft,
+		Note:        req.Note,
+		TaxRegion:   strings.TrimSpace(req.Region),
+		Items:       items,
+		StoreID:     req.StoreID,
+		TableID:     req.TableID,
+		CreatedByID: actorID(actor),
+	}
+	if coupon != nil {
+		order.CouponID = &coupon.ID
+	}
+	if req.CustomerID != nil {
+		customer, err := findCustomer(tx, *req.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+		order.CustomerID = &customer.ID
+	}
+	if req.DeviceID != nil {
+		shift, err := terminalShiftTx(tx, *req.DeviceID)
+		if err != nil {
+			return nil, err
+		}
+		order.DeviceID = req.DeviceID
+		order.ShiftID = &shift.ID
+		if shift.StoreID != nil {
+			order.StoreID = shift.StoreID
+		}
+	}
+	for i, item := range items {
+		// Lines reach the kitchen display once the order is placed
+		items[i].KitchenStatus = models.KitchenStatusQueued
+		order.ItemCount += item.Quantity
+		order.Subtotal += item.Subtotal
+		order.DiscountTotal += item.Discount
+		order.TaxTotal += item.Tax
+		order.IncludedTax += includedTax(item)
+		order.Total += item.Total
+	}
+	for _, tax := range taxBreakdown(items) {
+		order.Taxes = append(order.Taxes, models.OrderTaxes{TaxBreakdown: tax})
+	}
+	for _, promotion := range applied {
+		order.Promotions = append(order.Promotions, models.OrderPromotions{
+			PromotionID: promotion.PromotionID,
+			Name:        promotion.Name,
+			Amount:      promotion.Amount,
+		})
+	}
+
+	if err := tx.Create(&order).Error; err != nil {
+		return nil, err
+	}
+	order.Number = orderNumber(order.ID)
+	if err := tx.Model(&order).UpdateColumn("number", order.Number).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.activityService.RecordTx(tx, actor.UserID, models.ActivityOrderCreated, actor, "Order created", models.JSONMap{
+		"order_id": order.ID,
+		"number":   order.Number,
+		"total":    order.Total,
+		"draft":    req.Draft,
+	}); err != nil {
+		return nil, err
+	}
+
+	if !req.Draft {
+		if _, err := s.transitionTx(tx, &order, models.OrderStatusPlaced, "", actor); err != nil {
+			return nil, err
+		}
+	}
+
+	return &order, nil
+}
+
+// transitionTx moves a loaded order, with its items, to a new status if the transition is
+// allowed, and runs the transition hooks
+func (s *OrderService) transitionTx(tx *gorm.DB, order *models.Orders, status string, reason string, actor models.ActivityActor) (OrderTransition, error) {
+	if !slices.Contains(models.OrderStatusTransitions[order.Status], status) {
+		return OrderTransition{}, errors.New("invalid status transition")
+	}
+	return s.moveTx(tx, order, status, reason, actor)
+}
+
+// paidTx moves a loaded, placed order to paid, and runs the transition hooks. Only settling
+// the order's payments or invoice calls it.
+func (s *OrderService) paidTx(tx *gorm.DB, order *models.Orders, reason string, actor models.ActivityActor) (OrderTransition, error) {
+	if order.Status != models.OrderStatusPlaced {
+		return OrderTransition{}, errors.New("invalid status transition")
+	}
+	return s.moveTx(tx, order, models.OrderStatusPaid, reason, actor)
+}
+
+// refundedTx moves a loaded order whose items have all been returned to refunded, and runs the
+// transition hooks. Only returns call it, once they have refunded the payments.
+func (s *OrderService) refundedTx(tx *gorm.DB, order *models.Orders, reason string, actor models.ActivityActor) (OrderTransition, error) {
+	if !slices.Contains(models.OrderRefundableStatuses, order.Status) {
+		return OrderTransition{}, errors.New("invalid status transition")
+	}
+	return s.moveTx(tx, order, models.OrderStatusRefunded, reason, actor)
+}
+
+// moveTx records the status change of a transition already checked, and runs the transition hooks
+func (s *OrderService) moveTx(tx *gorm.DB, order *models.Orders, status string, reason string, actor models.ActivityActor) (OrderTransition, error) {
+	transition := OrderTransition{
+		Order:  order,
+		From:   order.Status,
+		To:     status,
+		Reason: reason,
+		Actor:  actor,
+	}
+	columns := orderStatusColumns(status, reason, time.Now())
+	if err := tx.Model(order).Updates(columns).Error; err != nil {
+		return OrderTransition{}, err
+	}
+
+	for _, hook := range s.transitionHooks {
+		if err := hook(tx, transition); err != nil {
+			return OrderTransition{}, err
+		}
+	}
+
+	if err := s.activityService.RecordTx(tx, actor.UserID, models.ActivityOrderStatusChanged, actor, fmt.Sprintf("Order %s %s", order.Number, status), models.JSONMap{
+		"order_id": order.ID,
+		"number":   order.Number,
+		"from":     transition.From,
+		"to":       status,
+		"reason":   reason,
+	}); err != nil {
+		return OrderTransition{}, err
+	}
+
+	return transition, nil
+}
+
+// TransitionOrder moves an order to a new status. The order row stays locked while the hooks
+// run, so two changes to the same order can't both pass the transition check.
+func (s *OrderService) TransitionOrder(id uint, status string, reason string, actor models.ActivityActor) (*models.Orders, error) {
+	var order models.Orders
+	var transition OrderTransition
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&order).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("order not found")
+			}
+			return err
+		}
+		if err := tx.Preload("Components").Where("order_id = ?", order.ID).Order("id").Find(&order.Items).Error; err != nil {
+			return err
+		}
+
+		var err error
+		transition, err = s.transitionTx(tx, &order, status, reason, actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.transitioned(transition)
+	return &order, nil
+}
+
+// logHookError logs a failed AfterTransition hook; the status change itself already committed
+func logHookError(transition OrderTransition, what string, err error) {
+	slog.Error("Order moved on but a hook failed", "order", transition.Order.Number, "status", transition.To, "hook", what, "error", err)
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// parkedOwner returns the Owner of a parked cart
+func parkedOwner(cartID uint) string {
+	return fmt.Sprintf("parked:%d", cartID)
+}
+
+// parkedCart returns a parked sale of the owner's store. Sales parked at other stores are not
+// found, so terminals only resume the sales of their own store.
+func parkedCart(db *gorm.DB, id uint, owner models.CartOwner) (*models.Carts, error) {
+	var cart models.Carts
+	if err := db.Where("id = ? AND parked_at IS NOT NULL", id).First(&cart).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("parked sale not found")
+		}
+		return nil, err
+	}
+	if owner.StoreID != nil && !sameOptionalID(cart.StoreID, owner.StoreID) {
+		return nil, errors.New("parked sale not found")
+	}
+	return &cart, nil
+}
+
+// ParkCart puts the sale in the owner's cart on hold under a label, leaving the terminal free to
+// serve the next customer. The parked sale keeps its lines, coupon and customer until it is
+// resumed or voided.
+func (s *CartService) ParkCart(ctx context.Context, owner models.CartOwner, req *models.ParkCartRequest) (*models.CartResponse, error) {
+	var cart *models.Carts
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		cart, err = lockCart(tx, owner)
+		if err != nil {
+			return err
+		}
+		if len(cart.Lines) == 0 {
+			return errors.New("cart is empty")
+		}
+
+		now := time.Now()
+		cart.Owner = parkedOwner(cart.ID)
+		cart.StoreID = owner.StoreID
+		cart.Label = req.Label
+		cart.ParkedAt = &now
+		cart.UserID = owner.UserID
+		return tx.Save(cart).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateCart(owner)
+	return s.priceCart(ctx, cart, owner.Location)
+}
+
+// GetParkedSales retrieves the sales parked at the store the request works in, with pagination
+func (s *CartService) GetParkedSales(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.Carts{},
+		Conditions:   []string{"parked_at IS NOT NULL"},
+		SearchFields: []string{"label", "note"},
+		FilterFields: map[string]string{
+			"user_id":     "user_id",
+			"customer_id": "customer_id",
+			"device_id":   "device_id",
+		},
+		StoreFilter: "store_id = ?",
+		SortFields: []string{
+			"label",
+			"parked_at",
+		},
+		DefaultSort:  "parked_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetParkedSale retrieves a parked sale with its running totals
+func (s *CartService) GetParkedSale(ctx context.Context, owner models.CartOwner, id uint) (*models.CartResponse, error) {
+	cart, err := parkedCart(s.db.WithContext(ctx), id, owner)
+	if err != nil {
+		return nil, err
+	}
+	return s.priceCart(ctx, cart, owner.Location)
+}
+
+// ResumeParkedSale takes a parked sale back into the owner's cart, which must be empty, e.g. on
+// another terminal of the store than the one it was parked at
+func (s *CartService) ResumeParkedSale(ctx context.Context, owner models.CartOwner, id uint) (*models.CartResponse, error) {
+	var cart *models.Carts
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		cart, err = parkedCart(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id, owner)
+		if err != nil {
+			return err
+		}
+		current, err := lockCart(tx, owner)
+		if err != nil {
+			return err
+		}
+		if len(current.Lines) > 0 {
+			return errors.New("cart not empty")
+		}
+
+		// The owner's empty cart makes way for the parked sale
+		if err := s.coupons.ReleaseTx(tx, current); err != nil {
+			return err
+		}
+		if err := tx.Delete(current).Error; err != nil {
+			return err
+		}
+		cart.Owner = owner.Key()
+		cart.DeviceID = owner.DeviceID
+		cart.Label = ""
+		cart.ParkedAt = nil
+		cart.UserID = owner.UserID
+		return tx.Save(cart).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateCart(owner)
+	return s.priceCart(ctx, cart, owner.Location)
+}
+
+// VoidParkedSale discards a parked sale the customer walked away from and gives back the
+// coupon use it reserved
+func (s *CartService) VoidParkedSale(ctx context.Context, owner models.CartOwner, id uint) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		cart, err := parkedCart(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id, owner)
+		if err != nil {
+			return err
+		}
+		if err := s.coupons.ReleaseTx(tx, cart); err != nil {
+			return err
+		}
+		return tx.Delete(cart).Error
+	})
+}
@@ -0,0 +1,452 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/payments"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// orderPaidSQL is what an order's payments add up to: captured minus refunded
+const orderPaidSQL = "COALESCE((SELECT SUM(payments.amount - payments.refunded_amount) FROM payments WHERE payments.order_id = orders.id AND payments.status IN ('captured', 'refunded')), 0)"
+
+// orderExpectedPaidSQL is what an order's payments should add up to. Paid orders must be
+// covered exactly and cancelled or refunded orders must have been paid back; open orders may be
+// partly paid but never overpaid.
+const orderExpectedPaidSQL = "CASE WHEN orders.status IN ('paid', 'fulfilled', 'completed') THEN orders.total " +
+	"WHEN orders.status IN ('cancelled', 'refunded') THEN 0 " +
+	"ELSE LEAST(orders.total, " + orderPaidSQL + ") END"
+
+// PaymentService takes payments for orders through the provider of each payment method. An
+// order moves to paid in the same transaction as the payment that covers its total. Cash taken
+// and refunded is recorded on the cashier's open cash drawer, loyalty points spent and refunded
+// on the customer's balance, and gift card payments on the card's balance.
+type PaymentService struct {
+	db                  *gorm.DB
+	orders              *OrderService
+	loyalty             *LoyaltyService
+	giftCards           *GiftCardService
+	providers           *payments.Registry
+	activityService     *ActivityService
+	stripeWebhookSecret string
+}
+
+func NewPaymentService(db *gorm.DB, orders *OrderService, loyalty *LoyaltyService, giftCards *GiftCardService, providers *payments.Registry, activityService *ActivityService, stripeWebhookSecret string) *PaymentService {
+	return &PaymentService{
+		db:                  db,
+		orders:              orders,
+		loyalty:             loyalty,
+		giftCards:           giftCards,
+		providers:           providers,
+		activityService:     activityService,
+		stripeWebhookSecret: stripeWebhookSecret,
+	}
+}
+
+// lockOrder loads an order for update, so concurrent payments can't both fit in its balance
+func lockOrder(tx *gorm.DB, id uint) (*models.Orders, error) {
+	var order models.Orders
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("order not found")
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// summarizePayments adds an order's payments up against its total
+func summarizePayments(order *models.Orders, orderPayments []models.Payments) *models.OrderPaymentsResponse {
+	summary := &models.OrderPaymentsResponse{
+		OrderID:  order.ID,
+		Total:    order.Total,
+		Payments: orderPayments,
+	}
+	for _, payment := range orderPayments {
+		switch payment.Status {
+		case models.PaymentStatusPending:
+			summary.Pending += payment.Amount
+		case models.PaymentStatusAuthorized:
+			summary.Authorized += payment.Amount
+		case models.PaymentStatusCaptured, models.PaymentStatusRefunded:
+			summary.Captured += payment.Amount
+			summary.Refunded += payment.RefundedAmount
+		}
+	}
+	summary.Paid = summary.Captured - summary.Refunded
+	summary.Balance = max(order.Total-summary.Paid-summary.Authorized-summary.Pending, 0)
+	return summary
+}
+
+// orderPayments loads the payments of an order, oldest first
+func orderPayments(db *gorm.DB, orderID uint) ([]models.Payments, error) {
+	var result []models.Payments
+	if err := db.Where("order_id = ?", orderID).Order("id").Find(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetOrderPayments retrieves the payments of an order with how they add up against its total
+func (s *PaymentService) GetOrderPayments(orderID uint) (*models.OrderPaymentsResponse, error) {
+	var order models.Orders
+	if err := s.db.Where("id = ?", orderID).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("order not found")
+		}
+		return nil, err
+	}
+
+	result, err := orderPayments(s.db, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	return summarizePayments(&order, result), nil
+}
+
+// settleTx moves a placed order to paid once its captured payments cover the total
+func (s *PaymentService) settleTx(tx *gorm.DB, order *models.Orders, actor models.ActivityActor) (*OrderTransition, error) {
+	if order.Status != models.OrderStatusPlaced {
+		return nil, nil
+	}
+
+	result, err := orderPayments(tx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	if summarizePayments(order, result).Paid < order.Total {
+		return nil, nil
+	}
+
+	if err := tx.Preload("Components").Where("order_id = ?", order.ID).Order("id").Find(&order.Items).Error; err != nil {
+		return nil, err
+	}
+	transition, err := s.orders.paidTx(tx, order, "", actor)
+	if err != nil {
+		return nil, err
+	}
+	return &transition, nil
+}
+
+// recordPaymentActivity records a payment on the creator's activity log
+func (s *PaymentService) recordPaymentActivity(tx *gorm.DB, eventType string, description string, order *models.Orders, payment *models.Payments, amount int64, actor models.ActivityActor) error {
+	return s.activityService.RecordTx(tx, actor.UserID, eventType, actor, description, models.JSONMap{
+		"order_id":   order.ID,
+		"number":     order.Number,
+		"payment_id": payment.ID,
+		"method":     payment.Method,
+		"amount":     amount,
+	})
+}
+
+// CreatePayment pays part or all of a placed order through the provider of the payment method.
+// The order stays locked while the provider is called, so split payments made at the same time
+// can't overpay it. A declined payment is kept as failed and reported with a DeclinedError;
+// payments the provider is still confirming are kept as pending until its webhook settles them.
+func (s *PaymentService) CreatePayment(ctx context.Context, orderID uint, req *models.CreatePaymentRequest, actor models.ActivityActor) (*models.Payments, error) {
+	provider, ok := s.providers.Provider(req.Method)
+	if !ok {
+		return nil, errors.New("unsupported payment method")
+	}
+
+	tendered := req.Amount
+	if req.Tendered != nil {
+		if req.Method != payments.MethodCash {
+			return nil, errors.New("tendered amount only applies to cash")
+		}
+		if *req.Tendered < req.Amount {
+			return nil, errors.New("tendered amount below payment")
+		}
+		tendered = *req.Tendered
+	}
+
+	var payment models.Payments
+	var transition *OrderTransition
+	var declined error
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		order, err := lockOrder(tx, orderID)
+		if err != nil {
+			return err
+		}
+		if order.Status != models.OrderStatusPlaced {
+			return errors.New("order not payable")
+		}
+
+		existing, err := orderPayments(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		if req.Amount > summarizePayments(order, existing).Balance {
+			return errors.New("payment exceeds balance")
+		}
+
+		shiftID, err := userShiftIDTx(tx, actor.UserID)
+		if err != nil {
+			return err
+		}
+
+		payment = models.Payments{
+			OrderID:     order.ID,
+			ShiftID:     shiftID,
+			Method:      req.Method,
+			Amount:      req.Amount,
+			Tendered:    tendered,
+			Change:      tendered - req.Amount,
+			Reference:   req.Reference,
+			CreatedByID: actorID(actor),
+		}
+
+		capture := req.Capture == nil || *req.Capture
+		result, err := provider.Authorize(ctx, payments.Request{
+			OrderID:     order.ID,
+			OrderNumber: order.Number,
+			Amount:      req.Amount,
+			Reference:   req.Reference,
+			Capture:     capture,
+			// Retries of a failed request see the same payments, so they reuse the key
+			IdempotencyKey: fmt.Sprintf("order-%d-payment-%d", order.ID, len(existing)+1),
+		})
+		if err != nil {
+			reason, ok := payments.AsDeclined(err)
+			if !ok {
+				return err
+			}
+			// The failed attempt is kept, so it is committed and the decline returned afterwards
+			declined = err
+			payment.Status = models.PaymentStatusFailed
+			payment.FailureReason = reason.Reason
+			payment.Change = 0
+			return tx.Create(&payment).Error
+		}
+		payment.Reference = result.Reference
+		payment.Status = models.PaymentStatusAuthorized
+		if result.Pending {
+			payment.Status = models.PaymentStatusPending
+		}
+
+		if !result.Captured && !result.Pending && capture {
+			if err := provider.Capture(ctx, result.Reference, req.Amount); err != nil {
+				return err
+			}
+			result.Captured = true
+		}
+		if result.Captured {
+			now := time.Now()
+			payment.Status = models.PaymentStatusCaptured
+			payment.CapturedAt = &now
+		}
+
+		if err := tx.Create(&payment).Error; err != nil {
+			return err
+		}
+		switch payment.Method {
+		case payments.MethodCash:
+			if err := recordCashTx(tx, actor.UserID, models.CashMovementSale, payment.Amount, payment.ID); err != nil {
+				return err
+			}
+		case payments.MethodLoyalty:
+			if err := s.loyalty.redeemTx(tx, order, &payment, actor); err != nil {
+				return err
+			}
+		case payments.MethodGiftCard:
+			if err := s.giftCards.redeemTx(tx, order, &payment, req.Reference, actor); err != nil {
+				return err
+			}
+		}
+		if err := s.recordPaymentActivity(tx, models.ActivityPaymentRecorded, fmt.Sprintf("Payment for order %s recorded", order.Number), order, &payment, payment.Amount, actor); err != nil {
+			return err
+		}
+
+		transition, err = s.settleTx(tx, order, actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if transition != nil {
+		s.orders.transitioned(*transition)
+	}
+	if declined != nil {
+		return &payment, declined
+	}
+	return &payment, nil
+}
+
+// lockPayment loads a payment of an order and locks the order for update
+func (s *PaymentService) lockPayment(tx *gorm.DB, orderID uint, paymentID uint) (*models.Orders, *models.Payments, error) {
+	order, err := lockOrder(tx, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var payment models.Payments
+	if err := tx.Where("id = ? AND order_id = ?", paymentID, order.ID).First(&payment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("payment not found")
+		}
+		return nil, nil, err
+	}
+	return order, &payment, nil
+}
+
+// CapturePayment takes an authorized payment in full
+func (s *PaymentService) CapturePayment(ctx context.Context, orderID uint, paymentID uint, actor models.ActivityActor) (*models.Payments, error) {
+	var payment *models.Payments
+	var transition *OrderTransition
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		order, locked, err := s.lockPayment(tx, orderID, paymentID)
+		if err != nil {
+			return err
+		}
+		payment = locked
+		if payment.Status != models.PaymentStatusAuthorized {
+			return errors.New("payment not authorized")
+		}
+
+		provider, ok := s.providers.Provider(payment.Method)
+		if !ok {
+			return errors.New("unsupported payment method")
+		}
+		if err := provider.Capture(ctx, payment.Reference, payment.Amount); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(payment).Updates(map[string]interface{}{
+			"status":      models.PaymentStatusCaptured,
+			"captured_at": now,
+		}).Error; err != nil {
+			return err
+		}
+
+		transition, err = s.settleTx(tx, order, actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if transition != nil {
+		s.orders.transitioned(*transition)
+	}
+	return payment, nil
+}
+
+// RefundPayment returns part or all of a captured payment through its provider. The order's
+// status is left alone; refunding the order itself is a separate transition.
+func (s *PaymentService) RefundPayment(ctx context.Context, orderID uint, paymentID uint, req *models.RefundPaymentRequest, actor models.ActivityActor) (*models.Payments, error) {
+	var payment *models.Payments
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		order, locked, err := s.lockPayment(tx, orderID, paymentID)
+		if err != nil {
+			return err
+		}
+		payment = locked
+		return s.refundPaymentTx(ctx, tx, order, payment, req.Amount, req.Reason, actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// refundPaymentTx refunds a captured payment of a locked order through its provider
+func (s *PaymentService) refundPaymentTx(ctx context.Context, tx *gorm.DB, order *models.Orders, payment *models.Payments, amount int64, reason string, actor models.ActivityActor) error {
+	if payment.Status != models.PaymentStatusCaptured {
+		return errors.New("payment not refundable")
+	}
+	if amount > payment.Amount-payment.RefundedAmount {
+		return errors.New("refund exceeds payment")
+	}
+
+	provider, ok := s.providers.Provider(payment.Method)
+	if !ok {
+		return errors.New("unsupported payment method")
+	}
+	if err := provider.Refund(ctx, payment.Reference, amount); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	refunded := payment.RefundedAmount + amount
+	columns := map[string]interface{}{
+		"refunded_amount": refunded,
+		"refunded_at":     now,
+	}
+	if refunded == payment.Amount {
+		columns["status"] = models.PaymentStatusRefunded
+	}
+	if err := tx.Model(payment).Updates(columns).Error; err != nil {
+		return err
+	}
+	switch payment.Method {
+	case payments.MethodCash:
+		if err := recordCashTx(tx, actor.UserID, models.CashMovementRefund, amount, payment.ID); err != nil {
+			return err
+		}
+	case payments.MethodLoyalty:
+		if err := s.loyalty.refundTx(tx, order, payment, amount, actor); err != nil {
+			return err
+		}
+	case payments.MethodGiftCard:
+		if err := s.giftCards.refundTx(tx, order, payment, amount, actor); err != nil {
+			return err
+		}
+	}
+
+	description := fmt.Sprintf("Payment for order %s refunded", order.Number)
+	if reason != "" {
+		description += ": " + reason
+	}
+	return s.recordPaymentActivity(tx, models.ActivityPaymentRefunded, description, order, payment, amount, actor)
+}
+
+// GetReconciliation retrieves orders whose payments don't add up: paid orders that aren't
+// covered exactly, cancelled or refunded orders that weren't paid back, and overpaid open
+// orders. The largest differences come first.
+func (s *PaymentService) GetReconciliation(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:       &models.Orders{},
+		Conditions:  []string{orderPaidSQL + " <> " + orderExpectedPaidSQL},
+		StoreFilter: "orders.store_id = ?",
+		SelectFields: []pagination.SelectField{
+			{Field: "orders.id", Alias: "id"},
+			{Field: "orders.number", Alias: "number"},
+			{Field: "orders.status", Alias: "status"},
+			{Field: "orders.total", Alias: "total"},
+			{Field: orderPaidSQL, Alias: "paid"},
+			{Field: orderExpectedPaidSQL, Alias: "expected"},
+			{Field: "ABS(" + orderPaidSQL + " - (" + orderExpectedPaidSQL + "))", Alias: "difference"},
+			{Field: "orders.created_at", Alias: "created_at"},
+		},
+		ScanIntoMaps: true,
+		SearchFields: []string{"orders.number"},
+		FilterFields: map[string]string{
+			"status": "orders.status",
+		},
+		DateFields: map[string]pagination.DateField{
+			"created_at": {
+				Start: "orders.created_at",
+				End:   "orders.created_at",
+			},
+		},
+		SortFields: []string{
+			"number",
+			"total",
+			"difference",
+			"created_at",
+		},
+		DefaultSort:  "difference",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// permissionCacheTTL bounds how long granted permissions are reused by the permission middleware
+const permissionCacheTTL = time.Minute
+
+// PermissionService grants permissions to roles. Grants are kept in memory for a short
+// while so permission checks don't hit the database on every request. In strict claims mode
+// every check also compares a version shared through the cache, so a grant or revoke on any
+// instance applies on the next request everywhere.
+type PermissionService struct {
+	db     *gorm.DB
+	cache  *cache.Cache
+	strict bool
+
+	mu        sync.RWMutex
+	grants    map[string]map[string]bool
+	expiresAt time.Time
+	version   string
+}
+
+func NewPermissionService(db *gorm.DB, cache *cache.Cache, claimsMode string) *PermissionService {
+	return &PermissionService{
+		db:     db,
+		cache:  cache,
+		strict: claimsMode == models.ClaimsModeStrict,
+	}
+}
+
+// invalidate drops the cached grants after a change and tells the other instances to do the same
+func (s *PermissionService) invalidate() {
+	s.mu.Lock()
+	s.grants = nil
+	s.mu.Unlock()
+
+	version := []byte(strconv.FormatInt(time.Now().UnixNano(), 10))
+	if err := s.cache.Set(context.Background(), cache.PermissionsVersionKey, version, 0); err != nil && !errors.Is(err, cache.ErrUnavailable) {
+		slog.Warn("Failed to publish permissions version", "error", err)
+	}
+}
+
+// sharedVersion returns the permissions version shared through the cache. It reports false when
+// the cache can't tell whether grants changed elsewhere.
+func (s *PermissionService) sharedVersion() (string, bool) {
+	data, err := s.cache.Get(context.Background(), cache.PermissionsVersionKey)
+	switch {
+	case err == nil:
+		return string(data), true
+	case errors.Is(err, cache.ErrMiss):
+		return "", true
+	default:
+		return "", false
+	}
+}
+
+// loadGrants returns the permissions granted to each role
+func (s *PermissionService) loadGrants() (map[string]map[string]bool, error) {
+	s.mu.RLock()
+	grants, expiresAt, version := s.grants, s.expiresAt, s.version
+	s.mu.RUnlock()
+
+	current := version
+	if s.strict {
+		var ok bool
+		// Without the cache, strict mode reads grants from the database on every check
+		if current, ok = s.sharedVersion(); !ok {
+			grants = nil
+		}
+	}
+	if grants != nil && time.Now().Before(expiresAt) && current == version {
+		return grants, nil
+	}
+
+	var rows []models.RolePermissions
+	if err := s.db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	grants = map[string]map[string]bool{}
+	for _, row := range rows {
+		if grants[row.Role] == nil {
+			grants[row.Role] = map[string]bool{}
+		}
+		grants[row.Role][row.Permission] = true
+	}
+
+	s.mu.Lock()
+	s.grants = grants
+	s.expiresAt = time.Now().Add(permissionCacheTTL)
+	s.version = current
+	s.mu.Unlock()
+
+	return grants, nil
+}
+
+// HasPermission reports whether the role holds the permission. Administrators hold every permission.
+func (s *PermissionService) HasPermission(role string, permission string) (bool, error) {
+	if role == models.RoleAdmin {
+		return true, nil
+	}
+	grants, err := s.loadGrants()
+	if err != nil {
+		return false, err
+	}
+	return grants[role][permission], nil
+}
+
+// GetPermissions lists every permission and the roles it is granted to
+func (s *PermissionService) GetPermissions() ([]models.PermissionInfo, error) {
+	grants, err := s.loadGrants()
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make([]models.PermissionInfo, 0, len(models.PermissionCatalog))
+	for permission, description := range models.PermissionCatalog {
+		roles := []string{models.RoleAdmin}
+		for role, granted := range grants {
+			if role != models.RoleAdmin && granted[permission] {
+				roles = append(roles, role)
+			}
+		}
+		sort.Strings(roles)
+		permissions = append(permissions, models.PermissionInfo{
+			Permission:  permission,
+			Description: description,
+			Roles:       roles,
+		})
+	}
+	sort.Slice(permissions, func(i, j int) bool {
+		return permissions[i].Permission < permissions[j].Permission
+	})
+
+	return permissions, nil
+}
+
+// GrantPermission grants the permission to every listed role inside one transaction.
+// Roles that already hold it are reported as failed and skipped.
+func (s *PermissionService) GrantPermission(permission string, roles []string, actor models.ActivityActor) (*models.PermissionRolesResponse, error) {
+	if _, ok := models.PermissionCatalog[permission]; !ok {
+		return nil, errors.New("permission not found")
+	}
+
+	return s.applyToRoles(permission, roles, func(tx *gorm.DB, role string) (string, error) {
+		if role == models.RoleAdmin {
+			return "administrators hold every permission", nil
+		}
+
+		var existing models.RolePermissions
+		if err := tx.Where("role = ? AND permission = ?", role, permission).First(&existing).Error; err == nil {
+			return "role already has this permission", nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", err
+		}
+
+		grant := models.RolePermissions{Role: role, Permission: permission, GrantedByID: actorID(actor)}
+		return "", tx.Create(&grant).Error
+	})
+}
+
+// RevokePermission takes the permission away from every listed role inside one transaction.
+// Roles that do not hold it are reported as failed and skipped.
+func (s *PermissionService) RevokePermission(permission string, roles []string) (*models.PermissionRolesResponse, error) {
+	if _, ok := models.PermissionCatalog[permission]; !ok {
+		return nil, errors.New("permission not found")
+	}
+
+	return s.applyToRoles(permission, roles, func(tx *gorm.DB, role string) (string, error) {
+		if role == models.RoleAdmin {
+			return "administrators hold every permission", nil
+		}
+
+		result := tx.Where("role = ? AND permission = ?", role, permission).Delete(&models.RolePermissions{})
+		if result.Error != nil {
+			return "", result.Error
+		}
+		if result.RowsAffected == 0 {
+			return "role does not have this permission", nil
+		}
+		return "", nil
+	})
+}
+
+// applyToRoles runs fn for each distinct role in one transaction. fn returns a reason to skip the
+// role, or an error to roll back every role.
+func (s *PermissionService) applyToRoles(permission string, roles []string, fn func(tx *gorm.DB, role string) (string, error)) (*models.PermissionRolesResponse, error) {
+	response := &models.PermissionRolesResponse{
+		Permission: permission,
+		Results:    make([]models.PermissionRoleResult, 0, len(roles)),
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		seen := map[string]bool{}
+		for _, role := range roles {
+			if seen[role] {
+				continue
+			}
+			seen[role] = true
+
+			skip, err := fn(tx, role)
+			if err != nil {
+				return err
+			}
+			if skip != "" {
+				response.Results = append(response.Results, models.PermissionRoleResult{Role: role, Error: skip})
+				response.Failed++
+				continue
+			}
+			response.Results = append(response.Results, models.PermissionRoleResult{Role: role, Success: true})
+			response.Succeeded++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return response, nil
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// presenceScanCount is the number of keys requested per SCAN round trip
+const presenceScanCount = 200
+
+// PresenceService tracks which users are online with a short-lived Redis key per user.
+// Every authenticated request refreshes the key; a user is online until it expires.
+type PresenceService struct {
+	db    *gorm.DB
+	cache *cache.Cache
+	ttl   time.Duration
+}
+
+func NewPresenceService(db *gorm.DB, cache *cache.Cache, ttl time.Duration) *PresenceService {
+	return &PresenceService{
+		db:    db,
+		cache: cache,
+		ttl:   ttl,
+	}
+}
+
+// Touch marks the user as online. Skipped while Redis is unavailable.
+func (s *PresenceService) Touch(ctx context.Context, userID uint) error {
+	lastSeen := strconv.FormatInt(time.Now().Unix(), 10)
+	return s.cache.Set(ctx, cache.PresenceKey(userID), []byte(lastSeen), s.ttl)
+}
+
+// GetOnlineUsers lists the users whose presence key has not expired, most recently seen first
+func (s *PresenceService) GetOnlineUsers(ctx context.Context) ([]models.OnlineUser, error) {
+	client, ok := s.cache.Client()
+	if !ok {
+		return nil, errors.New("presence unavailable")
+	}
+
+	var keys []string
+	var cursor uint64
+	for {
+		batch, next, err := client.Scan(ctx, cursor, cache.PresenceKeyPrefix+"*", presenceScanCount).Result()
+		if err != nil {
+			return nil, errors.New("presence unavailable")
+		}
+		keys = append(keys, batch...)
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	online := []models.OnlineUser{}
+	if len(keys) == 0 {
+		return online, nil
+	}
+
+	values, err := client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, errors.New("presence unavailable")
+	}
+
+	lastSeen := make(map[uint]time.Time, len(keys))
+	userIDs := make([]uint, 0, len(keys))
+	for i, key := range keys {
+		id, err := strconv.ParseUint(strings.TrimPrefix(key, cache.PresenceKeyPrefix), 10, 32)
+		if err != nil {
+			continue
+		}
+		value, ok := values[i].(string)
+		if !ok {
+			continue // expired between SCAN and MGET
+		}
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		lastSeen[uint(id)] = time.Unix(seconds, 0)
+		userIDs = append(userIDs, uint(id))
+	}
+
+	var users []models.Users
+	if len(userIDs) > 0 {
+		if err := s.db.Select("id", "username", "name", "role").
+			Where("id IN ? AND is_deleted = ?", userIDs, false).
+			Find(&users).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	for _, user := range users {
+		online = append(online, models.OnlineUser{
+			ID:         user.ID,
+			Username:   user.Username,
+			Name:       user.Name,
+			Role:       user.Role,
+			LastSeenAt: lastSeen[user.ID],
+		})
+	}
+
+	// Most recently seen first
+	sort.Slice(online, func(i, j int) bool {
+		return online[i].LastSeenAt.After(online[j].LastSeenAt)
+	})
+
+	return online, nil
+}
+
+// MarkOnline sets IsOnline on each user. Users are left untouched while presence is unknown.
+func (s *PresenceService) MarkOnline(ctx context.Context, users []models.Users) {
+	if len(users) == 0 {
+		return
+	}
+
+	client, ok := s.cache.Client()
+	if !ok {
+		return
+	}
+
+	keys := make([]string, len(users))
+	for i, user := range users {
+		keys[i] = cache.PresenceKey(user.ID)
+	}
+
+	values, err := client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return
+	}
+
+	for i := range users {
+		isOnline := values[i] != nil
+		users[i].IsOnline = &isOnline
+	}
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// printJobClaimTimeout is how long a terminal has to acknowledge a claimed job
+	// before it is handed out again
+	printJobClaimTimeout = 2 * time.Minute
+	// printJobRetryDelay is the base delay before a failed job is retried, doubled per attempt
+	printJobRetryDelay = 5 * time.Second
+)
+
+// PrintJobEvent is a committed change to a print job. StoreID is the store of the job's
+// device, nil for devices outside any store.
+type PrintJobEvent struct {
+	Job     models.PrintJobs
+	StoreID *uint
+}
+
+// PrintJobEventHook runs once a change to a print job is committed
+type PrintJobEventHook func(event PrintJobEvent)
+
+type PrintJobService struct {
+	db    *gorm.DB
+	hooks []PrintJobEventHook
+}
+
+func NewPrintJobService(db *gorm.DB) *PrintJobService {
+	return &PrintJobService{db: db}
+}
+
+// AfterChange subscribes a hook to every committed change to a print job. Hooks are not
+// synchronized and must be subscribed before the service is used.
+func (s *PrintJobService) AfterChange(hook PrintJobEventHook) {
+	s.hooks = append(s.hooks, hook)
+}
+
+// changed runs the hooks subscribed with AfterChange. Failing to load the device's store is
+// logged rather than returned, since the job has already changed.
+func (s *PrintJobService) changed(job *models.PrintJobs) {
+	if len(s.hooks) == 0 {
+		return
+	}
+
+	var device models.Devices
+	if err := s.db.Unscoped().Select("id", "store_id").Where("id = ?", job.DeviceID).First(&device).Error; err != nil {
+		slog.Error("Failed to load the device of a changed print job", "print_job_id", job.ID, "error", err)
+		return
+	}
+
+	event := PrintJobEvent{Job: *job, StoreID: device.StoreID}
+	for _, hook := range s.hooks {
+		hook(event)
+	}
+}
+
+// GetAllPrintJobs retrieves print jobs with pagination and filters
+func (s *PrintJobService) GetAllPrintJobs(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model: &models.PrintJobs{},
+		FilterFields: map[string]string{
+			"device_id": "device_id",
+			"status":    "status",
+			"type":      "type",
+		},
+		StoreFilter: "device_id IN (SELECT id FROM devices WHERE store_id = ?)",
+		DateFields: map[string]pagination.DateField{
+			"created_at": {
+				Start: "created_at",
+				End:   "created_at",
+			},
+		},
+		SortFields: []string{
+			"status",
+			"created_at",
+			"updated_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetPrintJobById retrieves a print job by ID
+func (s *PrintJobService) GetPrintJobById(id uint) (*models.PrintJobs, error) {
+	var job models.PrintJobs
+	if err := s.db.Where("id = ?", id).First(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("print job not found")
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// CreatePrintJob enqueues a print job for a device. Jobs queued from a store may only print on
+// the devices of that store.
+func (s *PrintJobService) CreatePrintJob(req *models.CreatePrintJobRequest, createdByID uint) (*models.PrintJobs, error) {
+	var device models.Devices
+	if err := s.db.Where("id = ?", req.DeviceID).First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("device not found")
+		}
+		return nil, err
+	}
+
+	if device.Status != models.DeviceStatusActive {
+		return nil, errors.New("device is not active")
+	}
+	if req.StoreID != nil && (device.StoreID == nil || *device.StoreID != *req.StoreID) {
+		return nil, errors.New("device is in another store")
+	}
+
+	job := models.PrintJobs{
+		DeviceID:    device.ID,
+		Type:        req.Type,
+		Payload:     req.Payload,
+		Copies:      req.Copies,
+		MaxAttempts: req.MaxAttempts,
+		Status:      models.PrintJobStatusQueued,
+		AvailableAt: time.Now(),
+	}
+	if job.Copies == 0 {
+		job.Copies = 1
+	}
+	if job.MaxAttempts == 0 {
+		job.MaxAttempts = 3
+	}
+	if createdByID != 0 {
+		job.CreatedByID = &createdByID
+	}
+
+	if err := s.db.Create(&job).Error; err != nil {
+		return nil, err
+	}
+
+	s.changed(&job)
+	return &job, nil
+}
+
+// ClaimNextPrintJob hands the oldest available job for a device to the terminal.
+// Jobs whose claim has timed out are handed out again. Returns nil when the queue is empty.
+func (s *PrintJobService) ClaimNextPrintJob(deviceID uint) (*models.PrintJobs, error) {
+	var job models.PrintJobs
+	now := time.Now()
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("device_id = ?", deviceID).
+			Where("(status = ? AND available_at <= ?) OR (status = ? AND claimed_at < ?)",
+				models.PrintJobStatusQueued, now,
+				models.PrintJobStatusPrinting, now.Add(-printJobClaimTimeout)).
+			Order("available_at ASC, id ASC").
+			First(&job).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&job).Updates(map[string]interface{}{
+			"status":     models.PrintJobStatusPrinting,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"claimed_at": now,
+		}).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// The attempts counter is incremented in SQL, so reflect it on the returned job
+	job.Attempts++
+	s.changed(&job)
+	return &job, nil
+}
+
+// AckPrintJob records the outcome reported by the terminal that claimed the job.
+// Failed jobs are requeued with exponential backoff until they run out of attempts.
+func (s *PrintJobService) AckPrintJob(deviceID uint, id uint, req *models.AckPrintJobRequest) (*models.PrintJobs, error) {
+	job, err := s.GetPrintJobById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.DeviceID != deviceID {
+		return nil, errors.New("print job not found")
+	}
+	if job.Status != models.PrintJobStatusPrinting {
+		return nil, errors.New("print job is not being printed")
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{}
+
+	if req.Status == models.PrintJobStatusPrinted {
+		updates["status"] = models.PrintJobStatusPrinted
+		updates["printed_at"] = now
+		updates["last_error"] = ""
+	} else if job.Attempts < job.MaxAttempts {
+		updates["status"] = models.PrintJobStatusQueued
+		updates["available_at"] = now.Add(printJobRetryDelay << (job.Attempts - 1))
+		updates["last_error"] = req.Error
+	} else {
+		updates["status"] = models.PrintJobStatusFailed
+		updates["last_error"] = req.Error
+	}
+
+	if err := s.db.Model(job).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	s.changed(job)
+	return job, nil
+}
+
+// CancelPrintJob cancels a job that has not been printed yet
+func (s *PrintJobService) CancelPrintJob(id uint) (*models.PrintJobs, error) {
+	job, err := s.GetPrintJobById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status == models.PrintJobStatusPrinted || job.Status == models.PrintJobStatusCancelled {
+		return nil, errors.New("print job can no longer be cancelled")
+	}
+
+	if err := s.db.Model(job).Update("status", models.PrintJobStatusCancelled).Error; err != nil {
+		return nil, err
+	}
+
+	s.changed(job)
+	return job, nil
+}
+
+// RetryPrintJob requeues a failed or cancelled job with a fresh set of attempts
+func (s *PrintJobService) RetryPrintJob(id uint) (*models.PrintJobs, error) {
+	job, err := s.GetPrintJobById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status != models.PrintJobStatusFailed && job.Status != models.PrintJobStatusCancelled {
+		return nil, errors.New("print job can not be retried")
+	}
+
+	if err := s.db.Model(job).Updates(map[string]interface{}{
+		"status":       models.PrintJobStatusQueued,
+		"attempts":     0,
+		"available_at": time.Now(),
+		"claimed_at":   nil,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	s.changed(job)
+	return job, nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+)
+
+// barcodeCacheTTL bounds how long a lookup is reused. Product changes invalidate lookups right
+// away; stock is not tracked per barcode, so the stock shown may lag behind by up to this long.
+const barcodeCacheTTL = 30 * time.Second
+
+// barcodeLookupSQL resolves a barcode in one query. Variant barcodes win over product barcodes,
+// and active items over inactive ones. A product scanned by its own barcode reports the stock of
+// all its variants.
+const barcodeLookupSQL = `SELECT p.id AS product_id, pv.id AS variant_id, p.name, pv.title AS variant_title,
+	COALESCE(pv.sku, p.sku) AS sku,
+	COALESCE(NULLIF(pv.barcode, ''), p.barcode) AS barcode,
+	COALESCE(pv.price, p.price) AS price,
+	COALESCE(pv.status, p.status) AS status,
+	COALESCE((SELECT SUM(sl.quantity) FROM stock_levels sl WHERE sl.product_id = p.id AND (pv.id IS NULL OR sl.variant_id = pv.id)), 0) AS stock
+FROM products p
+LEFT JOIN product_variants pv ON pv.product_id = p.id AND pv.barcode = ? AND pv.deleted_at IS NULL
+WHERE p.deleted_at IS NULL AND (pv.id IS NOT NULL OR p.barcode = ?)
+ORDER BY pv.id IS NULL, COALESCE(pv.status, p.status) <> 'active', p.id, pv.id
+LIMIT 1`
+
+// LookupBarcode resolves a scanned barcode to a product or variant with its current price and
+// stock. POS terminals call it on every scan, so results are cached.
+func (s *ProductService) LookupBarcode(ctx context.Context, code string) (*models.BarcodeLookupResponse, error) {
+	key := cache.BarcodeKey(code)
+	if data, err := s.cache.Get(ctx, key); err == nil {
+		var cached models.BarcodeLookupResponse
+		if err := json.Unmarshal(data, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	var results []models.BarcodeLookupResponse
+	if err := s.db.WithContext(ctx).Raw(barcodeLookupSQL, code, code).Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, errors.New("product not found")
+	}
+	result := results[0]
+
+	if data, err := json.Marshal(result); err == nil {
+		if err := s.cache.Set(ctx, key, data, barcodeCacheTTL); err != nil && !errors.Is(err, cache.ErrUnavailable) {
+			slog.WarnContext(ctx, "Failed to cache barcode lookup", "barcode", code, "error", err)
+		}
+	}
+	return &result, nil
+}
+
+// productChanged drops the cached lookups of the product's barcodes, and of barcodes it used
+// before the change, and announces the change on the change feed
+func (s *ProductService) productChanged(productID uint, action string, previousBarcodes ...string) {
+	barcodes := previousBarcodes
+
+	var product models.Products
+	if err := s.db.Unscoped().Select("barcode").First(&product, productID).Error; err == nil {
+		barcodes = append(barcodes, product.Barcode)
+	}
+	var variantBarcodes []string
+	if err := s.db.Unscoped().Model(&models.ProductVariants{}).Where("product_id = ?", productID).Pluck("barcode", &variantBarcodes).Error; err == nil {
+		barcodes = append(barcodes, variantBarcodes...)
+	}
+
+	keys := make([]string, 0, len(barcodes))
+	for _, barcode := range barcodes {
+		if barcode != "" {
+			keys = append(keys, cache.BarcodeKey(barcode))
+		}
+	}
+	if err := s.cache.Delete(context.Background(), keys...); err != nil {
+		slog.Warn("Failed to invalidate barcode lookups", "product_id", productID, "error", err)
+	}
+
+	s.changes.Publish(models.ChangeEntityProduct, action, productID)
+}
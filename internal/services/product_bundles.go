@@ -0,0 +1,180 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// bundleComponents loads the components of the bundles among the products, by bundle
+func bundleComponents(db *gorm.DB, productIDs []uint) (map[uint][]models.BundleComponents, error) {
+	var components []models.BundleComponents
+	if err := db.Where("bundle_id IN ?", productIDs).Order("position, id").Find(&components).Error; err != nil {
+		return nil, err
+	}
+	byBundle := make(map[uint][]models.BundleComponents)
+	for _, component := range components {
+		byBundle[component.BundleID] = append(byBundle[component.BundleID], component)
+	}
+	return byBundle, nil
+}
+
+// checkBundle makes sure a bundle can be sold as a set of components: its stock is that of the
+// components, so it can't be tracked itself or issue gift cards
+func checkBundle(bundle bool, tracking string, giftCard bool) error {
+	if bundle && (tracking != "" || giftCard) {
+		return errors.New("invalid bundle")
+	}
+	return nil
+}
+
+// bundlePricing returns how a product sells: as priced for bundles, fixed unless given, and
+// not at all for other products
+func bundlePricing(bundle bool, pricing string) string {
+	if !bundle {
+		return ""
+	}
+	if pricing == "" {
+		return models.BundlePricingFixed
+	}
+	return pricing
+}
+
+// GetBundleComponents returns the components of a bundle product
+func (s *ProductService) GetBundleComponents(id uint) ([]models.BundleComponents, error) {
+	product, err := s.GetProductById(id)
+	if err != nil {
+		return nil, err
+	}
+	if !product.Bundle {
+		return nil, errors.New("product not a bundle")
+	}
+
+	components := []models.BundleComponents{}
+	if err := s.db.Preload("Product").Preload("Variant").
+		Where("bundle_id = ?", product.ID).Order("position, id").Find(&components).Error; err != nil {
+		return nil, err
+	}
+	return components, nil
+}
+
+// SetBundleComponents replaces the components of a bundle product. Components are plain
+// products or variants: not bundles themselves, nor tracked by serial or batch, since selling a
+// bundle doesn't name the units it takes.
+func (s *ProductService) SetBundleComponents(id uint, req *models.SetBundleComponentsRequest) ([]models.BundleComponents, error) {
+	product, err := s.GetProductById(id)
+	if err != nil {
+		return nil, err
+	}
+	if !product.Bundle {
+		return nil, errors.New("product not a bundle")
+	}
+
+	inputs := make([]models.StockMovementInput, len(req.Components))
+	productIDs := make([]uint, len(req.Components))
+	seen := make(map[stockLevelKey]bool, len(req.Components))
+	for i, component := range req.Components {
+		inputs[i] = models.StockMovementInput{ProductID: component.ProductID, VariantID: component.VariantID}
+		key := stockKey(inputs[i])
+		if seen[key] {
+			return nil, errors.New("duplicate bundle component")
+		}
+		seen[key] = true
+		productIDs[i] = component.ProductID
+	}
+	if len(inputs) > 0 {
+		// Components are checked like the products stock moves for, variants included
+		if err := checkMovementTargets(s.db, inputs); err != nil {
+			return nil, err
+		}
+		var invalid int64
+		if err := s.db.Model(&models.Products{}).
+			Where("id IN ? AND (id = ? OR bundle OR tracking <> '' OR gift_card)", uniqueIDs(productIDs), product.ID).
+			Count(&invalid).Error; err != nil {
+			return nil, err
+		}
+		if invalid > 0 {
+			return nil, errors.New("invalid bundle component")
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bundle_id = ?", product.ID).Delete(&models.BundleComponents{}).Error; err != nil {
+			return err
+		}
+		if len(req.Components) == 0 {
+			return nil
+		}
+		components := make([]models.BundleComponents, len(req.Components))
+		for i, component := range req.Components {
+			components[i] = models.BundleComponents{
+				BundleID:  product.ID,
+				ProductID: component.ProductID,
+				VariantID: component.VariantID,
+				Quantity:  component.Quantity,
+				Position:  i,
+			}
+		}
+		return tx.Create(&components).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.productChanged(product.ID, models.ChangeActionUpdated)
+	return s.GetBundleComponents(product.ID)
+}
+
+// priceBundle copies the components of a bundle onto its line and prices the line from them.
+// The line costs what its components cost; derived bundles also sell at what the components
+// sell for. Components no longer for sale make the bundle unavailable.
+func priceBundle(orderItem *models.OrderItems, product models.Products, components []models.BundleComponents, productsByID map[uint]models.Products, variantsByID map[uint]models.ProductVariants) error {
+	if len(components) == 0 {
+		return errors.New("bundle has no components")
+	}
+	if len(orderItem.Serials) > 0 {
+		return errors.New("product not serialized")
+	}
+	if orderItem.Batch != "" {
+		return errors.New("product not batch tracked")
+	}
+
+	var price, cost int64
+	orderItem.Components = make([]models.OrderItemComponents, len(components))
+	for i, component := range components {
+		componentProduct, ok := productsByID[component.ProductID]
+		if !ok || componentProduct.Status != models.ProductStatusActive {
+			return errors.New("product not available")
+		}
+		line := models.OrderItemComponents{
+			ProductID: componentProduct.ID,
+			Name:      componentProduct.Name,
+			SKU:       componentProduct.SKU,
+			PerBundle: component.Quantity,
+			Quantity:  component.Quantity * orderItem.Quantity,
+			UnitPrice: componentProduct.Price,
+			UnitCost:  componentProduct.Cost,
+		}
+		if component.VariantID != nil {
+			variant, ok := variantsByID[*component.VariantID]
+			if !ok || variant.Status != models.ProductStatusActive {
+				return errors.New("product not available")
+			}
+			line.VariantID = &variant.ID
+			line.VariantTitle = variant.Title
+			line.SKU = variant.SKU
+			line.UnitPrice = variant.Price
+			line.UnitCost = variant.Cost
+		}
+		price += line.UnitPrice * line.PerBundle
+		cost += line.UnitCost * line.PerBundle
+		orderItem.Components[i] = line
+	}
+
+	orderItem.UnitCost = cost
+	if product.BundlePricing == models.BundlePricingDerived {
+		orderItem.UnitPrice = price
+	}
+	return nil
+}
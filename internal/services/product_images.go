@@ -0,0 +1,394 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"github.com/Aebroyx/the-blade-api/internal/storage"
+	"gorm.io/gorm"
+)
+
+// Longest side of product image thumbnails, in pixels
+const productThumbnailSize = 320
+
+// Orphaned images removed per batch by PruneOrphans
+const productImagePruneBatch = 500
+
+// ProductImageService manages the image galleries of products. Files go to storage first and
+// rows are written after, so a failed upload never leaves rows pointing at missing files.
+// The files are charged to the storage usage of the tenant and store they were uploaded in.
+type ProductImageService struct {
+	db       *gorm.DB
+	storage  storage.Storage
+	products *ProductService
+	quota    *StorageQuotaService
+}
+
+func NewProductImageService(db *gorm.DB, storage storage.Storage, products *ProductService, quota *StorageQuotaService) *ProductImageService {
+	return &ProductImageService{
+		db:       db,
+		storage:  storage,
+		products: products,
+		quota:    quota,
+	}
+}
+
+// imageOwner returns who an image's files are charged to
+func imageOwner(image models.ProductImages) models.StorageOwner {
+	return models.StorageOwner{TenantID: image.TenantID, StoreID: image.StoreID}
+}
+
+// findGalleryProduct checks the product exists and isn't deleted
+func (s *ProductImageService) findGalleryProduct(productID uint) error {
+	if err := s.db.Select("id").Where("id = ?", productID).First(&models.Products{}).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("product not found")
+		}
+		return err
+	}
+	return nil
+}
+
+// productImages returns a product's gallery in order
+func productImages(db *gorm.DB, productID uint) ([]models.ProductImages, error) {
+	images := []models.ProductImages{}
+	if err := db.Where("product_id = ?", productID).Order("position, id").Find(&images).Error; err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// syncPrimaryImage copies the URLs of a product's primary image onto the product, clearing them
+// when the gallery is empty
+func syncPrimaryImage(tx *gorm.DB, productID uint) error {
+	var primary models.ProductImages
+	err := tx.Where("product_id = ? AND is_primary = ?", productID, true).First(&primary).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return tx.Model(&models.Products{}).Where("id = ?", productID).Updates(map[string]any{
+		"image_url":     primary.URL,
+		"thumbnail_url": primary.ThumbnailURL,
+	}).Error
+}
+
+// GetProductImages retrieves a product's gallery in order
+func (s *ProductImageService) GetProductImages(productID uint) ([]models.ProductImages, error) {
+	if err := s.findGalleryProduct(productID); err != nil {
+		return nil, err
+	}
+	return productImages(s.db, productID)
+}
+
+// storedImage is an upload written to storage but not yet recorded
+type storedImage struct {
+	image models.ProductImages
+	keys  []string
+}
+
+// storeImage writes an upload and its thumbnail to storage
+func (s *ProductImageService) storeImage(ctx context.Context, productID uint, owner models.StorageOwner, upload models.ProductImageUpload) (*storedImage, error) {
+	thumbnail, thumbnailType, info, err := storage.Thumbnail(upload.Data, productThumbnailSize)
+	if err != nil {
+		if errors.Is(err, storage.ErrUnsupportedImage) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("creating thumbnail of %s: %w", upload.Filename, err)
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("products/%d/%s", productID, hex.EncodeToString(buf))
+	key := name + info.Extension()
+	thumbnailKey := name + "_thumb.jpg"
+	if thumbnailType == "image/png" {
+		thumbnailKey = name + "_thumb.png"
+	}
+
+	if err := s.storage.Put(ctx, key, upload.Data, info.ContentType()); err != nil {
+		return nil, err
+	}
+	if err := s.storage.Put(ctx, thumbnailKey, thumbnail, thumbnailType); err != nil {
+		s.deleteFiles(ctx, key)
+		return nil, err
+	}
+
+	return &storedImage{
+		image: models.ProductImages{
+			ProductID:     productID,
+			Key:           key,
+			ThumbnailKey:  thumbnailKey,
+			URL:           s.storage.URL(key),
+			ThumbnailURL:  s.storage.URL(thumbnailKey),
+			Filename:      upload.Filename,
+			ContentType:   info.ContentType(),
+			Size:          int64(len(upload.Data)),
+			ThumbnailSize: int64(len(thumbnail)),
+			Width:         info.Width,
+			Height:        info.Height,
+			TenantID:      owner.TenantID,
+			StoreID:       owner.StoreID,
+		},
+		keys: []string{key, thumbnailKey},
+	}, nil
+}
+
+// deleteFiles removes files from storage, logging failures; the files are left behind unused
+func (s *ProductImageService) deleteFiles(ctx context.Context, keys ...string) {
+	if err := s.storage.Delete(ctx, keys...); err != nil {
+		slog.WarnContext(ctx, "Failed to delete stored files", "keys", keys, "error", err)
+	}
+}
+
+// UploadProductImages adds images to the end of a product's gallery, with a thumbnail of each.
+// The first image of an empty gallery becomes the primary image. It returns the whole gallery,
+// or a *StorageQuotaError when the images don't fit in the owner's storage quota.
+func (s *ProductImageService) UploadProductImages(ctx context.Context, productID uint, owner models.StorageOwner, uploads []models.ProductImageUpload) ([]models.ProductImages, error) {
+	if err := s.findGalleryProduct(productID); err != nil {
+		return nil, err
+	}
+
+	var existing int64
+	if err := s.db.Model(&models.ProductImages{}).Where("product_id = ?", productID).Count(&existing).Error; err != nil {
+		return nil, err
+	}
+	if err := limits.Check(limits.MaxProductImages, int(existing)+len(uploads)); err != nil {
+		return nil, err
+	}
+
+	// Thumbnails aren't made yet, so only the uploads themselves are checked up front
+	var uploadBytes int64
+	for _, upload := range uploads {
+		uploadBytes += int64(len(upload.Data))
+	}
+	if err := s.quota.CheckQuota(ctx, owner, uploadBytes); err != nil {
+		return nil, err
+	}
+
+	var stored []*storedImage
+	var keys []string
+	var storedBytes int64
+	for _, upload := range uploads {
+		image, err := s.storeImage(ctx, productID, owner, upload)
+		if err != nil {
+			s.deleteFiles(ctx, keys...)
+			return nil, err
+		}
+		stored = append(stored, image)
+		keys = append(keys, image.keys...)
+		storedBytes += image.image.Size + image.image.ThumbnailSize
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var last struct {
+			Position *int
+			Primary  bool
+		}
+		if err := tx.Model(&models.ProductImages{}).
+			Select("MAX(position) AS position, COALESCE(BOOL_OR(is_primary), false) AS \"primary\"").
+			Where("product_id = ?", productID).
+			Scan(&last).Error; err != nil {
+			return err
+		}
+
+		position := 0
+		if last.Position != nil {
+			position = *last.Position + 1
+		}
+		for i, image := range stored {
+			image.image.Position = position + i
+			image.image.IsPrimary = i == 0 && !last.Primary
+			if err := tx.Create(&image.image).Error; err != nil {
+				return err
+			}
+		}
+		if err := s.quota.chargeTx(tx, owner, storedBytes, int64(len(keys))); err != nil {
+			return err
+		}
+		return syncPrimaryImage(tx, productID)
+	})
+	if err != nil {
+		s.deleteFiles(ctx, keys...)
+		return nil, err
+	}
+
+	s.products.productChanged(productID, models.ChangeActionUpdated)
+	return productImages(s.db, productID)
+}
+
+// ReorderProductImages puts a product's gallery in the order given, which must list every image
+// of the product once
+func (s *ProductImageService) ReorderProductImages(productID uint, req *models.ReorderProductImagesRequest) ([]models.ProductImages, error) {
+	if err := s.findGalleryProduct(productID); err != nil {
+		return nil, err
+	}
+
+	images, err := productImages(s.db, productID)
+	if err != nil {
+		return nil, err
+	}
+	imageIDs := uniqueIDs(req.ImageIDs)
+	if len(imageIDs) != len(req.ImageIDs) || len(imageIDs) != len(images) {
+		return nil, errors.New("invalid image order")
+	}
+	known := make(map[uint]bool, len(images))
+	for _, image := range images {
+		known[image.ID] = true
+	}
+	for _, id := range imageIDs {
+		if !known[id] {
+			return nil, errors.New("invalid image order")
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for position, id := range imageIDs {
+			if err := tx.Model(&models.ProductImages{}).Where("id = ?", id).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return productImages(s.db, productID)
+}
+
+// findProductImage returns an image of a product's gallery
+func findProductImage(db *gorm.DB, productID uint, imageID uint) (*models.ProductImages, error) {
+	var image models.ProductImages
+	if err := db.Where("id = ? AND product_id = ?", imageID, productID).First(&image).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("image not found")
+		}
+		return nil, err
+	}
+	return &image, nil
+}
+
+// SetPrimaryProductImage makes an image the one shown for its product
+func (s *ProductImageService) SetPrimaryProductImage(productID uint, imageID uint) ([]models.ProductImages, error) {
+	if err := s.findGalleryProduct(productID); err != nil {
+		return nil, err
+	}
+	image, err := findProductImage(s.db, productID, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ProductImages{}).
+			Where("product_id = ? AND id <> ?", productID, image.ID).
+			Update("is_primary", false).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(image).Update("is_primary", true).Error; err != nil {
+			return err
+		}
+		return syncPrimaryImage(tx, productID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.products.productChanged(productID, models.ChangeActionUpdated)
+	return productImages(s.db, productID)
+}
+
+// DeleteProductImage removes an image from a product's gallery and from storage. Deleting the
+// primary image makes the next image of the gallery primary.
+func (s *ProductImageService) DeleteProductImage(ctx context.Context, productID uint, imageID uint) ([]models.ProductImages, error) {
+	if err := s.findGalleryProduct(productID); err != nil {
+		return nil, err
+	}
+	image, err := findProductImage(s.db, productID, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(image).Error; err != nil {
+			return err
+		}
+		if err := releaseStorageTx(tx, imageOwner(*image), image.Size+image.ThumbnailSize, 2); err != nil {
+			return err
+		}
+		if !image.IsPrimary {
+			return nil
+		}
+		var next models.ProductImages
+		err := tx.Where("product_id = ?", productID).Order("position, id").First(&next).Error
+		if err == nil {
+			if err := tx.Model(&next).Update("is_primary", true).Error; err != nil {
+				return err
+			}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		return syncPrimaryImage(tx, productID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.deleteFiles(ctx, image.Key, image.ThumbnailKey)
+	if image.IsPrimary {
+		s.products.productChanged(productID, models.ChangeActionUpdated)
+	}
+	return productImages(s.db, productID)
+}
+
+// PruneOrphans removes the images of deleted products from storage, then their rows. Images
+// whose files can't be deleted are kept so the next run retries them.
+func (s *ProductImageService) PruneOrphans(ctx context.Context) error {
+	var lastID uint
+	for {
+		var images []models.ProductImages
+		err := s.db.WithContext(ctx).
+			Where("id > ? AND product_id NOT IN (SELECT id FROM products WHERE deleted_at IS NULL)", lastID).
+			Order("id").
+			Limit(productImagePruneBatch).
+			Find(&images).Error
+		if err != nil {
+			return err
+		}
+		if len(images) == 0 {
+			return nil
+		}
+		lastID = images[len(images)-1].ID
+
+		var pruned []models.ProductImages
+		for _, image := range images {
+			if err := s.storage.Delete(ctx, image.Key, image.ThumbnailKey); err != nil {
+				slog.WarnContext(ctx, "Failed to delete files of orphaned product image", "product_image_id", image.ID, "error", err)
+				continue
+			}
+			pruned = append(pruned, image)
+		}
+		if len(pruned) > 0 {
+			err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				for _, image := range pruned {
+					if err := tx.Delete(&image).Error; err != nil {
+						return err
+					}
+					if err := releaseStorageTx(tx, imageOwner(image), image.Size+image.ThumbnailSize, 2); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+)
+
+type ProductService struct {
+	db      *gorm.DB
+	cache   *cache.Cache
+	changes *ChangeFeedService
+}
+
+func NewProductService(db *gorm.DB, cache *cache.Cache, changes *ChangeFeedService) *ProductService {
+	return &ProductService{
+		db:      db,
+		cache:   cache,
+		changes: changes,
+	}
+}
+
+// GetAllProducts retrieves products with pagination and search on name, SKU and barcode.
+// variants chooses whether each product carries its variants (nest) or every variant is listed
+// as a row of its own (flatten); empty leaves variants out.
+func (s *ProductService) GetAllProducts(params pagination.QueryParams, variants string) (*pagination.PaginatedResponse, error) {
+	config := productPaginationConfig()
+	switch variants {
+	case models.ProductVariantsNest:
+		config.Relations = []string{"Variants"}
+	case models.ProductVariantsFlatten:
+		config = flattenedProductsConfig(config)
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// ExportProducts streams every product matching the search and filters of the product listing,
+// ignoring page limits, along with the name of its category
+func (s *ProductService) ExportProducts(params pagination.QueryParams, fn func(product *models.Products, category string) error) error {
+	var categories []models.Categories
+	if err := s.db.Select("id", "name").Find(&categories).Error; err != nil {
+		return err
+	}
+	names := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Stream(params, productPaginationConfig(), func(record interface{}) error {
+		product := record.(*models.Products)
+		category := ""
+		if product.CategoryID != nil {
+			category = names[*product.CategoryID]
+		}
+		return fn(product, category)
+	})
+}
+
+// productPaginationConfig is the search, filters and sorting of the product listing
+func productPaginationConfig() pagination.PaginationConfig {
+	return pagination.PaginationConfig{
+		Model:        &models.Products{},
+		SearchFields: []string{"name", "sku", "barcode"},
+		FilterFields: map[string]string{
+			"status":       "status",
+			"sku":          "sku",
+			"barcode":      "barcode",
+			"tax_class_id": "tax_class_id",
+		},
+		CustomFilters: map[string]string{
+			// A category matches its own products and those of all its subcategories
+			"category_id": "category_id IN (" + categorySubtreeSQL + ")",
+			"supplier_id": "id IN (SELECT product_id FROM product_suppliers WHERE supplier_id = ?)",
+		},
+		DateFields: map[string]pagination.DateField{
+			"created_at": {
+				Start: "created_at",
+				End:   "created_at",
+			},
+			"updated_at": {
+				Start: "updated_at",
+				End:   "updated_at",
+			},
+		},
+		SortFields: []string{
+			"name",
+			"sku",
+			"price",
+			"cost",
+			"category_id",
+			"status",
+			"created_at",
+			"updated_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+}
+
+// flattenedProductsConfig turns the product listing into one row per variant. Variant fields
+// take precedence over the product's; products without variants are listed once.
+func flattenedProductsConfig(config pagination.PaginationConfig) pagination.PaginationConfig {
+	config.Joins = []pagination.JoinConfig{
+		{
+			Table:     "product_variants",
+			Alias:     "pv",
+			Type:      pagination.LeftJoin,
+			Condition: "pv.product_id = products.id AND pv.deleted_at IS NULL",
+		},
+	}
+	config.SelectFields = []pagination.SelectField{
+		{Field: "products.id", Alias: "product_id"},
+		{Field: "pv.id", Alias: "variant_id"},
+		{Field: "products.name", Alias: "name"},
+		{Field: "pv.title", Alias: "variant_title"},
+		{Field: "pv.options", Alias: "options"},
+		{Field: "COALESCE(pv.sku, products.sku)", Alias: "sku"},
+		{Field: "COALESCE(NULLIF(pv.barcode, ''), products.barcode)", Alias: "barcode"},
+		{Field: "COALESCE(pv.price, products.price)", Alias: "price"},
+		{Field: "COALESCE(pv.cost, products.cost)", Alias: "cost"},
+		{Field: "products.category_id", Alias: "category_id"},
+		{Field: "products.tax_class_id", Alias: "tax_class_id"},
+		{Field: "COALESCE(pv.status, products.status)", Alias: "status"},
+		{Field: "products.reorder_point", Alias: "reorder_point"},
+		{Field: "products.gift_card", Alias: "gift_card"},
+		{Field: "products.image_url", Alias: "image_url"},
+		{Field: "products.thumbnail_url", Alias: "thumbnail_url"},
+		{Field: "products.created_at", Alias: "created_at"},
+		{Field: "products.updated_at", Alias: "updated_at"},
+	}
+	config.ScanIntoMaps = true
+
+	// Columns present in both tables have to be qualified
+	config.SearchFields = []string{"products.name", "pv.title", "COALESCE(pv.sku, products.sku)", "COALESCE(NULLIF(pv.barcode, ''), products.barcode)"}
+	config.FilterFields = map[string]string{
+		"status":       "COALESCE(pv.status, products.status)",
+		"sku":          "COALESCE(pv.sku, products.sku)",
+		"barcode":      "COALESCE(NULLIF(pv.barcode, ''), products.barcode)",
+		"tax_class_id": "products.tax_class_id",
+	}
+	config.CustomFilters = map[string]string{
+		"category_id": "products.category_id IN (" + categorySubtreeSQL + ")",
+		"supplier_id": "products.id IN (SELECT product_id FROM product_suppliers WHERE supplier_id = ?)",
+	}
+	config.DateFields = map[string]pagination.DateField{
+		"created_at": {
+			Start: "products.created_at",
+			End:   "products.created_at",
+		},
+		"updated_at": {
+			Start: "products.updated_at",
+			End:   "products.updated_at",
+		},
+	}
+	return config
+}
+
+// GetProductById retrieves a product by ID with its image gallery
+func (s *ProductService) GetProductById(id uint) (*models.Products, error) {
+	var product models.Products
+	err := s.db.Preload("Images", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position, id")
+	}).Where("id = ?", id).First(&product).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+// skuTaken reports whether a product or variant other than the excluded ones uses the SKU
+func skuTaken(db *gorm.DB, sku string, excludeProductID uint, excludeVariantID uint) (bool, error) {
+	var count int64
+	if err := db.Model(&models.Products{}).Where("sku = ? AND id <> ?", sku, excludeProductID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+	if err := db.Model(&models.ProductVariants{}).Where("sku = ? AND id <> ?", sku, excludeVariantID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// checkSKU makes sure no other product or variant uses the SKU
+func (s *ProductService) checkSKU(sku string, excludeProductID uint, excludeVariantID uint) error {
+	taken, err := skuTaken(s.db, sku, excludeProductID, excludeVariantID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return errors.New("sku already exists")
+	}
+	return nil
+}
+
+// checkCategory makes sure the product's category exists. Products may be uncategorized.
+func (s *ProductService) checkCategory(categoryID *uint) error {
+	if categoryID == nil {
+		return nil
+	}
+	var count int64
+	if err := s.db.Model(&models.Categories{}).Where("id = ?", *categoryID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("category not found")
+	}
+	return nil
+}
+
+// checkTaxClass makes sure the product's tax class exists. Products without one are taxed at
+// the rate given with each line.
+func (s *ProductService) checkTaxClass(taxClassID *uint) error {
+	if taxClassID == nil {
+		return nil
+	}
+	var count int64
+	if err := s.db.Model(&models.TaxClasses{}).Where("id = ?", *taxClassID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("tax class not found")
+	}
+	return nil
+}
+
+// stockUnit returns the unit of measure a product is stocked in: the one given, or else the
+// unit it already has, each for new products. The product's unit can't change once stock has
+// moved, since its ledger and stock levels are counted in it.
+func (s *ProductService) stockUnit(code string, product *models.Products) (string, error) {
+	if code == "" && product != nil {
+		code = product.Unit
+	}
+	if code == "" {
+		code = models.UnitEach
+	}
+	if _, err := findUnit(s.db, code); err != nil {
+		return "", err
+	}
+	if product == nil || product.Unit == code {
+		return code, nil
+	}
+	var count int64
+	if err := s.db.Model(&models.StockMovements{}).Where("product_id = ?", product.ID).Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "", errors.New("product unit in use")
+	}
+	return code, nil
+}
+
+// CreateProduct creates a new product
+func (s *ProductService) CreateProduct(req *models.CreateProductRequest) (*models.Products, error) {
+	if err := s.checkSKU(req.SKU, 0, 0); err != nil {
+		return nil, err
+	}
+	if err := s.checkCategory(req.CategoryID); err != nil {
+		return nil, err
+	}
+	if err := s.checkTaxClass(req.TaxClassID); err != nil {
+		return nil, err
+	}
+	unit, err := s.stockUnit(req.Unit, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBundle(req.Bundle, req.Tracking, req.GiftCard); err != nil {
+		return nil, err
+	}
+
+	status := req.Status
+	if status == "" {
+		status = models.ProductStatusActive
+	}
+
+	product := models.Products{
+		Name:          req.Name,
+		SKU:           req.SKU,
+		Barcode:       req.Barcode,
+		Description:   req.Description,
+		Price:         req.Price,
+		Cost:          req.Cost,
+		CategoryID:    req.CategoryID,
+		TaxClassID:    req.TaxClassID,
+		Status:        status,
+		ReorderPoint:  req.ReorderPoint,
+		GiftCard:      req.GiftCard,
+		Tracking:      req.Tracking,
+		Unit:          unit,
+		Bundle:        req.Bundle,
+		BundlePricing: bundlePricing(req.Bundle, req.BundlePricing),
+	}
+
+	if err := s.db.Create(&product).Error; err != nil {
+		return nil, err
+	}
+
+	s.productChanged(product.ID, models.ChangeActionCreated)
+	return &product, nil
+}
+
+// UpdateProduct updates a product's details
+func (s *ProductService) UpdateProduct(id uint, req *models.UpdateProductRequest) (*models.Products, error) {
+	product, err := s.GetProductById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkSKU(req.SKU, product.ID, 0); err != nil {
+		return nil, err
+	}
+	if err := s.checkCategory(req.CategoryID); err != nil {
+		return nil, err
+	}
+	if err := s.checkTaxClass(req.TaxClassID); err != nil {
+		return nil, err
+	}
+	unit, err := s.stockUnit(req.Unit, product)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBundle(req.Bundle, req.Tracking, req.GiftCard); err != nil {
+		return nil, err
+	}
+
+	previousBarcode := product.Barcode
+	product.Name = req.Name
+	product.SKU = req.SKU
+	product.Barcode = req.Barcode
+	product.Description = req.Description
+	product.Price = req.Price
+	product.Cost = req.Cost
+	product.CategoryID = req.CategoryID
+	product.TaxClassID = req.TaxClassID
+	product.Status = req.Status
+	product.ReorderPoint = req.ReorderPoint
+	product.GiftCard = req.GiftCard
+	product.Tracking = req.Tracking
+	product.Unit = unit
+	product.Bundle = req.Bundle
+	product.BundlePricing = bundlePricing(req.Bundle, req.BundlePricing)
+
+	// The gallery is managed through the image routes
+	if err := s.db.Omit("Images").Save(product).Error; err != nil {
+		return nil, err
+	}
+
+	s.productChanged(product.ID, models.ChangeActionUpdated, previousBarcode)
+	return product, nil
+}
+
+// DeleteProduct soft deletes a product and its variants so past sales keep their reference to them.
+// The product's images are removed from storage by ProductImageService.PruneOrphans.
+func (s *ProductService) DeleteProduct(id uint) (*models.Products, error) {
+	product, err := s.GetProductById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductVariants{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(product).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.productChanged(product.ID, models.ChangeActionDeleted)
+	return product, nil
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"gorm.io/gorm"
+)
+
+// GetProductVariants returns a product's option set and variants
+func (s *ProductService) GetProductVariants(id uint) (*models.ProductVariantsResponse, error) {
+	product, err := s.GetProductById(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.productVariants(product.ID)
+}
+
+// productVariants loads the option set and variants of a product
+func (s *ProductService) productVariants(productID uint) (*models.ProductVariantsResponse, error) {
+	response := &models.ProductVariantsResponse{
+		Options:  []models.ProductOptions{},
+		Variants: []models.ProductVariants{},
+	}
+	if err := s.db.Where("product_id = ?", productID).Order("position ASC").Find(&response.Options).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Where("product_id = ?", productID).Order("id ASC").Find(&response.Variants).Error; err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// SetProductOptions replaces a product's option set. Variants are left alone until they are
+// generated again.
+func (s *ProductService) SetProductOptions(id uint, req *models.SetProductOptionsRequest) (*models.ProductVariantsResponse, error) {
+	product, err := s.GetProductById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	options := make([]models.ProductOptions, 0, len(req.Options))
+	names := map[string]bool{}
+	combinations := 1
+	for i, option := range req.Options {
+		name := strings.TrimSpace(option.Name)
+		if names[strings.ToLower(name)] {
+			return nil, errors.New("duplicate option name")
+		}
+		names[strings.ToLower(name)] = true
+
+		values := make(models.JSONStringList, 0, len(option.Values))
+		seen := map[string]bool{}
+		for _, value := range option.Values {
+			value = strings.TrimSpace(value)
+			if seen[strings.ToLower(value)] {
+				return nil, errors.New("duplicate option value")
+			}
+			seen[strings.ToLower(value)] = true
+			values = append(values, value)
+		}
+
+		combinations *= len(values)
+		options = append(options, models.ProductOptions{
+			ProductID: product.ID,
+			Name:      name,
+			Values:    values,
+			Position:  i,
+		})
+	}
+	if err := limits.Check(limits.MaxProductVariants, combinations); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductOptions{}).Error; err != nil {
+			return err
+		}
+		if len(options) == 0 {
+			return nil
+		}
+		return tx.Create(&options).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.productChanged(product.ID, models.ChangeActionUpdated)
+	return s.productVariants(product.ID)
+}
+
+// GenerateProductVariants creates a variant for every combination of option values that has
+// none yet. New variants start with the product's price and cost and a SKU derived from the
+// product's. Variants whose combination left the option set are made inactive rather than
+// deleted, so past sales keep their reference.
+func (s *ProductService) GenerateProductVariants(id uint) (*models.GenerateProductVariantsResponse, error) {
+	product, err := s.GetProductById(id)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.productVariants(product.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(current.Options) == 0 {
+		return nil, errors.New("product has no options")
+	}
+
+	existing := make(map[string]*models.ProductVariants, len(current.Variants))
+	for i := range current.Variants {
+		existing[combinationKey(current.Options, current.Variants[i].Options)] = &current.Variants[i]
+	}
+
+	response := &models.GenerateProductVariantsResponse{
+		Created:     []models.ProductVariants{},
+		Deactivated: []models.ProductVariants{},
+	}
+	matched := map[uint]bool{}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for _, combination := range optionCombinations(current.Options) {
+			if variant, ok := existing[combinationKey(current.Options, combination)]; ok {
+				matched[variant.ID] = true
+				continue
+			}
+
+			sku, err := s.variantSKU(tx, product.SKU, current.Options, combination)
+			if err != nil {
+				return err
+			}
+			variant := models.ProductVariants{
+				ProductID: product.ID,
+				Title:     variantTitle(current.Options, combination),
+				Options:   combination,
+				SKU:       sku,
+				Price:     product.Price,
+				Cost:      product.Cost,
+				Status:    models.ProductStatusActive,
+			}
+			if err := tx.Create(&variant).Error; err != nil {
+				return err
+			}
+			response.Created = append(response.Created, variant)
+		}
+
+		for i := range current.Variants {
+			variant := &current.Variants[i]
+			if matched[variant.ID] || variant.Status != models.ProductStatusActive {
+				continue
+			}
+			if err := tx.Model(variant).Update("status", models.ProductStatusInactive).Error; err != nil {
+				return err
+			}
+			response.Deactivated = append(response.Deactivated, *variant)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := s.productVariants(product.ID)
+	if err != nil {
+		return nil, err
+	}
+	response.Variants = variants.Variants
+
+	s.productChanged(product.ID, models.ChangeActionUpdated)
+	return response, nil
+}
+
+// getProductVariant retrieves a variant of a product
+func (s *ProductService) getProductVariant(productID uint, variantID uint) (*models.ProductVariants, error) {
+	var variant models.ProductVariants
+	if err := s.db.Where("id = ? AND product_id = ?", variantID, productID).First(&variant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("variant not found")
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// UpdateProductVariant updates a variant's SKU, barcode, price, cost and status
+func (s *ProductService) UpdateProductVariant(productID uint, variantID uint, req *models.UpdateProductVariantRequest) (*models.ProductVariants, error) {
+	variant, err := s.getProductVariant(productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkSKU(req.SKU, 0, variant.ID); err != nil {
+		return nil, err
+	}
+
+	previousBarcode := variant.Barcode
+	variant.SKU = req.SKU
+	variant.Barcode = req.Barcode
+	variant.Price = req.Price
+	variant.Cost = req.Cost
+	variant.Status = req.Status
+
+	if err := s.db.Save(variant).Error; err != nil {
+		return nil, err
+	}
+
+	s.productChanged(variant.ProductID, models.ChangeActionUpdated, previousBarcode)
+	return variant, nil
+}
+
+// DeleteProductVariant soft deletes a variant
+func (s *ProductService) DeleteProductVariant(productID uint, variantID uint) (*models.ProductVariants, error) {
+	variant, err := s.getProductVariant(productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Delete(variant).Error; err != nil {
+		return nil, err
+	}
+
+	s.productChanged(variant.ProductID, models.ChangeActionUpdated)
+	return variant, nil
+}
+
+// optionCombinations returns every combination of option values, varying the last option fastest
+func optionCombinations(options []models.ProductOptions) []models.JSONMap {
+	combinations := []models.JSONMap{{}}
+	for _, option := range options {
+		next := make([]models.JSONMap, 0, len(combinations)*len(option.Values))
+		for _, combination := range combinations {
+			for _, value := range option.Values {
+				extended := make(models.JSONMap, len(combination)+1)
+				for name, v := range combination {
+					extended[name] = v
+				}
+				extended[option.Name] = value
+				next = append(next, extended)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// combinationKey identifies a combination of option values, ignoring case. Values of options that
+// are not in the option set are ignored, so a variant only matches a combination of the current set.
+func combinationKey(options []models.ProductOptions, combination models.JSONMap) string {
+	names := make([]string, 0, len(options))
+	for _, option := range options {
+		names = append(names, option.Name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = strings.ToLower(name) + "=" + strings.ToLower(fmt.Sprint(combination[name]))
+	}
+	return strings.Join(parts, "|")
+}
+
+// variantTitle joins a combination's values in option order, e.g. "Red / M"
+func variantTitle(options []models.ProductOptions, combination models.JSONMap) string {
+	parts := make([]string, len(options))
+	for i, option := range options {
+		parts[i] = fmt.Sprint(combination[option.Name])
+	}
+	return strings.Join(parts, " / ")
+}
+
+// variantSKU derives a free SKU for a combination from the product's SKU, e.g. TSHIRT-RED-M.
+// A numeric suffix is added when the SKU is already taken.
+func (s *ProductService) variantSKU(tx *gorm.DB, productSKU string, options []models.ProductOptions, combination models.JSONMap) (string, error) {
+	parts := []string{productSKU}
+	for _, option := range options {
+		parts = append(parts, skuPart(fmt.Sprint(combination[option.Name])))
+	}
+	base := strings.Join(parts, "-")
+
+	sku := base
+	for n := 2; ; n++ {
+		taken, err := skuTaken(tx, sku, 0, 0)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return sku, nil
+		}
+		sku = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// skuPart turns an option value into uppercase letters and digits for use in a SKU
+func skuPart(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	if b.Len() == 0 {
+		return "X"
+	}
+	return b.String()
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"slices"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// productCategoriesSQL selects the category of each product together with every category
+// above it, so category promotions cover subcategories
+const productCategoriesSQL = `WITH RECURSIVE ancestors AS (
+	SELECT p.id AS product_id, c.id, c.parent_id FROM products p JOIN categories c ON c.id = p.category_id AND c.deleted_at IS NULL WHERE p.id IN ?
+	UNION ALL
+	SELECT a.product_id, c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id WHERE c.deleted_at IS NULL
+) SELECT product_id, id AS category_id FROM ancestors`
+
+// promotionOutcome is what a set of promotions takes off each line
+type promotionOutcome struct {
+	discounts []int64
+	applied   []models.AppliedPromotion
+	total     int64
+}
+
+// ApplyPromotions adds the discounts of the promotions that apply at a time to priced lines,
+// on top of their own discounts, and recomputes the lines' tax and totals. Coupon-only
+// promotions apply when their ID is among couponPromotionIDs. The result only depends on the
+// lines and the promotions, so a cart and the order it is checked out as get the same
+// discounts.
+func (s *PromotionService) ApplyPromotions(db *gorm.DB, items []*models.OrderItems, couponPromotionIDs []uint, now time.Time) ([]models.AppliedPromotion, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	query := db.Where("active AND (starts_at IS NULL OR starts_at <= ?) AND (ends_at IS NULL OR ends_at > ?)", now, now)
+	if len(couponPromotionIDs) > 0 {
+		query = query.Where("(NOT coupon_only OR id IN ?)", couponPromotionIDs)
+	} else {
+		query = query.Where("NOT coupon_only")
+	}
+	var promotions []models.Promotions
+	if err := query.Order("priority DESC, id ASC").Find(&promotions).Error; err != nil {
+		return nil, err
+	}
+	if len(promotions) == 0 {
+		return nil, nil
+	}
+
+	categories := map[uint]map[uint]bool{}
+	if slices.ContainsFunc(promotions, func(p models.Promotions) bool { return p.Scope == models.PromotionScopeCategories }) {
+		productIDs := make([]uint, len(items))
+		for i, item := range items {
+			productIDs[i] = item.ProductID
+		}
+		var rows []struct {
+			ProductID  uint
+			CategoryID uint
+		}
+		if err := db.Raw(productCategoriesSQL, uniqueIDs(productIDs)).Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			if categories[row.ProductID] == nil {
+				categories[row.ProductID] = map[uint]bool{}
+			}
+			categories[row.ProductID][row.CategoryID] = true
+		}
+	}
+
+	// What each line costs before promotions
+	base := make([]int64, len(items))
+	for i, item := range items {
+		base[i] = item.Subtotal - item.Discount
+	}
+
+	// The stackable promotions combined compete with each exclusive promotion on its own;
+	// the first of equally good options wins
+	var stackable []models.Promotions
+	for _, promotion := range promotions {
+		if promotion.Stacking != models.PromotionStackingExclusive {
+			stackable = append(stackable, promotion)
+		}
+	}
+	best := evaluatePromotions(stackable, items, base, categories)
+	for _, promotion := range promotions {
+		if promotion.Stacking != models.PromotionStackingExclusive {
+			continue
+		}
+		if outcome := evaluatePromotions([]models.Promotions{promotion}, items, base, categories); outcome.total > best.total {
+			best = outcome
+		}
+	}
+	if best.total == 0 {
+		return nil, nil
+	}
+
+	for i, item := range items {
+		if best.discounts[i] == 0 {
+			continue
+		}
+		item.Discount += best.discounts[i]
+		applyLineTax(item)
+	}
+	return best.applied, nil
+}
+
+// promotionCovers reports whether a promotion covers a line
+func promotionCovers(promotion models.Promotions, item *models.OrderItems, categories map[uint]map[uint]bool) bool {
+	switch promotion.Scope {
+	case models.PromotionScopeOrder:
+		return true
+	case models.PromotionScopeProducts:
+		return slices.Contains(promotion.ProductIDs, item.ProductID)
+	case models.PromotionScopeCategories:
+		return slices.ContainsFunc(promotion.CategoryIDs, func(id uint) bool {
+			return categories[item.ProductID][id]
+		})
+	default:
+		return false
+	}
+}
+
+// evaluatePromotions applies promotions one after another, each to what the previous ones
+// left of the lines it covers. A promotion applies when the lines it covers add up to its
+// minimum purchase before any promotion.
+func evaluatePromotions(promotions []models.Promotions, items []*models.OrderItems, base []int64, categories map[uint]map[uint]bool) promotionOutcome {
+	outcome := promotionOutcome{discounts: make([]int64, len(items))}
+	remaining := slices.Clone(base)
+
+	for _, promotion := range promotions {
+		var covered []int
+		var purchase, left int64
+		for i, item := range items {
+			if promotionCovers(promotion, item, categories) {
+				covered = append(covered, i)
+				purchase += base[i]
+				left += remaining[i]
+			}
+		}
+		if len(covered) == 0 || purchase < promotion.MinPurchase || left == 0 {
+			continue
+		}
+
+		discounts := make([]int64, len(covered))
+		var amount int64
+		switch promotion.Type {
+		case models.PromotionTypePercentage:
+			for j, i := range covered {
+				discounts[j] = remaining[i] * promotion.Value / 10000
+				amount += discounts[j]
+			}
+		case models.PromotionTypeFixed:
+			amount = min(promotion.Value, left)
+			spreadDiscount(amount, covered, remaining, discounts)
+		}
+		if amount == 0 {
+			continue
+		}
+
+		for j, i := range covered {
+			remaining[i] -= discounts[j]
+			outcome.discounts[i] += discounts[j]
+		}
+		outcome.applied = append(outcome.applied, models.AppliedPromotion{
+			PromotionID: promotion.ID,
+			Name:        promotion.Name,
+			Amount:      amount,
+		})
+		outcome.total += amount
+	}
+	return outcome
+}
+
+// spreadDiscount splits an amount over the covered lines in proportion to what is left of
+// them, so each line's tax is charged on what it really costs. Cents lost to rounding go to
+// the first lines that still have room.
+func spreadDiscount(amount int64, covered []int, remaining []int64, discounts []int64) {
+	var left int64
+	for _, i := range covered {
+		left += remaining[i]
+	}
+
+	spread := int64(0)
+	for j, i := range covered {
+		discounts[j] = amount * remaining[i] / left
+		spread += discounts[j]
+	}
+	for j, i := range covered {
+		if spread == amount {
+			break
+		}
+		extra := min(amount-spread, remaining[i]-discounts[j])
+		discounts[j] += extra
+		spread += extra
+	}
+}
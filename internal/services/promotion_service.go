@@ -0,0 +1,230 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// PromotionService manages promotions and works out the discounts they give carts and orders
+type PromotionService struct {
+	db *gorm.DB
+}
+
+func NewPromotionService(db *gorm.DB) *PromotionService {
+	return &PromotionService{
+		db: db,
+	}
+}
+
+// promotionFields are the fields shared by promotion create and update requests
+type promotionFields struct {
+	name        string
+	description string
+	kind        string
+	value       int64
+	scope       string
+	productIDs  []uint
+	categoryIDs []uint
+	minPurchase int64
+	stacking    string
+	priority    int
+	couponOnly  bool
+	active      *bool
+	startsAt    *time.Time
+	endsAt      *time.Time
+}
+
+// checkPromotion validates what a promotion is set to and makes sure the products and
+// categories it covers exist
+func (s *PromotionService) checkPromotion(fields promotionFields) error {
+	if fields.kind == models.PromotionTypePercentage && fields.value > 10000 {
+		return errors.New("percentage exceeds 100%")
+	}
+	if fields.startsAt != nil && fields.endsAt != nil && !fields.endsAt.After(*fields.startsAt) {
+		return errors.New("promotion ends before it starts")
+	}
+
+	switch fields.scope {
+	case models.PromotionScopeProducts:
+		ids := uniqueIDs(fields.productIDs)
+		var count int64
+		if err := s.db.Model(&models.Products{}).Where("id IN ?", ids).Count(&count).Error; err != nil {
+			return err
+		}
+		if count != int64(len(ids)) {
+			return errors.New("product not found")
+		}
+	case models.PromotionScopeCategories:
+		ids := uniqueIDs(fields.categoryIDs)
+		var count int64
+		if err := s.db.Model(&models.Categories{}).Where("id IN ?", ids).Count(&count).Error; err != nil {
+			return err
+		}
+		if count != int64(len(ids)) {
+			return errors.New("category not found")
+		}
+	}
+	return nil
+}
+
+// applyPromotionFields copies validated fields onto a promotion. Only the IDs of its own scope
+// are kept, so a promotion never carries IDs it ignores.
+func applyPromotionFields(promotion *models.Promotions, fields promotionFields) {
+	promotion.Name = fields.name
+	promotion.Description = fields.description
+	promotion.Type = fields.kind
+	promotion.Value = fields.value
+	promotion.Scope = fields.scope
+	promotion.ProductIDs = models.JSONIDList{}
+	promotion.CategoryIDs = models.JSONIDList{}
+	switch fields.scope {
+	case models.PromotionScopeProducts:
+		promotion.ProductIDs = uniqueIDs(fields.productIDs)
+	case models.PromotionScopeCategories:
+		promotion.CategoryIDs = uniqueIDs(fields.categoryIDs)
+	}
+	promotion.MinPurchase = fields.minPurchase
+	promotion.Stacking = fields.stacking
+	if promotion.Stacking == "" {
+		promotion.Stacking = models.PromotionStackingStackable
+	}
+	promotion.Priority = fields.priority
+	promotion.CouponOnly = fields.couponOnly
+	promotion.Active = fields.active == nil || *fields.active
+	promotion.StartsAt = fields.startsAt
+	promotion.EndsAt = fields.endsAt
+}
+
+// GetAllPromotions retrieves promotions with pagination. filters[current]=true lists the
+// promotions that apply right now.
+func (s *PromotionService) GetAllPromotions(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.Promotions{},
+		SearchFields: []string{"name", "description"},
+		FilterFields: map[string]string{
+			"type":        "type",
+			"scope":       "scope",
+			"stacking":    "stacking",
+			"active":      "active",
+			"coupon_only": "coupon_only",
+		},
+		CustomFilters: map[string]string{
+			"current": "(active AND (starts_at IS NULL OR starts_at <= NOW()) AND (ends_at IS NULL OR ends_at > NOW())) = ?",
+		},
+		DateFields: map[string]pagination.DateField{
+			"starts_at": {
+				Start: "starts_at",
+				End:   "starts_at",
+			},
+			"ends_at": {
+				Start: "ends_at",
+				End:   "ends_at",
+			},
+		},
+		SortFields: []string{
+			"name",
+			"priority",
+			"starts_at",
+			"ends_at",
+			"created_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetPromotionById retrieves a promotion by ID
+func (s *PromotionService) GetPromotionById(id uint) (*models.Promotions, error) {
+	var promotion models.Promotions
+	if err := s.db.Where("id = ?", id).First(&promotion).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("promotion not found")
+		}
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// CreatePromotion creates a promotion; it is active unless the request says otherwise
+func (s *PromotionService) CreatePromotion(req *models.CreatePromotionRequest) (*models.Promotions, error) {
+	fields := promotionFields{
+		name:        req.Name,
+		description: req.Description,
+		kind:        req.Type,
+		value:       req.Value,
+		scope:       req.Scope,
+		productIDs:  req.ProductIDs,
+		categoryIDs: req.CategoryIDs,
+		minPurchase: req.MinPurchase,
+		stacking:    req.Stacking,
+		priority:    req.Priority,
+		couponOnly:  req.CouponOnly,
+		active:      req.Active,
+		startsAt:    req.StartsAt,
+		endsAt:      req.EndsAt,
+	}
+	if err := s.checkPromotion(fields); err != nil {
+		return nil, err
+	}
+
+	var promotion models.Promotions
+	applyPromotionFields(&promotion, fields)
+	if err := s.db.Create(&promotion).Error; err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// UpdatePromotion replaces a promotion's settings. Orders already placed keep the discounts
+// they were given.
+func (s *PromotionService) UpdatePromotion(id uint, req *models.UpdatePromotionRequest) (*models.Promotions, error) {
+	promotion, err := s.GetPromotionById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := promotionFields{
+		name:        req.Name,
+		description: req.Description,
+		kind:        req.Type,
+		value:       req.Value,
+		scope:       req.Scope,
+		productIDs:  req.ProductIDs,
+		categoryIDs: req.CategoryIDs,
+		minPurchase: req.MinPurchase,
+		stacking:    req.Stacking,
+		priority:    req.Priority,
+		couponOnly:  req.CouponOnly,
+		active:      req.Active,
+		startsAt:    req.StartsAt,
+		endsAt:      req.EndsAt,
+	}
+	if err := s.checkPromotion(fields); err != nil {
+		return nil, err
+	}
+
+	applyPromotionFields(promotion, fields)
+	if err := s.db.Save(promotion).Error; err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+// DeletePromotion soft-deletes a promotion, so it no longer applies
+func (s *PromotionService) DeletePromotion(id uint) (*models.Promotions, error) {
+	promotion, err := s.GetPromotionById(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Delete(promotion).Error; err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// quotaOverrideCacheTTL bounds how long per-user quota overrides are reused by the quota middleware
+const quotaOverrideCacheTTL = time.Minute
+
+// QuotaService enforces per-user API request quotas. Each role has a quota per window,
+// which can be overridden for single users; counters live in Redis.
+type QuotaService struct {
+	db         *gorm.DB
+	cache      *cache.Cache
+	roleLimits map[string]int
+	window     time.Duration
+
+	mu        sync.RWMutex
+	overrides map[uint]int
+	expiresAt time.Time
+}
+
+func NewQuotaService(db *gorm.DB, appCache *cache.Cache, roleLimits map[string]int, window time.Duration) *QuotaService {
+	return &QuotaService{
+		db:         db,
+		cache:      appCache,
+		roleLimits: roleLimits,
+		window:     window,
+	}
+}
+
+// loadOverrides returns the quota overrides keyed by user ID
+func (s *QuotaService) loadOverrides() (map[uint]int, error) {
+	s.mu.RLock()
+	overrides, expiresAt := s.overrides, s.expiresAt
+	s.mu.RUnlock()
+	if overrides != nil && time.Now().Before(expiresAt) {
+		return overrides, nil
+	}
+
+	var rows []models.UserQuotas
+	if err := s.db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	overrides = make(map[uint]int, len(rows))
+	for _, row := range rows {
+		overrides[row.UserID] = row.Limit
+	}
+
+	s.mu.Lock()
+	s.overrides = overrides
+	s.expiresAt = time.Now().Add(quotaOverrideCacheTTL)
+	s.mu.Unlock()
+
+	return overrides, nil
+}
+
+// invalidate drops the cached overrides after a change
+func (s *QuotaService) invalidate() {
+	s.mu.Lock()
+	s.overrides = nil
+	s.mu.Unlock()
+}
+
+// newStatus describes the user's quota in the current window, before usage is filled in
+func (s *QuotaService) newStatus(userID uint, role string) (*models.UserQuotaStatus, error) {
+	overrides, err := s.loadOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	windowStart := time.Now().Truncate(s.window)
+	status := &models.UserQuotaStatus{
+		UserID:   userID,
+		Role:     role,
+		Limit:    s.roleLimits[role],
+		Window:   s.window.String(),
+		ResetsAt: windowStart.Add(s.window),
+	}
+	if limit, ok := overrides[userID]; ok {
+		status.Limit = limit
+		status.Override = true
+	}
+	return status, nil
+}
+
+// setUsage fills in the used and remaining requests
+func setUsage(status *models.UserQuotaStatus, used int64) {
+	status.Used = used
+	if status.Limit > 0 && used < int64(status.Limit) {
+		status.Remaining = int64(status.Limit) - used
+	}
+}
+
+// Count adds one request to the user's counter and returns their quota status.
+// Users with an unlimited quota are not counted. Returns cache.ErrUnavailable while Redis is down.
+func (s *QuotaService) Count(ctx context.Context, userID uint, role string) (*models.UserQuotaStatus, error) {
+	status, err := s.newStatus(userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if status.Limit == 0 {
+		return status, nil
+	}
+
+	client, ok := s.cache.Client()
+	if !ok {
+		return nil, cache.ErrUnavailable
+	}
+
+	key := cache.UserQuotaKey(userID, status.ResetsAt.Add(-s.window).Unix())
+	pipe := client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, s.window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, cache.ErrUnavailable
+	}
+
+	setUsage(status, incr.Val())
+	return status, nil
+}
+
+// findQuotaUser loads the user a quota belongs to
+func (s *QuotaService) findQuotaUser(userID uint) (*models.Users, error) {
+	var user models.Users
+	if err := s.db.Select("id", "role").Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetQuota returns a user's quota and usage without counting a request
+func (s *QuotaService) GetQuota(ctx context.Context, userID uint) (*models.UserQuotaStatus, error) {
+	user, err := s.findQuotaUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := s.newStatus(user.ID, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	// Usage reads as 0 while Redis is down
+	data, err := s.cache.Get(ctx, cache.UserQuotaKey(user.ID, status.ResetsAt.Add(-s.window).Unix()))
+	var used int64
+	if err == nil {
+		used, _ = strconv.ParseInt(string(data), 10, 64)
+	}
+	setUsage(status, used)
+	return status, nil
+}
+
+// ResetQuota clears the user's usage in the current window
+func (s *QuotaService) ResetQuota(ctx context.Context, userID uint) (*models.UserQuotaStatus, error) {
+	status, err := s.GetQuota(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// While Redis is down the delete is queued and applied once it recovers
+	if err := s.cache.Delete(ctx, cache.UserQuotaKey(status.UserID, status.ResetsAt.Add(-s.window).Unix())); err != nil {
+		return nil, err
+	}
+
+	setUsage(status, 0)
+	return status, nil
+}
+
+// UpdateQuota sets or, with a nil limit, removes the user's quota override
+func (s *QuotaService) UpdateQuota(ctx context.Context, userID uint, req *models.UpdateUserQuotaRequest, actor models.ActivityActor) (*models.UserQuotaStatus, error) {
+	user, err := s.findQuotaUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Limit == nil {
+		if err := s.db.Delete(&models.UserQuotas{}, "user_id = ?", user.ID).Error; err != nil {
+			return nil, err
+		}
+	} else {
+		quota := models.UserQuotas{UserID: user.ID, Limit: *req.Limit, UpdatedByID: actorID(actor)}
+		err := s.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"max_requests", "updated_by_id", "updated_at"}),
+		}).Create(&quota).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.invalidate()
+
+	return s.GetQuota(ctx, userID)
+}
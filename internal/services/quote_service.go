@@ -0,0 +1,606 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/mailer"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/receipt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultQuoteValidDays is how long quotes saved without an expiry date are valid
+const defaultQuoteValidDays = 30
+
+// QuoteService drafts priced quotes for customers, emails them and converts accepted quotes
+// into orders at the quoted prices. Revising a quote after it was sent makes a new version, so
+// every version a customer received is kept.
+type QuoteService struct {
+	db            *gorm.DB
+	orders        *OrderService
+	receipts      *ReceiptService
+	notifications *NotificationService
+}
+
+func NewQuoteService(db *gorm.DB, orders *OrderService, receipts *ReceiptService, notifications *NotificationService) *QuoteService {
+	return &QuoteService{
+		db:            db,
+		orders:        orders,
+		receipts:      receipts,
+		notifications: notifications,
+	}
+}
+
+// quoteNumber derives a quote's number from its ID
+func quoteNumber(id uint) string {
+	return fmt.Sprintf("QUO-%06d", id)
+}
+
+// lockQuote loads a quote and locks it for update
+func lockQuote(tx *gorm.DB, id uint) (*models.Quotes, error) {
+	var quote models.Quotes
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&quote).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("quote not found")
+		}
+		return nil, err
+	}
+	return &quote, nil
+}
+
+// quoteItems loads the lines of a version of a quote
+func quoteItems(db *gorm.DB, quoteID uint, version int) ([]models.QuoteItems, error) {
+	items := []models.QuoteItems{}
+	if err := db.Where("quote_id = ? AND version = ?", quoteID, version).Order("id").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// quoteOrderItems turns the lines of a quote into order lines, for the tax helpers orders use
+func quoteOrderItems(items []models.QuoteItems) []models.OrderItems {
+	orderItems := make([]models.OrderItems, len(items))
+	for i, item := range items {
+		orderItems[i] = models.OrderItems{
+			Subtotal: item.Subtotal,
+			Discount: item.Discount,
+			Tax:      item.Tax,
+			Taxes:    item.Taxes,
+		}
+	}
+	return orderItems
+}
+
+// GetAllQuotes retrieves quotes with pagination, search on number and email, and filters on
+// status, customer, store and order
+func (s *QuoteService) GetAllQuotes(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.Quotes{},
+		SearchFields: []string{"number", "email"},
+		FilterFields: map[string]string{
+			"status":      "status",
+			"customer_id": "customer_id",
+			"store_id":    "store_id",
+			"order_id":    "order_id",
+		},
+		StoreFilter: "store_id = ?",
+		DateFields: map[string]pagination.DateField{
+			"created_at": {
+				Start: "created_at",
+				End:   "created_at",
+			},
+			"expires_at": {
+				Start: "expires_at",
+				End:   "expires_at",
+			},
+		},
+		SortFields: []string{
+			"number",
+			"total",
+			"expires_at",
+			"created_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetQuoteById retrieves a quote with the lines of its current version
+func (s *QuoteService) GetQuoteById(id uint) (*models.Quotes, error) {
+	var quote models.Quotes
+	if err := s.db.Where("id = ?", id).First(&quote).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("quote not found")
+		}
+		return nil, err
+	}
+	items, err := quoteItems(s.db, quote.ID, quote.Version)
+	if err != nil {
+		return nil, err
+	}
+	quote.Items = items
+	return &quote, nil
+}
+
+// GetQuoteVersions retrieves every version of a quote with its lines, oldest first
+func (s *QuoteService) GetQuoteVersions(id uint) ([]models.QuoteVersions, error) {
+	if _, err := s.GetQuoteById(id); err != nil {
+		return nil, err
+	}
+
+	versions := []models.QuoteVersions{}
+	if err := s.db.Where("quote_id = ?", id).Order("version").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	var items []models.QuoteItems
+	if err := s.db.Where("quote_id = ?", id).Order("id").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	for i := range versions {
+		versions[i].Items = []models.QuoteItems{}
+		for _, item := range items {
+			if item.Version == versions[i].Version {
+				versions[i].Items = append(versions[i].Items, item)
+			}
+		}
+	}
+	return versions, nil
+}
+
+// priceQuote prices the requested lines like an order's, with the promotions running now, and
+// sets the quote's details and totals from the request. The lines are returned for the caller
+// to save with the quote's version.
+func (s *QuoteService) priceQuote(tx *gorm.DB, quote *models.Quotes, req *models.QuoteRequest, now time.Time) ([]models.QuoteItems, error) {
+	quote.CustomerID = nil
+	quote.Email = strings.TrimSpace(req.Email)
+	if req.CustomerID != nil {
+		customer, err := findCustomer(tx, *req.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+		quote.CustomerID = &customer.ID
+		if quote.Email == "" {
+			quote.Email = customer.Email
+		}
+	}
+
+	switch {
+	case req.ExpiresAt != nil:
+		if !req.ExpiresAt.After(now) {
+			return nil, errors.New("expiry date in the past")
+		}
+		quote.ExpiresAt = *req.ExpiresAt
+	case req.ValidDays != nil:
+		quote.ExpiresAt = now.AddDate(0, 0, *req.ValidDays)
+	default:
+		quote.ExpiresAt = now.AddDate(0, 0, defaultQuoteValidDays)
+	}
+
+	orderItems, err := s.orders.buildOrderItems(tx, req.Items, req.Region)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.orders.discountItems(tx, orderItems, nil, nil); err != nil {
+		return nil, err
+	}
+
+	quote.TaxRegion = strings.TrimSpace(req.Region)
+	quote.Note = strings.TrimSpace(req.Note)
+	quote.Terms = strings.TrimSpace(req.Terms)
+	quote.ItemCount, quote.Subtotal, quote.DiscountTotal, quote.TaxTotal, quote.IncludedTax, quote.Total = 0, 0, 0, 0, 0, 0
+	items := make([]models.QuoteItems, len(orderItems))
+	for i, item := range orderItems {
+		items[i] = models.QuoteItems{
+			ProductID:    item.ProductID,
+			VariantID:    item.VariantID,
+			Name:         item.Name,
+			VariantTitle: item.VariantTitle,
+			SKU:          item.SKU,
+			Quantity:     item.Quantity,
+			UnitPrice:    item.UnitPrice,
+			Subtotal:     item.Subtotal,
+			Discount:     item.Discount,
+			TaxRate:      item.TaxRate,
+			Tax:          item.Tax,
+			Taxes:        item.Taxes,
+			Total:        item.Total,
+		}
+		quote.ItemCount += item.Quantity
+		quote.Subtotal += item.Subtotal
+		quote.DiscountTotal += item.Discount
+		quote.TaxTotal += item.Tax
+		quote.IncludedTax += includedTax(item)
+		quote.Total += item.Total
+	}
+	return items, nil
+}
+
+// saveVersion saves the lines of a quote's current version and records the version, replacing
+// a version that was never sent
+func saveVersion(tx *gorm.DB, quote *models.Quotes, items []models.QuoteItems, actor models.ActivityActor) error {
+	if err := tx.Where("quote_id = ? AND version = ?", quote.ID, quote.Version).Delete(&models.QuoteItems{}).Error; err != nil {
+		return err
+	}
+	for i := range items {
+		items[i].QuoteID = quote.ID
+		items[i].Version = quote.Version
+	}
+	if err := tx.Create(&items).Error; err != nil {
+		return err
+	}
+	quote.Items = items
+
+	version := models.QuoteVersions{
+		QuoteID:       quote.ID,
+		Version:       quote.Version,
+		Note:          quote.Note,
+		Terms:         quote.Terms,
+		ItemCount:     quote.ItemCount,
+		Subtotal:      quote.Subtotal,
+		DiscountTotal: quote.DiscountTotal,
+		TaxTotal:      quote.TaxTotal,
+		IncludedTax:   quote.IncludedTax,
+		Total:         quote.Total,
+		ExpiresAt:     quote.ExpiresAt,
+		CreatedByID:   actorID(actor),
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "quote_id"}, {Name: "version"}},
+		DoUpdates: clause.AssignmentColumns([]string{"note", "terms", "item_count", "subtotal", "discount_total", "tax_total", "included_tax", "total", "expires_at", "created_by_id"}),
+	}).Create(&version).Error
+}
+
+// CreateQuote drafts a quote, priced like an order of the same lines would be now
+func (s *QuoteService) CreateQuote(req *models.QuoteRequest, actor models.ActivityActor) (*models.Quotes, error) {
+	quote := models.Quotes{
+		Version:     1,
+		Status:      models.QuoteStatusDraft,
+		StoreID:     req.StoreID,
+		CreatedByID: actorID(actor),
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		items, err := s.priceQuote(tx, &quote, req, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(&quote).Error; err != nil {
+			return err
+		}
+		quote.Number = quoteNumber(quote.ID)
+		if err := tx.Model(&quote).UpdateColumn("number", quote.Number).Error; err != nil {
+			return err
+		}
+		return saveVersion(tx, &quote, items, actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+// UpdateQuote revises a draft or sent quote. A version the customer was sent is kept as it was
+// and the revision becomes a new version, taking the quote back to draft until it is sent.
+func (s *QuoteService) UpdateQuote(id uint, req *models.QuoteRequest, actor models.ActivityActor) (*models.Quotes, error) {
+	var quote *models.Quotes
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		quote, err = lockQuote(tx, id)
+		if err != nil {
+			return err
+		}
+		if quote.Status != models.QuoteStatusDraft && quote.Status != models.QuoteStatusSent {
+			return errors.New("quote closed")
+		}
+
+		var current models.QuoteVersions
+		if err := tx.Where("quote_id = ? AND version = ?", quote.ID, quote.Version).Limit(1).Find(&current).Error; err != nil {
+			return err
+		}
+		if current.SentAt != nil {
+			quote.Version++
+			quote.Status = models.QuoteStatusDraft
+			quote.StatusReason = ""
+		}
+
+		items, err := s.priceQuote(tx, quote, req, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := tx.Save(quote).Error; err != nil {
+			return err
+		}
+		return saveVersion(tx, quote, items, actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+// sellerParty returns the details of the store a quote or invoice is made out by
+func sellerParty(db *gorm.DB, storeID *uint) (receipt.Party, error) {
+	if storeID == nil {
+		return receipt.Party{}, nil
+	}
+	var store models.Stores
+	if err := db.Unscoped().Where("id = ?", *storeID).First(&store).Error; err != nil {
+		return receipt.Party{}, err
+	}
+	party := receipt.Party{
+		Name:    store.Name,
+		Address: store.Address,
+		TaxID:   store.TaxID,
+		Email:   store.Email,
+		Phone:   store.Phone,
+	}
+	if store.LegalName != "" {
+		party.Name = store.LegalName
+	}
+	return party, nil
+}
+
+// quoteDocument gathers what a quote shows with its current lines
+func (s *QuoteService) quoteDocument(db *gorm.DB, quote *models.Quotes) (*receipt.Quote, error) {
+	items, err := quoteItems(db, quote.ID, quote.Version)
+	if err != nil {
+		return nil, err
+	}
+	seller, err := sellerParty(db, quote.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &receipt.Quote{
+		Number:    quote.Number,
+		Version:   quote.Version,
+		Status:    quote.Status,
+		Date:      quote.UpdatedAt,
+		ExpiresAt: quote.ExpiresAt,
+		Seller:    seller,
+		Buyer:     receipt.Party{Name: quote.Email, Email: quote.Email},
+		Subtotal:  quote.Subtotal,
+		Discount:  quote.DiscountTotal,
+		Total:     quote.Total,
+		Note:      quote.Note,
+		Terms:     quote.Terms,
+	}
+	if quote.CustomerID != nil {
+		var customer models.Customers
+		if err := db.Unscoped().Where("id = ?", *quote.CustomerID).First(&customer).Error; err != nil {
+			return nil, err
+		}
+		q.Buyer = receipt.Party{
+			Name:    customer.Name,
+			Company: customer.Company,
+			Address: customer.BillingAddress,
+			TaxID:   customer.TaxID,
+			Email:   quote.Email,
+		}
+	}
+	for _, item := range items {
+		description := item.Name
+		if item.VariantTitle != "" {
+			description += " - " + item.VariantTitle
+		}
+		q.Lines = append(q.Lines, receipt.InvoiceLine{
+			Description: description,
+			SKU:         item.SKU,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Discount:    item.Discount,
+			TaxRate:     item.TaxRate,
+			Total:       item.Subtotal - item.Discount,
+		})
+	}
+	for _, tax := range taxBreakdown(quoteOrderItems(items)) {
+		if tax.Amount != 0 {
+			q.Taxes = append(q.Taxes, receipt.Tax{Name: tax.Name, Rate: tax.Rate, Inclusive: tax.Inclusive, Amount: tax.Amount})
+		}
+	}
+	return q, nil
+}
+
+// SendQuote emails a quote as plain text with the receipt template of a tenant and location,
+// and marks it and its version sent. Sent quotes can be sent again, e.g. to another address.
+// The quote stays locked while the email is sent, so it is only marked sent once delivered.
+func (s *QuoteService) SendQuote(ctx context.Context, id uint, req *models.SendQuoteRequest, tenantID uint) (*models.Quotes, error) {
+	template, err := s.receipts.Template(ctx, tenantID, req.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		quote, err := lockQuote(tx, id)
+		if err != nil {
+			return err
+		}
+		if quote.Status != models.QuoteStatusDraft && quote.Status != models.QuoteStatusSent {
+			return errors.New("quote closed")
+		}
+		now := time.Now()
+		if quote.ExpiresAt.Before(now) {
+			return errors.New("quote expired")
+		}
+		address := strings.TrimSpace(req.Email)
+		if address == "" {
+			address = quote.Email
+		}
+		if address == "" {
+			return errors.New("quote has no email")
+		}
+
+		q, err := s.quoteDocument(tx, quote)
+		if err != nil {
+			return err
+		}
+		q.Status = models.QuoteStatusSent
+		subject := fmt.Sprintf("Your quote %s", quote.Number)
+		if q.Seller.Name != "" {
+			subject = fmt.Sprintf("Your quote from %s (%s)", q.Seller.Name, quote.Number)
+		}
+		if err := s.notifications.SendExternalEmail(models.NotificationEventQuote, mailer.Message{
+			To:      address,
+			Subject: subject,
+			Body:    string(receipt.Text(receipt.LayoutQuote(*q, template))),
+		}); err != nil {
+			return err
+		}
+
+		if err := tx.Model(quote).Updates(map[string]interface{}{
+			"status":  models.QuoteStatusSent,
+			"email":   address,
+			"sent_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.QuoteVersions{}).Where("quote_id = ? AND version = ?", quote.ID, quote.Version).Update("sent_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetQuoteById(id)
+}
+
+// AcceptQuote records that the customer accepted a sent quote that hasn't expired
+func (s *QuoteService) AcceptQuote(id uint, req *models.QuoteStatusRequest) (*models.Quotes, error) {
+	return s.answerQuote(id, models.QuoteStatusAccepted, "accepted_at", req.Reason)
+}
+
+// RejectQuote records that the customer rejected a sent quote
+func (s *QuoteService) RejectQuote(id uint, req *models.QuoteStatusRequest) (*models.Quotes, error) {
+	return s.answerQuote(id, models.QuoteStatusRejected, "rejected_at", req.Reason)
+}
+
+// answerQuote moves a sent quote to the customer's answer and records when it was given
+func (s *QuoteService) answerQuote(id uint, status string, column string, reason string) (*models.Quotes, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		quote, err := lockQuote(tx, id)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(models.QuoteStatusTransitions[quote.Status], status) {
+			return errors.New("invalid status transition")
+		}
+		now := time.Now()
+		if status == models.QuoteStatusAccepted && quote.ExpiresAt.Before(now) {
+			return errors.New("quote expired")
+		}
+
+		return tx.Model(quote).Updates(map[string]interface{}{
+			"status":        status,
+			"status_reason": strings.TrimSpace(reason),
+			column:          now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetQuoteById(id)
+}
+
+// ConvertQuote turns an accepted quote into an order for its customer that charges the quoted
+// prices and discounts, whatever the catalog and promotions say now. Taxes are charged at the
+// current rates. An order placed from a terminal needs its open shift, like any other.
+func (s *QuoteService) ConvertQuote(id uint, req *models.ConvertQuoteRequest, deviceID *uint, actor models.ActivityActor) (*models.Orders, error) {
+	var order *models.Orders
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		quote, err := lockQuote(tx, id)
+		if err != nil {
+			return err
+		}
+		if quote.OrderID != nil {
+			return errors.New("quote already converted")
+		}
+		if quote.Status != models.QuoteStatusAccepted {
+			return errors.New("quote not accepted")
+		}
+		items, err := quoteItems(tx, quote.ID, quote.Version)
+		if err != nil {
+			return err
+		}
+
+		orderReq := models.CreateOrderRequest{
+			Note:         "Quote " + quote.Number,
+			Region:       quote.TaxRegion,
+			CustomerID:   quote.CustomerID,
+			Draft:        req.Draft,
+			DeviceID:     deviceID,
+			StoreID:      quote.StoreID,
+			QuotedPrices: true,
+		}
+		for _, item := range items {
+			unitPrice := item.UnitPrice
+			orderReq.Items = append(orderReq.Items, models.OrderItemRequest{
+				ProductID: item.ProductID,
+				VariantID: item.VariantID,
+				Quantity:  item.Quantity,
+				UnitPrice: &unitPrice,
+				Discount:  item.Discount,
+				TaxRate:   item.TaxRate,
+			})
+		}
+		order, err = s.orders.CreateOrderTx(tx, &orderReq, nil, actor)
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(quote).Updates(map[string]interface{}{
+			"order_id":     order.ID,
+			"converted_at": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.orders.OrderCreated(order, actor)
+	return order, nil
+}
+
+// RenderQuote renders a quote as a PDF with the currency of the receipt template of a tenant
+// and location, returning it with the quote number
+func (s *QuoteService) RenderQuote(ctx context.Context, id uint, tenantID uint, location string) ([]byte, string, error) {
+	quote, err := s.GetQuoteById(id)
+	if err != nil {
+		return nil, "", err
+	}
+	template, err := s.receipts.Template(ctx, tenantID, location)
+	if err != nil {
+		return nil, "", err
+	}
+	q, err := s.quoteDocument(s.db, quote)
+	if err != nil {
+		return nil, "", err
+	}
+	return receipt.RenderQuote(*q, template), quote.Number, nil
+}
+
+// ExpireQuotes marks sent quotes past their expiry date expired. It is the entry point of the
+// background quote expiry job.
+func (s *QuoteService) ExpireQuotes(ctx context.Context) error {
+	now := time.Now()
+	result := s.db.WithContext(ctx).Model(&models.Quotes{}).
+		Where("status = ? AND expires_at < ?", models.QuoteStatusSent, now).
+		Updates(map[string]interface{}{
+			"status":     models.QuoteStatusExpired,
+			"expired_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		slog.InfoContext(ctx, "Expired quotes", "quotes", result.RowsAffected)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/mailer"
+	"github.com/Aebroyx/the-blade-api/internal/receipt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmailReceipt queues an order's receipt to be emailed to a customer, by default the order's
+// own. It is sent by the receipt emails job, which records whether it was delivered.
+func (s *ReceiptService) EmailReceipt(orderID uint, req *models.EmailReceiptRequest, tenantID uint, actor models.ActivityActor) (*models.ReceiptEmails, error) {
+	// Fails early for orders that have no receipt
+	if _, err := s.GetReceipt(orderID); err != nil {
+		return nil, err
+	}
+
+	address := req.Email
+	if address == "" {
+		var order models.Orders
+		if err := s.db.Select("id", "customer_id").Where("id = ?", orderID).First(&order).Error; err != nil {
+			return nil, err
+		}
+		if order.CustomerID != nil {
+			customer, err := findCustomer(s.db, *order.CustomerID)
+			if err != nil && err.Error() != "customer not found" {
+				return nil, err
+			}
+			if customer != nil {
+				address = customer.Email
+			}
+		}
+		if address == "" {
+			return nil, errors.New("order has no customer email")
+		}
+	}
+
+	email := models.ReceiptEmails{
+		OrderID:       orderID,
+		Email:         address,
+		TenantID:      tenantID,
+		Location:      req.Location,
+		Status:        models.ReceiptEmailStatusPending,
+		RequestedByID: actorID(actor),
+	}
+	if err := s.db.Create(&email).Error; err != nil {
+		return nil, err
+	}
+	return &email, nil
+}
+
+// GetReceiptEmails retrieves the receipt emails of an order with their delivery status,
+// oldest first
+func (s *ReceiptService) GetReceiptEmails(orderID uint) ([]models.ReceiptEmails, error) {
+	var order models.Orders
+	if err := s.db.Select("id").Where("id = ?", orderID).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("order not found")
+		}
+		return nil, err
+	}
+
+	emails := []models.ReceiptEmails{}
+	if err := s.db.Where("order_id = ?", order.ID).Order("id").Find(&emails).Error; err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
+
+// SendReceiptEmails sends the queued receipt emails, oldest first. It is the entry point of the
+// background receipt emails job; emails are claimed with a row lock so several API instances
+// can run it at once.
+func (s *ReceiptService) SendReceiptEmails(ctx context.Context) error {
+	for ctx.Err() == nil {
+		sent, err := s.sendReceiptEmail(ctx)
+		if err != nil {
+			return err
+		}
+		if !sent {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// sendReceiptEmail sends the oldest due receipt email. A failed email is retried with a
+// growing delay until it fails for good. Returns false when no email is due.
+func (s *ReceiptService) sendReceiptEmail(ctx context.Context) (bool, error) {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		var email models.ReceiptEmails
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND (retry_at IS NULL OR retry_at <= ?)", models.ReceiptEmailStatusPending, now).
+			Order("id ASC").
+			First(&email).Error
+		if err != nil {
+			return err
+		}
+
+		attempts := email.Attempts + 1
+		if sendErr := s.deliverReceiptEmail(ctx, &email); sendErr != nil {
+			slog.ErrorContext(ctx, "Receipt email failed", "receipt_email_id", email.ID, "order_id", email.OrderID, "attempt", attempts, "error", sendErr)
+
+			columns := map[string]interface{}{
+				"attempts":   attempts,
+				"last_error": truncateReason(sendErr.Error()),
+				"retry_at":   now.Add(time.Duration(attempts) * time.Minute),
+			}
+			if attempts >= models.ReceiptEmailMaxAttempts {
+				columns["status"] = models.ReceiptEmailStatusFailed
+			}
+			return tx.Model(&email).Updates(columns).Error
+		}
+
+		return tx.Model(&email).Updates(map[string]interface{}{
+			"status":     models.ReceiptEmailStatusSent,
+			"attempts":   attempts,
+			"last_error": "",
+			"sent_at":    now,
+		}).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// deliverReceiptEmail renders the receipt as it stands now and emails it as plain text, which
+// every mail client shows the way it prints
+func (s *ReceiptService) deliverReceiptEmail(ctx context.Context, email *models.ReceiptEmails) error {
+	r, err := s.GetReceipt(email.OrderID)
+	if err != nil {
+		return err
+	}
+	template, err := s.Template(ctx, email.TenantID, email.Location)
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("Your receipt %s", r.Number)
+	if len(template.Header) > 0 {
+		subject = fmt.Sprintf("Your receipt from %s (%s)", template.Header[0], r.Number)
+	}
+	return s.notificationService.SendExternalEmail(models.NotificationEventReceipt, mailer.Message{
+		To:      email.Email,
+		Subject: subject,
+		Body:    string(receipt.Text(receipt.Layout(*r, template))),
+	})
+}
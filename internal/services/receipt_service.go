@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/receipt"
+	"gorm.io/gorm"
+)
+
+// ReceiptService renders the receipts of orders and emails them to customers. How receipts
+// look is set by the receipt.template business setting, so each tenant and location can print
+// its own header and footer.
+type ReceiptService struct {
+	db                  *gorm.DB
+	settingService      *SettingService
+	notificationService *NotificationService
+}
+
+func NewReceiptService(db *gorm.DB, settingService *SettingService, notificationService *NotificationService) *ReceiptService {
+	return &ReceiptService{
+		db:                  db,
+		settingService:      settingService,
+		notificationService: notificationService,
+	}
+}
+
+// receiptPaymentStatuses are the statuses of payments that took money and appear on receipts
+var receiptPaymentStatuses = []string{
+	models.PaymentStatusCaptured,
+	models.PaymentStatusRefunded,
+}
+
+// Template resolves the receipt template for a tenant and location, falling back to the
+// default template where none is configured
+func (s *ReceiptService) Template(ctx context.Context, tenantID uint, location string) (receipt.Template, error) {
+	setting, err := s.settingService.Effective(ctx, receipt.TemplateSettingKey, tenantID, location)
+	if err != nil {
+		if err.Error() == "setting not found" {
+			return receipt.DefaultTemplate(), nil
+		}
+		return receipt.Template{}, err
+	}
+	return receipt.ParseTemplate(setting.Value.Data)
+}
+
+// GetReceipt gathers what an order's receipt shows. Draft orders haven't been sold yet and
+// have no receipt.
+func (s *ReceiptService) GetReceipt(orderID uint) (*receipt.Receipt, error) {
+	var order models.Orders
+	if err := s.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Taxes", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("id = ?", orderID).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("order not found")
+		}
+		return nil, err
+	}
+	if order.Status == models.OrderStatusDraft {
+		return nil, errors.New("order has no receipt")
+	}
+
+	r := &receipt.Receipt{
+		Number:   order.Number,
+		Status:   order.Status,
+		Date:     order.CreatedAt,
+		Subtotal: order.Subtotal,
+		Discount: order.DiscountTotal,
+		Total:    order.Total,
+	}
+	if order.PlacedAt != nil {
+		r.Date = *order.PlacedAt
+	}
+	if order.CreatedByID != nil {
+		var cashier models.Users
+		if err := s.db.Select("id", "name").Where("id = ?", *order.CreatedByID).Limit(1).Find(&cashier).Error; err != nil {
+			return nil, err
+		}
+		r.Cashier = cashier.Name
+	}
+
+	// Orders placed before taxes were broken down list their tax once per rate
+	taxes := map[int64]int64{}
+	for _, item := range order.Items {
+		name := item.Name
+		if item.VariantTitle != "" {
+			name += " - " + item.VariantTitle
+		}
+		r.Items = append(r.Items, receipt.Item{
+			Name:      name,
+			SKU:       item.SKU,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			Discount:  item.Discount,
+		})
+		if item.Tax != 0 {
+			taxes[item.TaxRate] += item.Tax
+		}
+	}
+	for _, tax := range order.Taxes {
+		if tax.Amount != 0 {
+			r.Taxes = append(r.Taxes, receipt.Tax{Name: tax.Name, Rate: tax.Rate, Inclusive: tax.Inclusive, Amount: tax.Amount})
+		}
+	}
+	if len(order.Taxes) == 0 {
+		for _, rate := range slices.Sorted(maps.Keys(taxes)) {
+			r.Taxes = append(r.Taxes, receipt.Tax{Rate: rate, Amount: taxes[rate]})
+		}
+	}
+
+	orderPayments, err := orderPayments(s.db, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, payment := range orderPayments {
+		if !slices.Contains(receiptPaymentStatuses, payment.Status) {
+			continue
+		}
+		r.Payments = append(r.Payments, receipt.Payment{
+			Method:   payment.Method,
+			Amount:   payment.Amount,
+			Tendered: payment.Tendered,
+			Change:   payment.Change,
+			Refunded: payment.RefundedAmount,
+		})
+	}
+	return r, nil
+}
+
+// RenderReceipt renders an order's receipt in a format with the template of a tenant and
+// location, returning it with the order number
+func (s *ReceiptService) RenderReceipt(ctx context.Context, orderID uint, format receipt.Format, tenantID uint, location string) ([]byte, string, error) {
+	r, err := s.GetReceipt(orderID)
+	if err != nil {
+		return nil, "", err
+	}
+	template, err := s.Template(ctx, tenantID, location)
+	if err != nil {
+		return nil, "", err
+	}
+	content, err := receipt.Render(*r, template, format)
+	if err != nil {
+		return nil, "", err
+	}
+	return content, r.Number, nil
+}
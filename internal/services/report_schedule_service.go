@@ -0,0 +1,623 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/export"
+	"github.com/Aebroyx/the-blade-api/internal/mailer"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/receipt"
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// reportWebhookTimeout is how long a webhook receiver may take to accept a report
+const reportWebhookTimeout = 30 * time.Second
+
+// reportWebhookLookupTimeout is how long checking a webhook URL may wait for its host to resolve
+const reportWebhookLookupTimeout = 5 * time.Second
+
+// maxReportRunErrorLength is the size of the error column of report runs
+const maxReportRunErrorLength = 500
+
+// ReportSignatureHeader carries the signature of webhook deliveries: t=<unix time>,v1=<hex
+// HMAC-SHA256 of "<unix time>.<body>" keyed with the schedule's webhook secret>
+const ReportSignatureHeader = "X-Report-Signature"
+
+// ReportScheduleService runs report definitions on cron schedules and delivers the rows by email
+// or webhook as CSV or PDF files. Every run is recorded, and administrators are alerted when a
+// run fails.
+type ReportScheduleService struct {
+	db            *gorm.DB
+	reports       *ReportService
+	notifications *NotificationService
+	http          *http.Client
+}
+
+func NewReportScheduleService(db *gorm.DB, reports *ReportService, notifications *NotificationService) *ReportScheduleService {
+	return &ReportScheduleService{
+		db:            db,
+		reports:       reports,
+		notifications: notifications,
+		http:          &http.Client{Timeout: reportWebhookTimeout, Transport: reportWebhookTransport()},
+	}
+}
+
+// blockedWebhookIP reports whether a report webhook may not reach an address: loopback,
+// link-local, private and unspecified addresses belong to the API's own network
+func blockedWebhookIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsPrivate() || ip.IsUnspecified()
+}
+
+// reportWebhookTransport dials public addresses only, checked on the address actually dialed so
+// a host resolving differently after the schedule was saved, or a redirect, can't reach the
+// API's network. Proxies are not used, since they would be dialed instead of the webhook.
+func reportWebhookTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: reportWebhookTimeout,
+		Control: func(network string, address string, conn syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || blockedWebhookIP(ip) {
+				return errors.New("webhook address not allowed")
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// checkWebhookURL makes sure a webhook URL is an HTTP(S) URL whose host only resolves to public
+// addresses
+func checkWebhookURL(ctx context.Context, webhookURL string) error {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return errors.New("invalid webhook url")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reportWebhookLookupTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, parsed.Hostname())
+	if err != nil {
+		return errors.New("invalid webhook url")
+	}
+	for _, addr := range addrs {
+		if blockedWebhookIP(addr.IP) {
+			return errors.New("webhook address not allowed")
+		}
+	}
+	return nil
+}
+
+// reportSchedule parses a cron expression, with the five standard fields or a descriptor such as
+// @daily, and the time zone it is read in
+func reportSchedule(expression string, timezone string) (cron.Schedule, *time.Location, error) {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, nil, errors.New("unknown time zone")
+	}
+	if strings.HasPrefix(expression, "TZ=") || strings.HasPrefix(expression, "CRON_TZ=") {
+		return nil, nil, errors.New("invalid cron expression")
+	}
+	schedule, err := cron.ParseStandard(expression)
+	if err != nil {
+		return nil, nil, errors.New("invalid cron expression")
+	}
+	return schedule, location, nil
+}
+
+// nextRun returns when a schedule is next due after the given time
+func nextRun(schedule *models.ReportSchedules, after time.Time) (*time.Time, error) {
+	parsed, location, err := reportSchedule(schedule.Cron, schedule.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	next := parsed.Next(after.In(location))
+	if next.IsZero() {
+		return nil, errors.New("invalid cron expression")
+	}
+	return &next, nil
+}
+
+// GetAllReportSchedules retrieves report schedules with pagination, search and filters
+func (s *ReportScheduleService) GetAllReportSchedules(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.ReportSchedules{},
+		SearchFields: []string{"name"},
+		FilterFields: map[string]string{
+			"report_id":   "report_id",
+			"delivery":    "delivery",
+			"format":      "format",
+			"is_active":   "is_active",
+			"last_status": "last_status",
+		},
+		SortFields: []string{
+			"name",
+			"next_run_at",
+			"last_run_at",
+			"created_at",
+		},
+		DefaultSort:  "name",
+		DefaultOrder: "ASC",
+		Relations:    []string{"Report"},
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetReportScheduleById retrieves a report schedule with its report definition
+func (s *ReportScheduleService) GetReportScheduleById(id uint) (*models.ReportSchedules, error) {
+	var schedule models.ReportSchedules
+	if err := s.db.Preload("Report").Where("id = ?", id).First(&schedule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("report schedule not found")
+		}
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// applyScheduleRequest copies a request onto a schedule and works out when it is next due
+func (s *ReportScheduleService) applyScheduleRequest(ctx context.Context, schedule *models.ReportSchedules, req *models.ReportScheduleRequest) error {
+	if _, err := s.reports.GetReportDefinitionById(req.ReportID); err != nil {
+		return err
+	}
+	if req.Delivery == models.ReportDeliveryWebhook {
+		if err := checkWebhookURL(ctx, req.WebhookURL); err != nil {
+			return err
+		}
+	}
+
+	schedule.ReportID = req.ReportID
+	schedule.Name = req.Name
+	schedule.Cron = strings.TrimSpace(req.Cron)
+	schedule.Timezone = req.Timezone
+	if schedule.Timezone == "" {
+		schedule.Timezone = "UTC"
+	}
+	schedule.Period = req.Period
+	schedule.Format = req.Format
+	schedule.Delivery = req.Delivery
+	schedule.Recipients = models.JSONStringList{}
+	schedule.WebhookURL = ""
+	if req.Delivery == models.ReportDeliveryEmail {
+		schedule.Recipients = req.Recipients
+	} else {
+		schedule.WebhookURL = req.WebhookURL
+		// An update without a secret keeps the one already set
+		if req.WebhookSecret != "" {
+			schedule.WebhookSecret = req.WebhookSecret
+		}
+	}
+	if req.IsActive != nil {
+		schedule.IsActive = *req.IsActive
+	}
+
+	schedule.NextRunAt = nil
+	if schedule.IsActive {
+		next, err := nextRun(schedule, time.Now())
+		if err != nil {
+			return err
+		}
+		schedule.NextRunAt = next
+	}
+	return nil
+}
+
+// CreateReportSchedule schedules a report definition
+func (s *ReportScheduleService) CreateReportSchedule(ctx context.Context, req *models.ReportScheduleRequest, actor models.ActivityActor) (*models.ReportSchedules, error) {
+	schedule := models.ReportSchedules{IsActive: true, CreatedByID: actorID(actor)}
+	if err := s.applyScheduleRequest(ctx, &schedule, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&schedule).Error; err != nil {
+		return nil, err
+	}
+	return s.GetReportScheduleById(schedule.ID)
+}
+
+// UpdateReportSchedule replaces a report schedule; it is next due by its new cron expression
+func (s *ReportScheduleService) UpdateReportSchedule(ctx context.Context, id uint, req *models.ReportScheduleRequest) (*models.ReportSchedules, error) {
+	schedule, err := s.GetReportScheduleById(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyScheduleRequest(ctx, schedule, req); err != nil {
+		return nil, err
+	}
+	schedule.Report = nil
+	if err := s.db.Save(schedule).Error; err != nil {
+		return nil, err
+	}
+	return s.GetReportScheduleById(schedule.ID)
+}
+
+// DeleteReportSchedule deletes a report schedule; its run history is kept
+func (s *ReportScheduleService) DeleteReportSchedule(id uint) (*models.ReportSchedules, error) {
+	schedule, err := s.GetReportScheduleById(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Delete(schedule).Error; err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// GetReportScheduleRuns retrieves the run history of a schedule, newest first
+func (s *ReportScheduleService) GetReportScheduleRuns(id uint, params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	if err := s.db.Unscoped().Select("id").Where("id = ?", id).First(&models.ReportSchedules{}).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("report schedule not found")
+		}
+		return nil, err
+	}
+
+	config := pagination.PaginationConfig{
+		Model:         &models.ReportScheduleRuns{},
+		BaseCondition: map[string]interface{}{"schedule_id": id},
+		FilterFields: map[string]string{
+			"status":  "status",
+			"trigger": "trigger",
+		},
+		DateFields: map[string]pagination.DateField{
+			"scheduled_for": {Start: "scheduled_for", End: "scheduled_for"},
+		},
+		SortFields:   []string{"scheduled_for", "started_at"},
+		DefaultSort:  "started_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// RunReportSchedule runs a schedule now, outside its cron expression, and returns the run. A
+// failed run is returned like a successful one, with its error.
+func (s *ReportScheduleService) RunReportSchedule(ctx context.Context, id uint, actor models.ActivityActor) (*models.ReportScheduleRuns, error) {
+	schedule, err := s.GetReportScheduleById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	run := s.run(ctx, schedule, models.ReportRunTriggerManual, time.Now())
+	run.TriggeredByID = actorID(actor)
+	if err := s.finishRun(ctx, schedule, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// RunDueSchedules runs the schedules that are due, oldest first. It is the entry point of the
+// background report schedules job; schedules are claimed with a row lock so several API
+// instances can run it at once.
+func (s *ReportScheduleService) RunDueSchedules(ctx context.Context) error {
+	for ctx.Err() == nil {
+		ran, err := s.runDueSchedule(ctx)
+		if err != nil {
+			return err
+		}
+		if !ran {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// runDueSchedule runs the schedule due the longest. Runs missed while the API was down are not
+// caught up on: the schedule runs once and is next due by its cron expression from now.
+// Returns false when no schedule is due.
+func (s *ReportScheduleService) runDueSchedule(ctx context.Context) (bool, error) {
+	schedule, scheduledFor, err := s.claimDueSchedule(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	run := s.run(ctx, schedule, models.ReportRunTriggerSchedule, scheduledFor)
+	if err := s.finishRun(ctx, schedule, run); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// claimDueSchedule locks the schedule due the longest and moves it on to the next time it is
+// due, returning the time it was due at. The report is built and delivered after the claim is
+// committed, so no lock is held meanwhile and a failure to record the run can't send it twice.
+// Returns gorm.ErrRecordNotFound when no schedule is due.
+func (s *ReportScheduleService) claimDueSchedule(ctx context.Context) (*models.ReportSchedules, time.Time, error) {
+	var schedule models.ReportSchedules
+	var scheduledFor time.Time
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("is_active = ? AND next_run_at <= ?", true, time.Now()).
+			Order("next_run_at ASC").
+			First(&schedule).Error
+		if err != nil {
+			return err
+		}
+		scheduledFor = *schedule.NextRunAt
+
+		columns := map[string]interface{}{}
+		next, err := nextRun(&schedule, time.Now())
+		if err != nil {
+			// The expression was valid when saved; stop the schedule rather than retry it forever
+			columns["is_active"] = false
+			columns["next_run_at"] = nil
+		} else {
+			columns["next_run_at"] = next
+		}
+		if err := tx.Model(&schedule).Updates(columns).Error; err != nil {
+			return err
+		}
+
+		var definition models.ReportDefinitions
+		if err := tx.Unscoped().Where("id = ?", schedule.ReportID).First(&definition).Error; err != nil {
+			return err
+		}
+		schedule.Report = &definition
+		return nil
+	})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return &schedule, scheduledFor, nil
+}
+
+// finishRun records a run on its schedule and alerts administrators when the run failed
+func (s *ReportScheduleService) finishRun(ctx context.Context, schedule *models.ReportSchedules, run *models.ReportScheduleRuns) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(run).Error; err != nil {
+			return err
+		}
+
+		columns := map[string]interface{}{
+			"last_run_at": run.StartedAt,
+			"last_status": run.Status,
+		}
+		if run.Status == models.ReportRunStatusFailed {
+			columns["consecutive_failures"] = gorm.Expr("consecutive_failures + 1")
+		} else {
+			columns["consecutive_failures"] = 0
+		}
+		return tx.Model(&models.ReportSchedules{}).Where("id = ?", schedule.ID).Updates(columns).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if run.Status == models.ReportRunStatusFailed {
+		s.alertFailure(ctx, schedule, run)
+	}
+	return nil
+}
+
+// run builds the schedule's report file and delivers it. Failures are recorded on the run
+// rather than returned.
+func (s *ReportScheduleService) run(ctx context.Context, schedule *models.ReportSchedules, trigger string, scheduledFor time.Time) *models.ReportScheduleRuns {
+	run := &models.ReportScheduleRuns{
+		ScheduleID:   schedule.ID,
+		ReportID:     schedule.ReportID,
+		Trigger:      trigger,
+		Format:       schedule.Format,
+		Delivery:     schedule.Delivery,
+		ScheduledFor: scheduledFor,
+		StartedAt:    time.Now(),
+	}
+
+	var dates *pagination.DateRange
+	if start, ok := periodStart(schedule.Period, scheduledFor); ok {
+		end := scheduledFor
+		dates = &pagination.DateRange{Start: &start, End: &end}
+		run.PeriodStart, run.PeriodEnd = &start, &end
+	}
+
+	file, rows, err := s.buildReport(ctx, schedule, dates)
+	if err == nil {
+		run.Rows, run.Bytes = rows, len(file)
+		err = s.deliver(ctx, schedule, run, file)
+	}
+
+	run.FinishedAt = time.Now()
+	run.Status = models.ReportRunStatusSucceeded
+	if err != nil {
+		slog.ErrorContext(ctx, "Scheduled report failed", "report_schedule_id", schedule.ID, "report_id", schedule.ReportID, "error", err)
+		run.Status = models.ReportRunStatusFailed
+		run.Error = truncateRunError(err.Error())
+	}
+	return run
+}
+
+// truncateRunError cuts a run's error to fit its column
+func truncateRunError(message string) string {
+	if len(message) > maxReportRunErrorLength {
+		return message[:maxReportRunErrorLength]
+	}
+	return message
+}
+
+// periodStart returns where the period a run covers starts, if the schedule has one
+func periodStart(period string, end time.Time) (time.Time, bool) {
+	switch period {
+	case models.ReportPeriodDay:
+		return end.AddDate(0, 0, -1), true
+	case models.ReportPeriodWeek:
+		return end.AddDate(0, 0, -7), true
+	case models.ReportPeriodMonth:
+		return end.AddDate(0, -1, 0), true
+	}
+	return time.Time{}, false
+}
+
+// buildReport runs the schedule's report and renders its rows in the schedule's format
+func (s *ReportScheduleService) buildReport(ctx context.Context, schedule *models.ReportSchedules, dates *pagination.DateRange) ([]byte, int, error) {
+	definition := schedule.Report
+	if definition == nil {
+		return nil, 0, errors.New("report not found")
+	}
+	columns := ReportColumns(definition)
+
+	var rows [][]string
+	err := s.reports.StreamReport(ctx, definition, dates, func(values []string) error {
+		rows = append(rows, values)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if schedule.Format == models.ReportFormatPDF {
+		subtitle := "All rows"
+		if dates != nil {
+			subtitle = fmt.Sprintf("%s to %s", dates.Start.Format(time.RFC3339), dates.End.Format(time.RFC3339))
+		}
+		return receipt.RenderTable(receipt.Table{
+			Title:    definition.Name,
+			Subtitle: subtitle,
+			Header:   columns,
+			Rows:     rows,
+		}), len(rows), nil
+	}
+
+	var buf bytes.Buffer
+	writer := export.NewCSVWriter(&buf)
+	if err := writer.WriteRow(columns); err != nil {
+		return nil, 0, err
+	}
+	for _, row := range rows {
+		if err := writer.WriteRow(row); err != nil {
+			return nil, 0, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), len(rows), nil
+}
+
+// reportFile names a report file and gives its content type
+func reportFile(schedule *models.ReportSchedules, run *models.ReportScheduleRuns) (string, string) {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, schedule.Report.Name)
+	filename := fmt.Sprintf("%s-%s.%s", name, run.ScheduledFor.UTC().Format("20060102-1504"), schedule.Format)
+	if schedule.Format == models.ReportFormatPDF {
+		return filename, "application/pdf"
+	}
+	return filename, export.FormatCSV.ContentType()
+}
+
+// deliver sends a report file to the schedule's recipients or webhook
+func (s *ReportScheduleService) deliver(ctx context.Context, schedule *models.ReportSchedules, run *models.ReportScheduleRuns, file []byte) error {
+	filename, contentType := reportFile(schedule, run)
+
+	if schedule.Delivery == models.ReportDeliveryWebhook {
+		return s.deliverWebhook(ctx, schedule, filename, contentType, file)
+	}
+
+	var errs []error
+	for _, recipient := range schedule.Recipients {
+		err := s.notifications.SendExternalEmail(models.NotificationEventScheduledReport, mailer.Message{
+			To:      recipient,
+			Subject: fmt.Sprintf("Report: %s", schedule.Report.Name),
+			Body: fmt.Sprintf("The %s report scheduled as %q is attached, with %d rows.\n",
+				schedule.Report.Name, schedule.Name, run.Rows),
+			Attachments: []mailer.Attachment{{Filename: filename, ContentType: contentType, Data: file}},
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", recipient, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliverWebhook POSTs a report file to the schedule's webhook, signed with its secret if it has one
+func (s *ReportScheduleService) deliverWebhook(ctx context.Context, schedule *models.ReportSchedules, filename string, contentType string, file []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, schedule.WebhookURL, bytes.NewReader(file))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	req.Header.Set("X-Report-Schedule-ID", strconv.FormatUint(uint64(schedule.ID), 10))
+	if schedule.WebhookSecret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(schedule.WebhookSecret))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(file)
+		req.Header.Set(ReportSignatureHeader, fmt.Sprintf("t=%s,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil))))
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// alertFailure tells administrators a scheduled report failed
+func (s *ReportScheduleService) alertFailure(ctx context.Context, schedule *models.ReportSchedules, run *models.ReportScheduleRuns) {
+	var admins []models.Users
+	if err := s.db.WithContext(ctx).Where("role = ? AND is_deleted = ? AND status = ?", models.RoleAdmin, false, models.UserStatusActive).
+		Order("id ASC").
+		Find(&admins).Error; err != nil {
+		slog.ErrorContext(ctx, "Failed to find administrators to alert about a failed report", "report_schedule_id", schedule.ID, "error", err)
+		return
+	}
+
+	title := fmt.Sprintf("Scheduled report %q failed", schedule.Name)
+	body := fmt.Sprintf("The %s run due %s failed: %s", schedule.Delivery, run.ScheduledFor.Format(time.RFC3339), run.Error)
+	if schedule.Report != nil {
+		body = fmt.Sprintf("The %s report could not be delivered by %s for the run due %s: %s",
+			schedule.Report.Name, schedule.Delivery, run.ScheduledFor.Format(time.RFC3339), run.Error)
+	}
+	data := models.JSONMap{"report_schedule_id": schedule.ID, "report_id": schedule.ReportID, "run_id": run.ID}
+	for _, admin := range admins {
+		if err := s.notifications.SendInApp(admin.ID, models.NotificationEventReportFailures, title, body, data); err != nil {
+			slog.ErrorContext(ctx, "Failed to add report failure notification", "user_id", admin.ID, "error", err)
+		}
+
+		message := mailer.Message{
+			To:      admin.Email,
+			Subject: title,
+			Body:    fmt.Sprintf("Hi %s,\n\n%s", admin.Name, body),
+		}
+		if err := s.notifications.SendEmail(admin.ID, models.NotificationEventReportFailures, message); err != nil {
+			slog.ErrorContext(ctx, "Failed to send report failure email", "user_id", admin.ID, "error", err)
+		}
+	}
+}
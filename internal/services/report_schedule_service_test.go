@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckWebhookURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr string
+	}{
+		{url: "https://203.0.113.10/reports"},
+		{url: "http://[2001:db8::1]:8080/reports"},
+		{url: "ftp://203.0.113.10/reports", wantErr: "invalid webhook url"},
+		{url: "https:///reports", wantErr: "invalid webhook url"},
+		{url: "http://127.0.0.1/reports", wantErr: "webhook address not allowed"},
+		{url: "http://[::1]/reports", wantErr: "webhook address not allowed"},
+		{url: "http://10.0.0.5/reports", wantErr: "webhook address not allowed"},
+		{url: "http://192.168.1.20/reports", wantErr: "webhook address not allowed"},
+		{url: "http://172.16.0.1/reports", wantErr: "webhook address not allowed"},
+		{url: "http://169.254.169.254/latest/meta-data", wantErr: "webhook address not allowed"},
+		{url: "http://[fe80::1]/reports", wantErr: "webhook address not allowed"},
+		{url: "http://0.0.0.0/reports", wantErr: "webhook address not allowed"},
+	}
+	for _, tt := range tests {
+		err := checkWebhookURL(context.Background(), tt.url)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s was rejected: %v", tt.url, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%s gave %v, want %s", tt.url, err, tt.wantErr)
+		}
+	}
+}
+
+func TestReportWebhookTransportRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// A host that resolved to a public address when the schedule was saved may resolve to a private one by delivery time
+	client := &http.Client{Transport: reportWebhookTransport()}
+	resp, err := client.Post(server.URL, "text/csv", strings.NewReader("id\n1\n"))
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("webhook on a loopback address was delivered")
+	}
+	if !strings.Contains(err.Error(), "webhook address not allowed") {
+		t.Errorf("delivery failed for another reason: %v", err)
+	}
+}
@@ -0,0 +1,760 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// reportEntity whitelists what a report definition may reference for one entity.
+// Dimensions and measures map the names used in definitions to SQL expressions,
+// so definitions never carry raw SQL.
+type reportEntity struct {
+	model         interface{}
+	baseCondition map[string]interface{}
+	dimensions    map[string]string
+	measures      map[string]string
+	filters       map[string]string
+	dateColumn    string
+}
+
+// reportEntities is the registry of entities available to the report builder
+var reportEntities = map[string]reportEntity{
+	"users": {
+		model: &models.Users{},
+		dimensions: map[string]string{
+			"role":          "role",
+			"status":        "status",
+			"is_deleted":    "is_deleted",
+			"created_day":   "date_trunc('day', created_at)",
+			"created_week":  "date_trunc('week', created_at)",
+			"created_month": "date_trunc('month', created_at)",
+		},
+		measures: map[string]string{
+			"count": "COUNT(*)",
+		},
+		filters: map[string]string{
+			"role":       "role",
+			"status":     "status",
+			"is_deleted": "is_deleted",
+		},
+		dateColumn: "created_at",
+	},
+	"user_activities": {
+		model: &models.UserActivities{},
+		dimensions: map[string]string{
+			"event_type": "event_type",
+			"user_id":    "user_id",
+			"day":        "date_trunc('day', created_at)",
+			"week":       "date_trunc('week', created_at)",
+			"month":      "date_trunc('month', created_at)",
+		},
+		measures: map[string]string{
+			"count":          "COUNT(*)",
+			"distinct_users": "COUNT(DISTINCT user_id)",
+		},
+		filters: map[string]string{
+			"event_type": "event_type",
+			"user_id":    "user_id",
+		},
+		dateColumn: "created_at",
+	},
+	"print_jobs": {
+		model: &models.PrintJobs{},
+		dimensions: map[string]string{
+			"device_id": "device_id",
+			"status":    "status",
+			"type":      "type",
+			"day":       "date_trunc('day', created_at)",
+		},
+		measures: map[string]string{
+			"count":        "COUNT(*)",
+			"total_copies": "SUM(copies)",
+			"avg_attempts": "AVG(attempts)",
+		},
+		filters: map[string]string{
+			"device_id": "device_id",
+			"status":    "status",
+			"type":      "type",
+		},
+		dateColumn: "created_at",
+	},
+	"products": {
+		model: &models.Products{},
+		dimensions: map[string]string{
+			"category_id":   "category_id",
+			"status":        "status",
+			"created_month": "date_trunc('month', created_at)",
+		},
+		measures: map[string]string{
+			"count":     "COUNT(*)",
+			"avg_price": "AVG(price)",
+			"avg_cost":  "AVG(cost)",
+		},
+		filters: map[string]string{
+			"category_id": "category_id",
+			"status":      "status",
+		},
+		dateColumn: "created_at",
+	},
+	"stock_movements": {
+		model: &models.StockMovements{},
+		dimensions: map[string]string{
+			"product_id": "product_id",
+			"type":       "type",
+			"reason":     "reason",
+			"day":        "date_trunc('day', created_at)",
+		},
+		measures: map[string]string{
+			"count":          "COUNT(*)",
+			"total_quantity": "SUM(quantity)",
+		},
+		filters: map[string]string{
+			"product_id": "product_id",
+			"store_id":   "store_id",
+			"type":       "type",
+			"reason":     "reason",
+		},
+		dateColumn: "created_at",
+	},
+	"orders": {
+		model: &models.Orders{},
+		dimensions: map[string]string{
+			"status":        "status",
+			"created_by_id": "created_by_id",
+			"tax_region":    "tax_region",
+			"customer_id":   "customer_id",
+			"store_id":      "store_id",
+			"day":           "date_trunc('day', created_at)",
+			"week":          "date_trunc('week', created_at)",
+			"month":         "date_trunc('month', created_at)",
+		},
+		measures: map[string]string{
+			"count":          "COUNT(*)",
+			"total_sales":    "SUM(total)",
+			"total_discount": "SUM(discount_total)",
+			"total_tax":      "SUM(tax_total)",
+			"included_tax":   "SUM(included_tax)",
+			"avg_order":      "AVG(total)",
+			"items_sold":     "SUM(item_count)",
+		},
+		filters: map[string]string{
+			"status":        "status",
+			"created_by_id": "created_by_id",
+			"customer_id":   "customer_id",
+			"store_id":      "store_id",
+		},
+		dateColumn: "created_at",
+	},
+	"order_items": {
+		model: &models.OrderItems{},
+		dimensions: map[string]string{
+			"product_id": "product_id",
+			"variant_id": "variant_id",
+			"day":        "date_trunc('day', created_at)",
+			"month":      "date_trunc('month', created_at)",
+		},
+		measures: map[string]string{
+			"count":          "COUNT(*)",
+			"quantity_sold":  "SUM(quantity)",
+			"revenue":        "SUM(total)",
+			"gross_margin":   "SUM(subtotal - discount - quantity * unit_cost)",
+			"total_discount": "SUM(discount)",
+		},
+		filters: map[string]string{
+			"product_id": "product_id",
+			"variant_id": "variant_id",
+		},
+		dateColumn: "created_at",
+	},
+	"order_item_components": {
+		model: &models.OrderItemComponents{},
+		dimensions: map[string]string{
+			"product_id": "product_id",
+			"variant_id": "variant_id",
+			"day":        "date_trunc('day', created_at)",
+			"month":      "date_trunc('month', created_at)",
+		},
+		measures: map[string]string{
+			"count":         "COUNT(*)",
+			"quantity_sold": "SUM(quantity)",
+			"cost":          "SUM(quantity * unit_cost)",
+		},
+		filters: map[string]string{
+			"product_id": "product_id",
+			"variant_id": "variant_id",
+		},
+		dateColumn: "created_at",
+	},
+	"product_variants": {
+		model: &models.ProductVariants{},
+		dimensions: map[string]string{
+			"product_id": "product_id",
+			"status":     "status",
+		},
+		measures: map[string]string{
+			"count":     "COUNT(*)",
+			"avg_price": "AVG(price)",
+			"avg_cost":  "AVG(cost)",
+		},
+		filters: map[string]string{
+			"product_id": "product_id",
+			"status":     "status",
+		},
+		dateColumn: "created_at",
+	},
+	"payments": {
+		model: &models.Payments{},
+		dimensions: map[string]string{
+			"method": "method",
+			"status": "status",
+			"day":    "date_trunc('day', created_at)",
+			"week":   "date_trunc('week', created_at)",
+			"month":  "date_trunc('month', created_at)",
+		},
+		measures: map[string]string{
+			"count":          "COUNT(*)",
+			"total_amount":   "SUM(amount)",
+			"total_refunded": "SUM(refunded_amount)",
+			"total_change":   "SUM(change)",
+		},
+		filters: map[string]string{
+			"method": "method",
+			"status": "status",
+		},
+		dateColumn: "created_at",
+	},
+	"returns": {
+		model: &models.OrderReturnItems{},
+		dimensions: map[string]string{
+			"product_id": "product_id",
+			"variant_id": "variant_id",
+			"restocked":  "restocked",
+			"day":        "date_trunc('day', created_at)",
+			"week":       "date_trunc('week', created_at)",
+			"month":      "date_trunc('month', created_at)",
+		},
+		measures: map[string]string{
+			"count":             "COUNT(*)",
+			"quantity_returned": "SUM(quantity)",
+			"total_refunded":    "SUM(amount)",
+		},
+		filters: map[string]string{
+			"product_id": "product_id",
+			"variant_id": "variant_id",
+		},
+		dateColumn: "created_at",
+	},
+	"promotions": {
+		model: &models.OrderPromotions{},
+		dimensions: map[string]string{
+			"promotion_id": "promotion_id",
+			"day":          "date_trunc('day', created_at)",
+			"week":         "date_trunc('week', created_at)",
+			"month":        "date_trunc('month', created_at)",
+		},
+		measures: map[string]string{
+			"count":          "COUNT(*)",
+			"total_discount": "SUM(amount)",
+		},
+		filters: map[string]string{
+			"promotion_id": "promotion_id",
+		},
+		dateColumn: "created_at",
+	},
+	"taxes": {
+		model: &models.OrderTaxes{},
+		dimensions: map[string]string{
+			"name":      "name",
+			"rate":      "rate",
+			"inclusive": "inclusive",
+			"day":       "date_trunc('day', created_at)",
+			"week":      "date_trunc('week', created_at)",
+			"month":     "date_trunc('month', created_at)",
+		},
+		measures: map[string]string{
+			"count":   "COUNT(DISTINCT order_id)",
+			"taxable": "SUM(taxable)",
+			"tax":     "SUM(amount)",
+		},
+		filters: map[string]string{
+			"name":        "name",
+			"tax_rate_id": "tax_rate_id",
+		},
+		dateColumn: "created_at",
+	},
+	"coupon_redemptions": {
+		model: &models.CouponRedemptions{},
+		dimensions: map[string]string{
+			"coupon_id": "coupon_id",
+			"status":    "status",
+			"day":       "date_trunc('day', created_at)",
+			"week":      "date_trunc('week', created_at)",
+			"month":     "date_trunc('month', created_at)",
+		},
+		measures: map[string]string{
+			"count": "COUNT(*)",
+		},
+		filters: map[string]string{
+			"coupon_id": "coupon_id",
+			"status":    "status",
+		},
+		dateColumn: "created_at",
+	},
+	"customers": {
+		model: &models.Customers{},
+		dimensions: map[string]string{
+			"marketing_opt_in": "marketing_opt_in",
+			"day":              "date_trunc('day', created_at)",
+			"week":             "date_trunc('week', created_at)",
+			"month":            "date_trunc('month', created_at)",
+		},
+		measures: map[string]string{
+			"count": "COUNT(*)",
+		},
+		filters: map[string]string{
+			"marketing_opt_in": "marketing_opt_in",
+			"import_id":        "import_id",
+		},
+		dateColumn: "created_at",
+	},
+	"loyalty_transactions": {
+		model: &models.LoyaltyTransactions{},
+		dimensions: map[string]string{
+			"type":        "type",
+			"customer_id": "customer_id",
+			"day":         "date_trunc('day', created_at)",
+			"week":        "date_trunc('week', created_at)",
+			"month":       "date_trunc('month', created_at)",
+		},
+		measures: map[string]string{
+			"count":  "COUNT(*)",
+			"points": "SUM(points)",
+			"earned": "SUM(GREATEST(points, 0))",
+			"spent":  "SUM(LEAST(points, 0))",
+		},
+		filters: map[string]string{
+			"type":        "type",
+			"customer_id": "customer_id",
+		},
+		dateColumn: "created_at",
+	},
+	"cash_drawers": {
+		model: &models.CashDrawerSessions{},
+		dimensions: map[string]string{
+			"opened_by_id": "opened_by_id",
+			"device_id":    "device_id",
+			"status":       "status",
+			"day":          "date_trunc('day', opened_at)",
+			"week":         "date_trunc('week', opened_at)",
+			"month":        "date_trunc('month', opened_at)",
+		},
+		measures: map[string]string{
+			"count":          "COUNT(*)",
+			"total_expected": "SUM(expected)",
+			"total_counted":  "SUM(counted)",
+			"total_variance": "SUM(variance)",
+			"total_shortage": "SUM(LEAST(variance, 0))",
+		},
+		filters: map[string]string{
+			"opened_by_id": "opened_by_id",
+			"device_id":    "device_id",
+			"status":       "status",
+		},
+		dateColumn: "opened_at",
+	},
+	"shifts": {
+		model: &models.Shifts{},
+		dimensions: map[string]string{
+			"user_id":   "user_id",
+			"device_id": "device_id",
+			"location":  "location",
+			"store_id":  "store_id",
+			"status":    "status",
+			"day":       "date_trunc('day', opened_at)",
+			"week":      "date_trunc('week', opened_at)",
+			"month":     "date_trunc('month', opened_at)",
+		},
+		measures: map[string]string{
+			"count":          "COUNT(*)",
+			"total_float":    "SUM(opening_float)",
+			"avg_hours_open": "AVG(EXTRACT(EPOCH FROM (closed_at - opened_at)) / 3600)",
+		},
+		filters: map[string]string{
+			"user_id":   "user_id",
+			"device_id": "device_id",
+			"location":  "location",
+			"store_id":  "store_id",
+			"status":    "status",
+		},
+		dateColumn: "opened_at",
+	},
+	"invoices": {
+		model: &models.Invoices{},
+		dimensions: map[string]string{
+			"status":      "status",
+			"customer_id": "customer_id",
+			"store_id":    "store_id",
+			"day":         "date_trunc('day', issued_at)",
+			"week":        "date_trunc('week', issued_at)",
+			"month":       "date_trunc('month', issued_at)",
+		},
+		measures: map[string]string{
+			"count":         "COUNT(*)",
+			"total_billed":  "SUM(total)",
+			"total_paid":    "SUM(amount_paid)",
+			"total_balance": "SUM(balance)",
+		},
+		filters: map[string]string{
+			"status":      "status",
+			"customer_id": "customer_id",
+			"store_id":    "store_id",
+		},
+		dateColumn: "issued_at",
+	},
+	"quotes": {
+		model: &models.Quotes{},
+		dimensions: map[string]string{
+			"status":        "status",
+			"customer_id":   "customer_id",
+			"store_id":      "store_id",
+			"created_by_id": "created_by_id",
+			"day":           "date_trunc('day', created_at)",
+			"week":          "date_trunc('week', created_at)",
+			"month":         "date_trunc('month', created_at)",
+		},
+		measures: map[string]string{
+			"count":        "COUNT(*)",
+			"total_quoted": "SUM(total)",
+			"converted":    "COUNT(order_id)",
+		},
+		filters: map[string]string{
+			"status":      "status",
+			"customer_id": "customer_id",
+			"store_id":    "store_id",
+		},
+		dateColumn: "created_at",
+	},
+}
+
+type ReportService struct {
+	db *gorm.DB
+}
+
+func NewReportService(db *gorm.DB) *ReportService {
+	return &ReportService{db: db}
+}
+
+// sortedKeys returns the keys of a whitelist map in a stable order
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GetReportEntities lists the entities, dimensions, measures, and filters available to report definitions
+func (s *ReportService) GetReportEntities() []models.ReportEntityResponse {
+	names := make([]string, 0, len(reportEntities))
+	for name := range reportEntities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	response := make([]models.ReportEntityResponse, 0, len(names))
+	for _, name := range names {
+		entity := reportEntities[name]
+		response = append(response, models.ReportEntityResponse{
+			Entity:     name,
+			Dimensions: sortedKeys(entity.dimensions),
+			Measures:   sortedKeys(entity.measures),
+			Filters:    sortedKeys(entity.filters),
+			DateField:  entity.dateColumn,
+		})
+	}
+	return response
+}
+
+// ValidateReportDefinition checks a definition against the whitelist and returns the problems found
+func ValidateReportDefinition(req *models.ReportDefinitionRequest) map[string]string {
+	problems := map[string]string{}
+
+	entity, ok := reportEntities[req.Entity]
+	if !ok {
+		problems["entity"] = fmt.Sprintf("unknown entity %q", req.Entity)
+		return problems
+	}
+
+	seen := map[string]bool{}
+	for _, dimension := range req.Dimensions {
+		if _, ok := entity.dimensions[dimension]; !ok {
+			problems["dimensions"] = fmt.Sprintf("unknown dimension %q for %s", dimension, req.Entity)
+		} else if seen[dimension] {
+			problems["dimensions"] = fmt.Sprintf("duplicate dimension %q", dimension)
+		}
+		seen[dimension] = true
+	}
+
+	for _, measure := range req.Measures {
+		if _, ok := entity.measures[measure]; !ok {
+			problems["measures"] = fmt.Sprintf("unknown measure %q for %s", measure, req.Entity)
+		} else if seen[measure] {
+			problems["measures"] = fmt.Sprintf("duplicate measure %q", measure)
+		}
+		seen[measure] = true
+	}
+
+	for filter, value := range req.Filters {
+		if _, ok := entity.filters[filter]; !ok {
+			problems["filters"] = fmt.Sprintf("unknown filter %q for %s", filter, req.Entity)
+		} else if value == nil {
+			problems["filters"] = fmt.Sprintf("filter %q needs a value", filter)
+		}
+	}
+
+	return problems
+}
+
+// GetAllReportDefinitions retrieves report definitions with pagination and search
+func (s *ReportService) GetAllReportDefinitions(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.ReportDefinitions{},
+		SearchFields: []string{"name", "description"},
+		FilterFields: map[string]string{
+			"entity": "entity",
+		},
+		SortFields: []string{
+			"name",
+			"entity",
+			"created_at",
+		},
+		DefaultSort:  "name",
+		DefaultOrder: "ASC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetReportDefinitionById retrieves a report definition by ID
+func (s *ReportService) GetReportDefinitionById(id uint) (*models.ReportDefinitions, error) {
+	var definition models.ReportDefinitions
+	if err := s.db.Where("id = ?", id).First(&definition).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("report not found")
+		}
+		return nil, err
+	}
+	return &definition, nil
+}
+
+// CreateReportDefinition stores a new validated report definition
+func (s *ReportService) CreateReportDefinition(req *models.ReportDefinitionRequest, createdByID uint) (*models.ReportDefinitions, error) {
+	var existing models.ReportDefinitions
+	if err := s.db.Where("name = ?", req.Name).First(&existing).Error; err == nil {
+		return nil, errors.New("report name already exists")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	definition := models.ReportDefinitions{
+		Name:        req.Name,
+		Description: req.Description,
+		Entity:      req.Entity,
+		Dimensions:  req.Dimensions,
+		Measures:    req.Measures,
+		Filters:     req.Filters,
+	}
+	if createdByID != 0 {
+		definition.CreatedByID = &createdByID
+	}
+
+	if err := s.db.Create(&definition).Error; err != nil {
+		return nil, err
+	}
+
+	return &definition, nil
+}
+
+// UpdateReportDefinition replaces a report definition
+func (s *ReportService) UpdateReportDefinition(id uint, req *models.ReportDefinitionRequest) (*models.ReportDefinitions, error) {
+	definition, err := s.GetReportDefinitionById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing models.ReportDefinitions
+	if err := s.db.Where("name = ? AND id <> ?", req.Name, definition.ID).First(&existing).Error; err == nil {
+		return nil, errors.New("report name already exists")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	definition.Name = req.Name
+	definition.Description = req.Description
+	definition.Entity = req.Entity
+	definition.Dimensions = req.Dimensions
+	definition.Measures = req.Measures
+	definition.Filters = req.Filters
+
+	if err := s.db.Save(definition).Error; err != nil {
+		return nil, err
+	}
+
+	return definition, nil
+}
+
+// DeleteReportDefinition deletes a report definition
+func (s *ReportService) DeleteReportDefinition(id uint) (*models.ReportDefinitions, error) {
+	definition, err := s.GetReportDefinitionById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Delete(definition).Error; err != nil {
+		return nil, err
+	}
+
+	return definition, nil
+}
+
+// reportConfig builds the aggregated query of a report definition. Callers can narrow the
+// results further with whitelisted filters and the "date" range, and sort by any dimension or
+// measure.
+func reportConfig(definition *models.ReportDefinitions) (pagination.PaginationConfig, error) {
+	entity, ok := reportEntities[definition.Entity]
+	if !ok {
+		return pagination.PaginationConfig{}, errors.New("report entity is no longer available")
+	}
+
+	config := pagination.PaginationConfig{
+		Model:         entity.model,
+		BaseCondition: map[string]interface{}{},
+		FilterFields:  entity.filters,
+		DateFields: map[string]pagination.DateField{
+			"date": {
+				Start: entity.dateColumn,
+				End:   entity.dateColumn,
+			},
+		},
+		ScanIntoMaps: true,
+	}
+
+	for field, value := range entity.baseCondition {
+		config.BaseCondition[field] = value
+	}
+	for filter, value := range definition.Filters {
+		if column, ok := entity.filters[filter]; ok {
+			config.BaseCondition[column] = value
+		}
+	}
+
+	for _, dimension := range definition.Dimensions {
+		expression, ok := entity.dimensions[dimension]
+		if !ok {
+			return pagination.PaginationConfig{}, errors.New("report references an unknown dimension")
+		}
+		config.SelectFields = append(config.SelectFields, pagination.SelectField{Field: expression, Alias: dimension})
+		config.GroupBy = append(config.GroupBy, expression)
+		config.SortFields = append(config.SortFields, dimension)
+	}
+
+	for _, measure := range definition.Measures {
+		expression, ok := entity.measures[measure]
+		if !ok {
+			return pagination.PaginationConfig{}, errors.New("report references an unknown measure")
+		}
+		config.SelectFields = append(config.SelectFields, pagination.SelectField{Field: expression, Alias: measure})
+		config.SortFields = append(config.SortFields, measure)
+	}
+
+	// Sort by the first dimension (e.g., the time bucket) unless the caller picks a column
+	config.DefaultSort = config.SortFields[0]
+	config.DefaultOrder = "ASC"
+
+	return config, nil
+}
+
+// RunReport executes a report definition through the paginator.
+// Callers can narrow the results further with whitelisted filters and the "date" range,
+// and sort by any dimension or measure.
+func (s *ReportService) RunReport(id uint, params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	definition, err := s.GetReportDefinitionById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := reportConfig(definition)
+	if err != nil {
+		return nil, err
+	}
+
+	// Search is not supported on aggregated reports
+	params.Search = ""
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// ReportColumns returns the columns of a report's rows: its dimensions, then its measures
+func ReportColumns(definition *models.ReportDefinitions) []string {
+	return append(append([]string{}, definition.Dimensions...), definition.Measures...)
+}
+
+// StreamReport runs a report definition without page limits, over the rows dated within the
+// range when one is given, and calls fn with every row's values in the order of ReportColumns
+func (s *ReportService) StreamReport(ctx context.Context, definition *models.ReportDefinitions, dates *pagination.DateRange, fn func(values []string) error) error {
+	config, err := reportConfig(definition)
+	if err != nil {
+		return err
+	}
+
+	var params pagination.QueryParams
+	if dates != nil {
+		params.Dates = map[string]pagination.DateRange{"date": *dates}
+	}
+	if err := params.BindContext(ctx); err != nil {
+		return err
+	}
+
+	columns := ReportColumns(definition)
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Stream(params, config, func(record interface{}) error {
+		row := record.(map[string]interface{})
+		values := make([]string, len(columns))
+		for i, column := range columns {
+			values[i] = reportValue(row[column])
+		}
+		return fn(values)
+	})
+}
+
+// reportValue formats a value of a report row for a file: times as dates, or timestamps when
+// they aren't midnight, and numbers without exponents
+func reportValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		if v.Equal(v.Truncate(24 * time.Hour)) {
+			return v.Format("2006-01-02")
+		}
+		return v.Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,423 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// refundableOrderStatuses are the statuses of orders that can be refunded: paid, whether or not
+// the goods were handed over
+var refundableOrderStatuses = []string{
+	models.OrderStatusPaid,
+	models.OrderStatusFulfilled,
+	models.OrderStatusCompleted,
+}
+
+// OrderReturn is a return being recorded as seen by the hooks subscribed to it. The return's
+// items say how much of each order item comes back.
+type OrderReturn struct {
+	Order  *models.Orders
+	Return *models.OrderReturns
+	Reason string
+	Actor  models.ActivityActor
+}
+
+// OrderReturnHook runs in the transaction that records a return; returning an error rolls the
+// return back
+type OrderReturnHook func(tx *gorm.DB, ret OrderReturn) error
+
+// ReturnService refunds orders, in full or line by line. Refunds are paid back through the
+// payments the order was paid with and returned goods can be put back into stock; an order
+// whose every line has been returned moves to refunded.
+type ReturnService struct {
+	db              *gorm.DB
+	orders          *OrderService
+	inventory       *InventoryService
+	payments        *PaymentService
+	activityService *ActivityService
+	returnHooks     []OrderReturnHook
+}
+
+func NewReturnService(db *gorm.DB, orders *OrderService, inventory *InventoryService, payments *PaymentService, activityService *ActivityService) *ReturnService {
+	return &ReturnService{
+		db:              db,
+		orders:          orders,
+		inventory:       inventory,
+		payments:        payments,
+		activityService: activityService,
+	}
+}
+
+// OnReturn subscribes a hook to every return, e.g. to take back what the returned lines earned
+func (s *ReturnService) OnReturn(hook OrderReturnHook) {
+	s.returnHooks = append(s.returnHooks, hook)
+}
+
+// returnNumber is the human-facing number of a return
+func returnNumber(id uint) string {
+	return fmt.Sprintf("RET-%06d", id)
+}
+
+// returnLine is a line of an order being returned, with the serials returned of serialized
+// products
+type returnLine struct {
+	item     *models.OrderItems
+	quantity int64
+	restock  bool
+	serials  []string
+}
+
+// amount is what the returned quantity of a line is worth. Returning the rest of a line pays
+// back whatever is left of its total, so rounding never leaves cents behind.
+func (l returnLine) amount() int64 {
+	if l.item.ReturnedQuantity+l.quantity == l.item.Quantity {
+		return l.item.Total - l.item.RefundedAmount
+	}
+	return l.item.Total * l.quantity / l.item.Quantity
+}
+
+// returnLines matches the requested lines to the order's items. Without requested lines every
+// item is returned for what is left of it.
+func returnLines(order *models.Orders, req *models.RefundOrderRequest) ([]returnLine, error) {
+	restock := req.Restock == nil || *req.Restock
+
+	var lines []returnLine
+	if len(req.Items) == 0 {
+		for i := range order.Items {
+			item := &order.Items[i]
+			if remaining := item.Quantity - item.ReturnedQuantity; remaining > 0 {
+				lines = append(lines, returnLine{item: item, quantity: remaining, restock: restock, serials: unreturnedSerials(item)})
+			}
+		}
+		if len(lines) == 0 {
+			return nil, errors.New("nothing left to refund")
+		}
+		return lines, nil
+	}
+
+	seen := make(map[uint]bool, len(req.Items))
+	for _, requested := range req.Items {
+		if seen[requested.OrderItemID] {
+			return nil, errors.New("duplicate order item")
+		}
+		seen[requested.OrderItemID] = true
+
+		index := slices.IndexFunc(order.Items, func(item models.OrderItems) bool {
+			return item.ID == requested.OrderItemID
+		})
+		if index < 0 {
+			return nil, errors.New("order item not found")
+		}
+		item := &order.Items[index]
+		if requested.Quantity > item.Quantity-item.ReturnedQuantity {
+			return nil, errors.New("return exceeds quantity sold")
+		}
+
+		serials, err := returnedSerials(item, requested)
+		if err != nil {
+			return nil, err
+		}
+		line := returnLine{item: item, quantity: requested.Quantity, restock: restock, serials: serials}
+		if requested.Restock != nil {
+			line.restock = *requested.Restock
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// unreturnedSerials returns the serials sold on an order line that haven't been returned
+func unreturnedSerials(item *models.OrderItems) []string {
+	var serials []string
+	for _, serial := range item.Serials {
+		if !slices.Contains(item.ReturnedSerials, serial) {
+			serials = append(serials, serial)
+		}
+	}
+	return serials
+}
+
+// returnBatches counts returned units of a line back against the batches it was taken from,
+// the last batch allocated first, and returns the quantity returned per batch
+func returnBatches(item *models.OrderItems, quantity int64) []models.BatchAllocation {
+	var returned []models.BatchAllocation
+	for i := len(item.Batches) - 1; i >= 0 && quantity > 0; i-- {
+		allocation := &item.Batches[i]
+		count := min(quantity, allocation.Quantity-allocation.Returned)
+		if count == 0 {
+			continue
+		}
+		allocation.Returned += count
+		quantity -= count
+		returned = append(returned, models.BatchAllocation{Batch: allocation.Batch, Quantity: count})
+	}
+	return returned
+}
+
+// returnedSerials checks the serials given for a returned line: lines sold with serials name
+// one per unit returned, from those sold on the line and not returned yet
+func returnedSerials(item *models.OrderItems, requested models.RefundItemRequest) ([]string, error) {
+	if len(item.Serials) == 0 {
+		if len(requested.Serials) > 0 {
+			return nil, errors.New("product not serialized")
+		}
+		return nil, nil
+	}
+	if len(requested.Serials) == 0 {
+		return nil, errors.New("serials required")
+	}
+	if int64(len(requested.Serials)) != requested.Quantity {
+		return nil, errors.New("serials don't match quantity")
+	}
+	remaining := unreturnedSerials(item)
+	for i, serial := range requested.Serials {
+		if slices.Contains(requested.Serials[:i], serial) {
+			return nil, errors.New("duplicate serial")
+		}
+		if !slices.Contains(remaining, serial) {
+			return nil, errors.New("serial not sold on order")
+		}
+	}
+	return requested.Serials, nil
+}
+
+// RefundOrder refunds some or all lines of a paid order. The amount is paid back through the
+// order's captured payments, newest first, and the whole return rolls back if a payment can't
+// be refunded.
+func (s *ReturnService) RefundOrder(ctx context.Context, orderID uint, req *models.RefundOrderRequest, actor models.ActivityActor) (*models.OrderReturns, error) {
+	var ret models.OrderReturns
+	var transition *OrderTransition
+	var restocks []models.StockMovementInput
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		order, err := lockOrder(tx, orderID)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(refundableOrderStatuses, order.Status) {
+			return errors.New("order not refundable")
+		}
+		if err := tx.Preload("Components").Where("order_id = ?", order.ID).Order("id").Find(&order.Items).Error; err != nil {
+			return err
+		}
+
+		lines, err := returnLines(order, req)
+		if err != nil {
+			return err
+		}
+
+		ret = models.OrderReturns{
+			OrderID:     order.ID,
+			Reason:      req.Reason,
+			CreatedByID: actorID(actor),
+		}
+		for _, line := range lines {
+			amount := line.amount()
+			ret.Items = append(ret.Items, models.OrderReturnItems{
+				OrderItemID: line.item.ID,
+				ProductID:   line.item.ProductID,
+				VariantID:   line.item.VariantID,
+				Quantity:    line.quantity,
+				Amount:      amount,
+				Restocked:   line.restock,
+				Serials:     models.JSONStringList(line.serials),
+			})
+			ret.ItemCount += line.quantity
+			ret.Amount += amount
+		}
+
+		orderPayments, err := orderPayments(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		var refundable int64
+		for _, payment := range orderPayments {
+			if payment.Status == models.PaymentStatusCaptured {
+				refundable += payment.Amount - payment.RefundedAmount
+			}
+		}
+		if ret.Amount > refundable {
+			return errors.New("refund exceeds payments")
+		}
+
+		if err := tx.Create(&ret).Error; err != nil {
+			return err
+		}
+		ret.Number = returnNumber(ret.ID)
+		if err := tx.Model(&ret).UpdateColumn("number", ret.Number).Error; err != nil {
+			return err
+		}
+
+		for _, line := range lines {
+			line.item.RefundedAmount += line.amount()
+			line.item.ReturnedQuantity += line.quantity
+			line.item.ReturnedSerials = append(line.item.ReturnedSerials, line.serials...)
+			batches := returnBatches(line.item, line.quantity)
+			if err := tx.Model(line.item).Updates(map[string]interface{}{
+				"returned_quantity": line.item.ReturnedQuantity,
+				"returned_serials":  line.item.ReturnedSerials,
+				"batches":           line.item.Batches,
+				"refunded_amount":   line.item.RefundedAmount,
+			}).Error; err != nil {
+				return err
+			}
+			if line.restock {
+				restock := models.StockMovementInput{
+					ProductID:   line.item.ProductID,
+					VariantID:   line.item.VariantID,
+					StoreID:     order.StoreID,
+					Type:        models.StockMovementReturn,
+					Quantity:    line.quantity,
+					Unit:        line.item.Unit,
+					Reference:   ret.Number,
+					Serials:     line.serials,
+					OrderID:     &order.ID,
+					CreatedByID: actorID(actor),
+				}
+				for _, component := range line.item.Components {
+					restock.ProductID = component.ProductID
+					restock.VariantID = component.VariantID
+					restock.Unit = ""
+					restock.Quantity = component.PerBundle * line.quantity
+					restocks = append(restocks, restock)
+				}
+				if len(batches) == 0 && len(line.item.Components) == 0 {
+					restocks = append(restocks, restock)
+				}
+				for _, batch := range batches {
+					restock.Quantity = batch.Quantity
+					restock.Batch = batch.Batch
+					restocks = append(restocks, restock)
+				}
+			}
+		}
+		if _, err := s.inventory.RecordMovements(tx, restocks); err != nil {
+			return err
+		}
+		for _, hook := range s.returnHooks {
+			if err := hook(tx, OrderReturn{Order: order, Return: &ret, Reason: req.Reason, Actor: actor}); err != nil {
+				return err
+			}
+		}
+
+		// Paid back through the most recent payments first
+		remaining := ret.Amount
+		for i := len(orderPayments) - 1; i >= 0 && remaining > 0; i-- {
+			payment := &orderPayments[i]
+			if payment.Status != models.PaymentStatusCaptured {
+				continue
+			}
+			amount := min(remaining, payment.Amount-payment.RefundedAmount)
+			if amount == 0 {
+				continue
+			}
+			if err := s.payments.refundPaymentTx(ctx, tx, order, payment, amount, fmt.Sprintf("return %s", ret.Number), actor); err != nil {
+				return err
+			}
+			refund := models.OrderReturnRefunds{
+				ReturnID:  ret.ID,
+				PaymentID: payment.ID,
+				Method:    payment.Method,
+				Amount:    amount,
+			}
+			if err := tx.Create(&refund).Error; err != nil {
+				return err
+			}
+			ret.Refunds = append(ret.Refunds, refund)
+			remaining -= amount
+		}
+
+		if err := s.activityService.RecordTx(tx, actor.UserID, models.ActivityOrderReturned, actor, fmt.Sprintf("Order %s refunded with return %s", order.Number, ret.Number), models.JSONMap{
+			"order_id":   order.ID,
+			"number":     order.Number,
+			"return_id":  ret.ID,
+			"return":     ret.Number,
+			"amount":     ret.Amount,
+			"item_count": ret.ItemCount,
+			"reason":     req.Reason,
+		}); err != nil {
+			return err
+		}
+
+		for _, item := range order.Items {
+			if item.ReturnedQuantity < item.Quantity {
+				return nil
+			}
+		}
+		fullyReturned, err := s.orders.refundedTx(tx, order, req.Reason, actor)
+		if err != nil {
+			return err
+		}
+		transition = &fullyReturned
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.inventory.stockChanged(restocks)
+	if transition != nil {
+		s.orders.transitioned(*transition)
+	}
+	return &ret, nil
+}
+
+// GetOrderReturns retrieves the returns of an order, oldest first
+func (s *ReturnService) GetOrderReturns(orderID uint) ([]models.OrderReturns, error) {
+	var order models.Orders
+	if err := s.db.Select("id").Where("id = ?", orderID).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("order not found")
+		}
+		return nil, err
+	}
+
+	returns := []models.OrderReturns{}
+	if err := s.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Refunds", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("order_id = ?", order.ID).Order("id").Find(&returns).Error; err != nil {
+		return nil, err
+	}
+	return returns, nil
+}
+
+// GetAllReturns retrieves returns across orders, for reviewing what is refunded and why
+func (s *ReturnService) GetAllReturns(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.OrderReturns{},
+		SearchFields: []string{"number", "reason"},
+		FilterFields: map[string]string{
+			"order_id":      "order_id",
+			"created_by_id": "created_by_id",
+		},
+		CustomFilters: map[string]string{
+			"product_id": "id IN (SELECT return_id FROM order_return_items WHERE product_id = ?)",
+		},
+		StoreFilter: "order_id IN (SELECT id FROM orders WHERE store_id = ?)",
+		DateFields: map[string]pagination.DateField{
+			"created_at": {
+				Start: "created_at",
+				End:   "created_at",
+			},
+		},
+		SortFields: []string{
+			"number",
+			"item_count",
+			"amount",
+			"created_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+		Relations:    []string{"Items"},
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
@@ -0,0 +1,941 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/limits"
+	"gorm.io/gorm"
+)
+
+// scimDefaultCount is the page size of SCIM list requests that do not ask for one
+const scimDefaultCount = 100
+
+// SCIMRequestError is a SCIM request the service could not apply. ScimType is the
+// RFC 7644 error type reported to the identity provider.
+type SCIMRequestError struct {
+	ScimType string
+	Detail   string
+}
+
+func (e *SCIMRequestError) Error() string {
+	return e.Detail
+}
+
+func invalidSCIMValue(format string, args ...interface{}) error {
+	return &SCIMRequestError{ScimType: "invalidValue", Detail: fmt.Sprintf(format, args...)}
+}
+
+// SCIMService maps SCIM 2.0 users onto accounts and groups onto teams, so identity providers
+// can provision and deprovision accounts. Members of the configured admin groups get the
+// admin role; everyone else provisioned through a group gets the user role.
+type SCIMService struct {
+	db          *gorm.DB
+	userService *UserService
+	teamService *TeamService
+	adminGroups map[string]bool
+}
+
+func NewSCIMService(db *gorm.DB, userService *UserService, teamService *TeamService, adminGroups []string) *SCIMService {
+	groups := make(map[string]bool, len(adminGroups))
+	for _, group := range adminGroups {
+		if group = strings.TrimSpace(group); group != "" {
+			groups[strings.ToLower(group)] = true
+		}
+	}
+	return &SCIMService{
+		db:          db,
+		userService: userService,
+		teamService: teamService,
+		adminGroups: groups,
+	}
+}
+
+// scimFilterPattern matches the only filter form identity providers rely on: attribute eq "value"
+var scimFilterPattern = regexp.MustCompile(`(?i)^\s*([a-z][a-z0-9.]*)\s+eq\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// parseSCIMFilter returns the column and value of an equality filter. Columns maps the
+// lower-cased attribute names that may be filtered on to their columns.
+func parseSCIMFilter(filter string, columns map[string]string) (string, string, error) {
+	match := scimFilterPattern.FindStringSubmatch(filter)
+	if match == nil {
+		return "", "", &SCIMRequestError{ScimType: "invalidFilter", Detail: "only filters of the form attribute eq \"value\" are supported"}
+	}
+	column, ok := columns[strings.ToLower(match[1])]
+	if !ok {
+		return "", "", &SCIMRequestError{ScimType: "invalidFilter", Detail: fmt.Sprintf("filtering on %s is not supported", match[1])}
+	}
+	value, err := strconv.Unquote(`"` + match[2] + `"`)
+	if err != nil {
+		return "", "", &SCIMRequestError{ScimType: "invalidFilter", Detail: "invalid filter value"}
+	}
+	return column, value, nil
+}
+
+// listWindow returns the offset and limit of a SCIM list request
+func listWindow(query *models.SCIMListQuery) (int, int) {
+	if query.StartIndex < 1 {
+		query.StartIndex = 1
+	}
+	count := scimDefaultCount
+	if query.Count != nil {
+		count = *query.Count
+	}
+	if count < 0 {
+		count = 0
+	}
+	if max := limits.Get(limits.MaxPageSize); count > max {
+		count = max
+	}
+	return query.StartIndex - 1, count
+}
+
+// toSCIMUser describes an account as a SCIM user
+func toSCIMUser(user *models.Users, teams []models.Teams) *models.SCIMUser {
+	active := user.Status == models.UserStatusActive
+	scimUser := &models.SCIMUser{
+		Schemas:     []string{models.SCIMSchemaUser},
+		ID:          strconv.FormatUint(uint64(user.ID), 10),
+		UserName:    user.Username,
+		Name:        &models.SCIMName{Formatted: user.Name},
+		DisplayName: user.Name,
+		Emails:      []models.SCIMEmail{{Value: user.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Groups:      []models.SCIMGroupRef{},
+		Meta: &models.SCIMMeta{
+			ResourceType: "User",
+			Created:      user.CreatedAt,
+			LastModified: user.UpdatedAt,
+			Location:     fmt.Sprintf("/scim/v2/Users/%d", user.ID),
+		},
+	}
+	if user.ExternalID != nil {
+		scimUser.ExternalID = *user.ExternalID
+	}
+	for _, team := range teams {
+		scimUser.Groups = append(scimUser.Groups, models.SCIMGroupRef{
+			Value:   strconv.FormatUint(uint64(team.ID), 10),
+			Display: team.Name,
+		})
+	}
+	return scimUser
+}
+
+// userTeams returns the teams a user belongs to
+func (s *SCIMService) userTeams(userID uint) ([]models.Teams, error) {
+	var teams []models.Teams
+	err := s.db.Where("id IN (SELECT team_id FROM team_members WHERE user_id = ?)", userID).Order("name ASC").Find(&teams).Error
+	return teams, err
+}
+
+// parseSCIMID parses the ID of a SCIM resource, which is the database ID of the account or team
+func parseSCIMID(id string) (uint, bool) {
+	parsed, err := strconv.ParseUint(id, 10, 32)
+	if err != nil || parsed == 0 {
+		return 0, false
+	}
+	return uint(parsed), true
+}
+
+// findUser loads a provisioned account; deprovisioned accounts are not found
+func (s *SCIMService) findUser(id string) (*models.Users, error) {
+	userID, ok := parseSCIMID(id)
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+
+	var user models.Users
+	if err := s.db.Where("id = ? AND is_deleted = ?", userID, false).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// describeUser loads an account and describes it as a SCIM user
+func (s *SCIMService) describeUser(id string) (*models.SCIMUser, error) {
+	user, err := s.findUser(id)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := s.userTeams(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toSCIMUser(user, teams), nil
+}
+
+// GetUsers lists provisioned accounts, optionally filtered by userName, externalId or email
+func (s *SCIMService) GetUsers(query *models.SCIMListQuery) (*models.SCIMListResponse, error) {
+	db := s.db.Model(&models.Users{}).Where("is_deleted = ?", false)
+	if query.Filter != "" {
+		column, value, err := parseSCIMFilter(query.Filter, map[string]string{
+			"id":           "id",
+			"username":     "username",
+			"externalid":   "external_id",
+			"emails":       "email",
+			"emails.value": "email",
+		})
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where(column+" = ?", value)
+	}
+
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	offset, count := listWindow(query)
+	resources := []*models.SCIMUser{}
+	if count > 0 {
+		var users []models.Users
+		if err := db.Order("id ASC").Offset(offset).Limit(count).Find(&users).Error; err != nil {
+			return nil, err
+		}
+		for i := range users {
+			teams, err := s.userTeams(users[i].ID)
+			if err != nil {
+				return nil, err
+			}
+			resources = append(resources, toSCIMUser(&users[i], teams))
+		}
+	}
+
+	return &models.SCIMListResponse{
+		Schemas:      []string{models.SCIMSchemaListResponse},
+		TotalResults: total,
+		StartIndex:   query.StartIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}, nil
+}
+
+// GetUser returns a provisioned account as a SCIM user
+func (s *SCIMService) GetUser(id string) (*models.SCIMUser, error) {
+	return s.describeUser(id)
+}
+
+// scimUserFields extracts the account fields from a SCIM user
+func scimUserFields(in *models.SCIMUser) (name string, email string, err error) {
+	if strings.TrimSpace(in.UserName) == "" {
+		return "", "", invalidSCIMValue("userName is required")
+	}
+
+	switch {
+	case in.Name != nil && in.Name.Formatted != "":
+		name = in.Name.Formatted
+	case in.Name != nil && (in.Name.GivenName != "" || in.Name.FamilyName != ""):
+		name = strings.TrimSpace(in.Name.GivenName + " " + in.Name.FamilyName)
+	case in.DisplayName != "":
+		name = in.DisplayName
+	default:
+		name = in.UserName
+	}
+
+	for _, candidate := range in.Emails {
+		if email == "" || candidate.Primary {
+			email = candidate.Value
+		}
+	}
+	if email == "" {
+		return "", "", invalidSCIMValue("an email address is required")
+	}
+	return name, email, nil
+}
+
+// checkExternalID makes sure no other account uses the identity provider's ID
+func (s *SCIMService) checkExternalID(externalID string, excludeID uint) error {
+	if externalID == "" {
+		return nil
+	}
+	var count int64
+	if err := s.db.Model(&models.Users{}).Where("external_id = ? AND id <> ?", externalID, excludeID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("external id already exists")
+	}
+	return nil
+}
+
+// setExternalID stores the identity provider's ID of an account
+func (s *SCIMService) setExternalID(userID uint, externalID string) error {
+	var value *string
+	if externalID != "" {
+		value = &externalID
+	}
+	return s.db.Model(&models.Users{}).Where("id = ?", userID).Update("external_id", value).Error
+}
+
+// setActive suspends or reactivates an account to match the SCIM active flag
+func (s *SCIMService) setActive(user *models.Users, active *bool, actor models.ActivityActor) error {
+	if active == nil {
+		return nil
+	}
+
+	status := models.UserStatusActive
+	reason := "Reactivated by the identity provider"
+	if !*active {
+		status = models.UserStatusSuspended
+		reason = "Deactivated by the identity provider"
+	}
+	if user.Status == status {
+		return nil
+	}
+
+	if _, err := s.userService.ChangeUserStatus(user.ID, status, reason, actor); err != nil {
+		if err.Error() == "invalid status transition" {
+			return invalidSCIMValue("cannot change a %s account to %s", user.Status, status)
+		}
+		return err
+	}
+	return nil
+}
+
+// CreateUser provisions an account. Without a password in the request the account gets a
+// random one, as users provisioned by an identity provider are expected to reset it.
+func (s *SCIMService) CreateUser(in *models.SCIMUser, actor models.ActivityActor) (*models.SCIMUser, error) {
+	name, email, err := scimUserFields(in)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkExternalID(in.ExternalID, 0); err != nil {
+		return nil, err
+	}
+
+	password := in.Password
+	if password == "" {
+		if password, err = generateTemporaryPassword(); err != nil {
+			return nil, err
+		}
+	}
+
+	created, err := s.userService.CreateUser(&models.CreateUserRequest{
+		Username: in.UserName,
+		Email:    email,
+		Password: password,
+		Name:     name,
+		Role:     models.RoleUser,
+		Status:   models.UserStatusActive,
+	}, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.setExternalID(created.ID, in.ExternalID); err != nil {
+		return nil, err
+	}
+
+	id := strconv.FormatUint(uint64(created.ID), 10)
+	user, err := s.findUser(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setActive(user, in.Active, actor); err != nil {
+		return nil, err
+	}
+
+	return s.describeUser(id)
+}
+
+// ReplaceUser overwrites an account with the SCIM user. Email changes still have to be
+// confirmed from the new address, like any other email change.
+func (s *SCIMService) ReplaceUser(id string, in *models.SCIMUser, actor models.ActivityActor) (*models.SCIMUser, error) {
+	user, err := s.findUser(id)
+	if err != nil {
+		return nil, err
+	}
+
+	name, email, err := scimUserFields(in)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkExternalID(in.ExternalID, user.ID); err != nil {
+		return nil, err
+	}
+
+	// A pending email change is kept rather than restarted on every sync
+	if user.PendingEmail != nil && *user.PendingEmail == email {
+		email = user.Email
+	}
+
+	updated, err := s.userService.UpdateUser(user.ID, &models.UpdateUserRequest{
+		Username: in.UserName,
+		Email:    email,
+		Name:     name,
+		Role:     user.Role,
+		Password: in.Password,
+	}, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.setExternalID(updated.ID, in.ExternalID); err != nil {
+		return nil, err
+	}
+	if err := s.setActive(updated, in.Active, actor); err != nil {
+		return nil, err
+	}
+
+	return s.describeUser(id)
+}
+
+// PatchUser applies SCIM PATCH operations to an account
+func (s *SCIMService) PatchUser(id string, req *models.SCIMPatchRequest, actor models.ActivityActor) (*models.SCIMUser, error) {
+	current, err := s.describeUser(id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, operation := range req.Operations {
+		op := strings.ToLower(operation.Op)
+		if op != "add" && op != "replace" && op != "remove" {
+			return nil, invalidSCIMValue("unsupported patch operation %q", operation.Op)
+		}
+
+		if operation.Path == "" {
+			values, ok := operation.Value.(map[string]interface{})
+			if !ok || op == "remove" {
+				return nil, &SCIMRequestError{ScimType: "noTarget", Detail: "a patch without a path must add or replace an object"}
+			}
+			for path, value := range values {
+				if err := setSCIMUserAttribute(current, path, value, false); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		if err := setSCIMUserAttribute(current, operation.Path, operation.Value, op == "remove"); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.ReplaceUser(id, current, actor)
+}
+
+// decodeSCIMValue converts a decoded JSON value into the target type
+func decodeSCIMValue(value interface{}, target interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// scimString reads a patch value that must be a string
+func scimString(path string, value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", invalidSCIMValue("%s must be a string", path)
+	}
+	return s, nil
+}
+
+// scimBool reads a patch value that must be a boolean; some providers send "True" or "False"
+func scimBool(path string, value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		if parsed, err := strconv.ParseBool(v); err == nil {
+			return parsed, nil
+		}
+	}
+	return false, invalidSCIMValue("%s must be a boolean", path)
+}
+
+// setSCIMUserAttribute applies one patch value to a SCIM user; remove clears the attribute
+func setSCIMUserAttribute(user *models.SCIMUser, path string, value interface{}, remove bool) error {
+	if user.Name == nil {
+		user.Name = &models.SCIMName{}
+	}
+
+	var err error
+	lower := strings.ToLower(path)
+	switch {
+	case lower == "username":
+		if remove {
+			return &SCIMRequestError{ScimType: "mutability", Detail: "userName cannot be removed"}
+		}
+		user.UserName, err = scimString(path, value)
+	case lower == "displayname":
+		user.DisplayName = ""
+		if !remove {
+			user.DisplayName, err = scimString(path, value)
+		}
+		// The display name and the formatted name are the same account field
+		user.Name.Formatted = user.DisplayName
+	case lower == "externalid":
+		user.ExternalID = ""
+		if !remove {
+			user.ExternalID, err = scimString(path, value)
+		}
+	case lower == "name":
+		user.Name = &models.SCIMName{}
+		if !remove {
+			err = decodeSCIMValue(value, user.Name)
+		}
+		user.DisplayName = ""
+	case lower == "name.formatted":
+		user.Name.Formatted = ""
+		if !remove {
+			user.Name.Formatted, err = scimString(path, value)
+		}
+		user.DisplayName = ""
+	case lower == "name.givenname", lower == "name.familyname":
+		var part string
+		if !remove {
+			if part, err = scimString(path, value); err != nil {
+				return err
+			}
+		}
+		if lower == "name.givenname" {
+			user.Name.GivenName = part
+		} else {
+			user.Name.FamilyName = part
+		}
+		// Rebuild the display name from its parts
+		user.Name.Formatted = ""
+		user.DisplayName = ""
+	case lower == "active":
+		if remove {
+			return &SCIMRequestError{ScimType: "mutability", Detail: "active cannot be removed"}
+		}
+		var active bool
+		if active, err = scimBool(path, value); err == nil {
+			user.Active = &active
+		}
+	case lower == "password":
+		if remove {
+			return &SCIMRequestError{ScimType: "mutability", Detail: "password cannot be removed"}
+		}
+		user.Password, err = scimString(path, value)
+	case lower == "emails":
+		if remove {
+			return &SCIMRequestError{ScimType: "mutability", Detail: "the email address cannot be removed"}
+		}
+		err = decodeSCIMValue(value, &user.Emails)
+	case strings.HasPrefix(lower, "emails[") && strings.HasSuffix(lower, "].value"):
+		// Accounts have a single email address, so every email filter targets it
+		if remove {
+			return &SCIMRequestError{ScimType: "mutability", Detail: "the email address cannot be removed"}
+		}
+		var email string
+		if email, err = scimString(path, value); err == nil {
+			user.Emails = []models.SCIMEmail{{Value: email, Type: "work", Primary: true}}
+		}
+	default:
+		return &SCIMRequestError{ScimType: "invalidPath", Detail: fmt.Sprintf("patching %s is not supported", path)}
+	}
+	return err
+}
+
+// DeleteUser deprovisions an account. It is deactivated rather than deleted, so its
+// history stays intact and an administrator can restore it.
+func (s *SCIMService) DeleteUser(id string, actor models.ActivityActor) error {
+	user, err := s.findUser(id)
+	if err != nil {
+		return err
+	}
+
+	teams, err := s.userTeams(user.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.userService.SoftDeleteUser(user.ID, actor); err != nil {
+		return err
+	}
+
+	// Deprovisioned accounts leave their groups
+	for _, team := range teams {
+		if _, err := s.teamService.RemoveTeamMember(team.ID, user.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toSCIMGroup describes a team as a SCIM group
+func toSCIMGroup(team *models.Teams) *models.SCIMGroup {
+	group := &models.SCIMGroup{
+		Schemas:     []string{models.SCIMSchemaGroup},
+		ID:          strconv.FormatUint(uint64(team.ID), 10),
+		DisplayName: team.Name,
+		Members:     []models.SCIMMember{},
+		Meta: &models.SCIMMeta{
+			ResourceType: "Group",
+			Created:      team.CreatedAt,
+			LastModified: team.UpdatedAt,
+			Location:     fmt.Sprintf("/scim/v2/Groups/%d", team.ID),
+		},
+	}
+	for _, member := range team.Members {
+		scimMember := models.SCIMMember{Value: strconv.FormatUint(uint64(member.UserID), 10)}
+		if member.User != nil {
+			scimMember.Display = member.User.Name
+		}
+		group.Members = append(group.Members, scimMember)
+	}
+	return group
+}
+
+// GetGroups lists teams, optionally filtered by displayName
+func (s *SCIMService) GetGroups(query *models.SCIMListQuery) (*models.SCIMListResponse, error) {
+	db := s.db.Model(&models.Teams{})
+	if query.Filter != "" {
+		column, value, err := parseSCIMFilter(query.Filter, map[string]string{
+			"id":          "id",
+			"displayname": "name",
+		})
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where(column+" = ?", value)
+	}
+
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	offset, count := listWindow(query)
+	resources := []*models.SCIMGroup{}
+	if count > 0 {
+		var teams []models.Teams
+		if err := db.Preload("Members.User").Order("id ASC").Offset(offset).Limit(count).Find(&teams).Error; err != nil {
+			return nil, err
+		}
+		for i := range teams {
+			resources = append(resources, toSCIMGroup(&teams[i]))
+		}
+	}
+
+	return &models.SCIMListResponse{
+		Schemas:      []string{models.SCIMSchemaListResponse},
+		TotalResults: total,
+		StartIndex:   query.StartIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}, nil
+}
+
+// findTeam loads the team a SCIM group ID refers to
+func (s *SCIMService) findTeam(id string) (*models.Teams, error) {
+	teamID, ok := parseSCIMID(id)
+	if !ok {
+		return nil, errors.New("team not found")
+	}
+	return s.teamService.GetTeamById(teamID)
+}
+
+// GetGroup returns a team as a SCIM group
+func (s *SCIMService) GetGroup(id string) (*models.SCIMGroup, error) {
+	team, err := s.findTeam(id)
+	if err != nil {
+		return nil, err
+	}
+	return toSCIMGroup(team), nil
+}
+
+// memberIDs parses the user IDs of SCIM group members
+func memberIDs(members []models.SCIMMember) ([]uint, error) {
+	ids := make([]uint, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseUint(member.Value, 10, 64)
+		if err != nil {
+			return nil, invalidSCIMValue("unknown member %q", member.Value)
+		}
+		ids = append(ids, uint(id))
+	}
+	return uniqueIDs(ids), nil
+}
+
+// setMembers adds and removes team members so the team has exactly the given users
+func (s *SCIMService) setMembers(team *models.Teams, userIDs []uint, actor models.ActivityActor) error {
+	wanted := make(map[uint]bool, len(userIDs))
+	for _, id := range userIDs {
+		wanted[id] = true
+	}
+
+	var affected []uint
+	current := map[uint]bool{}
+	for _, member := range team.Members {
+		current[member.UserID] = true
+		if !wanted[member.UserID] {
+			if _, err := s.teamService.RemoveTeamMember(team.ID, member.UserID); err != nil {
+				return err
+			}
+			affected = append(affected, member.UserID)
+		}
+	}
+	for _, id := range userIDs {
+		if current[id] {
+			continue
+		}
+		if _, err := s.teamService.AddTeamMember(team.ID, &models.AddTeamMemberRequest{UserID: id, Role: models.TeamRoleMember}); err != nil {
+			if err.Error() == "user not found" {
+				return invalidSCIMValue("unknown member %q", strconv.FormatUint(uint64(id), 10))
+			}
+			return err
+		}
+		affected = append(affected, id)
+	}
+
+	return s.syncRoles(affected, actor)
+}
+
+// syncRoles applies the group to role mapping: members of an admin group become admins and
+// everyone else becomes a regular user. Without configured admin groups roles are left alone.
+func (s *SCIMService) syncRoles(userIDs []uint, actor models.ActivityActor) error {
+	if len(s.adminGroups) == 0 || len(userIDs) == 0 {
+		return nil
+	}
+
+	groups := make([]string, 0, len(s.adminGroups))
+	for group := range s.adminGroups {
+		groups = append(groups, group)
+	}
+	var adminIDs []uint
+	err := s.db.Model(&models.TeamMembers{}).
+		Joins("JOIN teams ON teams.id = team_members.team_id AND teams.deleted_at IS NULL").
+		Where("team_members.user_id IN ? AND LOWER(teams.name) IN ?", userIDs, groups).
+		Distinct().Pluck("team_members.user_id", &adminIDs).Error
+	if err != nil {
+		return err
+	}
+
+	isAdmin := make(map[uint]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		isAdmin[id] = true
+	}
+	var regularIDs []uint
+	for _, id := range userIDs {
+		if !isAdmin[id] {
+			regularIDs = append(regularIDs, id)
+		}
+	}
+
+	// Users who already hold the role are skipped
+	if len(adminIDs) > 0 {
+		if _, err := s.userService.AssignRole(models.RoleAdmin, adminIDs, actor); err != nil {
+			return err
+		}
+	}
+	if len(regularIDs) > 0 {
+		if _, err := s.userService.AssignRole(models.RoleUser, regularIDs, actor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateGroup creates a team with the given members
+func (s *SCIMService) CreateGroup(in *models.SCIMGroup, actor models.ActivityActor) (*models.SCIMGroup, error) {
+	if strings.TrimSpace(in.DisplayName) == "" {
+		return nil, invalidSCIMValue("displayName is required")
+	}
+	ids, err := memberIDs(in.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	team, err := s.teamService.CreateTeam(&models.CreateTeamRequest{Name: in.DisplayName})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setMembers(team, ids, actor); err != nil {
+		return nil, err
+	}
+
+	return s.GetGroup(strconv.FormatUint(uint64(team.ID), 10))
+}
+
+// ReplaceGroup renames a team and replaces its members
+func (s *SCIMService) ReplaceGroup(id string, in *models.SCIMGroup, actor models.ActivityActor) (*models.SCIMGroup, error) {
+	if strings.TrimSpace(in.DisplayName) == "" {
+		return nil, invalidSCIMValue("displayName is required")
+	}
+	ids, err := memberIDs(in.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	team, err := s.findTeam(id)
+	if err != nil {
+		return nil, err
+	}
+	if team.Name != in.DisplayName {
+		if err := s.renameGroup(team, in.DisplayName, actor); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.setMembers(team, ids, actor); err != nil {
+		return nil, err
+	}
+
+	return s.GetGroup(id)
+}
+
+// renameGroup renames a team. The name decides whether the team is an admin group,
+// so its members' roles are mapped again.
+func (s *SCIMService) renameGroup(team *models.Teams, name string, actor models.ActivityActor) error {
+	if _, err := s.teamService.UpdateTeam(team.ID, &models.UpdateTeamRequest{Name: name, Description: team.Description}); err != nil {
+		return err
+	}
+	team.Name = name
+
+	members := make([]uint, len(team.Members))
+	for i, member := range team.Members {
+		members[i] = member.UserID
+	}
+	return s.syncRoles(members, actor)
+}
+
+// scimMemberFilterPattern matches the member paths of group patches: members[value eq "12"]
+var scimMemberFilterPattern = regexp.MustCompile(`(?i)^members\[\s*value\s+eq\s+"([^"]*)"\s*\]$`)
+
+// PatchGroup applies SCIM PATCH operations to a team's name and members
+func (s *SCIMService) PatchGroup(id string, req *models.SCIMPatchRequest, actor models.ActivityActor) (*models.SCIMGroup, error) {
+	team, err := s.findTeam(id)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]uint, 0, len(team.Members))
+	for _, member := range team.Members {
+		members = append(members, member.UserID)
+	}
+	name := team.Name
+
+	for _, operation := range req.Operations {
+		op := strings.ToLower(operation.Op)
+		path := strings.ToLower(operation.Path)
+
+		switch {
+		case op != "add" && op != "replace" && op != "remove":
+			return nil, invalidSCIMValue("unsupported patch operation %q", operation.Op)
+
+		case path == "" && op != "remove":
+			var values struct {
+				DisplayName string              `json:"displayName"`
+				Members     []models.SCIMMember `json:"members"`
+			}
+			if err := decodeSCIMValue(operation.Value, &values); err != nil {
+				return nil, invalidSCIMValue("invalid patch value")
+			}
+			if values.DisplayName != "" {
+				name = values.DisplayName
+			}
+			if values.Members != nil {
+				ids, err := memberIDs(values.Members)
+				if err != nil {
+					return nil, err
+				}
+				if op == "replace" {
+					members = ids
+				} else {
+					members = uniqueIDs(append(members, ids...))
+				}
+			}
+
+		case path == "displayname":
+			if op == "remove" {
+				return nil, &SCIMRequestError{ScimType: "mutability", Detail: "displayName cannot be removed"}
+			}
+			if name, err = scimString(operation.Path, operation.Value); err != nil {
+				return nil, err
+			}
+
+		case path == "members":
+			var values []models.SCIMMember
+			if operation.Value != nil {
+				if err := decodeSCIMValue(operation.Value, &values); err != nil {
+					return nil, invalidSCIMValue("members must be a list")
+				}
+			}
+			ids, err := memberIDs(values)
+			if err != nil {
+				return nil, err
+			}
+			switch op {
+			case "add":
+				members = uniqueIDs(append(members, ids...))
+			case "replace":
+				members = ids
+			case "remove":
+				// Without a value every member is removed
+				if operation.Value == nil {
+					members = nil
+				} else {
+					members = withoutIDs(members, ids)
+				}
+			}
+
+		case op == "remove" && scimMemberFilterPattern.MatchString(operation.Path):
+			ids, err := memberIDs([]models.SCIMMember{{Value: scimMemberFilterPattern.FindStringSubmatch(operation.Path)[1]}})
+			if err != nil {
+				return nil, err
+			}
+			members = withoutIDs(members, ids)
+
+		default:
+			return nil, &SCIMRequestError{ScimType: "invalidPath", Detail: fmt.Sprintf("patching %s is not supported", operation.Path)}
+		}
+	}
+
+	if strings.TrimSpace(name) == "" {
+		return nil, invalidSCIMValue("displayName is required")
+	}
+	if name != team.Name {
+		if err := s.renameGroup(team, name, actor); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.setMembers(team, members, actor); err != nil {
+		return nil, err
+	}
+
+	return s.GetGroup(id)
+}
+
+// withoutIDs returns ids minus the removed ones
+func withoutIDs(ids []uint, removed []uint) []uint {
+	drop := make(map[uint]bool, len(removed))
+	for _, id := range removed {
+		drop[id] = true
+	}
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !drop[id] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// DeleteGroup deletes a team; its former members' roles are mapped again
+func (s *SCIMService) DeleteGroup(id string, actor models.ActivityActor) error {
+	team, err := s.findTeam(id)
+	if err != nil {
+		return err
+	}
+	members := make([]uint, len(team.Members))
+	for i, member := range team.Members {
+		members[i] = member.UserID
+	}
+
+	if _, err := s.teamService.DeleteTeam(team.ID); err != nil {
+		return err
+	}
+	return s.syncRoles(members, actor)
+}
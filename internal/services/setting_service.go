@@ -0,0 +1,415 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// settingCacheTTL bounds how long a setting lookup is served from Redis
+const settingCacheTTL = 10 * time.Minute
+
+var settingKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_.]{0,99}$`)
+
+// SettingService stores typed business settings per scope and keeps a history of every change.
+// Lookups are cached in Redis per key and scope; every write invalidates the entry it touches.
+type SettingService struct {
+	db      *gorm.DB
+	cache   *cache.Cache
+	changes *ChangeFeedService
+}
+
+func NewSettingService(db *gorm.DB, cache *cache.Cache, changes *ChangeFeedService) *SettingService {
+	return &SettingService{
+		db:      db,
+		cache:   cache,
+		changes: changes,
+	}
+}
+
+// validateSettingValue checks that the value matches the setting type
+func validateSettingValue(settingType string, value models.JSONValue) error {
+	switch v := value.Data.(type) {
+	case string:
+		if settingType == models.SettingTypeString {
+			return nil
+		}
+	case float64:
+		if settingType == models.SettingTypeNumber {
+			return nil
+		}
+		if settingType == models.SettingTypeInt && v == math.Trunc(v) {
+			return nil
+		}
+	case bool:
+		if settingType == models.SettingTypeBool {
+			return nil
+		}
+	case map[string]interface{}, []interface{}:
+		if settingType == models.SettingTypeJSON {
+			return nil
+		}
+	}
+	return errors.New("value does not match the setting type")
+}
+
+// recordSettingChange stores a history entry for a setting as part of a transaction
+func recordSettingChange(tx *gorm.DB, setting *models.Settings, action string, oldValue *models.JSONValue, actor models.ActivityActor) error {
+	change := &models.SettingChanges{
+		SettingID: setting.ID,
+		Action:    action,
+		Version:   setting.Version,
+		OldValue:  oldValue,
+		ActorID:   actorID(actor),
+	}
+	if action != models.SettingActionDeleted {
+		value := setting.Value
+		change.NewValue = &value
+	}
+	return tx.Create(change).Error
+}
+
+// settingChanged drops the cached lookup of a setting and announces the change on the change feed
+func (s *SettingService) settingChanged(setting *models.Settings, action string) {
+	if err := s.cache.Delete(context.Background(), cache.SettingKey(setting.Scope, setting.ScopeID, setting.Key)); err != nil {
+		slog.Warn("Failed to invalidate cached setting", "key", setting.Key, "scope", setting.Scope, "scope_id", setting.ScopeID, "error", err)
+	}
+	s.changes.Publish(models.ChangeEntitySetting, action, setting.ID)
+}
+
+// checkScope makes sure the scope ID fits the scope
+func (s *SettingService) checkScope(scope string, scopeID string) error {
+	switch scope {
+	case models.SettingScopeGlobal:
+		if scopeID != "" {
+			return errors.New("global settings have no scope id")
+		}
+	case models.SettingScopeTenant:
+		var tenant models.Tenants
+		if err := s.db.Where("id = ?", scopeID).First(&tenant).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("tenant not found")
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAllSettings retrieves settings with pagination, search, and scope filters
+func (s *SettingService) GetAllSettings(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.Settings{},
+		SearchFields: []string{"key", "description"},
+		FilterFields: map[string]string{
+			"key":      "key",
+			"type":     "type",
+			"scope":    "scope",
+			"scope_id": "scope_id",
+		},
+		SortFields: []string{
+			"key",
+			"scope",
+			"updated_at",
+		},
+		DefaultSort:  "key",
+		DefaultOrder: "ASC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetSettingById retrieves a setting by ID
+func (s *SettingService) GetSettingById(id uint) (*models.Settings, error) {
+	var setting models.Settings
+	if err := s.db.Where("id = ?", id).First(&setting).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("setting not found")
+		}
+		return nil, err
+	}
+	return &setting, nil
+}
+
+// CreateSetting creates a setting. A previously deleted setting with the same key and scope
+// is brought back with the new value, so its history continues.
+func (s *SettingService) CreateSetting(req *models.CreateSettingRequest, actor models.ActivityActor) (*models.Settings, error) {
+	if !settingKeyPattern.MatchString(req.Key) {
+		return nil, errors.New("invalid setting key")
+	}
+	if err := validateSettingValue(req.Type, req.Value); err != nil {
+		return nil, err
+	}
+	if req.Scope == models.SettingScopeGlobal {
+		req.ScopeID = ""
+	}
+	if err := s.checkScope(req.Scope, req.ScopeID); err != nil {
+		return nil, err
+	}
+
+	var setting models.Settings
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("key = ? AND scope = ? AND scope_id = ?", req.Key, req.Scope, req.ScopeID).First(&setting).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			setting = models.Settings{
+				Key:         req.Key,
+				Type:        req.Type,
+				Scope:       req.Scope,
+				ScopeID:     req.ScopeID,
+				Value:       req.Value,
+				Description: req.Description,
+				Version:     1,
+				UpdatedByID: actorID(actor),
+			}
+			if err := tx.Create(&setting).Error; err != nil {
+				return err
+			}
+			return recordSettingChange(tx, &setting, models.SettingActionCreated, nil, actor)
+
+		case err != nil:
+			return err
+
+		case !setting.DeletedAt.Valid:
+			return errors.New("setting already exists")
+		}
+
+		setting.Type = req.Type
+		setting.Value = req.Value
+		setting.Description = req.Description
+		setting.Version++
+		setting.DeletedAt = gorm.DeletedAt{}
+		setting.UpdatedByID = actorID(actor)
+		if err := tx.Unscoped().Save(&setting).Error; err != nil {
+			return err
+		}
+		return recordSettingChange(tx, &setting, models.SettingActionRestored, nil, actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.settingChanged(&setting, models.ChangeActionCreated)
+	return &setting, nil
+}
+
+// UpdateSetting changes a setting's value. The value must keep the setting's type.
+func (s *SettingService) UpdateSetting(id uint, req *models.UpdateSettingRequest, actor models.ActivityActor) (*models.Settings, error) {
+	var setting models.Settings
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", id).First(&setting).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("setting not found")
+			}
+			return err
+		}
+		if req.Version != 0 && req.Version != setting.Version {
+			return errors.New("setting version mismatch")
+		}
+		if err := validateSettingValue(setting.Type, req.Value); err != nil {
+			return err
+		}
+
+		oldValue := setting.Value
+		result := tx.Model(&models.Settings{}).
+			Where("id = ? AND version = ?", setting.ID, setting.Version).
+			Updates(map[string]interface{}{
+				"value":         req.Value,
+				"description":   req.Description,
+				"version":       setting.Version + 1,
+				"updated_by_id": actorID(actor),
+				"updated_at":    time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("setting version mismatch")
+		}
+
+		setting.Value = req.Value
+		setting.Description = req.Description
+		setting.Version++
+		setting.UpdatedByID = actorID(actor)
+		return recordSettingChange(tx, &setting, models.SettingActionUpdated, &oldValue, actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.settingChanged(&setting, models.ChangeActionUpdated)
+	return &setting, nil
+}
+
+// DeleteSetting soft-deletes a setting; lookups fall back to the next broader scope
+func (s *SettingService) DeleteSetting(id uint, actor models.ActivityActor) (*models.Settings, error) {
+	var setting models.Settings
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", id).First(&setting).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("setting not found")
+			}
+			return err
+		}
+
+		oldValue := setting.Value
+		setting.Version++
+		setting.UpdatedByID = actorID(actor)
+		if err := tx.Model(&setting).Updates(map[string]interface{}{
+			"version":       setting.Version,
+			"updated_by_id": setting.UpdatedByID,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&setting).Error; err != nil {
+			return err
+		}
+		return recordSettingChange(tx, &setting, models.SettingActionDeleted, &oldValue, actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.settingChanged(&setting, models.ChangeActionDeleted)
+	return &setting, nil
+}
+
+// GetSettingHistory retrieves the changes of a setting, newest first. Deleted settings keep their history.
+func (s *SettingService) GetSettingHistory(id uint, params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	var setting models.Settings
+	if err := s.db.Unscoped().Where("id = ?", id).First(&setting).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("setting not found")
+		}
+		return nil, err
+	}
+
+	config := pagination.PaginationConfig{
+		Model: &models.SettingChanges{},
+		BaseCondition: map[string]interface{}{
+			"setting_id": setting.ID,
+		},
+		FilterFields: map[string]string{
+			"action":   "action",
+			"actor_id": "actor_id",
+		},
+		DateFields: map[string]pagination.DateField{
+			"created_at": {
+				Start: "created_at",
+				End:   "created_at",
+			},
+		},
+		SortFields: []string{
+			"version",
+			"created_at",
+		},
+		DefaultSort:  "version",
+		DefaultOrder: "DESC",
+	}
+
+	if params.SortBy == "" {
+		params.SortDesc = true
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// lookup returns the setting stored for one key and scope, or nil when there is none.
+// Both hits and misses are cached.
+func (s *SettingService) lookup(ctx context.Context, key string, scope string, scopeID string) (*models.Settings, error) {
+	cacheKey := cache.SettingKey(scope, scopeID, key)
+	if data, err := s.cache.Get(ctx, cacheKey); err == nil {
+		var setting *models.Settings
+		if err := json.Unmarshal(data, &setting); err == nil {
+			if s.cache.Sample() {
+				if fresh, err := s.load(key, scope, scopeID); err == nil {
+					if freshData, err := json.Marshal(fresh); err == nil {
+						s.cache.Verify(cacheKey, data, freshData)
+					}
+				}
+			}
+			return setting, nil
+		}
+	}
+
+	setting, err := s.load(key, scope, scopeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(setting); err == nil {
+		if err := s.cache.Set(ctx, cacheKey, data, settingCacheTTL); err != nil && !errors.Is(err, cache.ErrUnavailable) {
+			slog.WarnContext(ctx, "Failed to cache setting", "key", key, "scope", scope, "scope_id", scopeID, "error", err)
+		}
+	}
+	return setting, nil
+}
+
+// load reads a setting from the database, returning nil when it is not set
+func (s *SettingService) load(key string, scope string, scopeID string) (*models.Settings, error) {
+	var setting models.Settings
+	if err := s.db.Where("key = ? AND scope = ? AND scope_id = ?", key, scope, scopeID).First(&setting).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &setting, nil
+}
+
+// Effective resolves a setting for a tenant and location: the location value wins over the
+// tenant's, which wins over the global one. A tenant ID of 0 or an empty location skips that scope.
+func (s *SettingService) Effective(ctx context.Context, key string, tenantID uint, location string) (*models.EffectiveSetting, error) {
+	type candidate struct {
+		scope   string
+		scopeID string
+	}
+	candidates := make([]candidate, 0, 3)
+	if location != "" {
+		candidates = append(candidates, candidate{models.SettingScopeLocation, location})
+	}
+	if tenantID != 0 {
+		candidates = append(candidates, candidate{models.SettingScopeTenant, strconv.FormatUint(uint64(tenantID), 10)})
+	}
+	candidates = append(candidates, candidate{models.SettingScopeGlobal, ""})
+
+	for _, c := range candidates {
+		setting, err := s.lookup(ctx, key, c.scope, c.scopeID)
+		if err != nil {
+			return nil, err
+		}
+		if setting != nil {
+			return &models.EffectiveSetting{
+				Key:     setting.Key,
+				Type:    setting.Type,
+				Value:   setting.Value,
+				Scope:   setting.Scope,
+				ScopeID: setting.ScopeID,
+				Version: setting.Version,
+			}, nil
+		}
+	}
+
+	return nil, errors.New("setting not found")
+}
+
+// actorID returns the acting user's ID, or nil for system changes
+func actorID(actor models.ActivityActor) *uint {
+	if actor.UserID == 0 {
+		return nil
+	}
+	id := actor.UserID
+	return &id
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/payments"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ShiftService opens and closes registers. A shift ties a cashier to a terminal and owns the
+// cashier's cash drawer while it is open; orders rung up at the terminal and payments the
+// cashier takes are recorded against it.
+type ShiftService struct {
+	db              *gorm.DB
+	cashDrawers     *CashDrawerService
+	activityService *ActivityService
+}
+
+func NewShiftService(db *gorm.DB, cashDrawers *CashDrawerService, activityService *ActivityService) *ShiftService {
+	return &ShiftService{
+		db:              db,
+		cashDrawers:     cashDrawers,
+		activityService: activityService,
+	}
+}
+
+// GetAllShifts retrieves shifts with pagination
+func (s *ShiftService) GetAllShifts(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model: &models.Shifts{},
+		FilterFields: map[string]string{
+			"status":    "status",
+			"user_id":   "user_id",
+			"device_id": "device_id",
+			"location":  "location",
+			"store_id":  "store_id",
+		},
+		StoreFilter: "store_id = ?",
+		DateFields: map[string]pagination.DateField{
+			"opened_at": {
+				Start: "opened_at",
+				End:   "opened_at",
+			},
+		},
+		SortFields: []string{
+			"opened_at",
+			"closed_at",
+		},
+		DefaultSort:  "opened_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// shiftReport sums up a shift. Closed shifts report the totals they were closed with.
+func shiftReport(db *gorm.DB, shift *models.Shifts) (*models.ShiftReport, error) {
+	report := &models.ShiftReport{
+		Shift:  *shift,
+		Totals: shift.Totals,
+	}
+
+	var sales struct {
+		OrderCount int64
+		Sales      int64
+	}
+	if err := db.Model(&models.Orders{}).
+		Select("COUNT(*) AS order_count, COALESCE(SUM(total), 0) AS sales").
+		Where("shift_id = ? AND status NOT IN ?", shift.ID, []string{models.OrderStatusDraft, models.OrderStatusCancelled}).
+		Scan(&sales).Error; err != nil {
+		return nil, err
+	}
+	report.OrderCount = sales.OrderCount
+	report.Sales = sales.Sales
+
+	if shift.CashDrawerSessionID != nil {
+		var session models.CashDrawerSessions
+		if err := db.Where("id = ?", *shift.CashDrawerSessionID).First(&session).Error; err != nil {
+			return nil, err
+		}
+		drawer, err := loadDrawerReport(db, &session)
+		if err != nil {
+			return nil, err
+		}
+		report.Drawer = drawer
+	}
+
+	if shift.Status == models.ShiftStatusOpen {
+		totals, err := expectedShiftTotals(db, shift, report.Drawer)
+		if err != nil {
+			return nil, err
+		}
+		report.Totals = totals
+	}
+	return report, nil
+}
+
+// expectedShiftTotals works out what a shift took per payment method: the cash its drawer
+// should hold, and what payments of other methods add up to after refunds. Cash comes first.
+func expectedShiftTotals(db *gorm.DB, shift *models.Shifts, drawer *models.CashDrawerReport) (models.ShiftTotals, error) {
+	var rows []struct {
+		Method string
+		Amount int64
+	}
+	if err := db.Model(&models.Payments{}).
+		Select("method, COALESCE(SUM(amount - refunded_amount), 0) AS amount").
+		Where("shift_id = ? AND method <> ? AND status IN ?", shift.ID, payments.MethodCash, receiptPaymentStatuses).
+		Group("method").Order("method").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	cash := shift.OpeningFloat
+	if drawer != nil {
+		cash = drawer.Expected
+	}
+	totals := models.ShiftTotals{{Method: payments.MethodCash, Expected: cash}}
+	for _, row := range rows {
+		totals = append(totals, models.ShiftTotal{Method: row.Method, Expected: row.Amount})
+	}
+	return totals, nil
+}
+
+// GetShiftReport retrieves a shift with what it took
+func (s *ShiftService) GetShiftReport(id uint) (*models.ShiftReport, error) {
+	var shift models.Shifts
+	if err := s.db.Where("id = ?", id).First(&shift).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("shift not found")
+		}
+		return nil, err
+	}
+	return shiftReport(s.db, &shift)
+}
+
+// GetCurrentShift retrieves the report of the shift a user has open
+func (s *ShiftService) GetCurrentShift(userID uint) (*models.ShiftReport, error) {
+	var shift models.Shifts
+	if err := s.db.Where("user_id = ? AND status = ?", userID, models.ShiftStatusOpen).First(&shift).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("no open shift")
+		}
+		return nil, err
+	}
+	return shiftReport(s.db, &shift)
+}
+
+// OpenShift opens a shift for the acting user on a terminal: the one the request comes from,
+// or else the one in the request. The cashier's cash drawer is opened with the float.
+func (s *ShiftService) OpenShift(req *models.OpenShiftRequest, terminal *models.Devices, actor models.ActivityActor) (*models.Shifts, error) {
+	deviceID := req.DeviceID
+	if terminal != nil {
+		deviceID = &terminal.ID
+	}
+	if deviceID == nil {
+		return nil, errors.New("device required")
+	}
+
+	var shift models.Shifts
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		device, err := activeDevice(tx, *deviceID)
+		if err != nil {
+			return err
+		}
+
+		var open int64
+		if err := tx.Model(&models.Shifts{}).
+			Where("status = ? AND (device_id = ? OR user_id = ?)", models.ShiftStatusOpen, device.ID, actor.UserID).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return errors.New("shift already open")
+		}
+
+		session, err := s.cashDrawers.openDrawerTx(tx, &models.OpenCashDrawerRequest{
+			DeviceID:     &device.ID,
+			OpeningFloat: req.OpeningFloat,
+			Note:         req.Note,
+		}, actor)
+		if err != nil {
+			return err
+		}
+
+		shift = models.Shifts{
+			UserID:              actorID(actor),
+			DeviceID:            device.ID,
+			Location:            device.Location,
+			StoreID:             device.StoreID,
+			Status:              models.ShiftStatusOpen,
+			OpeningFloat:        req.OpeningFloat,
+			CashDrawerSessionID: &session.ID,
+			OpeningNote:         req.Note,
+			OpenedAt:            time.Now(),
+		}
+		if err := tx.Create(&shift).Error; err != nil {
+			return err
+		}
+
+		return s.activityService.RecordTx(tx, actor.UserID, models.ActivityShiftOpened, actor, "Shift opened", models.JSONMap{
+			"shift_id":      shift.ID,
+			"device_id":     shift.DeviceID,
+			"location":      shift.Location,
+			"opening_float": shift.OpeningFloat,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &shift, nil
+}
+
+// CloseShift closes the acting user's shift with what was counted per payment method. The
+// shift's cash drawer is closed with the counted cash; methods the shift took nothing with
+// may be counted too, e.g. to report a card batch that should have been empty.
+func (s *ShiftService) CloseShift(id uint, req *models.CloseShiftRequest, actor models.ActivityActor) (*models.ShiftReport, error) {
+	counted, ok := req.Counted[payments.MethodCash]
+	if !ok {
+		return nil, errors.New("counted cash required")
+	}
+
+	var report *models.ShiftReport
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var shift models.Shifts
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&shift).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("shift not found")
+			}
+			return err
+		}
+		if shift.UserID == nil || *shift.UserID != actor.UserID {
+			return errors.New("shift belongs to another user")
+		}
+		if shift.Status != models.ShiftStatusOpen {
+			return errors.New("shift closed")
+		}
+
+		var drawer *models.CashDrawerReport
+		if shift.CashDrawerSessionID != nil {
+			var session models.CashDrawerSessions
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", *shift.CashDrawerSessionID).First(&session).Error; err != nil {
+				return err
+			}
+			if session.Status == models.CashDrawerStatusOpen {
+				var err error
+				if drawer, err = s.cashDrawers.closeDrawerTx(tx, &session, counted, req.Note, actor); err != nil {
+					return err
+				}
+			}
+		}
+
+		totals, err := expectedShiftTotals(tx, &shift, drawer)
+		if err != nil {
+			return err
+		}
+		methods := make([]string, 0, len(req.Counted))
+		for method := range req.Counted {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			i := 0
+			for i < len(totals) && totals[i].Method != method {
+				i++
+			}
+			if i == len(totals) {
+				totals = append(totals, models.ShiftTotal{Method: method})
+			}
+			amount := req.Counted[method]
+			variance := amount - totals[i].Expected
+			totals[i].Counted = &amount
+			totals[i].Variance = &variance
+		}
+
+		now := time.Now()
+		if err := tx.Model(&shift).Updates(map[string]interface{}{
+			"status":       models.ShiftStatusClosed,
+			"totals":       totals,
+			"closing_note": req.Note,
+			"closed_by_id": actorID(actor),
+			"closed_at":    now,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", shift.ID).First(&shift).Error; err != nil {
+			return err
+		}
+
+		report, err = shiftReport(tx, &shift)
+		if err != nil {
+			return err
+		}
+		return s.activityService.RecordTx(tx, actor.UserID, models.ActivityShiftClosed, actor, "Shift closed", models.JSONMap{
+			"shift_id": shift.ID,
+			"orders":   report.OrderCount,
+			"sales":    report.Sales,
+			"totals":   totals,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// terminalShiftTx returns the open shift of a terminal, locked so it can't close until the
+// caller's transaction ends. Terminals don't record sales without one.
+func terminalShiftTx(tx *gorm.DB, deviceID uint) (*models.Shifts, error) {
+	var shift models.Shifts
+	err := tx.Clauses(clause.Locking{Strength: "SHARE"}).
+		Where("device_id = ? AND status = ?", deviceID, models.ShiftStatusOpen).
+		First(&shift).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("no open shift")
+		}
+		return nil, err
+	}
+	return &shift, nil
+}
+
+// userShiftIDTx returns the ID of the shift a user has open, locked like terminalShiftTx, or
+// nil when there is none
+func userShiftIDTx(tx *gorm.DB, userID uint) (*uint, error) {
+	if userID == 0 {
+		return nil, nil
+	}
+	var shift models.Shifts
+	err := tx.Clauses(clause.Locking{Strength: "SHARE"}).
+		Where("user_id = ? AND status = ?", userID, models.ShiftStatusOpen).
+		First(&shift).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &shift.ID, nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/config"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/mailer"
+	"github.com/Aebroyx/the-blade-api/internal/slo"
+	"gorm.io/gorm"
+)
+
+// SLOService reports the response time objectives tracked by this API instance and alerts
+// administrators when a route group uses up its error budget too fast
+type SLOService struct {
+	db            *gorm.DB
+	config        *config.Config
+	tracker       *slo.Tracker
+	notifications *NotificationService
+}
+
+func NewSLOService(db *gorm.DB, config *config.Config, tracker *slo.Tracker, notifications *NotificationService) *SLOService {
+	return &SLOService{
+		db:            db,
+		config:        config,
+		tracker:       tracker,
+		notifications: notifications,
+	}
+}
+
+// GetReport returns the state of every objective
+func (s *SLOService) GetReport() slo.Report {
+	return s.tracker.Report()
+}
+
+// CheckBurnRates alerts administrators about objectives that started burning their error budget
+// too fast. It is the entry point of the background SLO job; each objective is alerted about once
+// until it recovers.
+func (s *SLOService) CheckBurnRates(ctx context.Context) error {
+	alerts := s.tracker.Check()
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	var admins []models.Users
+	if err := s.db.WithContext(ctx).Where("role = ? AND is_deleted = ? AND status = ?", models.RoleAdmin, false, models.UserStatusActive).
+		Order("id ASC").
+		Find(&admins).Error; err != nil {
+		return err
+	}
+	slog.WarnContext(ctx, "Alerting administrators about route groups burning their response time budget", "administrators", len(admins), "route_groups", len(alerts))
+
+	title, body := sloAlertMessage(alerts, s.config.SLOWindow)
+	data := models.JSONMap{"objectives": alerts}
+	for _, admin := range admins {
+		if err := s.notifications.SendInApp(admin.ID, models.NotificationEventSLOAlerts, title, body, data); err != nil {
+			slog.ErrorContext(ctx, "Failed to add SLO notification", "user_id", admin.ID, "error", err)
+		}
+
+		message := mailer.Message{
+			To:      admin.Email,
+			Subject: title,
+			Body:    fmt.Sprintf("Hi %s,\n\n%s", admin.Name, body),
+		}
+		if err := s.notifications.SendEmail(admin.ID, models.NotificationEventSLOAlerts, message); err != nil {
+			slog.ErrorContext(ctx, "Failed to send SLO email", "user_id", admin.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// sloAlertMessage describes the objectives in a notification title and body
+func sloAlertMessage(alerts []slo.Status, window time.Duration) (string, string) {
+	title := fmt.Sprintf("%d route groups are responding too slowly", len(alerts))
+	if len(alerts) == 1 {
+		title = fmt.Sprintf("%s is responding too slowly", alerts[0].Route)
+	}
+
+	lines := make([]string, len(alerts))
+	for i, alert := range alerts {
+		lines[i] = fmt.Sprintf("- %s: %d of %d requests slower than %s over the last %s, burning the error budget %.1fx as fast as it allows",
+			alert.Route, alert.Long.Slow, alert.Long.Requests, alert.Threshold, window, alert.Long.BurnRate)
+	}
+	return title, "The following route groups are missing their response time objective:\n\n" + strings.Join(lines, "\n")
+}
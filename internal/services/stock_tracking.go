@@ -0,0 +1,577 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// stockBatchUpsertSQL adds a quantity to a batch, creating it on first receipt. A batch coming
+// in without an expiry date keeps the one it has, or takes the one it has at other stores. The
+// conflict target is the index added by migrateStockBatches.
+const stockBatchUpsertSQL = `INSERT INTO stock_batches (product_id, variant_id, store_id, batch, quantity, expires_at, received_at, updated_at)
+VALUES (?, ?, ?, ?, ?, COALESCE(?, (SELECT MAX(expires_at) FROM stock_batches WHERE product_id = ? AND batch = ?)), NOW(), NOW())
+ON CONFLICT (product_id, (COALESCE(variant_id, 0)), (COALESCE(store_id, 0)), batch)
+DO UPDATE SET quantity = stock_batches.quantity + EXCLUDED.quantity,
+	expires_at = COALESCE(EXCLUDED.expires_at, stock_batches.expires_at),
+	updated_at = EXCLUDED.updated_at`
+
+// trackedMovementTypes are the movements that have to name the units of tracked products they
+// move. Other movements, such as stocktake corrections, may leave them out and only change the
+// stock level.
+var trackedMovementTypes = []string{
+	models.StockMovementSale,
+	models.StockMovementPurchase,
+	models.StockMovementTransfer,
+}
+
+// soldOrderStatuses are the statuses of orders whose goods count as sold
+var soldOrderStatuses = []string{
+	models.OrderStatusPlaced,
+	models.OrderStatusPaid,
+	models.OrderStatusFulfilled,
+	models.OrderStatusCompleted,
+}
+
+// productTracking returns how the products are tracked, for those that are
+func productTracking(tx *gorm.DB, productIDs []uint) (map[uint]string, error) {
+	var products []models.Products
+	if err := tx.Select("id", "tracking").Where("id IN ? AND tracking <> ''", uniqueIDs(productIDs)).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	tracking := make(map[uint]string, len(products))
+	for _, product := range products {
+		tracking[product.ID] = product.Tracking
+	}
+	return tracking, nil
+}
+
+// checkTracking makes sure movements name the units of tracked products they move: a serial per
+// unit of serialized products and the batch of batch-tracked products. Untracked products can't
+// be given either.
+func (s *InventoryService) checkTracking(tx *gorm.DB, inputs []models.StockMovementInput) error {
+	productIDs := make([]uint, 0, len(inputs))
+	for _, input := range inputs {
+		productIDs = append(productIDs, input.ProductID)
+	}
+	tracking, err := productTracking(tx, productIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, input := range inputs {
+		required := slices.Contains(trackedMovementTypes, input.Type)
+		switch tracking[input.ProductID] {
+		case models.ProductTrackingSerial:
+			if input.Batch != "" {
+				return errors.New("product not batch tracked")
+			}
+			if len(input.Serials) == 0 {
+				if required {
+					return errors.New("serials required")
+				}
+				continue
+			}
+			if int64(len(input.Serials)) != abs(input.Quantity) {
+				return errors.New("serials don't match quantity")
+			}
+			seen := make(map[string]bool, len(input.Serials))
+			for _, serial := range input.Serials {
+				if seen[serial] {
+					return errors.New("duplicate serial")
+				}
+				seen[serial] = true
+			}
+		case models.ProductTrackingBatch:
+			if len(input.Serials) > 0 {
+				return errors.New("product not serialized")
+			}
+			if input.Batch == "" && required {
+				return errors.New("batch required")
+			}
+		default:
+			if len(input.Serials) > 0 {
+				return errors.New("product not serialized")
+			}
+			if input.Batch != "" {
+				return errors.New("product not batch tracked")
+			}
+		}
+	}
+	return nil
+}
+
+// abs returns the magnitude of a quantity
+func abs(quantity int64) int64 {
+	if quantity < 0 {
+		return -quantity
+	}
+	return quantity
+}
+
+// trackMovement moves the serials or batch named by a movement checked with checkTracking
+func (s *InventoryService) trackMovement(tx *gorm.DB, input models.StockMovementInput) error {
+	if len(input.Serials) > 0 {
+		return s.moveSerials(tx, input)
+	}
+	if input.Batch != "" {
+		return s.moveBatch(tx, input)
+	}
+	return nil
+}
+
+// moveSerials moves the units of a serialized product. Units leave a store by being sold,
+// written off or sent on a transfer, and come in by being received, returned or found; the
+// receiving leg of a transfer moves units still in stock at the source.
+func (s *InventoryService) moveSerials(tx *gorm.DB, input models.StockMovementInput) error {
+	var existing []models.StockSerials
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND serial IN ?", input.ProductID, input.Serials).
+		Order("id").Find(&existing).Error; err != nil {
+		return err
+	}
+	bySerial := make(map[string]*models.StockSerials, len(existing))
+	for i := range existing {
+		bySerial[existing[i].Serial] = &existing[i]
+	}
+
+	now := time.Now()
+	for _, serial := range input.Serials {
+		unit, ok := bySerial[serial]
+		inStock := ok && unit.Status == models.SerialStatusInStock && sameID(unit.VariantID, input.VariantID)
+
+		if input.Quantity < 0 {
+			if !inStock || !sameID(unit.StoreID, input.StoreID) {
+				return errors.New("serial not in stock")
+			}
+			columns := map[string]interface{}{"last_moved_at": now}
+			switch input.Type {
+			case models.StockMovementTransfer:
+				// The receiving leg moves the unit to its new store
+				continue
+			case models.StockMovementSale:
+				columns["status"] = models.SerialStatusSold
+				columns["order_id"] = input.OrderID
+				columns["sold_at"] = now
+			default:
+				columns["status"] = models.SerialStatusRemoved
+			}
+			if err := tx.Model(unit).Updates(columns).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch {
+		case input.Type == models.StockMovementTransfer:
+			if !inStock {
+				return errors.New("serial not in stock")
+			}
+		case inStock:
+			return errors.New("serial already in stock")
+		case !ok:
+			unit := models.StockSerials{
+				ProductID:   input.ProductID,
+				VariantID:   input.VariantID,
+				Serial:      serial,
+				StoreID:     input.StoreID,
+				Status:      models.SerialStatusInStock,
+				ReceivedAt:  now,
+				LastMovedAt: now,
+			}
+			if err := tx.Create(&unit).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		if err := tx.Model(unit).Updates(map[string]interface{}{
+			"variant_id":    input.VariantID,
+			"store_id":      input.StoreID,
+			"status":        models.SerialStatusInStock,
+			"last_moved_at": now,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveBatch changes the quantity of a batch at a store. Stock can only be taken from batches
+// the store has received, though, like stock levels, they may go negative.
+func (s *InventoryService) moveBatch(tx *gorm.DB, input models.StockMovementInput) error {
+	if input.Quantity > 0 {
+		return tx.Exec(stockBatchUpsertSQL, input.ProductID, input.VariantID, input.StoreID, input.Batch, input.Quantity,
+			input.ExpiresAt, input.ProductID, input.Batch).Error
+	}
+
+	var variantID, storeID uint
+	if input.VariantID != nil {
+		variantID = *input.VariantID
+	}
+	if input.StoreID != nil {
+		storeID = *input.StoreID
+	}
+	result := tx.Model(&models.StockBatches{}).
+		Where("product_id = ? AND COALESCE(variant_id, 0) = ? AND COALESCE(store_id, 0) = ? AND batch = ?", input.ProductID, variantID, storeID, input.Batch).
+		Updates(map[string]interface{}{
+			"quantity":   gorm.Expr("quantity + ?", input.Quantity),
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("batch not in stock")
+	}
+	return nil
+}
+
+// sameID reports whether two optional IDs, such as a variant or store, are the same
+func sameID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// allocateOrderBatches picks the batches the lines of batch-tracked products are taken from
+// when an order is placed, and saves them on the lines
+func (s *InventoryService) allocateOrderBatches(tx *gorm.DB, order *models.Orders) error {
+	productIDs := make([]uint, 0, len(order.Items))
+	for _, item := range order.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	tracking, err := productTracking(tx, uniqueIDs(productIDs))
+	if err != nil {
+		return err
+	}
+
+	for i := range order.Items {
+		item := &order.Items[i]
+		if tracking[item.ProductID] != models.ProductTrackingBatch {
+			if item.Batch != "" {
+				return errors.New("product not batch tracked")
+			}
+			continue
+		}
+		allocations, err := s.allocateBatches(tx, item, order.StoreID)
+		if err != nil {
+			return err
+		}
+		item.Batches = allocations
+		if err := tx.Model(item).UpdateColumn("batches", item.Batches).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allocateBatches picks the batches at the store a line is taken from: the batch asked for, or
+// else the batches on hand that expire first. Expired batches are only sold when the expired
+// stock policy is to warn, flagged on the allocation, and only once fresher stock ran out. Stock
+// may go negative, so what the batches on hand don't cover is taken from the last one.
+func (s *InventoryService) allocateBatches(tx *gorm.DB, item *models.OrderItems, storeID *uint) (models.BatchAllocations, error) {
+	var variantID, store uint
+	if item.VariantID != nil {
+		variantID = *item.VariantID
+	}
+	if storeID != nil {
+		store = *storeID
+	}
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND COALESCE(variant_id, 0) = ? AND COALESCE(store_id, 0) = ?", item.ProductID, variantID, store)
+	now := time.Now()
+	warn := s.config.ExpiredStockPolicy == models.ExpiredStockWarn
+
+	if item.Batch != "" {
+		var batch models.StockBatches
+		if err := query.Where("batch = ?", item.Batch).First(&batch).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("batch not in stock")
+			}
+			return nil, err
+		}
+		expired := batchExpired(batch, now)
+		if expired && !warn {
+			return nil, errors.New("batch expired")
+		}
+		return models.BatchAllocations{{Batch: batch.Batch, Quantity: item.Quantity, Expired: expired}}, nil
+	}
+
+	var batches []models.StockBatches
+	if err := query.Where("quantity > 0").
+		Order("expires_at ASC NULLS LAST").Order("received_at").Order("id").
+		Find(&batches).Error; err != nil {
+		return nil, err
+	}
+
+	var allocations models.BatchAllocations
+	remaining := item.Quantity
+	sawExpired := false
+	for _, expired := range []bool{false, true} {
+		for _, batch := range batches {
+			if remaining == 0 || batchExpired(batch, now) != expired {
+				continue
+			}
+			if expired && !warn {
+				sawExpired = true
+				continue
+			}
+			quantity := min(remaining, batch.Quantity)
+			allocations = append(allocations, models.BatchAllocation{Batch: batch.Batch, Quantity: quantity, Expired: expired})
+			remaining -= quantity
+		}
+	}
+	if remaining > 0 {
+		if len(allocations) == 0 {
+			if sawExpired {
+				return nil, errors.New("batch expired")
+			}
+			return nil, errors.New("batch required")
+		}
+		allocations[len(allocations)-1].Quantity += remaining
+	}
+	return allocations, nil
+}
+
+// batchExpired reports whether a batch is past its expiry date
+func batchExpired(batch models.StockBatches, now time.Time) bool {
+	return batch.ExpiresAt != nil && !batch.ExpiresAt.After(now)
+}
+
+// ReceiveStock records goods received into the store's stock, e.g. a supplier delivery,
+// registering the serials and batches of tracked products. The movements and the audit entry
+// are written in one transaction.
+func (s *InventoryService) ReceiveStock(req *models.StockReceiptRequest, actor models.ActivityActor) ([]models.StockMovements, error) {
+	inputs := make([]models.StockMovementInput, len(req.Items))
+	items := make([]models.JSONMap, len(req.Items))
+	for i, item := range req.Items {
+		inputs[i] = models.StockMovementInput{
+			ProductID:   item.ProductID,
+			VariantID:   item.VariantID,
+			StoreID:     req.StoreID,
+			Type:        models.StockMovementPurchase,
+			Quantity:    item.Quantity,
+			Unit:        item.Unit,
+			Reference:   req.Reference,
+			Note:        req.Note,
+			Serials:     item.Serials,
+			Batch:       item.Batch,
+			ExpiresAt:   item.ExpiresAt,
+			CreatedByID: actorID(actor),
+		}
+		items[i] = models.JSONMap{
+			"product_id": item.ProductID,
+			"variant_id": item.VariantID,
+			"quantity":   item.Quantity,
+			"unit":       item.Unit,
+		}
+	}
+
+	var movements []models.StockMovements
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		movements, err = s.RecordMovements(tx, inputs)
+		if err != nil {
+			return err
+		}
+
+		return s.activityService.RecordTx(tx, actor.UserID, models.ActivityStockReceived, actor, "Stock received", models.JSONMap{
+			"reference": req.Reference,
+			"items":     items,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.stockChanged(inputs)
+	return movements, nil
+}
+
+// GetSerials retrieves the units of serialized products with pagination, search on serial
+// numbers and filters to locate them. Units of every store are listed, so a serial can be found
+// wherever it is.
+func (s *InventoryService) GetSerials(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.StockSerials{},
+		SearchFields: []string{"serial"},
+		FilterFields: map[string]string{
+			"serial":     "serial",
+			"product_id": "product_id",
+			"variant_id": "variant_id",
+			"store_id":   "store_id",
+			"status":     "status",
+			"order_id":   "order_id",
+		},
+		DateFields: map[string]pagination.DateField{
+			"received_at": {
+				Start: "received_at",
+				End:   "received_at",
+			},
+		},
+		SortFields: []string{
+			"serial",
+			"received_at",
+			"last_moved_at",
+		},
+		DefaultSort:  "serial",
+		DefaultOrder: "ASC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetBatches retrieves the batches of batch-tracked products on hand per store with pagination
+// and search on batch codes. Batches of every store are listed, so stock of a batch can be
+// found wherever it is.
+func (s *InventoryService) GetBatches(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.StockBatches{},
+		SearchFields: []string{"batch"},
+		FilterFields: map[string]string{
+			"batch":      "batch",
+			"product_id": "product_id",
+			"variant_id": "variant_id",
+			"store_id":   "store_id",
+		},
+		CustomFilters: map[string]string{
+			"on_hand": "(quantity > 0) = ?",
+		},
+		DateFields: map[string]pagination.DateField{
+			"expires_at": {
+				Start: "expires_at",
+				End:   "expires_at",
+			},
+		},
+		SortFields: []string{
+			"batch",
+			"quantity",
+			"expires_at",
+			"received_at",
+		},
+		DefaultSort:  "received_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetExpiringStock retrieves the batches on hand, of the store the request works in if any, that
+// expire within the given number of days or already have, soonest first
+func (s *InventoryService) GetExpiringStock(params pagination.QueryParams, days int) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model: &models.StockBatches{},
+		Conditions: []string{
+			"stock_batches.quantity > 0",
+			fmt.Sprintf("stock_batches.expires_at <= NOW() + INTERVAL '%d days'", days),
+		},
+		Joins: []pagination.JoinConfig{
+			{
+				Table:     "products",
+				Alias:     "p",
+				Type:      pagination.InnerJoin,
+				Condition: "p.id = stock_batches.product_id AND p.deleted_at IS NULL",
+			},
+			{
+				Table:     "product_variants",
+				Alias:     "pv",
+				Type:      pagination.LeftJoin,
+				Condition: "pv.id = stock_batches.variant_id",
+			},
+		},
+		SelectFields: []pagination.SelectField{
+			{Field: "stock_batches.id", Alias: "id"},
+			{Field: "stock_batches.product_id", Alias: "product_id"},
+			{Field: "stock_batches.variant_id", Alias: "variant_id"},
+			{Field: "stock_batches.store_id", Alias: "store_id"},
+			{Field: "stock_batches.batch", Alias: "batch"},
+			{Field: "stock_batches.quantity", Alias: "quantity"},
+			{Field: "stock_batches.expires_at", Alias: "expires_at"},
+			{Field: "stock_batches.expires_at <= NOW()", Alias: "expired"},
+			{Field: "p.name", Alias: "product_name"},
+			{Field: "pv.title", Alias: "variant_title"},
+			{Field: "COALESCE(pv.sku, p.sku)", Alias: "sku"},
+		},
+		ScanIntoMaps: true,
+		SearchFields: []string{"p.name", "p.sku", "pv.sku", "stock_batches.batch"},
+		FilterFields: map[string]string{
+			"product_id": "stock_batches.product_id",
+			"variant_id": "stock_batches.variant_id",
+			"store_id":   "stock_batches.store_id",
+		},
+		CustomFilters: map[string]string{
+			"expired":     "(stock_batches.expires_at <= NOW()) = ?",
+			"category_id": "p.category_id IN (" + categorySubtreeSQL + ")",
+		},
+		StoreFilter: "stock_batches.store_id = ?",
+		SortFields: []string{
+			"expires_at",
+			"product_name",
+			"quantity",
+		},
+		DefaultSort:  "expires_at",
+		DefaultOrder: "ASC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetBatchRecall reports where a batch of a product went, for recalling it: the stock of the
+// batch still on hand per store, and the orders that sold units of it with their customers.
+func (s *InventoryService) GetBatchRecall(query *models.BatchRecallQuery) (*models.BatchRecall, error) {
+	var product models.Products
+	if err := s.db.Unscoped().Select("id").Where("id = ?", query.ProductID).First(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+
+	recall := models.BatchRecall{
+		ProductID: query.ProductID,
+		Batch:     query.Batch,
+		Stock:     []models.StockBatches{},
+		Sales:     []models.BatchRecallSale{},
+	}
+	if err := s.db.Where("product_id = ? AND batch = ?", query.ProductID, query.Batch).
+		Order("store_id").Order("variant_id").Find(&recall.Stock).Error; err != nil {
+		return nil, err
+	}
+
+	err := s.db.Table("order_items AS oi").
+		Select(`o.id AS order_id, o.number, o.status, o.store_id, o.placed_at,
+			SUM(b.quantity - b.returned) AS quantity,
+			o.customer_id, c.name AS customer_name, c.email AS customer_email, c.phone AS customer_phone`).
+		Joins("CROSS JOIN LATERAL jsonb_to_recordset(oi.batches) AS b(batch text, quantity bigint, returned bigint)").
+		Joins("JOIN orders o ON o.id = oi.order_id").
+		Joins("LEFT JOIN customers c ON c.id = o.customer_id").
+		Where("oi.product_id = ? AND b.batch = ? AND o.status IN ?", query.ProductID, query.Batch, soldOrderStatuses).
+		Group("o.id, c.id").
+		Having("SUM(b.quantity - b.returned) > 0").
+		Order("o.placed_at").
+		Scan(&recall.Sales).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(recall.Stock) == 0 && len(recall.Sales) == 0 {
+		return nil, errors.New("batch not found")
+	}
+	for _, stock := range recall.Stock {
+		recall.OnHand += stock.Quantity
+	}
+	for _, sale := range recall.Sales {
+		recall.Sold += sale.Quantity
+	}
+	return &recall, nil
+}
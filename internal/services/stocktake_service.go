@@ -0,0 +1,445 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// stocktakeReportSQL compares the counted lines of a stocktake with the store's stock. Approved
+// lines keep the stock they were approved against; open ones read the current level.
+const stocktakeReportSQL = `SELECT si.product_id, si.variant_id, p.name AS product_name, pv.title AS variant_title,
+	COALESCE(pv.sku, p.sku) AS sku,
+	si.counted_quantity AS counted,
+	COALESCE(si.expected_quantity, sl.quantity, 0) AS expected
+FROM stocktake_items si
+JOIN products p ON p.id = si.product_id
+LEFT JOIN product_variants pv ON pv.id = si.variant_id
+LEFT JOIN stock_levels sl ON sl.product_id = si.product_id
+	AND COALESCE(sl.variant_id, 0) = COALESCE(si.variant_id, 0)
+	AND COALESCE(sl.store_id, 0) = ?
+WHERE si.stocktake_id = ?
+ORDER BY p.name, pv.title, si.id`
+
+// StocktakeService runs inventory counts. Counts are recorded against an open stocktake, by
+// quantity or by scanning barcodes, and approving it posts the differences to the movements
+// ledger as recount adjustments.
+type StocktakeService struct {
+	db        *gorm.DB
+	inventory *InventoryService
+}
+
+func NewStocktakeService(db *gorm.DB, inventory *InventoryService) *StocktakeService {
+	return &StocktakeService{
+		db:        db,
+		inventory: inventory,
+	}
+}
+
+// stocktakeNumber is the human-facing number of a stocktake
+func stocktakeNumber(id uint) string {
+	return fmt.Sprintf("ST-%06d", id)
+}
+
+// storeKey is the stock level store of a nullable store ID, 0 being the default store
+func storeKey(storeID *uint) uint {
+	if storeID == nil {
+		return 0
+	}
+	return *storeID
+}
+
+// GetAllStocktakes retrieves stocktakes with pagination and filters on status
+func (s *StocktakeService) GetAllStocktakes(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.Stocktakes{},
+		SearchFields: []string{"number", "note"},
+		FilterFields: map[string]string{
+			"status":        "status",
+			"store_id":      "store_id",
+			"created_by_id": "created_by_id",
+		},
+		StoreFilter: "store_id = ?",
+		DateFields: map[string]pagination.DateField{
+			"created_at": {
+				Start: "created_at",
+				End:   "created_at",
+			},
+			"approved_at": {
+				Start: "approved_at",
+				End:   "approved_at",
+			},
+		},
+		SortFields: []string{
+			"number",
+			"status",
+			"created_at",
+			"approved_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetStocktakeById retrieves a stocktake with its counted lines
+func (s *StocktakeService) GetStocktakeById(id uint) (*models.Stocktakes, error) {
+	var stocktake models.Stocktakes
+	if err := s.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("id = ?", id).First(&stocktake).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("stocktake not found")
+		}
+		return nil, err
+	}
+	return &stocktake, nil
+}
+
+// CreateStocktake opens a stocktake for a store. A store has one open stocktake at a time so
+// the same stock is never adjusted twice; several people count into it together.
+func (s *StocktakeService) CreateStocktake(req *models.CreateStocktakeRequest, actor models.ActivityActor) (*models.Stocktakes, error) {
+	stocktake := models.Stocktakes{
+		StoreID:     req.StoreID,
+		Status:      models.StocktakeStatusOpen,
+		Note:        req.Note,
+		CreatedByID: actorID(actor),
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if req.StoreID != nil {
+			// Lock the store so two stocktakes can not be opened for it at once
+			store, err := findStore(tx.Clauses(clause.Locking{Strength: "UPDATE"}), *req.StoreID)
+			if err != nil {
+				return err
+			}
+			if !store.Active {
+				return errors.New("store inactive")
+			}
+		}
+
+		var open int64
+		if err := tx.Model(&models.Stocktakes{}).
+			Where("COALESCE(store_id, 0) = ? AND status = ?", storeKey(req.StoreID), models.StocktakeStatusOpen).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return errors.New("stocktake already open")
+		}
+
+		if err := tx.Create(&stocktake).Error; err != nil {
+			return err
+		}
+		stocktake.Number = stocktakeNumber(stocktake.ID)
+		return tx.Model(&stocktake).UpdateColumn("number", stocktake.Number).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &stocktake, nil
+}
+
+// lockOpenStocktake locks an open stocktake with its lines for the rest of the transaction
+func lockOpenStocktake(tx *gorm.DB, id uint) (*models.Stocktakes, error) {
+	var stocktake models.Stocktakes
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").Where("id = ?", id).First(&stocktake).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("stocktake not found")
+		}
+		return nil, err
+	}
+	if stocktake.Status != models.StocktakeStatusOpen {
+		return nil, errors.New("stocktake closed")
+	}
+	return &stocktake, nil
+}
+
+// countItems adds counted quantities to the lines of a locked stocktake, or replaces them when
+// replace is set, and returns the lines it changed
+func (s *StocktakeService) countItems(tx *gorm.DB, stocktake *models.Stocktakes, counts []models.StocktakeCountItem, replace bool, actor models.ActivityActor) ([]models.StocktakeItems, error) {
+	lines := make(map[stockLevelKey]*models.StocktakeItems, len(stocktake.Items))
+	for i := range stocktake.Items {
+		item := &stocktake.Items[i]
+		lines[stockKey(models.StockMovementInput{ProductID: item.ProductID, VariantID: item.VariantID})] = item
+	}
+
+	changed := make([]models.StocktakeItems, 0, len(counts))
+	for _, count := range counts {
+		key := stockKey(models.StockMovementInput{ProductID: count.ProductID, VariantID: count.VariantID})
+		item, ok := lines[key]
+		if !ok {
+			item = &models.StocktakeItems{
+				StocktakeID: stocktake.ID,
+				ProductID:   count.ProductID,
+				VariantID:   count.VariantID,
+			}
+			lines[key] = item
+		}
+		if replace {
+			item.CountedQuantity = count.Quantity
+		} else {
+			item.CountedQuantity += count.Quantity
+		}
+		item.CountedByID = actorID(actor)
+		if err := tx.Save(item).Error; err != nil {
+			return nil, err
+		}
+		changed = append(changed, *item)
+	}
+
+	return changed, tx.Model(stocktake).UpdateColumn("updated_at", time.Now()).Error
+}
+
+// RecordCounts records counted quantities on an open stocktake. Each count replaces what was
+// counted before for its product or variant, so recounting a shelf corrects the line.
+func (s *StocktakeService) RecordCounts(id uint, req *models.StocktakeCountRequest, actor models.ActivityActor) (*models.Stocktakes, error) {
+	inputs := make([]models.StockMovementInput, len(req.Items))
+	seen := make(map[stockLevelKey]bool, len(req.Items))
+	for i, item := range req.Items {
+		inputs[i] = models.StockMovementInput{ProductID: item.ProductID, VariantID: item.VariantID}
+		key := stockKey(inputs[i])
+		if seen[key] {
+			return nil, errors.New("duplicate stocktake line")
+		}
+		seen[key] = true
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		stocktake, err := lockOpenStocktake(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkMovementTargets(tx, inputs); err != nil {
+			return err
+		}
+		_, err = s.countItems(tx, stocktake, req.Items, true, actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetStocktakeById(id)
+}
+
+// RecordScans counts a batch of barcode scans on an open stocktake, one unit per scan. Terminals
+// buffer scans and send them in batches. Barcodes that don't resolve to a product or variant
+// stock is kept for, such as the product barcode of a product with variants, are reported back
+// rather than failing the batch.
+func (s *StocktakeService) RecordScans(id uint, req *models.StocktakeScanRequest, actor models.ActivityActor) (*models.StocktakeScanResult, error) {
+	result := models.StocktakeScanResult{Unmatched: []string{}}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		stocktake, err := lockOpenStocktake(tx, id)
+		if err != nil {
+			return err
+		}
+
+		resolved, err := resolveStockBarcodes(tx, req.Barcodes)
+		if err != nil {
+			return err
+		}
+		var counts []models.StocktakeCountItem
+		index := map[stockLevelKey]int{}
+		unmatched := map[string]bool{}
+		for _, code := range req.Barcodes {
+			input, ok := resolved[code]
+			if !ok {
+				if !unmatched[code] {
+					unmatched[code] = true
+					result.Unmatched = append(result.Unmatched, code)
+				}
+				continue
+			}
+			key := stockKey(input)
+			if i, ok := index[key]; ok {
+				counts[i].Quantity++
+			} else {
+				index[key] = len(counts)
+				counts = append(counts, models.StocktakeCountItem{ProductID: input.ProductID, VariantID: input.VariantID, Quantity: 1})
+			}
+			result.Counted++
+		}
+
+		result.Items, err = s.countItems(tx, stocktake, counts, false, actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// resolveStockBarcodes maps barcodes to the product or variant whose stock they count. Variant
+// barcodes win over product barcodes; a product barcode only counts products without variants,
+// since their stock is kept per variant.
+func resolveStockBarcodes(tx *gorm.DB, codes []string) (map[string]models.StockMovementInput, error) {
+	resolved := make(map[string]models.StockMovementInput, len(codes))
+
+	var variants []models.ProductVariants
+	if err := tx.Where("barcode IN ?", codes).Order("id").Find(&variants).Error; err != nil {
+		return nil, err
+	}
+	for _, variant := range variants {
+		if _, ok := resolved[variant.Barcode]; !ok {
+			variantID := variant.ID
+			resolved[variant.Barcode] = models.StockMovementInput{ProductID: variant.ProductID, VariantID: &variantID}
+		}
+	}
+
+	var products []models.Products
+	if err := tx.Where("barcode IN ?", codes).
+		Where("NOT EXISTS (SELECT 1 FROM product_variants pv WHERE pv.product_id = products.id AND pv.deleted_at IS NULL)").
+		Order("id").Find(&products).Error; err != nil {
+		return nil, err
+	}
+	for _, product := range products {
+		if _, ok := resolved[product.Barcode]; !ok {
+			resolved[product.Barcode] = models.StockMovementInput{ProductID: product.ID}
+		}
+	}
+	return resolved, nil
+}
+
+// GetStocktakeReport compares every counted line of a stocktake with the system stock
+func (s *StocktakeService) GetStocktakeReport(id uint) (*models.StocktakeReport, error) {
+	var stocktake models.Stocktakes
+	if err := s.db.Where("id = ?", id).First(&stocktake).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("stocktake not found")
+		}
+		return nil, err
+	}
+
+	report := models.StocktakeReport{
+		StocktakeID: stocktake.ID,
+		Number:      stocktake.Number,
+		Status:      stocktake.Status,
+		Lines:       []models.StocktakeReportLine{},
+	}
+	if err := s.db.Raw(stocktakeReportSQL, storeKey(stocktake.StoreID), stocktake.ID).Scan(&report.Lines).Error; err != nil {
+		return nil, err
+	}
+	for i := range report.Lines {
+		line := &report.Lines[i]
+		line.Difference = line.Counted - line.Expected
+		report.Counted++
+		switch {
+		case line.Difference > 0:
+			report.Mismatched++
+			report.Surplus += line.Difference
+		case line.Difference < 0:
+			report.Mismatched++
+			report.Shortage -= line.Difference
+		}
+	}
+	return &report, nil
+}
+
+// ApproveStocktake closes an open stocktake and adjusts the stock of every counted line to its
+// count, in one transaction. Stock levels are locked while the differences are worked out, so
+// sales recorded before the approval are part of the system stock the counts replace.
+func (s *StocktakeService) ApproveStocktake(id uint, actor models.ActivityActor) (*models.Stocktakes, error) {
+	var inputs []models.StockMovementInput
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		stocktake, err := lockOpenStocktake(tx, id)
+		if err != nil {
+			return err
+		}
+		if len(stocktake.Items) == 0 {
+			return errors.New("stocktake has no counts")
+		}
+
+		productIDs := make([]uint, len(stocktake.Items))
+		for i, item := range stocktake.Items {
+			productIDs[i] = item.ProductID
+		}
+		var levels []models.StockLevels
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id IN ? AND COALESCE(store_id, 0) = ?", uniqueIDs(productIDs), storeKey(stocktake.StoreID)).
+			Order("product_id, COALESCE(variant_id, 0)").
+			Find(&levels).Error; err != nil {
+			return err
+		}
+		stock := make(map[stockLevelKey]int64, len(levels))
+		for _, level := range levels {
+			stock[stockKey(models.StockMovementInput{ProductID: level.ProductID, VariantID: level.VariantID})] = level.Quantity
+		}
+
+		items := make([]models.JSONMap, 0, len(stocktake.Items))
+		for _, item := range stocktake.Items {
+			expected := stock[stockKey(models.StockMovementInput{ProductID: item.ProductID, VariantID: item.VariantID})]
+			if err := tx.Model(&item).UpdateColumn("expected_quantity", expected).Error; err != nil {
+				return err
+			}
+			difference := item.CountedQuantity - expected
+			if difference == 0 {
+				continue
+			}
+			inputs = append(inputs, models.StockMovementInput{
+				ProductID:   item.ProductID,
+				VariantID:   item.VariantID,
+				StoreID:     stocktake.StoreID,
+				Type:        models.StockMovementAdjustment,
+				Quantity:    difference,
+				Reason:      models.StockAdjustmentRecount,
+				Reference:   stocktake.Number,
+				Note:        "Stocktake",
+				CreatedByID: actorID(actor),
+			})
+			items = append(items, models.JSONMap{
+				"product_id": item.ProductID,
+				"variant_id": item.VariantID,
+				"quantity":   difference,
+			})
+		}
+		if _, err := s.inventory.RecordMovements(tx, inputs); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		stocktake.Status = models.StocktakeStatusApproved
+		stocktake.ApprovedAt = &now
+		stocktake.ApprovedByID = actorID(actor)
+		if err := tx.Omit("Items").Save(stocktake).Error; err != nil {
+			return err
+		}
+
+		return s.inventory.activityService.RecordTx(tx, actor.UserID, models.ActivityStockAdjusted, actor, "Stocktake approved", models.JSONMap{
+			"reason":    models.StockAdjustmentRecount,
+			"reference": stocktake.Number,
+			"items":     items,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.inventory.stockChanged(inputs)
+	return s.GetStocktakeById(id)
+}
+
+// CancelStocktake closes an open stocktake without touching stock
+func (s *StocktakeService) CancelStocktake(id uint, actor models.ActivityActor) (*models.Stocktakes, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		stocktake, err := lockOpenStocktake(tx, id)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		stocktake.Status = models.StocktakeStatusCancelled
+		stocktake.CancelledAt = &now
+		stocktake.CancelledByID = actorID(actor)
+		return tx.Omit("Items").Save(stocktake).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetStocktakeById(id)
+}
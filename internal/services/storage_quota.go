@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StorageQuotaError is returned when an upload would take a tenant or one of its stores over
+// its storage quota
+type StorageQuotaError struct {
+	Scope     string `json:"scope"`
+	Quota     int64  `json:"quota"`
+	Used      int64  `json:"used"`
+	Requested int64  `json:"requested"`
+}
+
+func (e *StorageQuotaError) Error() string {
+	return fmt.Sprintf("%s storage quota of %d bytes exceeded: %d used, %d requested", e.Scope, e.Quota, e.Used, e.Requested)
+}
+
+// StorageQuotaService meters the bytes kept in storage per tenant and store and enforces the
+// configured quotas on uploads
+type StorageQuotaService struct {
+	db     *gorm.DB
+	quotas models.StorageQuotas
+}
+
+func NewStorageQuotaService(db *gorm.DB, quotas models.StorageQuotas) *StorageQuotaService {
+	return &StorageQuotaService{
+		db:     db,
+		quotas: quotas,
+	}
+}
+
+// check returns a *StorageQuotaError when adding bytes would exceed the tenant's or the store's
+// quota. Files uploaded outside any store only count towards the tenant.
+func (s *StorageQuotaService) check(db *gorm.DB, owner models.StorageOwner, bytes int64) error {
+	if s.quotas.TenantBytes > 0 {
+		var used int64
+		if err := db.Model(&models.StorageUsages{}).
+			Select("COALESCE(SUM(bytes), 0)").
+			Where("tenant_id = ?", owner.TenantID).
+			Scan(&used).Error; err != nil {
+			return err
+		}
+		if used+bytes > s.quotas.TenantBytes {
+			return &StorageQuotaError{Scope: models.StorageScopeTenant, Quota: s.quotas.TenantBytes, Used: used, Requested: bytes}
+		}
+	}
+
+	if s.quotas.StoreBytes > 0 && owner.StoreID != 0 {
+		var used int64
+		if err := db.Model(&models.StorageUsages{}).
+			Select("COALESCE(SUM(bytes), 0)").
+			Where("tenant_id = ? AND store_id = ?", owner.TenantID, owner.StoreID).
+			Scan(&used).Error; err != nil {
+			return err
+		}
+		if used+bytes > s.quotas.StoreBytes {
+			return &StorageQuotaError{Scope: models.StorageScopeStore, Quota: s.quotas.StoreBytes, Used: used, Requested: bytes}
+		}
+	}
+
+	return nil
+}
+
+// CheckQuota fails fast, before any file is written, when an upload of the given size can't fit.
+// chargeTx checks again once the usage is locked.
+func (s *StorageQuotaService) CheckQuota(ctx context.Context, owner models.StorageOwner, bytes int64) error {
+	return s.check(s.db.WithContext(ctx), owner, bytes)
+}
+
+// chargeTx adds stored files to the owner's usage, failing with a *StorageQuotaError when they
+// don't fit. The tenant's row outside any store is locked first and stands for the whole tenant,
+// so uploads to different stores of a tenant are checked one after another.
+func (s *StorageQuotaService) chargeTx(tx *gorm.DB, owner models.StorageOwner, bytes int64, files int64) error {
+	rows := []models.StorageUsages{{TenantID: owner.TenantID}}
+	if owner.StoreID != 0 {
+		rows = append(rows, models.StorageUsages{TenantID: owner.TenantID, StoreID: owner.StoreID})
+	}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "store_id"}},
+		DoNothing: true,
+	}).Create(&rows).Error; err != nil {
+		return err
+	}
+
+	var locked []models.StorageUsages
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tenant_id = ? AND store_id IN ?", owner.TenantID, []uint{0, owner.StoreID}).
+		Order("store_id").
+		Find(&locked).Error; err != nil {
+		return err
+	}
+
+	if err := s.check(tx, owner, bytes); err != nil {
+		return err
+	}
+	return tx.Model(&models.StorageUsages{}).
+		Where("tenant_id = ? AND store_id = ?", owner.TenantID, owner.StoreID).
+		Updates(map[string]any{
+			"bytes": gorm.Expr("bytes + ?", bytes),
+			"files": gorm.Expr("files + ?", files),
+		}).Error
+}
+
+// releaseStorageTx takes removed files off the owner's usage
+func releaseStorageTx(tx *gorm.DB, owner models.StorageOwner, bytes int64, files int64) error {
+	return tx.Model(&models.StorageUsages{}).
+		Where("tenant_id = ? AND store_id = ?", owner.TenantID, owner.StoreID).
+		Updates(map[string]any{
+			"bytes": gorm.Expr("GREATEST(bytes - ?, 0)", bytes),
+			"files": gorm.Expr("GREATEST(files - ?, 0)", files),
+		}).Error
+}
+
+// GetUsage reports the storage usage of every tenant and its stores against the quotas
+func (s *StorageQuotaService) GetUsage(ctx context.Context) (*models.StorageUsageReport, error) {
+	db := s.db.WithContext(ctx)
+
+	var usages []models.StorageUsages
+	if err := db.Where("bytes > 0 OR files > 0").Order("tenant_id, store_id").Find(&usages).Error; err != nil {
+		return nil, err
+	}
+
+	tenantNames := map[uint]string{}
+	storeNames := map[uint]string{}
+	var tenantIDs, storeIDs []uint
+	for _, usage := range usages {
+		if usage.TenantID != 0 {
+			tenantIDs = append(tenantIDs, usage.TenantID)
+		}
+		if usage.StoreID != 0 {
+			storeIDs = append(storeIDs, usage.StoreID)
+		}
+	}
+	if len(tenantIDs) > 0 {
+		var tenants []models.Tenants
+		if err := db.Select("id", "name").Where("id IN ?", uniqueIDs(tenantIDs)).Find(&tenants).Error; err != nil {
+			return nil, err
+		}
+		for _, tenant := range tenants {
+			tenantNames[tenant.ID] = tenant.Name
+		}
+	}
+	if len(storeIDs) > 0 {
+		var stores []models.Stores
+		if err := db.Unscoped().Select("id", "name").Where("id IN ?", uniqueIDs(storeIDs)).Find(&stores).Error; err != nil {
+			return nil, err
+		}
+		for _, store := range stores {
+			storeNames[store.ID] = store.Name
+		}
+	}
+
+	byTenant := map[uint]*models.StorageTenantUsage{}
+	for _, usage := range usages {
+		tenant, ok := byTenant[usage.TenantID]
+		if !ok {
+			tenant = &models.StorageTenantUsage{
+				TenantID:   usage.TenantID,
+				TenantName: tenantNames[usage.TenantID],
+				Stores:     []models.StorageStoreUsage{},
+			}
+			byTenant[usage.TenantID] = tenant
+		}
+		tenant.Bytes += usage.Bytes
+		tenant.Files += usage.Files
+		tenant.Stores = append(tenant.Stores, models.StorageStoreUsage{
+			StoreID:   usage.StoreID,
+			StoreName: storeNames[usage.StoreID],
+			Bytes:     usage.Bytes,
+			Files:     usage.Files,
+		})
+	}
+
+	report := &models.StorageUsageReport{Quotas: s.quotas, Tenants: []models.StorageTenantUsage{}}
+	for _, tenant := range byTenant {
+		report.Tenants = append(report.Tenants, *tenant)
+	}
+	sort.Slice(report.Tenants, func(i, j int) bool { return report.Tenants[i].TenantID < report.Tenants[j].TenantID })
+	return report, nil
+}
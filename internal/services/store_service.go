@@ -0,0 +1,234 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// StoreService manages stores and which users work in them
+type StoreService struct {
+	db *gorm.DB
+}
+
+func NewStoreService(db *gorm.DB) *StoreService {
+	return &StoreService{
+		db: db,
+	}
+}
+
+// findStore returns a store that isn't deleted
+func findStore(db *gorm.DB, id uint) (*models.Stores, error) {
+	var store models.Stores
+	if err := db.Where("id = ?", id).First(&store).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("store not found")
+		}
+		return nil, err
+	}
+	return &store, nil
+}
+
+// GetAllStores retrieves stores with pagination and search on code and name
+func (s *StoreService) GetAllStores(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.Stores{},
+		SearchFields: []string{"code", "name"},
+		FilterFields: map[string]string{
+			"active": "active",
+		},
+		CustomFilters: map[string]string{
+			"user_id": "id IN (SELECT store_id FROM user_stores WHERE user_id = ?)",
+		},
+		SortFields: []string{
+			"code",
+			"name",
+			"created_at",
+		},
+		DefaultSort:  "name",
+		DefaultOrder: "ASC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetStoreById retrieves a store
+func (s *StoreService) GetStoreById(id uint) (*models.Stores, error) {
+	return findStore(s.db, id)
+}
+
+// applyStore checks a store's code and copies the request onto the store
+func (s *StoreService) applyStore(store *models.Stores, req *models.StoreRequest) error {
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	var count int64
+	if err := s.db.Model(&models.Stores{}).Where("code = ? AND id <> ?", code, store.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("store code already exists")
+	}
+
+	store.Code = code
+	store.Name = strings.TrimSpace(req.Name)
+	store.Address = req.Address
+	store.Phone = strings.TrimSpace(req.Phone)
+	store.Email = strings.TrimSpace(req.Email)
+	store.LegalName = strings.TrimSpace(req.LegalName)
+	store.TaxID = strings.TrimSpace(req.TaxID)
+	if req.Active != nil {
+		store.Active = *req.Active
+	}
+	return nil
+}
+
+// CreateStore creates a store, active unless asked otherwise
+func (s *StoreService) CreateStore(req *models.StoreRequest) (*models.Stores, error) {
+	store := models.Stores{Active: true}
+	if err := s.applyStore(&store, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&store).Error; err != nil {
+		return nil, err
+	}
+	return &store, nil
+}
+
+// UpdateStore updates a store
+func (s *StoreService) UpdateStore(id uint, req *models.StoreRequest) (*models.Stores, error) {
+	store, err := findStore(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyStore(store, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(store).Error; err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// DeleteStore soft-deletes a store and drops its user assignments. Stores with terminals set up
+// at them are kept until the terminals are moved.
+func (s *StoreService) DeleteStore(id uint) (*models.Stores, error) {
+	store, err := findStore(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var devices int64
+	if err := s.db.Model(&models.Devices{}).Where("store_id = ?", store.ID).Count(&devices).Error; err != nil {
+		return nil, err
+	}
+	if devices > 0 {
+		return nil, errors.New("store has devices")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("store_id = ?", store.ID).Delete(&models.UserStores{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(store).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// GetUserStores retrieves the stores a user is assigned to
+func (s *StoreService) GetUserStores(userID uint) ([]models.UserStores, error) {
+	if err := s.db.Where("id = ? AND is_deleted = ?", userID, false).First(&models.Users{}).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	stores := []models.UserStores{}
+	if err := s.db.Preload("Store").Where("user_id = ?", userID).Order("store_id").Find(&stores).Error; err != nil {
+		return nil, err
+	}
+	return stores, nil
+}
+
+// SetUserStores replaces the stores a user is assigned to
+func (s *StoreService) SetUserStores(userID uint, req *models.SetUserStoresRequest) ([]models.UserStores, error) {
+	if err := s.db.Where("id = ? AND is_deleted = ?", userID, false).First(&models.Users{}).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	storeIDs := uniqueIDs(req.StoreIDs)
+	if len(storeIDs) > 0 {
+		var count int64
+		if err := s.db.Model(&models.Stores{}).Where("id IN ?", storeIDs).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count != int64(len(storeIDs)) {
+			return nil, errors.New("store not found")
+		}
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserStores{}).Error; err != nil {
+			return err
+		}
+		if len(storeIDs) == 0 {
+			return nil
+		}
+		assignments := make([]models.UserStores, len(storeIDs))
+		for i, storeID := range storeIDs {
+			assignments[i] = models.UserStores{UserID: userID, StoreID: storeID}
+		}
+		return tx.Create(&assignments).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetUserStores(userID)
+}
+
+// AccessibleStores returns the IDs of the stores a user may work in: the stores they are
+// assigned to, or nil for admins and users assigned to none, who may work in every store
+func (s *StoreService) AccessibleStores(user models.RegisterResponse) ([]uint, error) {
+	if user.Role == models.RoleAdmin {
+		return nil, nil
+	}
+
+	var assigned []uint
+	if err := s.db.Model(&models.UserStores{}).Where("user_id = ?", user.ID).Pluck("store_id", &assigned).Error; err != nil {
+		return nil, err
+	}
+	if len(assigned) == 0 {
+		return nil, nil
+	}
+	return assigned, nil
+}
+
+// ResolveStore returns the store a user asked to work in, as long as it is active and the user
+// may work there
+func (s *StoreService) ResolveStore(storeID uint, user models.RegisterResponse) (*models.Stores, error) {
+	store, err := findStore(s.db, storeID)
+	if err != nil {
+		return nil, err
+	}
+	if !store.Active {
+		return nil, errors.New("store inactive")
+	}
+	assigned, err := s.AccessibleStores(user)
+	if err != nil {
+		return nil, err
+	}
+	if assigned != nil && !slices.Contains(assigned, store.ID) {
+		return nil, errors.New("store not assigned")
+	}
+	return store, nil
+}
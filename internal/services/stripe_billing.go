@@ -0,0 +1,181 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/stripe"
+	"gorm.io/gorm"
+)
+
+// stripeSubscription is the part of a Stripe subscription object the billing sync reads
+type stripeSubscription struct {
+	ID               string            `json:"id"`
+	Customer         string            `json:"customer"`
+	Status           string            `json:"status"`
+	CurrentPeriodEnd int64             `json:"current_period_end"`
+	CanceledAt       int64             `json:"canceled_at"`
+	Metadata         map[string]string `json:"metadata"`
+	Items            struct {
+		Data []struct {
+			CurrentPeriodEnd int64 `json:"current_period_end"`
+			Price            struct {
+				ID string `json:"id"`
+			} `json:"price"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+// stripeInvoice is the part of a Stripe invoice object the billing sync reads
+type stripeInvoice struct {
+	Customer     string `json:"customer"`
+	Subscription string `json:"subscription"`
+	Parent       struct {
+		SubscriptionDetails struct {
+			Subscription string `json:"subscription"`
+		} `json:"subscription_details"`
+	} `json:"parent"`
+}
+
+// HandleStripeWebhook verifies and applies a Stripe Billing webhook. Subscription events sync
+// the plan and status, invoice events flag failed and recovered payments. Each event is applied
+// once; redeliveries are acknowledged without changes.
+func (s *SubscriptionService) HandleStripeWebhook(payload []byte, signature string) error {
+	if err := stripe.VerifySignature(payload, signature, s.webhookSecret); err != nil {
+		return err
+	}
+
+	var event stripe.Event
+	if err := json.Unmarshal(payload, &event); err != nil || event.ID == "" {
+		return errors.New("invalid webhook payload")
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var processed models.BillingEvents
+		if err := tx.Where("stripe_event_id = ?", event.ID).First(&processed).Error; err == nil {
+			return nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		switch event.Type {
+		case "customer.subscription.created", "customer.subscription.updated", "customer.subscription.deleted":
+			var object stripeSubscription
+			if err := json.Unmarshal(event.Data.Object, &object); err != nil {
+				return errors.New("invalid webhook payload")
+			}
+			if err := s.syncStripeSubscription(tx, object); err != nil {
+				return err
+			}
+
+		case "invoice.payment_failed", "invoice.paid":
+			var object stripeInvoice
+			if err := json.Unmarshal(event.Data.Object, &object); err != nil {
+				return errors.New("invalid webhook payload")
+			}
+			status := models.SubscriptionStatusActive
+			if event.Type == "invoice.payment_failed" {
+				status = models.SubscriptionStatusPastDue
+			}
+			if err := s.syncStripeInvoice(tx, object, status); err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&models.BillingEvents{StripeEventID: event.ID, Type: event.Type}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+// syncStripeSubscription mirrors a Stripe subscription onto the local subscription it bills.
+// The local subscription is found by Stripe ID, then by the tenant_id metadata set at checkout.
+func (s *SubscriptionService) syncStripeSubscription(tx *gorm.DB, object stripeSubscription) error {
+	var subscription models.Subscriptions
+	err := tx.Where("stripe_subscription_id = ?", object.ID).First(&subscription).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tenantID, parseErr := strconv.ParseUint(object.Metadata["tenant_id"], 10, 32)
+		if parseErr != nil {
+			slog.Warn("Stripe webhook: subscription has no tenant_id metadata, ignoring", "subscription", object.ID)
+			return nil
+		}
+		err = tx.Where("tenant_id = ?", tenantID).First(&subscription).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			subscription = models.Subscriptions{TenantID: uint(tenantID)}
+			err = nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	var priceID string
+	periodEnd := object.CurrentPeriodEnd
+	if len(object.Items.Data) > 0 {
+		priceID = object.Items.Data[0].Price.ID
+		if periodEnd == 0 {
+			periodEnd = object.Items.Data[0].CurrentPeriodEnd
+		}
+	}
+	if priceID != "" {
+		var plan models.Plans
+		if err := tx.Where("stripe_price_id = ?", priceID).First(&plan).Error; err == nil {
+			subscription.PlanID = plan.ID
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		} else {
+			slog.Warn("Stripe webhook: no plan for price", "price", priceID, "subscription", object.ID)
+		}
+	}
+	if subscription.PlanID == 0 {
+		slog.Warn("Stripe webhook: subscription does not map to a plan, ignoring", "subscription", object.ID)
+		return nil
+	}
+
+	subscription.Status = object.Status
+	subscription.StripeCustomerID = object.Customer
+	subscription.StripeSubscriptionID = object.ID
+	if periodEnd > 0 {
+		end := time.Unix(periodEnd, 0)
+		subscription.CurrentPeriodEnd = &end
+	}
+	if object.CanceledAt > 0 {
+		canceledAt := time.Unix(object.CanceledAt, 0)
+		subscription.CanceledAt = &canceledAt
+	}
+
+	return tx.Save(&subscription).Error
+}
+
+// syncStripeInvoice updates the status of the subscription an invoice belongs to
+func (s *SubscriptionService) syncStripeInvoice(tx *gorm.DB, object stripeInvoice, status string) error {
+	subscriptionID := object.Subscription
+	if subscriptionID == "" {
+		subscriptionID = object.Parent.SubscriptionDetails.Subscription
+	}
+
+	query := tx.Model(&models.Subscriptions{})
+	switch {
+	case subscriptionID != "":
+		query = query.Where("stripe_subscription_id = ?", subscriptionID)
+	case object.Customer != "":
+		query = query.Where("stripe_customer_id = ?", object.Customer)
+	default:
+		return nil
+	}
+
+	// A paid invoice only clears a payment problem; it never revives a canceled subscription
+	if status == models.SubscriptionStatusActive {
+		query = query.Where("status IN ?", []string{models.SubscriptionStatusPastDue, models.SubscriptionStatusUnpaid, models.SubscriptionStatusIncomplete})
+	}
+
+	return query.Update("status", status).Error
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/payments"
+	"github.com/Aebroyx/the-blade-api/internal/stripe"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxPaymentReasonLength matches the size of the failure_reason and last_error columns
+const maxPaymentReasonLength = 255
+
+// stripePaymentEvents are the Stripe events that change payments; others are acknowledged and
+// dropped
+var stripePaymentEvents = map[string]bool{
+	"payment_intent.succeeded":                 true,
+	"payment_intent.amount_capturable_updated": true,
+	"payment_intent.payment_failed":            true,
+	"payment_intent.canceled":                  true,
+	"charge.refunded":                          true,
+}
+
+// stripeCharge is the part of a Stripe charge object the payment sync reads
+type stripeCharge struct {
+	PaymentIntent  string `json:"payment_intent"`
+	AmountRefunded int64  `json:"amount_refunded"`
+}
+
+// truncateReason cuts a provider message to fit its column
+func truncateReason(reason string) string {
+	if len(reason) > maxPaymentReasonLength {
+		return reason[:maxPaymentReasonLength]
+	}
+	return reason
+}
+
+// ReceiveStripeWebhook verifies a Stripe payments webhook and stores the event to be applied
+// in the background. Redeliveries of a stored event are acknowledged without storing it again.
+func (s *PaymentService) ReceiveStripeWebhook(payload []byte, signature string) error {
+	if err := stripe.VerifySignature(payload, signature, s.stripeWebhookSecret); err != nil {
+		return err
+	}
+
+	var event stripe.Event
+	if err := json.Unmarshal(payload, &event); err != nil || event.ID == "" {
+		return errors.New("invalid webhook payload")
+	}
+	if !stripePaymentEvents[event.Type] {
+		return nil
+	}
+
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.PaymentEvents{
+		Provider: payments.MethodStripe,
+		EventID:  event.ID,
+		Type:     event.Type,
+		Payload:  string(payload),
+		Status:   models.PaymentEventStatusPending,
+	}).Error
+}
+
+// ProcessPaymentEvents applies the stored webhook events, oldest first. It is the entry point
+// of the background payment events job; events are claimed with a row lock so several API
+// instances can run it at once.
+func (s *PaymentService) ProcessPaymentEvents(ctx context.Context) error {
+	for ctx.Err() == nil {
+		processed, err := s.processPaymentEvent(ctx)
+		if err != nil {
+			return err
+		}
+		if !processed {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// processPaymentEvent applies the oldest due event. An event that can't be applied, e.g.
+// because it arrived before its payment was recorded, is retried with a growing delay until
+// it fails for good. Returns false when no event is due.
+func (s *PaymentService) processPaymentEvent(ctx context.Context) (bool, error) {
+	var transition *OrderTransition
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		var event models.PaymentEvents
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND (retry_at IS NULL OR retry_at <= ?)", models.PaymentEventStatusPending, now).
+			Order("id ASC").
+			First(&event).Error
+		if err != nil {
+			return err
+		}
+
+		// Applied in a savepoint, so a failed event rolls back on its own and its attempt is kept
+		applyErr := tx.Transaction(func(inner *gorm.DB) error {
+			var err error
+			transition, err = s.applyStripeEvent(inner, &event)
+			return err
+		})
+
+		attempts := event.Attempts + 1
+		if applyErr != nil {
+			transition = nil
+			slog.ErrorContext(ctx, "Payment event failed", "event_id", event.EventID, "type", event.Type, "attempt", attempts, "error", applyErr)
+
+			columns := map[string]interface{}{
+				"attempts":   attempts,
+				"last_error": truncateReason(applyErr.Error()),
+				"retry_at":   now.Add(time.Duration(attempts) * time.Minute),
+			}
+			if attempts >= models.PaymentEventMaxAttempts {
+				columns["status"] = models.PaymentEventStatusFailed
+			}
+			return tx.Model(&event).Updates(columns).Error
+		}
+
+		return tx.Model(&event).Updates(map[string]interface{}{
+			"status":       models.PaymentEventStatusProcessed,
+			"attempts":     attempts,
+			"last_error":   "",
+			"processed_at": now,
+		}).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if transition != nil {
+		s.orders.transitioned(*transition)
+	}
+	return true, nil
+}
+
+// lockStripePayment loads the payment of a PaymentIntent and locks its order. Returns nil for
+// intents the API didn't create; intents it did create but hasn't recorded yet are an error,
+// so the event is retried once the payment is committed.
+func (s *PaymentService) lockStripePayment(tx *gorm.DB, intentID string, created bool) (*models.Orders, *models.Payments, error) {
+	var payment models.Payments
+	if err := tx.Where("method = ? AND reference = ?", payments.MethodStripe, intentID).First(&payment).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, err
+		}
+		if created {
+			return nil, nil, errors.New("payment not recorded yet")
+		}
+		return nil, nil, nil
+	}
+	return s.lockPayment(tx, payment.OrderID, payment.ID)
+}
+
+// applyStripeEvent mirrors a Stripe event onto the payment of its PaymentIntent. Payments
+// only move forward, so events that arrive out of order or repeat what the API already did
+// change nothing.
+func (s *PaymentService) applyStripeEvent(tx *gorm.DB, event *models.PaymentEvents) (*OrderTransition, error) {
+	var envelope stripe.Event
+	if err := json.Unmarshal([]byte(event.Payload), &envelope); err != nil {
+		return nil, err
+	}
+
+	if event.Type == "charge.refunded" {
+		var charge stripeCharge
+		if err := json.Unmarshal(envelope.Data.Object, &charge); err != nil {
+			return nil, err
+		}
+		return nil, s.syncStripeRefund(tx, charge)
+	}
+
+	var intent payments.StripeIntent
+	if err := json.Unmarshal(envelope.Data.Object, &intent); err != nil {
+		return nil, err
+	}
+	order, payment, err := s.lockStripePayment(tx, intent.ID, intent.Metadata["order_id"] != "")
+	if err != nil || payment == nil {
+		return nil, err
+	}
+	open := payment.Status == models.PaymentStatusPending || payment.Status == models.PaymentStatusAuthorized
+
+	switch event.Type {
+	case "payment_intent.succeeded":
+		if !open {
+			return nil, nil
+		}
+		if err := tx.Model(payment).Updates(map[string]interface{}{
+			"status":      models.PaymentStatusCaptured,
+			"captured_at": time.Now(),
+		}).Error; err != nil {
+			return nil, err
+		}
+		return s.settleTx(tx, order, stripeActor(payment))
+
+	case "payment_intent.amount_capturable_updated":
+		if payment.Status != models.PaymentStatusPending {
+			return nil, nil
+		}
+		return nil, tx.Model(payment).Update("status", models.PaymentStatusAuthorized).Error
+
+	case "payment_intent.payment_failed", "payment_intent.canceled":
+		if !open {
+			return nil, nil
+		}
+		return nil, tx.Model(payment).Updates(map[string]interface{}{
+			"status":         models.PaymentStatusFailed,
+			"failure_reason": truncateReason(intent.FailureReason()),
+		}).Error
+	}
+	return nil, nil
+}
+
+// syncStripeRefund catches a payment up with refunds made at Stripe, e.g. from its dashboard.
+// Refunds made through the API are already counted.
+func (s *PaymentService) syncStripeRefund(tx *gorm.DB, charge stripeCharge) error {
+	if charge.PaymentIntent == "" {
+		return nil
+	}
+	order, payment, err := s.lockStripePayment(tx, charge.PaymentIntent, false)
+	if err != nil || payment == nil {
+		return err
+	}
+	if payment.Status != models.PaymentStatusCaptured || charge.AmountRefunded <= payment.RefundedAmount {
+		return nil
+	}
+
+	refunded := min(charge.AmountRefunded, payment.Amount)
+	amount := refunded - payment.RefundedAmount
+	columns := map[string]interface{}{
+		"refunded_amount": refunded,
+		"refunded_at":     time.Now(),
+	}
+	if refunded == payment.Amount {
+		columns["status"] = models.PaymentStatusRefunded
+	}
+	if err := tx.Model(payment).Updates(columns).Error; err != nil {
+		return err
+	}
+	return s.recordPaymentActivity(tx, models.ActivityPaymentRefunded, fmt.Sprintf("Payment for order %s refunded at Stripe", order.Number), order, payment, amount, stripeActor(payment))
+}
+
+// stripeActor attributes changes made by Stripe events to whoever took the payment
+func stripeActor(payment *models.Payments) models.ActivityActor {
+	actor := models.ActivityActor{UserAgent: "Stripe webhook"}
+	if payment.CreatedByID != nil {
+		actor.UserID = *payment.CreatedByID
+	}
+	return actor
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// subscriptionCacheTTL bounds how long a tenant's subscription is reused by the plan middleware
+const subscriptionCacheTTL = 30 * time.Second
+
+// apiUsageKeyTTL keeps a daily API call counter around a little past its day
+const apiUsageKeyTTL = 48 * time.Hour
+
+// PlanLimitError is returned when an action would take the tenant over a plan limit
+type PlanLimitError struct {
+	Resource string `json:"resource"`
+	Limit    int    `json:"limit"`
+	Plan     string `json:"plan"`
+}
+
+func (e *PlanLimitError) Error() string {
+	return fmt.Sprintf("plan %s allows at most %d %s", e.Plan, e.Limit, e.Resource)
+}
+
+// subscriptionCacheEntry is a cached subscription lookup; a nil subscription means the tenant has none
+type subscriptionCacheEntry struct {
+	subscription *models.Subscriptions
+	expiresAt    time.Time
+}
+
+// SubscriptionService manages plans and tenant subscriptions, and meters plan usage.
+// Users and devices are not scoped to tenants yet, so user and location usage is counted
+// across the whole database.
+type SubscriptionService struct {
+	db            *gorm.DB
+	cache         *cache.Cache
+	webhookSecret string
+
+	mu            sync.RWMutex
+	subscriptions map[uint]subscriptionCacheEntry
+}
+
+func NewSubscriptionService(db *gorm.DB, cache *cache.Cache, webhookSecret string) *SubscriptionService {
+	return &SubscriptionService{
+		db:            db,
+		cache:         cache,
+		webhookSecret: webhookSecret,
+		subscriptions: map[uint]subscriptionCacheEntry{},
+	}
+}
+
+// GetTenantSubscription returns the tenant's subscription with its plan, or nil when it has none
+func (s *SubscriptionService) GetTenantSubscription(tenantID uint) (*models.Subscriptions, error) {
+	s.mu.RLock()
+	entry, ok := s.subscriptions[tenantID]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.subscription, nil
+	}
+
+	var subscription *models.Subscriptions
+	var found models.Subscriptions
+	if err := s.db.Preload("Plan").Where("tenant_id = ?", tenantID).First(&found).Error; err == nil {
+		subscription = &found
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.subscriptions[tenantID] = subscriptionCacheEntry{subscription: subscription, expiresAt: time.Now().Add(subscriptionCacheTTL)}
+	s.mu.Unlock()
+
+	return subscription, nil
+}
+
+// invalidate drops cached subscriptions after a plan or subscription changes
+func (s *SubscriptionService) invalidate() {
+	s.mu.Lock()
+	s.subscriptions = map[uint]subscriptionCacheEntry{}
+	s.mu.Unlock()
+}
+
+// CountAPICall adds one call to the tenant's daily counter and returns the new total.
+// Returns cache.ErrUnavailable while Redis is down.
+func (s *SubscriptionService) CountAPICall(ctx context.Context, tenantID uint) (int64, error) {
+	client, ok := s.cache.Client()
+	if !ok {
+		return 0, cache.ErrUnavailable
+	}
+
+	key := cache.APIUsageKey(tenantID, time.Now().UTC().Format("20060102"))
+	pipe := client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, apiUsageKeyTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, cache.ErrUnavailable
+	}
+	return incr.Val(), nil
+}
+
+// apiCallsToday returns the tenant's API calls so far today, or 0 while Redis is down
+func (s *SubscriptionService) apiCallsToday(ctx context.Context, tenantID uint) int64 {
+	data, err := s.cache.Get(ctx, cache.APIUsageKey(tenantID, time.Now().UTC().Format("20060102")))
+	if err != nil {
+		return 0
+	}
+	count, _ := strconv.ParseInt(string(data), 10, 64)
+	return count
+}
+
+// countUsers counts the accounts that take up a user seat
+func (s *SubscriptionService) countUsers() (int64, error) {
+	var count int64
+	err := s.db.Model(&models.Users{}).Where("is_deleted = ?", false).Count(&count).Error
+	return count, err
+}
+
+// countLocations counts the distinct locations devices are bound to
+func (s *SubscriptionService) countLocations() (int64, error) {
+	var count int64
+	err := s.db.Model(&models.Devices{}).Where("location <> ''").Distinct("location").Count(&count).Error
+	return count, err
+}
+
+// CheckCapacity returns a *PlanLimitError when adding one more of the resource would exceed the plan.
+// For locations, location is the one being assigned; reusing an existing location is always allowed.
+func (s *SubscriptionService) CheckCapacity(subscription *models.Subscriptions, resource string, location string) error {
+	if subscription == nil || subscription.Plan == nil {
+		return nil
+	}
+	plan := subscription.Plan
+
+	switch resource {
+	case models.PlanResourceUsers:
+		if plan.MaxUsers == 0 {
+			return nil
+		}
+		count, err := s.countUsers()
+		if err != nil {
+			return err
+		}
+		if count >= int64(plan.MaxUsers) {
+			return &PlanLimitError{Resource: resource, Limit: plan.MaxUsers, Plan: plan.Code}
+		}
+
+	case models.PlanResourceLocations:
+		if plan.MaxLocations == 0 || location == "" {
+			return nil
+		}
+		var existing int64
+		if err := s.db.Model(&models.Devices{}).Where("location = ?", location).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return nil
+		}
+		count, err := s.countLocations()
+		if err != nil {
+			return err
+		}
+		if count >= int64(plan.MaxLocations) {
+			return &PlanLimitError{Resource: resource, Limit: plan.MaxLocations, Plan: plan.Code}
+		}
+	}
+
+	return nil
+}
+
+// GetSubscription returns the tenant's subscription together with its current usage
+func (s *SubscriptionService) GetSubscription(tenant models.Tenants) (*models.SubscriptionResponse, error) {
+	subscription, err := s.GetTenantSubscription(tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.countUsers()
+	if err != nil {
+		return nil, err
+	}
+	locations, err := s.countLocations()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SubscriptionResponse{
+		Tenant:       tenant,
+		Subscription: subscription,
+		Usage: models.SubscriptionUsage{
+			Users:         users,
+			Locations:     locations,
+			APICallsToday: s.apiCallsToday(context.Background(), tenant.ID),
+		},
+	}, nil
+}
+
+// ChangePlan moves the tenant to another plan, creating its subscription if needed.
+// Stripe-billed subscriptions are normally changed through Stripe and synced by webhook.
+func (s *SubscriptionService) ChangePlan(tenant models.Tenants, planCode string) (*models.SubscriptionResponse, error) {
+	var plan models.Plans
+	if err := s.db.Where("code = ? AND is_active = ?", planCode, true).First(&plan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("plan not found")
+		}
+		return nil, err
+	}
+
+	var subscription models.Subscriptions
+	err := s.db.Where("tenant_id = ?", tenant.ID).First(&subscription).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		subscription = models.Subscriptions{
+			TenantID: tenant.ID,
+			PlanID:   plan.ID,
+			Status:   models.SubscriptionStatusActive,
+		}
+		if err := s.db.Create(&subscription).Error; err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		if err := s.db.Model(&subscription).Update("plan_id", plan.ID).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	s.invalidate()
+	return s.GetSubscription(tenant)
+}
+
+// GetAllPlans retrieves plans with pagination and search
+func (s *SubscriptionService) GetAllPlans(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.Plans{},
+		SearchFields: []string{"code", "name"},
+		FilterFields: map[string]string{
+			"is_active": "is_active",
+		},
+		SortFields: []string{
+			"code",
+			"name",
+			"created_at",
+		},
+		DefaultSort:  "code",
+		DefaultOrder: "ASC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// CreatePlan creates a plan
+func (s *SubscriptionService) CreatePlan(req *models.CreatePlanRequest) (*models.Plans, error) {
+	var existing models.Plans
+	if err := s.db.Where("code = ?", req.Code).First(&existing).Error; err == nil {
+		return nil, errors.New("plan code already exists")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	plan := models.Plans{
+		Code:              req.Code,
+		Name:              req.Name,
+		MaxUsers:          req.MaxUsers,
+		MaxLocations:      req.MaxLocations,
+		MaxAPICallsPerDay: req.MaxAPICallsPerDay,
+		StripePriceID:     req.StripePriceID,
+		IsActive:          true,
+	}
+	if err := s.db.Create(&plan).Error; err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
+
+// UpdatePlan updates a plan's limits; tenants on the plan pick them up right away
+func (s *SubscriptionService) UpdatePlan(id uint, req *models.UpdatePlanRequest) (*models.Plans, error) {
+	var plan models.Plans
+	if err := s.db.Where("id = ?", id).First(&plan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("plan not found")
+		}
+		return nil, err
+	}
+
+	plan.Name = req.Name
+	plan.MaxUsers = req.MaxUsers
+	plan.MaxLocations = req.MaxLocations
+	plan.MaxAPICallsPerDay = req.MaxAPICallsPerDay
+	plan.StripePriceID = req.StripePriceID
+	if req.IsActive != nil {
+		plan.IsActive = *req.IsActive
+	}
+
+	if err := s.db.Save(&plan).Error; err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return &plan, nil
+}
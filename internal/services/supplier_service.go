@@ -0,0 +1,234 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// SupplierService manages suppliers and which products are bought from them
+type SupplierService struct {
+	db *gorm.DB
+}
+
+func NewSupplierService(db *gorm.DB) *SupplierService {
+	return &SupplierService{
+		db: db,
+	}
+}
+
+// GetAllSuppliers retrieves suppliers with pagination and search on name, contact and email
+func (s *SupplierService) GetAllSuppliers(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.Suppliers{},
+		SearchFields: []string{"name", "contact_name", "email"},
+		FilterFields: map[string]string{
+			"active":        "active",
+			"payment_terms": "payment_terms",
+		},
+		CustomFilters: map[string]string{
+			"product_id": "id IN (SELECT supplier_id FROM product_suppliers WHERE product_id = ?)",
+		},
+		SortFields: []string{
+			"name",
+			"created_at",
+		},
+		DefaultSort:  "name",
+		DefaultOrder: "ASC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetSupplierById retrieves a supplier
+func (s *SupplierService) GetSupplierById(id uint) (*models.Suppliers, error) {
+	var supplier models.Suppliers
+	if err := s.db.Where("id = ?", id).First(&supplier).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("supplier not found")
+		}
+		return nil, err
+	}
+	return &supplier, nil
+}
+
+// applySupplier checks a supplier's name and copies the request onto the supplier
+func (s *SupplierService) applySupplier(supplier *models.Suppliers, req *models.SupplierRequest) error {
+	var count int64
+	if err := s.db.Model(&models.Suppliers{}).Where("LOWER(name) = LOWER(?) AND id <> ?", strings.TrimSpace(req.Name), supplier.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("supplier already exists")
+	}
+
+	supplier.Name = strings.TrimSpace(req.Name)
+	supplier.ContactName = req.ContactName
+	supplier.Email = strings.TrimSpace(req.Email)
+	supplier.Phone = strings.TrimSpace(req.Phone)
+	supplier.Address = req.Address
+	supplier.Website = req.Website
+	supplier.TaxNumber = req.TaxNumber
+	supplier.PaymentTerms = req.PaymentTerms
+	if supplier.PaymentTerms == "" {
+		supplier.PaymentTerms = models.SupplierTermsOnDelivery
+	}
+	supplier.PaymentDays = 0
+	if supplier.PaymentTerms == models.SupplierTermsNet {
+		supplier.PaymentDays = req.PaymentDays
+	}
+	supplier.Notes = req.Notes
+	if req.Active != nil {
+		supplier.Active = *req.Active
+	}
+	return nil
+}
+
+// CreateSupplier creates a supplier, active unless asked otherwise
+func (s *SupplierService) CreateSupplier(req *models.SupplierRequest) (*models.Suppliers, error) {
+	supplier := models.Suppliers{Active: true}
+	if err := s.applySupplier(&supplier, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&supplier).Error; err != nil {
+		return nil, err
+	}
+	return &supplier, nil
+}
+
+// UpdateSupplier updates a supplier
+func (s *SupplierService) UpdateSupplier(id uint, req *models.SupplierRequest) (*models.Suppliers, error) {
+	supplier, err := s.GetSupplierById(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applySupplier(supplier, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(supplier).Error; err != nil {
+		return nil, err
+	}
+	return supplier, nil
+}
+
+// DeleteSupplier soft-deletes a supplier and unlinks it from its products
+func (s *SupplierService) DeleteSupplier(id uint) (*models.Suppliers, error) {
+	supplier, err := s.GetSupplierById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("supplier_id = ?", supplier.ID).Delete(&models.ProductSuppliers{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(supplier).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return supplier, nil
+}
+
+// GetSupplierProducts retrieves the products bought from a supplier with what the supplier
+// charges for them
+func (s *SupplierService) GetSupplierProducts(id uint, params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	if _, err := s.GetSupplierById(id); err != nil {
+		return nil, err
+	}
+
+	config := pagination.PaginationConfig{
+		Model:         &models.ProductSuppliers{},
+		BaseCondition: map[string]interface{}{"supplier_id": id},
+		SearchFields:  []string{"supplier_sku"},
+		Relations:     []string{"Product"},
+		SortFields: []string{
+			"supplier_sku",
+			"cost",
+			"created_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetProductSuppliers retrieves the suppliers of a product, the preferred one first
+func (s *SupplierService) GetProductSuppliers(productID uint) ([]models.ProductSuppliers, error) {
+	var product models.Products
+	if err := s.db.Where("id = ?", productID).First(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+
+	suppliers := []models.ProductSuppliers{}
+	if err := s.db.Preload("Supplier").Where("product_id = ?", product.ID).Order("position").Find(&suppliers).Error; err != nil {
+		return nil, err
+	}
+	return suppliers, nil
+}
+
+// SetProductSuppliers replaces the suppliers of a product; their order in the request is the
+// order of preference
+func (s *SupplierService) SetProductSuppliers(productID uint, req *models.SetProductSuppliersRequest) ([]models.ProductSuppliers, error) {
+	var product models.Products
+	if err := s.db.Where("id = ?", productID).First(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+
+	ids := make([]uint, len(req.Suppliers))
+	for i, input := range req.Suppliers {
+		ids[i] = input.SupplierID
+	}
+	unique := uniqueIDs(ids)
+	if len(unique) != len(ids) {
+		return nil, errors.New("duplicate supplier")
+	}
+	if len(unique) > 0 {
+		var count int64
+		if err := s.db.Model(&models.Suppliers{}).Where("id IN ?", unique).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count != int64(len(unique)) {
+			return nil, errors.New("supplier not found")
+		}
+	}
+
+	links := make([]models.ProductSuppliers, len(req.Suppliers))
+	for i, input := range req.Suppliers {
+		links[i] = models.ProductSuppliers{
+			ProductID:        product.ID,
+			SupplierID:       input.SupplierID,
+			SupplierSKU:      strings.TrimSpace(input.SupplierSKU),
+			Cost:             input.Cost,
+			LeadTimeDays:     input.LeadTimeDays,
+			MinOrderQuantity: input.MinOrderQuantity,
+			Position:         i,
+		}
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductSuppliers{}).Error; err != nil {
+			return err
+		}
+		if len(links) == 0 {
+			return nil
+		}
+		return tx.Create(&links).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProductSuppliers(product.ID)
+}
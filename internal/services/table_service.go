@@ -0,0 +1,287 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// TableService manages the dining areas and tables of restaurants. The tickets open at tables
+// are carts of the table and kept by CartService.
+type TableService struct {
+	db *gorm.DB
+}
+
+func NewTableService(db *gorm.DB) *TableService {
+	return &TableService{
+		db: db,
+	}
+}
+
+// findDiningArea returns a dining area that isn't deleted
+func findDiningArea(db *gorm.DB, id uint) (*models.DiningAreas, error) {
+	var area models.DiningAreas
+	if err := db.Where("id = ?", id).First(&area).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("dining area not found")
+		}
+		return nil, err
+	}
+	return &area, nil
+}
+
+// findDiningTable returns a table that isn't deleted
+func findDiningTable(db *gorm.DB, id uint) (*models.DiningTables, error) {
+	var table models.DiningTables
+	if err := db.Where("id = ?", id).First(&table).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("table not found")
+		}
+		return nil, err
+	}
+	return &table, nil
+}
+
+// GetAllDiningAreas retrieves dining areas with pagination and search on name
+func (s *TableService) GetAllDiningAreas(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.DiningAreas{},
+		SearchFields: []string{"name"},
+		FilterFields: map[string]string{
+			"store_id": "store_id",
+		},
+		StoreFilter: "store_id = ?",
+		SortFields: []string{
+			"name",
+			"position",
+			"created_at",
+		},
+		DefaultSort:  "position",
+		DefaultOrder: "ASC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetDiningAreaById retrieves a dining area
+func (s *TableService) GetDiningAreaById(id uint) (*models.DiningAreas, error) {
+	return findDiningArea(s.db, id)
+}
+
+// applyDiningArea checks an area's store and copies the request onto the area
+func (s *TableService) applyDiningArea(area *models.DiningAreas, req *models.DiningAreaRequest) error {
+	if req.StoreID != nil {
+		if _, err := findStore(s.db, *req.StoreID); err != nil {
+			return err
+		}
+	}
+	area.Name = strings.TrimSpace(req.Name)
+	area.StoreID = req.StoreID
+	area.Position = req.Position
+	return nil
+}
+
+// CreateDiningArea creates a dining area
+func (s *TableService) CreateDiningArea(req *models.DiningAreaRequest) (*models.DiningAreas, error) {
+	var area models.DiningAreas
+	if err := s.applyDiningArea(&area, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&area).Error; err != nil {
+		return nil, err
+	}
+	return &area, nil
+}
+
+// UpdateDiningArea updates a dining area
+func (s *TableService) UpdateDiningArea(id uint, req *models.DiningAreaRequest) (*models.DiningAreas, error) {
+	area, err := findDiningArea(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyDiningArea(area, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(area).Error; err != nil {
+		return nil, err
+	}
+	return area, nil
+}
+
+// DeleteDiningArea soft-deletes a dining area. Areas are kept while they still have tables.
+func (s *TableService) DeleteDiningArea(id uint) (*models.DiningAreas, error) {
+	area, err := findDiningArea(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables int64
+	if err := s.db.Model(&models.DiningTables{}).Where("area_id = ?", area.ID).Count(&tables).Error; err != nil {
+		return nil, err
+	}
+	if tables > 0 {
+		return nil, errors.New("dining area has tables")
+	}
+
+	if err := s.db.Delete(area).Error; err != nil {
+		return nil, err
+	}
+	return area, nil
+}
+
+// GetAllTables retrieves tables with their area and open ticket, as a floor plan, with
+// pagination, search on table name and filters on area and whether a ticket is open
+func (s *TableService) GetAllTables(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model: &models.DiningTables{},
+		Joins: []pagination.JoinConfig{
+			{
+				Table:     "dining_areas",
+				Alias:     "da",
+				Type:      pagination.InnerJoin,
+				Condition: "da.id = dining_tables.area_id AND da.deleted_at IS NULL",
+			},
+			{
+				Table:     "carts",
+				Alias:     "c",
+				Type:      pagination.LeftJoin,
+				Condition: "c.table_id = dining_tables.id",
+			},
+		},
+		SelectFields: []pagination.SelectField{
+			{Field: "dining_tables.id", Alias: "id"},
+			{Field: "dining_tables.area_id", Alias: "area_id"},
+			{Field: "da.name", Alias: "area_name"},
+			{Field: "dining_tables.name", Alias: "name"},
+			{Field: "dining_tables.seats", Alias: "seats"},
+			{Field: "dining_tables.position", Alias: "position"},
+			{Field: "dining_tables.active", Alias: "active"},
+			{Field: "c.id", Alias: "ticket_id"},
+			{Field: "COALESCE(jsonb_array_length(c.lines), 0)", Alias: "ticket_lines"},
+			{Field: "c.created_at", Alias: "ticket_opened_at"},
+			{Field: "c.updated_at", Alias: "ticket_updated_at"},
+		},
+		ScanIntoMaps: true,
+		SearchFields: []string{"dining_tables.name"},
+		FilterFields: map[string]string{
+			"area_id": "dining_tables.area_id",
+			"active":  "dining_tables.active",
+		},
+		CustomFilters: map[string]string{
+			"store_id": "da.store_id = ?",
+			"occupied": "(c.id IS NOT NULL AND jsonb_array_length(c.lines) > 0) = ?",
+		},
+		StoreFilter: "da.store_id = ?",
+		SortFields: []string{
+			"name",
+			"area_name",
+			"position",
+			"ticket_opened_at",
+		},
+		DefaultSort:  "position",
+		DefaultOrder: "ASC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetTableById retrieves a table
+func (s *TableService) GetTableById(id uint) (*models.DiningTables, error) {
+	return findDiningTable(s.db, id)
+}
+
+// GetTicketTable returns a table tickets can be opened at: an active table in an area of the
+// store the request works in, if it works in one
+func (s *TableService) GetTicketTable(id uint, storeID *uint) (*models.DiningTables, error) {
+	table, err := findDiningTable(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	area, err := findDiningArea(s.db, table.AreaID)
+	if err != nil {
+		return nil, err
+	}
+	if storeID != nil && area.StoreID != nil && *area.StoreID != *storeID {
+		return nil, errors.New("table not found")
+	}
+	if !table.Active {
+		return nil, errors.New("table inactive")
+	}
+	return table, nil
+}
+
+// applyDiningTable checks a table's area and copies the request onto the table
+func (s *TableService) applyDiningTable(table *models.DiningTables, req *models.DiningTableRequest) error {
+	if _, err := findDiningArea(s.db, req.AreaID); err != nil {
+		return err
+	}
+	table.AreaID = req.AreaID
+	table.Name = strings.TrimSpace(req.Name)
+	table.Seats = req.Seats
+	table.Position = req.Position
+	if req.Active != nil {
+		table.Active = *req.Active
+	}
+	return nil
+}
+
+// CreateTable creates a table, active unless asked otherwise
+func (s *TableService) CreateTable(req *models.DiningTableRequest) (*models.DiningTables, error) {
+	table := models.DiningTables{Active: true}
+	if err := s.applyDiningTable(&table, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&table).Error; err != nil {
+		return nil, err
+	}
+	return &table, nil
+}
+
+// UpdateTable updates a table
+func (s *TableService) UpdateTable(id uint, req *models.DiningTableRequest) (*models.DiningTables, error) {
+	table, err := findDiningTable(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyDiningTable(table, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(table).Error; err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// DeleteTable soft-deletes a table. Tables with an open ticket are kept until it is checked
+// out, cleared or moved to another table.
+func (s *TableService) DeleteTable(id uint) (*models.DiningTables, error) {
+	table, err := findDiningTable(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets int64
+	if err := s.db.Model(&models.Carts{}).Where("table_id = ? AND jsonb_array_length(lines) > 0", table.ID).Count(&tickets).Error; err != nil {
+		return nil, err
+	}
+	if tickets > 0 {
+		return nil, errors.New("table has open ticket")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// An empty ticket left behind goes with the table
+		if err := tx.Where("table_id = ?", table.ID).Delete(&models.Carts{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(table).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return table, nil
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var tagNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+type TagService struct {
+	db              *gorm.DB
+	activityService *ActivityService
+	changes         *ChangeFeedService
+}
+
+func NewTagService(db *gorm.DB, activityService *ActivityService, changes *ChangeFeedService) *TagService {
+	return &TagService{
+		db:              db,
+		activityService: activityService,
+		changes:         changes,
+	}
+}
+
+// normalizeTagName lowercases and trims a tag name and checks that it is a valid label
+func normalizeTagName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !tagNamePattern.MatchString(name) {
+		return "", errors.New("invalid tag name")
+	}
+	return name, nil
+}
+
+// GetAllTags retrieves tags with pagination and search
+func (s *TagService) GetAllTags(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.Tags{},
+		SearchFields: []string{"name", "description"},
+		FilterFields: map[string]string{
+			"name": "name",
+		},
+		CustomFilters: map[string]string{
+			"user_id": "id IN (SELECT tag_id FROM user_tags WHERE user_id = ?)",
+		},
+		SortFields: []string{
+			"name",
+			"created_at",
+		},
+		DefaultSort:  "name",
+		DefaultOrder: "ASC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// CreateTag creates a tag
+func (s *TagService) CreateTag(req *models.TagRequest) (*models.Tags, error) {
+	name, err := normalizeTagName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing models.Tags
+	if err := s.db.Where("name = ?", name).First(&existing).Error; err == nil {
+		return nil, errors.New("tag name already exists")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	tag := models.Tags{
+		Name:        name,
+		Color:       req.Color,
+		Description: req.Description,
+	}
+	if err := s.db.Create(&tag).Error; err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+// UpdateTag renames or recolors a tag; users keep it
+func (s *TagService) UpdateTag(id uint, req *models.TagRequest) (*models.Tags, error) {
+	var tag models.Tags
+	if err := s.db.Where("id = ?", id).First(&tag).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tag not found")
+		}
+		return nil, err
+	}
+
+	name, err := normalizeTagName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing models.Tags
+	if err := s.db.Where("name = ? AND id <> ?", name, tag.ID).First(&existing).Error; err == nil {
+		return nil, errors.New("tag name already exists")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	tag.Name = name
+	tag.Color = req.Color
+	tag.Description = req.Description
+	if err := s.db.Save(&tag).Error; err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+// DeleteTag deletes a tag and removes it from every user
+func (s *TagService) DeleteTag(id uint) (*models.Tags, error) {
+	var tag models.Tags
+	var userIDs []uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", id).First(&tag).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("tag not found")
+			}
+			return err
+		}
+
+		if err := tx.Model(&models.UserTags{}).Where("tag_id = ?", tag.ID).Pluck("user_id", &userIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tag_id = ?", tag.ID).Delete(&models.UserTags{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&tag).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.changes.Publish(models.ChangeEntityUser, models.ChangeActionUpdated, userIDs...)
+	return &tag, nil
+}
+
+// findUserForTags loads the user whose tags are changed
+func findUserForTags(tx *gorm.DB, userID uint) (*models.Users, error) {
+	var user models.Users
+	if err := tx.Where("id = ? AND is_deleted = ?", userID, false).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// userTags returns the tags of a user ordered by name
+func userTags(tx *gorm.DB, userID uint) ([]models.Tags, error) {
+	tags := []models.Tags{}
+	err := tx.Where("id IN (SELECT tag_id FROM user_tags WHERE user_id = ?)", userID).Order("name ASC").Find(&tags).Error
+	return tags, err
+}
+
+// AssignUserTags adds tags to a user and returns the user's tags. Tags the user already has are left as they are.
+func (s *TagService) AssignUserTags(userID uint, req *models.AssignUserTagsRequest, actor models.ActivityActor) ([]models.Tags, error) {
+	var user *models.Users
+	var tags []models.Tags
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if user, err = findUserForTags(tx, userID); err != nil {
+			return err
+		}
+
+		var found []models.Tags
+		if err := tx.Where("id IN ?", req.TagIDs).Find(&found).Error; err != nil {
+			return err
+		}
+		if len(found) != len(uniqueIDs(req.TagIDs)) {
+			return errors.New("tag not found")
+		}
+
+		links := make([]models.UserTags, len(found))
+		names := make([]string, len(found))
+		for i, tag := range found {
+			links[i] = models.UserTags{UserID: user.ID, TagID: tag.ID}
+			names[i] = tag.Name
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected > 0 {
+			if err := s.activityService.RecordTx(tx, user.ID, models.ActivityTagsChanged, actor, "Tags added", models.JSONMap{
+				"added": names,
+			}); err != nil {
+				return err
+			}
+		}
+
+		tags, err = userTags(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.changes.Publish(models.ChangeEntityUser, models.ChangeActionUpdated, user.ID)
+	return tags, nil
+}
+
+// RemoveUserTag takes a tag off a user and returns the user's remaining tags
+func (s *TagService) RemoveUserTag(userID uint, tagID uint, actor models.ActivityActor) ([]models.Tags, error) {
+	var user *models.Users
+	var tags []models.Tags
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if user, err = findUserForTags(tx, userID); err != nil {
+			return err
+		}
+
+		var tag models.Tags
+		if err := tx.Where("id = ?", tagID).First(&tag).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("tag not found")
+			}
+			return err
+		}
+
+		result := tx.Where("user_id = ? AND tag_id = ?", user.ID, tag.ID).Delete(&models.UserTags{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("user does not have this tag")
+		}
+
+		if err := s.activityService.RecordTx(tx, user.ID, models.ActivityTagsChanged, actor, "Tag removed", models.JSONMap{
+			"removed": []string{tag.Name},
+		}); err != nil {
+			return err
+		}
+
+		tags, err = userTags(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.changes.Publish(models.ChangeEntityUser, models.ChangeActionUpdated, user.ID)
+	return tags, nil
+}
+
+// uniqueIDs returns the IDs with duplicates removed, keeping their order
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// TaxService manages tax classes and the rates charged on them
+type TaxService struct {
+	db *gorm.DB
+}
+
+func NewTaxService(db *gorm.DB) *TaxService {
+	return &TaxService{
+		db: db,
+	}
+}
+
+// GetAllTaxClasses retrieves tax classes with pagination
+func (s *TaxService) GetAllTaxClasses(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.TaxClasses{},
+		SearchFields: []string{"name", "description"},
+		SortFields: []string{
+			"name",
+			"created_at",
+		},
+		DefaultSort:  "name",
+		DefaultOrder: "ASC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetTaxClassById retrieves a tax class with its rates
+func (s *TaxService) GetTaxClassById(id uint) (*models.TaxClasses, error) {
+	var class models.TaxClasses
+	if err := s.db.Preload("Rates", func(db *gorm.DB) *gorm.DB {
+		return db.Order("region, id")
+	}).Where("id = ?", id).First(&class).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tax class not found")
+		}
+		return nil, err
+	}
+	return &class, nil
+}
+
+// checkTaxClassName makes sure no other tax class has the name
+func (s *TaxService) checkTaxClassName(name string, excludeID uint) error {
+	var count int64
+	if err := s.db.Model(&models.TaxClasses{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, excludeID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("tax class already exists")
+	}
+	return nil
+}
+
+// CreateTaxClass creates a tax class without rates; products in it are untaxed until rates
+// are added
+func (s *TaxService) CreateTaxClass(req *models.CreateTaxClassRequest) (*models.TaxClasses, error) {
+	if err := s.checkTaxClassName(req.Name, 0); err != nil {
+		return nil, err
+	}
+
+	class := models.TaxClasses{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := s.db.Create(&class).Error; err != nil {
+		return nil, err
+	}
+	return &class, nil
+}
+
+// UpdateTaxClass renames a tax class
+func (s *TaxService) UpdateTaxClass(id uint, req *models.UpdateTaxClassRequest) (*models.TaxClasses, error) {
+	class, err := s.GetTaxClassById(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkTaxClassName(req.Name, class.ID); err != nil {
+		return nil, err
+	}
+
+	class.Name = req.Name
+	class.Description = req.Description
+	if err := s.db.Omit("Rates").Save(class).Error; err != nil {
+		return nil, err
+	}
+	return class, nil
+}
+
+// DeleteTaxClass soft-deletes a tax class and its rates. Classes products are in can't be
+// deleted, since the products would silently become taxed at their lines' rates.
+func (s *TaxService) DeleteTaxClass(id uint) (*models.TaxClasses, error) {
+	class, err := s.GetTaxClassById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var products int64
+	if err := s.db.Model(&models.Products{}).Where("tax_class_id = ?", class.ID).Count(&products).Error; err != nil {
+		return nil, err
+	}
+	if products > 0 {
+		return nil, errors.New("tax class in use")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tax_class_id = ?", class.ID).Delete(&models.TaxRates{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(class).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return class, nil
+}
+
+// checkTaxRate makes sure a rate is either included in prices or added on top like the other
+// rates of its class in its region
+func (s *TaxService) checkTaxRate(classID uint, excludeID uint, req *models.TaxRateRequest) error {
+	var mismatched int64
+	err := s.db.Model(&models.TaxRates{}).
+		Where("tax_class_id = ? AND region = ? AND id <> ? AND inclusive <> ?", classID, strings.TrimSpace(req.Region), excludeID, req.Inclusive).
+		Count(&mismatched).Error
+	if err != nil {
+		return err
+	}
+	if mismatched > 0 {
+		return errors.New("tax rates of a region must all be inclusive or exclusive")
+	}
+	return nil
+}
+
+// findTaxRate looks up a rate of a tax class
+func (s *TaxService) findTaxRate(classID uint, rateID uint) (*models.TaxRates, error) {
+	var rate models.TaxRates
+	if err := s.db.Where("id = ? AND tax_class_id = ?", rateID, classID).First(&rate).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tax rate not found")
+		}
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// CreateTaxRate adds a rate to a tax class. Orders already placed keep the taxes they were
+// charged.
+func (s *TaxService) CreateTaxRate(classID uint, req *models.TaxRateRequest) (*models.TaxRates, error) {
+	if _, err := s.GetTaxClassById(classID); err != nil {
+		return nil, err
+	}
+	if err := s.checkTaxRate(classID, 0, req); err != nil {
+		return nil, err
+	}
+
+	rate := models.TaxRates{
+		TaxClassID: classID,
+		Name:       req.Name,
+		Region:     strings.TrimSpace(req.Region),
+		Rate:       req.Rate,
+		Inclusive:  req.Inclusive,
+	}
+	if err := s.db.Create(&rate).Error; err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// UpdateTaxRate changes a rate of a tax class
+func (s *TaxService) UpdateTaxRate(classID uint, rateID uint, req *models.TaxRateRequest) (*models.TaxRates, error) {
+	rate, err := s.findTaxRate(classID, rateID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkTaxRate(classID, rate.ID, req); err != nil {
+		return nil, err
+	}
+
+	rate.Name = req.Name
+	rate.Region = strings.TrimSpace(req.Region)
+	rate.Rate = req.Rate
+	rate.Inclusive = req.Inclusive
+	if err := s.db.Save(rate).Error; err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
+
+// DeleteTaxRate soft-deletes a rate of a tax class
+func (s *TaxService) DeleteTaxRate(classID uint, rateID uint) (*models.TaxRates, error) {
+	rate, err := s.findTaxRate(classID, rateID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Delete(rate).Error; err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
+
+// classTaxes returns the taxes charged on each of the tax classes in a region: the class's
+// rates of the region, or its rates without a region if it has none there
+func classTaxes(db *gorm.DB, classIDs []uint, region string) (map[uint]models.LineTaxes, error) {
+	taxes := map[uint]models.LineTaxes{}
+	if len(classIDs) == 0 {
+		return taxes, nil
+	}
+
+	region = strings.TrimSpace(region)
+	var rates []models.TaxRates
+	if err := db.Where("tax_class_id IN ? AND region IN ?", classIDs, []string{region, ""}).Order("id").Find(&rates).Error; err != nil {
+		return nil, err
+	}
+
+	regional := map[uint]bool{}
+	for _, rate := range rates {
+		if region != "" && rate.Region == region {
+			regional[rate.TaxClassID] = true
+		}
+	}
+	for _, rate := range rates {
+		if regional[rate.TaxClassID] != (region != "" && rate.Region == region) {
+			continue
+		}
+		taxes[rate.TaxClassID] = append(taxes[rate.TaxClassID], models.LineTax{
+			TaxRateID: &rate.ID,
+			Name:      rate.Name,
+			Rate:      rate.Rate,
+			Inclusive: rate.Inclusive,
+		})
+	}
+	return taxes, nil
+}
+
+// manualLineTaxes returns the taxes of a line of a product without a tax class, charged at
+// the rate given with the line
+func manualLineTaxes(rate int64) models.LineTaxes {
+	if rate == 0 {
+		return models.LineTaxes{}
+	}
+	return models.LineTaxes{{Name: "Tax", Rate: rate}}
+}
+
+// includedTax returns the part of a line's tax included in its price
+func includedTax(item models.OrderItems) int64 {
+	if len(item.Taxes) > 0 && item.Taxes[0].Inclusive {
+		return item.Tax
+	}
+	return 0
+}
+
+// applyLineTax works out a line's taxes from its amount after discount. Tax added on top is
+// charged per rate; tax included in the price is taken out once for all rates and split
+// between them in proportion, so the line's total stays the price charged.
+func applyLineTax(item *models.OrderItems) {
+	amount := item.Subtotal - item.Discount
+	item.TaxRate = 0
+	for _, tax := range item.Taxes {
+		item.TaxRate += tax.Rate
+	}
+
+	item.Tax = 0
+	if len(item.Taxes) > 0 && item.Taxes[0].Inclusive {
+		if item.TaxRate > 0 {
+			net := (amount*10000 + (10000+item.TaxRate)/2) / (10000 + item.TaxRate)
+			item.Tax = amount - net
+		}
+		left := item.Tax
+		for i := range item.Taxes {
+			share := left
+			if i < len(item.Taxes)-1 && item.TaxRate > 0 {
+				share = item.Tax * item.Taxes[i].Rate / item.TaxRate
+			}
+			item.Taxes[i].Amount = share
+			left -= share
+		}
+		item.Total = amount
+		return
+	}
+
+	for i := range item.Taxes {
+		item.Taxes[i].Amount = lineTax(amount, item.Taxes[i].Rate)
+		item.Tax += item.Taxes[i].Amount
+	}
+	item.Total = amount + item.Tax
+}
+
+// taxBreakdown adds up the taxes of lines per rate, in the order the rates first appear
+func taxBreakdown(items []models.OrderItems) []models.TaxBreakdown {
+	breakdown := []models.TaxBreakdown{}
+	for _, item := range items {
+		taxable := item.Subtotal - item.Discount - includedTax(item)
+		for _, tax := range item.Taxes {
+			i := 0
+			for ; i < len(breakdown); i++ {
+				b := breakdown[i]
+				if sameOptionalID(b.TaxRateID, tax.TaxRateID) && b.Name == tax.Name && b.Rate == tax.Rate && b.Inclusive == tax.Inclusive {
+					break
+				}
+			}
+			if i == len(breakdown) {
+				breakdown = append(breakdown, models.TaxBreakdown{
+					TaxRateID: tax.TaxRateID,
+					Name:      tax.Name,
+					Rate:      tax.Rate,
+					Inclusive: tax.Inclusive,
+				})
+			}
+			breakdown[i].Taxable += taxable
+			breakdown[i].Amount += tax.Amount
+		}
+	}
+	return breakdown
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+)
+
+type TeamService struct {
+	db *gorm.DB
+}
+
+func NewTeamService(db *gorm.DB) *TeamService {
+	return &TeamService{db: db}
+}
+
+// GetAllTeams retrieves teams with pagination and search
+func (s *TeamService) GetAllTeams(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.Teams{},
+		SearchFields: []string{"name", "description"},
+		FilterFields: map[string]string{
+			"name": "name",
+		},
+		CustomFilters: map[string]string{
+			"user_id": "id IN (SELECT team_id FROM team_members WHERE user_id = ?)",
+		},
+		DateFields: map[string]pagination.DateField{
+			"created_at": {
+				Start: "created_at",
+				End:   "created_at",
+			},
+		},
+		SortFields: []string{
+			"name",
+			"created_at",
+			"updated_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetTeamById retrieves a team together with its members
+func (s *TeamService) GetTeamById(id uint) (*models.Teams, error) {
+	var team models.Teams
+	if err := s.db.Preload("Members.User").Where("id = ?", id).First(&team).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("team not found")
+		}
+		return nil, err
+	}
+	return &team, nil
+}
+
+// CreateTeam creates a new team
+func (s *TeamService) CreateTeam(req *models.CreateTeamRequest) (*models.Teams, error) {
+	// Check if team name already exists
+	var existingTeam models.Teams
+	if err := s.db.Where("name = ?", req.Name).First(&existingTeam).Error; err == nil {
+		return nil, errors.New("team name already exists")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	team := models.Teams{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+
+	if err := s.db.Create(&team).Error; err != nil {
+		return nil, err
+	}
+
+	return &team, nil
+}
+
+// UpdateTeam updates a team's details
+func (s *TeamService) UpdateTeam(id uint, req *models.UpdateTeamRequest) (*models.Teams, error) {
+	var team models.Teams
+	if err := s.db.Where("id = ?", id).First(&team).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("team not found")
+		}
+		return nil, err
+	}
+
+	// Check if the new name is taken by another team
+	var existingTeam models.Teams
+	if err := s.db.Where("name = ? AND id <> ?", req.Name, team.ID).First(&existingTeam).Error; err == nil {
+		return nil, errors.New("team name already exists")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	team.Name = req.Name
+	team.Description = req.Description
+
+	if err := s.db.Save(&team).Error; err != nil {
+		return nil, err
+	}
+
+	return &team, nil
+}
+
+// DeleteTeam deletes a team and its memberships
+func (s *TeamService) DeleteTeam(id uint) (*models.Teams, error) {
+	var team models.Teams
+	if err := s.db.Where("id = ?", id).First(&team).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("team not found")
+		}
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("team_id = ?", team.ID).Delete(&models.TeamMembers{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&team).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &team, nil
+}
+
+// GetTeamMembers lists the members of a team
+func (s *TeamService) GetTeamMembers(teamID uint) ([]models.TeamMembers, error) {
+	if _, err := s.GetTeamById(teamID); err != nil {
+		return nil, err
+	}
+
+	var members []models.TeamMembers
+	if err := s.db.Preload("User").Where("team_id = ?", teamID).Order("created_at ASC").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// AddTeamMember adds a user to a team with the given team role
+func (s *TeamService) AddTeamMember(teamID uint, req *models.AddTeamMemberRequest) (*models.TeamMembers, error) {
+	team, err := s.GetTeamById(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.Users
+	if err := s.db.Where("id = ? AND is_deleted = ?", req.UserID, false).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	var existingMember models.TeamMembers
+	if err := s.db.Where("team_id = ? AND user_id = ?", team.ID, user.ID).First(&existingMember).Error; err == nil {
+		return nil, errors.New("user is already a team member")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	member := models.TeamMembers{
+		TeamID: team.ID,
+		UserID: user.ID,
+		Role:   req.Role,
+	}
+
+	if err := s.db.Create(&member).Error; err != nil {
+		return nil, err
+	}
+
+	member.User = &user
+	return &member, nil
+}
+
+// UpdateTeamMember changes a member's role within a team
+func (s *TeamService) UpdateTeamMember(teamID, userID uint, req *models.UpdateTeamMemberRequest) (*models.TeamMembers, error) {
+	var member models.TeamMembers
+	if err := s.db.Preload("User").Where("team_id = ? AND user_id = ?", teamID, userID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("team member not found")
+		}
+		return nil, err
+	}
+
+	if err := s.db.Model(&member).Update("role", req.Role).Error; err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// RemoveTeamMember removes a user from a team
+func (s *TeamService) RemoveTeamMember(teamID, userID uint) (*models.TeamMembers, error) {
+	var member models.TeamMembers
+	if err := s.db.Where("team_id = ? AND user_id = ?", teamID, userID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("team member not found")
+		}
+		return nil, err
+	}
+
+	if err := s.db.Delete(&member).Error; err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// GetMemberRole returns the user's role within a team, or an empty string if they are not a member
+func (s *TeamService) GetMemberRole(teamID uint, userID uint) (string, error) {
+	var member models.TeamMembers
+	if err := s.db.Where("team_id = ? AND user_id = ?", teamID, userID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return member.Role, nil
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// tenantCacheTTL bounds how long a host lookup is reused before hitting the database again
+const tenantCacheTTL = time.Minute
+
+// tenantCacheEntry is a cached host lookup; a nil tenant means the host has no tenant
+type tenantCacheEntry struct {
+	tenant    *models.Tenants
+	expiresAt time.Time
+}
+
+type TenantService struct {
+	db    *gorm.DB
+	mu    sync.RWMutex
+	hosts map[string]tenantCacheEntry
+}
+
+func NewTenantService(db *gorm.DB) *TenantService {
+	return &TenantService{
+		db:    db,
+		hosts: map[string]tenantCacheEntry{},
+	}
+}
+
+// NormalizeHost lowercases a Host header value and strips its port and trailing dot
+func NormalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// ResolveHost returns the active tenant serving the given host, or nil when there is none.
+// Lookups are cached briefly since this runs on every request.
+func (s *TenantService) ResolveHost(host string) (*models.Tenants, error) {
+	host = NormalizeHost(host)
+	if host == "" {
+		return nil, nil
+	}
+
+	s.mu.RLock()
+	entry, ok := s.hosts[host]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.tenant, nil
+	}
+
+	var tenant *models.Tenants
+	var found models.Tenants
+	if err := s.db.Where("host = ? AND is_active = ?", host, true).First(&found).Error; err == nil {
+		tenant = &found
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.hosts[host] = tenantCacheEntry{tenant: tenant, expiresAt: time.Now().Add(tenantCacheTTL)}
+	s.mu.Unlock()
+
+	return tenant, nil
+}
+
+// invalidate drops every cached host lookup after tenant settings change
+func (s *TenantService) invalidate() {
+	s.mu.Lock()
+	s.hosts = map[string]tenantCacheEntry{}
+	s.mu.Unlock()
+}
+
+// GetAllTenants retrieves tenants with pagination, search, and filters
+func (s *TenantService) GetAllTenants(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.Tenants{},
+		SearchFields: []string{"name", "host"},
+		FilterFields: map[string]string{
+			"is_active": "is_active",
+		},
+		SortFields: []string{
+			"name",
+			"host",
+			"created_at",
+		},
+		DefaultSort:  "name",
+		DefaultOrder: "ASC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetTenantById retrieves a tenant by ID
+func (s *TenantService) GetTenantById(id uint) (*models.Tenants, error) {
+	var tenant models.Tenants
+	if err := s.db.Where("id = ?", id).First(&tenant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tenant not found")
+		}
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+// CreateTenant creates a tenant for a new host
+func (s *TenantService) CreateTenant(req *models.CreateTenantRequest) (*models.Tenants, error) {
+	host := NormalizeHost(req.Host)
+	if err := s.checkHostAvailable(host, 0); err != nil {
+		return nil, err
+	}
+
+	tenant := models.Tenants{
+		Name:               req.Name,
+		Host:               host,
+		CORSAllowedOrigins: models.JSONStringList(req.CORSAllowedOrigins),
+		CookieDomain:       req.CookieDomain,
+		CookieSecure:       req.CookieSecure,
+		IsActive:           req.IsActive == nil || *req.IsActive,
+		Status:             models.TenantStatusActive,
+	}
+
+	// Write every column so an inactive tenant is not replaced by the is_active default
+	if err := s.db.Select("*").Omit("id").Create(&tenant).Error; err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return &tenant, nil
+}
+
+// UpdateTenant updates a tenant's host, CORS origins and cookie settings
+func (s *TenantService) UpdateTenant(id uint, req *models.UpdateTenantRequest) (*models.Tenants, error) {
+	tenant, err := s.GetTenantById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	host := NormalizeHost(req.Host)
+	if err := s.checkHostAvailable(host, tenant.ID); err != nil {
+		return nil, err
+	}
+
+	tenant.Name = req.Name
+	tenant.Host = host
+	tenant.CORSAllowedOrigins = models.JSONStringList(req.CORSAllowedOrigins)
+	tenant.CookieDomain = req.CookieDomain
+	tenant.CookieSecure = req.CookieSecure
+	if req.IsActive != nil {
+		tenant.IsActive = *req.IsActive
+	}
+
+	if err := s.db.Save(tenant).Error; err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return tenant, nil
+}
+
+// DeleteTenant deletes a tenant; its host falls back to the global configuration
+func (s *TenantService) DeleteTenant(id uint) (*models.Tenants, error) {
+	tenant, err := s.GetTenantById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Delete(tenant).Error; err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return tenant, nil
+}
+
+// checkHostAvailable ensures no other tenant already serves the host
+func (s *TenantService) checkHostAvailable(host string, excludeID uint) error {
+	var existing models.Tenants
+	if err := s.db.Where("host = ? AND id <> ?", host, excludeID).First(&existing).Error; err == nil {
+		return errors.New("tenant host already exists")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
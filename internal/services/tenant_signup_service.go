@@ -0,0 +1,265 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/config"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/mailer"
+	"github.com/Aebroyx/the-blade-api/internal/password"
+	"gorm.io/gorm"
+)
+
+// tenantVerificationTTL is how long a tenant signup waits for its owner to verify their email
+const tenantVerificationTTL = 24 * time.Hour
+
+// TenantSignupService runs the public tenant registration flow. A signup provisions the
+// tenant, its first administrator and optional demo data in one transaction; both stay
+// inactive until the administrator verifies their email address.
+type TenantSignupService struct {
+	db              *gorm.DB
+	config          *config.Config
+	passwords       *password.Manager
+	tenantService   *TenantService
+	activityService *ActivityService
+	notifications   *NotificationService
+}
+
+func NewTenantSignupService(db *gorm.DB, config *config.Config, passwords *password.Manager, tenantService *TenantService, activityService *ActivityService, notifications *NotificationService) *TenantSignupService {
+	return &TenantSignupService{
+		db:              db,
+		config:          config,
+		passwords:       passwords,
+		tenantService:   tenantService,
+		activityService: activityService,
+		notifications:   notifications,
+	}
+}
+
+// Signup provisions a pending tenant and its first administrator, then emails the verification link
+func (s *TenantSignupService) Signup(req *models.TenantSignupRequest, actor models.ActivityActor) (*models.TenantProvisioningStatus, error) {
+	host := NormalizeHost(req.Host)
+
+	hashedPassword, err := s.passwords.Hash(req.AdminPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := generateLinkToken()
+	if err != nil {
+		return nil, err
+	}
+	tokenHash := hashLinkToken(token)
+	expiresAt := time.Now().Add(tenantVerificationTTL)
+
+	var tenant models.Tenants
+	var admin models.Users
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.releaseExpiredSignup(tx, host); err != nil {
+			return err
+		}
+
+		var existingTenant models.Tenants
+		if err := tx.Where("host = ?", host).First(&existingTenant).Error; err == nil {
+			return errors.New("tenant host already exists")
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		var existingUser models.Users
+		if err := tx.Where("username = ?", req.AdminUsername).First(&existingUser).Error; err == nil {
+			return errors.New("username already exists")
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err := tx.Where("email = ?", req.AdminEmail).First(&existingUser).Error; err == nil {
+			return errors.New("email already exists")
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		admin = models.Users{
+			Username: req.AdminUsername,
+			Email:    req.AdminEmail,
+			Password: hashedPassword,
+			Name:     req.AdminName,
+			Role:     models.RoleAdmin,
+			Status:   models.UserStatusPending,
+		}
+		if err := tx.Create(&admin).Error; err != nil {
+			return err
+		}
+
+		tenant = models.Tenants{
+			Name:                  req.TenantName,
+			Host:                  host,
+			CORSAllowedOrigins:    models.JSONStringList{"https://" + host},
+			CookieSecure:          true,
+			IsActive:              false,
+			Status:                models.TenantStatusPendingVerification,
+			OwnerID:               &admin.ID,
+			VerificationTokenHash: &tokenHash,
+			VerificationExpiresAt: &expiresAt,
+		}
+		// Write every column so the tenant is not activated by the is_active default
+		if err := tx.Select("*").Omit("id").Create(&tenant).Error; err != nil {
+			return err
+		}
+
+		// The first administrator leads the tenant's staff team
+		team := models.Teams{
+			Name:        fmt.Sprintf("%s staff", host),
+			Description: fmt.Sprintf("Staff of %s", req.TenantName),
+		}
+		if err := tx.Create(&team).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.TeamMembers{TeamID: team.ID, UserID: admin.ID, Role: models.TeamRoleLead}).Error; err != nil {
+			return err
+		}
+
+		if req.DemoData {
+			device := models.Devices{
+				Name:     "Demo register",
+				Location: host,
+				Status:   models.DeviceStatusPending,
+			}
+			if err := tx.Create(&device).Error; err != nil {
+				return err
+			}
+		}
+
+		actor.UserID = admin.ID
+		return s.activityService.RecordTx(tx, admin.ID, models.ActivityRegistered, actor, fmt.Sprintf("Registered tenant %s", req.TenantName), models.JSONMap{
+			"tenant_id": tenant.ID,
+			"host":      host,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.sendVerificationMail(admin, tenant, token)
+
+	return provisioningStatus(tenant), nil
+}
+
+// releaseExpiredSignup removes an unverified signup whose link expired, freeing its host,
+// username and email for a new attempt
+func (s *TenantSignupService) releaseExpiredSignup(tx *gorm.DB, host string) error {
+	var tenant models.Tenants
+	err := tx.Where("host = ? AND status = ? AND verification_expires_at < ?", host, models.TenantStatusPendingVerification, time.Now()).First(&tenant).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if tenant.OwnerID != nil {
+		if err := tx.Where("user_id = ?", *tenant.OwnerID).Delete(&models.TeamMembers{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", *tenant.OwnerID).Delete(&models.UserActivities{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", *tenant.OwnerID).Delete(&models.LoginEvents{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("id = ? AND status = ?", *tenant.OwnerID, models.UserStatusPending).Delete(&models.Users{}).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Unscoped().Where("name = ?", fmt.Sprintf("%s staff", host)).Delete(&models.Teams{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("location = ? AND status = ?", host, models.DeviceStatusPending).Delete(&models.Devices{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&tenant).Error
+}
+
+// sendVerificationMail emails the verification link to the tenant's first administrator.
+// Failures are logged; the signup can be retried once the link has expired.
+func (s *TenantSignupService) sendVerificationMail(admin models.Users, tenant models.Tenants, token string) {
+	link := fmt.Sprintf("%s/verify-tenant?token=%s", s.config.FrontendURL, token)
+
+	message := mailer.Message{
+		To:      admin.Email,
+		Subject: fmt.Sprintf("Verify your email to activate %s", tenant.Name),
+		Body: fmt.Sprintf("Hi %s,\n\nThanks for signing up %s. Please verify your email address by opening the link below "+
+			"to activate your account. The link expires in %d hours.\n\n%s\n\nIf you did not sign up, you can ignore this email.",
+			admin.Name, tenant.Name, int(tenantVerificationTTL.Hours()), link),
+	}
+	if err := s.notifications.SendEmail(admin.ID, models.NotificationEventAccountSecurity, message); err != nil {
+		slog.Error("Failed to send tenant verification", "tenant_id", tenant.ID, "error", err)
+	}
+}
+
+// VerifySignup activates the tenant and its administrator for a valid verification token
+func (s *TenantSignupService) VerifySignup(token string) (*models.TenantProvisioningStatus, error) {
+	var tenant models.Tenants
+	if err := s.db.Where("verification_token_hash = ?", hashLinkToken(token)).First(&tenant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid verification token")
+		}
+		return nil, err
+	}
+
+	if tenant.VerificationExpiresAt == nil || time.Now().After(*tenant.VerificationExpiresAt) {
+		return nil, errors.New("verification token expired")
+	}
+
+	now := time.Now()
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&tenant).Updates(map[string]interface{}{
+			"status":                  models.TenantStatusActive,
+			"is_active":               true,
+			"verification_token_hash": nil,
+			"verification_expires_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+		if tenant.OwnerID == nil {
+			return nil
+		}
+		return tx.Model(&models.Users{}).Where("id = ?", *tenant.OwnerID).Updates(map[string]interface{}{
+			"status":            models.UserStatusActive,
+			"status_changed_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tenant.Status = models.TenantStatusActive
+	tenant.IsActive = true
+	s.tenantService.invalidate()
+
+	return provisioningStatus(tenant), nil
+}
+
+// GetProvisioningStatus reports the signup progress of the tenant serving a host
+func (s *TenantSignupService) GetProvisioningStatus(host string) (*models.TenantProvisioningStatus, error) {
+	var tenant models.Tenants
+	if err := s.db.Where("host = ?", NormalizeHost(host)).First(&tenant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tenant not found")
+		}
+		return nil, err
+	}
+	return provisioningStatus(tenant), nil
+}
+
+// provisioningStatus describes a tenant's signup progress without exposing its settings
+func provisioningStatus(tenant models.Tenants) *models.TenantProvisioningStatus {
+	return &models.TenantProvisioningStatus{
+		Host:     tenant.Host,
+		Name:     tenant.Name,
+		Status:   tenant.Status,
+		IsActive: tenant.IsActive,
+	}
+}
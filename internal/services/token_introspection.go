@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// IntrospectToken checks an access token as the auth middleware does: its signature and
+// expiry, and that its user still exists and may sign in. Tokens failing a check are inactive,
+// with the reason; only a failing database is an error.
+func (s *UserService) IntrospectToken(ctx context.Context, accessToken string) (*models.TokenIntrospection, error) {
+	claims := &models.Claims{}
+	token, err := jwt.ParseWithClaims(accessToken, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(s.config.JWTSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return &models.TokenIntrospection{Reason: models.TokenInactiveExpired}, nil
+	}
+	if err != nil || !token.Valid {
+		return &models.TokenIntrospection{Reason: models.TokenInactiveInvalid}, nil
+	}
+
+	var user models.Users
+	if err := s.db.WithContext(ctx).First(&user, claims.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.TokenIntrospection{Reason: models.TokenInactiveUserNotFound}, nil
+		}
+		return nil, err
+	}
+	switch user.Status {
+	case models.UserStatusSuspended:
+		return &models.TokenIntrospection{Reason: models.TokenInactiveSuspended}, nil
+	case models.UserStatusPending:
+		return &models.TokenIntrospection{Reason: models.TokenInactivePending}, nil
+	}
+
+	introspection := &models.TokenIntrospection{Active: true, User: &user}
+	if claims.IssuedAt != nil {
+		introspection.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		introspection.ExpiresAt = claims.ExpiresAt.Time
+	}
+	return introspection, nil
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// transferNextStatuses lists the statuses a transfer in each status can move to
+var transferNextStatuses = map[string][]string{
+	models.TransferStatusRequested: {models.TransferStatusApproved, models.TransferStatusCancelled},
+	models.TransferStatusApproved:  {models.TransferStatusSent, models.TransferStatusCancelled},
+	models.TransferStatusSent:      {models.TransferStatusReceived, models.TransferStatusCancelled},
+}
+
+// TransferService moves stock between stores. Transfers are requested, approved by someone
+// allowed to, sent and finally received, which is when the stock moves.
+type TransferService struct {
+	db        *gorm.DB
+	inventory *InventoryService
+}
+
+func NewTransferService(db *gorm.DB, inventory *InventoryService) *TransferService {
+	return &TransferService{
+		db:        db,
+		inventory: inventory,
+	}
+}
+
+// transferNumber is the human-facing number of a transfer
+func transferNumber(id uint) string {
+	return fmt.Sprintf("TR-%06d", id)
+}
+
+// GetAllTransfers retrieves transfers with pagination and filters on stores and status. Within a
+// store, transfers from and to it are listed.
+func (s *TransferService) GetAllTransfers(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.StockTransfers{},
+		SearchFields: []string{"number", "note"},
+		FilterFields: map[string]string{
+			"status":               "status",
+			"source_store_id":      "source_store_id",
+			"destination_store_id": "destination_store_id",
+			"requested_by_id":      "requested_by_id",
+		},
+		CustomFilters: map[string]string{
+			"product_id": "id IN (SELECT transfer_id FROM stock_transfer_items WHERE product_id = ?)",
+		},
+		StoreFilter: "? IN (source_store_id, destination_store_id)",
+		DateFields: map[string]pagination.DateField{
+			"created_at": {
+				Start: "created_at",
+				End:   "created_at",
+			},
+			"received_at": {
+				Start: "received_at",
+				End:   "received_at",
+			},
+		},
+		SortFields: []string{
+			"number",
+			"status",
+			"created_at",
+			"sent_at",
+			"received_at",
+		},
+		DefaultSort:  "created_at",
+		DefaultOrder: "DESC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetInTransit retrieves the lines of sent transfers that haven't been received yet, with the
+// product's name and SKU. Within a store, stock on its way from and to it is listed.
+func (s *TransferService) GetInTransit(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model: &models.StockTransferItems{},
+		Joins: []pagination.JoinConfig{
+			{
+				Table:     "stock_transfers",
+				Alias:     "t",
+				Type:      pagination.InnerJoin,
+				Condition: "t.id = stock_transfer_items.transfer_id",
+			},
+			{
+				Table:     "products",
+				Alias:     "p",
+				Type:      pagination.InnerJoin,
+				Condition: "p.id = stock_transfer_items.product_id",
+			},
+			{
+				Table:     "product_variants",
+				Alias:     "pv",
+				Type:      pagination.LeftJoin,
+				Condition: "pv.id = stock_transfer_items.variant_id",
+			},
+		},
+		SelectFields: []pagination.SelectField{
+			{Field: "t.id", Alias: "transfer_id"},
+			{Field: "t.number", Alias: "number"},
+			{Field: "t.source_store_id", Alias: "source_store_id"},
+			{Field: "t.destination_store_id", Alias: "destination_store_id"},
+			{Field: "stock_transfer_items.product_id", Alias: "product_id"},
+			{Field: "stock_transfer_items.variant_id", Alias: "variant_id"},
+			{Field: "p.name", Alias: "product_name"},
+			{Field: "pv.title", Alias: "variant_title"},
+			{Field: "COALESCE(pv.sku, p.sku)", Alias: "sku"},
+			{Field: "stock_transfer_items.quantity", Alias: "quantity"},
+			{Field: "t.sent_at", Alias: "sent_at"},
+		},
+		ScanIntoMaps:  true,
+		BaseCondition: map[string]interface{}{"t.status": models.TransferStatusSent},
+		SearchFields:  []string{"t.number", "p.name", "p.sku", "pv.sku"},
+		FilterFields: map[string]string{
+			"product_id":           "stock_transfer_items.product_id",
+			"variant_id":           "stock_transfer_items.variant_id",
+			"source_store_id":      "t.source_store_id",
+			"destination_store_id": "t.destination_store_id",
+		},
+		StoreFilter: "? IN (t.source_store_id, t.destination_store_id)",
+		SortFields: []string{
+			"product_name",
+			"sku",
+			"quantity",
+			"sent_at",
+		},
+		DefaultSort:  "sent_at",
+		DefaultOrder: "ASC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetTransferById retrieves a transfer with its lines
+func (s *TransferService) GetTransferById(id uint) (*models.StockTransfers, error) {
+	var transfer models.StockTransfers
+	if err := s.db.Preload("Items").Where("id = ?", id).First(&transfer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("transfer not found")
+		}
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// CreateTransfer requests a transfer between two active stores. A product or variant is listed
+// once per transfer.
+func (s *TransferService) CreateTransfer(req *models.CreateStockTransferRequest, actor models.ActivityActor) (*models.StockTransfers, error) {
+	for _, storeID := range []uint{req.SourceStoreID, req.DestinationStoreID} {
+		store, err := findStore(s.db, storeID)
+		if err != nil {
+			return nil, err
+		}
+		if !store.Active {
+			return nil, errors.New("store inactive")
+		}
+	}
+
+	inputs := make([]models.StockMovementInput, len(req.Items))
+	items := make([]models.StockTransferItems, len(req.Items))
+	seen := make(map[stockLevelKey]bool, len(req.Items))
+	for i, item := range req.Items {
+		inputs[i] = models.StockMovementInput{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Type:      models.StockMovementTransfer,
+			Quantity:  item.Quantity,
+			Serials:   item.Serials,
+			Batch:     item.Batch,
+		}
+		key := stockKey(inputs[i])
+		if seen[key] {
+			return nil, errors.New("duplicate transfer line")
+		}
+		seen[key] = true
+		items[i] = models.StockTransferItems{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+			Serials:   models.JSONStringList(item.Serials),
+			Batch:     item.Batch,
+		}
+	}
+	if err := checkMovementTargets(s.db, inputs); err != nil {
+		return nil, err
+	}
+	if err := s.inventory.checkTracking(s.db, inputs); err != nil {
+		return nil, err
+	}
+
+	transfer := models.StockTransfers{
+		SourceStoreID:      req.SourceStoreID,
+		DestinationStoreID: req.DestinationStoreID,
+		Status:             models.TransferStatusRequested,
+		Note:               req.Note,
+		RequestedByID:      actorID(actor),
+		Items:              items,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&transfer).Error; err != nil {
+			return err
+		}
+		transfer.Number = transferNumber(transfer.ID)
+		return tx.Model(&transfer).UpdateColumn("number", transfer.Number).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// transition moves a transfer to a status, running fn in the same transaction first
+func (s *TransferService) transition(id uint, status string, fn func(tx *gorm.DB, transfer *models.StockTransfers) error) (*models.StockTransfers, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var transfer models.StockTransfers
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").Where("id = ?", id).First(&transfer).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("transfer not found")
+			}
+			return err
+		}
+
+		if !slices.Contains(transferNextStatuses[transfer.Status], status) {
+			return errors.New("invalid status transition")
+		}
+
+		transfer.Status = status
+		if err := fn(tx, &transfer); err != nil {
+			return err
+		}
+		return tx.Omit("Items").Save(&transfer).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTransferById(id)
+}
+
+// ApproveTransfer approves a requested transfer so it can be sent
+func (s *TransferService) ApproveTransfer(id uint, actor models.ActivityActor) (*models.StockTransfers, error) {
+	return s.transition(id, models.TransferStatusApproved, func(tx *gorm.DB, transfer *models.StockTransfers) error {
+		now := time.Now()
+		transfer.ApprovedAt = &now
+		transfer.ApprovedByID = actorID(actor)
+		return nil
+	})
+}
+
+// SendTransfer records that an approved transfer left the source store; its lines are in
+// transit until the destination receives them
+func (s *TransferService) SendTransfer(id uint, actor models.ActivityActor) (*models.StockTransfers, error) {
+	return s.transition(id, models.TransferStatusSent, func(tx *gorm.DB, transfer *models.StockTransfers) error {
+		now := time.Now()
+		transfer.SentAt = &now
+		transfer.SentByID = actorID(actor)
+		return nil
+	})
+}
+
+// ReceiveTransfer records that the destination received a sent transfer, moving its stock out of
+// the source store and into the destination in one transaction
+func (s *TransferService) ReceiveTransfer(id uint, actor models.ActivityActor) (*models.StockTransfers, error) {
+	var inputs []models.StockMovementInput
+	received, err := s.transition(id, models.TransferStatusReceived, func(tx *gorm.DB, transfer *models.StockTransfers) error {
+		inputs = make([]models.StockMovementInput, 0, 2*len(transfer.Items))
+		for _, item := range transfer.Items {
+			for _, leg := range []struct {
+				storeID  uint
+				quantity int64
+			}{
+				{transfer.SourceStoreID, -item.Quantity},
+				{transfer.DestinationStoreID, item.Quantity},
+			} {
+				storeID := leg.storeID
+				inputs = append(inputs, models.StockMovementInput{
+					ProductID:   item.ProductID,
+					VariantID:   item.VariantID,
+					StoreID:     &storeID,
+					Type:        models.StockMovementTransfer,
+					Quantity:    leg.quantity,
+					Reference:   transfer.Number,
+					Serials:     item.Serials,
+					Batch:       item.Batch,
+					CreatedByID: actorID(actor),
+				})
+			}
+		}
+		if _, err := s.inventory.RecordMovements(tx, inputs); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		transfer.ReceivedAt = &now
+		transfer.ReceivedByID = actorID(actor)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.inventory.stockChanged(inputs)
+	return received, nil
+}
+
+// CancelTransfer cancels a transfer that hasn't been received; no stock has moved for it
+func (s *TransferService) CancelTransfer(id uint, actor models.ActivityActor) (*models.StockTransfers, error) {
+	return s.transition(id, models.TransferStatusCancelled, func(tx *gorm.DB, transfer *models.StockTransfers) error {
+		now := time.Now()
+		transfer.CancelledAt = &now
+		transfer.CancelledByID = actorID(actor)
+		return nil
+	})
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// UnitService manages units of measure and the packagings products are bought in
+type UnitService struct {
+	db *gorm.DB
+}
+
+func NewUnitService(db *gorm.DB) *UnitService {
+	return &UnitService{
+		db: db,
+	}
+}
+
+// findUnit returns the unit of measure with the code
+func findUnit(db *gorm.DB, code string) (*models.UnitsOfMeasure, error) {
+	var unit models.UnitsOfMeasure
+	if err := db.Where("code = ?", code).First(&unit).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("unit not found")
+		}
+		return nil, err
+	}
+	return &unit, nil
+}
+
+// GetAllUnits retrieves units of measure with pagination
+func (s *UnitService) GetAllUnits(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := pagination.PaginationConfig{
+		Model:        &models.UnitsOfMeasure{},
+		SearchFields: []string{"code", "name"},
+		FilterFields: map[string]string{
+			"dimension": "dimension",
+		},
+		SortFields: []string{
+			"code",
+			"name",
+			"created_at",
+		},
+		DefaultSort:  "code",
+		DefaultOrder: "ASC",
+	}
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetUnitById retrieves a unit of measure
+func (s *UnitService) GetUnitById(id uint) (*models.UnitsOfMeasure, error) {
+	var unit models.UnitsOfMeasure
+	if err := s.db.Where("id = ?", id).First(&unit).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("unit not found")
+		}
+		return nil, err
+	}
+	return &unit, nil
+}
+
+// CreateUnit creates a unit of measure. Codes are unique, ignoring case.
+func (s *UnitService) CreateUnit(req *models.CreateUnitRequest) (*models.UnitsOfMeasure, error) {
+	code := strings.TrimSpace(req.Code)
+	var count int64
+	if err := s.db.Model(&models.UnitsOfMeasure{}).Where("LOWER(code) = LOWER(?)", code).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("unit already exists")
+	}
+
+	unit := models.UnitsOfMeasure{
+		Code:      code,
+		Name:      req.Name,
+		Dimension: req.Dimension,
+		Factor:    req.Factor,
+	}
+	if err := s.db.Create(&unit).Error; err != nil {
+		return nil, err
+	}
+	return &unit, nil
+}
+
+// UpdateUnit renames a unit of measure
+func (s *UnitService) UpdateUnit(id uint, req *models.UpdateUnitRequest) (*models.UnitsOfMeasure, error) {
+	unit, err := s.GetUnitById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	unit.Name = req.Name
+	if err := s.db.Save(unit).Error; err != nil {
+		return nil, err
+	}
+	return unit, nil
+}
+
+// DeleteUnit deletes a unit of measure nothing is counted in: no product is stocked or packaged
+// in it and the stock ledger doesn't refer to it
+func (s *UnitService) DeleteUnit(id uint) (*models.UnitsOfMeasure, error) {
+	unit, err := s.GetUnitById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, model := range []interface{}{&models.Products{}, &models.ProductUnits{}, &models.StockMovements{}} {
+		var count int64
+		if err := s.db.Unscoped().Model(model).Where("unit = ?", unit.Code).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, errors.New("unit in use")
+		}
+	}
+
+	if err := s.db.Delete(unit).Error; err != nil {
+		return nil, err
+	}
+	return unit, nil
+}
+
+// GetProductUnits retrieves the packagings of a product
+func (s *UnitService) GetProductUnits(productID uint) ([]models.ProductUnits, error) {
+	var product models.Products
+	if err := s.db.Where("id = ?", productID).First(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+
+	units := []models.ProductUnits{}
+	if err := s.db.Where("product_id = ?", product.ID).Order("factor").Find(&units).Error; err != nil {
+		return nil, err
+	}
+	return units, nil
+}
+
+// SetProductUnits replaces the packagings of a product. The unit the product is stocked in
+// can't be one of them, since it is worth one stock unit by definition.
+func (s *UnitService) SetProductUnits(productID uint, req *models.SetProductUnitsRequest) ([]models.ProductUnits, error) {
+	var product models.Products
+	if err := s.db.Where("id = ?", productID).First(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+
+	units := make([]models.ProductUnits, len(req.Units))
+	codes := make([]string, 0, len(req.Units))
+	for i, input := range req.Units {
+		if input.Unit == product.Unit {
+			return nil, errors.New("unit is the stock unit")
+		}
+		if slices.Contains(codes, input.Unit) {
+			return nil, errors.New("duplicate unit")
+		}
+		codes = append(codes, input.Unit)
+		units[i] = models.ProductUnits{
+			ProductID: product.ID,
+			Unit:      input.Unit,
+			Factor:    input.Factor,
+		}
+	}
+	if len(codes) > 0 {
+		var count int64
+		if err := s.db.Model(&models.UnitsOfMeasure{}).Where("code IN ?", codes).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count != int64(len(codes)) {
+			return nil, errors.New("unit not found")
+		}
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductUnits{}).Error; err != nil {
+			return err
+		}
+		if len(units) == 0 {
+			return nil
+		}
+		return tx.Create(&units).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProductUnits(product.ID)
+}
+
+// toStockUnits converts the quantities of stock movements into the units their products are
+// stocked in. Quantities convert through the packagings of the product, e.g. cases of 12, or
+// between units of the same dimension, e.g. kilograms into grams, as long as they come out in
+// whole stock units. It returns the converted movements, with Unit set to the unit the
+// quantity was given in, and the quantities as given.
+func toStockUnits(tx *gorm.DB, inputs []models.StockMovementInput) ([]models.StockMovementInput, []int64, error) {
+	productIDs := make([]uint, 0, len(inputs))
+	for _, input := range inputs {
+		productIDs = append(productIDs, input.ProductID)
+	}
+	var products []models.Products
+	if err := tx.Select("id", "unit").Where("id IN ?", uniqueIDs(productIDs)).Find(&products).Error; err != nil {
+		return nil, nil, err
+	}
+	stockUnits := make(map[uint]string, len(products))
+	for _, product := range products {
+		stockUnits[product.ID] = product.Unit
+	}
+
+	var codes []string
+	var packaged []uint
+	for _, input := range inputs {
+		if input.Unit != "" && input.Unit != stockUnits[input.ProductID] {
+			codes = append(codes, input.Unit, stockUnits[input.ProductID])
+			packaged = append(packaged, input.ProductID)
+		}
+	}
+
+	converted := slices.Clone(inputs)
+	given := make([]int64, len(inputs))
+	for i, input := range inputs {
+		given[i] = input.Quantity
+		if input.Unit == "" {
+			converted[i].Unit = stockUnits[input.ProductID]
+		}
+	}
+	if len(codes) == 0 {
+		return converted, given, nil
+	}
+
+	var units []models.UnitsOfMeasure
+	if err := tx.Where("code IN ?", codes).Find(&units).Error; err != nil {
+		return nil, nil, err
+	}
+	unitsByCode := make(map[string]models.UnitsOfMeasure, len(units))
+	for _, unit := range units {
+		unitsByCode[unit.Code] = unit
+	}
+	var packagings []models.ProductUnits
+	if err := tx.Where("product_id IN ?", uniqueIDs(packaged)).Find(&packagings).Error; err != nil {
+		return nil, nil, err
+	}
+	factors := make(map[uint]map[string]int64, len(packagings))
+	for _, packaging := range packagings {
+		if factors[packaging.ProductID] == nil {
+			factors[packaging.ProductID] = map[string]int64{}
+		}
+		factors[packaging.ProductID][packaging.Unit] = packaging.Factor
+	}
+
+	for i, input := range inputs {
+		if input.Unit == "" || input.Unit == stockUnits[input.ProductID] {
+			continue
+		}
+		if factor, ok := factors[input.ProductID][input.Unit]; ok {
+			converted[i].Quantity = input.Quantity * factor
+			continue
+		}
+		from, ok := unitsByCode[input.Unit]
+		if !ok {
+			return nil, nil, errors.New("unit not found")
+		}
+		to := unitsByCode[stockUnits[input.ProductID]]
+		if from.Dimension != to.Dimension || from.Factor == 0 || to.Factor == 0 {
+			return nil, nil, errors.New("unit not convertible")
+		}
+		if input.Quantity*from.Factor%to.Factor != 0 {
+			return nil, nil, errors.New("quantity not in whole units")
+		}
+		converted[i].Quantity = input.Quantity * from.Factor / to.Factor
+	}
+	return converted, given, nil
+}
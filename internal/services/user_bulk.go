@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// BulkUpdateUsers applies one action to every listed user inside a single transaction.
+// Users the action does not apply to are reported as failed and skipped; a database error
+// rolls back the whole batch. Every changed user gets an audit entry and a cache invalidation.
+func (s *UserService) BulkUpdateUsers(req *models.BulkUserRequest, actor models.ActivityActor) (*models.BulkUserResponse, error) {
+	return s.bulkUpdate(req, actor, nil)
+}
+
+// AssignRole gives every listed user the role, reporting per-user results like a bulk action
+func (s *UserService) AssignRole(role string, userIDs []uint, actor models.ActivityActor) (*models.BulkUserResponse, error) {
+	return s.bulkUpdate(&models.BulkUserRequest{Action: models.BulkActionSetRole, IDs: userIDs, Role: role}, actor, nil)
+}
+
+// RemoveRole moves every listed user holding the role back to the default user role.
+// Users without the role are reported as failed and skipped.
+func (s *UserService) RemoveRole(role string, userIDs []uint, actor models.ActivityActor) (*models.BulkUserResponse, error) {
+	if role == models.RoleUser {
+		return nil, errors.New("cannot remove the default role")
+	}
+
+	req := &models.BulkUserRequest{Action: models.BulkActionSetRole, IDs: userIDs, Role: models.RoleUser}
+	return s.bulkUpdate(req, actor, func(user *models.Users) error {
+		if user.Role != role {
+			return &bulkSkipError{"user does not have this role"}
+		}
+		return nil
+	})
+}
+
+// bulkUpdate applies a bulk action, first running the optional check on each user.
+// A *bulkSkipError from the check skips the user.
+func (s *UserService) bulkUpdate(req *models.BulkUserRequest, actor models.ActivityActor, check func(user *models.Users) error) (*models.BulkUserResponse, error) {
+	// Keep the request order but handle each ID once
+	ids := make([]uint, 0, len(req.IDs))
+	seen := make(map[uint]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	response := &models.BulkUserResponse{
+		Action:  req.Action,
+		Results: make([]models.BulkUserResult, 0, len(ids)),
+	}
+	var changed []uint
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var users []models.Users
+		if err := tx.Unscoped().Where("id IN ?", ids).Find(&users).Error; err != nil {
+			return err
+		}
+		byID := make(map[uint]*models.Users, len(users))
+		for i := range users {
+			byID[users[i].ID] = &users[i]
+		}
+
+		for _, id := range ids {
+			user, ok := byID[id]
+			if !ok {
+				response.Results = append(response.Results, models.BulkUserResult{ID: id, Error: "user not found"})
+				continue
+			}
+
+			var err error
+			if check != nil {
+				err = check(user)
+			}
+			if err == nil {
+				err = s.bulkApply(tx, user, req, actor)
+			}
+			if err != nil {
+				var skip *bulkSkipError
+				if !errors.As(err, &skip) {
+					return err
+				}
+				response.Results = append(response.Results, models.BulkUserResult{ID: id, Error: skip.reason})
+				continue
+			}
+
+			response.Results = append(response.Results, models.BulkUserResult{ID: id, Success: true})
+			changed = append(changed, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(changed) > 0 {
+		keys := make([]string, 0, 2*len(changed))
+		for _, id := range changed {
+			keys = append(keys, cache.UserKey(id), cache.ClaimsVersionKey(id))
+		}
+		if err := s.cache.Delete(context.Background(), keys...); err != nil {
+			slog.Warn("Failed to invalidate user cache", "users", len(changed), "error", err)
+		}
+
+		action := models.ChangeActionUpdated
+		if req.Action == models.BulkActionSoftDelete {
+			action = models.ChangeActionDeleted
+		}
+		s.changes.Publish(models.ChangeEntityUser, action, changed...)
+	}
+
+	response.Succeeded = len(changed)
+	response.Failed = len(response.Results) - len(changed)
+	return response, nil
+}
+
+// bulkSkipError marks a user the bulk action does not apply to
+type bulkSkipError struct {
+	reason string
+}
+
+func (e *bulkSkipError) Error() string {
+	return e.reason
+}
+
+// bulkApply performs the bulk action on one user and records its audit entry.
+// A *bulkSkipError means the user was left unchanged.
+func (s *UserService) bulkApply(tx *gorm.DB, user *models.Users, req *models.BulkUserRequest, actor models.ActivityActor) error {
+	now := time.Now()
+
+	switch req.Action {
+	case models.BulkActionSoftDelete:
+		if user.IsDeleted || user.DeletedAt.Valid {
+			return &bulkSkipError{"user is already deleted"}
+		}
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"is_deleted":      true,
+			"soft_deleted_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		return s.activityService.RecordTx(tx, user.ID, models.ActivityUserSoftDeleted, actor, "Account deactivated", nil)
+
+	case models.BulkActionRestore:
+		if !user.IsDeleted && !user.DeletedAt.Valid {
+			return &bulkSkipError{"user is not deleted"}
+		}
+		if user.PurgedAt != nil {
+			return &bulkSkipError{"user has been purged"}
+		}
+		var existing models.Users
+		if err := tx.Where("username = ? AND id <> ? AND is_deleted = ?", user.Username, user.ID, false).First(&existing).Error; err == nil {
+			return &bulkSkipError{"username already exists"}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err := tx.Where("email = ? AND id <> ? AND is_deleted = ?", user.Email, user.ID, false).First(&existing).Error; err == nil {
+			return &bulkSkipError{"email already exists"}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err := tx.Unscoped().Model(user).Updates(map[string]interface{}{
+			"is_deleted":      false,
+			"soft_deleted_at": nil,
+			"deleted_at":      nil,
+		}).Error; err != nil {
+			return err
+		}
+		return s.activityService.RecordTx(tx, user.ID, models.ActivityUserRestored, actor, "Account restored", nil)
+
+	case models.BulkActionSetRole:
+		if user.IsDeleted || user.DeletedAt.Valid {
+			return &bulkSkipError{"user is deleted"}
+		}
+		if user.ID == actor.UserID {
+			return &bulkSkipError{"cannot change your own role"}
+		}
+		if user.Role == req.Role {
+			return &bulkSkipError{"user already has this role"}
+		}
+		previous := user.Role
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"role":           req.Role,
+			"claims_version": gorm.Expr("claims_version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		return s.activityService.RecordTx(tx, user.ID, models.ActivityRoleChanged, actor, "Role changed", models.JSONMap{
+			"from": previous,
+			"to":   req.Role,
+		})
+
+	case models.BulkActionSuspend:
+		if user.IsDeleted || user.DeletedAt.Valid {
+			return &bulkSkipError{"user is deleted"}
+		}
+		if user.ID == actor.UserID {
+			return &bulkSkipError{"cannot suspend yourself"}
+		}
+		allowed := false
+		for _, next := range models.UserStatusTransitions[user.Status] {
+			if next == models.UserStatusSuspended {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return &bulkSkipError{"invalid status transition"}
+		}
+		previous := user.Status
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"status":            models.UserStatusSuspended,
+			"status_reason":     req.Reason,
+			"status_changed_at": now,
+			"claims_version":    gorm.Expr("claims_version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		return s.activityService.RecordTx(tx, user.ID, models.ActivityStatusChanged, actor, fmt.Sprintf("Status changed from %s to %s", previous, models.UserStatusSuspended), models.JSONMap{
+			"from":   previous,
+			"to":     models.UserStatusSuspended,
+			"reason": req.Reason,
+		})
+	}
+
+	return errors.New("invalid bulk action")
+}
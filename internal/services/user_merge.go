@@ -0,0 +1,417 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// MergeUsers folds a duplicate account into the primary one inside a single transaction.
+// Records owned by the duplicate are reassigned, metadata is combined with the primary's
+// values winning, and the duplicate is soft-deleted and suspended so it can no longer sign in.
+// Both accounts get an audit entry describing the merge.
+func (s *UserService) MergeUsers(req *models.MergeUsersRequest, actor models.ActivityActor) (*models.MergeUsersResponse, error) {
+	if req.PrimaryID == req.DuplicateID {
+		return nil, errors.New("cannot merge a user into itself")
+	}
+
+	var response *models.MergeUsersResponse
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var primary, duplicate models.Users
+		if err := tx.Where("id = ?", req.PrimaryID).First(&primary).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("primary user not found")
+			}
+			return err
+		}
+		if err := tx.Unscoped().Where("id = ?", req.DuplicateID).First(&duplicate).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("duplicate user not found")
+			}
+			return err
+		}
+
+		if primary.IsDeleted {
+			return errors.New("primary user is deleted")
+		}
+		if duplicate.MergedIntoID != nil {
+			return errors.New("duplicate user is already merged")
+		}
+		if duplicate.PurgedAt != nil {
+			return errors.New("user has been purged")
+		}
+		if duplicate.ID == actor.UserID {
+			return errors.New("cannot merge away your own account")
+		}
+
+		reassigned, err := reassignUserRecords(tx, duplicate.ID, primary.ID)
+		if err != nil {
+			return err
+		}
+
+		metadata, conflicts := mergeUserMetadata(primary.Metadata, duplicate.Metadata)
+		if err := tx.Model(&primary).Update("metadata", metadata).Error; err != nil {
+			return err
+		}
+		primary.Metadata = metadata
+
+		now := time.Now()
+		reason := fmt.Sprintf("Merged into user %d", primary.ID)
+		if err := tx.Unscoped().Model(&duplicate).Updates(map[string]interface{}{
+			"is_deleted":        true,
+			"soft_deleted_at":   now,
+			"status":            models.UserStatusSuspended,
+			"status_reason":     reason,
+			"status_changed_at": now,
+			"claims_version":    gorm.Expr("claims_version + 1"),
+			"merged_into_id":    primary.ID,
+		}).Error; err != nil {
+			return err
+		}
+
+		details := models.JSONMap{
+			"primary_id":         primary.ID,
+			"duplicate_id":       duplicate.ID,
+			"duplicate_username": duplicate.Username,
+			"reassigned":         reassigned,
+			"metadata_conflicts": conflicts,
+		}
+		if err := s.activityService.RecordTx(tx, primary.ID, models.ActivityUserMerged, actor, fmt.Sprintf("Merged duplicate account %s", duplicate.Username), details); err != nil {
+			return err
+		}
+		if err := s.activityService.RecordTx(tx, duplicate.ID, models.ActivityUserMerged, actor, reason, details); err != nil {
+			return err
+		}
+
+		response = &models.MergeUsersResponse{
+			Primary:           primary,
+			DuplicateID:       duplicate.ID,
+			Reassigned:        reassigned,
+			MetadataConflicts: conflicts,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.userChanged(req.PrimaryID, models.ChangeActionUpdated)
+	s.userChanged(req.DuplicateID, models.ChangeActionDeleted)
+
+	return response, nil
+}
+
+// reassignUserRecords moves everything owned by one user to another and counts what moved
+func reassignUserRecords(tx *gorm.DB, fromID, toID uint) (models.MergedRecords, error) {
+	var moved models.MergedRecords
+
+	// The duplicate's own history and the actions it performed both move to the primary
+	result := tx.Model(&models.UserActivities{}).Where("user_id = ?", fromID).Update("user_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.Activities = result.RowsAffected
+	if err := tx.Model(&models.UserActivities{}).Where("actor_id = ?", fromID).Update("actor_id", toID).Error; err != nil {
+		return moved, err
+	}
+
+	result = tx.Model(&models.LoginEvents{}).Where("user_id = ?", fromID).Update("user_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.LoginEvents = result.RowsAffected
+
+	result = tx.Model(&models.PrintJobs{}).Where("created_by_id = ?", fromID).Update("created_by_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.PrintJobs = result.RowsAffected
+
+	result = tx.Model(&models.ReportDefinitions{}).Where("created_by_id = ?", fromID).Update("created_by_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.ReportDefinitions = result.RowsAffected
+
+	// Deleted schedules keep their run history, so they move too
+	result = tx.Unscoped().Model(&models.ReportSchedules{}).Where("created_by_id = ?", fromID).Update("created_by_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.ReportSchedules = result.RowsAffected
+	if err := tx.Model(&models.ReportScheduleRuns{}).Where("triggered_by_id = ?", fromID).Update("triggered_by_id", toID).Error; err != nil {
+		return moved, err
+	}
+
+	result = tx.Model(&models.Tenants{}).Where("owner_id = ?", fromID).Update("owner_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.Tenants = result.RowsAffected
+
+	result = tx.Model(&models.StockMovements{}).Where("created_by_id = ?", fromID).Update("created_by_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.StockMovements = result.RowsAffected
+
+	result = tx.Model(&models.StockTransfers{}).Where("requested_by_id = ?", fromID).Update("requested_by_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.StockTransfers = result.RowsAffected
+	for _, column := range []string{"approved_by_id", "sent_by_id", "received_by_id", "cancelled_by_id"} {
+		if err := tx.Model(&models.StockTransfers{}).Where(column+" = ?", fromID).Update(column, toID).Error; err != nil {
+			return moved, err
+		}
+	}
+
+	result = tx.Model(&models.Stocktakes{}).Where("created_by_id = ?", fromID).Update("created_by_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.Stocktakes = result.RowsAffected
+	for _, column := range []string{"approved_by_id", "cancelled_by_id"} {
+		if err := tx.Model(&models.Stocktakes{}).Where(column+" = ?", fromID).Update(column, toID).Error; err != nil {
+			return moved, err
+		}
+	}
+	if err := tx.Model(&models.StocktakeItems{}).Where("counted_by_id = ?", fromID).Update("counted_by_id", toID).Error; err != nil {
+		return moved, err
+	}
+
+	result = tx.Model(&models.Orders{}).Where("created_by_id = ?", fromID).Update("created_by_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.Orders = result.RowsAffected
+
+	result = tx.Model(&models.Imports{}).Where("created_by_id = ?", fromID).Update("created_by_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.Imports = result.RowsAffected
+
+	result = tx.Model(&models.Payments{}).Where("created_by_id = ?", fromID).Update("created_by_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.Payments = result.RowsAffected
+
+	// A cashier has one open drawer; the duplicate's stays with it when the primary has one open
+	result = tx.Model(&models.CashDrawerSessions{}).
+		Where("opened_by_id = ? AND (status <> ? OR NOT EXISTS (SELECT 1 FROM cash_drawer_sessions primary_drawer WHERE primary_drawer.opened_by_id = ? AND primary_drawer.status = ?))",
+			fromID, models.CashDrawerStatusOpen, toID, models.CashDrawerStatusOpen).
+		Update("opened_by_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.CashDrawerSessions = result.RowsAffected
+
+	if err := tx.Model(&models.CashDrawerSessions{}).Where("closed_by_id = ?", fromID).Update("closed_by_id", toID).Error; err != nil {
+		return moved, err
+	}
+	if err := tx.Model(&models.CashDrawerMovements{}).Where("created_by_id = ?", fromID).Update("created_by_id", toID).Error; err != nil {
+		return moved, err
+	}
+
+	// Likewise a cashier has one open shift
+	result = tx.Model(&models.Shifts{}).
+		Where("user_id = ? AND (status <> ? OR NOT EXISTS (SELECT 1 FROM shifts primary_shift WHERE primary_shift.user_id = ? AND primary_shift.status = ?))",
+			fromID, models.ShiftStatusOpen, toID, models.ShiftStatusOpen).
+		Update("user_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.Shifts = result.RowsAffected
+
+	if err := tx.Model(&models.Shifts{}).Where("closed_by_id = ?", fromID).Update("closed_by_id", toID).Error; err != nil {
+		return moved, err
+	}
+
+	result = tx.Model(&models.OrderReturns{}).Where("created_by_id = ?", fromID).Update("created_by_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.OrderReturns = result.RowsAffected
+
+	result = tx.Model(&models.ReceiptEmails{}).Where("requested_by_id = ?", fromID).Update("requested_by_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.ReceiptEmails = result.RowsAffected
+
+	result = tx.Model(&models.Coupons{}).Where("created_by_id = ?", fromID).Update("created_by_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.Coupons = result.RowsAffected
+
+	result = tx.Model(&models.Customers{}).Where("created_by_id = ?", fromID).Update("created_by_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.Customers = result.RowsAffected
+
+	result = tx.Model(&models.LoyaltyTransactions{}).Where("created_by_id = ?", fromID).Update("created_by_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.LoyaltyTransactions = result.RowsAffected
+
+	result = tx.Model(&models.GiftCards{}).Where("issued_by_id = ?", fromID).Update("issued_by_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.GiftCards = result.RowsAffected
+	if err := tx.Model(&models.GiftCards{}).Where("voided_by_id = ?", fromID).Update("voided_by_id", toID).Error; err != nil {
+		return moved, err
+	}
+	if err := tx.Model(&models.GiftCardTransactions{}).Where("created_by_id = ?", fromID).Update("created_by_id", toID).Error; err != nil {
+		return moved, err
+	}
+
+	result = tx.Model(&models.Invoices{}).Where("created_by_id = ?", fromID).Update("created_by_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.Invoices = result.RowsAffected
+	if err := tx.Model(&models.Invoices{}).Where("voided_by_id = ?", fromID).Update("voided_by_id", toID).Error; err != nil {
+		return moved, err
+	}
+	if err := tx.Model(&models.InvoicePayments{}).Where("created_by_id = ?", fromID).Update("created_by_id", toID).Error; err != nil {
+		return moved, err
+	}
+
+	result = tx.Model(&models.Quotes{}).Where("created_by_id = ?", fromID).Update("created_by_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.Quotes = result.RowsAffected
+	if err := tx.Model(&models.QuoteVersions{}).Where("created_by_id = ?", fromID).Update("created_by_id", toID).Error; err != nil {
+		return moved, err
+	}
+
+	result = tx.Model(&models.Notifications{}).Where("user_id = ?", fromID).Update("user_id", toID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.Notifications = result.RowsAffected
+
+	memberships, err := mergeTeamMemberships(tx, fromID, toID)
+	if err != nil {
+		return moved, err
+	}
+	moved.TeamMemberships = memberships
+
+	// Tags both users have are kept once
+	if err := tx.Exec("INSERT INTO user_tags (user_id, tag_id, created_at) SELECT ?, tag_id, created_at FROM user_tags WHERE user_id = ? ON CONFLICT DO NOTHING", toID, fromID).Error; err != nil {
+		return moved, err
+	}
+	if err := tx.Where("user_id = ?", fromID).Delete(&models.UserTags{}).Error; err != nil {
+		return moved, err
+	}
+
+	// Store assignments are combined, unless the primary may already work in every store
+	if err := tx.Exec("INSERT INTO user_stores (user_id, store_id, created_at) SELECT ?, store_id, created_at FROM user_stores WHERE user_id = ? AND EXISTS (SELECT 1 FROM user_stores WHERE user_id = ?) ON CONFLICT DO NOTHING", toID, fromID, toID).Error; err != nil {
+		return moved, err
+	}
+	if err := tx.Where("user_id = ?", fromID).Delete(&models.UserStores{}).Error; err != nil {
+		return moved, err
+	}
+
+	settings, err := mergeUserSettings(tx, fromID, toID)
+	if err != nil {
+		return moved, err
+	}
+	moved.Settings = settings
+
+	// The duplicate's own cart is dropped; terminal carts it last worked on move to the primary
+	if err := tx.Where("owner = ?", models.CartOwner{UserID: fromID}.Key()).Delete(&models.Carts{}).Error; err != nil {
+		return moved, err
+	}
+	if err := tx.Model(&models.Carts{}).Where("user_id = ?", fromID).Update("user_id", toID).Error; err != nil {
+		return moved, err
+	}
+
+	// The primary keeps its own notification preferences
+	if err := tx.Where("user_id = ?", fromID).Delete(&models.NotificationPreferences{}).Error; err != nil {
+		return moved, err
+	}
+
+	return moved, nil
+}
+
+// mergeTeamMemberships moves team memberships to the primary user. Where both users are in
+// the same team, the primary keeps one membership with the higher of the two roles.
+func mergeTeamMemberships(tx *gorm.DB, fromID, toID uint) (int64, error) {
+	var memberships []models.TeamMembers
+	if err := tx.Where("user_id = ?", fromID).Find(&memberships).Error; err != nil {
+		return 0, err
+	}
+
+	var moved int64
+	for _, membership := range memberships {
+		var existing models.TeamMembers
+		err := tx.Where("team_id = ? AND user_id = ?", membership.TeamID, toID).First(&existing).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := tx.Model(&membership).Update("user_id", toID).Error; err != nil {
+				return moved, err
+			}
+			moved++
+		case err != nil:
+			return moved, err
+		default:
+			if membership.Role == models.TeamRoleLead && existing.Role != models.TeamRoleLead {
+				if err := tx.Model(&existing).Update("role", models.TeamRoleLead).Error; err != nil {
+					return moved, err
+				}
+			}
+			if err := tx.Delete(&membership).Error; err != nil {
+				return moved, err
+			}
+		}
+	}
+	return moved, nil
+}
+
+// mergeUserSettings hands the duplicate's settings to the primary user when it has none of its own.
+// Otherwise the primary's settings are kept and the duplicate's are dropped.
+func mergeUserSettings(tx *gorm.DB, fromID, toID uint) (bool, error) {
+	var existing models.UserSettings
+	err := tx.Where("user_id = ?", toID).First(&existing).Error
+	if err == nil {
+		return false, tx.Where("user_id = ?", fromID).Delete(&models.UserSettings{}).Error
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	result := tx.Model(&models.UserSettings{}).Where("user_id = ?", fromID).Update("user_id", toID)
+	return result.RowsAffected > 0, result.Error
+}
+
+// mergeUserMetadata combines two metadata maps, keeping the primary value for keys set on both.
+// It returns the combined map and the sorted keys whose duplicate value was discarded.
+func mergeUserMetadata(primary, duplicate models.JSONMap) (models.JSONMap, []string) {
+	merged := models.JSONMap{}
+	for key, value := range duplicate {
+		merged[key] = value
+	}
+
+	conflicts := []string{}
+	for key, value := range primary {
+		if other, ok := duplicate[key]; ok && fmt.Sprint(other) != fmt.Sprint(value) {
+			conflicts = append(conflicts, key)
+		}
+		merged[key] = value
+	}
+	sort.Strings(conflicts)
+
+	return merged, conflicts
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+)
+
+// maxMetadataValueLength caps string values of custom user attributes
+const maxMetadataValueLength = 255
+
+var (
+	metadataKeyPattern    = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+	employeeNumberPattern = regexp.MustCompile(`^[A-Za-z0-9-]{1,32}$`)
+)
+
+// userMetadataRules holds the validators for well-known metadata keys.
+// Other keys accept any string, number, or boolean so deployments can add their own attributes.
+var userMetadataRules = map[string]func(value interface{}) error{
+	"department": func(value interface{}) error {
+		department, ok := value.(string)
+		if !ok || department == "" || len(department) > 100 {
+			return errors.New("must be a non-empty string of at most 100 characters")
+		}
+		return nil
+	},
+	"employee_number": func(value interface{}) error {
+		number, ok := value.(string)
+		if !ok || !employeeNumberPattern.MatchString(number) {
+			return errors.New("must be up to 32 letters, digits, or dashes")
+		}
+		return nil
+	},
+	"hire_date": func(value interface{}) error {
+		date, ok := value.(string)
+		if !ok {
+			return errors.New("must be a date in YYYY-MM-DD format")
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return errors.New("must be a date in YYYY-MM-DD format")
+		}
+		return nil
+	},
+}
+
+// ValidateUserMetadata checks metadata keys and values and returns the problems keyed by metadata key
+func ValidateUserMetadata(metadata models.JSONMap) map[string]string {
+	problems := map[string]string{}
+	for key, value := range metadata {
+		if !metadataKeyPattern.MatchString(key) {
+			problems[key] = "key must be lowercase letters, digits, or underscores and start with a letter"
+			continue
+		}
+
+		if validate, ok := userMetadataRules[key]; ok {
+			if err := validate(value); err != nil {
+				problems[key] = err.Error()
+			}
+			continue
+		}
+
+		switch v := value.(type) {
+		case string:
+			if len(v) > maxMetadataValueLength {
+				problems[key] = fmt.Sprintf("must be at most %d characters", maxMetadataValueLength)
+			}
+		case float64, bool:
+		default:
+			problems[key] = "must be a string, number, or boolean"
+		}
+	}
+	return problems
+}
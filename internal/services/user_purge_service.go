@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/anonymize"
+	"github.com/Aebroyx/the-blade-api/internal/cache"
+	"github.com/Aebroyx/the-blade-api/internal/config"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// UserPurgeService permanently removes users that have been deleted for longer than the retention window
+type UserPurgeService struct {
+	db      *gorm.DB
+	config  *config.Config
+	cache   *cache.Cache
+	changes *ChangeFeedService
+	running sync.Mutex
+}
+
+func NewUserPurgeService(db *gorm.DB, config *config.Config, cache *cache.Cache, changes *ChangeFeedService) *UserPurgeService {
+	return &UserPurgeService{
+		db:      db,
+		config:  config,
+		cache:   cache,
+		changes: changes,
+	}
+}
+
+// PurgeUsers purges every user soft-deleted (or deleted) before the retention cutoff.
+// Depending on the configured mode, users are either deleted outright or anonymized in place.
+// In dry-run mode nothing is changed and the report lists the users that would be purged.
+func (s *UserPurgeService) PurgeUsers(dryRun bool) (*models.UserPurgeReport, error) {
+	if !s.running.TryLock() {
+		return nil, errors.New("purge already running")
+	}
+	defer s.running.Unlock()
+
+	cutoff := time.Now().AddDate(0, 0, -s.config.UserPurgeRetentionDays)
+	report := &models.UserPurgeReport{
+		Mode:          s.config.UserPurgeMode,
+		DryRun:        dryRun,
+		RetentionDays: s.config.UserPurgeRetentionDays,
+		Cutoff:        cutoff,
+		Users:         []models.PurgedUser{},
+	}
+
+	// Users soft-deleted before soft_deleted_at existed fall back to their last update
+	var users []models.Users
+	if err := s.db.Unscoped().
+		Where("purged_at IS NULL").
+		Where("(is_deleted = ? AND COALESCE(soft_deleted_at, updated_at) < ?) OR (deleted_at IS NOT NULL AND deleted_at < ?)", true, cutoff, cutoff).
+		Order("id ASC").
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	faker, err := newPurgeFaker()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		if !dryRun {
+			if err := s.purgeUser(user, faker); err != nil {
+				return report, fmt.Errorf("failed to purge user ID %d: %w", user.ID, err)
+			}
+		}
+
+		report.Users = append(report.Users, models.PurgedUser{
+			ID:        user.ID,
+			Username:  user.Username,
+			DeletedAt: deletionTime(user),
+		})
+		report.Count++
+	}
+
+	if !dryRun {
+		slog.Info("Purged deleted users", "users", report.Count, "deleted_before", cutoff.Format(time.RFC3339), "mode", report.Mode)
+	}
+
+	return report, nil
+}
+
+// RunScheduledPurge is the entry point of the background purge job
+func (s *UserPurgeService) RunScheduledPurge(ctx context.Context) error {
+	_, err := s.PurgeUsers(false)
+	return err
+}
+
+// purgeUser removes or anonymizes a single user and everything that refers to them
+func (s *UserPurgeService) purgeUser(user models.Users, faker *anonymize.Faker) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.TeamMembers{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserSettings{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserTags{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserStores{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserQuotas{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.NotificationPreferences{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.Notifications{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.Carts{}).Error; err != nil {
+			return err
+		}
+
+		if s.config.UserPurgeMode == models.UserPurgeModeAnonymize {
+			if err := tx.Model(&models.LoginEvents{}).Where("user_id = ?", user.ID).Updates(map[string]interface{}{
+				"username":   "",
+				"ip_address": "",
+				"user_agent": "",
+			}).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Model(&models.UserActivities{}).Where("user_id = ?", user.ID).Updates(map[string]interface{}{
+				"ip_address": "",
+				"user_agent": "",
+				"metadata":   models.JSONMap{},
+			}).Error; err != nil {
+				return err
+			}
+
+			// An empty hash never matches, so the account can not be signed into again
+			return tx.Unscoped().Model(&user).Updates(map[string]interface{}{
+				"name":                      faker.Value(anonymize.KindName, user.Name),
+				"username":                  faker.Value(anonymize.KindUsername, user.Username),
+				"email":                     faker.Value(anonymize.KindEmail, user.Email),
+				"password":                  "",
+				"pending_email":             nil,
+				"external_id":               nil,
+				"metadata":                  models.JSONMap{},
+				"last_login_ip":             "",
+				"email_change_token_hash":   nil,
+				"password_reset_token_hash": nil,
+				"purged_at":                 time.Now(),
+			}).Error
+		}
+
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserActivities{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.LoginEvents{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.UserActivities{}).Where("actor_id = ?", user.ID).Update("actor_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.PrintJobs{}).Where("created_by_id = ?", user.ID).Update("created_by_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ReportDefinitions{}).Where("created_by_id = ?", user.ID).Update("created_by_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.ReportSchedules{}).Where("created_by_id = ?", user.ID).Update("created_by_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ReportScheduleRuns{}).Where("triggered_by_id = ?", user.ID).Update("triggered_by_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.StockMovements{}).Where("created_by_id = ?", user.ID).Update("created_by_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Orders{}).Where("created_by_id = ?", user.ID).Update("created_by_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Imports{}).Where("created_by_id = ?", user.ID).Update("created_by_id", nil).Error; err != nil {
+			return err
+		}
+		for _, column := range []string{"requested_by_id", "approved_by_id", "sent_by_id", "received_by_id", "cancelled_by_id"} {
+			if err := tx.Model(&models.StockTransfers{}).Where(column+" = ?", user.ID).Update(column, nil).Error; err != nil {
+				return err
+			}
+		}
+		for _, column := range []string{"created_by_id", "approved_by_id", "cancelled_by_id"} {
+			if err := tx.Model(&models.Stocktakes{}).Where(column+" = ?", user.ID).Update(column, nil).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.StocktakeItems{}).Where("counted_by_id = ?", user.ID).Update("counted_by_id", nil).Error; err != nil {
+			return err
+		}
+		for _, column := range []string{"issued_by_id", "voided_by_id"} {
+			if err := tx.Model(&models.GiftCards{}).Where(column+" = ?", user.ID).Update(column, nil).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.GiftCardTransactions{}).Where("created_by_id = ?", user.ID).Update("created_by_id", nil).Error; err != nil {
+			return err
+		}
+		for _, column := range []string{"created_by_id", "voided_by_id"} {
+			if err := tx.Model(&models.Invoices{}).Where(column+" = ?", user.ID).Update(column, nil).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.InvoicePayments{}).Where("created_by_id = ?", user.ID).Update("created_by_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Quotes{}).Where("created_by_id = ?", user.ID).Update("created_by_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.QuoteVersions{}).Where("created_by_id = ?", user.ID).Update("created_by_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Payments{}).Where("created_by_id = ?", user.ID).Update("created_by_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.CashDrawerSessions{}).Where("opened_by_id = ?", user.ID).Update("opened_by_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.CashDrawerSessions{}).Where("closed_by_id = ?", user.ID).Update("closed_by_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.CashDrawerMovements{}).Where("created_by_id = ?", user.ID).Update("created_by_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Shifts{}).Where("user_id = ?", user.ID).Update("user_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Shifts{}).Where("closed_by_id = ?", user.ID).Update("closed_by_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.OrderReturns{}).Where("created_by_id = ?", user.ID).Update("created_by_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ReceiptEmails{}).Where("requested_by_id = ?", user.ID).Update("requested_by_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Coupons{}).Where("created_by_id = ?", user.ID).Update("created_by_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Customers{}).Where("created_by_id = ?", user.ID).Update("created_by_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.LoyaltyTransactions{}).Where("created_by_id = ?", user.ID).Update("created_by_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Tenants{}).Where("owner_id = ?", user.ID).Update("owner_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Users{}).Where("merged_into_id = ?", user.ID).Update("merged_into_id", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(&user).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.cache.Delete(context.Background(), cache.UserKey(user.ID), cache.ClaimsVersionKey(user.ID)); err != nil {
+		slog.Warn("Failed to invalidate cache for purged user", "user_id", user.ID, "error", err)
+	}
+	s.changes.Publish(models.ChangeEntityUser, models.ChangeActionDeleted, user.ID)
+
+	return nil
+}
+
+// newPurgeFaker creates a faker keyed by a random salt, so anonymized values can not be linked back
+func newPurgeFaker() (*anonymize.Faker, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return anonymize.NewFaker(hex.EncodeToString(salt)), nil
+}
+
+// deletionTime returns when the user was deleted, preferring the soft delete timestamp
+func deletionTime(user models.Users) time.Time {
+	if user.SoftDeletedAt != nil {
+		return *user.SoftDeletedAt
+	}
+	if user.DeletedAt.Valid {
+		return user.DeletedAt.Time
+	}
+	return user.UpdatedAt
+}
@@ -2,25 +2,47 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"math/big"
+	"reflect"
 	"time"
 
+	"github.com/Aebroyx/the-blade-api/internal/cache"
 	"github.com/Aebroyx/the-blade-api/internal/config"
 	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/mailer"
 	"github.com/Aebroyx/the-blade-api/internal/pagination"
+	"github.com/Aebroyx/the-blade-api/internal/password"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/redis/go-redis/v9"
 
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+const (
+	// emailChangeTokenTTL is how long an email confirmation link stays valid
+	emailChangeTokenTTL = 24 * time.Hour
+	// passwordResetTokenTTL is how long a password reset link stays valid
+	passwordResetTokenTTL = time.Hour
+	// temporaryPasswordLength is the length of passwords generated by an administrator reset
+	temporaryPasswordLength = 12
+	// temporaryPasswordAlphabet leaves out characters that are easily confused
+	temporaryPasswordAlphabet = "abcdefghjkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
 type UserService struct {
-	db          *gorm.DB
-	config      *config.Config
-	redisClient *redis.Client
+	db              *gorm.DB
+	config          *config.Config
+	cache           *cache.Cache
+	passwords       *password.Manager
+	activityService *ActivityService
+	notifications   *NotificationService
+	presence        *PresenceService
+	changes         *ChangeFeedService
 }
 
 // UserQueryParams represents the query parameters for user listing
@@ -42,29 +64,34 @@ type UserListResponse struct {
 	TotalPages int            `json:"totalPages"`
 }
 
-func NewUserService(db *gorm.DB, config *config.Config, redisClient *redis.Client) *UserService {
+func NewUserService(db *gorm.DB, config *config.Config, cache *cache.Cache, passwords *password.Manager, activityService *ActivityService, notifications *NotificationService, presence *PresenceService, changes *ChangeFeedService) *UserService {
 	return &UserService{
-		db:          db,
-		config:      config,
-		redisClient: redisClient,
+		db:              db,
+		config:          config,
+		cache:           cache,
+		passwords:       passwords,
+		activityService: activityService,
+		notifications:   notifications,
+		presence:        presence,
+		changes:         changes,
 	}
 }
 
 // invalidateUserCache removes the user data from Redis cache
 func (s *UserService) invalidateUserCache(userID uint) {
-	if s.redisClient != nil {
-		userKey := fmt.Sprintf("user:%d", userID)
-		err := s.redisClient.Del(context.Background(), userKey).Err()
-		if err != nil {
-			log.Printf("Failed to invalidate user cache for ID %d: %v", userID, err)
-		} else {
-			log.Printf("Successfully invalidated user cache for ID %d", userID)
-		}
+	if err := s.cache.Delete(context.Background(), cache.UserKey(userID), cache.ClaimsVersionKey(userID)); err != nil {
+		slog.Warn("Failed to invalidate user cache", "user_id", userID, "error", err)
 	}
 }
 
+// userChanged invalidates the cached user and announces the change on the change feed
+func (s *UserService) userChanged(userID uint, action string) {
+	s.invalidateUserCache(userID)
+	s.changes.Publish(models.ChangeEntityUser, action, userID)
+}
+
 // Register creates a new user with the provided registration data
-func (s *UserService) Register(req *models.RegisterRequest) (*models.RegisterResponse, error) {
+func (s *UserService) Register(req *models.RegisterRequest, actor models.ActivityActor) (*models.RegisterResponse, error) {
 	// Check if username already exists
 	var existingUser models.Users
 	if err := s.db.Where("username = ?", req.Username).First(&existingUser).Error; err == nil {
@@ -81,7 +108,7 @@ func (s *UserService) Register(req *models.RegisterRequest) (*models.RegisterRes
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.passwords.Hash(req.Password)
 	if err != nil {
 		return nil, err
 	}
@@ -90,15 +117,21 @@ func (s *UserService) Register(req *models.RegisterRequest) (*models.RegisterRes
 	user := models.Users{
 		Username: req.Username,
 		Email:    req.Email,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 		Name:     req.Name,
 		Role:     "user", // Default role
+		Status:   models.UserStatusActive,
 	}
 
 	if err := s.db.Create(&user).Error; err != nil {
 		return nil, err
 	}
 
+	s.changes.Publish(models.ChangeEntityUser, models.ChangeActionCreated, user.ID)
+
+	actor.UserID = user.ID
+	s.activityService.Record(user.ID, models.ActivityRegistered, actor, "Registered an account", nil)
+
 	// Return user data without password
 	return &models.RegisterResponse{
 		ID:       user.ID,
@@ -109,22 +142,76 @@ func (s *UserService) Register(req *models.RegisterRequest) (*models.RegisterRes
 	}, nil
 }
 
+// CheckAvailability reports whether a username, and optionally an email, can still be registered.
+// Deleted accounts keep their username and email, so they are counted as taken.
+func (s *UserService) CheckAvailability(query *models.AvailabilityQuery, includeEmail bool) (*models.AvailabilityResponse, error) {
+	response := &models.AvailabilityResponse{}
+
+	if query.Username != "" {
+		var count int64
+		if err := s.db.Unscoped().Model(&models.Users{}).Where("username = ?", query.Username).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		available := count == 0
+		response.Username = query.Username
+		response.UsernameAvailable = &available
+	}
+
+	if query.Email != "" && includeEmail {
+		var count int64
+		if err := s.db.Unscoped().Model(&models.Users{}).Where("email = ? OR pending_email = ?", query.Email, query.Email).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		available := count == 0
+		response.Email = query.Email
+		response.EmailAvailable = &available
+	}
+
+	return response, nil
+}
+
 // Login authenticates a user and returns tokens
-func (s *UserService) Login(req *models.LoginRequest) (*models.LoginResponse, error) {
+func (s *UserService) Login(req *models.LoginRequest, actor models.ActivityActor) (*models.LoginResponse, error) {
 	// Find user by username
 	var user models.Users
 	if err := s.db.Where("username = ?", req.Username).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.activityService.RecordLogin(nil, req.Username, false, models.LoginFailureUnknownUser, actor)
 			return nil, errors.New("invalid username or password")
 		}
 		return nil, err
 	}
 
 	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+	if matches, err := s.passwords.Verify(user.Password, req.Password); err != nil || !matches {
+		if err != nil {
+			slog.Error("Failed to verify password", "user_id", user.ID, "error", err)
+		}
+		s.activityService.Record(user.ID, models.ActivityLoginFailed, actor, "Failed login attempt", nil)
+		s.activityService.RecordLogin(&user.ID, user.Username, false, models.LoginFailureInvalidPassword, actor)
 		return nil, errors.New("invalid username or password")
 	}
 
+	// Only reveal the account status once the password has been verified
+	switch user.Status {
+	case models.UserStatusSuspended:
+		s.activityService.Record(user.ID, models.ActivityLoginFailed, actor, "Login attempt on a suspended account", nil)
+		s.activityService.RecordLogin(&user.ID, user.Username, false, models.LoginFailureAccountSuspended, actor)
+		return nil, errors.New("account suspended")
+	case models.UserStatusPending:
+		s.activityService.RecordLogin(&user.ID, user.Username, false, models.LoginFailureAccountPending, actor)
+		return nil, errors.New("account pending")
+	}
+
+	// Move the password to the configured algorithm while it is at hand
+	if s.passwords.NeedsRehash(user.Password) {
+		if hashedPassword, err := s.passwords.Hash(req.Password); err != nil {
+			slog.Warn("Failed to rehash password", "user_id", user.ID, "error", err)
+		} else if err := s.db.Model(&user).UpdateColumn("password", hashedPassword).Error; err != nil {
+			slog.Warn("Failed to store rehashed password", "user_id", user.ID, "error", err)
+		}
+	}
+
 	// Generate tokens
 	accessToken, accessExp, err := s.generateToken(user, s.config.JWTExpiry)
 	if err != nil {
@@ -136,14 +223,28 @@ func (s *UserService) Login(req *models.LoginRequest) (*models.LoginResponse, er
 		return nil, err
 	}
 
+	// Track the last login without touching updated_at
+	now := time.Now()
+	if err := s.db.Model(&user).UpdateColumns(map[string]interface{}{
+		"last_login_at": now,
+		"last_login_ip": actor.IPAddress,
+	}).Error; err != nil {
+		slog.Warn("Failed to update last login", "user_id", user.ID, "error", err)
+	}
+
+	actor.UserID = user.ID
+	s.activityService.Record(user.ID, models.ActivityLogin, actor, "Logged in", nil)
+	s.activityService.RecordLogin(&user.ID, user.Username, true, "", actor)
+
 	// Create response
 	return &models.LoginResponse{
 		User: models.RegisterResponse{
-			ID:       user.ID,
-			Username: user.Username,
-			Email:    user.Email,
-			Name:     user.Name,
-			Role:     user.Role,
+			ID:                 user.ID,
+			Username:           user.Username,
+			Email:              user.Email,
+			Name:               user.Name,
+			Role:               user.Role,
+			MustChangePassword: user.MustChangePassword,
 		},
 		Token: models.TokenResponse{
 			AccessToken:  accessToken,
@@ -180,22 +281,37 @@ func (s *UserService) generateToken(user models.Users, expiry time.Duration) (st
 	return tokenString, expirationTime, nil
 }
 
-// GetAllUsers retrieves users with pagination, search, and filters
-func (s *UserService) GetAllUsers(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
-	config := pagination.PaginationConfig{
+// userPaginationConfig returns the pagination config shared by user listing and export
+func (s *UserService) userPaginationConfig() pagination.PaginationConfig {
+	return pagination.PaginationConfig{
 		Model: &models.Users{},
 		BaseCondition: map[string]interface{}{
 			"is_deleted": false,
 		},
 		SearchFields: []string{"name", "email", "username"},
+		FullText: &pagination.FullTextSearch{
+			Column: "search_vector",
+		},
 		FilterFields: map[string]string{
 			"role":       "role",
+			"status":     "status",
 			"name":       "name",
 			"email":      "email",
 			"username":   "username",
 			"created_at": "created_at",
 			"updated_at": "updated_at",
 		},
+		CustomFilters: map[string]string{
+			"team_id":  "id IN (SELECT user_id FROM team_members WHERE team_id = ?)",
+			"tag_id":   "id IN (SELECT user_id FROM user_tags WHERE tag_id = ?)",
+			"tag":      "id IN (SELECT user_tags.user_id FROM user_tags JOIN tags ON tags.id = user_tags.tag_id WHERE tags.name = ?)",
+			"store_id": "id IN (SELECT user_id FROM user_stores WHERE store_id = ?)",
+		},
+		// Users without store assignments work in every store
+		StoreFilter: "id IN (SELECT user_id FROM user_stores WHERE store_id = ?) OR NOT EXISTS (SELECT 1 FROM user_stores WHERE user_stores.user_id = users.id)",
+		JSONFilterFields: map[string]string{
+			"metadata": "metadata",
+		},
 		DateFields: map[string]pagination.DateField{
 			"created_at": {
 				Start: "created_at",
@@ -205,6 +321,10 @@ func (s *UserService) GetAllUsers(params pagination.QueryParams) (*pagination.Pa
 				Start: "updated_at",
 				End:   "updated_at",
 			},
+			"last_login_at": {
+				Start: "last_login_at",
+				End:   "last_login_at",
+			},
 		},
 		SortFields: []string{
 			"name",
@@ -212,13 +332,68 @@ func (s *UserService) GetAllUsers(params pagination.QueryParams) (*pagination.Pa
 			"role",
 			"created_at",
 			"updated_at",
+			"last_login_at",
 		},
 		DefaultSort:  "created_at",
 		DefaultOrder: "DESC",
 	}
+}
+
+// userFields maps the user fields that can be requested through the fields parameter to their columns.
+// Tags are preloaded and is_online comes from Redis presence rather than a column.
+var userFields = map[string]string{
+	"id":                   "id",
+	"username":             "username",
+	"email":                "email",
+	"name":                 "name",
+	"role":                 "role",
+	"status":               "status",
+	"status_reason":        "status_reason",
+	"status_changed_at":    "status_changed_at",
+	"created_at":           "created_at",
+	"updated_at":           "updated_at",
+	"is_deleted":           "is_deleted",
+	"soft_deleted_at":      "soft_deleted_at",
+	"purged_at":            "purged_at",
+	"metadata":             "metadata",
+	"last_login_at":        "last_login_at",
+	"last_login_ip":        "last_login_ip",
+	"must_change_password": "must_change_password",
+	"pending_email":        "pending_email",
+	"merged_into_id":       "merged_into_id",
+	"tags":                 "",
+	"is_online":            "",
+}
+
+// ParseUserFields parses the fields parameter of user endpoints; the ID is always included
+func ParseUserFields(raw string) (*pagination.FieldSet, error) {
+	return pagination.ParseFields(raw, userFields, "id")
+}
+
+// GetAllUsers retrieves users with pagination, search, and filters.
+// With a field set only those columns are selected and returned.
+func (s *UserService) GetAllUsers(params pagination.QueryParams, fields *pagination.FieldSet) (*pagination.PaginatedResponse, error) {
+	config := s.userPaginationConfig()
+	config.SelectFields = fields.SelectFields()
+	if fields.Has("tags") {
+		config.Relations = []string{"Tags"}
+	}
 
 	paginator := pagination.NewPaginator(s.db)
-	return paginator.Paginate(params, config)
+	response, err := paginator.Paginate(params, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if users, ok := response.Data.([]models.Users); ok && fields.Has("is_online") {
+		s.presence.MarkOnline(context.Background(), users)
+	}
+
+	response.Data, err = fields.Shape(response.Data)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
 
 	// Pagination Example (with join)
 	// GetAllUsers retrieves users with pagination, search, and filters
@@ -256,16 +431,79 @@ func (s *UserService) GetAllUsers(params pagination.QueryParams) (*pagination.Pa
 	// return paginator.Paginate(params, config)
 }
 
-func (s *UserService) GetUserById(id string) (models.Users, error) {
+// GetOnlineUsers lists the users currently online
+func (s *UserService) GetOnlineUsers() ([]models.OnlineUser, error) {
+	return s.presence.GetOnlineUsers(context.Background())
+}
+
+// CheckExportQuery rejects filters, date ranges and sorts a users export doesn't support
+func (s *UserService) CheckExportQuery(params pagination.QueryParams) error {
+	return s.userPaginationConfig().Check(params)
+}
+
+// ExportUsers streams every user matching the search and filters, ignoring page limits
+func (s *UserService) ExportUsers(params pagination.QueryParams, fn func(user *models.Users) error) error {
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Stream(params, s.userPaginationConfig(), func(record interface{}) error {
+		return fn(record.(*models.Users))
+	})
+}
+
+// GetDeletedUsers retrieves soft-deleted users with pagination, search, and filters
+func (s *UserService) GetDeletedUsers(params pagination.QueryParams) (*pagination.PaginatedResponse, error) {
+	config := s.userPaginationConfig()
+	config.BaseCondition = nil
+	config.Conditions = []string{"is_deleted = true OR deleted_at IS NOT NULL"}
+	config.Unscoped = true
+
+	paginator := pagination.NewPaginator(s.db)
+	return paginator.Paginate(params, config)
+}
+
+// GetUserById retrieves a user, limited to the requested fields when a field set is given
+func (s *UserService) GetUserById(id uint, fields *pagination.FieldSet) (interface{}, error) {
+	query := s.db
+	if selects := fields.SelectFields(); len(selects) > 0 {
+		columns := make([]string, len(selects))
+		for i, field := range selects {
+			columns[i] = field.Field
+		}
+		query = query.Select(columns)
+	}
+	if fields.Has("tags") {
+		query = query.Preload("Tags", func(db *gorm.DB) *gorm.DB {
+			return db.Order("name ASC")
+		})
+	}
+
 	var user models.Users
-	if err := s.db.Where("id = ?", id).First(&user).Error; err != nil {
-		return models.Users{}, err
+	if err := query.Where("id = ?", id).First(&user).Error; err != nil {
+		return nil, err
 	}
-	return user, nil
+	return fields.Shape(user)
+}
+
+// FindUser retrieves a user by username or email, whichever is given
+func (s *UserService) FindUser(ctx context.Context, username string, email string) (*models.Users, error) {
+	query := s.db.WithContext(ctx).Where("is_deleted = ?", false)
+	switch {
+	case username != "":
+		query = query.Where("username = ?", username)
+	case email != "":
+		query = query.Where("email = ?", email)
+	default:
+		return nil, errors.New("username or email is required")
+	}
+
+	var user models.Users
+	if err := query.First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
 }
 
 // CreateUser creates a new user with the provided data
-func (s *UserService) CreateUser(req *models.CreateUserRequest) (*models.CreateUserResponse, error) {
+func (s *UserService) CreateUser(req *models.CreateUserRequest, actor models.ActivityActor) (*models.CreateUserResponse, error) {
 	// Check if username already exists
 	var existingUser models.Users
 	if err := s.db.Where("username = ?", req.Username).First(&existingUser).Error; err == nil {
@@ -282,7 +520,7 @@ func (s *UserService) CreateUser(req *models.CreateUserRequest) (*models.CreateU
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.passwords.Hash(req.Password)
 	if err != nil {
 		return nil, err
 	}
@@ -291,15 +529,29 @@ func (s *UserService) CreateUser(req *models.CreateUserRequest) (*models.CreateU
 	user := models.Users{
 		Username: req.Username,
 		Email:    req.Email,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 		Name:     req.Name,
 		Role:     req.Role,
+		Status:   req.Status,
+		Metadata: req.Metadata,
+	}
+	if user.Metadata == nil {
+		user.Metadata = models.JSONMap{}
+	}
+	if user.Status == "" {
+		user.Status = models.UserStatusActive
 	}
 
 	if err := s.db.Create(&user).Error; err != nil {
 		return nil, err
 	}
 
+	s.changes.Publish(models.ChangeEntityUser, models.ChangeActionCreated, user.ID)
+
+	s.activityService.Record(user.ID, models.ActivityUserCreated, actor, "Account created by an administrator", models.JSONMap{
+		"role": user.Role,
+	})
+
 	// Return user data without password
 	return &models.CreateUserResponse{
 		ID:        user.ID,
@@ -307,29 +559,64 @@ func (s *UserService) CreateUser(req *models.CreateUserRequest) (*models.CreateU
 		Email:     user.Email,
 		Name:      user.Name,
 		Role:      user.Role,
+		Status:    user.Status,
+		Metadata:  user.Metadata,
 		CreatedAt: user.CreatedAt,
 	}, nil
 }
 
-func (s *UserService) UpdateUser(id string, req *models.UpdateUserRequest) (*models.Users, error) {
+func (s *UserService) UpdateUser(id uint, req *models.UpdateUserRequest, actor models.ActivityActor) (*models.Users, error) {
 	var user models.Users
 	if err := s.db.Where("id = ?", id).First(&user).Error; err != nil {
 		return nil, err
 	}
 
+	// Keep the previous values to describe the change in the activity timeline
+	previous := user
+
+	// A new email only takes effect once it has been confirmed
+	emailChanged := req.Email != user.Email
+	var emailToken string
+	if emailChanged {
+		var existingUser models.Users
+		if err := s.db.Where("email = ? AND id <> ?", req.Email, user.ID).First(&existingUser).Error; err == nil {
+			return nil, errors.New("email already exists")
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		token, err := generateLinkToken()
+		if err != nil {
+			return nil, err
+		}
+		emailToken = token
+
+		tokenHash := hashLinkToken(token)
+		expiresAt := time.Now().Add(emailChangeTokenTTL)
+		pendingEmail := req.Email
+		user.PendingEmail = &pendingEmail
+		user.EmailChangeTokenHash = &tokenHash
+		user.EmailChangeExpiresAt = &expiresAt
+	}
+
 	// Update user fields
 	user.Username = req.Username
-	user.Email = req.Email
 	user.Name = req.Name
-	user.Role = req.Role
+	if req.Metadata != nil {
+		user.Metadata = req.Metadata
+	}
+	if user.Role != req.Role {
+		user.Role = req.Role
+		user.ClaimsVersion++
+	}
 
 	// Only update password if provided
 	if req.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		hashedPassword, err := s.passwords.Hash(req.Password)
 		if err != nil {
 			return nil, err
 		}
-		user.Password = string(hashedPassword)
+		user.Password = hashedPassword
 	}
 
 	// Update user
@@ -338,12 +625,138 @@ func (s *UserService) UpdateUser(id string, req *models.UpdateUserRequest) (*mod
 	}
 
 	// Invalidate user cache after update
-	s.invalidateUserCache(user.ID)
+	s.userChanged(user.ID, models.ChangeActionUpdated)
+
+	s.recordUpdateActivity(previous, user, req.Password != "", actor)
+
+	if emailChanged {
+		s.sendEmailChangeMails(user, emailToken)
+		s.activityService.Record(user.ID, models.ActivityEmailChangeRequested, actor, "Email change requested", models.JSONMap{
+			"from": user.Email,
+			"to":   req.Email,
+		})
+	}
 
 	return &user, nil
 }
 
-func (s *UserService) DeleteUser(id string) (*models.Users, error) {
+// generateLinkToken generates a random token for links sent by email
+func generateLinkToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashLinkToken hashes a link token for storage
+func hashLinkToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// sendEmailChangeMails sends the confirmation link to the new address and a notice to the current one.
+// Failures are logged; the change can be requested again to resend the link.
+func (s *UserService) sendEmailChangeMails(user models.Users, token string) {
+	link := fmt.Sprintf("%s/confirm-email?token=%s", s.config.FrontendURL, token)
+
+	confirmation := mailer.Message{
+		To:      *user.PendingEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your new email address by opening the link below. "+
+			"The link expires in %d hours.\n\n%s\n\nIf you did not request this change, you can ignore this email.",
+			user.Name, int(emailChangeTokenTTL.Hours()), link),
+	}
+	if err := s.notifications.SendEmail(user.ID, models.NotificationEventAccountSecurity, confirmation); err != nil {
+		slog.Error("Failed to send email confirmation", "user_id", user.ID, "error", err)
+	}
+
+	notice := mailer.Message{
+		To:      user.Email,
+		Subject: "Your email address is being changed",
+		Body: fmt.Sprintf("Hi %s,\n\nA change of the email address on your account to %s was requested. "+
+			"It takes effect once the new address is confirmed.\n\nIf you did not request this change, please contact an administrator.",
+			user.Name, *user.PendingEmail),
+	}
+	if err := s.notifications.SendEmail(user.ID, models.NotificationEventAccountSecurity, notice); err != nil {
+		slog.Error("Failed to send email change notice", "user_id", user.ID, "error", err)
+	}
+}
+
+// ConfirmEmailChange swaps in the pending email for the user the token was issued to
+func (s *UserService) ConfirmEmailChange(token string, actor models.ActivityActor) (*models.Users, error) {
+	var user models.Users
+	if err := s.db.Where("email_change_token_hash = ?", hashLinkToken(token)).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid email confirmation token")
+		}
+		return nil, err
+	}
+
+	if user.PendingEmail == nil || user.EmailChangeExpiresAt == nil || time.Now().After(*user.EmailChangeExpiresAt) {
+		return nil, errors.New("email confirmation token expired")
+	}
+
+	// The address may have been taken while the change was pending
+	var existingUser models.Users
+	if err := s.db.Where("email = ? AND id <> ?", *user.PendingEmail, user.ID).First(&existingUser).Error; err == nil {
+		return nil, errors.New("email already exists")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	previousEmail := user.Email
+	if err := s.db.Model(&user).Updates(map[string]interface{}{
+		"email":                   *user.PendingEmail,
+		"pending_email":           nil,
+		"email_change_token_hash": nil,
+		"email_change_expires_at": nil,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	s.userChanged(user.ID, models.ChangeActionUpdated)
+
+	s.activityService.Record(user.ID, models.ActivityEmailChanged, actor, "Email changed", models.JSONMap{
+		"from": previousEmail,
+		"to":   user.Email,
+	})
+
+	return &user, nil
+}
+
+// recordUpdateActivity records profile, role, and password changes made by UpdateUser
+func (s *UserService) recordUpdateActivity(previous, updated models.Users, passwordChanged bool, actor models.ActivityActor) {
+	changes := models.JSONMap{}
+	if previous.Username != updated.Username {
+		changes["username"] = models.JSONMap{"from": previous.Username, "to": updated.Username}
+	}
+	if previous.Email != updated.Email {
+		changes["email"] = models.JSONMap{"from": previous.Email, "to": updated.Email}
+	}
+	if previous.Name != updated.Name {
+		changes["name"] = models.JSONMap{"from": previous.Name, "to": updated.Name}
+	}
+	if !reflect.DeepEqual(previous.Metadata, updated.Metadata) {
+		changes["metadata"] = models.JSONMap{"from": previous.Metadata, "to": updated.Metadata}
+	}
+	if len(changes) > 0 {
+		s.activityService.Record(updated.ID, models.ActivityProfileUpdated, actor, "Profile updated", changes)
+	}
+
+	if previous.Role != updated.Role {
+		s.activityService.Record(updated.ID, models.ActivityRoleChanged, actor, "Role changed", models.JSONMap{
+			"from": previous.Role,
+			"to":   updated.Role,
+		})
+	}
+
+	if passwordChanged {
+		s.activityService.Record(updated.ID, models.ActivityPasswordChanged, actor, "Password changed", nil)
+	}
+}
+
+func (s *UserService) DeleteUser(id uint, actor models.ActivityActor) (*models.Users, error) {
 	var user models.Users
 	if err := s.db.Where("id = ?", id).First(&user).Error; err != nil {
 		return nil, err
@@ -354,23 +767,292 @@ func (s *UserService) DeleteUser(id string) (*models.Users, error) {
 	}
 
 	// Invalidate user cache after deletion
-	s.invalidateUserCache(user.ID)
+	s.userChanged(user.ID, models.ChangeActionDeleted)
+
+	s.activityService.Record(user.ID, models.ActivityUserDeleted, actor, "Account deleted", nil)
 
 	return &user, nil
 }
 
-func (s *UserService) SoftDeleteUser(id string) (*models.Users, error) {
+func (s *UserService) SoftDeleteUser(id uint, actor models.ActivityActor) (*models.Users, error) {
 	var user models.Users
 	if err := s.db.Where("id = ?", id).First(&user).Error; err != nil {
 		return nil, err
 	}
 
-	if err := s.db.Model(&user).Update("is_deleted", true).Error; err != nil {
+	if err := s.db.Model(&user).Updates(map[string]interface{}{
+		"is_deleted":      true,
+		"soft_deleted_at": time.Now(),
+	}).Error; err != nil {
 		return nil, err
 	}
 
 	// Invalidate user cache after soft deletion
-	s.invalidateUserCache(user.ID)
+	s.userChanged(user.ID, models.ChangeActionDeleted)
+
+	s.activityService.Record(user.ID, models.ActivityUserSoftDeleted, actor, "Account deactivated", nil)
+
+	return &user, nil
+}
+
+// RestoreUser undoes a soft delete, provided the username and email are still free among active users
+func (s *UserService) RestoreUser(id uint, actor models.ActivityActor) (*models.Users, error) {
+	var user models.Users
+	if err := s.db.Unscoped().Where("id = ?", id).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	if !user.IsDeleted && !user.DeletedAt.Valid {
+		return nil, errors.New("user is not deleted")
+	}
+
+	// Anonymized users keep their row but no longer have anything worth restoring
+	if user.PurgedAt != nil {
+		return nil, errors.New("user has been purged")
+	}
+
+	// Check if username was taken by an active user in the meantime
+	var existingUser models.Users
+	if err := s.db.Where("username = ? AND id <> ? AND is_deleted = ?", user.Username, user.ID, false).First(&existingUser).Error; err == nil {
+		return nil, errors.New("username already exists")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// Check if email was taken by an active user in the meantime
+	if err := s.db.Where("email = ? AND id <> ? AND is_deleted = ?", user.Email, user.ID, false).First(&existingUser).Error; err == nil {
+		return nil, errors.New("email already exists")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := s.db.Unscoped().Model(&user).Updates(map[string]interface{}{
+		"is_deleted":      false,
+		"soft_deleted_at": nil,
+		"deleted_at":      nil,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	// Invalidate user cache after restore
+	s.userChanged(user.ID, models.ChangeActionUpdated)
+
+	s.activityService.Record(user.ID, models.ActivityUserRestored, actor, "Account restored", nil)
 
 	return &user, nil
 }
+
+// ChangeUserStatus moves a user to a new status if the transition is allowed.
+// The cached user is invalidated so the auth middleware sees the new status on the next request.
+func (s *UserService) ChangeUserStatus(id uint, status string, reason string, actor models.ActivityActor) (*models.Users, error) {
+	var user models.Users
+	if err := s.db.Where("id = ?", id).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	if user.ID == actor.UserID && status == models.UserStatusSuspended {
+		return nil, errors.New("cannot suspend yourself")
+	}
+
+	allowed := false
+	for _, next := range models.UserStatusTransitions[user.Status] {
+		if next == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, errors.New("invalid status transition")
+	}
+
+	previous := user.Status
+	now := time.Now()
+	if err := s.db.Model(&user).Updates(map[string]interface{}{
+		"status":            status,
+		"status_reason":     reason,
+		"status_changed_at": now,
+		"claims_version":    gorm.Expr("claims_version + 1"),
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	s.userChanged(user.ID, models.ChangeActionUpdated)
+
+	s.activityService.Record(user.ID, models.ActivityStatusChanged, actor, fmt.Sprintf("Status changed from %s to %s", previous, status), models.JSONMap{
+		"from":   previous,
+		"to":     status,
+		"reason": reason,
+	})
+
+	return &user, nil
+}
+
+// generateTemporaryPassword generates a random password for an administrator reset
+func generateTemporaryPassword() (string, error) {
+	max := big.NewInt(int64(len(temporaryPasswordAlphabet)))
+	password := make([]byte, temporaryPasswordLength)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		password[i] = temporaryPasswordAlphabet[n.Int64()]
+	}
+	return string(password), nil
+}
+
+// AdminResetPassword resets a user's password on behalf of an administrator.
+// The temporary method replaces the password and returns it once; the email method
+// sends the user a reset link. Either way the user must choose a new password before doing anything else.
+func (s *UserService) AdminResetPassword(id uint, method string, actor models.ActivityActor) (*models.AdminResetPasswordResponse, error) {
+	var user models.Users
+	if err := s.db.Where("id = ?", id).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	if method == "" {
+		method = models.PasswordResetTemporary
+	}
+
+	response := &models.AdminResetPasswordResponse{
+		UserID: user.ID,
+		Method: method,
+	}
+	updates := map[string]interface{}{
+		"must_change_password": true,
+	}
+
+	var token string
+	switch method {
+	case models.PasswordResetTemporary:
+		password, err := generateTemporaryPassword()
+		if err != nil {
+			return nil, err
+		}
+		hashedPassword, err := s.passwords.Hash(password)
+		if err != nil {
+			return nil, err
+		}
+		updates["password"] = hashedPassword
+		updates["password_reset_token_hash"] = nil
+		updates["password_reset_expires_at"] = nil
+		response.TemporaryPassword = password
+	case models.PasswordResetEmail:
+		generated, err := generateLinkToken()
+		if err != nil {
+			return nil, err
+		}
+		token = generated
+		updates["password_reset_token_hash"] = hashLinkToken(token)
+		updates["password_reset_expires_at"] = time.Now().Add(passwordResetTokenTTL)
+	default:
+		return nil, errors.New("invalid password reset method")
+	}
+
+	if err := s.db.Model(&user).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	// Invalidate user cache so the auth middleware enforces the password change right away
+	s.userChanged(user.ID, models.ChangeActionUpdated)
+
+	if method == models.PasswordResetEmail {
+		link := fmt.Sprintf("%s/reset-password?token=%s", s.config.FrontendURL, token)
+		message := mailer.Message{
+			To:      user.Email,
+			Subject: "Reset your password",
+			Body: fmt.Sprintf("Hi %s,\n\nAn administrator has reset the password on your account. "+
+				"Choose a new password by opening the link below. The link expires in %d minutes.\n\n%s",
+				user.Name, int(passwordResetTokenTTL.Minutes()), link),
+		}
+		if err := s.notifications.SendEmail(user.ID, models.NotificationEventAccountSecurity, message); err != nil {
+			return nil, fmt.Errorf("failed to send password reset email: %w", err)
+		}
+	}
+
+	s.activityService.Record(user.ID, models.ActivityPasswordReset, actor, "Password reset by an administrator", models.JSONMap{
+		"method": method,
+	})
+
+	return response, nil
+}
+
+// ResetPassword sets a new password using the token from a reset link
+func (s *UserService) ResetPassword(token string, password string, actor models.ActivityActor) error {
+	var user models.Users
+	if err := s.db.Where("password_reset_token_hash = ?", hashLinkToken(token)).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("invalid password reset token")
+		}
+		return err
+	}
+
+	if user.PasswordResetExpiresAt == nil || time.Now().After(*user.PasswordResetExpiresAt) {
+		return errors.New("password reset token expired")
+	}
+
+	if err := s.setPassword(&user, password); err != nil {
+		return err
+	}
+
+	actor.UserID = user.ID
+	s.activityService.Record(user.ID, models.ActivityPasswordChanged, actor, "Password changed from a reset link", nil)
+
+	return nil
+}
+
+// ChangePassword changes the signed-in user's own password after checking the current one
+func (s *UserService) ChangePassword(userID uint, req *models.ChangePasswordRequest, actor models.ActivityActor) error {
+	var user models.Users
+	if err := s.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("user not found")
+		}
+		return err
+	}
+
+	matches, err := s.passwords.Verify(user.Password, req.CurrentPassword)
+	if err != nil {
+		return err
+	}
+	if !matches {
+		return errors.New("current password is incorrect")
+	}
+
+	if err := s.setPassword(&user, req.NewPassword); err != nil {
+		return err
+	}
+
+	s.activityService.Record(user.ID, models.ActivityPasswordChanged, actor, "Password changed", nil)
+
+	return nil
+}
+
+// setPassword stores a new password and clears any pending reset
+func (s *UserService) setPassword(user *models.Users, password string) error {
+	hashedPassword, err := s.passwords.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Model(user).Updates(map[string]interface{}{
+		"password":                  hashedPassword,
+		"must_change_password":      false,
+		"password_reset_token_hash": nil,
+		"password_reset_expires_at": nil,
+	}).Error; err != nil {
+		return err
+	}
+
+	s.userChanged(user.ID, models.ChangeActionUpdated)
+	return nil
+}
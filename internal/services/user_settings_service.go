@@ -0,0 +1,110 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+
+// knownSettings holds the validators for settings keys the clients agree on.
+// Keys not listed here are passed through untouched so clients can store their own preferences.
+var knownSettings = map[string]func(value interface{}) error{
+	"theme": func(value interface{}) error {
+		theme, ok := value.(string)
+		if !ok || (theme != "light" && theme != "dark" && theme != "system") {
+			return errors.New("must be one of light, dark, system")
+		}
+		return nil
+	},
+	"locale": func(value interface{}) error {
+		locale, ok := value.(string)
+		if !ok || !localePattern.MatchString(locale) {
+			return errors.New("must be a locale code such as en or en-US")
+		}
+		return nil
+	},
+	"default_store": func(value interface{}) error {
+		if value == nil {
+			return nil
+		}
+		id, ok := value.(float64)
+		if !ok || id < 1 || id != float64(int64(id)) {
+			return errors.New("must be a store ID or null")
+		}
+		return nil
+	},
+	"table_columns": func(value interface{}) error {
+		tables, ok := value.(map[string]interface{})
+		if !ok {
+			return errors.New("must be an object of table names to column lists")
+		}
+		for table, columns := range tables {
+			list, ok := columns.([]interface{})
+			if !ok {
+				return fmt.Errorf("columns for %s must be a list", table)
+			}
+			for _, column := range list {
+				if _, ok := column.(string); !ok {
+					return fmt.Errorf("columns for %s must be strings", table)
+				}
+			}
+		}
+		return nil
+	},
+}
+
+type UserSettingsService struct {
+	db *gorm.DB
+}
+
+func NewUserSettingsService(db *gorm.DB) *UserSettingsService {
+	return &UserSettingsService{db: db}
+}
+
+// ValidateUserSettings validates known settings keys and returns the problems keyed by setting name
+func ValidateUserSettings(settings models.JSONMap) map[string]string {
+	problems := map[string]string{}
+	for key, value := range settings {
+		if validate, ok := knownSettings[key]; ok {
+			if err := validate(value); err != nil {
+				problems[key] = err.Error()
+			}
+		}
+	}
+	return problems
+}
+
+// GetUserSettings returns the user's settings, or empty settings if none were saved yet
+func (s *UserSettingsService) GetUserSettings(userID uint) (*models.UserSettings, error) {
+	var settings models.UserSettings
+	if err := s.db.Where("user_id = ?", userID).First(&settings).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.UserSettings{UserID: userID, Settings: models.JSONMap{}}, nil
+		}
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// UpdateUserSettings replaces the user's settings
+func (s *UserSettingsService) UpdateUserSettings(userID uint, values models.JSONMap) (*models.UserSettings, error) {
+	var settings models.UserSettings
+	err := s.db.Where("user_id = ?", userID).First(&settings).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	settings.UserID = userID
+	settings.Settings = values
+
+	if err := s.db.Save(&settings).Error; err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
@@ -0,0 +1,251 @@
+// Package slo tracks response time objectives per route group. Each objective asks that a
+// share of the requests under a path prefix finish within a threshold; the rest use up its
+// error budget. Budgets are tracked per API instance over a sliding window, which is enough to
+// notice a route group getting slow without a metrics backend.
+package slo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Buckets per window; the short window is the last shortBuckets of them
+	windowBuckets = 60
+	shortBuckets  = 5
+	// Windows with fewer requests than this never alert, so a handful of slow requests at
+	// night don't page anyone
+	minAlertRequests = 20
+)
+
+// Objective is a latency objective: Target of the requests under Route must finish within
+// Threshold
+type Objective struct {
+	Route     string
+	Threshold time.Duration
+	Target    float64
+}
+
+// Parse parses objectives written as route=threshold@target-percent and separated by commas,
+// e.g. "/api/orders=300ms@99,/api/products=200ms@99.5"
+func Parse(value string) ([]Objective, error) {
+	var objectives []Objective
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		route, rest, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(route, "/") {
+			return nil, fmt.Errorf("%q must look like /api/orders=300ms@99", entry)
+		}
+		threshold, target, ok := strings.Cut(rest, "@")
+		if !ok {
+			return nil, fmt.Errorf("%q must look like /api/orders=300ms@99", entry)
+		}
+
+		objective := Objective{Route: strings.TrimRight(route, "/")}
+		var err error
+		if objective.Threshold, err = time.ParseDuration(threshold); err != nil || objective.Threshold <= 0 {
+			return nil, fmt.Errorf("%q has an invalid threshold", entry)
+		}
+		percent, err := strconv.ParseFloat(target, 64)
+		if err != nil || percent <= 0 || percent >= 100 {
+			return nil, fmt.Errorf("%q must have a target between 0 and 100 percent", entry)
+		}
+		objective.Target = percent / 100
+		objectives = append(objectives, objective)
+	}
+	return objectives, nil
+}
+
+// bucket counts the requests of one slice of the window
+type bucket struct {
+	start int64
+	total int64
+	slow  int64
+}
+
+// series holds the buckets and alert state of one objective
+type series struct {
+	objective Objective
+	buckets   [windowBuckets]bucket
+	alerting  bool
+}
+
+// Window summarizes the requests of an objective over a window
+type Window struct {
+	Requests int64 `json:"requests"`
+	Slow     int64 `json:"slow"`
+	// How fast the error budget is used up: 1 uses it up exactly over the SLO window
+	BurnRate float64 `json:"burn_rate"`
+}
+
+// Status reports an objective with its burn rates over the long and short window
+type Status struct {
+	Route     string  `json:"route"`
+	Threshold string  `json:"threshold"`
+	Target    float64 `json:"target"`
+	Long      Window  `json:"long_window"`
+	Short     Window  `json:"short_window"`
+	// Share of the long window's error budget not used yet
+	BudgetRemaining float64 `json:"budget_remaining"`
+	Alerting        bool    `json:"alerting"`
+}
+
+// Report is the state of every objective
+type Report struct {
+	Window      string   `json:"window"`
+	ShortWindow string   `json:"short_window"`
+	AlertBurn   float64  `json:"alert_burn_rate"`
+	Objectives  []Status `json:"objectives"`
+}
+
+// Tracker counts requests against the objectives. It is safe for concurrent use.
+type Tracker struct {
+	window    time.Duration
+	alertBurn float64
+	mu        sync.Mutex
+	series    []*series
+	now       func() time.Time
+}
+
+// NewTracker creates a tracker over the given window that alerts once both windows burn the
+// budget at alertBurn times the sustainable rate or faster. Longer routes are matched first.
+func NewTracker(objectives []Objective, window time.Duration, alertBurn float64) *Tracker {
+	sorted := append([]Objective(nil), objectives...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].Route) > len(sorted[j].Route)
+	})
+
+	t := &Tracker{
+		window:    window,
+		alertBurn: alertBurn,
+		now:       time.Now,
+	}
+	for _, objective := range sorted {
+		t.series = append(t.series, &series{objective: objective})
+	}
+	return t
+}
+
+// Enabled reports whether any objective is configured
+func (t *Tracker) Enabled() bool {
+	return len(t.series) > 0
+}
+
+// match returns the series of the objective covering a route, or nil
+func (t *Tracker) match(route string) *series {
+	for _, s := range t.series {
+		if route == s.objective.Route || strings.HasPrefix(route, s.objective.Route+"/") {
+			return s
+		}
+	}
+	return nil
+}
+
+// bucketWidth is the time covered by one bucket
+func (t *Tracker) bucketWidth() int64 {
+	return max(int64(t.window/windowBuckets), 1)
+}
+
+// Observe counts a request to a route that took the given time
+func (t *Tracker) Observe(route string, elapsed time.Duration) {
+	s := t.match(route)
+	if s == nil {
+		return
+	}
+
+	width := t.bucketWidth()
+	start := t.now().UnixNano() / width * width
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b := &s.buckets[(start/width)%windowBuckets]
+	if b.start != start {
+		*b = bucket{start: start}
+	}
+	b.total++
+	if elapsed > s.objective.Threshold {
+		b.slow++
+	}
+}
+
+// sum adds up the buckets of the last n bucket widths
+func (t *Tracker) sum(s *series, n int64) Window {
+	width := t.bucketWidth()
+	oldest := t.now().UnixNano()/width*width - (n-1)*width
+
+	var w Window
+	for _, b := range s.buckets {
+		if b.start >= oldest {
+			w.Requests += b.total
+			w.Slow += b.slow
+		}
+	}
+	if w.Requests > 0 {
+		w.BurnRate = float64(w.Slow) / float64(w.Requests) / (1 - s.objective.Target)
+	}
+	return w
+}
+
+// status reports one objective; callers hold the lock
+func (t *Tracker) status(s *series) Status {
+	long := t.sum(s, windowBuckets)
+	return Status{
+		Route:           s.objective.Route,
+		Threshold:       s.objective.Threshold.String(),
+		Target:          s.objective.Target,
+		Long:            long,
+		Short:           t.sum(s, shortBuckets),
+		BudgetRemaining: math.Max(0, 1-long.BurnRate),
+		Alerting:        s.alerting,
+	}
+}
+
+// Report returns the state of every objective
+func (t *Tracker) Report() Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := Report{
+		Window:      t.window.String(),
+		ShortWindow: (t.window / windowBuckets * shortBuckets).String(),
+		AlertBurn:   t.alertBurn,
+		Objectives:  make([]Status, len(t.series)),
+	}
+	for i, s := range t.series {
+		report.Objectives[i] = t.status(s)
+	}
+	return report
+}
+
+// Check returns the objectives that started burning their budget too fast since the last
+// check. Both windows must burn at the alert rate: the long window shows the budget is really
+// at risk, the short one that it still is. An objective alerts again once it recovered.
+func (t *Tracker) Check() []Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var alerts []Status
+	for _, s := range t.series {
+		status := t.status(s)
+		burning := status.Short.Requests >= minAlertRequests &&
+			status.Long.BurnRate >= t.alertBurn && status.Short.BurnRate >= t.alertBurn
+		if burning && !s.alerting {
+			s.alerting = true
+			status.Alerting = true
+			alerts = append(alerts, status)
+		} else if !burning && status.Long.BurnRate < t.alertBurn {
+			s.alerting = false
+		}
+	}
+	return alerts
+}
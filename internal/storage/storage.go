@@ -0,0 +1,99 @@
+// Package storage keeps uploaded files, such as product images, behind an interface so the
+// API doesn't care whether they live on local disk or in an object store.
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Storage stores files under slash-separated keys and tells where clients can fetch them
+type Storage interface {
+	// Put stores data under the key, replacing any file already there
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Delete removes the files under the keys; missing files are not an error
+	Delete(ctx context.Context, keys ...string) error
+	// URL returns the address clients fetch the file under the key from
+	URL(key string) string
+}
+
+// Local stores files in a directory on disk. They are served by the API itself unless the
+// public URL points elsewhere, e.g. at a CDN in front of the directory.
+type Local struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocal returns a storage keeping files in dir, creating it when needed, and serving them
+// under baseURL
+func NewLocal(dir string, baseURL string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Local{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+// path returns where the file under the key is kept, refusing keys that would leave the directory
+func (l *Local) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if clean == "/" || clean != "/"+key {
+		return "", errors.New("invalid storage key")
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(clean)), nil
+}
+
+func (l *Local) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	target, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see half a file
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (l *Local) Delete(ctx context.Context, keys ...string) error {
+	var errs []error
+	for _, key := range keys {
+		target, err := l.path(key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (l *Local) URL(key string) string {
+	return l.baseURL + "/" + key
+}
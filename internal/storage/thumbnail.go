@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+
+	// Registers GIF decoding with image.Decode
+	_ "image/gif"
+)
+
+// Image describes a decoded upload
+type Image struct {
+	// Format is the decoder that read the image: jpeg, png or gif
+	Format string
+	Width  int
+	Height int
+}
+
+// ContentType returns the MIME type of the image's format
+func (i Image) ContentType() string {
+	return "image/" + i.Format
+}
+
+// Extension returns the file extension of the image's format, with the dot
+func (i Image) Extension() string {
+	if i.Format == "jpeg" {
+		return ".jpg"
+	}
+	return "." + i.Format
+}
+
+// ErrUnsupportedImage is returned for data that isn't a JPEG, PNG or GIF image
+var ErrUnsupportedImage = errors.New("unsupported image type")
+
+// Thumbnail decodes an image and scales it down to fit within size×size pixels, keeping its
+// aspect ratio; smaller images keep their size. JPEGs stay JPEGs and everything else becomes a
+// PNG so transparency survives. It returns the thumbnail, its content type and the original
+// image's details.
+func Thumbnail(data []byte, size int) ([]byte, string, Image, error) {
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", Image{}, ErrUnsupportedImage
+	}
+	bounds := src.Bounds()
+	info := Image{Format: format, Width: bounds.Dx(), Height: bounds.Dy()}
+
+	width, height := info.Width, info.Height
+	if width > size || height > size {
+		if width >= height {
+			height = max(1, height*size/width)
+			width = size
+		} else {
+			width = max(1, width*size/height)
+			height = size
+		}
+	}
+	thumb := scale(src, width, height)
+
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 85})
+		return buf.Bytes(), "image/jpeg", info, err
+	}
+	err = png.Encode(&buf, thumb)
+	return buf.Bytes(), "image/png", info, err
+}
+
+// scale resizes an image by averaging the source pixels covered by each target pixel, which
+// keeps downscaled images smooth without an imaging library
+func scale(src image.Image, width int, height int) image.Image {
+	bounds := src.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+	if width == bounds.Dx() && height == bounds.Dy() {
+		return rgba
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	for y := 0; y < height; y++ {
+		y0 := y * srcHeight / height
+		y1 := max(y0+1, (y+1)*srcHeight/height)
+		for x := 0; x < width; x++ {
+			x0 := x * srcWidth / width
+			x1 := max(x0+1, (x+1)*srcWidth/width)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pixel := rgba.RGBAAt(sx, sy)
+					r += uint64(pixel.R)
+					g += uint64(pixel.G)
+					b += uint64(pixel.B)
+					a += uint64(pixel.A)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n)})
+		}
+	}
+	return dst
+}
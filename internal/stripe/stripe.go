@@ -0,0 +1,145 @@
+// Package stripe holds what Stripe Billing and Stripe payments share: webhook signature
+// verification, the webhook event envelope and a minimal client for the Stripe API.
+package stripe
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureTolerance is how old a signed webhook may be before it is rejected as a replay
+const SignatureTolerance = 5 * time.Minute
+
+// ErrInvalidSignature is returned for webhooks that don't carry a valid, recent signature
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Event is the envelope of a Stripe webhook event; Data.Object depends on the event type
+type Event struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// VerifySignature checks the Stripe-Signature header against the raw payload and the signing
+// secret of the webhook endpoint
+func VerifySignature(payload []byte, header string, secret string) error {
+	if secret == "" {
+		return ErrInvalidSignature
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > SignatureTolerance || age < -SignatureTolerance {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+
+	for _, signature := range signatures {
+		decoded, err := hex.DecodeString(signature)
+		if err == nil && hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// Error is an error answered by the Stripe API
+type Error struct {
+	Status      int    `json:"-"`
+	Type        string `json:"type"`
+	Code        string `json:"code"`
+	DeclineCode string `json:"decline_code"`
+	Message     string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("stripe: %s (%s, status %d)", e.Message, e.Type, e.Status)
+}
+
+// Client calls the Stripe API with a secret key
+type Client struct {
+	secretKey string
+	baseURL   string
+	http      *http.Client
+}
+
+// NewClient creates a client for the Stripe API
+func NewClient(secretKey string) *Client {
+	return &Client{
+		secretKey: secretKey,
+		baseURL:   "https://api.stripe.com",
+		http:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Post sends form parameters to an API path, e.g. "/v1/payment_intents", and decodes the
+// response into out. Requests with the same idempotency key are applied once by Stripe, so
+// retries after a timeout can't charge twice.
+func (c *Client) Post(ctx context.Context, path string, params url.Values, idempotencyKey string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.secretKey, "")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var envelope struct {
+			Error Error `json:"error"`
+		}
+		if err := json.Unmarshal(body, &envelope); err != nil {
+			return fmt.Errorf("stripe: unexpected status %d", resp.StatusCode)
+		}
+		envelope.Error.Status = resp.StatusCode
+		return &envelope.Error
+	}
+	return json.Unmarshal(body, out)
+}
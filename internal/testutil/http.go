@@ -0,0 +1,116 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Aebroyx/the-blade-api/internal/apiversion"
+	"github.com/Aebroyx/the-blade-api/internal/common"
+	"github.com/Aebroyx/the-blade-api/internal/domain/models"
+	"github.com/Aebroyx/the-blade-api/internal/middleware"
+	"github.com/Aebroyx/the-blade-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Auth returns the authentication middleware of the API, backed by the Env
+func (e *Env) Auth() gin.HandlerFunc {
+	presence := services.NewPresenceService(e.DB, e.Cache, e.Config.PresenceTTL)
+	return middleware.Auth(e.Config.JWTSecret, e.Config.AuthClaimsMode, e.DB, e.Cache, presence)
+}
+
+// API serves the routes of the registrars the way the server does: each version under
+// /api/<version> with its protected routes behind Auth, unversioned paths negotiated and
+// requests matching no route answered by NoRoute
+func (e *Env) API(registrars ...apiversion.Registrar) http.Handler {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	auth := e.Auth()
+	versions := make([]string, 0, len(registrars))
+	api := router.Group(apiversion.Prefix)
+	for _, registrar := range registrars {
+		versioned := api.Group("/"+registrar.Version(), middleware.APIVersion(registrar.Version()), middleware.SchemaVersion())
+		registrar.Register(versioned, versioned.Group("", auth))
+		versions = append(versions, registrar.Version())
+	}
+	router.NoRoute(middleware.NoRoute(versions))
+
+	return apiversion.Negotiate(router)
+}
+
+// Token issues an access token for the user, as logging in would
+func (e *Env) Token(t testing.TB, user models.Users) string {
+	t.Helper()
+
+	now := time.Now()
+	claims := &models.Claims{
+		UserID:   user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Role:     user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(e.Config.JWTExpiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "the-blade-api",
+			Subject:   user.Username,
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(e.Config.JWTSecret))
+	if err != nil {
+		t.Fatalf("testutil: failed to sign token: %v", err)
+	}
+	return token
+}
+
+// Request sends a request through the handler and records the response. A non-nil body is
+// sent as JSON. A non-nil user is authenticated with an access token cookie.
+func (e *Env) Request(t testing.TB, handler http.Handler, method string, path string, body interface{}, user *models.Users) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("testutil: failed to encode request body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if user != nil {
+		req.AddCookie(&http.Cookie{Name: "access_token", Value: e.Token(t, *user)})
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// Decode checks the response status and decodes the data of the response envelope into out,
+// which may be nil when only the status matters
+func Decode(t testing.TB, recorder *httptest.ResponseRecorder, status int, out interface{}) {
+	t.Helper()
+
+	if recorder.Code != status {
+		t.Fatalf("expected status %d, got %d: %s", status, recorder.Code, recorder.Body.String())
+	}
+	if out == nil {
+		return
+	}
+
+	response := common.Response{Data: out}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("testutil: failed to decode response: %v: %s", err, recorder.Body.String())
+	}
+}
@@ -0,0 +1,213 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	postgresRepository = "postgres"
+	postgresTag        = "16-alpine"
+	redisRepository    = "redis"
+	redisTag           = "7-alpine"
+
+	// How long a freshly started server gets to accept connections
+	readyTimeout = 30 * time.Second
+	// Containers left behind by a crashed test binary stop themselves after this many seconds
+	containerExpiry = 600
+)
+
+// errNoServers means neither external servers nor Docker are available, so tests are skipped
+var errNoServers = errors.New("set TEST_POSTGRES_URL and TEST_REDIS_ADDR or install Docker to run integration tests")
+
+// server is a Postgres or Redis instance shared by every Env of a test binary
+type server struct {
+	host     string
+	port     string
+	user     string
+	password string
+	database string
+	sslMode  string
+	// Container started for the tests; nil for external servers
+	pool     *dockertest.Pool
+	resource *dockertest.Resource
+}
+
+// dsn returns the connection string of a database on the server
+func (s *server) dsn(database string) string {
+	return fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		s.host, s.port, s.user, s.password, database, s.sslMode,
+	)
+}
+
+// addr returns the host:port of the server
+func (s *server) addr() string {
+	return net.JoinHostPort(s.host, s.port)
+}
+
+// stop removes the server's container, if the tests started one
+func (s *server) stop() {
+	if s == nil || s.resource == nil {
+		return
+	}
+	if err := s.pool.Purge(s.resource); err != nil {
+		fmt.Fprintf(os.Stderr, "testutil: failed to remove container %s: %v\n", s.resource.Container.Name, err)
+	}
+}
+
+// startPostgres connects to TEST_POSTGRES_URL, or starts a throwaway Postgres container
+func startPostgres() (*server, *gorm.DB, error) {
+	var pg *server
+	if raw := os.Getenv("TEST_POSTGRES_URL"); raw != "" {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid TEST_POSTGRES_URL: %w", err)
+		}
+		password, _ := u.User.Password()
+		pg = &server{
+			host:     u.Hostname(),
+			port:     u.Port(),
+			user:     u.User.Username(),
+			password: password,
+			database: strings.TrimPrefix(u.Path, "/"),
+			sslMode:  u.Query().Get("sslmode"),
+		}
+		if pg.port == "" {
+			pg.port = "5432"
+		}
+		if pg.database == "" {
+			pg.database = "postgres"
+		}
+		if pg.sslMode == "" {
+			pg.sslMode = "disable"
+		}
+	} else {
+		var err error
+		pg, err = startContainer(postgresRepository, postgresTag, "5432", "POSTGRES_USER=postgres", "POSTGRES_PASSWORD=postgres")
+		if err != nil {
+			return nil, nil, err
+		}
+		pg.user = "postgres"
+		pg.password = "postgres"
+		pg.database = "postgres"
+		pg.sslMode = "disable"
+	}
+
+	// The admin connection creates and drops the database of every Env
+	var admin *gorm.DB
+	err := waitFor(func() error {
+		db, err := gorm.Open(postgres.Open(pg.dsn(pg.database)), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		if err != nil {
+			return err
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		if err := sqlDB.Ping(); err != nil {
+			sqlDB.Close()
+			return err
+		}
+		admin = db
+		return nil
+	})
+	if err != nil {
+		pg.stop()
+		return nil, nil, fmt.Errorf("postgres did not become ready: %w", err)
+	}
+	return pg, admin, nil
+}
+
+// startRedis connects to TEST_REDIS_ADDR, or starts a throwaway Redis container
+func startRedis() (*server, error) {
+	var rs *server
+	if addr := os.Getenv("TEST_REDIS_ADDR"); addr != "" {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TEST_REDIS_ADDR: %w", err)
+		}
+		rs = &server{host: host, port: port, password: os.Getenv("TEST_REDIS_PASSWORD")}
+	} else {
+		var err error
+		rs, err = startContainer(redisRepository, redisTag, "6379")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := waitFor(func() error {
+		client := redis.NewClient(&redis.Options{Addr: rs.addr(), Password: rs.password})
+		defer client.Close()
+		return client.Ping(context.Background()).Err()
+	})
+	if err != nil {
+		rs.stop()
+		return nil, fmt.Errorf("redis did not become ready: %w", err)
+	}
+	return rs, nil
+}
+
+// startContainer runs an image in the background and publishes its port on a random local port
+func startContainer(repository string, tag string, port string, env ...string) (*server, error) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		return nil, errNoServers
+	}
+	if err := pool.Client.Ping(); err != nil {
+		return nil, errNoServers
+	}
+
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: repository,
+		Tag:        tag,
+		Env:        env,
+	}, func(config *docker.HostConfig) {
+		config.AutoRemove = true
+		config.RestartPolicy = docker.RestartPolicy{Name: "no"}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start %s:%s: %w", repository, tag, err)
+	}
+	s := &server{pool: pool, resource: resource}
+	if err := resource.Expire(containerExpiry); err != nil {
+		s.stop()
+		return nil, fmt.Errorf("failed to set the expiry of %s:%s: %w", repository, tag, err)
+	}
+
+	address := resource.GetHostPort(port + "/tcp")
+	s.host, s.port, err = net.SplitHostPort(address)
+	if err != nil {
+		s.stop()
+		return nil, fmt.Errorf("unexpected port of %s:%s: %q", repository, tag, address)
+	}
+	return s, nil
+}
+
+// waitFor retries check until it succeeds or the servers had long enough to start
+func waitFor(check func() error) error {
+	deadline := time.Now().Add(readyTimeout)
+	for {
+		err := check()
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
@@ -0,0 +1,81 @@
+// Package tracing sets up OpenTelemetry tracing. Spans are recorded by the official Gin, GORM,
+// go-redis and gRPC instrumentation and exported in batches to an OpenTelemetry collector over
+// OTLP/HTTP. Trace context is read from the W3C traceparent header, so traces started by a client
+// or gateway continue here.
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Config configures tracing
+type Config struct {
+	// Base URL of the collector's OTLP/HTTP receiver, e.g. http://otel-collector:4318
+	Endpoint string
+	// service.name of the spans' resource
+	ServiceName string
+	// Share of traces started here (0 to 1) that are recorded; traces continued from another
+	// service follow its sampling decision
+	SampleRate float64
+}
+
+// Setup installs the global tracer provider and propagators the instrumentation uses. It returns
+// a function that exports the spans still queued and stops the provider.
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	exporter, err := otlptracehttp.New(ctx,
+		otlptracehttp.WithEndpointURL(strings.TrimSuffix(config.Endpoint, "/")+"/v1/traces"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(config.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(requestSampler{
+			Sampler: sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRate)),
+		}),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return provider.Shutdown, nil
+}
+
+// requestSampler drops client spans that have no parent, so database and cache calls made
+// outside a traced request, such as health checks and background jobs, don't start traces of
+// their own
+type requestSampler struct {
+	sdktrace.Sampler
+}
+
+func (s requestSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	parent := trace.SpanContextFromContext(p.ParentContext)
+	if !parent.IsValid() && p.Kind == trace.SpanKindClient {
+		return sdktrace.SamplingResult{Decision: sdktrace.Drop, Tracestate: parent.TraceState()}
+	}
+	return s.Sampler.ShouldSample(p)
+}
+
+func (s requestSampler) Description() string {
+	return "RequestSampler{" + s.Sampler.Description() + "}"
+}